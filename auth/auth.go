@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/piusalfred/whatsapp/config"
 	"github.com/piusalfred/whatsapp/pkg/crypto"
 	whttp "github.com/piusalfred/whatsapp/pkg/http"
 )
@@ -299,6 +301,31 @@ func (c *Client) RefreshAccessToken(ctx context.Context,
 	return res, nil
 }
 
+// ConfigTokenRefresher returns a config.TokenRefresher that exchanges tokens with
+// RefreshAccessToken using the app ID and secret of the config, for use with
+// config.NewAutoRefreshReader. sixtyDays asks for tokens that expire in 60 days.
+func (c *Client) ConfigTokenRefresher(sixtyDays bool) config.TokenRefresherFunc {
+	return func(ctx context.Context, conf *config.Config, current string) (*config.Token, error) {
+		start := time.Now()
+		res, err := c.RefreshAccessToken(ctx, RefreshAccessTokenParams{
+			ClientID:            conf.AppID,
+			ClientSecret:        conf.AppSecret,
+			FbExchangeToken:     current,
+			SetTokenExpiresIn60: sixtyDays,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		token := &config.Token{Value: res.AccessToken}
+		if res.ExpiresIn > 0 {
+			token.ExpiresAt = start.Add(time.Duration(res.ExpiresIn) * time.Second)
+		}
+
+		return token, nil
+	}
+}
+
 type (
 	TokenRotator interface {
 		RotateToken(ctx context.Context, refresher TokenRefresher, revoker TokenRevoker, store TokenStore) error
@@ -0,0 +1,349 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type (
+	// TokenInfo is the result of inspecting an access token with /debug_token.
+	TokenInfo struct {
+		AppID               string           `json:"app_id,omitempty"`
+		Type                string           `json:"type,omitempty"`
+		Application         string           `json:"application,omitempty"`
+		DataAccessExpiresAt int64            `json:"data_access_expires_at,omitempty"`
+		ExpiresAt           int64            `json:"expires_at,omitempty"`
+		IsValid             bool             `json:"is_valid"`
+		IssuedAt            int64            `json:"issued_at,omitempty"`
+		Scopes              []string         `json:"scopes,omitempty"`
+		GranularScopes      []*GranularScope `json:"granular_scopes,omitempty"`
+		UserID              string           `json:"user_id,omitempty"`
+	}
+
+	// GranularScope lists the assets (WABAs, business ids) a scope was granted for.
+	GranularScope struct {
+		Scope     string   `json:"scope"`
+		TargetIDs []string `json:"target_ids,omitempty"`
+	}
+
+	// DebugTokenParams contains the parameters of a /debug_token call. AccessToken is an app
+	// token ("{app-id}|{app-secret}") or a token of an app developer.
+	DebugTokenParams struct {
+		InputToken  string
+		AccessToken string
+	}
+
+	debugTokenResponse struct {
+		Data *TokenInfo `json:"data"`
+	}
+)
+
+// HasScope reports whether the token was granted scope.
+func (info *TokenInfo) HasScope(scope string) bool {
+	return slices.Contains(info.Scopes, scope)
+}
+
+// Expired reports whether the token has expired at now. Tokens that never expire report
+// an ExpiresAt of zero.
+func (info *TokenInfo) Expired(now time.Time) bool {
+	return info.ExpiresAt > 0 && now.Unix() >= info.ExpiresAt
+}
+
+// DebugToken returns the metadata of params.InputToken including its scopes.
+func (c *Client) DebugToken(ctx context.Context, params DebugTokenParams) (*TokenInfo, error) {
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](whttp.RequestTypeDebugToken),
+		whttp.WithRequestEndpoints[any](c.apiVersion, "debug_token"),
+		whttp.WithRequestQueryParams[any](map[string]string{
+			"input_token":  params.InputToken,
+			"access_token": params.AccessToken,
+		}),
+	}
+
+	req := whttp.MakeRequest[any](http.MethodGet, c.baseURL, opts...)
+
+	res := &debugTokenResponse{}
+	decoder := whttp.ResponseDecoderJSON(res, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := c.sender.Send(ctx, req, decoder); err != nil {
+		return nil, fmt.Errorf("debug token: %w", err)
+	}
+
+	if res.Data == nil {
+		return nil, fmt.Errorf("debug token: %w", ErrEmptyTokenInfo)
+	}
+
+	return res.Data, nil
+}
+
+type (
+	// TokenInfoFetcher returns the TokenInfo of an access token.
+	TokenInfoFetcher interface {
+		FetchTokenInfo(ctx context.Context, token string) (*TokenInfo, error)
+	}
+
+	TokenInfoFetcherFunc func(ctx context.Context, token string) (*TokenInfo, error)
+)
+
+func (fn TokenInfoFetcherFunc) FetchTokenInfo(ctx context.Context, token string) (*TokenInfo, error) {
+	return fn(ctx, token)
+}
+
+// TokenInfoFetcher returns a fetcher that inspects tokens with DebugToken, authenticated
+// with appAccessToken.
+func (c *Client) TokenInfoFetcher(appAccessToken string) TokenInfoFetcherFunc {
+	return func(ctx context.Context, token string) (*TokenInfo, error) {
+		return c.DebugToken(ctx, DebugTokenParams{InputToken: token, AccessToken: appAccessToken})
+	}
+}
+
+// TokenInfoCache keeps TokenInfo per token for ttl so that checking scopes does not cost
+// an extra Graph call per request. Stale entries are dropped whenever a token is fetched.
+type TokenInfoCache struct {
+	fetcher TokenInfoFetcher
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*tokenInfoEntry
+}
+
+type tokenInfoEntry struct {
+	info      *TokenInfo
+	fetchedAt time.Time
+}
+
+var _ TokenInfoFetcher = (*TokenInfoCache)(nil)
+
+func NewTokenInfoCache(fetcher TokenInfoFetcher, ttl time.Duration) *TokenInfoCache {
+	return &TokenInfoCache{
+		fetcher: fetcher,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*tokenInfoEntry),
+	}
+}
+
+// FetchTokenInfo returns the cached TokenInfo of token, fetching it when missing or stale.
+func (cache *TokenInfoCache) FetchTokenInfo(ctx context.Context, token string) (*TokenInfo, error) {
+	cache.mu.Lock()
+	entry, ok := cache.entries[token]
+	cache.mu.Unlock()
+
+	if ok && cache.now().Sub(entry.fetchedAt) < cache.ttl {
+		return entry.info, nil
+	}
+
+	info, err := cache.fetcher.FetchTokenInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, ErrEmptyTokenInfo
+	}
+
+	now := cache.now()
+	cache.mu.Lock()
+	for key, entry := range cache.entries {
+		if now.Sub(entry.fetchedAt) >= cache.ttl {
+			delete(cache.entries, key)
+		}
+	}
+	cache.entries[token] = &tokenInfoEntry{info: info, fetchedAt: now}
+	cache.mu.Unlock()
+
+	return info, nil
+}
+
+// Len returns the number of cached tokens.
+func (cache *TokenInfoCache) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return len(cache.entries)
+}
+
+// Invalidate drops the cached TokenInfo of token, for example after it was rotated.
+func (cache *TokenInfoCache) Invalidate(token string) {
+	cache.mu.Lock()
+	delete(cache.entries, token)
+	cache.mu.Unlock()
+}
+
+// RequiredScopes maps request types to the scopes the access token must carry.
+type RequiredScopes map[whttp.RequestType][]string
+
+// DefaultRequiredScopes returns the scopes needed by the management endpoints: phone
+// numbers, business profile, templates, flows, analytics, webhook configuration and
+// subscriptions, the connected product catalogs, groups, calls and blocked users.
+func DefaultRequiredScopes() RequiredScopes {
+	management := []string{TokenScopeWhatsappBusinessManagement}
+	catalog := []string{TokenScopeCatalogManagement}
+	messaging := []string{TokenScopeWhatsappBusinessMessaging}
+
+	return RequiredScopes{
+		whttp.RequestTypeListPhoneNumbers:           management,
+		whttp.RequestTypeGetPhoneNumber:             management,
+		whttp.RequestTypeGetWebhookConfiguration:    management,
+		whttp.RequestTypeUpdateWebhookConfiguration: management,
+		whttp.RequestTypeCreateTemplate:             management,
+		whttp.RequestTypeListTemplates:              management,
+		whttp.RequestTypeGetTemplate:                management,
+		whttp.RequestTypeUpdateTemplate:             management,
+		whttp.RequestTypeDeleteTemplate:             management,
+		whttp.RequestTypeListTemplateLibrary:        management,
+		whttp.RequestTypeGetBusinessProfile:         management,
+		whttp.RequestTypeUpdateBusinessProfile:      management,
+		whttp.RequestTypeRetrieveFlows:              management,
+		whttp.RequestTypeRetrieveFlowDetails:        management,
+		whttp.RequestTypeRetrieveAssets:             management,
+		whttp.RequestTypePublishFlow:                management,
+		whttp.RequestTypeDeprecateFlow:              management,
+		whttp.RequestTypeDeleteFlow:                 management,
+		whttp.RequestTypeUpdateFlow:                 management,
+		whttp.RequestTypeCreateFlow:                 management,
+		whttp.RequestTypeRetrieveFlowPreview:        management,
+		whttp.RequestTypeGetFlowMetrics:             management,
+		whttp.RequestTypeFetchMessagingAnalytics:    management,
+		whttp.RequestTypeFetchTemplateAnalytics:     management,
+		whttp.RequestTypeFetchPricingAnalytics:      management,
+		whttp.RequestTypeFetchConversationAnalytics: management,
+		whttp.RequestTypeEnableTemplatesAnalytics:   management,
+		whttp.RequestTypeDisableButtonClickTracking: management,
+		whttp.RequestTypeTwoStepVerification:        management,
+		whttp.RequestTypeListCatalogs:               management,
+		whttp.RequestTypeListProducts:               catalog,
+		whttp.RequestTypeGetProduct:                 catalog,
+		whttp.RequestTypeGetCommerceSettings:        management,
+		whttp.RequestTypeUpdateCommerceSettings:     management,
+		whttp.RequestTypeCreateGroup:                messaging,
+		whttp.RequestTypeListGroups:                 messaging,
+		whttp.RequestTypeGetGroup:                   messaging,
+		whttp.RequestTypeUpdateGroup:                messaging,
+		whttp.RequestTypeDeleteGroup:                messaging,
+		whttp.RequestTypeGetGroupInviteLink:         messaging,
+		whttp.RequestTypeResetGroupInviteLink:       messaging,
+		whttp.RequestTypeRemoveGroupParticipants:    messaging,
+		whttp.RequestTypeListGroupJoinRequests:      messaging,
+		whttp.RequestTypeApproveGroupJoinRequests:   messaging,
+		whttp.RequestTypeInitiateCall:               messaging,
+		whttp.RequestTypeManageCall:                 messaging,
+		whttp.RequestTypeGetCallPermissions:         messaging,
+		whttp.RequestTypeRejectGroupJoinRequests:    messaging,
+		whttp.RequestTypeGetPhoneNumberSettings:     management,
+		whttp.RequestTypeUpdatePhoneNumberSettings:  management,
+		whttp.RequestTypeBlockUsers:                 messaging,
+		whttp.RequestTypeUnblockUsers:               messaging,
+		whttp.RequestTypeListBlockedUsers:           messaging,
+		whttp.RequestTypeSubscribeApp:               management,
+		whttp.RequestTypeListSubscribedApps:         management,
+		whttp.RequestTypeUnsubscribeApp:             management,
+		whttp.RequestTypeSetBusinessPublicKey:       messaging,
+		whttp.RequestTypeGetBusinessPublicKey:       messaging,
+		whttp.RequestTypeSyncAppData:                messaging,
+	}
+}
+
+// MissingScopesError is returned by ScopesCheckMiddleware when the access token lacks
+// scopes needed by the request.
+type MissingScopesError struct {
+	RequestType whttp.RequestType
+	Missing     []string
+}
+
+func (e *MissingScopesError) Error() string {
+	return fmt.Sprintf("%s: %s requires %s", ErrMissingScopes, e.RequestType, strings.Join(e.Missing, ", "))
+}
+
+func (e *MissingScopesError) Unwrap() error {
+	return ErrMissingScopes
+}
+
+// ScopesCheckMiddleware verifies that the access token of a request carries the scopes
+// listed in required before the request is sent. Requests whose type is not listed are
+// passed through. If the token cannot be inspected, or the fetcher returns no TokenInfo,
+// the request is sent anyway and Graph has the final say.
+func ScopesCheckMiddleware[T any](fetcher TokenInfoFetcher, required RequiredScopes) whttp.Middleware[T] {
+	return func(next whttp.SenderFunc[T]) whttp.SenderFunc[T] {
+		return func(ctx context.Context, request *whttp.Request[T], decoder whttp.ResponseDecoder) error {
+			scopes, ok := required[request.Type]
+			if !ok || len(scopes) == 0 {
+				return next(ctx, request, decoder)
+			}
+
+			token := request.Bearer
+			if token == "" {
+				token = request.QueryParams["access_token"]
+			}
+
+			if token == "" {
+				return next(ctx, request, decoder)
+			}
+
+			info, err := fetcher.FetchTokenInfo(ctx, token)
+			if err != nil || info == nil {
+				return next(ctx, request, decoder)
+			}
+
+			if !info.IsValid {
+				return fmt.Errorf("%s: %w", request.Type, ErrInvalidToken)
+			}
+
+			var missing []string
+			for _, scope := range scopes {
+				if !info.HasScope(scope) {
+					missing = append(missing, scope)
+				}
+			}
+
+			if len(missing) > 0 {
+				return &MissingScopesError{RequestType: request.Type, Missing: missing}
+			}
+
+			return next(ctx, request, decoder)
+		}
+	}
+}
+
+// authError is a custom error type for auth errors.
+type authError string
+
+func (e authError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrMissingScopes  = authError("access token is missing required scopes")
+	ErrInvalidToken   = authError("access token is invalid or expired")
+	ErrEmptyTokenInfo = authError("empty token info")
+)
@@ -0,0 +1,135 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/auth"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestScopesCheckMiddleware(t *testing.T) {
+	t.Parallel()
+
+	infos := map[string]*auth.TokenInfo{
+		"messaging": {IsValid: true, Scopes: []string{auth.TokenScopeWhatsappBusinessMessaging}},
+		"full": {IsValid: true, Scopes: []string{
+			auth.TokenScopeWhatsappBusinessMessaging,
+			auth.TokenScopeWhatsappBusinessManagement,
+		}},
+		"expired": {IsValid: false},
+	}
+
+	fetches := 0
+	cache := auth.NewTokenInfoCache(auth.TokenInfoFetcherFunc(func(_ context.Context, token string) (*auth.TokenInfo, error) {
+		fetches++
+
+		return infos[token], nil
+	}), time.Minute)
+
+	sent := 0
+	sender := auth.ScopesCheckMiddleware[any](cache, auth.DefaultRequiredScopes())(
+		func(context.Context, *whttp.Request[any], whttp.ResponseDecoder) error {
+			sent++
+
+			return nil
+		})
+
+	tests := []struct {
+		name        string
+		token       string
+		requestType whttp.RequestType
+		wantErr     error
+	}{
+		{name: "messaging token on management endpoint", token: "messaging", requestType: whttp.RequestTypeGetBusinessProfile, wantErr: auth.ErrMissingScopes},
+		{name: "messaging token sends messages", token: "messaging", requestType: whttp.RequestTypeSendMessage},
+		{name: "management token", token: "full", requestType: whttp.RequestTypeListPhoneNumbers},
+		{name: "invalid token", token: "expired", requestType: whttp.RequestTypeGetPhoneNumber, wantErr: auth.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		err := sender(context.Background(), &whttp.Request[any]{Type: tt.requestType, Bearer: tt.token}, nil)
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	var missing *auth.MissingScopesError
+	err := sender(context.Background(), &whttp.Request[any]{Type: whttp.RequestTypeCreateFlow, Bearer: "messaging"}, nil)
+	if !errors.As(err, &missing) || missing.Missing[0] != auth.TokenScopeWhatsappBusinessManagement {
+		t.Errorf("expected MissingScopesError for %s, got %v", auth.TokenScopeWhatsappBusinessManagement, err)
+	}
+
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3 (one per cached token)", fetches)
+	}
+}
+
+func TestScopesCheckMiddleware_NilTokenInfo(t *testing.T) {
+	t.Parallel()
+
+	fetcher := auth.TokenInfoFetcherFunc(func(context.Context, string) (*auth.TokenInfo, error) {
+		return nil, nil //nolint:nilnil // no token info
+	})
+
+	for name, fetcher := range map[string]auth.TokenInfoFetcher{
+		"fetcher": fetcher,
+		"cache":   auth.NewTokenInfoCache(fetcher, time.Minute),
+	} {
+		sent := 0
+		sender := auth.ScopesCheckMiddleware[any](fetcher, auth.DefaultRequiredScopes())(
+			func(context.Context, *whttp.Request[any], whttp.ResponseDecoder) error {
+				sent++
+
+				return nil
+			})
+
+		err := sender(context.Background(), &whttp.Request[any]{Type: whttp.RequestTypeSendMessage, Bearer: "token"}, nil)
+		if err != nil || sent != 1 {
+			t.Errorf("%s: error = %v, sent = %d, want the request sent", name, err, sent)
+		}
+	}
+
+	if _, err := auth.NewTokenInfoCache(fetcher, time.Minute).FetchTokenInfo(context.Background(),
+		"token"); !errors.Is(err, auth.ErrEmptyTokenInfo) {
+		t.Errorf("FetchTokenInfo() error = %v, want %v", err, auth.ErrEmptyTokenInfo)
+	}
+}
+
+func TestTokenInfoCache_Prune(t *testing.T) {
+	t.Parallel()
+
+	cache := auth.NewTokenInfoCache(auth.TokenInfoFetcherFunc(func(context.Context, string) (*auth.TokenInfo, error) {
+		return &auth.TokenInfo{IsValid: true}, nil
+	}), 10*time.Millisecond)
+
+	ctx := context.Background()
+	for _, token := range []string{"a", "b", "c"} {
+		if _, err := cache.FetchTokenInfo(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := cache.FetchTokenInfo(ctx, "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cache.Len(); got != 1 {
+		t.Errorf("Len() = %d, want the stale tokens dropped", got)
+	}
+}
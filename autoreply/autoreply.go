@@ -0,0 +1,277 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package autoreply keeps bots built on the message webhooks from spamming customers.
+//
+// Blocked and reported bots lower the quality rating of the business phone number, which in
+// turn lowers its messaging limits. A Guard enforces a Policy on the replies sent while a
+// message handler runs: a cap on the replies sent for a single inbound message, a cooldown
+// between replies to the same contact and the business hours in which replies are sent.
+//
+// Wrap the handlers that reply automatically and add the guard middleware to the client
+// used to reply:
+//
+//	guard := autoreply.NewGuard(autoreply.Policy{MaxRepliesPerMessage: 2, Cooldown: time.Minute})
+//	handlers.TextMessage = autoreply.Handler(guard, handlers.TextMessage)
+//	client, _ := wmessage.NewBaseClient(sender, reader, autoreply.Middleware())
+//
+// Replies sent outside a wrapped handler, and messages sent to anyone other than the
+// sender of the inbound message, are not limited.
+package autoreply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type (
+	// Policy is the set of limits applied to automatic replies. Zero values are not
+	// enforced.
+	Policy struct {
+		// MaxRepliesPerMessage caps the replies sent while handling one inbound message.
+		MaxRepliesPerMessage int
+
+		// Cooldown is the minimum time between replies to the same contact triggered by
+		// different inbound messages. Several replies to one message are not delayed.
+		Cooldown time.Duration
+
+		// Hours are the hours in which replies are sent.
+		Hours *webhooks.BusinessHours
+	}
+
+	GuardOption func(*Guard)
+
+	// Guard enforces a Policy. A Guard is safe for concurrent use, use one per policy and
+	// share it between the handlers the policy applies to. Handlers wrapped with different
+	// guards can reply through the same client.
+	Guard struct {
+		policy Policy
+		now    func() time.Time
+
+		mu       sync.Mutex
+		contacts map[string]*contactState
+	}
+
+	contactState struct {
+		lastReplyAt time.Time
+		inboundID   string
+	}
+
+	// scope is the inbound message a handler is replying to.
+	scope struct {
+		guard     *Guard
+		contact   string
+		inboundID string
+
+		mu      sync.Mutex
+		replies int
+	}
+
+	scopeContextKey struct{}
+)
+
+func WithGuardClock(now func() time.Time) GuardOption {
+	return func(g *Guard) {
+		g.now = now
+	}
+}
+
+func NewGuard(policy Policy, options ...GuardOption) *Guard {
+	guard := &Guard{
+		policy:   policy,
+		now:      time.Now,
+		contacts: make(map[string]*contactState),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(guard)
+		}
+	}
+
+	return guard
+}
+
+// WithInbound returns a context in which replies to contact count against the inbound
+// message with the given ID, for handlers not built on the message webhook handlers.
+func (g *Guard) WithInbound(ctx context.Context, contact, inboundID string) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, &scope{guard: g, contact: contact, inboundID: inboundID})
+}
+
+// Check reports whether a reply to recipient would be allowed in ctx without counting it.
+func Check(ctx context.Context, recipient string) error {
+	s, ok := ctx.Value(scopeContextKey{}).(*scope)
+	if !ok || s.contact != recipient {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.guard.check(s, s.guard.now())
+}
+
+// Middleware returns a sender middleware that rejects the replies not allowed by the policy
+// of the handler they are sent from with ErrReplyLimit, ErrCooldown or
+// ErrOutsideBusinessHours. Suppressed tells these apart from send failures.
+func Middleware() wmessage.SenderMiddleware {
+	return func(next wmessage.SenderFunc) wmessage.SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *wmessage.BaseRequest) (*wmessage.Response, error) {
+			s, ok := ctx.Value(scopeContextKey{}).(*scope)
+			if !ok || request.Type != whttp.RequestTypeSendMessage ||
+				request.Message == nil || request.Message.To != s.contact {
+				return next(ctx, conf, request)
+			}
+
+			if err := s.guard.reserve(s); err != nil {
+				return nil, fmt.Errorf("reply to %s: %w", s.contact, err)
+			}
+
+			response, err := next(ctx, conf, request)
+			if err != nil {
+				s.guard.release(s)
+
+				return nil, err
+			}
+
+			return response, nil
+		}
+	}
+}
+
+func (g *Guard) reserve(s *scope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := g.now()
+	if err := g.check(s, now); err != nil {
+		return err
+	}
+
+	s.replies++
+
+	g.mu.Lock()
+	g.contacts[s.contact] = &contactState{lastReplyAt: now, inboundID: s.inboundID}
+	g.prune(now)
+	g.mu.Unlock()
+
+	return nil
+}
+
+func (g *Guard) release(s *scope) {
+	s.mu.Lock()
+	s.replies--
+	s.mu.Unlock()
+}
+
+// check must be called with s.mu held.
+func (g *Guard) check(s *scope, now time.Time) error {
+	if !g.policy.Hours.Contains(now) {
+		return ErrOutsideBusinessHours
+	}
+
+	if g.policy.MaxRepliesPerMessage > 0 && s.replies >= g.policy.MaxRepliesPerMessage {
+		return ErrReplyLimit
+	}
+
+	if g.policy.Cooldown <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	state, ok := g.contacts[s.contact]
+	g.mu.Unlock()
+
+	if ok && state.inboundID != s.inboundID && now.Sub(state.lastReplyAt) < g.policy.Cooldown {
+		return ErrCooldown
+	}
+
+	return nil
+}
+
+// pruneThreshold is the number of contacts tracked before the ones past their cooldown are
+// dropped.
+const pruneThreshold = 1024
+
+// prune must be called with g.mu held.
+func (g *Guard) prune(now time.Time) {
+	if len(g.contacts) < pruneThreshold {
+		return
+	}
+
+	for contact, state := range g.contacts {
+		if now.Sub(state.lastReplyAt) >= g.policy.Cooldown {
+			delete(g.contacts, contact)
+		}
+	}
+}
+
+// Handler applies the policy of guard to the replies sent by next.
+func Handler[T any](guard *Guard, next message.Handler[T]) message.Handler[T] {
+	if next == nil {
+		return nil
+	}
+
+	return message.HandlerFunc[T](func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, msg *T,
+	) error {
+		return next.Handle(guard.WithInbound(ctx, mctx.From, mctx.ID), nctx, mctx, msg)
+	})
+}
+
+// ReceivedHandler applies the policy of guard to the replies sent by next.
+func ReceivedHandler(guard *Guard, next message.ReceivedHandler) message.ReceivedHandler {
+	if next == nil {
+		return nil
+	}
+
+	return message.OnMessageReceivedHook(func(ctx context.Context, nctx *message.NotificationContext,
+		msg *message.Message,
+	) error {
+		return next.Handle(guard.WithInbound(ctx, msg.From, msg.ID), nctx, msg)
+	})
+}
+
+// Suppressed reports whether err is a reply rejected by a policy.
+func Suppressed(err error) bool {
+	return errors.Is(err, ErrReplyLimit) || errors.Is(err, ErrCooldown) ||
+		errors.Is(err, ErrOutsideBusinessHours)
+}
+
+// autoReplyError is a custom error type for auto reply errors.
+type autoReplyError string
+
+func (e autoReplyError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrReplyLimit           = autoReplyError("auto reply limit reached for the inbound message")
+	ErrCooldown             = autoReplyError("auto reply cooldown has not elapsed for the contact")
+	ErrOutsideBusinessHours = autoReplyError("auto replies are not sent outside business hours")
+)
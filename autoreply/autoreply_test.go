@@ -0,0 +1,84 @@
+package autoreply_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/autoreply"
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.May, 6, 10, 0, 0, 0, time.UTC) // a Monday
+	guard := autoreply.NewGuard(autoreply.Policy{
+		MaxRepliesPerMessage: 2,
+		Cooldown:             time.Minute,
+		Hours:                &webhooks.BusinessHours{Start: 9 * time.Hour, End: 17 * time.Hour},
+	}, autoreply.WithGuardClock(func() time.Time { return now }))
+
+	sent := 0
+	send := autoreply.Middleware()(func(context.Context, *config.Config, *wmessage.BaseRequest) (*wmessage.Response, error) {
+		sent++
+
+		return &wmessage.Response{}, nil
+	})
+
+	reply := func(ctx context.Context, to string) error {
+		_, err := send(ctx, &config.Config{}, &wmessage.BaseRequest{
+			Type:    whttp.RequestTypeSendMessage,
+			Message: &wmessage.Message{To: to},
+		})
+
+		return err
+	}
+
+	var errs []error
+	handler := autoreply.Handler(guard, message.HandlerFunc[message.Text](
+		func(ctx context.Context, _ *message.NotificationContext, _ *message.Info, _ *message.Text) error {
+			errs = []error{reply(ctx, "alice"), reply(ctx, "alice"), reply(ctx, "alice"), reply(ctx, "agent")}
+
+			return nil
+		}))
+
+	handle := func(id string) {
+		if err := handler.Handle(context.Background(), &message.NotificationContext{},
+			&message.Info{From: "alice", ID: id}, &message.Text{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handle("wamid.1")
+
+	wantErrs := []error{nil, nil, autoreply.ErrReplyLimit, nil}
+	for i, want := range wantErrs {
+		if !errors.Is(errs[i], want) {
+			t.Errorf("reply %d: expected %v, got %v", i, want, errs[i])
+		}
+	}
+
+	now = now.Add(30 * time.Second)
+	handle("wamid.2")
+
+	if !errors.Is(errs[0], autoreply.ErrCooldown) || !autoreply.Suppressed(errs[0]) {
+		t.Errorf("expected %v within the cooldown, got %v", autoreply.ErrCooldown, errs[0])
+	}
+
+	now = now.Add(8 * time.Hour)
+	handle("wamid.3")
+
+	if !errors.Is(errs[0], autoreply.ErrOutsideBusinessHours) {
+		t.Errorf("expected %v after hours, got %v", autoreply.ErrOutsideBusinessHours, errs[0])
+	}
+
+	if sent != 5 {
+		t.Errorf("expected 5 messages sent, got %d", sent)
+	}
+}
@@ -0,0 +1,575 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package booking books appointments over WhatsApp.
+//
+// A Booker asks a CalendarProvider for the free slots and offers them to the contact as an
+// interactive list, grouped by day. When the contact picks a slot the Booker books it with
+// the provider, sends a confirmation and schedules reminders, which SendReminders sends
+// when they are due:
+//
+//	booker := booking.New(calendar, client, booking.NewMemoryStore(),
+//		booking.WithReminderOffsets(24*time.Hour, time.Hour),
+//		booking.WithReminder(booking.TemplateMessage("appointment_reminder", "en", reminderParams)),
+//	)
+//	_, err := booker.Offer(ctx, "255700000001", &booking.SlotQuery{Service: "consultation"})
+//	handlers.ListReply = booker.ListReplyHandler(handlers.ListReply)
+//
+// Reminders are usually sent more than 24 hours after the last message of the contact, when
+// only template messages can be sent.
+package booking
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// SlotIDPrefix prefixes the IDs of the list rows offering slots, it tells the replies to
+// booking lists apart from the replies to other lists.
+const SlotIDPrefix = "booking:"
+
+// DefaultSearchWindow is how far ahead slots are looked up unless the query says otherwise.
+const DefaultSearchWindow = 7 * 24 * time.Hour
+
+type (
+	// Slot is a free period of the calendar. Title describes it in the list, for example the
+	// name of the staff member.
+	Slot struct {
+		ID       string    `json:"id"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Title    string    `json:"title,omitempty"`
+		Resource string    `json:"resource,omitempty"`
+	}
+
+	// SlotQuery selects the slots offered to Contact between From and To.
+	SlotQuery struct {
+		Contact string
+		Service string
+		From    time.Time
+		To      time.Time
+	}
+
+	Booking struct {
+		ID        string    `json:"id"`
+		SlotID    string    `json:"slot_id"`
+		Contact   string    `json:"contact"`
+		Service   string    `json:"service,omitempty"`
+		Start     time.Time `json:"start"`
+		End       time.Time `json:"end"`
+		Title     string    `json:"title,omitempty"`
+		Resource  string    `json:"resource,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// CalendarProvider is the calendar slots are booked in.
+	CalendarProvider interface {
+		// Slots returns the free slots matching query.
+		Slots(ctx context.Context, query *SlotQuery) ([]*Slot, error)
+
+		// Book books the slot with the given ID for contact. It fails with ErrSlotUnavailable
+		// when the slot was taken in the meantime.
+		Book(ctx context.Context, slotID, contact string) (*Booking, error)
+	}
+
+	// Reminder is a reminder of a booking due at At.
+	Reminder struct {
+		ID        string    `json:"id"`
+		BookingID string    `json:"booking_id"`
+		At        time.Time `json:"at"`
+		SentAt    time.Time `json:"sent_at"`
+	}
+
+	Store interface {
+		SaveBooking(ctx context.Context, booking *Booking) error
+		GetBooking(ctx context.Context, id string) (*Booking, error)
+
+		// SaveReminder inserts the reminder or replaces the one with the same ID.
+		SaveReminder(ctx context.Context, reminder *Reminder) error
+
+		// DueReminders returns the reminders not sent yet that are due at now.
+		DueReminders(ctx context.Context, now time.Time) ([]*Reminder, error)
+	}
+
+	// MessageFunc builds the message sent about a booking. The recipient is set on messages
+	// that do not have one.
+	MessageFunc func(booking *Booking) *wmessage.Message
+
+	Option func(*Booker)
+
+	// Booker offers slots, books the selected ones and reminds contacts of their bookings.
+	Booker struct {
+		calendar     CalendarProvider
+		sender       wmessage.MessageSender
+		store        Store
+		location     *time.Location
+		offsets      []time.Duration
+		confirmation MessageFunc
+		reminder     MessageFunc
+		listBody     string
+		listButton   string
+		noSlots      string
+		unavailable  string
+		now          func() time.Time
+
+		mu       sync.Mutex
+		services map[string]string
+	}
+)
+
+// WithLocation sets the time zone slots are shown in, UTC by default.
+func WithLocation(location *time.Location) Option {
+	return func(b *Booker) {
+		b.location = location
+	}
+}
+
+// WithReminderOffsets schedules a reminder offset before the start of every booking.
+func WithReminderOffsets(offsets ...time.Duration) Option {
+	return func(b *Booker) {
+		b.offsets = offsets
+	}
+}
+
+// WithConfirmation sets the message confirming a booking, a text message by default.
+func WithConfirmation(fn MessageFunc) Option {
+	return func(b *Booker) {
+		b.confirmation = fn
+	}
+}
+
+// WithReminder sets the reminder message, a text message by default. See TemplateMessage.
+func WithReminder(fn MessageFunc) Option {
+	return func(b *Booker) {
+		b.reminder = fn
+	}
+}
+
+// WithListText sets the body of the slots list and the label of its button.
+func WithListText(body, button string) Option {
+	return func(b *Booker) {
+		b.listBody = body
+		b.listButton = button
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(b *Booker) {
+		b.now = now
+	}
+}
+
+func New(calendar CalendarProvider, sender wmessage.MessageSender, store Store, options ...Option) *Booker {
+	b := &Booker{
+		calendar:    calendar,
+		sender:      sender,
+		store:       store,
+		location:    time.UTC,
+		listBody:    "Please pick a time for your appointment.",
+		listButton:  "Available times",
+		noSlots:     "Sorry, there are no free times at the moment.",
+		unavailable: "Sorry, that time was just taken.",
+		now:         time.Now,
+		services:    make(map[string]string),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(b)
+		}
+	}
+
+	if b.confirmation == nil {
+		b.confirmation = b.textMessage("Your appointment on %s is confirmed.")
+	}
+
+	if b.reminder == nil {
+		b.reminder = b.textMessage("Reminder: you have an appointment on %s.")
+	}
+
+	return b
+}
+
+// TemplateMessage returns a MessageFunc sending the template name in language with the body
+// parameters returned by params.
+func TemplateMessage(name, language string, params func(booking *Booking) []string) MessageFunc {
+	return func(booking *Booking) *wmessage.Message {
+		var parameters []*wmessage.TemplateParameter
+		if params != nil {
+			for _, param := range params(booking) {
+				parameters = append(parameters, &wmessage.TemplateParameter{
+					Type: wmessage.TemplateParameterTypeText,
+					Text: param,
+				})
+			}
+		}
+
+		tmpl := &wmessage.Template{
+			Name:     name,
+			Language: &wmessage.TemplateLanguage{Code: language, Policy: "deterministic"},
+		}
+
+		if len(parameters) > 0 {
+			tmpl.Components = []*wmessage.TemplateComponent{{
+				Type:       wmessage.TemplateComponentTypeBody,
+				Parameters: parameters,
+			}}
+		}
+
+		msg, _ := wmessage.New(booking.Contact, wmessage.WithTemplateMessage(tmpl))
+
+		return msg
+	}
+}
+
+// Offer sends the slots matching query to the contact as an interactive list, at most
+// wmessage.InteractiveListMaxRows of them, and returns the offered slots. The contact is
+// told when there are none.
+func (b *Booker) Offer(ctx context.Context, contact string, query *SlotQuery) ([]*Slot, error) {
+	q := SlotQuery{}
+	if query != nil {
+		q = *query
+	}
+	q.Contact = contact
+
+	if q.From.IsZero() {
+		q.From = b.now()
+	}
+
+	if q.To.IsZero() {
+		q.To = q.From.Add(DefaultSearchWindow)
+	}
+
+	slots, err := b.calendar.Slots(ctx, &q)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSlots, err)
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].Start.Before(slots[j].Start) })
+	if len(slots) > wmessage.InteractiveListMaxRows {
+		slots = slots[:wmessage.InteractiveListMaxRows]
+	}
+
+	if len(slots) == 0 {
+		return nil, b.sendText(ctx, contact, b.noSlots)
+	}
+
+	b.mu.Lock()
+	b.services[contact] = q.Service
+	b.mu.Unlock()
+
+	msg, _ := wmessage.New(contact, wmessage.WithInteractiveList(&wmessage.InteractiveListRequest{
+		Button:   b.listButton,
+		Body:     b.listBody,
+		Sections: b.sections(slots),
+	}))
+
+	if _, err := b.sender.SendMessage(ctx, msg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSend, err)
+	}
+
+	return slots, nil
+}
+
+// Select books the slot of a reply to a slots list, confirms the booking and schedules its
+// reminders. It returns nil for replies to other lists. When the slot was taken the contact
+// is told and offered the remaining slots.
+func (b *Booker) Select(ctx context.Context, contact string, reply *message.ListReply) (*Booking, error) {
+	if reply == nil || !strings.HasPrefix(reply.ID, SlotIDPrefix) {
+		return nil, nil //nolint:nilnil // not a booking reply
+	}
+
+	slotID := strings.TrimPrefix(reply.ID, SlotIDPrefix)
+	booking, err := b.calendar.Book(ctx, slotID, contact)
+	if errors.Is(err, ErrSlotUnavailable) {
+		if err := b.sendText(ctx, contact, b.unavailable); err != nil {
+			return nil, err
+		}
+
+		b.mu.Lock()
+		service := b.services[contact]
+		b.mu.Unlock()
+
+		if _, err := b.Offer(ctx, contact, &SlotQuery{Service: service}); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("book slot %s: %w", slotID, ErrSlotUnavailable)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBook, err)
+	}
+
+	now := b.now()
+	if booking.ID == "" {
+		booking.ID = newID()
+	}
+	booking.SlotID = slotID
+	booking.Contact = contact
+	booking.CreatedAt = now
+
+	b.mu.Lock()
+	booking.Service = b.services[contact]
+	delete(b.services, contact)
+	b.mu.Unlock()
+
+	if err := b.store.SaveBooking(ctx, booking); err != nil {
+		return nil, fmt.Errorf("save booking: %w", err)
+	}
+
+	for _, offset := range b.offsets {
+		at := booking.Start.Add(-offset)
+		if !at.After(now) {
+			continue
+		}
+
+		reminder := &Reminder{ID: newID(), BookingID: booking.ID, At: at}
+		if err := b.store.SaveReminder(ctx, reminder); err != nil {
+			return nil, fmt.Errorf("save reminder: %w", err)
+		}
+	}
+
+	if err := b.send(ctx, booking, b.confirmation); err != nil {
+		return booking, err
+	}
+
+	return booking, nil
+}
+
+// ListReplyHandler books the slots selected in slot lists and passes the replies to other
+// lists to next.
+func (b *Booker) ListReplyHandler(next message.ListReplyMessageHandler) message.ListReplyMessageHandler {
+	return message.OnListReplyMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, reply *message.ListReply,
+	) error {
+		if !strings.HasPrefix(reply.ID, SlotIDPrefix) {
+			if next == nil {
+				return nil
+			}
+
+			return next.Handle(ctx, nctx, mctx, reply)
+		}
+
+		_, err := b.Select(ctx, mctx.From, reply)
+		if errors.Is(err, ErrSlotUnavailable) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+// SendReminders sends the reminders that are due and returns how many were sent. It is
+// meant to be called periodically.
+func (b *Booker) SendReminders(ctx context.Context) (int, error) {
+	now := b.now()
+	reminders, err := b.store.DueReminders(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("list reminders: %w", err)
+	}
+
+	var (
+		sent int
+		errs []error
+	)
+
+	for _, reminder := range reminders {
+		booking, err := b.store.GetBooking(ctx, reminder.BookingID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get booking %s: %w", reminder.BookingID, err))
+
+			continue
+		}
+
+		if err := b.send(ctx, booking, b.reminder); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		reminder.SentAt = now
+		if err := b.store.SaveReminder(ctx, reminder); err != nil {
+			errs = append(errs, fmt.Errorf("save reminder %s: %w", reminder.ID, err))
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// sections groups slots by day, a section per day.
+func (b *Booker) sections(slots []*Slot) []*wmessage.InteractiveSection {
+	var sections []*wmessage.InteractiveSection
+	for _, slot := range slots {
+		start := slot.Start.In(b.location)
+		day := start.Format("Mon 2 Jan")
+		if len(sections) == 0 || sections[len(sections)-1].Title != day {
+			sections = append(sections, &wmessage.InteractiveSection{Title: day})
+		}
+
+		section := sections[len(sections)-1]
+		section.Rows = append(section.Rows, &wmessage.InteractiveSectionRow{
+			ID:          SlotIDPrefix + slot.ID,
+			Title:       start.Format("15:04") + " - " + slot.End.In(b.location).Format("15:04"),
+			Description: slot.Title,
+		})
+	}
+
+	return sections
+}
+
+func (b *Booker) textMessage(format string) MessageFunc {
+	return func(booking *Booking) *wmessage.Message {
+		when := booking.Start.In(b.location).Format("Mon 2 Jan 2006 at 15:04")
+		msg, _ := wmessage.New(booking.Contact, wmessage.WithTextMessage(&wmessage.Text{
+			Body: fmt.Sprintf(format, when),
+		}))
+
+		return msg
+	}
+}
+
+func (b *Booker) send(ctx context.Context, booking *Booking, fn MessageFunc) error {
+	msg := fn(booking)
+	if msg == nil {
+		return nil
+	}
+
+	if msg.To == "" {
+		msg.To = booking.Contact
+	}
+
+	if _, err := b.sender.SendMessage(ctx, msg); err != nil {
+		return fmt.Errorf("%w: booking %s: %w", ErrSend, booking.ID, err)
+	}
+
+	return nil
+}
+
+func (b *Booker) sendText(ctx context.Context, contact, text string) error {
+	msg, _ := wmessage.New(contact, wmessage.WithTextMessage(&wmessage.Text{Body: text}))
+	if _, err := b.sender.SendMessage(ctx, msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrSend, err)
+	}
+
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8) //nolint:mnd // 64 random bits
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps bookings and reminders in memory.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	bookings  map[string]*Booking
+	reminders map[string]*Reminder
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		bookings:  make(map[string]*Booking),
+		reminders: make(map[string]*Reminder),
+	}
+}
+
+func (s *MemoryStore) SaveBooking(_ context.Context, booking *Booking) error {
+	if booking == nil || booking.ID == "" {
+		return ErrInvalidBooking
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *booking
+	s.bookings[booking.ID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStore) GetBooking(_ context.Context, id string) (*Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	booking, ok := s.bookings[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	b := *booking
+
+	return &b, nil
+}
+
+func (s *MemoryStore) SaveReminder(_ context.Context, reminder *Reminder) error {
+	if reminder == nil || reminder.ID == "" {
+		return ErrInvalidBooking
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *reminder
+	s.reminders[reminder.ID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStore) DueReminders(_ context.Context, now time.Time) ([]*Reminder, error) {
+	s.mu.RLock()
+	var due []*Reminder
+	for _, reminder := range s.reminders {
+		if reminder.SentAt.IsZero() && !reminder.At.After(now) {
+			r := *reminder
+			due = append(due, &r)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].At.Before(due[j].At) })
+
+	return due, nil
+}
+
+type bookingError string
+
+func (e bookingError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrSlotUnavailable = bookingError("slot is no longer available")
+	ErrSlots           = bookingError("list available slots failed")
+	ErrBook            = bookingError("book slot failed")
+	ErrSend            = bookingError("send booking message failed")
+	ErrInvalidBooking  = bookingError("booking and reminder must have an id")
+	ErrNotFound        = bookingError("booking not found")
+)
@@ -0,0 +1,171 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/booking"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type fakeCalendar struct {
+	slots []*booking.Slot
+	taken map[string]bool
+}
+
+func (c *fakeCalendar) Slots(_ context.Context, _ *booking.SlotQuery) ([]*booking.Slot, error) {
+	var free []*booking.Slot
+	for _, slot := range c.slots {
+		if !c.taken[slot.ID] {
+			free = append(free, slot)
+		}
+	}
+
+	return free, nil
+}
+
+func (c *fakeCalendar) Book(_ context.Context, slotID, _ string) (*booking.Booking, error) {
+	for _, slot := range c.slots {
+		if slot.ID != slotID {
+			continue
+		}
+
+		if c.taken[slotID] {
+			return nil, booking.ErrSlotUnavailable
+		}
+		c.taken[slotID] = true
+
+		return &booking.Booking{Start: slot.Start, End: slot.End, Title: slot.Title}, nil
+	}
+
+	return nil, booking.ErrSlotUnavailable
+}
+
+type recordingSender struct {
+	sent []*wmessage.Message
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg *wmessage.Message) (*wmessage.Response, error) {
+	s.sent = append(s.sent, msg)
+
+	return &wmessage.Response{}, nil
+}
+
+func TestBooker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	calendar := &fakeCalendar{taken: map[string]bool{}, slots: []*booking.Slot{
+		{ID: "s2", Start: now.Add(26 * time.Hour), End: now.Add(27 * time.Hour), Title: "Dr. Amani"},
+		{ID: "s1", Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour), Title: "Dr. Neema"},
+	}}
+	sender := &recordingSender{}
+	store := booking.NewMemoryStore()
+	clock := now
+	booker := booking.New(calendar, sender, store,
+		booking.WithReminderOffsets(24*time.Hour, time.Hour),
+		booking.WithClock(func() time.Time { return clock }),
+	)
+
+	slots, err := booker.Offer(ctx, "255700000001", &booking.SlotQuery{Service: "consultation"})
+	if err != nil {
+		t.Fatalf("Offer() error = %v", err)
+	}
+
+	if len(slots) != 2 || slots[0].ID != "s1" {
+		t.Fatalf("Offer() slots not sorted by start: %+v", slots)
+	}
+
+	list := sender.sent[0].Interactive
+	if list == nil || list.Type != wmessage.TypeInteractiveList {
+		t.Fatalf("Offer() sent %+v, want a list", sender.sent[0])
+	}
+
+	wantSections := []*wmessage.InteractiveSection{
+		{Title: "Fri 1 Mar", Rows: []*wmessage.InteractiveSectionRow{
+			{ID: "booking:s1", Title: "10:00 - 11:00", Description: "Dr. Neema"},
+		}},
+		{Title: "Sat 2 Mar", Rows: []*wmessage.InteractiveSectionRow{
+			{ID: "booking:s2", Title: "10:00 - 11:00", Description: "Dr. Amani"},
+		}},
+	}
+	if diff := gcmp.Diff(wantSections, list.Action.Sections); diff != "" {
+		t.Errorf("sections mismatch (-want +got):\n%s", diff)
+	}
+
+	var passed bool
+	handler := booker.ListReplyHandler(message.OnListReplyMessageHook(func(context.Context,
+		*message.NotificationContext, *message.Info, *message.ListReply,
+	) error {
+		passed = true
+
+		return nil
+	}))
+
+	info := &message.Info{From: "255700000001"}
+	if err := handler.Handle(ctx, nil, info, &message.ListReply{ID: "menu:support"}); err != nil || !passed {
+		t.Fatalf("other list replies must reach next, err = %v", err)
+	}
+
+	if err := handler.Handle(ctx, nil, info, &message.ListReply{ID: "booking:s2"}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	if got := sender.sent[len(sender.sent)-1].Text.Body; got != "Your appointment on Sat 2 Mar 2024 at 10:00 is confirmed." {
+		t.Errorf("confirmation = %q", got)
+	}
+
+	// the 24h reminder is due now, the 1h one the next day.
+	clock = now.Add(2 * time.Hour)
+	sent, err := booker.SendReminders(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("SendReminders() = %d, %v, want 1", sent, err)
+	}
+
+	if sent, _ := booker.SendReminders(ctx); sent != 0 {
+		t.Errorf("reminders sent twice")
+	}
+
+	clock = now.Add(25 * time.Hour)
+	if sent, _ := booker.SendReminders(ctx); sent != 1 {
+		t.Errorf("SendReminders() = %d, want 1", sent)
+	}
+
+	if got := sender.sent[len(sender.sent)-1].Text.Body; got != "Reminder: you have an appointment on Sat 2 Mar 2024 at 10:00." {
+		t.Errorf("reminder = %q", got)
+	}
+}
+
+func TestBookerSlotTaken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	calendar := &fakeCalendar{taken: map[string]bool{"s1": true}, slots: []*booking.Slot{
+		{ID: "s1", Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour)},
+	}}
+	sender := &recordingSender{}
+	booker := booking.New(calendar, sender, booking.NewMemoryStore(),
+		booking.WithClock(func() time.Time { return now }))
+
+	_, err := booker.Select(ctx, "255700000001", &message.ListReply{ID: "booking:s1"})
+	if !errors.Is(err, booking.ErrSlotUnavailable) {
+		t.Fatalf("Select() error = %v, want ErrSlotUnavailable", err)
+	}
+
+	want := []string{"Sorry, that time was just taken.", "Sorry, there are no free times at the moment."}
+	var got []string
+	for _, msg := range sender.sent {
+		got = append(got, msg.Text.Body)
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("sent mismatch (-want +got):\n%s", diff)
+	}
+}
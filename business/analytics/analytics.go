@@ -576,6 +576,7 @@ const (
 	GranularityMonthly  Granularity = "MONTHLY"
 	GranularityLifetime Granularity = "LIFETIME"
 	GranularityMonth    Granularity = "MONTH"
+	GranularityHalfDay  Granularity = "HALF_DAY"
 )
 
 // String returns the string representation of the granularity.
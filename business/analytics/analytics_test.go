@@ -1,6 +1,10 @@
 package analytics
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+)
 
 func TestQueryParamsString(t *testing.T) {
 	t.Parallel()
@@ -116,3 +120,40 @@ func TestQueryParamsString(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	dates := LastDays(7, now)
+	if got, want := dates.Start, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("LastDays() start = %s, want %s", got, want)
+	}
+
+	month := MonthOf(now)
+	req, err := NewPricingRequest(month, GranularityMonthly, WithPricingDimensions(DimensionPricingCategory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "pricing_analytics.start(1709251200).end(1711929600).granularity(MONTHLY).phone_numbers([]).dimensions([\"PRICING_CATEGORY\"])"
+	params := MakePricingAnalyticsQueryParams(req.Start, req.End, req.Granularity, req.Options...)
+	if got := params.QueryParamsString(); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	for _, granularity := range []Granularity{GranularityMonthly, GranularityHalfHour} {
+		if _, err := NewMessagingRequest(month, granularity); !errors.Is(err, ErrInvalidGranularity) {
+			t.Errorf("%s: expected %v, got %v", granularity, ErrInvalidGranularity, err)
+		}
+	}
+
+	if _, err := NewMessagingRequest(month, GranularityHalfDay); err != nil {
+		t.Errorf("HALF_DAY: unexpected error %v", err)
+	}
+
+	if _, err := NewConversationalRequest(DateRange{Start: now, End: now}, GranularityDaily); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("expected %v, got %v", ErrInvalidDateRange, err)
+	}
+}
@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidDateRange   = errors.New("invalid analytics date range")
+	ErrInvalidGranularity = errors.New("granularity not supported by the analytics type")
+)
+
+// Dimensions supported by pricing analytics.
+const (
+	DimensionPricingCategory Dimension = "PRICING_CATEGORY"
+	DimensionPricingType     Dimension = "PRICING_TYPE"
+)
+
+// DateRange is the period analytics are fetched for. The Graph API takes the bounds as
+// UNIX timestamps, Start inclusive and End exclusive.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LastDays returns the range covering the n whole days before the day of now, in the
+// location of now.
+func LastDays(n int, now time.Time) DateRange {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	return DateRange{Start: end.AddDate(0, 0, -n), End: end}
+}
+
+// MonthOf returns the range covering the calendar month of t.
+func MonthOf(t time.Time) DateRange {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+
+	return DateRange{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+func (r DateRange) Validate() error {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return fmt.Errorf("%w: start and end are required", ErrInvalidDateRange)
+	}
+
+	if !r.Start.Before(r.End) {
+		return fmt.Errorf("%w: start %s is not before end %s", ErrInvalidDateRange,
+			r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// supportedGranularities lists the granularities accepted by each analytics type.
+var supportedGranularities = map[Type][]Granularity{ //nolint:gochecknoglobals // lookup table
+	TypeMessagingAnalytics:    {GranularityHalfDay, GranularityDay, GranularityMonth},
+	TypeConversationAnalytics: {GranularityHalfHour, GranularityDaily, GranularityMonthly},
+	TypePricingAnalytics:      {GranularityHalfHour, GranularityDaily, GranularityMonthly},
+	TypeTemplateAnalytics:     {GranularityDaily},
+}
+
+// ValidateGranularity checks that granularity is accepted by the analytics type, messaging
+// analytics for example take DAY where conversation and pricing analytics take DAILY.
+func ValidateGranularity(analyticsType Type, granularity Granularity) error {
+	supported, ok := supportedGranularities[analyticsType]
+	if !ok || slices.Contains(supported, granularity) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s does not support %s", ErrInvalidGranularity, analyticsType, granularity)
+}
+
+// NewMessagingRequest validates the range and granularity and returns a request for the
+// sent and delivered message counts.
+func NewMessagingRequest(dates DateRange, granularity Granularity,
+	options ...MessagingQueryParamsOption,
+) (*MessagingRequest, error) {
+	if err := validateRequest(TypeMessagingAnalytics, dates, granularity); err != nil {
+		return nil, err
+	}
+
+	return &MessagingRequest{
+		Start:       dates.Start.Unix(),
+		End:         dates.End.Unix(),
+		Granularity: granularity,
+		Options:     options,
+	}, nil
+}
+
+// NewConversationalRequest validates the range and granularity and returns a request for
+// the conversation counts and costs.
+func NewConversationalRequest(dates DateRange, granularity Granularity,
+	options ...ConversationalQueryParamsOption,
+) (*ConversationalRequest, error) {
+	if err := validateRequest(TypeConversationAnalytics, dates, granularity); err != nil {
+		return nil, err
+	}
+
+	return &ConversationalRequest{
+		Start:       dates.Start.Unix(),
+		End:         dates.End.Unix(),
+		Granularity: granularity,
+		Options:     options,
+	}, nil
+}
+
+// NewPricingRequest validates the range and granularity and returns a request for the
+// per message pricing volumes and costs.
+func NewPricingRequest(dates DateRange, granularity Granularity,
+	options ...PricingQueryParamsOption,
+) (*PricingRequest, error) {
+	if err := validateRequest(TypePricingAnalytics, dates, granularity); err != nil {
+		return nil, err
+	}
+
+	return &PricingRequest{
+		Start:       dates.Start.Unix(),
+		End:         dates.End.Unix(),
+		Granularity: granularity,
+		Options:     options,
+	}, nil
+}
+
+func validateRequest(analyticsType Type, dates DateRange, granularity Granularity) error {
+	if err := dates.Validate(); err != nil {
+		return err
+	}
+
+	return ValidateGranularity(analyticsType, granularity)
+}
@@ -0,0 +1,510 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package calls manages WhatsApp voice calls made with the Calling API.
+//
+// Calls placed by users are reported by webhooks and answered with the Manage actions:
+// PreAccept sets up the media connection before the call is answered, Accept answers it with
+// the SDP answer, Reject declines it and Terminate ends it. Calls to users are started with
+// Initiate, which takes the SDP offer of the business, and require the permission of the
+// user, requested with message.WithCallPermissionRequest and checked with Permissions.
+//
+//	client := calls.NewBaseClient(sender, reader)
+//	_, err := client.PreAccept(ctx, callID, sdpAnswer)
+//	_, err = client.Accept(ctx, callID, sdpAnswer)
+//	_, err = client.Terminate(ctx, callID)
+package calls
+
+//go:generate mockgen -destination=../mocks/calls/mock_calls.go -package=calls -source=calls.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const (
+	Endpoint            = "calls"
+	EndpointPermissions = "call_permissions"
+)
+
+const messagingProduct = "whatsapp"
+
+// Action is what a call request does.
+type Action string
+
+const (
+	// ActionConnect starts a call to a user.
+	ActionConnect Action = "connect"
+
+	// ActionPreAccept connects the media of a user initiated call before it is answered, so
+	// that audio flows as soon as the call is accepted.
+	ActionPreAccept Action = "pre_accept"
+	ActionAccept    Action = "accept"
+	ActionReject    Action = "reject"
+	ActionTerminate Action = "terminate"
+)
+
+const (
+	SDPTypeOffer  = "offer"
+	SDPTypeAnswer = "answer"
+)
+
+// Permission statuses of a user.
+const (
+	PermissionGranted   = "granted"
+	PermissionTemporary = "temporary"
+	PermissionNone      = "no_permission"
+)
+
+type (
+	// Session is the WebRTC session description of the business, an offer when starting a
+	// call and an answer when pre-accepting or accepting one.
+	Session struct {
+		SDPType string `json:"sdp_type"`
+		SDP     string `json:"sdp"`
+	}
+
+	// InitiateRequest starts a call to To. BizOpaqueCallbackData is returned in the call
+	// webhooks.
+	InitiateRequest struct {
+		To                    string
+		Session               *Session
+		BizOpaqueCallbackData string
+	}
+
+	// ManageRequest applies Action to the call with the given ID. Session is required by
+	// ActionPreAccept and ActionAccept.
+	ManageRequest struct {
+		CallID                string
+		Action                Action
+		Session               *Session
+		BizOpaqueCallbackData string
+	}
+
+	// InitiateResponse carries the ID of the started call.
+	InitiateResponse struct {
+		Product string      `json:"messaging_product,omitempty"`
+		Calls   []*CallInfo `json:"calls,omitempty"`
+	}
+
+	CallInfo struct {
+		ID string `json:"id"`
+	}
+
+	// SuccessResponse is returned by the call actions.
+	SuccessResponse struct {
+		Product string `json:"messaging_product,omitempty"`
+		Success bool   `json:"success"`
+	}
+
+	// PermissionsResponse reports whether the business may call a user and whether it may
+	// ask them for the permission.
+	PermissionsResponse struct {
+		Product    string              `json:"messaging_product,omitempty"`
+		Permission *Permission         `json:"permission,omitempty"`
+		Actions    []*PermissionAction `json:"actions,omitempty"`
+	}
+
+	// Permission is the call permission of a user. ExpirationTime, a unix timestamp, is set
+	// for temporary permissions.
+	Permission struct {
+		Status         string `json:"status"`
+		ExpirationTime int64  `json:"expiration_time,omitempty"`
+	}
+
+	// PermissionAction is an action the business can take, such as sending a permission
+	// request or starting a call, and the limits it is subject to.
+	PermissionAction struct {
+		ActionName       string             `json:"action_name"`
+		CanPerformAction bool               `json:"can_perform_action"`
+		Limits           []*PermissionLimit `json:"limits,omitempty"`
+	}
+
+	// PermissionLimit caps an action over TimePeriod, an ISO 8601 duration such as PT24H.
+	PermissionLimit struct {
+		TimePeriod          string `json:"time_period"`
+		MaxAllowed          int    `json:"max_allowed"`
+		CurrentUsage        int    `json:"current_usage"`
+		LimitExpirationTime int64  `json:"limit_expiration_time,omitempty"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+// CallID returns the ID of the started call.
+func (r *InitiateResponse) CallID() string {
+	if r == nil || len(r.Calls) == 0 {
+		return ""
+	}
+
+	return r.Calls[0].ID
+}
+
+// Granted reports whether the business may call the user.
+func (r *PermissionsResponse) Granted() bool {
+	return r != nil && r.Permission != nil &&
+		(r.Permission.Status == PermissionGranted || r.Permission.Status == PermissionTemporary)
+}
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Initiate(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) Manage(ctx context.Context, req *ManageRequest) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Manage(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) PreAccept(ctx context.Context, callID, sdp string) (*SuccessResponse, error) {
+	return c.Manage(ctx, answer(callID, ActionPreAccept, sdp))
+}
+
+func (c *BaseClient) Accept(ctx context.Context, callID, sdp string) (*SuccessResponse, error) {
+	return c.Manage(ctx, answer(callID, ActionAccept, sdp))
+}
+
+func (c *BaseClient) Reject(ctx context.Context, callID string) (*SuccessResponse, error) {
+	return c.Manage(ctx, &ManageRequest{CallID: callID, Action: ActionReject})
+}
+
+func (c *BaseClient) Terminate(ctx context.Context, callID string) (*SuccessResponse, error) {
+	return c.Manage(ctx, &ManageRequest{CallID: callID, Action: ActionTerminate})
+}
+
+func (c *BaseClient) Permissions(ctx context.Context, userWaID string) (*PermissionsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Permissions(ctx, c.Sender, conf, userWaID)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResponse, error) {
+	return Initiate(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) Manage(ctx context.Context, req *ManageRequest) (*SuccessResponse, error) {
+	return Manage(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) PreAccept(ctx context.Context, callID, sdp string) (*SuccessResponse, error) {
+	return c.Manage(ctx, answer(callID, ActionPreAccept, sdp))
+}
+
+func (c *Client) Accept(ctx context.Context, callID, sdp string) (*SuccessResponse, error) {
+	return c.Manage(ctx, answer(callID, ActionAccept, sdp))
+}
+
+func (c *Client) Reject(ctx context.Context, callID string) (*SuccessResponse, error) {
+	return c.Manage(ctx, &ManageRequest{CallID: callID, Action: ActionReject})
+}
+
+func (c *Client) Terminate(ctx context.Context, callID string) (*SuccessResponse, error) {
+	return c.Manage(ctx, &ManageRequest{CallID: callID, Action: ActionTerminate})
+}
+
+func (c *Client) Permissions(ctx context.Context, userWaID string) (*PermissionsResponse, error) {
+	return Permissions(ctx, c.Sender, c.Config, userWaID)
+}
+
+var (
+	ErrInitiateCall   = errors.New("failed to initiate call")
+	ErrManageCall     = errors.New("failed to manage call")
+	ErrGetPermissions = errors.New("failed to get call permissions")
+	ErrMissingCallID  = errors.New("call id is required")
+	ErrMissingUser    = errors.New("user wa id is required")
+	ErrMissingSession = errors.New("session with an sdp is required")
+	ErrInvalidAction  = errors.New("invalid call action")
+	ErrSDPType        = errors.New("session must be an sdp offer when initiating a call and an answer otherwise")
+)
+
+// Initiate starts a call to a user who granted the business the permission to call them.
+func Initiate(ctx context.Context, sender Sender, conf *config.Config, req *InitiateRequest) (*InitiateResponse, error) {
+	if req == nil || req.To == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInitiateCall, ErrMissingUser)
+	}
+
+	if err := validateSession(req.Session, SDPTypeOffer); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInitiateCall, err)
+	}
+
+	body := map[string]any{
+		"messaging_product": messagingProduct,
+		"to":                req.To,
+		"action":            ActionConnect,
+		"session":           req.Session,
+	}
+
+	if req.BizOpaqueCallbackData != "" {
+		body["biz_opaque_callback_data"] = req.BizOpaqueCallbackData
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeInitiateCall,
+		Endpoints: []string{conf.PhoneNumberID, Endpoint},
+		Body:      body,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInitiateCall, err)
+	}
+
+	return &InitiateResponse{Product: response.Product, Calls: response.Calls}, nil
+}
+
+// Manage applies an action to a call.
+func Manage(ctx context.Context, sender Sender, conf *config.Config, req *ManageRequest) (*SuccessResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManageCall, err)
+	}
+
+	body := map[string]any{
+		"messaging_product": messagingProduct,
+		"call_id":           req.CallID,
+		"action":            req.Action,
+	}
+
+	if req.Session != nil {
+		body["session"] = req.Session
+	}
+
+	if req.BizOpaqueCallbackData != "" {
+		body["biz_opaque_callback_data"] = req.BizOpaqueCallbackData
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeManageCall,
+		Endpoints: []string{conf.PhoneNumberID, Endpoint},
+		Body:      body,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManageCall, err)
+	}
+
+	return &SuccessResponse{Product: response.Product, Success: response.Success}, nil
+}
+
+// Permissions returns the call permission of the user and the limits on calling them and
+// on asking them for the permission.
+func Permissions(ctx context.Context, sender Sender, conf *config.Config, userWaID string) (*PermissionsResponse, error) {
+	if userWaID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrGetPermissions, ErrMissingUser)
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeGetCallPermissions,
+		Endpoints:   []string{conf.PhoneNumberID, EndpointPermissions},
+		QueryParams: map[string]string{"user_wa_id": userWaID},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPermissions, err)
+	}
+
+	return &PermissionsResponse{
+		Product:    response.Product,
+		Permission: response.Permission,
+		Actions:    response.Actions,
+	}, nil
+}
+
+func answer(callID string, action Action, sdp string) *ManageRequest {
+	return &ManageRequest{
+		CallID:  callID,
+		Action:  action,
+		Session: &Session{SDPType: SDPTypeAnswer, SDP: sdp},
+	}
+}
+
+func (req *ManageRequest) validate() error {
+	if req == nil || req.CallID == "" {
+		return ErrMissingCallID
+	}
+
+	switch req.Action {
+	case ActionPreAccept, ActionAccept:
+		return validateSession(req.Session, SDPTypeAnswer)
+	case ActionReject, ActionTerminate:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidAction, req.Action)
+	}
+}
+
+func validateSession(session *Session, sdpType string) error {
+	if session == nil || session.SDP == "" {
+		return ErrMissingSession
+	}
+
+	if session.SDPType != sdpType {
+		return ErrSDPType
+	}
+
+	return nil
+}
+
+type (
+	// BaseRequest manages the calls or the call permissions of the phone number, Endpoints
+	// being the phone number ID followed by Endpoint or EndpointPermissions.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response decodes the replies to call actions and permission lookups.
+	Response struct {
+		Product    string              `json:"messaging_product,omitempty"`
+		Success    bool                `json:"success,omitempty"`
+		Calls      []*CallInfo         `json:"calls,omitempty"`
+		Permission *Permission         `json:"permission,omitempty"`
+		Actions    []*PermissionAction `json:"actions,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResponse, error)
+	Manage(ctx context.Context, req *ManageRequest) (*SuccessResponse, error)
+	PreAccept(ctx context.Context, callID, sdp string) (*SuccessResponse, error)
+	Accept(ctx context.Context, callID, sdp string) (*SuccessResponse, error)
+	Reject(ctx context.Context, callID string) (*SuccessResponse, error)
+	Terminate(ctx context.Context, callID string) (*SuccessResponse, error)
+	Permissions(ctx context.Context, userWaID string) (*PermissionsResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
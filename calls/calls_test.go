@@ -0,0 +1,162 @@
+package calls_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/calls"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_Actions(t *testing.T) {
+	t.Parallel()
+
+	var bodies []map[string]any
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v20.0/phone/calls" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if body["action"] == "connect" {
+			_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","calls":[{"id":"wacid.1"}]}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","success":true}`))
+	})
+	client := calls.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	started, err := client.Initiate(ctx, &calls.InitiateRequest{
+		To:      "255700000001",
+		Session: &calls.Session{SDPType: calls.SDPTypeOffer, SDP: "v=0 offer"},
+	})
+	if err != nil || started.CallID() != "wacid.1" {
+		t.Fatalf("Initiate() = %+v, %v", started, err)
+	}
+
+	steps := []func() (*calls.SuccessResponse, error){
+		func() (*calls.SuccessResponse, error) { return client.PreAccept(ctx, "wacid.2", "v=0 answer") },
+		func() (*calls.SuccessResponse, error) { return client.Accept(ctx, "wacid.2", "v=0 answer") },
+		func() (*calls.SuccessResponse, error) { return client.Terminate(ctx, "wacid.2") },
+		func() (*calls.SuccessResponse, error) { return client.Reject(ctx, "wacid.3") },
+	}
+	for i, step := range steps {
+		if response, err := step(); err != nil || !response.Success {
+			t.Fatalf("step %d = %+v, %v", i, response, err)
+		}
+	}
+
+	answer := map[string]any{"sdp_type": "answer", "sdp": "v=0 answer"}
+	want := []map[string]any{
+		{"messaging_product": "whatsapp", "to": "255700000001", "action": "connect", "session": map[string]any{
+			"sdp_type": "offer", "sdp": "v=0 offer",
+		}},
+		{"messaging_product": "whatsapp", "call_id": "wacid.2", "action": "pre_accept", "session": answer},
+		{"messaging_product": "whatsapp", "call_id": "wacid.2", "action": "accept", "session": answer},
+		{"messaging_product": "whatsapp", "call_id": "wacid.2", "action": "terminate"},
+		{"messaging_product": "whatsapp", "call_id": "wacid.3", "action": "reject"},
+	}
+	if diff := gcmp.Diff(want, bodies); diff != "" {
+		t.Errorf("request bodies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBaseClient_Permissions(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone/call_permissions" || r.URL.Query().Get("user_wa_id") != "255700000001" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","permission":{"status":"temporary","expiration_time":1745343479},` +
+			`"actions":[{"action_name":"start_call","can_perform_action":true,` +
+			`"limits":[{"time_period":"PT24H","max_allowed":5,"current_usage":1}]}]}`))
+	})
+	client := calls.NewBaseClient(whttp.NewAnySender(), reader)
+
+	response, err := client.Permissions(context.Background(), "255700000001")
+	if err != nil {
+		t.Fatalf("Permissions() error = %v", err)
+	}
+
+	want := &calls.PermissionsResponse{
+		Product:    "whatsapp",
+		Permission: &calls.Permission{Status: calls.PermissionTemporary, ExpirationTime: 1745343479},
+		Actions: []*calls.PermissionAction{{
+			ActionName:       "start_call",
+			CanPerformAction: true,
+			Limits:           []*calls.PermissionLimit{{TimePeriod: "PT24H", MaxAllowed: 5, CurrentUsage: 1}},
+		}},
+	}
+	if diff := gcmp.Diff(want, response); diff != "" {
+		t.Errorf("Permissions() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !response.Granted() {
+		t.Error("Granted() = false for a temporary permission")
+	}
+}
+
+func TestManageValidation(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("no request expected")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client := calls.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		req  *calls.ManageRequest
+		want error
+	}{
+		{name: "no call id", req: &calls.ManageRequest{Action: calls.ActionTerminate}, want: calls.ErrMissingCallID},
+		{name: "accept without sdp", req: &calls.ManageRequest{CallID: "c", Action: calls.ActionAccept}, want: calls.ErrMissingSession},
+		{name: "accept with offer", req: &calls.ManageRequest{
+			CallID: "c", Action: calls.ActionAccept, Session: &calls.Session{SDPType: calls.SDPTypeOffer, SDP: "v=0"},
+		}, want: calls.ErrSDPType},
+		{name: "unknown action", req: &calls.ManageRequest{CallID: "c", Action: "hold"}, want: calls.ErrInvalidAction},
+	}
+
+	for _, tt := range tests {
+		if _, err := client.Manage(ctx, tt.req); !errors.Is(err, tt.want) || !errors.Is(err, calls.ErrManageCall) {
+			t.Errorf("%s: Manage() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestCallPermissionRequestMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.New("255700000001", message.WithCallPermissionRequest("May we call you about your order?", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := json.Marshal(msg.Interactive)
+	want := `{"type":"call_permission_request","action":{"name":"call_permission_request"},"body":{"text":"May we call you about your order?"}}`
+	if string(got) != want {
+		t.Errorf("interactive = %s, want %s", got, want)
+	}
+}
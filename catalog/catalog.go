@@ -0,0 +1,501 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package catalog reads the product catalogs connected to a WhatsApp Business Account and
+// manages the commerce settings of a business phone number. The products can be sent with
+// the product, product_list and catalog_message interactive messages of the message package.
+package catalog
+
+//go:generate mockgen -destination=../mocks/catalog/mock_catalog.go -package=catalog -source=catalog.go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const (
+	EndpointCatalogs         = "product_catalogs"
+	EndpointProducts         = "products"
+	EndpointCommerceSettings = "whatsapp_commerce_settings"
+)
+
+const (
+	AvailabilityInStock    = "in stock"
+	AvailabilityOutOfStock = "out of stock"
+)
+
+// DefaultProductFields are requested when a request does not list the product fields, the
+// API only returns the id otherwise.
+var DefaultProductFields = []string{ //nolint:gochecknoglobals // read only defaults
+	"id", "retailer_id", "name", "description", "availability", "condition",
+	"price", "sale_price", "currency", "image_url", "url", "brand", "visibility", "review_status",
+}
+
+type (
+	Catalog struct {
+		ID           string `json:"id"`
+		Name         string `json:"name,omitempty"`
+		Vertical     string `json:"vertical,omitempty"`
+		ProductCount int    `json:"product_count,omitempty"`
+	}
+
+	// Product is a catalog item. RetailerID is the content id used by product messages and
+	// reported back in order webhooks.
+	Product struct {
+		ID           string `json:"id"`
+		RetailerID   string `json:"retailer_id,omitempty"`
+		Name         string `json:"name,omitempty"`
+		Description  string `json:"description,omitempty"`
+		Availability string `json:"availability,omitempty"`
+		Condition    string `json:"condition,omitempty"`
+		Price        string `json:"price,omitempty"`
+		SalePrice    string `json:"sale_price,omitempty"`
+		Currency     string `json:"currency,omitempty"`
+		ImageURL     string `json:"image_url,omitempty"`
+		URL          string `json:"url,omitempty"`
+		Brand        string `json:"brand,omitempty"`
+		Visibility   string `json:"visibility,omitempty"`
+		ReviewStatus string `json:"review_status,omitempty"`
+	}
+
+	// CommerceSettings controls whether the catalog and the cart are shown to customers
+	// chatting with the business phone number.
+	CommerceSettings struct {
+		ID               string `json:"id,omitempty"`
+		IsCartEnabled    bool   `json:"is_cart_enabled"`
+		IsCatalogVisible bool   `json:"is_catalog_visible"`
+	}
+
+	// ListProductsRequest pages the products of a catalog. Filter is a JSON encoded product
+	// filter, for example {"availability":{"eq":"in stock"}}.
+	ListProductsRequest struct {
+		CatalogID string
+		Fields    []string
+		Filter    string
+		Limit     int
+		After     string
+		Before    string
+	}
+
+	ListCatalogsResponse struct {
+		Data   []*Catalog `json:"data"`
+		Paging *Paging    `json:"paging,omitempty"`
+	}
+
+	ListProductsResponse struct {
+		Data   []*Product `json:"data"`
+		Paging *Paging    `json:"paging,omitempty"`
+	}
+
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
+
+	SuccessResponse struct {
+		Success bool `json:"success"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) ListCatalogs(ctx context.Context) (*ListCatalogsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListCatalogs(ctx, c.Sender, conf)
+}
+
+func (c *BaseClient) ListProducts(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListProducts(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) ListAllProducts(ctx context.Context, req *ListProductsRequest) ([]*Product, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListAllProducts(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) GetProduct(ctx context.Context, productID string, fields ...string) (*Product, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return GetProduct(ctx, c.Sender, conf, productID, fields...)
+}
+
+func (c *BaseClient) GetCommerceSettings(ctx context.Context) (*CommerceSettings, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return GetCommerceSettings(ctx, c.Sender, conf)
+}
+
+func (c *BaseClient) UpdateCommerceSettings(ctx context.Context, settings *CommerceSettings) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return UpdateCommerceSettings(ctx, c.Sender, conf, settings)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) ListCatalogs(ctx context.Context) (*ListCatalogsResponse, error) {
+	return ListCatalogs(ctx, c.Sender, c.Config)
+}
+
+func (c *Client) ListProducts(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error) {
+	return ListProducts(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) ListAllProducts(ctx context.Context, req *ListProductsRequest) ([]*Product, error) {
+	return ListAllProducts(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) GetProduct(ctx context.Context, productID string, fields ...string) (*Product, error) {
+	return GetProduct(ctx, c.Sender, c.Config, productID, fields...)
+}
+
+func (c *Client) GetCommerceSettings(ctx context.Context) (*CommerceSettings, error) {
+	return GetCommerceSettings(ctx, c.Sender, c.Config)
+}
+
+func (c *Client) UpdateCommerceSettings(ctx context.Context, settings *CommerceSettings) (*SuccessResponse, error) {
+	return UpdateCommerceSettings(ctx, c.Sender, c.Config, settings)
+}
+
+var (
+	ErrListCatalogs           = errors.New("failed to list catalogs")
+	ErrListProducts           = errors.New("failed to list products")
+	ErrGetProduct             = errors.New("failed to get product")
+	ErrGetCommerceSettings    = errors.New("failed to get commerce settings")
+	ErrUpdateCommerceSettings = errors.New("failed to update commerce settings")
+	ErrMissingCatalogID       = errors.New("catalog id is required")
+)
+
+// ListCatalogs lists the catalogs connected to the business account.
+func ListCatalogs(ctx context.Context, sender Sender, conf *config.Config) (*ListCatalogsResponse, error) {
+	request := &BaseRequest{
+		Method:    http.MethodGet,
+		Type:      whttp.RequestTypeListCatalogs,
+		Endpoints: []string{conf.BusinessAccountID, EndpointCatalogs},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListCatalogs, err)
+	}
+
+	var catalogs []*Catalog
+	if err := response.decodeData(&catalogs); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListCatalogs, err)
+	}
+
+	return &ListCatalogsResponse{Data: catalogs, Paging: response.Paging}, nil
+}
+
+func ListProducts(ctx context.Context, sender Sender, conf *config.Config,
+	req *ListProductsRequest,
+) (*ListProductsResponse, error) {
+	if req == nil || req.CatalogID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrListProducts, ErrMissingCatalogID)
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListProducts,
+		Endpoints:   []string{req.CatalogID, EndpointProducts},
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListProducts, err)
+	}
+
+	var products []*Product
+	if err := response.decodeData(&products); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListProducts, err)
+	}
+
+	return &ListProductsResponse{Data: products, Paging: response.Paging}, nil
+}
+
+// ListAllProducts follows the after cursor until every product matching req has been fetched.
+func ListAllProducts(ctx context.Context, sender Sender, conf *config.Config,
+	req *ListProductsRequest,
+) ([]*Product, error) {
+	list := ProductListerFunc(func(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error) {
+		return ListProducts(ctx, sender, conf, req)
+	})
+
+	products, err := NewProductsPager(list, req).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// GetProduct fetches a product by its catalog item id, requesting DefaultProductFields when
+// no fields are given.
+func GetProduct(ctx context.Context, sender Sender, conf *config.Config,
+	productID string, fields ...string,
+) (*Product, error) {
+	if len(fields) == 0 {
+		fields = DefaultProductFields
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeGetProduct,
+		Endpoints:   []string{productID},
+		QueryParams: map[string]string{"fields": strings.Join(fields, ",")},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetProduct, err)
+	}
+
+	return &response.Product, nil
+}
+
+func GetCommerceSettings(ctx context.Context, sender Sender, conf *config.Config) (*CommerceSettings, error) {
+	request := &BaseRequest{
+		Method:    http.MethodGet,
+		Type:      whttp.RequestTypeGetCommerceSettings,
+		Endpoints: []string{conf.PhoneNumberID, EndpointCommerceSettings},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetCommerceSettings, err)
+	}
+
+	var settings []*CommerceSettings
+	if err := response.decodeData(&settings); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetCommerceSettings, err)
+	}
+
+	if len(settings) == 0 {
+		return &CommerceSettings{}, nil
+	}
+
+	return settings[0], nil
+}
+
+func UpdateCommerceSettings(ctx context.Context, sender Sender, conf *config.Config,
+	settings *CommerceSettings,
+) (*SuccessResponse, error) {
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeUpdateCommerceSettings,
+		Endpoints: []string{conf.PhoneNumberID, EndpointCommerceSettings},
+		QueryParams: map[string]string{
+			"is_cart_enabled":    strconv.FormatBool(settings.IsCartEnabled),
+			"is_catalog_visible": strconv.FormatBool(settings.IsCatalogVisible),
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateCommerceSettings, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+func (req *ListProductsRequest) queryParams() map[string]string {
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = DefaultProductFields
+	}
+
+	params := map[string]string{"fields": strings.Join(fields, ",")}
+	set := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	set("filter", req.Filter)
+	set("after", req.After)
+	set("before", req.Before)
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	return params
+}
+
+type (
+	// BaseRequest reads the catalogs of the business account, the products of a catalog or
+	// the commerce settings of the phone number, depending on Endpoints.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+	}
+
+	// Response decodes a product, or a page of catalogs, products or commerce settings
+	// kept raw in Data until the caller knows which one it asked for.
+	Response struct {
+		Product
+
+		Data    json.RawMessage `json:"data,omitempty"`
+		Paging  *Paging         `json:"paging,omitempty"`
+		Success bool            `json:"success,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (response *Response) decodeData(v any) error {
+	if len(response.Data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(response.Data, v); err != nil {
+		return fmt.Errorf("decode data: %w", err)
+	}
+
+	return nil
+}
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	ListCatalogs(ctx context.Context) (*ListCatalogsResponse, error)
+	ListProducts(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error)
+	ListAllProducts(ctx context.Context, req *ListProductsRequest) ([]*Product, error)
+	GetProduct(ctx context.Context, productID string, fields ...string) (*Product, error)
+	GetCommerceSettings(ctx context.Context) (*CommerceSettings, error)
+	UpdateCommerceSettings(ctx context.Context, settings *CommerceSettings) (*SuccessResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
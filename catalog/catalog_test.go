@@ -0,0 +1,95 @@
+package catalog_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/catalog"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_ListAllProducts(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"":   `{"data":[{"id":"1","retailer_id":"sku-1","name":"Tea","price":"$1.00"}],"paging":{"cursors":{"before":"b0","after":"c1"},"next":"https://graph/next"}}`,
+		"c1": `{"data":[{"id":"2","retailer_id":"sku-2","name":"Coffee","price":"$2.00"}],"paging":{"cursors":{"before":"c1","after":"c2"}}}`,
+	}
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/cat/products" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if r.URL.Query().Get("fields") == "" || r.URL.Query().Get("filter") != `{"availability":{"eq":"in stock"}}` {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("after")]))
+	})
+	client := catalog.NewBaseClient(whttp.NewAnySender(), reader)
+
+	products, err := client.ListAllProducts(context.Background(), &catalog.ListProductsRequest{
+		CatalogID: "cat",
+		Filter:    `{"availability":{"eq":"in stock"}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, product := range products {
+		ids = append(ids, product.RetailerID)
+	}
+
+	if diff := gcmp.Diff([]string{"sku-1", "sku-2"}, ids); diff != "" {
+		t.Errorf("retailer ids mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBaseClient_CommerceSettings(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone/whatsapp_commerce_settings" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			if r.URL.Query().Get("is_catalog_visible") != "true" || r.URL.Query().Get("is_cart_enabled") != "false" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"success":true}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":[{"id":"s1","is_cart_enabled":true,"is_catalog_visible":true}]}`))
+	})
+	client := catalog.NewBaseClient(whttp.NewAnySender(), reader)
+
+	settings, err := client.GetCommerceSettings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &catalog.CommerceSettings{ID: "s1", IsCartEnabled: true, IsCatalogVisible: true}
+	if diff := gcmp.Diff(want, settings); diff != "" {
+		t.Errorf("settings mismatch (-want +got):\n%s", diff)
+	}
+
+	resp, err := client.UpdateCommerceSettings(context.Background(), &catalog.CommerceSettings{IsCatalogVisible: true})
+	if err != nil || !resp.Success {
+		t.Errorf("UpdateCommerceSettings() = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package catalog
+
+import (
+	"context"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type (
+	// ProductLister lists the products of a catalog, it is implemented by BaseClient and
+	// Client.
+	ProductLister interface {
+		ListProducts(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error)
+	}
+
+	ProductListerFunc func(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error)
+)
+
+func (fn ProductListerFunc) ListProducts(ctx context.Context, req *ListProductsRequest,
+) (*ListProductsResponse, error) {
+	return fn(ctx, req)
+}
+
+// NewProductsPager creates a pager over the products matching req. The Limit of req sets
+// the page size and its After cursor, when set, resumes a previous walk from that page.
+func NewProductsPager(lister ProductLister, req *ListProductsRequest) *whttp.Pager[*Product] {
+	page := &ListProductsRequest{}
+	if req != nil {
+		*page = *req
+	}
+	page.Before = ""
+
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*Product], error) {
+		r := *page
+		r.After = after
+
+		response, err := lister.ListProducts(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*Product]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, page.After)
+}
@@ -0,0 +1,106 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Command whatsapp-fixtures writes sample webhook notification payloads, one per supported
+// change field and message type, generated from the webhooks notification types.
+//
+// Usage:
+//
+//	whatsapp-fixtures -list
+//	whatsapp-fixtures -name messages/text
+//	whatsapp-fixtures -out ./testdata/webhooks -seed 7
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks/fixtures"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "whatsapp-fixtures:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		out   = flag.String("out", "", "directory to write <name>.json files to, prints to stdout when empty")
+		seed  = flag.Uint64("seed", 1, "seed for the fake data, the same seed produces the same payloads")
+		name  = flag.String("name", "", "only emit the fixture with this name")
+		list  = flag.Bool("list", false, "list the fixture names and exit")
+		stamp = flag.Int64("time", 0, "unix time used for timestamps, defaults to now")
+	)
+	flag.Parse()
+
+	now := time.Now()
+	if *stamp > 0 {
+		now = time.Unix(*stamp, 0)
+	}
+
+	all := fixtures.NewGenerator(*seed, now).All()
+
+	if *list {
+		for _, fixture := range all {
+			fmt.Printf("%-45s %s\n", fixture.Name, fixture.Field)
+		}
+
+		return nil
+	}
+
+	found := false
+	for _, fixture := range all {
+		if *name != "" && fixture.Name != *name {
+			continue
+		}
+		found = true
+
+		payload, err := fixture.JSON()
+		if err != nil {
+			return err
+		}
+
+		if *out == "" {
+			fmt.Printf("%s\n", payload)
+
+			continue
+		}
+
+		path := filepath.Join(*out, strings.ReplaceAll(fixture.Name, "/", "_")+".json")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:mnd,gosec // output dir
+			return err
+		}
+
+		if err := os.WriteFile(path, append(payload, '\n'), 0o644); err != nil { //nolint:mnd,gosec // fixture file
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no fixture named %q, run with -list to see the available names", *name)
+	}
+
+	return nil
+}
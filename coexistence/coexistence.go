@@ -0,0 +1,202 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package coexistence contains helpers for phone numbers that are used on the WhatsApp
+// Business app and the Cloud API at the same time.
+//
+// Numbers onboarded in coexistence mode keep working on the phone app, and some Cloud API
+// operations are not available to them. A Guard rejects those operations with a typed
+// error before a request is made, and a Detector finds out whether the configured number
+// is a coexistence number. Sync asks for the contacts and the conversation history of the
+// app to be shared with the Cloud API.
+//
+// The related webhook fields (smb_app_state_sync, smb_message_echoes and history) are
+// decoded by the webhooks/coexistence and webhooks/message packages.
+package coexistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/piusalfred/whatsapp/phonenumber"
+)
+
+// Operation names a Cloud API capability that may not be available in coexistence mode.
+type Operation string
+
+const (
+	OperationBlockUsers        Operation = "block_users"
+	OperationDeregister        Operation = "deregister"
+	OperationGroups            Operation = "groups"
+	OperationThroughputUpgrade Operation = "throughput_upgrade"
+	OperationCalling           Operation = "calling"
+)
+
+// DefaultUnsupportedOperations is the set of operations a Guard rejects when none are
+// given. Availability changes as Meta expands coexistence, so callers that know better
+// can pass their own list to NewGuard.
+func DefaultUnsupportedOperations() []Operation {
+	return []Operation{
+		OperationBlockUsers,
+		OperationDeregister,
+		OperationGroups,
+		OperationThroughputUpgrade,
+		OperationCalling,
+	}
+}
+
+// Guard rejects operations that are not supported for coexistence numbers.
+type Guard struct {
+	unsupported map[Operation]struct{}
+}
+
+// NewGuard creates a Guard that rejects the given operations. With no operations it uses
+// DefaultUnsupportedOperations.
+func NewGuard(operations ...Operation) *Guard {
+	if len(operations) == 0 {
+		operations = DefaultUnsupportedOperations()
+	}
+
+	unsupported := make(map[Operation]struct{}, len(operations))
+	for _, op := range operations {
+		unsupported[op] = struct{}{}
+	}
+
+	return &Guard{unsupported: unsupported}
+}
+
+// Supported reports whether op can be used on a coexistence number.
+func (g *Guard) Supported(op Operation) bool {
+	_, ok := g.unsupported[op]
+
+	return !ok
+}
+
+// Check returns an *UnsupportedOperationError when number is a coexistence number and
+// op is not supported for it. Numbers that are not in coexistence mode always pass.
+func (g *Guard) Check(number *phonenumber.PhoneNumber, op Operation) error {
+	if number == nil || !number.IsCoexistence() || g.Supported(op) {
+		return nil
+	}
+
+	return &UnsupportedOperationError{PhoneNumberID: number.ID, Operation: op}
+}
+
+// PhoneNumberGetter fetches the details of the configured phone number. phonenumber.Service
+// implementations satisfy it.
+type PhoneNumberGetter interface {
+	Get(ctx context.Context, request *phonenumber.GetRequest) (*phonenumber.PhoneNumber, error)
+}
+
+// Detector looks up whether the configured phone number is in coexistence mode. The
+// result is cached after the first successful lookup, call Reset to force a new one.
+type Detector struct {
+	getter PhoneNumberGetter
+	guard  *Guard
+
+	mu     sync.Mutex
+	number *phonenumber.PhoneNumber
+}
+
+// NewDetector creates a Detector. If guard is nil a Guard with the default operations is used.
+func NewDetector(getter PhoneNumberGetter, guard *Guard) *Detector {
+	if guard == nil {
+		guard = NewGuard()
+	}
+
+	return &Detector{getter: getter, guard: guard}
+}
+
+// PhoneNumber returns the configured phone number including the coexistence fields.
+func (d *Detector) PhoneNumber(ctx context.Context) (*phonenumber.PhoneNumber, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.number != nil {
+		return d.number, nil
+	}
+
+	number, err := d.getter.Get(ctx, &phonenumber.GetRequest{
+		Fields: []string{
+			"id",
+			"display_phone_number",
+			"verified_name",
+			"quality_rating",
+			phonenumber.FieldIsOnBizApp,
+			phonenumber.FieldPlatformType,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("detect coexistence: %w", err)
+	}
+	d.number = number
+
+	return number, nil
+}
+
+// IsCoexistence reports whether the configured number is a coexistence number.
+func (d *Detector) IsCoexistence(ctx context.Context) (bool, error) {
+	number, err := d.PhoneNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return number.IsCoexistence(), nil
+}
+
+// Check looks up the configured number and checks op against the guard.
+func (d *Detector) Check(ctx context.Context, op Operation) error {
+	number, err := d.PhoneNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.guard.Check(number, op)
+}
+
+// Reset drops the cached phone number.
+func (d *Detector) Reset() {
+	d.mu.Lock()
+	d.number = nil
+	d.mu.Unlock()
+}
+
+// UnsupportedOperationError is returned when an operation is attempted on a coexistence
+// number that does not support it. It matches ErrUnsupportedOperation with errors.Is.
+type UnsupportedOperationError struct {
+	PhoneNumberID string
+	Operation     Operation
+}
+
+func (e *UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("%s: %s on phone number %s", ErrUnsupportedOperation, e.Operation, e.PhoneNumberID)
+}
+
+func (e *UnsupportedOperationError) Unwrap() error {
+	return ErrUnsupportedOperation
+}
+
+// coexistenceError is a custom error type for coexistence errors.
+type coexistenceError string
+
+func (e coexistenceError) Error() string {
+	return string(e)
+}
+
+const ErrUnsupportedOperation = coexistenceError("operation not supported in coexistence mode")
@@ -0,0 +1,215 @@
+package coexistence_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/coexistence"
+	"github.com/piusalfred/whatsapp/phonenumber"
+)
+
+func coexistenceNumber() *phonenumber.PhoneNumber {
+	return &phonenumber.PhoneNumber{ID: "phone", IsOnBizApp: true, PlatformType: phonenumber.PlatformTypeCloudAPI}
+}
+
+func TestGuard_Check(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		guard   *coexistence.Guard
+		number  *phonenumber.PhoneNumber
+		op      coexistence.Operation
+		wantErr bool
+	}{
+		{
+			name:    "groups rejected by default",
+			guard:   coexistence.NewGuard(),
+			number:  coexistenceNumber(),
+			op:      coexistence.OperationGroups,
+			wantErr: true,
+		},
+		{
+			name:    "calling rejected by default",
+			guard:   coexistence.NewGuard(),
+			number:  coexistenceNumber(),
+			op:      coexistence.OperationCalling,
+			wantErr: true,
+		},
+		{
+			name:   "unknown operation allowed",
+			guard:  coexistence.NewGuard(),
+			number: coexistenceNumber(),
+			op:     "send_message",
+		},
+		{
+			name:   "custom list allows default operations",
+			guard:  coexistence.NewGuard(coexistence.OperationDeregister),
+			number: coexistenceNumber(),
+			op:     coexistence.OperationGroups,
+		},
+		{
+			name:    "custom list rejects its operations",
+			guard:   coexistence.NewGuard(coexistence.OperationDeregister),
+			number:  coexistenceNumber(),
+			op:      coexistence.OperationDeregister,
+			wantErr: true,
+		},
+		{
+			name:   "app only number allowed",
+			guard:  coexistence.NewGuard(),
+			number: &phonenumber.PhoneNumber{ID: "phone", IsOnBizApp: true},
+			op:     coexistence.OperationGroups,
+		},
+		{
+			name:   "cloud api number allowed",
+			guard:  coexistence.NewGuard(),
+			number: &phonenumber.PhoneNumber{ID: "phone", PlatformType: phonenumber.PlatformTypeCloudAPI},
+			op:     coexistence.OperationGroups,
+		},
+		{
+			name:  "nil number allowed",
+			guard: coexistence.NewGuard(),
+			op:    coexistence.OperationGroups,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.guard.Check(tt.number, tt.op)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, coexistence.ErrUnsupportedOperation) {
+				t.Errorf("Check() error = %v, want ErrUnsupportedOperation", err)
+			}
+		})
+	}
+}
+
+func TestUnsupportedOperationError(t *testing.T) {
+	t.Parallel()
+
+	err := coexistence.NewGuard().Check(coexistenceNumber(), coexistence.OperationBlockUsers)
+
+	var opErr *coexistence.UnsupportedOperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("errors.As(%v) = false, want *UnsupportedOperationError", err)
+	}
+
+	want := &coexistence.UnsupportedOperationError{PhoneNumberID: "phone", Operation: coexistence.OperationBlockUsers}
+	if diff := gcmp.Diff(want, opErr); diff != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(err, coexistence.ErrUnsupportedOperation) {
+		t.Errorf("errors.Is(%v, ErrUnsupportedOperation) = false", err)
+	}
+}
+
+type phoneNumberGetter struct {
+	number *phonenumber.PhoneNumber
+	err    error
+	calls  int
+	fields []string
+}
+
+func (g *phoneNumberGetter) Get(_ context.Context, request *phonenumber.GetRequest) (*phonenumber.PhoneNumber, error) {
+	g.calls++
+	g.fields = request.Fields
+
+	return g.number, g.err
+}
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	errLookup := errors.New("lookup failed")
+
+	tests := []struct {
+		name     string
+		number   *phonenumber.PhoneNumber
+		err      error
+		want     bool
+		wantErr  error
+		checkErr error
+	}{
+		{
+			name:     "coexistence number",
+			number:   coexistenceNumber(),
+			want:     true,
+			checkErr: coexistence.ErrUnsupportedOperation,
+		},
+		{
+			name:   "cloud api number",
+			number: &phonenumber.PhoneNumber{ID: "phone", PlatformType: phonenumber.PlatformTypeCloudAPI},
+		},
+		{
+			name:   "on premise number on the app",
+			number: &phonenumber.PhoneNumber{ID: "phone", IsOnBizApp: true, PlatformType: phonenumber.PlatformTypeOnPremise},
+		},
+		{
+			name:     "lookup error",
+			err:      errLookup,
+			wantErr:  errLookup,
+			checkErr: errLookup,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			getter := &phoneNumberGetter{number: tt.number, err: tt.err}
+			detector := coexistence.NewDetector(getter, nil)
+
+			got, err := detector.IsCoexistence(context.Background())
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Fatalf("IsCoexistence() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			if err := detector.Check(context.Background(), coexistence.OperationGroups); !errors.Is(err, tt.checkErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.checkErr)
+			}
+
+			for _, field := range []string{phonenumber.FieldIsOnBizApp, phonenumber.FieldPlatformType} {
+				if !slices.Contains(getter.fields, field) {
+					t.Errorf("requested fields %v, missing %s", getter.fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestDetector_Reset(t *testing.T) {
+	t.Parallel()
+
+	getter := &phoneNumberGetter{number: coexistenceNumber()}
+	detector := coexistence.NewDetector(getter, nil)
+
+	for range 2 {
+		if _, err := detector.IsCoexistence(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if getter.calls != 1 {
+		t.Errorf("calls = %d, want the number cached after the first lookup", getter.calls)
+	}
+
+	detector.Reset()
+	getter.number = &phonenumber.PhoneNumber{ID: "phone"}
+
+	got, err := detector.IsCoexistence(context.Background())
+	if err != nil || got || getter.calls != 2 {
+		t.Errorf("after Reset IsCoexistence() = %v, %v with %d calls, want false after a new lookup",
+			got, err, getter.calls)
+	}
+}
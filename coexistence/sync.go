@@ -0,0 +1,222 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package coexistence
+
+//go:generate mockgen -destination=../mocks/coexistence/mock_coexistence.go -package=coexistence -source=sync.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const EndpointSMBAppData = "smb_app_data"
+
+// SyncType selects what a sync request shares from the WhatsApp Business app. The data is
+// delivered on the webhook field of the same name.
+type SyncType string
+
+const (
+	// SyncTypeContacts shares the address book of the app, delivered as contact events on
+	// the smb_app_state_sync field.
+	SyncTypeContacts SyncType = "smb_app_state_sync"
+
+	// SyncTypeHistory shares past conversations, delivered on the history field.
+	SyncTypeHistory SyncType = "history"
+)
+
+const messagingProduct = "whatsapp"
+
+var (
+	ErrSync            = errors.New("failed to request app data sync")
+	ErrInvalidSyncType = errors.New("invalid sync type")
+)
+
+type (
+	// SyncResponse acknowledges a sync request. The data itself arrives through webhooks.
+	SyncResponse struct {
+		Product   string `json:"messaging_product,omitempty"`
+		RequestID string `json:"request_id,omitempty"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Sync(ctx context.Context, syncType SyncType) (*SyncResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Sync(ctx, c.Sender, conf, syncType)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Sync(ctx context.Context, syncType SyncType) (*SyncResponse, error) {
+	return Sync(ctx, c.Sender, c.Config, syncType)
+}
+
+// Sync asks WhatsApp to share the contacts or the conversation history of the WhatsApp
+// Business app with the Cloud API. It is called once after onboarding a coexistence number,
+// contacts first, and has to be done within 24 hours of it. Later changes to the contacts
+// are delivered as they happen.
+func Sync(ctx context.Context, sender Sender, conf *config.Config, syncType SyncType) (*SyncResponse, error) {
+	if syncType != SyncTypeContacts && syncType != SyncTypeHistory {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSyncType, syncType)
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeSyncAppData,
+		Endpoints: []string{conf.PhoneNumberID, EndpointSMBAppData},
+		Body: map[string]any{
+			"messaging_product": messagingProduct,
+			"sync_type":         syncType,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSync, err)
+	}
+
+	return &SyncResponse{Product: response.Product, RequestID: response.RequestID}, nil
+}
+
+type (
+	// BaseRequest starts a sync of the WhatsApp Business app data of the phone number.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response carries the ID of the sync request.
+	Response struct {
+		Product   string `json:"messaging_product,omitempty"`
+		RequestID string `json:"request_id,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	Sync(ctx context.Context, syncType SyncType) (*SyncResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
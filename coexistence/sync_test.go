@@ -0,0 +1,44 @@
+package coexistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/coexistence"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_Sync(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v20.0/phone/smb_app_data" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["sync_type"] != "smb_app_state_sync" ||
+			body["messaging_product"] != "whatsapp" {
+			t.Errorf("body = %v, %v", body, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","request_id":"req-1"}`))
+	})
+	client := coexistence.NewBaseClient(whttp.NewAnySender(), reader)
+
+	response, err := client.Sync(context.Background(), coexistence.SyncTypeContacts)
+	if err != nil || response.RequestID != "req-1" {
+		t.Fatalf("Sync() = %+v, %v", response, err)
+	}
+
+	if _, err := client.Sync(context.Background(), "calls"); !errors.Is(err, coexistence.ErrInvalidSyncType) {
+		t.Errorf("Sync(calls) error = %v, want ErrInvalidSyncType", err)
+	}
+}
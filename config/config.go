@@ -32,6 +32,7 @@ type (
 		AccessToken       string
 		PhoneNumberID     string
 		BusinessAccountID string
+		AppID             string
 		AppSecret         string
 		SecureRequests    bool
 	}
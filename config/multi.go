@@ -0,0 +1,101 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type (
+	// MultiReader reads the Config of one tenant, keyed by a tenant or WhatsApp Business
+	// Account ID. Platforms that manage many customer accounts implement it on top of their
+	// tenant store.
+	MultiReader interface {
+		ReadTenant(ctx context.Context, tenantID string) (*Config, error)
+	}
+
+	MultiReaderFunc func(ctx context.Context, tenantID string) (*Config, error)
+
+	// StaticMultiReader is a MultiReader backed by a fixed set of configs. It is safe for
+	// concurrent use.
+	StaticMultiReader struct {
+		mu      sync.RWMutex
+		configs map[string]*Config
+	}
+)
+
+var (
+	_ MultiReader = (MultiReaderFunc)(nil)
+	_ MultiReader = (*StaticMultiReader)(nil)
+)
+
+func (fn MultiReaderFunc) ReadTenant(ctx context.Context, tenantID string) (*Config, error) {
+	return fn(ctx, tenantID)
+}
+
+// NewStaticMultiReader creates a StaticMultiReader with the given configs keyed by tenant ID.
+func NewStaticMultiReader(configs map[string]*Config) *StaticMultiReader {
+	r := &StaticMultiReader{configs: make(map[string]*Config, len(configs))}
+	for tenantID, conf := range configs {
+		r.Set(tenantID, conf)
+	}
+
+	return r
+}
+
+// ReadTenant returns a copy of the tenant Config or ErrTenantNotFound.
+func (r *StaticMultiReader) ReadTenant(_ context.Context, tenantID string) (*Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conf, ok := r.configs[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTenantNotFound, tenantID)
+	}
+
+	c := *conf
+
+	return &c, nil
+}
+
+// Set adds or replaces the Config of a tenant.
+func (r *StaticMultiReader) Set(tenantID string, conf *Config) {
+	c := *conf
+
+	r.mu.Lock()
+	r.configs[tenantID] = &c
+	r.mu.Unlock()
+}
+
+// Delete removes the Config of a tenant.
+func (r *StaticMultiReader) Delete(tenantID string) {
+	r.mu.Lock()
+	delete(r.configs, tenantID)
+	r.mu.Unlock()
+}
+
+// TenantReader returns a Reader that reads the Config of tenantID from multi.
+func TenantReader(multi MultiReader, tenantID string) Reader {
+	return ReaderFunc(func(ctx context.Context) (*Config, error) {
+		return multi.ReadTenant(ctx, tenantID)
+	})
+}
@@ -0,0 +1,137 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package config
+
+import (
+	"context"
+)
+
+type (
+	// Override replaces Config fields for the requests made with a context carrying it, so
+	// one client can serve several phone numbers or tenants. Empty fields leave the Config
+	// value unchanged.
+	Override struct {
+		PhoneNumberID     string
+		BusinessAccountID string
+		AccessToken       string
+		AppSecret         string
+	}
+
+	// OverrideReader is a Reader that applies the Override set in the context on top of the
+	// Config returned by the wrapped Reader.
+	OverrideReader struct {
+		reader Reader
+	}
+)
+
+var _ Reader = (*OverrideReader)(nil)
+
+// NewOverrideReader creates an OverrideReader.
+func NewOverrideReader(reader Reader) *OverrideReader {
+	return &OverrideReader{reader: reader}
+}
+
+// Read returns the wrapped Config with the context Override applied.
+func (r *OverrideReader) Read(ctx context.Context) (*Config, error) {
+	conf, err := r.reader.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyContextOverride(ctx, conf), nil
+}
+
+// Apply returns a copy of conf with the non-empty fields of the Override. conf is returned
+// unchanged when the Override is empty.
+func (o Override) Apply(conf *Config) *Config {
+	if conf == nil || o == (Override{}) {
+		return conf
+	}
+
+	c := *conf
+	if o.PhoneNumberID != "" {
+		c.PhoneNumberID = o.PhoneNumberID
+	}
+
+	if o.BusinessAccountID != "" {
+		c.BusinessAccountID = o.BusinessAccountID
+	}
+
+	if o.AccessToken != "" {
+		c.AccessToken = o.AccessToken
+	}
+
+	if o.AppSecret != "" {
+		c.AppSecret = o.AppSecret
+	}
+
+	return &c
+}
+
+type overrideContextKey struct{}
+
+// WithContextOverride sets the Override to use for requests made with the returned context.
+// Fields left empty keep the value of an Override already set in ctx.
+//
+//	ctx = config.WithContextOverride(ctx, config.Override{
+//		PhoneNumberID: tenant.PhoneNumberID,
+//		AccessToken:   tenant.AccessToken,
+//	})
+//	resp, err := client.SendText(ctx, request)
+func WithContextOverride(ctx context.Context, override Override) context.Context {
+	if parent, ok := OverrideFromContext(ctx); ok {
+		override = Override{
+			PhoneNumberID:     firstNonEmpty(override.PhoneNumberID, parent.PhoneNumberID),
+			BusinessAccountID: firstNonEmpty(override.BusinessAccountID, parent.BusinessAccountID),
+			AccessToken:       firstNonEmpty(override.AccessToken, parent.AccessToken),
+			AppSecret:         firstNonEmpty(override.AppSecret, parent.AppSecret),
+		}
+	}
+
+	return context.WithValue(ctx, overrideContextKey{}, override)
+}
+
+// OverrideFromContext returns the Override set by WithContextOverride.
+func OverrideFromContext(ctx context.Context) (Override, bool) {
+	override, ok := ctx.Value(overrideContextKey{}).(Override)
+
+	return override, ok
+}
+
+// ApplyContextOverride returns conf with the Override set in ctx applied. Clients that
+// cache their Config call it per request.
+func ApplyContextOverride(ctx context.Context, conf *Config) *Config {
+	override, ok := OverrideFromContext(ctx)
+	if !ok {
+		return conf
+	}
+
+	return override.Apply(conf)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,62 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+func TestOverrideReader_Read(t *testing.T) {
+	t.Parallel()
+
+	base := &config.Config{
+		BaseURL:           "https://graph.facebook.com",
+		APIVersion:        "v20.0",
+		AccessToken:       "shared-token",
+		PhoneNumberID:     "default-phone",
+		BusinessAccountID: "default-waba",
+	}
+
+	reader := config.NewOverrideReader(config.ReaderFunc(func(_ context.Context) (*config.Config, error) {
+		return base, nil
+	}))
+
+	got, err := reader.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != base {
+		t.Errorf("Read() without an override = %+v, want the wrapped config", got)
+	}
+
+	ctx := config.WithContextOverride(context.Background(), config.Override{
+		PhoneNumberID: "tenant-phone",
+		AccessToken:   "tenant-token",
+	})
+	ctx = config.WithContextOverride(ctx, config.Override{PhoneNumberID: "support-phone"})
+
+	got, err = reader.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &config.Config{
+		BaseURL:           "https://graph.facebook.com",
+		APIVersion:        "v20.0",
+		AccessToken:       "tenant-token",
+		PhoneNumberID:     "support-phone",
+		BusinessAccountID: "default-waba",
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("Read() mismatch (-want +got):\n%s", diff)
+	}
+
+	if base.PhoneNumberID != "default-phone" || base.AccessToken != "shared-token" {
+		t.Errorf("wrapped config was modified: %+v", base)
+	}
+}
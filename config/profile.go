@@ -0,0 +1,177 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type (
+	// Profile describes one phone number under a WhatsApp Business Account. Businesses that
+	// run several lines (sales, support, alerts) from one account keep the shared settings
+	// in a single Config and a Profile per line.
+	//
+	// ProfileReader applies the PhoneNumberID of the selected profile. DisplayName is the
+	// name customers see for the number, it is set on the number in WhatsApp Manager and
+	// kept here to tell the lines apart in logs and reports. MessagesPerSecond is the
+	// throughput of the number, applied by a limiter created with ratelimit.WithProfiles,
+	// and DefaultTemplate is sent by message.NewProfileTemplateMessage.
+	Profile struct {
+		Name              string       `json:"name"`
+		PhoneNumberID     string       `json:"phone_number_id"`
+		DisplayName       string       `json:"display_name,omitempty"`
+		MessagesPerSecond int          `json:"messages_per_second,omitempty"`
+		DefaultTemplate   *TemplateRef `json:"default_template,omitempty"`
+	}
+
+	// TemplateRef names an approved template and its language.
+	TemplateRef struct {
+		Name     string `json:"name"`
+		Language string `json:"language"`
+	}
+
+	// ProfileReader is a Reader that applies the profile selected in the context, or the
+	// default profile, on top of the Config returned by the wrapped Reader.
+	ProfileReader struct {
+		reader         Reader
+		profiles       map[string]*Profile
+		defaultProfile string
+	}
+)
+
+var _ Reader = (*ProfileReader)(nil)
+
+// NewProfileReader creates a ProfileReader. defaultProfile may be empty, in which case
+// the wrapped Config is used unchanged when no profile is selected.
+func NewProfileReader(reader Reader, defaultProfile string, profiles ...*Profile) (*ProfileReader, error) {
+	byName := make(map[string]*Profile, len(profiles))
+	for _, profile := range profiles {
+		if profile == nil || profile.Name == "" || profile.PhoneNumberID == "" {
+			return nil, fmt.Errorf("%w: name and phone number id are required", ErrInvalidProfile)
+		}
+
+		if _, ok := byName[profile.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate profile %q", ErrInvalidProfile, profile.Name)
+		}
+		byName[profile.Name] = profile
+	}
+
+	if _, ok := byName[defaultProfile]; defaultProfile != "" && !ok {
+		return nil, fmt.Errorf("%w: default %q", ErrProfileNotFound, defaultProfile)
+	}
+
+	return &ProfileReader{
+		reader:         reader,
+		profiles:       byName,
+		defaultProfile: defaultProfile,
+	}, nil
+}
+
+// Read returns a copy of the wrapped Config with the PhoneNumberID of the selected profile.
+func (r *ProfileReader) Read(ctx context.Context) (*Config, error) {
+	conf, err := r.reader.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Profile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return conf, nil
+	}
+
+	c := *conf
+	c.PhoneNumberID = profile.PhoneNumberID
+
+	return &c, nil
+}
+
+// Profile returns the profile selected by WithProfile, the default profile, or nil when
+// neither is set.
+func (r *ProfileReader) Profile(ctx context.Context) (*Profile, error) {
+	name, ok := ProfileFromContext(ctx)
+	if !ok {
+		name = r.defaultProfile
+	}
+
+	if name == "" {
+		return nil, nil //nolint:nilnil // no profile selected
+	}
+
+	profile, ok := r.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, name)
+	}
+
+	return profile, nil
+}
+
+// Profiles returns all profiles sorted by name.
+func (r *ProfileReader) Profiles() []*Profile {
+	profiles := make([]*Profile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles
+}
+
+type profileContextKey struct{}
+
+// WithProfile selects the profile to use for requests made with the returned context.
+// Messages select their profile with message.WithProfile, which uses this underneath.
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileContextKey{}, name)
+}
+
+// ProfileFromContext returns the profile name set by WithProfile.
+func ProfileFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(profileContextKey{}).(string)
+
+	return name, ok && name != ""
+}
+
+// configError is a custom error type for config errors.
+type configError string
+
+func (e configError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrProfileNotFound   = configError("profile not found")
+	ErrInvalidProfile    = configError("invalid profile")
+	ErrTokenRefresh      = configError("access token refresh failed")
+	ErrEmptyToken        = configError("refreshed access token is empty")
+	ErrInvalidConfig     = configError("invalid config")
+	ErrMissingField      = configError("required field is missing")
+	ErrInvalidBaseURL    = configError("invalid base url")
+	ErrInvalidAPIVersion = configError("invalid api version")
+	ErrTenantNotFound    = configError("tenant not found")
+)
@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+func TestProfileReader_Read(t *testing.T) {
+	t.Parallel()
+
+	base := &config.Config{AccessToken: "token", PhoneNumberID: "base-phone", BusinessAccountID: "waba"}
+	wrapped := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return base, nil
+	})
+
+	profiles := []*config.Profile{
+		{Name: "sales", PhoneNumberID: "sales-phone", MessagesPerSecond: 20},
+		{Name: "support", PhoneNumberID: "support-phone", DefaultTemplate: &config.TemplateRef{
+			Name:     "ticket_update",
+			Language: "en_US",
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		defaultProfile string
+		selected       string
+		want           string
+		wantProfile    *config.Profile
+		wantErr        error
+	}{
+		{name: "no profile", want: "base-phone"},
+		{name: "default profile", defaultProfile: "sales", want: "sales-phone", wantProfile: profiles[0]},
+		{
+			name:           "selected profile",
+			defaultProfile: "sales",
+			selected:       "support",
+			want:           "support-phone",
+			wantProfile:    profiles[1],
+		},
+		{name: "unknown profile", selected: "alerts", wantErr: config.ErrProfileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := config.NewProfileReader(wrapped, tt.defaultProfile, profiles...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if tt.selected != "" {
+				ctx = config.WithProfile(ctx, tt.selected)
+			}
+
+			got, err := reader.Read(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.PhoneNumberID != tt.want || got.AccessToken != "token" {
+				t.Errorf("Read() = %+v, want phone number %q and the shared token", got, tt.want)
+			}
+
+			profile, err := reader.Profile(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := gcmp.Diff(tt.wantProfile, profile); diff != "" {
+				t.Errorf("Profile() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if base.PhoneNumberID != "base-phone" {
+		t.Errorf("Read() modified the wrapped config: %+v", base)
+	}
+}
+
+func TestNewProfileReader(t *testing.T) {
+	t.Parallel()
+
+	wrapped := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{}, nil
+	})
+
+	tests := []struct {
+		name           string
+		defaultProfile string
+		profiles       []*config.Profile
+		wantErr        error
+	}{
+		{name: "missing phone number", profiles: []*config.Profile{{Name: "sales"}}, wantErr: config.ErrInvalidProfile},
+		{
+			name: "duplicate",
+			profiles: []*config.Profile{
+				{Name: "sales", PhoneNumberID: "1"},
+				{Name: "sales", PhoneNumberID: "2"},
+			},
+			wantErr: config.ErrInvalidProfile,
+		},
+		{
+			name:           "unknown default",
+			defaultProfile: "support",
+			profiles:       []*config.Profile{{Name: "sales", PhoneNumberID: "1"}},
+			wantErr:        config.ErrProfileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := config.NewProfileReader(wrapped, tt.defaultProfile, tt.profiles...); !errors.Is(err,
+				tt.wantErr) {
+				t.Errorf("NewProfileReader() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
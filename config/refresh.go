@@ -0,0 +1,209 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshLeeway is how long before it expires a token is refreshed unless configured
+// otherwise.
+const DefaultRefreshLeeway = 24 * time.Hour
+
+// DefaultRefreshRetryInterval is how long after a failed refresh the next one is attempted
+// while the current token is still valid, unless configured otherwise.
+const DefaultRefreshRetryInterval = time.Minute
+
+type (
+	// Token is an access token. A zero ExpiresAt means the token does not expire.
+	Token struct {
+		Value     string
+		ExpiresAt time.Time
+	}
+
+	// TokenRefresher exchanges the access token current for a fresh one. conf is the Config
+	// read from the wrapped Reader, it carries the app credentials. See
+	// auth.Client.ConfigTokenRefresher.
+	TokenRefresher interface {
+		RefreshToken(ctx context.Context, conf *Config, current string) (*Token, error)
+	}
+
+	TokenRefresherFunc func(ctx context.Context, conf *Config, current string) (*Token, error)
+
+	AutoRefreshOption func(*AutoRefreshReader)
+
+	// AutoRefreshReader is a Reader that replaces the access token of the Config returned
+	// by the wrapped Reader with one it keeps fresh. The token of the wrapped Config is
+	// exchanged on the first Read, then refreshed when it is about to expire. Refreshes are
+	// serialized, concurrent reads wait for the refresh in progress.
+	//
+	// When a refresh fails the current token keeps being used until it expires, with a
+	// refresh attempted at most once per retry interval. Read then fails with ErrTokenRefresh.
+	AutoRefreshReader struct {
+		reader    Reader
+		refresher TokenRefresher
+		leeway    time.Duration
+		retry     time.Duration
+		now       func() time.Time
+		onRefresh func(ctx context.Context, token *Token)
+
+		mu          sync.Mutex
+		token       *Token
+		nextAttempt time.Time
+	}
+)
+
+var _ Reader = (*AutoRefreshReader)(nil)
+
+func (fn TokenRefresherFunc) RefreshToken(ctx context.Context, conf *Config, current string) (*Token, error) {
+	return fn(ctx, conf, current)
+}
+
+// Expired reports whether the token expires within leeway of now.
+func (t *Token) Expired(now time.Time, leeway time.Duration) bool {
+	return !t.ExpiresAt.IsZero() && !now.Add(leeway).Before(t.ExpiresAt)
+}
+
+// WithRefreshLeeway sets how long before it expires a token is refreshed.
+func WithRefreshLeeway(leeway time.Duration) AutoRefreshOption {
+	return func(r *AutoRefreshReader) {
+		r.leeway = leeway
+	}
+}
+
+// WithRefreshRetryInterval sets how long after a failed refresh the next one is attempted.
+func WithRefreshRetryInterval(interval time.Duration) AutoRefreshOption {
+	return func(r *AutoRefreshReader) {
+		r.retry = interval
+	}
+}
+
+// WithInitialToken starts the reader with token instead of exchanging the token of the
+// wrapped Config on the first Read, for tokens persisted by a previous run.
+func WithInitialToken(token *Token) AutoRefreshOption {
+	return func(r *AutoRefreshReader) {
+		r.token = token
+	}
+}
+
+// WithRefreshHandler sets a function called with every new token, to persist it.
+func WithRefreshHandler(fn func(ctx context.Context, token *Token)) AutoRefreshOption {
+	return func(r *AutoRefreshReader) {
+		r.onRefresh = fn
+	}
+}
+
+func WithRefreshClock(now func() time.Time) AutoRefreshOption {
+	return func(r *AutoRefreshReader) {
+		r.now = now
+	}
+}
+
+func NewAutoRefreshReader(reader Reader, refresher TokenRefresher, options ...AutoRefreshOption) *AutoRefreshReader {
+	r := &AutoRefreshReader{
+		reader:    reader,
+		refresher: refresher,
+		leeway:    DefaultRefreshLeeway,
+		retry:     DefaultRefreshRetryInterval,
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(r)
+		}
+	}
+
+	return r
+}
+
+// Read returns a copy of the wrapped Config with a fresh access token.
+func (r *AutoRefreshReader) Read(ctx context.Context) (*Config, error) {
+	conf, err := r.reader.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.Token(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *conf
+	c.AccessToken = token.Value
+
+	return &c, nil
+}
+
+// Token returns the current token, refreshing it first when it is about to expire. conf is
+// passed to the TokenRefresher.
+func (r *AutoRefreshReader) Token(ctx context.Context, conf *Config) (*Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if r.token != nil && (!r.token.Expired(now, r.leeway) || now.Before(r.nextAttempt)) &&
+		!r.token.Expired(now, 0) {
+		return r.token, nil
+	}
+
+	current := conf.AccessToken
+	if r.token != nil {
+		current = r.token.Value
+	}
+
+	token, err := r.refresher.RefreshToken(ctx, conf, current)
+	if err != nil || token == nil || token.Value == "" {
+		if r.token != nil && !r.token.Expired(now, 0) {
+			r.nextAttempt = now.Add(r.retry)
+
+			return r.token, nil
+		}
+
+		if err == nil {
+			err = ErrEmptyToken
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrTokenRefresh, err)
+	}
+
+	r.token = token
+	r.nextAttempt = time.Time{}
+	if r.onRefresh != nil {
+		r.onRefresh(ctx, token)
+	}
+
+	return token, nil
+}
+
+// Invalidate drops the current token so that the next Read refreshes it, for tokens the
+// API rejected before they expired.
+func (r *AutoRefreshReader) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token != nil {
+		r.token = &Token{Value: r.token.Value, ExpiresAt: r.now()}
+		r.nextAttempt = time.Time{}
+	}
+}
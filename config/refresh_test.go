@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+func TestAutoRefreshReader(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var (
+		refreshes []string
+		fail      error
+		persisted *config.Token
+	)
+
+	refresher := config.TokenRefresherFunc(func(_ context.Context, conf *config.Config, current string) (*config.Token, error) {
+		if conf.AppID != "app" {
+			t.Errorf("refresher got app id %q, want app", conf.AppID)
+		}
+
+		refreshes = append(refreshes, current)
+		if fail != nil {
+			return nil, fail
+		}
+
+		return &config.Token{Value: "token-" + now.Format("0102"), ExpiresAt: now.Add(60 * 24 * time.Hour)}, nil
+	})
+
+	base := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{AppID: "app", AccessToken: "long-lived"}, nil
+	})
+
+	reader := config.NewAutoRefreshReader(base, refresher,
+		config.WithRefreshClock(func() time.Time { return now }),
+		config.WithRefreshHandler(func(_ context.Context, token *config.Token) { persisted = token }),
+	)
+
+	read := func() (string, error) {
+		t.Helper()
+		conf, err := reader.Read(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return conf.AccessToken, nil
+	}
+
+	// the long-lived token is exchanged on the first read, then cached.
+	for range 2 {
+		token, err := read()
+		if err != nil || token != "token-0101" {
+			t.Fatalf("read = %q, %v, want token-0101", token, err)
+		}
+	}
+
+	if len(refreshes) != 1 || refreshes[0] != "long-lived" {
+		t.Fatalf("refreshes = %v, want [long-lived]", refreshes)
+	}
+
+	if persisted == nil || persisted.Value != "token-0101" {
+		t.Fatalf("persisted = %+v, want token-0101", persisted)
+	}
+
+	// within the leeway the token is refreshed.
+	now = now.Add(59*24*time.Hour + time.Hour)
+	if token, err := read(); err != nil || token != "token-0229" {
+		t.Fatalf("read = %q, %v, want token-0229", token, err)
+	}
+
+	if refreshes[1] != "token-0101" {
+		t.Fatalf("refreshed %q, want token-0101", refreshes[1])
+	}
+
+	// failed refreshes keep the current token until it expires and are not retried on
+	// every read.
+	fail = errors.New("graph api unavailable")
+	now = now.Add(59*24*time.Hour + time.Hour)
+	for range 3 {
+		if token, err := read(); err != nil || token != "token-0229" {
+			t.Fatalf("read = %q, %v, want token-0229", token, err)
+		}
+	}
+
+	if len(refreshes) != 3 {
+		t.Fatalf("refreshes = %d, want 3", len(refreshes))
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, err := read(); !errors.Is(err, config.ErrTokenRefresh) || !errors.Is(err, fail) {
+		t.Fatalf("read error = %v, want %v", err, config.ErrTokenRefresh)
+	}
+
+	fail = nil
+	reader.Invalidate()
+	if token, err := read(); err != nil || token != "token-0429" {
+		t.Fatalf("read = %q, %v, want token-0429", token, err)
+	}
+}
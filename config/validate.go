@@ -0,0 +1,175 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultBaseURL is the Graph API URL used when Config.BaseURL is empty.
+	DefaultBaseURL = "https://graph.facebook.com"
+
+	// DefaultAPIVersion is the API version used when Config.APIVersion is empty.
+	DefaultAPIVersion = "v20.0"
+
+	// LowestAPIVersion is the lowest major API version Validate accepts.
+	LowestAPIVersion = 16
+)
+
+// Family groups the operations a Config is used for, each needs different fields.
+type Family string
+
+const (
+	// FamilyMessaging needs the access token and the phone number id.
+	FamilyMessaging Family = "messaging"
+
+	// FamilyManagement needs the access token and the business account id.
+	FamilyManagement Family = "management"
+
+	// FamilyWebhooks needs the app secret to validate the payload signatures.
+	FamilyWebhooks Family = "webhooks"
+)
+
+// FieldError reports the field of a Config that failed validation.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var apiVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?$`)
+
+// Validate normalizes the Config, applies the defaults and checks that the fields needed
+// by the given families are set, so that a misconfigured deployment fails at startup
+// instead of on its first request.
+//
+// The BaseURL defaults to DefaultBaseURL and loses its trailing slashes, the APIVersion
+// defaults to DefaultAPIVersion and is written as "v<major>.<minor>", so "20" becomes
+// "v20.0". Whatever the families, SecureRequests needs the AppSecret.
+//
+// All the failed checks are reported at once. The returned error wraps ErrInvalidConfig
+// and a *FieldError per failed check, which wraps one of ErrMissingField,
+// ErrInvalidBaseURL and ErrInvalidAPIVersion.
+func (c *Config) Validate(families ...Family) error {
+	var errs []error
+
+	if err := c.normalizeBaseURL(); err != nil {
+		errs = append(errs, &FieldError{Field: "BaseURL", Err: err})
+	}
+
+	if err := c.normalizeAPIVersion(); err != nil {
+		errs = append(errs, &FieldError{Field: "APIVersion", Err: err})
+	}
+
+	required := map[string]string{}
+	if c.SecureRequests {
+		required["AppSecret"] = c.AppSecret
+	}
+
+	for _, family := range families {
+		switch family {
+		case FamilyMessaging:
+			required["AccessToken"] = c.AccessToken
+			required["PhoneNumberID"] = c.PhoneNumberID
+		case FamilyManagement:
+			required["AccessToken"] = c.AccessToken
+			required["BusinessAccountID"] = c.BusinessAccountID
+		case FamilyWebhooks:
+			required["AppSecret"] = c.AppSecret
+		default:
+			return fmt.Errorf("%w: unknown family %q", ErrInvalidConfig, family)
+		}
+	}
+
+	for _, field := range []string{"AccessToken", "PhoneNumberID", "BusinessAccountID", "AppSecret"} {
+		if value, ok := required[field]; ok && strings.TrimSpace(value) == "" {
+			errs = append(errs, &FieldError{Field: field, Err: ErrMissingField})
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (c *Config) normalizeBaseURL() error {
+	baseURL := strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	if baseURL == "" {
+		c.BaseURL = DefaultBaseURL
+
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBaseURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q is not an absolute http url", ErrInvalidBaseURL, c.BaseURL)
+	}
+
+	c.BaseURL = baseURL
+
+	return nil
+}
+
+func (c *Config) normalizeAPIVersion() error {
+	version := strings.TrimSpace(c.APIVersion)
+	if version == "" {
+		c.APIVersion = DefaultAPIVersion
+
+		return nil
+	}
+
+	matches := apiVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidAPIVersion, c.APIVersion)
+	}
+
+	major, err := strconv.Atoi(matches[1])
+	if err != nil || major < LowestAPIVersion {
+		return fmt.Errorf("%w: %q is lower than v%d.0", ErrInvalidAPIVersion, c.APIVersion, LowestAPIVersion)
+	}
+
+	minor := matches[2]
+	if minor == "" {
+		minor = "0"
+	}
+
+	c.APIVersion = fmt.Sprintf("v%d.%s", major, minor)
+
+	return nil
+}
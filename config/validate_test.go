@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{
+		BaseURL:       "https://graph.facebook.com/",
+		APIVersion:    "21",
+		AccessToken:   "token",
+		PhoneNumberID: "phone",
+	}
+
+	if err := conf.Validate(config.FamilyMessaging); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	want := &config.Config{
+		BaseURL:       "https://graph.facebook.com",
+		APIVersion:    "v21.0",
+		AccessToken:   "token",
+		PhoneNumberID: "phone",
+	}
+
+	if diff := gcmp.Diff(want, conf); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+
+	defaults := &config.Config{AppSecret: "secret"}
+	if err := defaults.Validate(config.FamilyWebhooks); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if defaults.BaseURL != config.DefaultBaseURL || defaults.APIVersion != config.DefaultAPIVersion {
+		t.Errorf("defaults not applied: %+v", defaults)
+	}
+}
+
+func TestConfig_ValidateErrors(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{
+		BaseURL:        "graph.facebook.com",
+		APIVersion:     "v15.0",
+		SecureRequests: true,
+	}
+
+	err := conf.Validate(config.FamilyMessaging, config.FamilyManagement)
+	if !errors.Is(err, config.ErrInvalidConfig) {
+		t.Fatalf("Validate() error = %v, want %v", err, config.ErrInvalidConfig)
+	}
+
+	for _, target := range []error{config.ErrInvalidBaseURL, config.ErrInvalidAPIVersion, config.ErrMissingField} {
+		if !errors.Is(err, target) {
+			t.Errorf("Validate() error = %v, want it to wrap %v", err, target)
+		}
+	}
+
+	want := []string{"BaseURL", "APIVersion", "AccessToken", "PhoneNumberID", "BusinessAccountID", "AppSecret"}
+	if diff := gcmp.Diff(want, fieldErrors(err)); diff != "" {
+		t.Errorf("fields mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func fieldErrors(err error) []string {
+	switch e := err.(type) { //nolint:errorlint // walks the error tree
+	case *config.FieldError:
+		return []string{e.Field}
+	case interface{ Unwrap() []error }:
+		var fields []string
+		for _, wrapped := range e.Unwrap() {
+			fields = append(fields, fieldErrors(wrapped)...)
+		}
+
+		return fields
+	default:
+		return nil
+	}
+}
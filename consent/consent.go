@@ -0,0 +1,222 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package consent keeps track of the users that stopped marketing messages and enforces it
+// when sending.
+//
+// WhatsApp reports in user_preferences webhooks when a user stops or resumes marketing
+// messages from the business. Handler records these changes in a Store, and Middleware
+// blocks marketing template messages to the users that opted out before they reach the
+// Graph API, failing with ErrRecipientOptedOut.
+//
+//	store := consent.NewMemoryStore()
+//	handlers.SetUserPreferenceHandler(consent.Handler(store))
+//
+//	client, err := message.NewBaseClient(sender, reader)
+//	client.Use(consent.Middleware(store))
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+var (
+	ErrRecipientOptedOut = errors.New("recipient opted out of marketing messages")
+	ErrStore             = errors.New("consent store failed")
+)
+
+type (
+	// Key identifies the consent of a user for one business phone number.
+	Key struct {
+		PhoneNumberID string
+		WaID          string
+	}
+
+	// Record is the latest marketing preference of a user.
+	Record struct {
+		Key
+		OptedOut  bool
+		Detail    string
+		UpdatedAt time.Time
+	}
+
+	// Store keeps consent records. Get returns nil without an error for users with no
+	// record, they have not opted out.
+	Store interface {
+		Get(ctx context.Context, key Key) (*Record, error)
+		Put(ctx context.Context, record *Record) error
+	}
+
+	// CategoryResolver returns the category of a template that is sent without one, for
+	// example from a cache of the templates of the account.
+	CategoryResolver func(ctx context.Context, template *wmessage.Template) (wmessage.TemplateCategory, error)
+
+	MiddlewareOption func(*middleware)
+
+	middleware struct {
+		store   Store
+		resolve CategoryResolver
+	}
+
+	// MemoryStore is a Store kept in memory.
+	MemoryStore struct {
+		mu      sync.RWMutex
+		records map[Key]*Record
+	}
+)
+
+// WithCategoryResolver sets how the category of templates sent without one is found.
+// Without a resolver such templates are sent.
+func WithCategoryResolver(resolve CategoryResolver) MiddlewareOption {
+	return func(m *middleware) {
+		m.resolve = resolve
+	}
+}
+
+// Handler returns a user preference handler recording marketing stop and resume changes
+// in store. Changes older than the stored record are ignored, since webhooks can be
+// delivered out of order.
+func Handler(store Store) message.OnUserPreferenceHook {
+	return func(ctx context.Context, nctx *message.NotificationContext, preference *message.UserPreference) error {
+		if !preference.IsMarketingStop() && !preference.IsMarketingResume() {
+			return nil
+		}
+
+		key := Key{WaID: preference.WaID}
+		if nctx != nil && nctx.Metadata != nil {
+			key.PhoneNumberID = nctx.Metadata.PhoneNumberID
+		}
+
+		updatedAt := time.Unix(preference.Timestamp, 0).UTC()
+		current, err := store.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrStore, err)
+		}
+
+		if current != nil && current.UpdatedAt.After(updatedAt) {
+			return nil
+		}
+
+		record := &Record{
+			Key:       key,
+			OptedOut:  preference.IsMarketingStop(),
+			Detail:    preference.Detail,
+			UpdatedAt: updatedAt,
+		}
+
+		if err := store.Put(ctx, record); err != nil {
+			return fmt.Errorf("%w: %w", ErrStore, err)
+		}
+
+		return nil
+	}
+}
+
+// Middleware returns a send middleware that fails marketing template messages to users
+// that opted out with an error wrapping ErrRecipientOptedOut and
+// wmessage.ErrSendSuppressed. Other messages are sent as usual.
+func Middleware(store Store, options ...MiddlewareOption) wmessage.Middleware {
+	m := &middleware{store: store}
+	for _, option := range options {
+		if option != nil {
+			option(m)
+		}
+	}
+
+	return wmessage.Guard(m.check)
+}
+
+// OptedOut reports whether the user opted out of marketing messages.
+func OptedOut(ctx context.Context, store Store, key Key) (bool, error) {
+	record, err := store.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrStore, err)
+	}
+
+	return record != nil && record.OptedOut, nil
+}
+
+func (m *middleware) check(ctx context.Context, conf *config.Config, request *wmessage.BaseRequest) error {
+	msg := request.Message
+	if request.Type != whttp.RequestTypeSendMessage || msg == nil || msg.Template == nil {
+		return nil
+	}
+
+	category := msg.Template.Category
+	if category == "" && m.resolve != nil {
+		resolved, err := m.resolve(ctx, msg.Template)
+		if err != nil {
+			return fmt.Errorf("resolve category of template %q: %w", msg.Template.Name, err)
+		}
+		category = resolved
+	}
+
+	if category != wmessage.TemplateCategoryMarketing {
+		return nil
+	}
+
+	optedOut, err := OptedOut(ctx, m.store, Key{PhoneNumberID: conf.PhoneNumberID, WaID: msg.To})
+	if err != nil {
+		return err
+	}
+
+	if optedOut {
+		return fmt.Errorf("%w: %s", ErrRecipientOptedOut, msg.To)
+	}
+
+	return nil
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[Key]*Record)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key Key) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil, nil //nolint:nilnil // no record means no opt-out
+	}
+
+	r := *record
+
+	return &r, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, record *Record) error {
+	r := *record
+
+	s.mu.Lock()
+	s.records[record.Key] = &r
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package consent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/consent"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const preferencesPayload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "WABA_ID",
+    "changes": [{
+      "field": "user_preferences",
+      "value": {
+        "messaging_product": "whatsapp",
+        "metadata": {"display_phone_number": "15550000000", "phone_number_id": "PHONE_ID"},
+        "user_preferences": [{
+          "wa_id": "255700000001",
+          "detail": "User requested to stop marketing messages",
+          "category": "marketing_messages",
+          "value": "%s",
+          "timestamp": %s
+        }]
+      }
+    }]
+  }]
+}`
+
+func notify(t *testing.T, handlers *message.Handlers, value, timestamp string) {
+	t.Helper()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(fmt.Sprintf(preferencesPayload, value, timestamp)), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	response := handlers.HandleNotification(context.Background(), &notification)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("HandleNotification() status = %d", response.StatusCode)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	store := consent.NewMemoryStore()
+	handlers := &message.Handlers{}
+	handlers.SetUserPreferenceHandler(consent.Handler(store))
+
+	ctx := context.Background()
+	key := consent.Key{PhoneNumberID: "PHONE_ID", WaID: "255700000001"}
+
+	optedOut := func() bool {
+		t.Helper()
+
+		out, err := consent.OptedOut(ctx, store, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return out
+	}
+
+	if optedOut() {
+		t.Fatal("OptedOut() = true before any preference")
+	}
+
+	notify(t, handlers, "stop", "1700000100")
+	if !optedOut() {
+		t.Fatal("OptedOut() = false after stop")
+	}
+
+	// An older resume delivered late must not override the stop.
+	notify(t, handlers, "resume", "1700000000")
+	if !optedOut() {
+		t.Fatal("OptedOut() = false after a stale resume")
+	}
+
+	notify(t, handlers, "resume", "1700000200")
+	if optedOut() {
+		t.Fatal("OptedOut() = true after resume")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := consent.NewMemoryStore()
+	if err := store.Put(ctx, &consent.Record{
+		Key:      consent.Key{PhoneNumberID: "PHONE_ID", WaID: "255700000001"},
+		OptedOut: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent int
+	sender := whttp.SenderFunc[wmessage.Message](func(context.Context, *whttp.Request[wmessage.Message],
+		whttp.ResponseDecoder,
+	) error {
+		sent++
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{
+			BaseURL:       "https://graph.facebook.com",
+			APIVersion:    "v20.0",
+			PhoneNumberID: "PHONE_ID",
+		}, nil
+	})
+
+	client, err := wmessage.NewBaseClient(sender, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := func(_ context.Context, template *wmessage.Template) (wmessage.TemplateCategory, error) {
+		if template.Name == "spring_sale" {
+			return wmessage.TemplateCategoryMarketing, nil
+		}
+
+		return wmessage.TemplateCategoryUtility, nil
+	}
+
+	client.Use(consent.Middleware(store, consent.WithCategoryResolver(resolve)))
+
+	tests := []struct {
+		name     string
+		to       string
+		template *wmessage.Template
+		blocked  bool
+	}{
+		{
+			name:     "marketing to opted out recipient",
+			to:       "255700000001",
+			template: &wmessage.Template{Name: "promo", Category: wmessage.TemplateCategoryMarketing},
+			blocked:  true,
+		},
+		{
+			name:     "resolved marketing to opted out recipient",
+			to:       "255700000001",
+			template: &wmessage.Template{Name: "spring_sale"},
+			blocked:  true,
+		},
+		{
+			name:     "utility to opted out recipient",
+			to:       "255700000001",
+			template: &wmessage.Template{Name: "order_update"},
+		},
+		{
+			name:     "marketing to other recipient",
+			to:       "255700000002",
+			template: &wmessage.Template{Name: "promo", Category: wmessage.TemplateCategoryMarketing},
+		},
+	}
+
+	for _, tt := range tests {
+		before := sent
+		_, err := client.SendTemplate(ctx, wmessage.NewRequest(tt.to, tt.template, ""))
+
+		if tt.blocked {
+			if !errors.Is(err, consent.ErrRecipientOptedOut) || !errors.Is(err, wmessage.ErrSendSuppressed) {
+				t.Errorf("%s: SendTemplate() error = %v, want ErrRecipientOptedOut", tt.name, err)
+			}
+
+			if sent != before {
+				t.Errorf("%s: blocked message was sent", tt.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: SendTemplate() error = %v", tt.name, err)
+		}
+
+		if sent != before+1 {
+			t.Errorf("%s: message was not sent", tt.name)
+		}
+	}
+
+	if _, err := client.SendText(ctx, wmessage.NewRequest("255700000001", &wmessage.Text{Body: "hi"}, "")); err != nil {
+		t.Errorf("SendText() error = %v, non template messages must not be blocked", err)
+	}
+}
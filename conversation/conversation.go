@@ -0,0 +1,359 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package conversation keeps per customer session state for bots built on the message
+// webhooks. Sessions are keyed by the customer wa_id, scoped by the business phone number,
+// and expire 24 hours after the last inbound message, matching the customer service window
+// in which free form messages can be sent.
+//
+// Wrap the message handlers with the Manager so each handler finds the session in its
+// context and changes made to it are saved once the handler returns:
+//
+//	manager := conversation.NewManager(conversation.NewMemoryStore())
+//	handlers.TextMessage = conversation.Handler(manager, handlers.TextMessage)
+//
+//	// inside the handler
+//	session, _ := conversation.FromContext(ctx)
+//	session.State = "awaiting_address"
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// Window is the customer service window opened by every inbound message.
+const Window = 24 * time.Hour
+
+type (
+	Session struct {
+		Key           string         `json:"key"`
+		WaID          string         `json:"wa_id"`
+		PhoneNumberID string         `json:"phone_number_id,omitempty"`
+		State         string         `json:"state,omitempty"`
+		Data          map[string]any `json:"data,omitempty"`
+		CreatedAt     time.Time      `json:"created_at"`
+		LastInboundAt time.Time      `json:"last_inbound_at"`
+		ExpiresAt     time.Time      `json:"expires_at"`
+	}
+
+	// SessionStore persists sessions. Get returns ErrSessionNotFound for unknown keys, the
+	// Manager treats expired sessions returned by a store as missing.
+	SessionStore interface {
+		Get(ctx context.Context, key string) (*Session, error)
+		Save(ctx context.Context, session *Session) error
+		Delete(ctx context.Context, key string) error
+	}
+
+	// KeyFunc derives the session key of a customer.
+	KeyFunc func(nctx *message.NotificationContext, waID string) string
+)
+
+// Expired reports whether the session outlived its expiry at now.
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// WindowOpen reports whether free form messages can still be sent to the customer at now.
+func (s *Session) WindowOpen(now time.Time) bool {
+	return !s.LastInboundAt.IsZero() && now.Before(s.LastInboundAt.Add(Window))
+}
+
+// Get returns the value stored under key.
+func (s *Session) Get(key string) (any, bool) {
+	value, ok := s.Data[key]
+
+	return value, ok
+}
+
+// Set stores value under key.
+func (s *Session) Set(key string, value any) {
+	if s.Data == nil {
+		s.Data = make(map[string]any)
+	}
+	s.Data[key] = value
+}
+
+// Clone returns a copy of the session, Data is copied one level deep.
+func (s *Session) Clone() *Session {
+	clone := *s
+	clone.Data = maps.Clone(s.Data)
+
+	return &clone
+}
+
+// DefaultKey scopes the wa_id by the business phone number the message was sent to, so a
+// customer talking to two numbers of the same app has two sessions.
+func DefaultKey(nctx *message.NotificationContext, waID string) string {
+	if nctx == nil || nctx.Metadata == nil || nctx.Metadata.PhoneNumberID == "" {
+		return waID
+	}
+
+	return nctx.Metadata.PhoneNumberID + "/" + waID
+}
+
+type (
+	Manager struct {
+		store SessionStore
+		ttl   time.Duration
+		key   KeyFunc
+		now   func() time.Time
+		hooks SessionHooks
+	}
+
+	ManagerOption func(*Manager)
+
+	// SessionHooks are called when the Manager starts a session for a customer and when a
+	// session is ended. Sessions that silently expire do not trigger OnEnd.
+	SessionHooks struct {
+		OnStart func(ctx context.Context, session *Session)
+		OnEnd   func(ctx context.Context, session *Session)
+	}
+)
+
+// WithTTL sets how long a session lives after the last inbound message, Window by default.
+func WithTTL(ttl time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.ttl = ttl
+	}
+}
+
+func WithKeyFunc(fn KeyFunc) ManagerOption {
+	return func(m *Manager) {
+		m.key = fn
+	}
+}
+
+func WithClock(now func() time.Time) ManagerOption {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+func WithSessionHooks(hooks SessionHooks) ManagerOption {
+	return func(m *Manager) {
+		m.hooks = hooks
+	}
+}
+
+func NewManager(store SessionStore, options ...ManagerOption) *Manager {
+	manager := &Manager{
+		store: store,
+		ttl:   Window,
+		key:   DefaultKey,
+		now:   time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(manager)
+		}
+	}
+
+	return manager
+}
+
+// Load returns the live session of the customer, starting a new one when there is none or
+// the previous one expired.
+func (m *Manager) Load(ctx context.Context, nctx *message.NotificationContext, waID string) (*Session, error) {
+	key := m.key(nctx, waID)
+	now := m.now()
+
+	session, err := m.store.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: %s: %w", ErrLoadSession, key, err)
+	}
+
+	if session == nil || session.Expired(now) {
+		session = &Session{Key: key, WaID: waID, CreatedAt: now}
+		if nctx != nil && nctx.Metadata != nil {
+			session.PhoneNumberID = nctx.Metadata.PhoneNumberID
+		}
+	}
+
+	return session, nil
+}
+
+// Touch records an inbound message at now, reopening the window and extending the expiry.
+func (m *Manager) Touch(session *Session) {
+	now := m.now()
+	session.LastInboundAt = now
+	session.ExpiresAt = now.Add(m.ttl)
+}
+
+func (m *Manager) Save(ctx context.Context, session *Session) error {
+	if err := m.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrSaveSession, session.Key, err)
+	}
+
+	return nil
+}
+
+// End deletes the session, the next message from the customer starts a new one.
+func (m *Manager) End(ctx context.Context, session *Session) error {
+	if err := m.store.Delete(ctx, session.Key); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDeleteSession, session.Key, err)
+	}
+
+	if m.hooks.OnEnd != nil {
+		m.hooks.OnEnd(ctx, session)
+	}
+
+	return nil
+}
+
+// run loads and touches the session of waID, calls fn with the session in its context and
+// saves the session afterwards, even when fn fails, unless fn ended it.
+func (m *Manager) run(ctx context.Context, nctx *message.NotificationContext, waID string,
+	fn func(ctx context.Context) error,
+) error {
+	session, err := m.Load(ctx, nctx, waID)
+	if err != nil {
+		return err
+	}
+
+	started := session.LastInboundAt.IsZero()
+	m.Touch(session)
+
+	if started && m.hooks.OnStart != nil {
+		m.hooks.OnStart(ctx, session)
+	}
+
+	state := &sessionState{session: session}
+	handlerErr := fn(context.WithValue(ctx, sessionContextKey{}, state))
+
+	if state.ended {
+		return errors.Join(handlerErr, m.End(ctx, session))
+	}
+
+	return errors.Join(handlerErr, m.Save(ctx, session))
+}
+
+type (
+	sessionContextKey struct{}
+
+	sessionState struct {
+		session *Session
+		ended   bool
+	}
+)
+
+// FromContext returns the session injected by Handler or ReceivedHandler.
+func FromContext(ctx context.Context) (*Session, bool) {
+	state, ok := ctx.Value(sessionContextKey{}).(*sessionState)
+	if !ok {
+		return nil, false
+	}
+
+	return state.session, true
+}
+
+// EndFromContext marks the session in ctx to be deleted instead of saved once the handler
+// returns, for example when the conversation reached its goal.
+func EndFromContext(ctx context.Context) bool {
+	state, ok := ctx.Value(sessionContextKey{}).(*sessionState)
+	if ok {
+		state.ended = true
+	}
+
+	return ok
+}
+
+// Handler injects the session of the message sender into the context of next.
+func Handler[T any](manager *Manager, next message.Handler[T]) message.Handler[T] {
+	if next == nil {
+		return nil
+	}
+
+	return message.HandlerFunc[T](func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, msg *T,
+	) error {
+		return manager.run(ctx, nctx, mctx.From, func(ctx context.Context) error {
+			return next.Handle(ctx, nctx, mctx, msg)
+		})
+	})
+}
+
+// ReceivedHandler injects the session of the message sender into the context of next.
+func ReceivedHandler(manager *Manager, next message.ReceivedHandler) message.ReceivedHandler {
+	if next == nil {
+		return nil
+	}
+
+	return message.OnMessageReceivedHook(func(ctx context.Context, nctx *message.NotificationContext,
+		msg *message.Message,
+	) error {
+		return manager.run(ctx, nctx, msg.From, func(ctx context.Context) error {
+			return next.Handle(ctx, nctx, msg)
+		})
+	})
+}
+
+// Wrap injects sessions into every message handler set on handlers. Handlers set afterwards
+// are not wrapped.
+func Wrap(manager *Manager, handlers *message.Handlers) {
+	handlers.OrderMessage = wrap(manager, handlers.OrderMessage)
+	handlers.ButtonMessage = wrap(manager, handlers.ButtonMessage)
+	handlers.LocationMessage = wrap(manager, handlers.LocationMessage)
+	handlers.ContactsMessage = wrap(manager, handlers.ContactsMessage)
+	handlers.MessageReaction = wrap(manager, handlers.MessageReaction)
+	handlers.ProductEnquiry = wrap(manager, handlers.ProductEnquiry)
+	handlers.InteractiveMessage = wrap(manager, handlers.InteractiveMessage)
+	handlers.ButtonReply = wrap(manager, handlers.ButtonReply)
+	handlers.ListReply = wrap(manager, handlers.ListReply)
+	handlers.FlowReply = wrap(manager, handlers.FlowReply)
+	handlers.TextMessage = wrap(manager, handlers.TextMessage)
+	handlers.ReferralMessage = wrap(manager, handlers.ReferralMessage)
+	handlers.AudioMessage = wrap(manager, handlers.AudioMessage)
+	handlers.VideoMessage = wrap(manager, handlers.VideoMessage)
+	handlers.ImageMessage = wrap(manager, handlers.ImageMessage)
+	handlers.DocumentMessage = wrap(manager, handlers.DocumentMessage)
+	handlers.StickerMessage = wrap(manager, handlers.StickerMessage)
+
+	if handlers.MessageReceived != nil {
+		handlers.MessageReceived = ReceivedHandler(manager, handlers.MessageReceived)
+	}
+}
+
+func wrap[T any](manager *Manager, next message.Handler[T]) message.Handler[T] {
+	if next == nil {
+		return nil
+	}
+
+	return Handler(manager, next)
+}
+
+// conversationError is a custom error type for conversation errors.
+type conversationError string
+
+func (e conversationError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrSessionNotFound = conversationError("session not found")
+	ErrLoadSession     = conversationError("could not load session")
+	ErrSaveSession     = conversationError("could not save session")
+	ErrDeleteSession   = conversationError("could not delete session")
+)
@@ -0,0 +1,79 @@
+package conversation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/conversation"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	store := conversation.NewMemoryStore()
+	manager := conversation.NewManager(store, conversation.WithClock(func() time.Time { return now }))
+
+	var states []string
+	handlers := &message.Handlers{}
+	handlers.TextMessage = message.OnTextMessageHook(func(ctx context.Context, _ *message.NotificationContext,
+		_ *message.Info, text *message.Text,
+	) error {
+		session, ok := conversation.FromContext(ctx)
+		if !ok {
+			return errors.New("no session in context")
+		}
+
+		states = append(states, session.State)
+		switch text.Body {
+		case "bye":
+			conversation.EndFromContext(ctx)
+		default:
+			session.State = text.Body
+		}
+
+		return nil
+	})
+	conversation.Wrap(manager, handlers)
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "111"}}
+	send := func(body string) {
+		t.Helper()
+
+		err := handlers.TextMessage.Handle(context.Background(), nctx, &message.Info{From: "255700"}, &message.Text{Body: body})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send("menu")
+	send("order")
+
+	session, err := store.Get(context.Background(), "111/255700")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if session.State != "order" || !session.WindowOpen(now.Add(23*time.Hour)) || session.WindowOpen(now.Add(conversation.Window)) {
+		t.Errorf("unexpected session %+v", session)
+	}
+
+	// The session expires with the customer service window.
+	now = now.Add(25 * time.Hour)
+	send("hello")
+	send("bye")
+
+	if _, err := store.Get(context.Background(), "111/255700"); !errors.Is(err, conversation.ErrSessionNotFound) {
+		t.Errorf("expected the session to be ended, got %v", err)
+	}
+
+	want := []string{"", "menu", "", "hello"}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("states = %q, want %q", states, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package conversation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+// sealedDataKey holds the sealed State and Data of sessions saved by EncryptedStore.
+const sealedDataKey = "$sealed"
+
+var _ SessionStore = (*EncryptedStore)(nil)
+
+// EncryptedStore seals the State and Data of sessions before they reach the wrapped store.
+// Keys, ids and timestamps stay in clear so stores can still index and expire sessions.
+type EncryptedStore struct {
+	store  SessionStore
+	sealer *crypto.Sealer
+}
+
+func NewEncryptedStore(store SessionStore, sealer *crypto.Sealer) *EncryptedStore {
+	return &EncryptedStore{store: store, sealer: sealer}
+}
+
+type sealedSession struct {
+	State string         `json:"state,omitempty"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, key string) (*Session, error) {
+	session, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, ok := session.Data[sealedDataKey].(string)
+	if !ok {
+		return session, nil
+	}
+
+	var content sealedSession
+	if err := s.sealer.OpenJSON(ctx, sealed, session.Key, &content); err != nil {
+		return nil, fmt.Errorf("open session %s: %w", key, err)
+	}
+
+	session.State, session.Data = content.State, content.Data
+
+	return session, nil
+}
+
+func (s *EncryptedStore) Save(ctx context.Context, session *Session) error {
+	sealed, err := s.sealer.SealJSON(ctx, &sealedSession{State: session.State, Data: session.Data}, session.Key)
+	if err != nil {
+		return fmt.Errorf("seal session %s: %w", session.Key, err)
+	}
+
+	clone := session.Clone()
+	clone.State = ""
+	clone.Data = map[string]any{sealedDataKey: sealed}
+
+	return s.store.Save(ctx, clone)
+}
+
+func (s *EncryptedStore) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, key)
+}
@@ -0,0 +1,85 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package conversation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ SessionStore = (*MemoryStore)(nil)
+
+// MemoryStore is a SessionStore kept in memory. It hands out copies, so changes to a
+// session are only visible to others after Save. Expired sessions are kept until Sweep.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*Session, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	return session.Clone(), nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	s.sessions[session.Key] = session.Clone()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.sessions, key)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Sweep removes the sessions expired at now and returns how many were removed.
+func (s *MemoryStore) Sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, session := range s.sessions {
+		if session.Expired(now) {
+			delete(s.sessions, key)
+			removed++
+		}
+	}
+
+	return removed
+}
@@ -0,0 +1,368 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package digest coalesces high frequency application events into periodic summary
+// messages, so that alerting integrations do not flood recipients with notifications or
+// use up their template quota.
+//
+// Events added to a Digester are buffered per recipient. The first buffered event opens a
+// window and when it closes a single message summarizing the buffered events is sent.
+// Recipients receive at most one digest per window:
+//
+//	digester := digest.New(client, digest.WithWindow(10*time.Minute))
+//	go digester.Run(ctx)
+//	err := digester.Add(ctx, &digest.Event{Recipient: "255700000001", Key: "disk:db-1", Text: "db-1 disk at 91%"})
+//
+// Events sharing a Key replace each other in the buffer, a digest shows the last one with
+// the number of times it occurred. Call FlushAll on shutdown to send the buffered events.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+)
+
+const (
+	DefaultWindow       = 5 * time.Minute
+	DefaultTickInterval = time.Second
+	DefaultMaxLines     = 10
+	DefaultMaxBuffered  = 1000
+)
+
+type (
+	Event struct {
+		Recipient string
+		// Key identifies the thing the event is about, events with the same key are coalesced.
+		Key  string
+		Text string
+		At   time.Time
+		// Count is the number of events coalesced into this one, set by the Digester.
+		Count int
+	}
+
+	// Digest is the batch of events sent to Recipient in one message, oldest first.
+	Digest struct {
+		Recipient string
+		Events    []*Event
+		// Total is the number of events added during the window, coalesced events included.
+		Total    int
+		OpenedAt time.Time
+	}
+
+	// Formatter builds the message sending a digest. The recipient is set on messages that do
+	// not have one.
+	Formatter func(digest *Digest) *wmessage.Message
+
+	Option func(*Digester)
+
+	// Digester buffers events and sends digests. It is safe for concurrent use.
+	Digester struct {
+		sender      wmessage.MessageSender
+		window      time.Duration
+		tick        time.Duration
+		maxBuffered int
+		format      Formatter
+		onError     func(ctx context.Context, recipient string, err error)
+		now         func() time.Time
+
+		mu      sync.Mutex
+		pending map[string]*buffer
+	}
+
+	buffer struct {
+		openedAt time.Time
+		events   []*Event
+		keys     map[string]*Event
+		total    int
+	}
+)
+
+// WithWindow sets how long events are buffered before they are sent, which is also the
+// minimum time between two digests to a recipient.
+func WithWindow(window time.Duration) Option {
+	return func(d *Digester) {
+		d.window = window
+	}
+}
+
+// WithTickInterval sets how often Run checks for due digests.
+func WithTickInterval(interval time.Duration) Option {
+	return func(d *Digester) {
+		d.tick = interval
+	}
+}
+
+// WithMaxBuffered caps the distinct events buffered per recipient, the oldest are dropped
+// beyond it but still counted in the digest total.
+func WithMaxBuffered(n int) Option {
+	return func(d *Digester) {
+		d.maxBuffered = n
+	}
+}
+
+// WithFormatter sets the function building the digest messages, TextFormatter by default.
+func WithFormatter(format Formatter) Option {
+	return func(d *Digester) {
+		d.format = format
+	}
+}
+
+// WithErrorHandler sets the function called with the error of every digest that could not
+// be sent, the only way to learn about them when flushing with Run.
+func WithErrorHandler(fn func(ctx context.Context, recipient string, err error)) Option {
+	return func(d *Digester) {
+		d.onError = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(d *Digester) {
+		d.now = now
+	}
+}
+
+func New(sender wmessage.MessageSender, options ...Option) *Digester {
+	d := &Digester{
+		sender:      sender,
+		window:      DefaultWindow,
+		tick:        DefaultTickInterval,
+		maxBuffered: DefaultMaxBuffered,
+		format:      TextFormatter(DefaultMaxLines),
+		now:         time.Now,
+		pending:     make(map[string]*buffer),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(d)
+		}
+	}
+
+	return d
+}
+
+// TextFormatter formats digests as text messages listing at most maxLines events. A digest
+// of a single event is sent as the text of the event.
+func TextFormatter(maxLines int) Formatter {
+	return func(digest *Digest) *wmessage.Message {
+		var body string
+		if digest.Total == 1 && len(digest.Events) == 1 {
+			body = digest.Events[0].Text
+		} else {
+			body = formatLines(digest, maxLines)
+		}
+
+		msg, _ := wmessage.New(digest.Recipient, wmessage.WithTextMessage(&wmessage.Text{Body: body}))
+
+		return msg
+	}
+}
+
+func formatLines(digest *Digest, maxLines int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d new notifications:", digest.Total)
+
+	for i, event := range digest.Events {
+		if maxLines > 0 && i == maxLines {
+			fmt.Fprintf(&b, "\n...and %d more", len(digest.Events)-maxLines)
+
+			break
+		}
+
+		b.WriteString("\n- ")
+		b.WriteString(event.Text)
+		if event.Count > 1 {
+			b.WriteString(" (x" + strconv.Itoa(event.Count) + ")")
+		}
+	}
+
+	return b.String()
+}
+
+// Add buffers an event. The window of the recipient opens with its first buffered event,
+// which comes after the last digest was sent.
+func (d *Digester) Add(_ context.Context, event *Event) error {
+	if event == nil || event.Recipient == "" || event.Text == "" {
+		return ErrInvalidEvent
+	}
+
+	e := *event
+	if e.At.IsZero() {
+		e.At = d.now()
+	}
+	e.Count = 1
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	buf, ok := d.pending[e.Recipient]
+	if !ok {
+		buf = &buffer{openedAt: d.now(), keys: make(map[string]*Event)}
+		d.pending[e.Recipient] = buf
+	}
+	buf.total++
+
+	if e.Key != "" {
+		if previous, ok := buf.keys[e.Key]; ok {
+			previous.Text = e.Text
+			previous.At = e.At
+			previous.Count++
+
+			return nil
+		}
+		buf.keys[e.Key] = &e
+	}
+
+	buf.events = append(buf.events, &e)
+	if d.maxBuffered > 0 && len(buf.events) > d.maxBuffered {
+		dropped := buf.events[0]
+		buf.events = buf.events[1:]
+		if dropped.Key != "" {
+			delete(buf.keys, dropped.Key)
+		}
+	}
+
+	return nil
+}
+
+// Pending returns the number of events buffered for recipient, coalesced events included.
+func (d *Digester) Pending(recipient string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if buf, ok := d.pending[recipient]; ok {
+		return buf.total
+	}
+
+	return 0
+}
+
+// Flush sends the digests whose window has closed and returns how many were sent. Digests
+// that could not be sent are dropped and their errors joined.
+func (d *Digester) Flush(ctx context.Context) (int, error) {
+	return d.flush(ctx, false)
+}
+
+// FlushAll sends every buffered digest regardless of the window.
+func (d *Digester) FlushAll(ctx context.Context) (int, error) {
+	return d.flush(ctx, true)
+}
+
+// Run flushes the due digests every tick interval until ctx is done.
+func (d *Digester) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_, _ = d.Flush(ctx)
+		}
+	}
+}
+
+func (d *Digester) flush(ctx context.Context, all bool) (int, error) {
+	digests := d.due(all)
+
+	var (
+		sent int
+		errs []error
+	)
+
+	for _, digest := range digests {
+		if err := d.send(ctx, digest); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// due removes the digests that are due from the buffers.
+func (d *Digester) due(all bool) []*Digest {
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var digests []*Digest
+	for recipient, buf := range d.pending {
+		if !all && now.Before(buf.openedAt.Add(d.window)) {
+			continue
+		}
+
+		digests = append(digests, &Digest{
+			Recipient: recipient,
+			Events:    buf.events,
+			Total:     buf.total,
+			OpenedAt:  buf.openedAt,
+		})
+		delete(d.pending, recipient)
+	}
+
+	sort.Slice(digests, func(i, j int) bool { return digests[i].Recipient < digests[j].Recipient })
+
+	return digests
+}
+
+func (d *Digester) send(ctx context.Context, digest *Digest) error {
+	msg := d.format(digest)
+	if msg == nil {
+		return nil
+	}
+
+	if msg.To == "" {
+		msg.To = digest.Recipient
+	}
+
+	if _, err := d.sender.SendMessage(ctx, msg); err != nil {
+		err = fmt.Errorf("%w: %s: %w", ErrSend, digest.Recipient, err)
+		if d.onError != nil {
+			d.onError(ctx, digest.Recipient, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type digestError string
+
+func (e digestError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrInvalidEvent = digestError("event must have a recipient and a text")
+	ErrSend         = digestError("send digest failed")
+)
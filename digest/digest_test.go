@@ -0,0 +1,117 @@
+package digest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/digest"
+	wmessage "github.com/piusalfred/whatsapp/message"
+)
+
+type recordingSender struct {
+	sent []string
+	err  error
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg *wmessage.Message) (*wmessage.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.sent = append(s.sent, msg.To+": "+msg.Text.Body)
+
+	return &wmessage.Response{}, nil
+}
+
+func TestDigester(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sender := &recordingSender{}
+	d := digest.New(sender,
+		digest.WithWindow(5*time.Minute),
+		digest.WithFormatter(digest.TextFormatter(2)),
+		digest.WithClock(func() time.Time { return now }),
+	)
+
+	add := func(recipient, key, text string) {
+		t.Helper()
+		if err := d.Add(ctx, &digest.Event{Recipient: recipient, Key: key, Text: text}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	add("alice", "disk:db-1", "db-1 disk at 90%")
+	add("alice", "disk:db-1", "db-1 disk at 95%")
+	add("alice", "", "deploy finished")
+	add("alice", "", "backup finished")
+	add("bob", "", "new order #1")
+
+	if got := d.Pending("alice"); got != 4 {
+		t.Errorf("Pending() = %d, want 4", got)
+	}
+
+	if sent, err := d.Flush(ctx); sent != 0 || err != nil {
+		t.Fatalf("Flush() before the window closed = %d, %v", sent, err)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if sent, err := d.Flush(ctx); sent != 2 || err != nil {
+		t.Fatalf("Flush() = %d, %v, want 2", sent, err)
+	}
+
+	want := []string{
+		"alice: 4 new notifications:\n- db-1 disk at 95% (x2)\n- deploy finished\n...and 1 more",
+		"bob: new order #1",
+	}
+	if diff := gcmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("sent mismatch (-want +got):\n%s", diff)
+	}
+
+	// a window opened right after a digest is not cut short.
+	now = now.Add(time.Minute)
+	add("alice", "", "deploy failed")
+	now = now.Add(4 * time.Minute)
+	if sent, _ := d.Flush(ctx); sent != 0 {
+		t.Errorf("digest sent before its window closed")
+	}
+
+	now = now.Add(time.Minute)
+	if sent, _ := d.Flush(ctx); sent != 1 {
+		t.Errorf("Flush() = %d, want 1", sent)
+	}
+}
+
+func TestDigesterFlushAll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sender := &recordingSender{err: errors.New("unavailable")}
+
+	var failed []string
+	d := digest.New(sender, digest.WithErrorHandler(func(_ context.Context, recipient string, _ error) {
+		failed = append(failed, recipient)
+	}))
+
+	if err := d.Add(ctx, &digest.Event{Recipient: "alice"}); !errors.Is(err, digest.ErrInvalidEvent) {
+		t.Errorf("Add() error = %v, want ErrInvalidEvent", err)
+	}
+
+	_ = d.Add(ctx, &digest.Event{Recipient: "alice", Text: "disk full"})
+	sent, err := d.FlushAll(ctx)
+	if sent != 0 || !errors.Is(err, digest.ErrSend) {
+		t.Fatalf("FlushAll() = %d, %v, want ErrSend", sent, err)
+	}
+
+	if diff := gcmp.Diff([]string{"alice"}, failed); diff != "" {
+		t.Errorf("failed mismatch (-want +got):\n%s", diff)
+	}
+
+	if d.Pending("alice") != 0 {
+		t.Errorf("failed digests must be dropped")
+	}
+}
@@ -0,0 +1,504 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package doccollect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/media"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// DefaultSessionTTL is how long a session stays open unless configured otherwise.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+type (
+	// Messages are the texts sent to the contact. They may use the {name}, {missing},
+	// {received}, {total} and {reason} placeholders. Empty texts are not sent.
+	Messages struct {
+		Prompt   string
+		Received string
+		Rejected string
+		Reminder string
+		Complete string
+		Expired  string
+	}
+
+	// ReminderPolicy reminds contacts of missing documents every Interval without
+	// activity, at most Max times per session. Reminders are off when Interval is zero.
+	ReminderPolicy struct {
+		Interval time.Duration
+		Max      int
+	}
+
+	Option func(*Collector)
+
+	// Collector runs document collection sessions. Sessions are updated one at a time, a
+	// Collector is safe for concurrent use.
+	Collector struct {
+		store      Store
+		blobs      BlobStore
+		media      media.Service
+		sender     wmessage.MessageSender
+		messages   Messages
+		reminders  ReminderPolicy
+		ttl        time.Duration
+		now        func() time.Time
+		onComplete func(ctx context.Context, session *Session)
+
+		mu sync.Mutex
+	}
+)
+
+// DefaultMessages returns the texts sent unless configured otherwise.
+func DefaultMessages() Messages {
+	return Messages{
+		Prompt:   "Please send us the following documents: {missing}.",
+		Received: "We received your {name} ({received}/{total}). Still missing: {missing}.",
+		Rejected: "We could not accept this file: {reason}. Still missing: {missing}.",
+		Reminder: "We are still waiting for the following documents: {missing}.",
+		Complete: "Thank you, we received all {total} documents.",
+		Expired:  "",
+	}
+}
+
+func WithMessages(messages Messages) Option {
+	return func(c *Collector) {
+		c.messages = messages
+	}
+}
+
+func WithReminderPolicy(policy ReminderPolicy) Option {
+	return func(c *Collector) {
+		c.reminders = policy
+	}
+}
+
+// WithSessionTTL sets how long sessions stay open. Sessions do not expire when ttl is zero.
+func WithSessionTTL(ttl time.Duration) Option {
+	return func(c *Collector) {
+		c.ttl = ttl
+	}
+}
+
+// WithCompletionHandler sets the function called when every document of a session was
+// received.
+func WithCompletionHandler(fn func(ctx context.Context, session *Session)) Option {
+	return func(c *Collector) {
+		c.onComplete = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(c *Collector) {
+		c.now = now
+	}
+}
+
+// New returns a Collector storing sessions in store and documents in blobs. Documents are
+// downloaded with mediaService and messages sent with sender.
+func New(store Store, blobs BlobStore, mediaService media.Service, sender wmessage.MessageSender,
+	options ...Option,
+) *Collector {
+	c := &Collector{
+		store:    store,
+		blobs:    blobs,
+		media:    mediaService,
+		sender:   sender,
+		messages: DefaultMessages(),
+		ttl:      DefaultSessionTTL,
+		now:      time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(c)
+		}
+	}
+
+	return c
+}
+
+// Rejected reports whether err is a document the collector rejected, which the contact was
+// told about.
+func Rejected(err error) bool {
+	return errors.Is(err, ErrUnexpectedDocument) || errors.Is(err, ErrUnsupportedMimeType) ||
+		errors.Is(err, ErrFileTooLarge)
+}
+
+// Start opens a session asking contact for the documents of requirements and sends the
+// prompt. phoneNumberID is the business phone number the session runs on, it may be empty.
+func (c *Collector) Start(ctx context.Context, contact, phoneNumberID string,
+	requirements ...*Requirement,
+) (*Session, error) {
+	if err := validateRequirements(requirements); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.store.Active(ctx, contact); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, contact)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("find session: %w", err)
+	}
+
+	now := c.now()
+	session := &Session{
+		ID:            newSessionID(),
+		Contact:       contact,
+		PhoneNumberID: phoneNumberID,
+		Requirements:  requirements,
+		Status:        StatusOpen,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if c.ttl > 0 {
+		session.ExpiresAt = now.Add(c.ttl)
+	}
+
+	if err := c.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("save session: %w", err)
+	}
+
+	if err := c.notify(ctx, session, c.messages.Prompt, nil, nil); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+// Cancel closes the session with the given ID.
+func (c *Collector) Cancel(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, err := c.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get session: %w", err)
+	}
+
+	if session.Status != StatusOpen {
+		return fmt.Errorf("%w: %s", ErrSessionClosed, session.Status)
+	}
+
+	session.Status = StatusCanceled
+	session.UpdatedAt = c.now()
+	if err := c.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+
+	return nil
+}
+
+// Handle processes a document or image sent by the contact described by info. It returns
+// nil when the contact has no open session. Documents that do not meet a requirement are
+// rejected with an error for which Rejected is true. A document already received, as on a
+// retried webhook delivery, is not processed again.
+func (c *Collector) Handle(ctx context.Context, info *message.Info, file *wmessage.MediaInfo) (*Session, error) {
+	if info == nil || file == nil || (info.Type != TypeDocument && info.Type != TypeImage) {
+		return nil, nil //nolint:nilnil // not a document
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, err := c.store.Active(ctx, info.From)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil //nolint:nilnil // no session
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", err)
+	}
+
+	now := c.now()
+	if !session.ExpiresAt.IsZero() && !now.Before(session.ExpiresAt) {
+		return nil, c.expire(ctx, session, now)
+	}
+
+	for _, item := range session.Items {
+		if item.MessageID == info.ID {
+			return session, nil
+		}
+	}
+
+	requirement := match(session, info.Type, file)
+	if requirement == nil {
+		return session, c.reject(ctx, session, ErrUnexpectedDocument)
+	}
+
+	item, err := c.download(ctx, session, requirement, file)
+	if err != nil {
+		if Rejected(err) {
+			return session, c.reject(ctx, session, err)
+		}
+
+		return nil, err
+	}
+
+	item.MessageID = info.ID
+	item.ReceivedAt = now
+	session.Items = append(session.Items, item)
+	session.UpdatedAt = now
+
+	complete := len(session.Missing()) == 0
+	if complete {
+		session.Status = StatusComplete
+	}
+
+	if err := c.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("save session: %w", err)
+	}
+
+	text := c.messages.Received
+	if complete {
+		text = c.messages.Complete
+		if c.onComplete != nil {
+			c.onComplete(ctx, session)
+		}
+	}
+
+	if err := c.notify(ctx, session, text, requirement, nil); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+// Handler processes the documents and images of contacts with an open session, and passes
+// the others to next.
+func (c *Collector) Handler(next message.MediaMessageHandler) message.MediaMessageHandler {
+	return message.OnMediaMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, file *wmessage.MediaInfo,
+	) error {
+		session, err := c.Handle(ctx, mctx, file)
+		if err != nil && !Rejected(err) {
+			return err
+		}
+
+		if session != nil || next == nil {
+			return nil
+		}
+
+		return next.Handle(ctx, nctx, mctx, file)
+	})
+}
+
+// Remind sends the reminders that are due and expires the sessions past their expiry. It is
+// meant to be called periodically and returns the number of reminders sent.
+func (c *Collector) Remind(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sessions, err := c.store.Open(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+
+	var (
+		sent int
+		errs []error
+		now  = c.now()
+	)
+
+	for _, session := range sessions {
+		if !session.ExpiresAt.IsZero() && !now.Before(session.ExpiresAt) {
+			errs = append(errs, c.expire(ctx, session, now))
+
+			continue
+		}
+
+		if !c.reminderDue(session, now) {
+			continue
+		}
+
+		session.Reminders++
+		session.LastReminderAt = now
+		if err := c.store.Save(ctx, session); err != nil {
+			errs = append(errs, fmt.Errorf("save session %s: %w", session.ID, err))
+
+			continue
+		}
+
+		if err := c.notify(ctx, session, c.messages.Reminder, nil, nil); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+func (c *Collector) reminderDue(session *Session, now time.Time) bool {
+	if c.reminders.Interval <= 0 || (c.reminders.Max > 0 && session.Reminders >= c.reminders.Max) {
+		return false
+	}
+
+	last := session.UpdatedAt
+	if session.LastReminderAt.After(last) {
+		last = session.LastReminderAt
+	}
+
+	return now.Sub(last) >= c.reminders.Interval
+}
+
+func (c *Collector) expire(ctx context.Context, session *Session, now time.Time) error {
+	session.Status = StatusExpired
+	session.UpdatedAt = now
+	if err := c.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("save session %s: %w", session.ID, err)
+	}
+
+	return c.notify(ctx, session, c.messages.Expired, nil, nil)
+}
+
+func (c *Collector) reject(ctx context.Context, session *Session, reason error) error {
+	if err := c.notify(ctx, session, c.messages.Rejected, nil, reason); err != nil {
+		return errors.Join(reason, err)
+	}
+
+	return reason
+}
+
+// download validates the media against requirement and stores it.
+func (c *Collector) download(ctx context.Context, session *Session, requirement *Requirement,
+	file *wmessage.MediaInfo,
+) (*Item, error) {
+	info, err := c.media.GetInfo(ctx, &media.BaseRequest{MediaID: file.ID, PhoneNumberID: session.PhoneNumberID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStoreDocument, err)
+	}
+
+	mimeType := info.MimeType
+	if mimeType == "" {
+		mimeType = file.MimeType
+	}
+
+	if err := requirement.validate(mimeType, info.FileSize); err != nil {
+		return nil, err
+	}
+
+	key := blobKey(session, requirement, file.Filename)
+	decoder := whttp.BodyReaderResponseDecoder(func(ctx context.Context, reader io.Reader) error {
+		return c.blobs.Put(ctx, key, reader, info.FileSize, mimeType)
+	})
+
+	if err := c.media.Download(ctx, &media.DownloadRequest{URL: info.URL}, decoder); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStoreDocument, err)
+	}
+
+	return &Item{
+		Requirement: requirement.Name,
+		MediaID:     file.ID,
+		MimeType:    mimeType,
+		Filename:    file.Filename,
+		Size:        info.FileSize,
+		SHA256:      info.SHA256,
+		BlobKey:     key,
+	}, nil
+}
+
+func (c *Collector) notify(ctx context.Context, session *Session, text string, requirement *Requirement,
+	reason error,
+) error {
+	if text == "" {
+		return nil
+	}
+
+	received, total := session.Progress()
+	replacements := []string{
+		"{missing}", names(session.Missing()),
+		"{received}", strconv.Itoa(received),
+		"{total}", strconv.Itoa(total),
+	}
+
+	if requirement != nil {
+		replacements = append(replacements, "{name}", requirement.Name)
+	}
+
+	if reason != nil {
+		replacements = append(replacements, "{reason}", reason.Error())
+	}
+
+	body := strings.NewReplacer(replacements...).Replace(text)
+	msg, _ := wmessage.New(session.Contact, wmessage.WithTextMessage(&wmessage.Text{Body: body}))
+
+	if _, err := c.sender.SendMessage(ctx, msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotify, err)
+	}
+
+	return nil
+}
+
+// match returns the missing requirement the file is for: the one named by its caption, or
+// the first accepting its type, preferably one accepting its MIME type too.
+func match(session *Session, mediaType string, file *wmessage.MediaInfo) *Requirement {
+	missing := session.Missing()
+	caption := strings.TrimSpace(file.Caption)
+	for _, requirement := range missing {
+		if caption != "" && strings.EqualFold(caption, requirement.Name) && requirement.accepts(mediaType) {
+			return requirement
+		}
+	}
+
+	var first *Requirement
+	for _, requirement := range missing {
+		if !requirement.accepts(mediaType) {
+			continue
+		}
+
+		if first == nil {
+			first = requirement
+		}
+
+		if len(requirement.MimeTypes) == 0 || requirement.validate(file.MimeType, 0) == nil {
+			return requirement
+		}
+	}
+
+	return first
+}
+
+func blobKey(session *Session, requirement *Requirement, filename string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+
+		return r
+	}, strings.ToLower(requirement.Name))
+
+	return session.ID + "/" + name + path.Ext(path.Base(filename))
+}
@@ -0,0 +1,247 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package doccollect runs document collection sessions, in which a contact is asked for a
+// set of documents and sends them as WhatsApp documents or images.
+//
+// A Collector starts a Session with the Requirements of the workflow and prompts the
+// contact. Every document or image the contact sends is matched to a missing requirement,
+// validated against its types, MIME types and size, downloaded to a BlobStore and
+// acknowledged with the progress of the session. Remind, called periodically, reminds
+// contacts of the missing documents and expires abandoned sessions.
+//
+//	collector := doccollect.New(doccollect.NewMemoryStore(), blobs, mediaClient, messageClient)
+//	session, err := collector.Start(ctx, "255700000001", "",
+//		&doccollect.Requirement{Name: "ID", Types: []string{doccollect.TypeImage, doccollect.TypeDocument}},
+//		&doccollect.Requirement{Name: "Payslip", MimeTypes: []string{"application/pdf"}},
+//	)
+//	handlers.DocumentMessage = collector.Handler(handlers.DocumentMessage)
+//	handlers.ImageMessage = collector.Handler(handlers.ImageMessage)
+//
+// A contact has at most one open session.
+package doccollect
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Media types a requirement accepts.
+const (
+	TypeDocument = "document"
+	TypeImage    = "image"
+)
+
+type Status string
+
+const (
+	StatusOpen     Status = "open"
+	StatusComplete Status = "complete"
+	StatusExpired  Status = "expired"
+	StatusCanceled Status = "canceled"
+)
+
+type (
+	// Requirement is a document of a session. Types are the accepted media types, documents
+	// and images when empty. MimeTypes, when set, restricts the accepted files, and MaxSize
+	// caps their size in bytes.
+	Requirement struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description,omitempty"`
+		Types       []string `json:"types,omitempty"`
+		MimeTypes   []string `json:"mime_types,omitempty"`
+		MaxSize     int64    `json:"max_size,omitempty"`
+	}
+
+	// Item is a document received for a requirement. BlobKey is the key it is stored under
+	// in the BlobStore.
+	Item struct {
+		Requirement string    `json:"requirement"`
+		MessageID   string    `json:"message_id"`
+		MediaID     string    `json:"media_id"`
+		MimeType    string    `json:"mime_type"`
+		Filename    string    `json:"filename,omitempty"`
+		Size        int64     `json:"size"`
+		SHA256      string    `json:"sha256,omitempty"`
+		BlobKey     string    `json:"blob_key"`
+		ReceivedAt  time.Time `json:"received_at"`
+	}
+
+	Session struct {
+		ID             string         `json:"id"`
+		Contact        string         `json:"contact"`
+		PhoneNumberID  string         `json:"phone_number_id,omitempty"`
+		Requirements   []*Requirement `json:"requirements"`
+		Items          []*Item        `json:"items,omitempty"`
+		Status         Status         `json:"status"`
+		CreatedAt      time.Time      `json:"created_at"`
+		UpdatedAt      time.Time      `json:"updated_at"`
+		ExpiresAt      time.Time      `json:"expires_at"`
+		Reminders      int            `json:"reminders,omitempty"`
+		LastReminderAt time.Time      `json:"last_reminder_at"`
+	}
+
+	Store interface {
+		// Save inserts the session or replaces the one with the same ID.
+		Save(ctx context.Context, session *Session) error
+		Get(ctx context.Context, id string) (*Session, error)
+
+		// Active returns the open session of contact, or ErrNotFound.
+		Active(ctx context.Context, contact string) (*Session, error)
+
+		// Open returns the open sessions.
+		Open(ctx context.Context) ([]*Session, error)
+	}
+
+	// BlobStore stores the content of the received documents. size is zero when unknown.
+	BlobStore interface {
+		Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	}
+)
+
+// Item returns the item received for the requirement name.
+func (s *Session) Item(name string) (*Item, bool) {
+	for _, item := range s.Items {
+		if item.Requirement == name {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+// Missing returns the requirements no document was received for, in order.
+func (s *Session) Missing() []*Requirement {
+	var missing []*Requirement
+	for _, requirement := range s.Requirements {
+		if _, ok := s.Item(requirement.Name); !ok {
+			missing = append(missing, requirement)
+		}
+	}
+
+	return missing
+}
+
+// Progress returns the number of documents received and required.
+func (s *Session) Progress() (int, int) {
+	return len(s.Requirements) - len(s.Missing()), len(s.Requirements)
+}
+
+func (r *Requirement) accepts(mediaType string) bool {
+	if len(r.Types) == 0 {
+		return mediaType == TypeDocument || mediaType == TypeImage
+	}
+
+	for _, t := range r.Types {
+		if t == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate checks a file against the requirement.
+func (r *Requirement) validate(mimeType string, size int64) error {
+	if len(r.MimeTypes) > 0 {
+		ok := false
+		base, _, _ := strings.Cut(mimeType, ";")
+		for _, t := range r.MimeTypes {
+			if strings.EqualFold(strings.TrimSpace(base), t) {
+				ok = true
+
+				break
+			}
+		}
+
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedMimeType, mimeType)
+		}
+	}
+
+	if r.MaxSize > 0 && size > r.MaxSize {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrFileTooLarge, size, r.MaxSize)
+	}
+
+	return nil
+}
+
+func validateRequirements(requirements []*Requirement) error {
+	if len(requirements) == 0 {
+		return fmt.Errorf("%w: no requirements", ErrInvalidRequirements)
+	}
+
+	names := make(map[string]bool, len(requirements))
+	for i, requirement := range requirements {
+		if requirement == nil || requirement.Name == "" {
+			return fmt.Errorf("%w: requirement %d has no name", ErrInvalidRequirements, i)
+		}
+
+		key := strings.ToLower(requirement.Name)
+		if names[key] {
+			return fmt.Errorf("%w: duplicate requirement %q", ErrInvalidRequirements, requirement.Name)
+		}
+		names[key] = true
+	}
+
+	return nil
+}
+
+func newSessionID() string {
+	b := make([]byte, 12) //nolint:mnd // 96 random bits
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func names(requirements []*Requirement) string {
+	parts := make([]string, len(requirements))
+	for i, requirement := range requirements {
+		parts[i] = requirement.Name
+		if requirement.Description != "" {
+			parts[i] += " (" + requirement.Description + ")"
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+type collectError string
+
+func (e collectError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNotFound            = collectError("session not found")
+	ErrSessionExists       = collectError("contact already has an open session")
+	ErrSessionClosed       = collectError("session is not open")
+	ErrInvalidSession      = collectError("session must have an id and a contact")
+	ErrInvalidRequirements = collectError("invalid requirements")
+	ErrUnexpectedDocument  = collectError("no missing document accepts this file")
+	ErrUnsupportedMimeType = collectError("file type is not accepted")
+	ErrFileTooLarge        = collectError("file is too large")
+	ErrStoreDocument       = collectError("store document failed")
+	ErrNotify              = collectError("notify contact failed")
+)
@@ -0,0 +1,143 @@
+package doccollect_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/doccollect"
+	"github.com/piusalfred/whatsapp/media"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type fakeMedia struct {
+	files map[string]*media.Information
+}
+
+func (f *fakeMedia) Upload(context.Context, *media.UploadRequest) (*media.UploadMediaResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeMedia) Delete(context.Context, *media.BaseRequest) (*media.DeleteMediaResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeMedia) GetInfo(_ context.Context, req *media.BaseRequest) (*media.Information, error) {
+	return f.files[req.MediaID], nil
+}
+
+func (f *fakeMedia) Download(ctx context.Context, req *media.DownloadRequest, decoder whttp.ResponseDecoder) error {
+	return decoder.Decode(ctx, &http.Response{Body: io.NopCloser(strings.NewReader("content of " + req.URL))})
+}
+
+type recordingSender struct {
+	sent []string
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg *wmessage.Message) (*wmessage.Response, error) {
+	s.sent = append(s.sent, msg.Text.Body)
+
+	return &wmessage.Response{}, nil
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	blobs := doccollect.NewMemoryBlobStore()
+	sender := &recordingSender{}
+	service := &fakeMedia{files: map[string]*media.Information{
+		"id-card":  {URL: "https://cdn/id-card", MimeType: "image/jpeg", FileSize: 2048},
+		"payslip":  {URL: "https://cdn/payslip", MimeType: "application/pdf", FileSize: 4096},
+		"big-scan": {URL: "https://cdn/big-scan", MimeType: "application/pdf", FileSize: 10 << 20},
+	}}
+
+	var completed *doccollect.Session
+	collector := doccollect.New(doccollect.NewMemoryStore(), blobs, service, sender,
+		doccollect.WithClock(func() time.Time { return now }),
+		doccollect.WithReminderPolicy(doccollect.ReminderPolicy{Interval: 24 * time.Hour, Max: 1}),
+		doccollect.WithCompletionHandler(func(_ context.Context, session *doccollect.Session) {
+			completed = session
+		}),
+	)
+
+	session, err := collector.Start(ctx, "255700000001", "111",
+		&doccollect.Requirement{Name: "ID", Types: []string{doccollect.TypeImage}},
+		&doccollect.Requirement{Name: "Payslip", MimeTypes: []string{"application/pdf"}, MaxSize: 1 << 20},
+	)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	if _, err := collector.Start(ctx, "255700000001", "111", &doccollect.Requirement{Name: "ID"}); err == nil {
+		t.Fatal("expected an error for a second open session")
+	}
+
+	var passed int
+	handler := collector.Handler(message.OnMediaMessageHook(func(context.Context, *message.NotificationContext,
+		*message.Info, *wmessage.MediaInfo,
+	) error {
+		passed++
+
+		return nil
+	}))
+
+	handle := func(from, id, mediaType string, file *wmessage.MediaInfo) {
+		t.Helper()
+		info := &message.Info{From: from, ID: id, Type: mediaType}
+		if err := handler.Handle(ctx, &message.NotificationContext{}, info, file); err != nil {
+			t.Fatalf("handle %s: %v", id, err)
+		}
+	}
+
+	handle("255700000001", "wamid.1", doccollect.TypeDocument, &wmessage.MediaInfo{ID: "big-scan", MimeType: "application/pdf"})
+	handle("255700000001", "wamid.2", doccollect.TypeDocument, &wmessage.MediaInfo{ID: "payslip", MimeType: "application/pdf", Filename: "March.PDF"})
+	handle("255700000001", "wamid.2", doccollect.TypeDocument, &wmessage.MediaInfo{ID: "payslip", MimeType: "application/pdf", Filename: "March.PDF"})
+	handle("255700000002", "wamid.3", doccollect.TypeImage, &wmessage.MediaInfo{ID: "id-card"})
+
+	if passed != 1 {
+		t.Fatalf("passed %d messages to the next handler, want 1", passed)
+	}
+
+	now = now.Add(25 * time.Hour)
+	for range 2 {
+		if _, err := collector.Remind(ctx); err != nil {
+			t.Fatalf("remind: %v", err)
+		}
+	}
+
+	handle("255700000001", "wamid.4", doccollect.TypeImage, &wmessage.MediaInfo{ID: "id-card", Filename: "id.jpg"})
+
+	want := []string{
+		"Please send us the following documents: ID, Payslip.",
+		"We could not accept this file: file is too large: 10485760 bytes, the limit is 1048576. Still missing: ID, Payslip.",
+		"We received your Payslip (1/2). Still missing: ID.",
+		"We are still waiting for the following documents: ID.",
+		"Thank you, we received all 2 documents.",
+	}
+
+	if strings.Join(sender.sent, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("sent messages:\n%s\nwant:\n%s", strings.Join(sender.sent, "\n"), strings.Join(want, "\n"))
+	}
+
+	if completed == nil || completed.ID != session.ID || completed.Status != doccollect.StatusComplete {
+		t.Fatalf("unexpected completed session %+v", completed)
+	}
+
+	item, ok := completed.Item("Payslip")
+	if !ok || item.BlobKey != session.ID+"/payslip.PDF" || item.Size != 4096 {
+		t.Fatalf("unexpected payslip item %+v", item)
+	}
+
+	blob, ok := blobs.Get(item.BlobKey)
+	if !ok || !bytes.Equal(blob, []byte("content of https://cdn/payslip")) {
+		t.Fatalf("unexpected payslip blob %q", blob)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package doccollect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	_ Store     = (*MemoryStore)(nil)
+	_ BlobStore = (*MemoryBlobStore)(nil)
+)
+
+// MemoryStore is a Store that keeps sessions in memory.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*Session)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, session *Session) error {
+	if session == nil || session.ID == "" || session.Contact == "" {
+		return ErrInvalidSession
+	}
+
+	stored, err := clone(session)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = stored
+
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return clone(session)
+}
+
+func (s *MemoryStore) Active(_ context.Context, contact string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, session := range s.sessions {
+		if session.Contact == contact && session.Status == StatusOpen {
+			return clone(session)
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) Open(_ context.Context) ([]*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var open []*Session
+	for _, session := range s.sessions {
+		if session.Status != StatusOpen {
+			continue
+		}
+
+		c, err := clone(session)
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, c)
+	}
+
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].CreatedAt.Before(open[j].CreatedAt)
+	})
+
+	return open, nil
+}
+
+// clone deep copies a session so that callers do not share its slices with the store.
+func clone(session *Session) (*Session, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("copy session: %w", err)
+	}
+
+	var c Session
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("copy session: %w", err)
+	}
+
+	return &c, nil
+}
+
+// MemoryBlobStore is a BlobStore that keeps the documents in memory, for tests.
+type MemoryBlobStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+func (s *MemoryBlobStore) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, content); err != nil {
+		return fmt.Errorf("read blob: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = buf.Bytes()
+
+	return nil
+}
+
+// Get returns the content stored under key.
+func (s *MemoryBlobStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blob, ok := s.blobs[key]
+
+	return blob, ok
+}
@@ -0,0 +1,511 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package emailbridge bridges WhatsApp conversations and email threads, for support tools
+// that work from a shared mailbox.
+//
+// Every conversation with a customer is mirrored by an email thread. Inbound WhatsApp
+// messages are mailed to the support mailbox as messages of the thread of the customer, and
+// replies to the thread are sent back to the customer on WhatsApp. The thread of a customer
+// is kept in their conversation session, so a new thread starts with every new session:
+//
+//	manager := conversation.NewManager(conversation.NewMemoryStore())
+//	bridge := emailbridge.New(smtpMailer, client, manager, emailbridge.NewMemoryThreadStore(),
+//		emailbridge.WithMailbox("whatsapp@example.com", "support@example.com"),
+//	)
+//	handlers.TextMessage = bridge.TextHandler(handlers.TextMessage)
+//
+//	// when the support mailbox receives an email
+//	err := bridge.Reply(ctx, email)
+//
+// The Mailer, the parsing of received emails and the ThreadStore are left to the
+// application; the package only defines how messages map to emails.
+package emailbridge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/conversation"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// SessionKey is the conversation session key holding the ID of the thread of the customer.
+const SessionKey = "emailbridge.thread"
+
+type (
+	// Email is an email sent to or received from the support mailbox. MessageID, InReplyTo
+	// and References are the Message-ID, In-Reply-To and References headers, with or without
+	// their angle brackets.
+	Email struct {
+		MessageID  string
+		InReplyTo  string
+		References []string
+		From       string
+		To         []string
+		Subject    string
+		Text       string
+		Headers    map[string]string
+	}
+
+	// Mailer sends emails, over SMTP or the API of an email provider.
+	Mailer interface {
+		Send(ctx context.Context, email *Email) error
+	}
+
+	MailerFunc func(ctx context.Context, email *Email) error
+
+	// Thread links an email thread to a customer. MessageIDs are the IDs of the emails of
+	// the thread, the first one being the root.
+	Thread struct {
+		ID            string    `json:"id"`
+		Contact       string    `json:"contact"`
+		Name          string    `json:"name,omitempty"`
+		PhoneNumberID string    `json:"phone_number_id,omitempty"`
+		Subject       string    `json:"subject"`
+		MessageIDs    []string  `json:"message_ids"`
+		CreatedAt     time.Time `json:"created_at"`
+		UpdatedAt     time.Time `json:"updated_at"`
+	}
+
+	// ThreadStore persists threads. Get and ByMessageID return ErrThreadNotFound for unknown
+	// threads.
+	ThreadStore interface {
+		Save(ctx context.Context, thread *Thread) error
+		Get(ctx context.Context, id string) (*Thread, error)
+
+		// ByMessageID returns the thread containing the email with the given Message-ID.
+		ByMessageID(ctx context.Context, messageID string) (*Thread, error)
+	}
+
+	Option func(*Bridge)
+
+	// Bridge mirrors conversations to email threads and back.
+	Bridge struct {
+		mailer  Mailer
+		sender  wmessage.MessageSender
+		manager *conversation.Manager
+		threads ThreadStore
+		from    string
+		to      []string
+		domain  string
+		subject func(thread *Thread) string
+		newID   func() string
+		now     func() time.Time
+	}
+)
+
+func (fn MailerFunc) Send(ctx context.Context, email *Email) error {
+	return fn(ctx, email)
+}
+
+// Root returns the Message-ID of the first email of the thread.
+func (t *Thread) Root() string {
+	if len(t.MessageIDs) == 0 {
+		return ""
+	}
+
+	return t.MessageIDs[0]
+}
+
+// Last returns the Message-ID of the last email of the thread.
+func (t *Thread) Last() string {
+	if len(t.MessageIDs) == 0 {
+		return ""
+	}
+
+	return t.MessageIDs[len(t.MessageIDs)-1]
+}
+
+// WithMailbox sets the address the bridge mails from and the support mailboxes it mails to.
+// The domain of from is used in the generated Message-IDs.
+func WithMailbox(from string, to ...string) Option {
+	return func(b *Bridge) {
+		b.from = from
+		b.to = to
+		if _, domain, ok := strings.Cut(from, "@"); ok {
+			b.domain = strings.TrimSuffix(domain, ">")
+		}
+	}
+}
+
+// WithSubject sets the subject of new threads, "WhatsApp conversation with <name> (<wa_id>)"
+// by default.
+func WithSubject(fn func(thread *Thread) string) Option {
+	return func(b *Bridge) {
+		b.subject = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(b *Bridge) {
+		b.now = now
+	}
+}
+
+func New(mailer Mailer, sender wmessage.MessageSender, manager *conversation.Manager, threads ThreadStore,
+	options ...Option,
+) *Bridge {
+	b := &Bridge{
+		mailer:  mailer,
+		sender:  sender,
+		manager: manager,
+		threads: threads,
+		domain:  "whatsapp.invalid",
+		subject: defaultSubject,
+		newID:   newID,
+		now:     time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(b)
+		}
+	}
+
+	return b
+}
+
+func defaultSubject(thread *Thread) string {
+	if thread.Name == "" {
+		return "WhatsApp conversation with " + thread.Contact
+	}
+
+	return fmt.Sprintf("WhatsApp conversation with %s (%s)", thread.Name, thread.Contact)
+}
+
+// TextHandler mails the text messages to the thread of the sender before calling next. It
+// runs next within the conversation session of the sender.
+func (b *Bridge) TextHandler(next message.TextMessageHandler) message.TextMessageHandler {
+	inner := message.OnTextMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, text *message.Text,
+	) error {
+		if err := b.Forward(ctx, nctx, mctx, text.Body); err != nil {
+			return err
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		return next.Handle(ctx, nctx, mctx, text)
+	})
+
+	return conversation.Handler[message.Text](b.manager, inner)
+}
+
+// MediaHandler mails a notice of the media messages, with their caption, to the thread of
+// the sender before calling next. The media itself is not attached.
+func (b *Bridge) MediaHandler(next message.MediaMessageHandler) message.MediaMessageHandler {
+	inner := message.OnMediaMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, media *wmessage.MediaInfo,
+	) error {
+		text := fmt.Sprintf("[%s %s]", mctx.Type, media.ID)
+		if media.Caption != "" {
+			text += "\n" + media.Caption
+		}
+
+		if err := b.Forward(ctx, nctx, mctx, text); err != nil {
+			return err
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		return next.Handle(ctx, nctx, mctx, media)
+	})
+
+	return conversation.Handler[wmessage.MediaInfo](b.manager, inner)
+}
+
+// Forward mails text, received in the message described by mctx, to the thread of the
+// sender, starting the thread when the conversation session has none. ctx must carry the
+// session of the sender, as set by the handlers of the conversation package.
+func (b *Bridge) Forward(ctx context.Context, nctx *message.NotificationContext, mctx *message.Info,
+	text string,
+) error {
+	session, ok := conversation.FromContext(ctx)
+	if !ok {
+		return ErrNoSession
+	}
+
+	thread, err := b.thread(ctx, session, nctx)
+	if err != nil {
+		return err
+	}
+
+	email := &Email{
+		MessageID: b.messageID(),
+		From:      b.from,
+		To:        b.to,
+		Subject:   thread.Subject,
+		Text:      text,
+		Headers: map[string]string{
+			"X-WhatsApp-Contact":    thread.Contact,
+			"X-WhatsApp-Message-ID": mctx.ID,
+		},
+	}
+
+	if root := thread.Root(); root != "" {
+		email.Subject = "Re: " + thread.Subject
+		email.InReplyTo = thread.Last()
+		email.References = append([]string(nil), thread.MessageIDs...)
+	}
+
+	if err := b.mailer.Send(ctx, email); err != nil {
+		return fmt.Errorf("%w: %w", ErrMail, err)
+	}
+
+	thread.MessageIDs = append(thread.MessageIDs, email.MessageID)
+	thread.UpdatedAt = b.now()
+	if err := b.threads.Save(ctx, thread); err != nil {
+		return fmt.Errorf("save thread: %w", err)
+	}
+
+	return nil
+}
+
+// Reply sends the text of an email replying to a thread to the customer of the thread,
+// without the quoted text. The thread is found from the In-Reply-To and References
+// headers. Replies are refused with ErrWindowClosed once the customer service window of
+// the conversation is closed, as only templates can be sent then.
+func (b *Bridge) Reply(ctx context.Context, email *Email) error {
+	thread, err := b.findThread(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: thread.PhoneNumberID}}
+	session, err := b.manager.Load(ctx, nctx, thread.Contact)
+	if err != nil {
+		return err
+	}
+
+	if !session.WindowOpen(b.now()) {
+		return fmt.Errorf("%w: %s", ErrWindowClosed, thread.Contact)
+	}
+
+	text := StripQuoted(email.Text)
+	if text == "" {
+		return ErrEmptyReply
+	}
+
+	msg, _ := wmessage.New(thread.Contact, wmessage.WithTextMessage(&wmessage.Text{Body: text}))
+	if _, err := b.sender.SendMessage(ctx, msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrSend, err)
+	}
+
+	if email.MessageID != "" {
+		thread.MessageIDs = append(thread.MessageIDs, normalizeID(email.MessageID))
+		thread.UpdatedAt = b.now()
+		if err := b.threads.Save(ctx, thread); err != nil {
+			return fmt.Errorf("save thread: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// thread returns the thread of the session, starting one when there is none.
+func (b *Bridge) thread(ctx context.Context, session *conversation.Session,
+	nctx *message.NotificationContext,
+) (*Thread, error) {
+	if id, ok := session.Get(SessionKey); ok {
+		if id, ok := id.(string); ok {
+			thread, err := b.threads.Get(ctx, id)
+			if err == nil {
+				return thread, nil
+			}
+
+			if !errors.Is(err, ErrThreadNotFound) {
+				return nil, fmt.Errorf("get thread: %w", err)
+			}
+		}
+	}
+
+	now := b.now()
+	thread := &Thread{
+		ID:            b.newID(),
+		Contact:       session.WaID,
+		PhoneNumberID: session.PhoneNumberID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if nctx != nil {
+		for _, contact := range nctx.Contacts {
+			if contact.WaID == session.WaID && contact.Profile != nil {
+				thread.Name = contact.Profile.Name
+			}
+		}
+	}
+	thread.Subject = b.subject(thread)
+	session.Set(SessionKey, thread.ID)
+
+	return thread, nil
+}
+
+func (b *Bridge) findThread(ctx context.Context, email *Email) (*Thread, error) {
+	if email == nil {
+		return nil, ErrThreadNotFound
+	}
+
+	ids := append([]string{email.InReplyTo}, email.References...)
+	for i := len(ids) - 1; i >= 0; i-- {
+		id := normalizeID(ids[i])
+		if id == "" {
+			continue
+		}
+
+		thread, err := b.threads.ByMessageID(ctx, id)
+		if err == nil {
+			return thread, nil
+		}
+
+		if !errors.Is(err, ErrThreadNotFound) {
+			return nil, fmt.Errorf("find thread: %w", err)
+		}
+	}
+
+	return nil, ErrThreadNotFound
+}
+
+func (b *Bridge) messageID() string {
+	return b.newID() + "@" + b.domain
+}
+
+// StripQuoted returns the text of an email reply without the quoted message: the lines
+// starting with ">" and everything after a "On ... wrote:" line.
+func StripQuoted(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "On ") && strings.HasSuffix(trimmed, "wrote:") {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func normalizeID(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+}
+
+func newID() string {
+	b := make([]byte, 12) //nolint:mnd // 96 random bits
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+var _ ThreadStore = (*MemoryThreadStore)(nil)
+
+// MemoryThreadStore is a ThreadStore that keeps threads in memory.
+type MemoryThreadStore struct {
+	mu       sync.RWMutex
+	threads  map[string]*Thread
+	messages map[string]string
+}
+
+func NewMemoryThreadStore() *MemoryThreadStore {
+	return &MemoryThreadStore{
+		threads:  make(map[string]*Thread),
+		messages: make(map[string]string),
+	}
+}
+
+func (s *MemoryThreadStore) Save(_ context.Context, thread *Thread) error {
+	if thread == nil || thread.ID == "" {
+		return ErrInvalidThread
+	}
+
+	stored := *thread
+	stored.MessageIDs = append([]string(nil), thread.MessageIDs...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.threads[thread.ID] = &stored
+	for _, id := range stored.MessageIDs {
+		s.messages[id] = thread.ID
+	}
+
+	return nil
+}
+
+func (s *MemoryThreadStore) Get(_ context.Context, id string) (*Thread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.get(id)
+}
+
+func (s *MemoryThreadStore) ByMessageID(_ context.Context, messageID string) (*Thread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.messages[messageID]
+	if !ok {
+		return nil, ErrThreadNotFound
+	}
+
+	return s.get(id)
+}
+
+func (s *MemoryThreadStore) get(id string) (*Thread, error) {
+	thread, ok := s.threads[id]
+	if !ok {
+		return nil, ErrThreadNotFound
+	}
+
+	t := *thread
+	t.MessageIDs = append([]string(nil), thread.MessageIDs...)
+
+	return &t, nil
+}
+
+type bridgeError string
+
+func (e bridgeError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNoSession      = bridgeError("no conversation session in context")
+	ErrThreadNotFound = bridgeError("email thread not found")
+	ErrInvalidThread  = bridgeError("thread must have an id")
+	ErrWindowClosed   = bridgeError("customer service window is closed")
+	ErrEmptyReply     = bridgeError("email reply has no text")
+	ErrMail           = bridgeError("mail message failed")
+	ErrSend           = bridgeError("send reply failed")
+)
@@ -0,0 +1,113 @@
+package emailbridge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/conversation"
+	"github.com/piusalfred/whatsapp/emailbridge"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type recordingSender struct {
+	sent []*wmessage.Message
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg *wmessage.Message) (*wmessage.Response, error) {
+	s.sent = append(s.sent, msg)
+
+	return &wmessage.Response{}, nil
+}
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	var mails []*emailbridge.Email
+	mailer := emailbridge.MailerFunc(func(_ context.Context, email *emailbridge.Email) error {
+		mails = append(mails, email)
+
+		return nil
+	})
+
+	sender := &recordingSender{}
+	manager := conversation.NewManager(conversation.NewMemoryStore(), conversation.WithClock(clock))
+	bridge := emailbridge.New(mailer, sender, manager, emailbridge.NewMemoryThreadStore(),
+		emailbridge.WithMailbox("whatsapp@example.com", "support@example.com"),
+		emailbridge.WithClock(clock),
+	)
+
+	var handled int
+	handler := bridge.TextHandler(message.OnTextMessageHook(func(context.Context, *message.NotificationContext,
+		*message.Info, *message.Text,
+	) error {
+		handled++
+
+		return nil
+	}))
+
+	nctx := &message.NotificationContext{
+		Metadata: &message.Metadata{PhoneNumberID: "phone"},
+		Contacts: []*message.Contact{{WaID: "255700000001", Profile: &message.Profile{Name: "Amani"}}},
+	}
+	for i, body := range []string{"My order is late", "It was due Monday"} {
+		info := &message.Info{From: "255700000001", ID: "wamid." + string(rune('a'+i)), Type: "text"}
+		if err := handler.Handle(ctx, nctx, info, &message.Text{Body: body}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	if handled != 2 || len(mails) != 2 {
+		t.Fatalf("handled %d messages and mailed %d, want 2 and 2", handled, len(mails))
+	}
+
+	if got, want := mails[0].Subject, "WhatsApp conversation with Amani (255700000001)"; got != want {
+		t.Errorf("subject = %q, want %q", got, want)
+	}
+
+	if mails[1].Subject != "Re: "+mails[0].Subject || mails[1].InReplyTo != mails[0].MessageID ||
+		!gcmp.Equal(mails[1].References, []string{mails[0].MessageID}) {
+		t.Errorf("second email is not threaded: %+v", mails[1])
+	}
+
+	err := bridge.Reply(ctx, &emailbridge.Email{
+		MessageID: "<reply-1@example.com>",
+		InReplyTo: "<" + mails[1].MessageID + ">",
+		Text:      "Sorry, it ships today.\n\nOn Fri, Mar 1, 2024 whatsapp@example.com wrote:\n> It was due Monday",
+	})
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+
+	if len(sender.sent) != 1 || sender.sent[0].To != "255700000001" || sender.sent[0].Text.Body != "Sorry, it ships today." {
+		t.Fatalf("Reply() sent %+v", sender.sent)
+	}
+
+	// replies to the reply are threaded too, until the window closes.
+	now = now.Add(25 * time.Hour)
+	err = bridge.Reply(ctx, &emailbridge.Email{References: []string{"reply-1@example.com"}, Text: "Any update?"})
+	if !errors.Is(err, emailbridge.ErrWindowClosed) {
+		t.Errorf("Reply() error = %v, want ErrWindowClosed", err)
+	}
+
+	if err := bridge.Reply(ctx, &emailbridge.Email{InReplyTo: "unknown@example.com"}); !errors.Is(err, emailbridge.ErrThreadNotFound) {
+		t.Errorf("Reply() error = %v, want ErrThreadNotFound", err)
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	t.Parallel()
+
+	got := emailbridge.StripQuoted("Thanks!\r\n> earlier text\r\nBest\r\n")
+	if got != "Thanks!\nBest" {
+		t.Errorf("StripQuoted() = %q", got)
+	}
+}
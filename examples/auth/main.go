@@ -3,8 +3,6 @@ package main
 import (
 	"context"
 	"log/slog"
-	"net/http"
-	"net/http/httputil"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -62,27 +60,7 @@ func main() {
 	}))
 
 	clientOptions := []whttp.CoreClientOption[any]{
-		whttp.WithCoreClientRequestInterceptor[any](
-			func(ctx context.Context, req *http.Request) error {
-				logger.LogAttrs(ctx, slog.LevelInfo, "request intercepted",
-					slog.String("http.request.method", req.Method),
-					slog.String("http.request.url", req.URL.String()),
-				)
-				return nil
-			},
-		),
-		whttp.WithCoreClientResponseInterceptor[any](
-			func(ctx context.Context, resp *http.Response) error {
-				dumpResponse, _ := httputil.DumpResponse(resp, true)
-
-				logger.LogAttrs(ctx, slog.LevelInfo, "response intercepted",
-					slog.String("http.response.status", resp.Status),
-					slog.Int("http.response.code", resp.StatusCode),
-					slog.String("body", string(dumpResponse)),
-				)
-				return nil
-			},
-		),
+		whttp.WithCoreClientLogger[any](logger, whttp.WithLogBodies(0)),
 	}
 	ctx := context.Background()
 
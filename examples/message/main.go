@@ -21,15 +21,12 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/piusalfred/whatsapp/config"
 	"github.com/piusalfred/whatsapp/message"
-	"github.com/piusalfred/whatsapp/pkg/crypto"
 	whttp "github.com/piusalfred/whatsapp/pkg/http"
 )
 
@@ -61,9 +58,6 @@ func LoadConfigFromFile(filepath string) (config.ReaderFunc, string) {
 		return conf, nil
 	}
 
-	prrof, _ := crypto.GenerateAppSecretProof(conf.AccessToken, conf.AppSecret)
-	fmt.Println("PROOOOOF" + prrof)
-
 	return fn, recipient
 }
 
@@ -74,24 +68,7 @@ func main() {
 	}))
 
 	clientOptions := []whttp.CoreClientOption[message.Message]{
-		whttp.WithCoreClientRequestInterceptor[message.Message](
-			func(ctx context.Context, req *http.Request) error {
-				logger.LogAttrs(ctx, slog.LevelInfo, "request intercepted",
-					slog.String("http.request.method", req.Method),
-					slog.String("http.request.url", req.URL.String()),
-				)
-				return nil
-			},
-		),
-		whttp.WithCoreClientResponseInterceptor[message.Message](
-			func(ctx context.Context, resp *http.Response) error {
-				logger.LogAttrs(ctx, slog.LevelInfo, "response intercepted",
-					slog.String("http.response.status", resp.Status),
-					slog.Int("http.response.code", resp.StatusCode),
-				)
-				return nil
-			},
-		),
+		whttp.WithCoreClientLogger[message.Message](logger),
 	}
 
 	ctx := context.Background()
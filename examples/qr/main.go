@@ -60,18 +60,7 @@ func main() {
 
 	clientOptions := []whttp.CoreClientOption[any]{
 		whttp.WithCoreClientHTTPClient[any](http.DefaultClient),
-		whttp.WithCoreClientRequestInterceptor[any](
-			func(ctx context.Context, req *http.Request) error {
-				logger.Info("Request Intercepted", slog.String("url", req.URL.String()))
-				return nil
-			},
-		),
-		whttp.WithCoreClientResponseInterceptor[any](
-			func(ctx context.Context, resp *http.Response) error {
-				logger.Info("Response Intercepted", slog.Int("status", resp.StatusCode))
-				return nil
-			},
-		),
+		whttp.WithCoreClientLogger[any](logger),
 		whttp.WithCoreClientMiddlewares(middlewares...),
 	}
 
@@ -24,19 +24,20 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 
+	"github.com/piusalfred/whatsapp/pkg/pretty"
 	"github.com/piusalfred/whatsapp/webhooks"
 	"github.com/piusalfred/whatsapp/webhooks/business"
 	"github.com/piusalfred/whatsapp/webhooks/message"
+	"github.com/piusalfred/whatsapp/webhooks/router"
 )
 
 func HandleBusinessNotification(ctx context.Context, notification *business.Notification) *webhooks.Response {
-	fmt.Printf("Business notification received: %+v\n", notification)
 	return &webhooks.Response{StatusCode: http.StatusOK}
 }
 
 func HandleMessageNotification(ctx context.Context, notification *message.Notification) *webhooks.Response {
-	fmt.Printf("Message notification received: %+v\n", notification)
 	return &webhooks.Response{StatusCode: http.StatusOK}
 }
 
@@ -60,6 +61,9 @@ func AddMetadataMiddleware[T any](next webhooks.NotificationHandlerFunc[T]) webh
 }
 
 func main() {
+	// notifications are printed as colorized trees.
+	printer := &pretty.Printer{Color: true}
+
 	messageListener := webhooks.NewListener(
 		HandleMessageNotification,
 		func(ctx context.Context) (string, error) {
@@ -71,6 +75,7 @@ func main() {
 		},
 		LoggingMiddleware[message.Notification],
 		AddMetadataMiddleware[message.Notification],
+		webhooks.PrettyPrintMiddleware[message.Notification](os.Stdout, printer),
 	)
 
 	businessListener := webhooks.NewListener(
@@ -84,12 +89,11 @@ func main() {
 		},
 		LoggingMiddleware[business.Notification],
 		AddMetadataMiddleware[business.Notification],
+		webhooks.PrettyPrintMiddleware[business.Notification](os.Stdout, printer),
 	)
 
-	http.HandleFunc("POST /webhooks/messages", messageListener.HandleNotification)
-	http.HandleFunc("POST /webhooks/business", businessListener.HandleNotification)
-	http.HandleFunc("POST /webhooks/messages/verify", messageListener.HandleSubscriptionVerification)
-	http.HandleFunc("POST /webhooks/business/verify", businessListener.HandleSubscriptionVerification)
+	router.Mount(http.DefaultServeMux, "/webhooks/messages", messageListener)
+	router.Mount(http.DefaultServeMux, "/webhooks/business", businessListener)
 
 	fmt.Println("Starting server on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
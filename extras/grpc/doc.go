@@ -0,0 +1,41 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package grpc exposes the events of a webhooks/events Hub as a gRPC stream, so services
+// written in other languages can subscribe to the notifications received by the webhook
+// listener. The schema is in proto/whatsapp/events/v1/events.proto.
+//
+// It is a separate module to keep gRPC out of the dependencies of the main module. The
+// generated protobuf package eventsv1 is committed, after changing the schema regenerate
+// it with protoc, protoc-gen-go and protoc-gen-go-grpc installed:
+//
+//	task generate-grpc-extras  # or go generate ./... in extras/grpc
+//
+// The server is registered with the Hub the webhook listener publishes to:
+//
+//	import wgrpc "github.com/piusalfred/whatsapp/extras/grpc"
+//
+//	hub := events.NewHub()
+//	listener := webhooks.NewListener(handlers.HandleNotification, verifier, opts, hub.Middleware())
+//
+//	server := grpc.NewServer()
+//	wgrpc.Register(server, hub)
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/whatsapp/events/v1/events.proto
@@ -0,0 +1,237 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"encoding/json"
+
+	ggrpc "google.golang.org/grpc"
+
+	eventsv1 "github.com/piusalfred/whatsapp/extras/grpc/proto/whatsapp/events/v1"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/events"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// Server implements eventsv1.EventStreamServer on top of an events.Hub.
+type Server struct {
+	eventsv1.UnimplementedEventStreamServer
+
+	hub *events.Hub
+}
+
+var _ eventsv1.EventStreamServer = (*Server)(nil)
+
+// NewServer creates a Server streaming the events of hub.
+func NewServer(hub *events.Hub) *Server {
+	return &Server{hub: hub}
+}
+
+// Register registers a Server streaming the events of hub with registrar.
+func Register(registrar ggrpc.ServiceRegistrar, hub *events.Hub) {
+	eventsv1.RegisterEventStreamServer(registrar, NewServer(hub))
+}
+
+// Subscribe streams the events matching the request until the stream context is done or
+// the Hub is closed. Events dropped because the client is too slow are not resent.
+func (s *Server) Subscribe(req *eventsv1.SubscribeRequest, stream eventsv1.EventStream_SubscribeServer) error {
+	subscription := s.hub.Subscribe(FilterFromProto(req))
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+
+			if err := stream.Send(EventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// FilterFromProto converts a subscribe request to an events.Filter.
+func FilterFromProto(req *eventsv1.SubscribeRequest) *events.Filter {
+	filter := &events.Filter{
+		PhoneNumberIDs: req.GetPhoneNumberIds(),
+		WaIDs:          req.GetWaIds(),
+	}
+
+	for _, kind := range req.GetKinds() {
+		switch kind {
+		case eventsv1.Kind_KIND_MESSAGE:
+			filter.Kinds = append(filter.Kinds, events.KindMessage)
+		case eventsv1.Kind_KIND_STATUS:
+			filter.Kinds = append(filter.Kinds, events.KindStatus)
+		case eventsv1.Kind_KIND_ERROR:
+			filter.Kinds = append(filter.Kinds, events.KindError)
+		case eventsv1.Kind_KIND_UNSPECIFIED:
+		}
+	}
+
+	return filter
+}
+
+// EventToProto converts a normalized event to its protobuf message.
+func EventToProto(event *events.Event) *eventsv1.Event {
+	out := &eventsv1.Event{
+		Id:                 event.ID,
+		BusinessAccountId:  event.BusinessAccountID,
+		PhoneNumberId:      event.PhoneNumberID,
+		DisplayPhoneNumber: event.DisplayPhoneNumber,
+		WaId:               event.WaID,
+		ProfileName:        event.ProfileName,
+		Timestamp:          event.Timestamp.Unix(),
+	}
+
+	var payload any
+	switch event.Kind {
+	case events.KindMessage:
+		out.Kind = eventsv1.Kind_KIND_MESSAGE
+		out.Message = messageToProto(event.Message)
+		payload = event.Message
+	case events.KindStatus:
+		out.Kind = eventsv1.Kind_KIND_STATUS
+		out.Status = statusToProto(event.Status)
+		payload = event.Status
+	case events.KindError:
+		out.Kind = eventsv1.Kind_KIND_ERROR
+		out.Error = errorToProto(event.Error)
+		payload = event.Error
+	}
+
+	if payload != nil {
+		out.PayloadJson, _ = json.Marshal(payload)
+	}
+
+	return out
+}
+
+func messageToProto(msg *message.Message) *eventsv1.Message {
+	if msg == nil {
+		return nil
+	}
+
+	out := &eventsv1.Message{Id: msg.ID, From: msg.From, Type: msg.Type}
+	if msg.Context != nil {
+		out.ContextMessageId = msg.Context.ID
+	}
+
+	if msg.Text != nil {
+		out.Text = msg.Text.Body
+	}
+
+	for _, media := range []*wmessage.MediaInfo{msg.Image, msg.Video, msg.Audio, msg.Document, msg.Sticker} {
+		if media != nil {
+			out.Media = mediaToProto(media)
+		}
+	}
+
+	if msg.Button != nil {
+		out.Text = msg.Button.Text
+		out.Reply = &eventsv1.Reply{Id: msg.Button.Payload, Title: msg.Button.Text}
+	}
+
+	if msg.Interactive != nil {
+		switch {
+		case msg.Interactive.ButtonReply != nil:
+			reply := msg.Interactive.ButtonReply
+			out.Reply = &eventsv1.Reply{Id: reply.ID, Title: reply.Title}
+		case msg.Interactive.ListReply != nil:
+			reply := msg.Interactive.ListReply
+			out.Reply = &eventsv1.Reply{Id: reply.ID, Title: reply.Title, Description: reply.Description}
+		}
+	}
+
+	if msg.Location != nil {
+		out.Location = &eventsv1.Location{
+			Latitude:  msg.Location.Latitude,
+			Longitude: msg.Location.Longitude,
+			Name:      msg.Location.Name,
+			Address:   msg.Location.Address,
+		}
+	}
+
+	if msg.Reaction != nil {
+		out.Reaction = &eventsv1.Reaction{MessageId: msg.Reaction.MessageID, Emoji: msg.Reaction.Emoji}
+	}
+
+	return out
+}
+
+func mediaToProto(info *wmessage.MediaInfo) *eventsv1.Media {
+	return &eventsv1.Media{
+		Id:       info.ID,
+		MimeType: info.MimeType,
+		Sha256:   info.Sha256,
+		Caption:  info.Caption,
+		Filename: info.Filename,
+	}
+}
+
+func statusToProto(status *message.Status) *eventsv1.Status {
+	if status == nil {
+		return nil
+	}
+
+	out := &eventsv1.Status{
+		MessageId:             status.ID,
+		Status:                status.StatusValue,
+		RecipientId:           status.RecipientID,
+		BizOpaqueCallbackData: status.BizOpaqueCallbackData,
+	}
+
+	if status.Conversation != nil {
+		out.ConversationId = status.Conversation.ID
+	}
+
+	if status.Pricing != nil {
+		out.PricingCategory = status.Pricing.Category
+	}
+
+	for _, err := range status.Errors {
+		out.Errors = append(out.Errors, errorToProto(err))
+	}
+
+	return out
+}
+
+func errorToProto(err *werrors.Error) *eventsv1.Error {
+	if err == nil {
+		return nil
+	}
+
+	out := &eventsv1.Error{
+		Code:    int32(err.Code), //nolint:gosec // Graph API error codes fit in an int32
+		Title:   err.Title,
+		Message: err.Message,
+	}
+
+	if err.Data != nil {
+		out.Details = err.Data.Details
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	wgrpc "github.com/piusalfred/whatsapp/extras/grpc"
+	eventsv1 "github.com/piusalfred/whatsapp/extras/grpc/proto/whatsapp/events/v1"
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/events"
+	wmessage "github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestServerSubscribe(t *testing.T) {
+	t.Parallel()
+
+	hub := events.NewHub()
+	listener := bufconn.Listen(1 << 20)
+	server := ggrpc.NewServer()
+	wgrpc.Register(server, hub)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := ggrpc.NewClient("passthrough:///bufnet",
+		ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := eventsv1.NewEventStreamClient(conn).Subscribe(ctx, &eventsv1.SubscribeRequest{
+		Kinds: []eventsv1.Kind{eventsv1.Kind_KIND_MESSAGE},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := &events.Event{
+		ID:     "wamid.status",
+		Kind:   events.KindStatus,
+		WaID:   "255700000000",
+		Status: &wmessage.Status{ID: "wamid.status", StatusValue: "read"},
+	}
+	msg := &events.Event{
+		ID:            "wamid.message",
+		Kind:          events.KindMessage,
+		PhoneNumberID: "1234567890",
+		WaID:          "255700000000",
+		Timestamp:     time.Unix(1700000000, 0),
+		Message: &wmessage.Message{
+			ID:    "wamid.message",
+			From:  "255700000000",
+			Type:  "text",
+			Text:  &wmessage.Text{Body: "hello"},
+			Image: &message.MediaInfo{ID: "media-id", MimeType: "image/jpeg"},
+		},
+	}
+
+	// the subscription is registered by the server handler after the stream is opened,
+	// so publish until the first event arrives.
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				hub.Publish(status, msg)
+			}
+		}
+	}()
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.GetKind() != eventsv1.Kind_KIND_MESSAGE {
+		t.Fatalf("kind = %v, want %v", event.GetKind(), eventsv1.Kind_KIND_MESSAGE)
+	}
+
+	if event.GetId() != "wamid.message" || event.GetPhoneNumberId() != "1234567890" ||
+		event.GetTimestamp() != 1700000000 {
+		t.Errorf("unexpected event %v", event)
+	}
+
+	if got := event.GetMessage().GetText(); got != "hello" {
+		t.Errorf("text = %q, want %q", got, "hello")
+	}
+
+	if got := event.GetMessage().GetMedia().GetId(); got != "media-id" {
+		t.Errorf("media id = %q, want %q", got, "media-id")
+	}
+
+	if len(event.GetPayloadJson()) == 0 {
+		t.Error("payload_json is empty")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package otel records the measurements of the webhook handlers with OpenTelemetry
+// metric instruments.
+//
+//	recorder, err := otel.NewRecorder(otel.GetMeterProvider().Meter("whatsapp"))
+//	handlers.SetMetricsRecorder(recorder)
+//
+// The instruments are:
+//
+//	whatsapp.webhook.notifications              notifications handled, by outcome
+//	whatsapp.webhook.notification.duration      time spent handling notifications, in seconds
+//	whatsapp.webhook.handled                    items handled, by field, kind and type
+//	whatsapp.webhook.handler.duration           time spent in the handlers of the items
+//	whatsapp.webhook.handler.errors             items whose handler failed
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+var _ message.MetricsRecorder = (*Recorder)(nil)
+
+// Recorder is a message.MetricsRecorder recording with OpenTelemetry instruments.
+type Recorder struct {
+	notifications        metric.Int64Counter
+	notificationDuration metric.Float64Histogram
+	handled              metric.Int64Counter
+	handlerDuration      metric.Float64Histogram
+	handlerErrors        metric.Int64Counter
+}
+
+// NewRecorder creates a Recorder with instruments created by meter.
+func NewRecorder(meter metric.Meter) (*Recorder, error) {
+	var (
+		r    Recorder
+		err  error
+		errs []error
+	)
+
+	r.notifications, err = meter.Int64Counter("whatsapp.webhook.notifications",
+		metric.WithDescription("Number of webhook notifications handled."))
+	errs = append(errs, err)
+
+	r.notificationDuration, err = meter.Float64Histogram("whatsapp.webhook.notification.duration",
+		metric.WithDescription("Time spent handling webhook notifications."), metric.WithUnit("s"))
+	errs = append(errs, err)
+
+	r.handled, err = meter.Int64Counter("whatsapp.webhook.handled",
+		metric.WithDescription("Number of messages, statuses and other notification items handled."))
+	errs = append(errs, err)
+
+	r.handlerDuration, err = meter.Float64Histogram("whatsapp.webhook.handler.duration",
+		metric.WithDescription("Time spent in the handlers of notification items."), metric.WithUnit("s"))
+	errs = append(errs, err)
+
+	r.handlerErrors, err = meter.Int64Counter("whatsapp.webhook.handler.errors",
+		metric.WithDescription("Number of notification items whose handler failed."))
+	errs = append(errs, err)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("create webhook instruments: %w", err)
+	}
+
+	return &r, nil
+}
+
+// RecordNotification implements message.MetricsRecorder.
+func (r *Recorder) RecordNotification(ctx context.Context, duration time.Duration, err error) {
+	attrs := metric.WithAttributes(attribute.String("outcome", outcomeOf(err)))
+	r.notifications.Add(ctx, 1, attrs)
+	r.notificationDuration.Record(ctx, duration.Seconds(), attrs)
+}
+
+// RecordHandled implements message.MetricsRecorder.
+func (r *Recorder) RecordHandled(ctx context.Context, event *message.MetricsEvent) {
+	attrs := metric.WithAttributes(
+		attribute.String("field", event.Field),
+		attribute.String("kind", event.Kind),
+		attribute.String("type", event.Type),
+	)
+
+	r.handled.Add(ctx, 1, attrs)
+	r.handlerDuration.Record(ctx, event.Duration.Seconds(), attrs)
+	if event.Err != nil {
+		r.handlerErrors.Add(ctx, 1, attrs)
+	}
+}
+
+func outcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package prometheus exports the measurements of the webhook handlers as Prometheus
+// metrics.
+//
+//	recorder, err := prometheus.NewRecorder(prom.DefaultRegisterer)
+//	handlers.SetMetricsRecorder(recorder)
+//
+// The metrics, with the "whatsapp_webhook" prefix by default, are:
+//
+//	notifications_total{outcome}                 notifications handled, outcome is "ok", "timeout" or "error"
+//	notification_duration_seconds{outcome}       time spent handling notifications
+//	handled_total{field,kind,type}               messages, statuses and other items handled
+//	handler_duration_seconds{field,kind,type}    time spent in the handlers of the items
+//	handler_errors_total{field,kind,type}        items whose handler failed
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const DefaultNamespace = "whatsapp"
+
+var _ message.MetricsRecorder = (*Recorder)(nil)
+
+type (
+	// Recorder is a message.MetricsRecorder updating Prometheus collectors.
+	Recorder struct {
+		notifications        *prometheus.CounterVec
+		notificationDuration *prometheus.HistogramVec
+		handled              *prometheus.CounterVec
+		handlerDuration      *prometheus.HistogramVec
+		handlerErrors        *prometheus.CounterVec
+	}
+
+	Option func(*options)
+
+	options struct {
+		namespace string
+		buckets   []float64
+	}
+)
+
+// WithNamespace sets the namespace of the metrics, DefaultNamespace by default.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the duration histograms, prometheus.DefBuckets by
+// default.
+func WithBuckets(buckets ...float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// NewRecorder creates a Recorder and registers its collectors with registerer.
+func NewRecorder(registerer prometheus.Registerer, opts ...Option) (*Recorder, error) {
+	o := &options{namespace: DefaultNamespace, buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	itemLabels := []string{"field", "kind", "type"}
+	r := &Recorder{
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Subsystem: "webhook",
+			Name:      "notifications_total",
+			Help:      "Number of webhook notifications handled.",
+		}, []string{"outcome"}),
+		notificationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Subsystem: "webhook",
+			Name:      "notification_duration_seconds",
+			Help:      "Time spent handling webhook notifications.",
+			Buckets:   o.buckets,
+		}, []string{"outcome"}),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Subsystem: "webhook",
+			Name:      "handled_total",
+			Help:      "Number of messages, statuses and other notification items handled.",
+		}, itemLabels),
+		handlerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Subsystem: "webhook",
+			Name:      "handler_duration_seconds",
+			Help:      "Time spent in the handlers of notification items.",
+			Buckets:   o.buckets,
+		}, itemLabels),
+		handlerErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Subsystem: "webhook",
+			Name:      "handler_errors_total",
+			Help:      "Number of notification items whose handler failed.",
+		}, itemLabels),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		r.notifications, r.notificationDuration, r.handled, r.handlerDuration, r.handlerErrors,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("register webhook metrics: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// RecordNotification implements message.MetricsRecorder.
+func (r *Recorder) RecordNotification(_ context.Context, duration time.Duration, err error) {
+	outcome := outcomeOf(err)
+	r.notifications.WithLabelValues(outcome).Inc()
+	r.notificationDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// RecordHandled implements message.MetricsRecorder.
+func (r *Recorder) RecordHandled(_ context.Context, event *message.MetricsEvent) {
+	labels := []string{event.Field, event.Kind, event.Type}
+	r.handled.WithLabelValues(labels...).Inc()
+	r.handlerDuration.WithLabelValues(labels...).Observe(event.Duration.Seconds())
+	if event.Err != nil {
+		r.handlerErrors.WithLabelValues(labels...).Inc()
+	}
+}
+
+func outcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
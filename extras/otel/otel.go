@@ -0,0 +1,169 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package otel instruments the requests of the core HTTP client with OpenTelemetry. A
+// Telemetry starts a client span for every attempt, records the attempts in a duration
+// histogram and an error counter keyed by request type, and propagates the trace context
+// to the Graph API with the configured propagator.
+//
+//	telemetry, err := otel.NewTelemetry(tracerProvider, meterProvider, propagation.TraceContext{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	sender := whttp.NewSender[message.Message](
+//		whttp.WithCoreClientTelemetry[message.Message](telemetry))
+//
+// The instruments are:
+//
+//	whatsapp.client.request.duration    time spent in attempts, in seconds
+//	whatsapp.client.request.errors      failed attempts, including the retried ones
+//
+// It is a separate module to keep OpenTelemetry out of the dependencies of the main module.
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// InstrumentationName is the name of the tracer and meter used by Telemetry.
+const InstrumentationName = "github.com/piusalfred/whatsapp/extras/otel"
+
+var _ whttp.Telemetry = (*Telemetry)(nil)
+
+// Telemetry is a whttp.Telemetry backed by OpenTelemetry.
+type Telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+}
+
+// NewTelemetry creates a Telemetry with a tracer and instruments from the providers. A
+// nil propagator propagates the W3C trace context.
+func NewTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider,
+	propagator propagation.TextMapPropagator,
+) (*Telemetry, error) {
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	t := &Telemetry{
+		tracer:     tracerProvider.Tracer(InstrumentationName),
+		propagator: propagator,
+	}
+
+	meter := meterProvider.Meter(InstrumentationName)
+
+	var errs [2]error
+	t.duration, errs[0] = meter.Float64Histogram("whatsapp.client.request.duration",
+		metric.WithDescription("Time spent sending requests to the Graph API."), metric.WithUnit("s"))
+	t.errors, errs[1] = meter.Int64Counter("whatsapp.client.request.errors",
+		metric.WithDescription("Number of requests to the Graph API that failed."))
+
+	if err := errors.Join(errs[:]...); err != nil {
+		return nil, fmt.Errorf("create client instruments: %w", err)
+	}
+
+	return t, nil
+}
+
+// StartSpan implements whttp.Telemetry.
+func (t *Telemetry) StartSpan(ctx context.Context, name string,
+	attributes []whttp.Attribute,
+) (context.Context, whttp.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attributes)...))
+
+	return ctx, &spanAdapter{span: span}
+}
+
+// Inject implements whttp.Telemetry.
+func (t *Telemetry) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// RecordRequest implements whttp.Telemetry.
+func (t *Telemetry) RecordRequest(ctx context.Context, m *whttp.RequestMetric) {
+	attrs := []attribute.KeyValue{
+		attribute.String(whttp.AttributeRequestType, m.RequestType.String()),
+		attribute.String(whttp.AttributeHTTPMethod, m.Method),
+	}
+
+	if m.StatusCode != 0 {
+		attrs = append(attrs, attribute.Int(whttp.AttributeStatusCode, m.StatusCode))
+	}
+
+	options := metric.WithAttributes(attrs...)
+	t.duration.Record(ctx, m.Duration.Seconds(), options)
+	if m.Err != nil {
+		t.errors.Add(ctx, 1, options)
+	}
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s *spanAdapter) SetAttributes(attributes ...whttp.Attribute) {
+	s.span.SetAttributes(convert(attributes)...)
+}
+
+func (s *spanAdapter) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *spanAdapter) End() {
+	s.span.End()
+}
+
+func convert(attributes []whttp.Attribute) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		switch value := attr.Value.(type) {
+		case string:
+			out = append(out, attribute.String(attr.Key, value))
+		case int:
+			out = append(out, attribute.Int(attr.Key, value))
+		case int64:
+			out = append(out, attribute.Int64(attr.Key, value))
+		case bool:
+			out = append(out, attribute.Bool(attr.Key, value))
+		case float64:
+			out = append(out, attribute.Float64(attr.Key, value))
+		default:
+			out = append(out, attribute.String(attr.Key, fmt.Sprint(value)))
+		}
+	}
+
+	return out
+}
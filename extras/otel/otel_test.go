@@ -0,0 +1,122 @@
+package otel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	wotel "github.com/piusalfred/whatsapp/extras/otel"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type testMessage struct {
+	Name string `json:"name"`
+}
+
+func TestTelemetry(t *testing.T) {
+	t.Parallel()
+
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	var (
+		calls       int
+		traceparent []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = append(traceparent, r.Header.Get("Traceparent"))
+		w.WriteHeader(statuses[calls])
+		calls++
+		_, _ = w.Write([]byte(`{"name":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	telemetry, err := wotel.NewTelemetry(tracerProvider, meterProvider, propagation.TraceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := whttp.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	sender := whttp.NewSender[testMessage](
+		whttp.WithCoreClientRetryPolicy[testMessage](policy),
+		whttp.WithCoreClientTelemetry[testMessage](telemetry),
+	)
+
+	request := whttp.MakeRequest(http.MethodPost, server.URL,
+		whttp.WithRequestType[testMessage](whttp.RequestTypeSendMessage),
+		whttp.WithRequestMessage(&testMessage{Name: "telemetry"}))
+
+	var got testMessage
+	ctx := context.Background()
+	if err := sender.Send(ctx, request, whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{})); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+
+	for i, span := range spans {
+		if span.Name != whttp.SpanName(whttp.RequestTypeSendMessage) || span.SpanKind != trace.SpanKindClient {
+			t.Errorf("span %d = %q of kind %v", i, span.Name, span.SpanKind)
+		}
+
+		want := "00-" + span.SpanContext.TraceID().String() + "-" + span.SpanContext.SpanID().String() + "-01"
+		if traceparent[i] != want {
+			t.Errorf("request %d traceparent = %q, want %q", i, traceparent[i], want)
+		}
+	}
+
+	if spans[0].Status.Code != codes.Error || spans[1].Status.Code == codes.Error {
+		t.Errorf("span statuses = %v, %v, want the first attempt failed", spans[0].Status, spans[1].Status)
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	var durations, failures int64
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				if m.Name == "whatsapp.client.request.duration" {
+					for _, point := range data.DataPoints {
+						durations += int64(point.Count)
+					}
+				}
+			case metricdata.Sum[int64]:
+				if m.Name == "whatsapp.client.request.errors" {
+					for _, point := range data.DataPoints {
+						failures += point.Value
+						if status, ok := point.Attributes.Value(whttp.AttributeStatusCode); !ok ||
+							status.AsInt64() != http.StatusServiceUnavailable {
+							t.Errorf("error counted with status %v, want %d", status, http.StatusServiceUnavailable)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if durations != 2 || failures != 1 {
+		t.Errorf("recorded %d durations and %d errors, want 2 and 1", durations, failures)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/piusalfred/whatsapp/extras/publish
+
+go 1.23.0
+
+require (
+	github.com/nats-io/nats.go v1.37.0
+	github.com/piusalfred/whatsapp v0.0.0
+	github.com/segmentio/kafka-go v0.4.47
+)
+
+require (
+	github.com/klauspost/compress v1.17.2 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
+
+replace github.com/piusalfred/whatsapp => ../../
@@ -0,0 +1,80 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package kafka publishes webhook events to Kafka with segmentio/kafka-go.
+//
+//	writer := &kafka.Writer{Addr: kafka.TCP("localhost:9092")}
+//	forwarder := publish.NewForwarder(wkafka.NewPublisher(writer))
+package kafka
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/piusalfred/whatsapp/webhooks/publish"
+)
+
+var _ publish.Publisher = (*Publisher)(nil)
+
+// Publisher is a publish.Publisher writing to a kafka.Writer.
+type Publisher struct {
+	writer *kafka.Writer
+}
+
+// NewPublisher creates a Publisher. A writer without a Balancer gets a kafka.Hash, so the
+// events of a customer land in the same partition. When writer.Topic is set it takes
+// precedence over the topic of the messages.
+func NewPublisher(writer *kafka.Writer) *Publisher {
+	if writer.Balancer == nil {
+		writer.Balancer = &kafka.Hash{}
+	}
+
+	return &Publisher{writer: writer}
+}
+
+// Publish writes the messages in one batch.
+func (p *Publisher) Publish(ctx context.Context, messages ...*publish.Message) error {
+	records := make([]kafka.Message, 0, len(messages))
+	for _, msg := range messages {
+		record := kafka.Message{
+			Key:     []byte(msg.Key),
+			Value:   msg.Value,
+			Headers: make([]kafka.Header, 0, len(msg.Headers)),
+		}
+
+		// kafka-go rejects messages with a topic when the writer has one.
+		if p.writer.Topic == "" {
+			record.Topic = msg.Topic
+		}
+
+		for key, value := range msg.Headers {
+			record.Headers = append(record.Headers, kafka.Header{Key: key, Value: []byte(value)})
+		}
+
+		records = append(records, record)
+	}
+
+	return p.writer.WriteMessages(ctx, records...)
+}
+
+// Close flushes and closes the writer.
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,102 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package nats publishes webhook events to NATS subjects.
+//
+// Every event is published to the subject "<topic>.<key>", for example
+// "whatsapp.events.255700000001", so consumers can subscribe to all events with
+// "whatsapp.events.>" or to one customer. The event ID is sent in the Nats-Msg-Id header,
+// which JetStream streams capturing the subjects use to drop duplicate deliveries.
+//
+//	conn, err := nats.Connect(nats.DefaultURL)
+//	forwarder := publish.NewForwarder(wnats.NewPublisher(conn))
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/piusalfred/whatsapp/webhooks/publish"
+)
+
+var _ publish.Publisher = (*Publisher)(nil)
+
+type (
+	// Conn is the part of *nats.Conn the Publisher uses.
+	Conn interface {
+		PublishMsg(msg *nats.Msg) error
+		FlushWithContext(ctx context.Context) error
+	}
+
+	// Publisher is a publish.Publisher writing to a NATS connection.
+	Publisher struct {
+		conn Conn
+	}
+)
+
+// NewPublisher creates a Publisher.
+func NewPublisher(conn Conn) *Publisher {
+	return &Publisher{conn: conn}
+}
+
+// Publish publishes the messages and flushes the connection, so that an error is returned
+// when the server could not be reached.
+func (p *Publisher) Publish(ctx context.Context, messages ...*publish.Message) error {
+	for _, msg := range messages {
+		out := &nats.Msg{
+			Subject: Subject(msg.Topic, msg.Key),
+			Data:    msg.Value,
+			Header:  nats.Header{},
+		}
+
+		for key, value := range msg.Headers {
+			out.Header.Set(key, value)
+		}
+
+		if id := msg.Headers[publish.HeaderEventID]; id != "" {
+			out.Header.Set(nats.MsgIdHdr, id)
+		}
+
+		if err := p.conn.PublishMsg(out); err != nil {
+			return fmt.Errorf("publish %s: %w", out.Subject, err)
+		}
+	}
+
+	return p.conn.FlushWithContext(ctx)
+}
+
+// Subject returns the subject a message with topic and key is published to. Characters
+// that have a meaning in subjects are replaced in the key.
+func Subject(topic, key string) string {
+	if key == "" {
+		return topic
+	}
+
+	return topic + "." + strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+
+		return r
+	}, key)
+}
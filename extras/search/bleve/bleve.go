@@ -0,0 +1,220 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package bleve is a search.Indexer and search.Searcher backed by a Bleve index, to search
+// the transcripts of conversations without running a search server.
+//
+//	index, err := bleve.Open("/var/lib/whatsapp/messages.bleve")
+//	handlers.SetMessageReceivedHandler(search.MessageReceivedHandler(index))
+//	docs, err := index.Search(ctx, &search.Query{Text: "refund", WaID: "255700000001"})
+//
+// The text is analyzed with the standard analyzer, so searches are case insensitive and
+// match whole words. The other fields are indexed as keywords.
+package bleve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/piusalfred/whatsapp/webhooks/search"
+)
+
+// DefaultLimit is the number of documents returned by a query without a limit.
+const DefaultLimit = 100
+
+var (
+	_ search.Indexer  = (*Index)(nil)
+	_ search.Searcher = (*Index)(nil)
+)
+
+// Index is a Bleve index of search.Document.
+type Index struct {
+	index bleve.Index
+}
+
+// NewMapping returns the index mapping of search.Document.
+func NewMapping() mapping.IndexMapping {
+	keyword := func() *mapping.FieldMapping {
+		field := bleve.NewKeywordFieldMapping()
+		field.Store = true
+
+		return field
+	}
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = "standard"
+	text.Store = true
+
+	document := bleve.NewDocumentMapping()
+	document.AddFieldMappingsAt("message_id", keyword())
+	document.AddFieldMappingsAt("wa_id", keyword())
+	document.AddFieldMappingsAt("phone_number_id", keyword())
+	document.AddFieldMappingsAt("type", keyword())
+	document.AddFieldMappingsAt("text", text)
+	document.AddFieldMappingsAt("timestamp", bleve.NewDateTimeFieldMapping())
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = document
+
+	return indexMapping
+}
+
+// New wraps an open Bleve index created with NewMapping.
+func New(index bleve.Index) *Index {
+	return &Index{index: index}
+}
+
+// Open opens the index at path, creating it when it does not exist.
+func Open(path string) (*Index, error) {
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, NewMapping())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("open search index %s: %w", path, err)
+	}
+
+	return New(index), nil
+}
+
+// NewMemory creates an index kept in memory.
+func NewMemory() (*Index, error) {
+	index, err := bleve.NewMemOnly(NewMapping())
+	if err != nil {
+		return nil, fmt.Errorf("create search index: %w", err)
+	}
+
+	return New(index), nil
+}
+
+// Close closes the underlying index.
+func (i *Index) Close() error {
+	return i.index.Close()
+}
+
+// Index adds the documents in one batch. Documents are keyed by message ID, so indexing a
+// redelivered message replaces the previous document.
+func (i *Index) Index(ctx context.Context, documents ...*search.Document) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	batch := i.index.NewBatch()
+	for _, doc := range documents {
+		if doc == nil || doc.MessageID == "" {
+			continue
+		}
+
+		if err := batch.Index(doc.MessageID, doc); err != nil {
+			return fmt.Errorf("%w: %s: %w", search.ErrIndex, doc.MessageID, err)
+		}
+	}
+
+	if err := i.index.Batch(batch); err != nil {
+		return fmt.Errorf("%w: %w", search.ErrIndex, err)
+	}
+
+	return nil
+}
+
+// Search returns the documents matching q, most recent first, like search.MemoryIndex.
+func (i *Index) Search(ctx context.Context, q *search.Query) ([]*search.Document, error) {
+	if q == nil {
+		q = &search.Query{}
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	request := bleve.NewSearchRequestOptions(buildQuery(q), limit, 0, false)
+	request.Fields = []string{"*"}
+	request.SortBy([]string{"-timestamp", "-_id"})
+
+	result, err := i.index.SearchInContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("search index: %w", err)
+	}
+
+	documents := make([]*search.Document, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		documents = append(documents, documentOf(hit.ID, hit.Fields))
+	}
+
+	return documents, nil
+}
+
+func buildQuery(q *search.Query) query.Query {
+	var queries []query.Query
+	if q.Text != "" {
+		match := bleve.NewMatchQuery(q.Text)
+		match.SetField("text")
+		match.SetOperator(query.MatchQueryOperatorAnd)
+		queries = append(queries, match)
+	}
+
+	if q.WaID != "" {
+		term := bleve.NewTermQuery(q.WaID)
+		term.SetField("wa_id")
+		queries = append(queries, term)
+	}
+
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		inclusive, exclusive := true, false
+		dates := bleve.NewDateRangeInclusiveQuery(q.Since, q.Until, &inclusive, &exclusive)
+		dates.SetField("timestamp")
+		queries = append(queries, dates)
+	}
+
+	if len(queries) == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+
+	return bleve.NewConjunctionQuery(queries...)
+}
+
+func documentOf(id string, fields map[string]any) *search.Document {
+	field := func(name string) string {
+		value, _ := fields[name].(string)
+
+		return value
+	}
+
+	doc := &search.Document{
+		MessageID:     id,
+		WaID:          field("wa_id"),
+		PhoneNumberID: field("phone_number_id"),
+		Type:          field("type"),
+		Text:          field("text"),
+	}
+
+	if timestamp, err := time.Parse(time.RFC3339Nano, field("timestamp")); err == nil {
+		doc.Timestamp = timestamp.UTC()
+	}
+
+	return doc
+}
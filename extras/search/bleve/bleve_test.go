@@ -0,0 +1,85 @@
+package bleve_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/extras/search/bleve"
+	"github.com/piusalfred/whatsapp/webhooks/search"
+)
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	index, err := bleve.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = index.Close() })
+
+	documents := []*search.Document{
+		{
+			MessageID: "m1", WaID: "alice", PhoneNumberID: "phone", Type: "text",
+			Text: "Where is my ORDER?", Timestamp: time.Unix(1700000000, 0).UTC(),
+		},
+		{
+			MessageID: "m2", WaID: "bob", PhoneNumberID: "phone", Type: "image",
+			Text: "damaged order box", Timestamp: time.Unix(1700000100, 0).UTC(),
+		},
+		{
+			MessageID: "m3", WaID: "alice", PhoneNumberID: "phone", Type: "interactive",
+			Text: "Track order", Timestamp: time.Unix(1700000200, 0).UTC(),
+		},
+	}
+
+	if err := index.Index(context.Background(), documents...); err != nil {
+		t.Fatal(err)
+	}
+
+	// a redelivered message replaces the indexed document.
+	if err := index.Index(context.Background(), documents[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query *search.Query
+		want  []string
+	}{
+		{name: "single term", query: &search.Query{Text: "order"}, want: []string{"m3", "m2", "m1"}},
+		{name: "all terms", query: &search.Query{Text: "order damaged"}, want: []string{"m2"}},
+		{name: "customer", query: &search.Query{Text: "order", WaID: "alice"}, want: []string{"m3", "m1"}},
+		{name: "since", query: &search.Query{Text: "order", Since: time.Unix(1700000050, 0)}, want: []string{"m3", "m2"}},
+		{name: "until", query: &search.Query{Until: time.Unix(1700000100, 0)}, want: []string{"m1"}},
+		{name: "limit", query: &search.Query{Text: "order", Limit: 1}, want: []string{"m3"}},
+		{name: "no match", query: &search.Query{Text: "refund"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		docs, err := index.Search(context.Background(), tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			got = append(got, doc.MessageID)
+		}
+
+		if diff := gcmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: results mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+
+	docs, err := index.Search(context.Background(), &search.Query{Text: "box"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff([]*search.Document{documents[1]}, docs); diff != "" {
+		t.Errorf("stored document mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/piusalfred/whatsapp/extras/translate
+
+go 1.23.0
+
+require github.com/piusalfred/whatsapp v0.0.0
+
+replace github.com/piusalfred/whatsapp => ../../
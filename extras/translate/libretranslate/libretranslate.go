@@ -0,0 +1,149 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package libretranslate is a translate.Translator backed by a LibreTranslate server, see
+// https://libretranslate.com. It also serves as an example for adapters to other providers.
+//
+//	provider := &libretranslate.Translator{BaseURL: "http://localhost:5000"}
+//	translation := translate.New(provider, "en")
+//
+// It is a separate module to keep provider clients out of the public API of the main module.
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/translate"
+)
+
+var _ translate.Translator = (*Translator)(nil)
+
+// Translator sends detection and translation requests to the LibreTranslate server at
+// BaseURL, with APIKey when the server requires one. Client defaults to http.DefaultClient.
+type Translator struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+type (
+	libreDetection struct {
+		Language   string  `json:"language"`
+		Confidence float64 `json:"confidence"`
+	}
+
+	libreTranslation struct {
+		TranslatedText string `json:"translatedText"`
+	}
+
+	libreError struct {
+		Error string `json:"error"`
+	}
+)
+
+// Detect returns the language detected with the highest confidence.
+func (lt *Translator) Detect(ctx context.Context, text string) (string, error) {
+	var detections []*libreDetection
+	if err := lt.post(ctx, "/detect", map[string]string{"q": text}, &detections); err != nil {
+		return "", err
+	}
+
+	var best *libreDetection
+	for _, detection := range detections {
+		if best == nil || detection.Confidence > best.Confidence {
+			best = detection
+		}
+	}
+
+	if best == nil {
+		return "", nil
+	}
+
+	return best.Language, nil
+}
+
+func (lt *Translator) Translate(ctx context.Context, text, from, to string) (string, error) {
+	var translation libreTranslation
+	err := lt.post(ctx, "/translate", map[string]string{
+		"q":      text,
+		"source": from,
+		"target": to,
+		"format": "text",
+	}, &translation)
+	if err != nil {
+		return "", err
+	}
+
+	return translation.TranslatedText, nil
+}
+
+func (lt *Translator) post(ctx context.Context, path string, body map[string]string, v any) error {
+	if lt.APIKey != "" {
+		body["api_key"] = lt.APIKey
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(lt.BaseURL, "/")+path,
+		bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := lt.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var libreErr libreError
+		if json.Unmarshal(payload, &libreErr) == nil && libreErr.Error != "" {
+			return fmt.Errorf("%w: status %d: %s", translate.ErrProvider, resp.StatusCode, libreErr.Error)
+		}
+
+		return fmt.Errorf("%w: status %d", translate.ErrProvider, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package libretranslate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/extras/translate/libretranslate"
+	"github.com/piusalfred/whatsapp/translate"
+)
+
+func TestTranslator(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["api_key"] != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"invalid api key"}`))
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/detect":
+			_, _ = w.Write([]byte(`[{"language":"en","confidence":20},{"language":"sw","confidence":90}]`))
+		case "/translate":
+			if body["source"] != "sw" || body["target"] != "en" {
+				t.Errorf("unexpected languages %q -> %q", body["source"], body["target"])
+			}
+			_, _ = w.Write([]byte(`{"translatedText":"hello"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := &libretranslate.Translator{BaseURL: server.URL, APIKey: "key", Client: server.Client()}
+
+	language, err := provider.Detect(context.Background(), "habari")
+	if err != nil || language != "sw" {
+		t.Fatalf("Detect() = %q, %v, want sw", language, err)
+	}
+
+	text, err := provider.Translate(context.Background(), "habari", "sw", "en")
+	if err != nil || text != "hello" {
+		t.Fatalf("Translate() = %q, %v, want hello", text, err)
+	}
+
+	provider.APIKey = "wrong"
+	if _, err := provider.Detect(context.Background(), "habari"); !errors.Is(err, translate.ErrProvider) {
+		t.Fatalf("Detect() error = %v, want %v for an invalid api key", err, translate.ErrProvider)
+	}
+}
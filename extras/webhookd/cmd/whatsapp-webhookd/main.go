@@ -0,0 +1,86 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Command whatsapp-webhookd is a standalone webhook server configured with a JSON or YAML
+// file and environment variables, see the webhooks/server package for the configuration
+// and the extras/webhookd package for its YAML form.
+//
+// The endpoints can use the built-in "log" handler, which logs the object and change
+// fields of every notification as JSON lines on stdout. Programs that need their own
+// handlers register them with server.Register and call server.Run instead.
+//
+// Usage:
+//
+//	whatsapp-webhookd -config webhookd.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/piusalfred/whatsapp/extras/webhookd"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/routing"
+	"github.com/piusalfred/whatsapp/webhooks/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "whatsapp-webhookd:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	configPath := flag.String("config", "webhookd.json", "path of the JSON or YAML configuration file")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	server.Register("log", server.ListenerFactory[routing.Notification](
+		webhooks.NotificationHandlerFunc[routing.Notification](func(ctx context.Context,
+			notification *routing.Notification,
+		) *webhooks.Response {
+			for _, entry := range notification.Entry {
+				for _, change := range entry.Changes {
+					logger.InfoContext(ctx, "notification",
+						slog.String("object", notification.Object),
+						slog.String("entry", entry.ID),
+						slog.String("field", change.Field))
+				}
+			}
+
+			return &webhooks.Response{StatusCode: http.StatusOK}
+		})))
+
+	config, err := webhookd.LoadConfigFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return server.Run(ctx, config, server.WithLogger(logger))
+}
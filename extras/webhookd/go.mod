@@ -0,0 +1,11 @@
+module github.com/piusalfred/whatsapp/extras/webhookd
+
+go 1.23.0
+
+require (
+	github.com/google/go-cmp v0.6.0
+	github.com/piusalfred/whatsapp v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/piusalfred/whatsapp => ../../
@@ -0,0 +1,50 @@
+package webhookd_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/extras/webhookd"
+	"github.com/piusalfred/whatsapp/webhooks/routing"
+)
+
+func TestLoadRoutingTable(t *testing.T) {
+	t.Parallel()
+
+	document := `routes:
+  - field: messages
+    message_type: text
+    handler: text
+  - field: flows
+    handler: flows
+default: log
+`
+	got, err := webhookd.LoadRoutingTable(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, err := routing.LoadTable(strings.NewReader(`{"routes":[
+		{"field":"messages","message_type":"text","handler":"text"},
+		{"field":"flows","handler":"flows"}],"default":"log"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadRoutingTable() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := webhookd.LoadRoutingTable(strings.NewReader("routez: []\n")); !errors.Is(err,
+		routing.ErrInvalidTable) {
+		t.Errorf("expected error %v, got %v", routing.ErrInvalidTable, err)
+	}
+
+	if _, err := webhookd.LoadRoutingTable(strings.NewReader("routes:\n  - field: messages\n")); !errors.Is(err,
+		routing.ErrMissingHandlerName) {
+		t.Errorf("expected error %v, got %v", routing.ErrMissingHandlerName, err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package webhookd loads the configuration of the standalone webhook server of the
+// webhooks/server package, and the routing tables of the webhooks/routing package, from
+// YAML files with the same keys as their JSON form:
+//
+//	addr: ":8443"
+//	tls:
+//	  cert_file: /etc/webhookd/cert.pem
+//	  key_file: /etc/webhookd/key.pem
+//	shutdown_timeout: 15s
+//	endpoints:
+//	  - path: /webhooks/messages
+//	    handler: messages
+//	    verify_token_env: MESSAGES_VERIFY_TOKEN
+//	    app_secret_env: APP_SECRET
+//
+// It is a separate module to keep the YAML decoder out of the dependencies of the main
+// module. The whatsapp-webhookd command in cmd/whatsapp-webhookd runs the server with a
+// configuration loaded by LoadConfigFile:
+//
+//	go run ./cmd/whatsapp-webhookd -config webhookd.yaml
+package webhookd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/piusalfred/whatsapp/webhooks/server"
+)
+
+// LoadConfig decodes a YAML server configuration from r and validates it.
+func LoadConfig(r io.Reader) (*server.Config, error) {
+	var config server.Config
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: decode: %w", server.ErrInvalidConfig, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadConfigFile loads the server configuration in the file at path and applies the
+// environment to it. Files with a .yaml or .yml extension are decoded as YAML, others
+// are left to server.LoadConfigFile as JSON.
+func LoadConfigFile(path string) (*server.Config, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+		return server.LoadConfigFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", server.ErrInvalidConfig, err)
+	}
+	defer file.Close()
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,60 @@
+package webhookd_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/extras/webhookd"
+	"github.com/piusalfred/whatsapp/webhooks/server"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "webhookd.yaml")
+	document := `addr: ":8443"
+shutdown_timeout: 15s
+endpoints:
+  - path: /webhooks/messages
+    handler: test
+    app_secret_env: WEBHOOKD_TEST_APP_SECRET
+`
+	if err := os.WriteFile(yamlPath, []byte(document), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := webhookd.LoadConfigFile(yamlPath); !errors.Is(err, server.ErrInvalidConfig) {
+		t.Errorf("LoadConfigFile() error = %v, want ErrInvalidConfig for an unset variable", err)
+	}
+
+	config, err := webhookd.LoadConfig(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Addr != ":8443" || time.Duration(config.ShutdownTimeout) != 15*time.Second ||
+		config.Endpoints[0].Path != "/webhooks/messages" ||
+		config.Endpoints[0].AppSecretEnv != "WEBHOOKD_TEST_APP_SECRET" {
+		t.Errorf("config = %+v", config)
+	}
+
+	if _, err := webhookd.LoadConfig(strings.NewReader("endpoints: []\nunknown: true\n")); !errors.Is(err,
+		server.ErrInvalidConfig) {
+		t.Errorf("LoadConfig() error = %v, want ErrInvalidConfig for an unknown key", err)
+	}
+
+	jsonPath := filepath.Join(dir, "webhookd.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"endpoints": [{"path": "/webhooks", "handler": "test",
+		"app_secret": "app-secret"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if config, err := webhookd.LoadConfigFile(jsonPath); err != nil || config.Endpoints[0].Path != "/webhooks" {
+		t.Errorf("LoadConfigFile() = %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package flow
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const EndpointBusinessEncryption = "whatsapp_business_encryption"
+
+// Signature statuses of the business public key. The key is signed when it is set and
+// MISMATCH means it has to be set again, for example after the phone number changed.
+const (
+	PublicKeySignatureValid    = "VALID"
+	PublicKeySignatureMismatch = "MISMATCH"
+)
+
+type (
+	// BusinessPublicKey is the public key WhatsApp uses to encrypt the requests sent to
+	// the Flow endpoint of a phone number.
+	BusinessPublicKey struct {
+		Key             string `json:"business_public_key"`
+		SignatureStatus string `json:"business_public_key_signature_status"`
+	}
+
+	businessPublicKeyResponse struct {
+		Data []*BusinessPublicKey `json:"data"`
+	}
+)
+
+// Valid reports whether the key is signed and in use.
+func (k *BusinessPublicKey) Valid() bool {
+	return k != nil && k.SignatureStatus == PublicKeySignatureValid
+}
+
+// SetBusinessPublicKey uploads the PEM encoded public key of the phone number. Requests to
+// the Flow endpoint are encrypted with it from then on.
+func (client *BaseClient) SetBusinessPublicKey(ctx context.Context, publicKeyPEM string) (*SuccessResponse, error) {
+	conf, err := client.Reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("set business public key: read config: %w", err)
+	}
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](whttp.RequestTypeSetBusinessPublicKey),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestEndpoints[any](conf.APIVersion, conf.PhoneNumberID, EndpointBusinessEncryption),
+		whttp.WithRequestForm[any](&whttp.RequestForm{
+			Fields: map[string]string{"business_public_key": publicKeyPEM},
+		}),
+	}
+
+	req := whttp.MakeRequest[any](http.MethodPost, conf.BaseURL, opts...)
+
+	var resp SuccessResponse
+	decoder := whttp.ResponseDecoderJSON(&resp, whttp.DecodeOptions{
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := client.Sender.Send(ctx, req, decoder); err != nil {
+		return nil, fmt.Errorf("set business public key: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// GetBusinessPublicKey returns the public key set for the phone number.
+func (client *BaseClient) GetBusinessPublicKey(ctx context.Context) (*BusinessPublicKey, error) {
+	conf, err := client.Reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get business public key: read config: %w", err)
+	}
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](whttp.RequestTypeGetBusinessPublicKey),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestEndpoints[any](conf.APIVersion, conf.PhoneNumberID, EndpointBusinessEncryption),
+	}
+
+	req := whttp.MakeRequest[any](http.MethodGet, conf.BaseURL, opts...)
+
+	var resp businessPublicKeyResponse
+	decoder := whttp.ResponseDecoderJSON(&resp, whttp.DecodeOptions{
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := client.Sender.Send(ctx, req, decoder); err != nil {
+		return nil, fmt.Errorf("get business public key: %w", err)
+	}
+
+	if len(resp.Data) == 0 {
+		return &BusinessPublicKey{}, nil
+	}
+
+	return resp.Data[0], nil
+}
+
+// RegisterKey encodes the public part of key and sets it as the business public key, the
+// private key is then used with NewDataExchangeHandler to decrypt the endpoint requests.
+func RegisterKey(ctx context.Context, client *BaseClient, key *rsa.PrivateKey) error {
+	publicKeyPEM, err := crypto.MarshalPublicKeyPEM(&key.PublicKey)
+	if err != nil {
+		return fmt.Errorf("register key: %w", err)
+	}
+
+	response, err := client.SetBusinessPublicKey(ctx, string(publicKeyPEM))
+	if err != nil {
+		return fmt.Errorf("register key: %w", err)
+	}
+
+	if !response.Success {
+		return fmt.Errorf("register key: %w", ErrPublicKeyNotSet)
+	}
+
+	return nil
+}
+
+// StaticPrivateKey returns a private key loader for NewDataExchangeHandler that always
+// returns the PEM encoded key.
+func StaticPrivateKey(privateKeyPEM []byte) (func(ctx context.Context) (*rsa.PrivateKey, error), error) {
+	key, err := crypto.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(context.Context) (*rsa.PrivateKey, error) {
+		return key, nil
+	}, nil
+}
@@ -0,0 +1,69 @@
+package flow_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/flow"
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+func TestBaseClient_BusinessPublicKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateRSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uploaded string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone/whatsapp_business_encryption" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			uploaded = r.FormValue("business_public_key")
+			_, _ = w.Write([]byte(`{"success":true}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":[{"business_public_key":` + strconv.Quote(uploaded) +
+			`,"business_public_key_signature_status":"VALID"}]}`))
+	})
+
+	ctx := context.Background()
+	if err := flow.RegisterKey(ctx, client, key); err != nil {
+		t.Fatalf("RegisterKey() error = %v", err)
+	}
+
+	public, err := crypto.ParsePublicKeyPEM([]byte(uploaded))
+	if err != nil || !public.Equal(&key.PublicKey) {
+		t.Fatalf("uploaded key = %q, %v", uploaded, err)
+	}
+
+	got, err := client.GetBusinessPublicKey(ctx)
+	if err != nil {
+		t.Fatalf("GetBusinessPublicKey() error = %v", err)
+	}
+
+	if !got.Valid() || got.Key != uploaded {
+		t.Errorf("GetBusinessPublicKey() = %+v", got)
+	}
+
+	privatePEM, _ := crypto.MarshalPrivateKeyPEM(key)
+	loader, err := flow.StaticPrivateKey(privatePEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded, err := loader(ctx); err != nil || !loaded.Equal(key) {
+		t.Errorf("StaticPrivateKey() loader = %v", err)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -59,15 +60,21 @@ const (
 )
 
 type (
+	// CreateRequest creates a flow. FlowJSON is the flow definition as a JSON string and
+	// Publish publishes the flow right after it is created, it requires FlowJSON.
 	CreateRequest struct {
 		Name        string   `json:"name,omitempty"`
 		Categories  []string `json:"categories,omitempty"`
+		FlowJSON    string   `json:"flow_json,omitempty"`
+		Publish     bool     `json:"publish,omitempty"`
 		CloneFlowID string   `json:"clone_flow_id,omitempty"`
 		EndpointURI string   `json:"endpoint_uri,omitempty"`
 	}
 
 	CreateResponse struct {
-		ID string `json:"id"`
+		ID               string            `json:"id"`
+		Success          bool              `json:"success,omitempty"`
+		ValidationErrors []ValidationError `json:"validation_errors,omitempty"`
 	}
 
 	UpdateRequest struct {
@@ -81,9 +88,12 @@ type (
 		Success bool `json:"success"`
 	}
 
+	// UpdateFlowJSONRequest uploads the flow JSON in File as the flow.json asset of the
+	// flow with FlowID. Name is the asset name and defaults to "flow.json".
 	UpdateFlowJSONRequest struct {
-		Name string
-		File string
+		FlowID string
+		Name   string
+		File   string
 	}
 
 	UpdateFlowJSONResponse struct {
@@ -309,7 +319,10 @@ func (client *BaseClient) Send(ctx context.Context, conf *config.Config, req *Ba
 		BaseURL:     conf.BaseURL,
 		Endpoints:   endpoints,
 		QueryParams: req.QueryParams,
-		Message:     &req.Payload,
+	}
+
+	if req.Payload != nil {
+		request.Message = &req.Payload
 	}
 
 	response := &BaseResponse{}
@@ -401,9 +414,18 @@ func (client *BaseClient) UpdateFlowJSON(ctx context.Context,
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
+	if request.FlowID == "" {
+		return nil, ErrMissingFlowID
+	}
+
+	name := request.Name
+	if name == "" {
+		name = "flow.json"
+	}
+
 	form := &whttp.RequestForm{
 		Fields: map[string]string{
-			"name":       request.Name,
+			"name":       name,
 			"asset_type": "FLOW_JSON",
 		},
 		FormFile: &whttp.FormFile{
@@ -413,10 +435,10 @@ func (client *BaseClient) UpdateFlowJSON(ctx context.Context,
 	}
 
 	opts := []whttp.RequestOption[any]{
-		whttp.WithRequestType[any](whttp.RequestTypeUploadMedia),
+		whttp.WithRequestType[any](whttp.RequestTypeUpdateFlow),
 		whttp.WithRequestBearer[any](conf.AccessToken),
 		whttp.WithRequestForm[any](form),
-		whttp.WithRequestEndpoints[any](conf.APIVersion, conf.PhoneNumberID, "media"),
+		whttp.WithRequestEndpoints[any](conf.APIVersion, request.FlowID, "assets"),
 		whttp.WithRequestAppSecret[any](conf.AppSecret),
 		whttp.WithRequestSecured[any](conf.SecureRequests),
 	}
@@ -430,7 +452,7 @@ func (client *BaseClient) UpdateFlowJSON(ctx context.Context,
 	})
 
 	if err := client.Sender.Send(ctx, req, decoder); err != nil {
-		return nil, fmt.Errorf("upload media failed: %w", err)
+		return nil, fmt.Errorf("upload flow json: %w", err)
 	}
 
 	return &resp, nil
@@ -471,7 +493,11 @@ func (client *BaseClient) Create(ctx context.Context, request CreateRequest) (*C
 		return nil, fmt.Errorf("send create request: %w", err)
 	}
 
-	return &CreateResponse{ID: response.ID}, nil
+	return &CreateResponse{
+		ID:               response.ID,
+		Success:          response.Success,
+		ValidationErrors: response.ValidationErrors,
+	}, nil
 }
 
 func (client *BaseClient) ListAll(ctx context.Context) (*ListResponse, error) {
@@ -564,6 +590,11 @@ func (client *BaseClient) Deprecate(ctx context.Context, id string) (*SuccessRes
 	return &SuccessResponse{Success: response.Success}, nil
 }
 
+var (
+	ErrMissingFlowID   = errors.New("flow id is required")
+	ErrPublicKeyNotSet = errors.New("business public key was not set")
+)
+
 var _ Service = (*BaseClient)(nil)
 
 type (
@@ -579,6 +610,8 @@ type (
 		GeneratePreview(ctx context.Context, request *PreviewRequest) (*PreviewResponse, error)
 		Get(ctx context.Context, request *GetRequest) (*SingleFlowResponse, error)
 		GetFlowMetrics(ctx context.Context, request *MetricsRequest) (*MetricsAPIResponse, error)
+		SetBusinessPublicKey(ctx context.Context, publicKeyPEM string) (*SuccessResponse, error)
+		GetBusinessPublicKey(ctx context.Context) (*BusinessPublicKey, error)
 	}
 )
 
@@ -782,6 +815,12 @@ func (h *DataExchangeHandlerImpl) Handle(w http.ResponseWriter, request *http.Re
 	}
 
 	response, err := h.Handler.Handle(ctx, message)
+	if errors.Is(err, ErrInvalidFlowToken) {
+		w.WriteHeader(StatusInvalidFlowToken)
+
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 
@@ -0,0 +1,96 @@
+package flow_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/flow"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *flow.BaseClient {
+	t.Helper()
+
+	return flow.NewBaseClient(apitest.NewConfigReader(t, handler), whttp.NewAnySender())
+}
+
+func TestBaseClient_UpdateFlowJSON(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "flow.json")
+	if err := os.WriteFile(file, []byte(`{"version":"5.0","screens":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v20.0/1234/assets" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+
+		if r.FormValue("asset_type") != "FLOW_JSON" || r.FormValue("name") != "flow.json" {
+			t.Errorf("unexpected form: %v", r.MultipartForm.Value)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"validation_errors":[]}`))
+	})
+
+	resp, err := client.UpdateFlowJSON(context.Background(), &flow.UpdateFlowJSONRequest{FlowID: "1234", File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !resp.Success {
+		t.Errorf("expected success")
+	}
+
+	if _, err := client.UpdateFlowJSON(context.Background(), &flow.UpdateFlowJSONRequest{File: file}); err == nil {
+		t.Errorf("expected error without a flow id")
+	}
+}
+
+func TestBaseClient_Create(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v20.0/waba/flows" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["flow_json"] == nil || body["publish"] != true {
+			t.Errorf("unexpected body: %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"99","success":true,"validation_errors":[]}`))
+	})
+
+	resp, err := client.Create(context.Background(), flow.CreateRequest{
+		Name:       "signup",
+		Categories: []string{flow.CategorySignUp},
+		FlowJSON:   `{"version":"5.0","screens":[]}`,
+		Publish:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.ID != "99" || !resp.Success {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+// StatusInvalidFlowToken is the HTTP status the data exchange endpoint answers with when
+// the flow token is invalid or expired, the client then shows an error and closes the flow.
+const StatusInvalidFlowToken = 427
+
+const actionPing = "ping"
+
+var (
+	ErrInvalidFlowToken = errors.New("invalid flow token")
+	ErrFlowTokenExpired = errors.New("flow token expired")
+)
+
+// FlowTokenClaims are carried by signed flow tokens. Signing the token sent with a flow
+// message lets the data exchange endpoint trust it without a lookup.
+type FlowTokenClaims struct {
+	FlowID    string            `json:"fid,omitempty"`
+	WaID      string            `json:"sub,omitempty"`
+	ExpiresAt int64             `json:"exp,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+}
+
+// SignFlowToken returns a flow token carrying claims signed with signer.
+func SignFlowToken(ctx context.Context, signer *crypto.Signer, claims *FlowTokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("encode flow token claims: %w", err)
+	}
+
+	token, err := signer.Sign(ctx, payload)
+	if err != nil {
+		return "", fmt.Errorf("sign flow token: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifyFlowToken checks the signature and expiry of a token made by SignFlowToken.
+func VerifyFlowToken(ctx context.Context, signer *crypto.Signer, token string, now time.Time) (*FlowTokenClaims, error) {
+	payload, err := signer.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFlowToken, err)
+	}
+
+	var claims FlowTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFlowToken, err)
+	}
+
+	if claims.ExpiresAt != 0 && !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFlowToken, ErrFlowTokenExpired)
+	}
+
+	return &claims, nil
+}
+
+type flowTokenContextKey struct{}
+
+// FlowTokenClaimsFromContext returns the claims verified by FlowTokenMiddleware.
+func FlowTokenClaimsFromContext(ctx context.Context) (*FlowTokenClaims, bool) {
+	claims, ok := ctx.Value(flowTokenContextKey{}).(*FlowTokenClaims)
+
+	return claims, ok
+}
+
+// FlowTokenMiddleware rejects data exchange requests whose flow token was not made by
+// SignFlowToken with signer, health check pings excepted, and passes the claims on in the
+// context. The endpoint answers rejected requests with StatusInvalidFlowToken.
+func FlowTokenMiddleware(signer *crypto.Signer) DataExchangeRequestHandlerMiddleware {
+	return func(next DataExchangeHandlerFunc) DataExchangeHandlerFunc {
+		return func(ctx context.Context, request *DataExchangeRequest) (*Response, error) {
+			if request.Action == actionPing {
+				return next(ctx, request)
+			}
+
+			claims, err := VerifyFlowToken(ctx, signer, request.FlowToken, time.Now())
+			if err != nil {
+				return nil, err
+			}
+
+			return next(context.WithValue(ctx, flowTokenContextKey{}, claims), request)
+		}
+	}
+}
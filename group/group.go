@@ -0,0 +1,771 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package group manages WhatsApp groups owned by a business phone number: creating and
+// updating groups, sharing their invite links, removing participants and approving or
+// rejecting join requests. Messages are sent to a group with message.NewGroupMessage.
+package group
+
+//go:generate mockgen -destination=../mocks/group/mock_group.go -package=group -source=group.go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const (
+	EndpointGroups       = "groups"
+	EndpointInviteLink   = "invite_link"
+	EndpointParticipants = "participants"
+	EndpointJoinRequests = "join_requests"
+)
+
+const messagingProduct = "whatsapp"
+
+// Join approval modes. With JoinApprovalRequired people opening the invite link send a
+// join request that must be approved.
+const (
+	JoinApprovalRequired = "approval_required"
+	JoinApprovalAuto     = "auto_approve"
+)
+
+// DefaultGroupFields are requested by Get when no fields are given.
+var DefaultGroupFields = []string{ //nolint:gochecknoglobals // read only defaults
+	"subject", "description", "suspended", "creation_timestamp", "participants", "total_participant_count",
+}
+
+type (
+	CreateRequest struct {
+		Subject          string `json:"subject"`
+		Description      string `json:"description,omitempty"`
+		JoinApprovalMode string `json:"join_approval_mode,omitempty"`
+	}
+
+	// CreateResponse carries the id of the created group and its invite link. The API may
+	// create the group asynchronously, in which case only RequestID is set and the group is
+	// reported by a group_lifecycle_update webhook.
+	CreateResponse struct {
+		ID         string `json:"id,omitempty"`
+		RequestID  string `json:"request_id,omitempty"`
+		InviteLink string `json:"invite_link,omitempty"`
+	}
+
+	UpdateRequest struct {
+		GroupID     string `json:"-"`
+		Subject     string `json:"subject,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Group struct {
+		ID                    string         `json:"id"`
+		Subject               string         `json:"subject,omitempty"`
+		Description           string         `json:"description,omitempty"`
+		Suspended             bool           `json:"suspended,omitempty"`
+		CreationTimestamp     int64          `json:"creation_timestamp,omitempty"`
+		Participants          []*Participant `json:"participants,omitempty"`
+		TotalParticipantCount int            `json:"total_participant_count,omitempty"`
+	}
+
+	Participant struct {
+		WaID string `json:"wa_id"`
+	}
+
+	ListRequest struct {
+		Limit  int
+		After  string
+		Before string
+	}
+
+	ListResponse struct {
+		Data   []*Group `json:"data"`
+		Paging *Paging  `json:"paging,omitempty"`
+	}
+
+	JoinRequest struct {
+		ID                string `json:"join_request_id"`
+		WaID              string `json:"wa_id"`
+		CreationTimestamp int64  `json:"creation_timestamp,omitempty"`
+	}
+
+	ListJoinRequestsResponse struct {
+		Data   []*JoinRequest `json:"data"`
+		Paging *Paging        `json:"paging,omitempty"`
+	}
+
+	// JoinRequestsResponse reports the join requests that were approved or rejected and the
+	// ones that failed.
+	JoinRequestsResponse struct {
+		Approved []string             `json:"approved_join_requests,omitempty"`
+		Rejected []string             `json:"rejected_join_requests,omitempty"`
+		Failed   []*FailedJoinRequest `json:"failed_join_requests,omitempty"`
+	}
+
+	FailedJoinRequest struct {
+		ID     string            `json:"join_request_id"`
+		Errors []json.RawMessage `json:"errors,omitempty"`
+	}
+
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
+
+	SuccessResponse struct {
+		Success bool `json:"success"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Create(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return List(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) Get(ctx context.Context, groupID string, fields ...string) (*Group, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Get(ctx, c.Sender, conf, groupID, fields...)
+}
+
+func (c *BaseClient) Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Update(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) Delete(ctx context.Context, groupID string) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Delete(ctx, c.Sender, conf, groupID)
+}
+
+func (c *BaseClient) InviteLink(ctx context.Context, groupID string) (string, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return InviteLink(ctx, c.Sender, conf, groupID)
+}
+
+func (c *BaseClient) ResetInviteLink(ctx context.Context, groupID string) (string, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ResetInviteLink(ctx, c.Sender, conf, groupID)
+}
+
+func (c *BaseClient) RemoveParticipants(ctx context.Context, groupID string, waIDs ...string) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return RemoveParticipants(ctx, c.Sender, conf, groupID, waIDs...)
+}
+
+func (c *BaseClient) ListJoinRequests(ctx context.Context, groupID string,
+	req *ListRequest,
+) (*ListJoinRequestsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListJoinRequests(ctx, c.Sender, conf, groupID, req)
+}
+
+func (c *BaseClient) ApproveJoinRequests(ctx context.Context, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ApproveJoinRequests(ctx, c.Sender, conf, groupID, ids...)
+}
+
+func (c *BaseClient) RejectJoinRequests(ctx context.Context, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return RejectJoinRequests(ctx, c.Sender, conf, groupID, ids...)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	return Create(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return List(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) Get(ctx context.Context, groupID string, fields ...string) (*Group, error) {
+	return Get(ctx, c.Sender, c.Config, groupID, fields...)
+}
+
+func (c *Client) Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error) {
+	return Update(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) Delete(ctx context.Context, groupID string) (*SuccessResponse, error) {
+	return Delete(ctx, c.Sender, c.Config, groupID)
+}
+
+func (c *Client) InviteLink(ctx context.Context, groupID string) (string, error) {
+	return InviteLink(ctx, c.Sender, c.Config, groupID)
+}
+
+func (c *Client) ResetInviteLink(ctx context.Context, groupID string) (string, error) {
+	return ResetInviteLink(ctx, c.Sender, c.Config, groupID)
+}
+
+func (c *Client) RemoveParticipants(ctx context.Context, groupID string, waIDs ...string) (*SuccessResponse, error) {
+	return RemoveParticipants(ctx, c.Sender, c.Config, groupID, waIDs...)
+}
+
+func (c *Client) ListJoinRequests(ctx context.Context, groupID string,
+	req *ListRequest,
+) (*ListJoinRequestsResponse, error) {
+	return ListJoinRequests(ctx, c.Sender, c.Config, groupID, req)
+}
+
+func (c *Client) ApproveJoinRequests(ctx context.Context, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	return ApproveJoinRequests(ctx, c.Sender, c.Config, groupID, ids...)
+}
+
+func (c *Client) RejectJoinRequests(ctx context.Context, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	return RejectJoinRequests(ctx, c.Sender, c.Config, groupID, ids...)
+}
+
+var (
+	ErrCreateGroup         = errors.New("failed to create group")
+	ErrListGroups          = errors.New("failed to list groups")
+	ErrGetGroup            = errors.New("failed to get group")
+	ErrUpdateGroup         = errors.New("failed to update group")
+	ErrDeleteGroup         = errors.New("failed to delete group")
+	ErrInviteLink          = errors.New("failed to get group invite link")
+	ErrRemoveParticipants  = errors.New("failed to remove group participants")
+	ErrListJoinRequests    = errors.New("failed to list group join requests")
+	ErrApproveJoinRequests = errors.New("failed to approve group join requests")
+	ErrRejectJoinRequests  = errors.New("failed to reject group join requests")
+	ErrMissingGroupID      = errors.New("group id is required")
+	ErrMissingSubject      = errors.New("group subject is required")
+	ErrNoParticipants      = errors.New("at least one participant is required")
+	ErrNoJoinRequests      = errors.New("at least one join request is required")
+)
+
+func Create(ctx context.Context, sender Sender, conf *config.Config, req *CreateRequest) (*CreateResponse, error) {
+	if req == nil || req.Subject == "" {
+		return nil, fmt.Errorf("%w: %w", ErrCreateGroup, ErrMissingSubject)
+	}
+
+	body := map[string]any{
+		"messaging_product": messagingProduct,
+		"subject":           req.Subject,
+	}
+
+	if req.Description != "" {
+		body["description"] = req.Description
+	}
+
+	if req.JoinApprovalMode != "" {
+		body["join_approval_mode"] = req.JoinApprovalMode
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeCreateGroup,
+		Endpoints: []string{conf.PhoneNumberID, EndpointGroups},
+		Body:      body,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateGroup, err)
+	}
+
+	return &CreateResponse{
+		ID:         response.ID,
+		RequestID:  response.RequestID,
+		InviteLink: response.InviteLink,
+	}, nil
+}
+
+// List lists the active groups of the phone number.
+func List(ctx context.Context, sender Sender, conf *config.Config, req *ListRequest) (*ListResponse, error) {
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListGroups,
+		Endpoints:   []string{conf.PhoneNumberID, EndpointGroups},
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListGroups, err)
+	}
+
+	// the groups are listed under data.groups.
+	var data struct {
+		Groups []*Group `json:"groups"`
+	}
+	if err := response.decodeData(&data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListGroups, err)
+	}
+
+	return &ListResponse{Data: data.Groups, Paging: response.Paging}, nil
+}
+
+// Get fetches a group, requesting DefaultGroupFields when no fields are given.
+func Get(ctx context.Context, sender Sender, conf *config.Config, groupID string, fields ...string) (*Group, error) {
+	if groupID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrGetGroup, ErrMissingGroupID)
+	}
+
+	if len(fields) == 0 {
+		fields = DefaultGroupFields
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeGetGroup,
+		Endpoints:   []string{groupID},
+		QueryParams: map[string]string{"fields": strings.Join(fields, ",")},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetGroup, err)
+	}
+
+	group := response.Group
+	if group.ID == "" {
+		group.ID = groupID
+	}
+
+	return &group, nil
+}
+
+func Update(ctx context.Context, sender Sender, conf *config.Config, req *UpdateRequest) (*SuccessResponse, error) {
+	if req == nil || req.GroupID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateGroup, ErrMissingGroupID)
+	}
+
+	body := map[string]any{"messaging_product": messagingProduct}
+	if req.Subject != "" {
+		body["subject"] = req.Subject
+	}
+
+	if req.Description != "" {
+		body["description"] = req.Description
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeUpdateGroup,
+		Endpoints: []string{req.GroupID},
+		Body:      body,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateGroup, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+// Delete deletes the group, removing all its participants.
+func Delete(ctx context.Context, sender Sender, conf *config.Config, groupID string) (*SuccessResponse, error) {
+	if groupID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrDeleteGroup, ErrMissingGroupID)
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodDelete,
+		Type:      whttp.RequestTypeDeleteGroup,
+		Endpoints: []string{groupID},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDeleteGroup, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+func InviteLink(ctx context.Context, sender Sender, conf *config.Config, groupID string) (string, error) {
+	return inviteLink(ctx, sender, conf, groupID, http.MethodGet, whttp.RequestTypeGetGroupInviteLink)
+}
+
+// ResetInviteLink revokes the invite link of the group and returns the new one.
+func ResetInviteLink(ctx context.Context, sender Sender, conf *config.Config, groupID string) (string, error) {
+	return inviteLink(ctx, sender, conf, groupID, http.MethodPost, whttp.RequestTypeResetGroupInviteLink)
+}
+
+func inviteLink(ctx context.Context, sender Sender, conf *config.Config, groupID, method string,
+	requestType whttp.RequestType,
+) (string, error) {
+	if groupID == "" {
+		return "", fmt.Errorf("%w: %w", ErrInviteLink, ErrMissingGroupID)
+	}
+
+	request := &BaseRequest{
+		Method:    method,
+		Type:      requestType,
+		Endpoints: []string{groupID, EndpointInviteLink},
+	}
+
+	if method == http.MethodPost {
+		request.Body = map[string]any{"messaging_product": messagingProduct}
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInviteLink, err)
+	}
+
+	return response.InviteLink, nil
+}
+
+func RemoveParticipants(ctx context.Context, sender Sender, conf *config.Config, groupID string,
+	waIDs ...string,
+) (*SuccessResponse, error) {
+	if groupID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrRemoveParticipants, ErrMissingGroupID)
+	}
+
+	if len(waIDs) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrRemoveParticipants, ErrNoParticipants)
+	}
+
+	participants := make([]map[string]string, len(waIDs))
+	for i, id := range waIDs {
+		participants[i] = map[string]string{"user": id}
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodDelete,
+		Type:      whttp.RequestTypeRemoveGroupParticipants,
+		Endpoints: []string{groupID, EndpointParticipants},
+		Body: map[string]any{
+			"messaging_product": messagingProduct,
+			"participants":      participants,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoveParticipants, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+// ListJoinRequests lists the pending join requests of a group whose join approval mode is
+// JoinApprovalRequired.
+func ListJoinRequests(ctx context.Context, sender Sender, conf *config.Config, groupID string,
+	req *ListRequest,
+) (*ListJoinRequestsResponse, error) {
+	if groupID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrListJoinRequests, ErrMissingGroupID)
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListGroupJoinRequests,
+		Endpoints:   []string{groupID, EndpointJoinRequests},
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListJoinRequests, err)
+	}
+
+	var requests []*JoinRequest
+	if err := response.decodeData(&requests); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListJoinRequests, err)
+	}
+
+	return &ListJoinRequestsResponse{Data: requests, Paging: response.Paging}, nil
+}
+
+func ApproveJoinRequests(ctx context.Context, sender Sender, conf *config.Config, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	response, err := joinRequests(ctx, sender, conf, groupID, http.MethodPost,
+		whttp.RequestTypeApproveGroupJoinRequests, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrApproveJoinRequests, err)
+	}
+
+	return response, nil
+}
+
+func RejectJoinRequests(ctx context.Context, sender Sender, conf *config.Config, groupID string,
+	ids ...string,
+) (*JoinRequestsResponse, error) {
+	response, err := joinRequests(ctx, sender, conf, groupID, http.MethodDelete,
+		whttp.RequestTypeRejectGroupJoinRequests, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRejectJoinRequests, err)
+	}
+
+	return response, nil
+}
+
+func joinRequests(ctx context.Context, sender Sender, conf *config.Config, groupID, method string,
+	requestType whttp.RequestType, ids []string,
+) (*JoinRequestsResponse, error) {
+	if groupID == "" {
+		return nil, ErrMissingGroupID
+	}
+
+	if len(ids) == 0 {
+		return nil, ErrNoJoinRequests
+	}
+
+	request := &BaseRequest{
+		Method:    method,
+		Type:      requestType,
+		Endpoints: []string{groupID, EndpointJoinRequests},
+		Body: map[string]any{
+			"messaging_product": messagingProduct,
+			"join_requests":     ids,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.JoinRequestsResponse, nil
+}
+
+func (req *ListRequest) queryParams() map[string]string {
+	params := map[string]string{}
+	if req == nil {
+		return params
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.After != "" {
+		params["after"] = req.After
+	}
+
+	if req.Before != "" {
+		params["before"] = req.Before
+	}
+
+	return params
+}
+
+type (
+	// BaseRequest targets a group, or the groups of the phone number when Endpoints starts
+	// with the phone number ID. The API version is prepended by the BaseSender.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response holds the fields of every group endpoint, a call fills in its own.
+	Response struct {
+		Group
+		JoinRequestsResponse
+
+		RequestID  string          `json:"request_id,omitempty"`
+		InviteLink string          `json:"invite_link,omitempty"`
+		Data       json.RawMessage `json:"data,omitempty"`
+		Paging     *Paging         `json:"paging,omitempty"`
+		Success    bool            `json:"success,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (response *Response) decodeData(v any) error {
+	if len(response.Data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(response.Data, v); err != nil {
+		return fmt.Errorf("decode data: %w", err)
+	}
+
+	return nil
+}
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface { //nolint:interfacebloat // mirrors the group endpoints
+	Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error)
+	List(ctx context.Context, req *ListRequest) (*ListResponse, error)
+	Get(ctx context.Context, groupID string, fields ...string) (*Group, error)
+	Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error)
+	Delete(ctx context.Context, groupID string) (*SuccessResponse, error)
+	InviteLink(ctx context.Context, groupID string) (string, error)
+	ResetInviteLink(ctx context.Context, groupID string) (string, error)
+	RemoveParticipants(ctx context.Context, groupID string, waIDs ...string) (*SuccessResponse, error)
+	ListJoinRequests(ctx context.Context, groupID string, req *ListRequest) (*ListJoinRequestsResponse, error)
+	ApproveJoinRequests(ctx context.Context, groupID string, ids ...string) (*JoinRequestsResponse, error)
+	RejectJoinRequests(ctx context.Context, groupID string, ids ...string) (*JoinRequestsResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
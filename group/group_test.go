@@ -0,0 +1,154 @@
+package group_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/group"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		Method string
+		Path   string
+		Body   map[string]any
+	}
+
+	var calls []call
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		c := call{Method: r.Method, Path: r.URL.Path}
+		if r.ContentLength > 0 {
+			_ = json.NewDecoder(r.Body).Decode(&c.Body)
+		}
+		calls = append(calls, c)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v20.0/phone/groups":
+			_, _ = w.Write([]byte(`{"id":"g1","invite_link":"https://chat.whatsapp.com/abc"}`))
+		case "GET /v20.0/phone/groups":
+			_, _ = w.Write([]byte(`{"data":{"groups":[{"id":"g1","creation_timestamp":1700000000}]},"paging":{"cursors":{"before":"b","after":"a"}}}`))
+		case "GET /v20.0/g1":
+			_, _ = w.Write([]byte(`{"id":"g1","subject":"Support","participants":[{"wa_id":"255700000001"}],"total_participant_count":1}`))
+		case "GET /v20.0/g1/join_requests":
+			_, _ = w.Write([]byte(`{"data":[{"join_request_id":"jr1","wa_id":"255700000002"}]}`))
+		case "POST /v20.0/g1/join_requests":
+			_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","approved_join_requests":["jr1"]}`))
+		default:
+			_, _ = w.Write([]byte(`{"success":true}`))
+		}
+	})
+	client := group.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	created, err := client.Create(ctx, &group.CreateRequest{
+		Subject:          "Support",
+		JoinApprovalMode: group.JoinApprovalRequired,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if created.ID != "g1" || created.InviteLink != "https://chat.whatsapp.com/abc" {
+		t.Errorf("Create() = %+v", created)
+	}
+
+	list, err := client.List(ctx, &group.ListRequest{Limit: 10})
+	if err != nil || len(list.Data) != 1 || list.Data[0].ID != "g1" || list.Paging.Cursors.After != "a" {
+		t.Fatalf("List() = %+v, %v", list, err)
+	}
+
+	info, err := client.Get(ctx, "g1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	want := &group.Group{
+		ID:                    "g1",
+		Subject:               "Support",
+		Participants:          []*group.Participant{{WaID: "255700000001"}},
+		TotalParticipantCount: 1,
+	}
+	if diff := gcmp.Diff(want, info); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+
+	requests, err := client.ListJoinRequests(ctx, "g1", nil)
+	if err != nil || len(requests.Data) != 1 || requests.Data[0].ID != "jr1" {
+		t.Fatalf("ListJoinRequests() = %+v, %v", requests, err)
+	}
+
+	approved, err := client.ApproveJoinRequests(ctx, "g1", "jr1")
+	if err != nil || len(approved.Approved) != 1 {
+		t.Fatalf("ApproveJoinRequests() = %+v, %v", approved, err)
+	}
+
+	removed, err := client.RemoveParticipants(ctx, "g1", "255700000001")
+	if err != nil || !removed.Success {
+		t.Fatalf("RemoveParticipants() = %+v, %v", removed, err)
+	}
+
+	wantCalls := []call{
+		{Method: http.MethodPost, Path: "/v20.0/phone/groups", Body: map[string]any{
+			"messaging_product": "whatsapp", "subject": "Support", "join_approval_mode": "approval_required",
+		}},
+		{Method: http.MethodGet, Path: "/v20.0/phone/groups"},
+		{Method: http.MethodGet, Path: "/v20.0/g1"},
+		{Method: http.MethodGet, Path: "/v20.0/g1/join_requests"},
+		{Method: http.MethodPost, Path: "/v20.0/g1/join_requests", Body: map[string]any{
+			"messaging_product": "whatsapp", "join_requests": []any{"jr1"},
+		}},
+		{Method: http.MethodDelete, Path: "/v20.0/g1/participants", Body: map[string]any{
+			"messaging_product": "whatsapp", "participants": []any{map[string]any{"user": "255700000001"}},
+		}},
+	}
+	if diff := gcmp.Diff(wantCalls, calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("no request expected")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client := group.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	if _, err := client.Create(ctx, &group.CreateRequest{}); !errors.Is(err, group.ErrMissingSubject) {
+		t.Errorf("Create() error = %v, want ErrMissingSubject", err)
+	}
+
+	if _, err := client.RemoveParticipants(ctx, "g1"); !errors.Is(err, group.ErrNoParticipants) {
+		t.Errorf("RemoveParticipants() error = %v, want ErrNoParticipants", err)
+	}
+
+	if _, err := client.RejectJoinRequests(ctx, ""); !errors.Is(err, group.ErrMissingGroupID) {
+		t.Errorf("RejectJoinRequests() error = %v, want ErrMissingGroupID", err)
+	}
+}
+
+func TestNewGroupMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.NewGroupMessage("g1", message.WithTextMessage(&message.Text{Body: "hello"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.RecipientType != message.RecipientTypeGroup || msg.To != "g1" || msg.Type != message.TypeText {
+		t.Errorf("NewGroupMessage() = %+v", msg)
+	}
+}
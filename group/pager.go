@@ -0,0 +1,79 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package group
+
+import (
+	"context"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type (
+	// Lister lists groups, it is implemented by BaseClient and Client.
+	Lister interface {
+		List(ctx context.Context, req *ListRequest) (*ListResponse, error)
+	}
+
+	// JoinRequestLister lists the join requests of a group, it is implemented by
+	// BaseClient and Client.
+	JoinRequestLister interface {
+		ListJoinRequests(ctx context.Context, groupID string, req *ListRequest) (*ListJoinRequestsResponse, error)
+	}
+)
+
+// NewPager creates a pager over the groups of the phone number. The Limit of req sets the
+// page size and its After cursor, when set, resumes a previous walk from that page.
+func NewPager(lister Lister, req *ListRequest) *whttp.Pager[*Group] {
+	limit, after := req.page()
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*Group], error) {
+		response, err := lister.List(ctx, &ListRequest{Limit: limit, After: after})
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*Group]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, after)
+}
+
+// NewJoinRequestsPager creates a pager over the pending join requests of a group, see
+// NewPager.
+func NewJoinRequestsPager(lister JoinRequestLister, groupID string, req *ListRequest) *whttp.Pager[*JoinRequest] {
+	limit, after := req.page()
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*JoinRequest], error) {
+		response, err := lister.ListJoinRequests(ctx, groupID, &ListRequest{Limit: limit, After: after})
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*JoinRequest]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, after)
+}
+
+func (req *ListRequest) page() (int, string) {
+	if req == nil {
+		return 0, ""
+	}
+
+	return req.Limit, req.After
+}
@@ -0,0 +1,267 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/piusalfred/whatsapp/auth"
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/phonenumber"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/subscription"
+)
+
+// Names of the checks in a HealthReport.
+const (
+	HealthCheckToken        = "token"
+	HealthCheckPhoneNumber  = "phone_number"
+	HealthCheckSubscription = "webhook_subscription"
+)
+
+type (
+	// HealthCheckResult is the outcome of one check. Err is nil when the check passed.
+	// Detail describes what was found, like the phone number status.
+	HealthCheckResult struct {
+		Name   string
+		Detail string
+		Err    error
+	}
+
+	// HealthReport is the outcome of a health check. The fields hold what the API
+	// returned, they are nil when the call failed.
+	HealthReport struct {
+		CheckedAt      time.Time
+		Token          *auth.TokenInfo
+		PhoneNumber    *phonenumber.PhoneNumber
+		SubscribedApps []*subscription.SubscribedApp
+		Checks         []*HealthCheckResult
+	}
+
+	HealthCheckerOption func(*HealthChecker)
+
+	// HealthChecker checks that the credentials and the phone number of a Config can
+	// be used to send and receive messages:
+	//
+	//   - the access token is valid and not expired, inspected with /debug_token
+	//   - the phone number is CONNECTED, its quality rating is reported
+	//   - the app is subscribed to the webhooks of the business account
+	//
+	// It is meant for readiness probes and startup checks.
+	//
+	//	report, err := whatsapp.NewHealthChecker(sender, reader).HealthCheck(ctx)
+	//	if err != nil {
+	//		return err
+	//	}
+	//	if err := report.Err(); err != nil {
+	//		log.Printf("whatsapp is not ready: %v", err)
+	//	}
+	HealthChecker struct {
+		reader         config.Reader
+		sender         whttp.AnySender
+		appAccessToken string
+		now            func() time.Time
+	}
+)
+
+// WithHealthCheckAppAccessToken sets the token used to call /debug_token. By default the
+// app token "{app-id}|{app-secret}" of the Config is used when it has both, and the access
+// token itself otherwise.
+func WithHealthCheckAppAccessToken(token string) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.appAccessToken = token
+	}
+}
+
+// NewHealthChecker creates a HealthChecker for the Config read by reader.
+func NewHealthChecker(sender whttp.AnySender, reader config.Reader, options ...HealthCheckerOption) *HealthChecker {
+	h := &HealthChecker{reader: reader, sender: sender, now: time.Now}
+	for _, option := range options {
+		if option != nil {
+			option(h)
+		}
+	}
+
+	return h
+}
+
+// Healthy reports whether every check passed.
+func (r *HealthReport) Healthy() bool {
+	return r.Err() == nil
+}
+
+// Err returns an error wrapping ErrUnhealthy and the errors of the failed checks, nil
+// when all passed.
+func (r *HealthReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, check.Err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrUnhealthy, errors.Join(errs...))
+}
+
+// Check returns the result of the check named name, nil when it did not run.
+func (r *HealthReport) Check(name string) *HealthCheckResult {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	return nil
+}
+
+// HealthCheck runs the checks. The error is only set when the Config cannot be read,
+// failed checks are reported in the HealthReport.
+func (h *HealthChecker) HealthCheck(ctx context.Context) (*HealthReport, error) {
+	conf, err := h.reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("health check: read config: %w", err)
+	}
+
+	report := &HealthReport{CheckedAt: h.now()}
+	static := config.ReaderFunc(func(context.Context) (*config.Config, error) { return conf, nil })
+
+	report.Checks = append(report.Checks,
+		h.checkToken(ctx, conf, report),
+		h.checkPhoneNumber(ctx, static, report),
+		h.checkSubscription(ctx, conf, static, report),
+	)
+
+	return report, nil
+}
+
+func (h *HealthChecker) checkToken(ctx context.Context, conf *config.Config, report *HealthReport) *HealthCheckResult {
+	result := &HealthCheckResult{Name: HealthCheckToken}
+
+	appToken := h.appAccessToken
+	switch {
+	case appToken != "":
+	case conf.AppID != "" && conf.AppSecret != "":
+		appToken = conf.AppID + "|" + conf.AppSecret
+	default:
+		appToken = conf.AccessToken
+	}
+
+	info, err := auth.NewClient(conf.BaseURL, conf.APIVersion, h.sender).DebugToken(ctx, auth.DebugTokenParams{
+		InputToken:  conf.AccessToken,
+		AccessToken: appToken,
+	})
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+	report.Token = info
+
+	switch {
+	case !info.IsValid:
+		result.Err = ErrInvalidToken
+	case info.Expired(report.CheckedAt):
+		result.Err = fmt.Errorf("%w: expired at %s", ErrInvalidToken, time.Unix(info.ExpiresAt, 0).UTC())
+	case info.ExpiresAt > 0:
+		result.Detail = "expires at " + time.Unix(info.ExpiresAt, 0).UTC().Format(time.RFC3339)
+	default:
+		result.Detail = "never expires"
+	}
+
+	return result
+}
+
+func (h *HealthChecker) checkPhoneNumber(ctx context.Context, reader config.Reader,
+	report *HealthReport,
+) *HealthCheckResult {
+	result := &HealthCheckResult{Name: HealthCheckPhoneNumber}
+
+	client, err := phonenumber.NewBaseClient(reader, &phonenumber.BaseSender{Sender: h.sender})
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+
+	number, err := client.Get(ctx, &phonenumber.GetRequest{Fields: []string{
+		phonenumber.FieldStatus,
+		phonenumber.FieldQualityRating,
+		phonenumber.FieldDisplayPhoneNumber,
+		phonenumber.FieldVerifiedName,
+	}})
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+	report.PhoneNumber = number
+
+	result.Detail = fmt.Sprintf("status %s, quality rating %s", number.Status, number.QualityRating)
+	if number.Status != phonenumber.StatusConnected {
+		result.Err = fmt.Errorf("%w: status is %q", ErrPhoneNumberNotConnected, number.Status)
+	}
+
+	return result
+}
+
+func (h *HealthChecker) checkSubscription(ctx context.Context, conf *config.Config, reader config.Reader,
+	report *HealthReport,
+) *HealthCheckResult {
+	result := &HealthCheckResult{Name: HealthCheckSubscription}
+
+	apps, err := subscription.NewBaseClient(h.sender, reader).List(ctx)
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+	report.SubscribedApps = apps.Data
+
+	switch {
+	case len(apps.Data) == 0:
+		result.Err = ErrNotSubscribed
+	case conf.AppID != "" && apps.App(conf.AppID) == nil:
+		result.Err = fmt.Errorf("%w: app %s is not among the %d subscribed apps", ErrNotSubscribed,
+			conf.AppID, len(apps.Data))
+	default:
+		result.Detail = fmt.Sprintf("%d subscribed apps", len(apps.Data))
+	}
+
+	return result
+}
+
+const (
+	ErrUnhealthy               = whatsappError("unhealthy")
+	ErrInvalidToken            = whatsappError("access token is invalid")
+	ErrPhoneNumberNotConnected = whatsappError("phone number is not connected")
+	ErrNotSubscribed           = whatsappError("app is not subscribed to the webhooks")
+)
+
+// HealthCheck checks the credentials and the phone number of the tenant, see HealthChecker.
+func (c *TenantClients) HealthCheck(ctx context.Context) (*HealthReport, error) {
+	return NewHealthChecker(c.sender, c.config).HealthCheck(ctx)
+}
@@ -0,0 +1,93 @@
+package whatsapp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/piusalfred/whatsapp"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestHealthChecker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		status     string
+		apps       string
+		wantFailed []string
+	}{
+		{
+			name:   "healthy",
+			status: "CONNECTED",
+			apps:   `{"data":[{"whatsapp_business_api_data":{"id":"app","name":"app"}}]}`,
+		},
+		{
+			name:       "disconnected and not subscribed",
+			status:     "FLAGGED",
+			apps:       `{"data":[{"whatsapp_business_api_data":{"id":"OTHER_APP"}}]}`,
+			wantFailed: []string{whatsapp.HealthCheckPhoneNumber, whatsapp.HealthCheckSubscription},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /v20.0/debug_token", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("access_token") != "app|secret" {
+					http.Error(w, `{"error":{"message":"bad app token"}}`, http.StatusBadRequest)
+
+					return
+				}
+				_, _ = w.Write([]byte(`{"data":{"app_id":"app","is_valid":true,"expires_at":0}}`))
+			})
+			mux.HandleFunc("GET /v20.0/phone", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"id":"phone","status":"` + tt.status + `","quality_rating":"GREEN"}`))
+			})
+			mux.HandleFunc("GET /v20.0/waba/subscribed_apps", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.apps))
+			})
+
+			reader := apitest.NewConfigReader(t, mux.ServeHTTP)
+
+			report, err := whatsapp.NewHealthChecker(whttp.NewAnySender(), reader).HealthCheck(context.Background())
+			if err != nil {
+				t.Fatalf("HealthCheck() error = %v", err)
+			}
+
+			var failed []string
+			for _, check := range report.Checks {
+				if check.Err != nil {
+					failed = append(failed, check.Name)
+				}
+			}
+
+			if len(failed) != len(tt.wantFailed) {
+				t.Fatalf("failed checks = %v (%v), want %v", failed, report.Err(), tt.wantFailed)
+			}
+
+			for i := range failed {
+				if failed[i] != tt.wantFailed[i] {
+					t.Errorf("failed checks = %v, want %v", failed, tt.wantFailed)
+				}
+			}
+
+			if report.Healthy() != (len(tt.wantFailed) == 0) {
+				t.Errorf("Healthy() = %v", report.Healthy())
+			}
+
+			if !report.Healthy() && !errors.Is(report.Err(), whatsapp.ErrPhoneNumberNotConnected) {
+				t.Errorf("Err() = %v, want ErrPhoneNumberNotConnected", report.Err())
+			}
+
+			if report.PhoneNumber == nil || report.PhoneNumber.QualityRating != "GREEN" {
+				t.Errorf("PhoneNumber = %+v", report.PhoneNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package integration publishes conversation and consent events to external systems such
+// as CRMs over outbound webhooks.
+//
+// Every Subscription names a URL and the events it wants. Events are POSTed as JSON, signed
+// with the HMAC-SHA256 of the subscription secret over "<timestamp>.<body>", and retried
+// with exponential backoff on network errors, 429 and 5xx responses. Receivers check the
+// signature with VerifySignature.
+//
+// Conversation events come from the conversation.Manager through SessionHooks:
+//
+//	publisher := integration.NewPublisher(subscriptions)
+//	manager := conversation.NewManager(store, conversation.WithSessionHooks(publisher.SessionHooks()))
+//
+// Contact and consent events are published by the application with Dispatch or Publish.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/conversation"
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+const (
+	SignatureHeader = "X-Whatsapp-Event-Signature"
+	TimestampHeader = "X-Whatsapp-Event-Timestamp"
+	EventTypeHeader = "X-Whatsapp-Event-Type"
+	EventIDHeader   = "X-Whatsapp-Event-Id"
+)
+
+type EventType string
+
+const (
+	EventContactCreated      EventType = "contact.created"
+	EventConsentChanged      EventType = "consent.changed"
+	EventConversationStarted EventType = "conversation.started"
+	EventConversationClosed  EventType = "conversation.closed"
+)
+
+type (
+	// Event is the JSON body delivered to subscribers. ID is unique per event and lets
+	// receivers drop the duplicates caused by retries.
+	Event struct {
+		ID            string    `json:"id"`
+		Type          EventType `json:"type"`
+		OccurredAt    time.Time `json:"occurred_at"`
+		PhoneNumberID string    `json:"phone_number_id,omitempty"`
+		WaID          string    `json:"wa_id,omitempty"`
+		Data          any       `json:"data,omitempty"`
+	}
+
+	ContactData struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	// ConsentData describes a change of the customer's opt-in for a topic, such as
+	// marketing messages.
+	ConsentData struct {
+		Topic   string `json:"topic"`
+		Granted bool   `json:"granted"`
+		Source  string `json:"source,omitempty"`
+	}
+
+	ConversationData struct {
+		State     string         `json:"state,omitempty"`
+		StartedAt time.Time      `json:"started_at"`
+		Data      map[string]any `json:"data,omitempty"`
+	}
+
+	// Subscription is an external endpoint. An empty Events receives every event.
+	Subscription struct {
+		URL     string
+		Secret  string
+		Events  []EventType
+		Headers map[string]string
+	}
+)
+
+func (s *Subscription) wants(eventType EventType) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+// NewEvent returns an event with a random ID occurring now.
+func NewEvent(eventType EventType, phoneNumberID, waID string, data any) *Event {
+	id := make([]byte, 16) //nolint:mnd // 128 bit id
+	_, _ = rand.Read(id)
+
+	return &Event{
+		ID:            hex.EncodeToString(id),
+		Type:          eventType,
+		OccurredAt:    time.Now().UTC(),
+		PhoneNumberID: phoneNumberID,
+		WaID:          waID,
+		Data:          data,
+	}
+}
+
+func ContactCreated(phoneNumberID, waID, name string) *Event {
+	return NewEvent(EventContactCreated, phoneNumberID, waID, &ContactData{Name: name})
+}
+
+func ConsentChanged(phoneNumberID, waID string, consent *ConsentData) *Event {
+	return NewEvent(EventConsentChanged, phoneNumberID, waID, consent)
+}
+
+// ConversationEvent returns a conversation event describing session.
+func ConversationEvent(eventType EventType, session *conversation.Session) *Event {
+	return NewEvent(eventType, session.PhoneNumberID, session.WaID, &ConversationData{
+		State:     session.State,
+		StartedAt: session.CreatedAt,
+		Data:      session.Data,
+	})
+}
+
+type (
+	// Publisher delivers events to the subscriptions that want them.
+	Publisher struct {
+		subscriptions []*Subscription
+		client        *http.Client
+		timeout       time.Duration
+		maxAttempts   int
+		backoff       time.Duration
+		maxBackoff    time.Duration
+		now           func() time.Time
+		onError       func(ctx context.Context, subscription *Subscription, event *Event, err error)
+		wg            sync.WaitGroup
+	}
+
+	PublisherOption func(*Publisher)
+)
+
+func WithHTTPClient(client *http.Client) PublisherOption {
+	return func(p *Publisher) {
+		p.client = client
+	}
+}
+
+// WithTimeout bounds every delivery attempt. The default is 10 seconds.
+func WithTimeout(timeout time.Duration) PublisherOption {
+	return func(p *Publisher) {
+		p.timeout = timeout
+	}
+}
+
+// WithRetry sets the number of delivery attempts and the backoff between them, which
+// doubles after every attempt up to maxBackoff.
+func WithRetry(maxAttempts int, backoff, maxBackoff time.Duration) PublisherOption {
+	return func(p *Publisher) {
+		p.maxAttempts = maxAttempts
+		p.backoff = backoff
+		p.maxBackoff = maxBackoff
+	}
+}
+
+func WithClock(now func() time.Time) PublisherOption {
+	return func(p *Publisher) {
+		p.now = now
+	}
+}
+
+// WithErrorHandler sets the function called when an event dispatched in the background
+// could not be delivered.
+func WithErrorHandler(fn func(ctx context.Context, subscription *Subscription, event *Event, err error)) PublisherOption {
+	return func(p *Publisher) {
+		p.onError = fn
+	}
+}
+
+// NewPublisher returns a Publisher that tries every delivery 5 times, starting with a one
+// second backoff capped at one minute.
+func NewPublisher(subscriptions []*Subscription, options ...PublisherOption) *Publisher {
+	publisher := &Publisher{
+		subscriptions: subscriptions,
+		client:        http.DefaultClient,
+		timeout:       10 * time.Second, //nolint:mnd // default timeout
+		maxAttempts:   5,                //nolint:mnd // default attempts
+		backoff:       time.Second,
+		maxBackoff:    time.Minute,
+		now:           time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(publisher)
+		}
+	}
+
+	return publisher
+}
+
+// Publish delivers event to the subscriptions that want it and waits for them. Failed
+// deliveries do not stop the others, their errors are joined.
+func (p *Publisher) Publish(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrPublish, event.Type, err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, subscription := range p.subscriptions {
+		if !subscription.wants(event.Type) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.deliver(ctx, subscription, event, body); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Dispatch delivers event in the background, reporting failures to the error handler.
+// Cancelling ctx does not stop the delivery.
+func (p *Publisher) Dispatch(ctx context.Context, event *Event) {
+	ctx = context.WithoutCancel(ctx)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.report(ctx, nil, event, fmt.Errorf("%w: %s: %w", ErrPublish, event.Type, err))
+
+		return
+	}
+
+	for _, subscription := range p.subscriptions {
+		if !subscription.wants(event.Type) {
+			continue
+		}
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			if err := p.deliver(ctx, subscription, event, body); err != nil {
+				p.report(ctx, subscription, event, err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until the events passed to Dispatch have been delivered or given up on.
+func (p *Publisher) Wait() {
+	p.wg.Wait()
+}
+
+// SessionHooks dispatches conversation.started and conversation.closed events for the
+// sessions of a conversation.Manager.
+func (p *Publisher) SessionHooks() conversation.SessionHooks {
+	return conversation.SessionHooks{
+		OnStart: func(ctx context.Context, session *conversation.Session) {
+			p.Dispatch(ctx, ConversationEvent(EventConversationStarted, session))
+		},
+		OnEnd: func(ctx context.Context, session *conversation.Session) {
+			p.Dispatch(ctx, ConversationEvent(EventConversationClosed, session))
+		},
+	}
+}
+
+func (p *Publisher) report(ctx context.Context, subscription *Subscription, event *Event, err error) {
+	if p.onError != nil {
+		p.onError(ctx, subscription, event, err)
+	}
+}
+
+func (p *Publisher) deliver(ctx context.Context, subscription *Subscription, event *Event, body []byte) error {
+	backoff := p.backoff
+
+	for attempt := 1; ; attempt++ {
+		retry, err := p.attempt(ctx, subscription, event, body)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= p.maxAttempts {
+			return fmt.Errorf("%w: %s to %s after %d attempts: %w", ErrDeliver, event.Type,
+				subscription.URL, attempt, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("%w: %s to %s: %w", ErrDeliver, event.Type, subscription.URL, ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, p.maxBackoff) //nolint:mnd // exponential backoff
+	}
+}
+
+// attempt posts body once and reports whether a failure is worth retrying.
+func (p *Publisher) attempt(ctx context.Context, subscription *Subscription, event *Event, body []byte) (bool, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(p.now().Unix(), 10)
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range subscription.Headers {
+		request.Header.Set(key, value)
+	}
+	request.Header.Set(EventTypeHeader, string(event.Type))
+	request.Header.Set(EventIDHeader, event.ID)
+	request.Header.Set(TimestampHeader, timestamp)
+
+	if subscription.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(body, timestamp, subscription.Secret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+
+	return retry, fmt.Errorf("status %d", response.StatusCode)
+}
+
+// Sign returns the signature header value of body sent at timestamp, "sha256=<hex>".
+func Sign(body []byte, timestamp, secret string) string {
+	return "sha256=" + hex.EncodeToString(crypto.HMACSHA256([]byte(secret), signedPayload(body, timestamp)))
+}
+
+// VerifySignature checks the signature and timestamp headers of a delivery. Deliveries
+// whose timestamp is further than tolerance from now are rejected to prevent replays, a
+// zero tolerance skips that check.
+func VerifySignature(body []byte, timestamp, signature, secret string, tolerance time.Duration, now time.Time) error {
+	if tolerance > 0 {
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+		}
+
+		if skew := now.Sub(time.Unix(seconds, 0)); skew > tolerance || skew < -tolerance {
+			return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+		}
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(body, timestamp, secret))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func signedPayload(body []byte, timestamp string) []byte {
+	return append([]byte(timestamp+"."), body...)
+}
+
+// integrationError is a custom error type for integration errors.
+type integrationError string
+
+func (e integrationError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrPublish          = integrationError("could not publish event")
+	ErrDeliver          = integrationError("could not deliver event")
+	ErrInvalidSignature = integrationError("invalid event signature")
+)
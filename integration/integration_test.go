@@ -0,0 +1,96 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/conversation"
+	"github.com/piusalfred/whatsapp/integration"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestPublisher(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		received []*integration.Event
+		calls    atomic.Int32
+	)
+
+	crm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if err := integration.VerifySignature(body, r.Header.Get(integration.TimestampHeader),
+			r.Header.Get(integration.SignatureHeader), "crm-secret", time.Minute, time.Now()); err != nil {
+			t.Errorf("VerifySignature() = %v", err)
+		}
+
+		var event integration.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		received = append(received, &event)
+		mu.Unlock()
+	}))
+	defer crm.Close()
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	publisher := integration.NewPublisher([]*integration.Subscription{
+		{URL: crm.URL, Secret: "crm-secret", Events: []integration.EventType{integration.EventConversationStarted, integration.EventConversationClosed}},
+		{URL: rejecting.URL, Events: []integration.EventType{integration.EventConsentChanged}},
+	}, integration.WithRetry(3, time.Millisecond, time.Millisecond))
+
+	ctx := context.Background()
+	err := publisher.Publish(ctx, integration.ConsentChanged("phone", "255700", &integration.ConsentData{Topic: "marketing"}))
+	if !errors.Is(err, integration.ErrDeliver) {
+		t.Errorf("expected %v from the rejecting subscription, got %v", integration.ErrDeliver, err)
+	}
+
+	manager := conversation.NewManager(conversation.NewMemoryStore(),
+		conversation.WithSessionHooks(publisher.SessionHooks()))
+
+	handler := conversation.Handler(manager, message.HandlerFunc[message.Text](
+		func(ctx context.Context, _ *message.NotificationContext, _ *message.Info, _ *message.Text) error {
+			conversation.EndFromContext(ctx)
+
+			return nil
+		}))
+
+	if err := handler.Handle(ctx, &message.NotificationContext{}, &message.Info{From: "255700"}, &message.Text{}); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	types := map[integration.EventType]bool{}
+	for _, event := range received {
+		types[event.Type] = event.WaID == "255700"
+	}
+
+	if len(received) != 2 || !types[integration.EventConversationStarted] || !types[integration.EventConversationClosed] {
+		t.Errorf("unexpected events delivered: %v", types)
+	}
+}
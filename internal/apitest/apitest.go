@@ -0,0 +1,60 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package apitest holds the fixtures shared by the tests of the API clients.
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+const (
+	APIVersion        = "v20.0"
+	AccessToken       = "token"
+	PhoneNumberID     = "phone"
+	BusinessAccountID = "waba"
+	AppID             = "app"
+	AppSecret         = "secret"
+)
+
+// NewConfigReader starts a server answering with handler and returns a reader of a config
+// pointing at it. The server is closed when the test ends.
+func NewConfigReader(t testing.TB, handler http.HandlerFunc) config.Reader {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{
+			BaseURL:           server.URL,
+			APIVersion:        APIVersion,
+			AccessToken:       AccessToken,
+			PhoneNumberID:     PhoneNumberID,
+			BusinessAccountID: BusinessAccountID,
+			AppID:             AppID,
+			AppSecret:         AppSecret,
+		}, nil
+	})
+}
@@ -0,0 +1,346 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// Client keeps the surface of the whatsapp.Client that older releases of this library
+// exported, implemented on top of message.BaseClient. It lets existing code compile
+// against the current packages and be migrated one call at a time:
+//
+//	whatsapp.NewClient(opts...)            -> message.NewBaseClient(sender, reader)
+//	client.SendTextMessage(ctx, to, text)  -> client.SendText(ctx, message.NewRequest(to, text, ""))
+//	client.React(ctx, to, reaction)        -> client.SendReaction(ctx, message.NewRequest(to, reaction, ""))
+//	client.MarkMessageRead(ctx, id)        -> client.MarkAsRead(ctx, id)
+//	RequestParams{ReplyID: id}             -> message.Request[T]{ReplyTo: id}
+//
+// Deprecated: use the message, media and qrcode packages.
+type Client struct {
+	config *config.Config
+	http   *http.Client
+	base   *message.BaseClient
+}
+
+// ClientOption configures a Client.
+//
+// Deprecated: configure a config.Reader and the pkg/http sender instead.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http client used to make requests.
+//
+// Deprecated: use whttp.WithCoreClientHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) {
+		client.http = httpClient
+	}
+}
+
+// WithBaseURL sets the base url of the Cloud API.
+//
+// Deprecated: set config.Config.BaseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(client *Client) {
+		client.config.BaseURL = baseURL
+	}
+}
+
+// WithVersion sets the API version.
+//
+// Deprecated: set config.Config.APIVersion.
+func WithVersion(version string) ClientOption {
+	return func(client *Client) {
+		client.config.APIVersion = version
+	}
+}
+
+// WithAccessToken sets the access token.
+//
+// Deprecated: set config.Config.AccessToken.
+func WithAccessToken(accessToken string) ClientOption {
+	return func(client *Client) {
+		client.config.AccessToken = accessToken
+	}
+}
+
+// WithPhoneNumberID sets the phone number id messages are sent from.
+//
+// Deprecated: set config.Config.PhoneNumberID.
+func WithPhoneNumberID(phoneNumberID string) ClientOption {
+	return func(client *Client) {
+		client.config.PhoneNumberID = phoneNumberID
+	}
+}
+
+// WithBusinessAccountID sets the WhatsApp Business Account id.
+//
+// Deprecated: set config.Config.BusinessAccountID.
+func WithBusinessAccountID(businessAccountID string) ClientOption {
+	return func(client *Client) {
+		client.config.BusinessAccountID = businessAccountID
+	}
+}
+
+// NewClient creates a Client with the given options. Unset values default to BaseURL and
+// LowestSupportedAPIVersion.
+//
+// Deprecated: use the message, media and qrcode packages.
+func NewClient(options ...ClientOption) *Client {
+	client := &Client{
+		config: &config.Config{
+			BaseURL:    BaseURL,
+			APIVersion: LowestSupportedAPIVersion,
+		},
+		http: http.DefaultClient,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(client)
+		}
+	}
+
+	sender := whttp.NewSender[message.Message](whttp.WithCoreClientHTTPClient[message.Message](client.http))
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		conf := *client.config
+
+		return &conf, nil
+	})
+
+	// NewBaseClient never fails without middlewares.
+	client.base, _ = message.NewBaseClient(sender, reader)
+
+	return client
+}
+
+// Config returns a copy of the client configuration.
+func (client *Client) Config() *config.Config {
+	conf := *client.config
+
+	return &conf
+}
+
+// Messages returns the message.BaseClient the legacy methods delegate to.
+func (client *Client) Messages() *message.BaseClient {
+	return client.base
+}
+
+type (
+	// RequestParams are the common parameters of a legacy send call.
+	//
+	// Deprecated: use message.Request.
+	RequestParams struct {
+		ID        string
+		Metadata  map[string]string
+		Recipient string
+		ReplyID   string
+	}
+
+	// TextMessage is the legacy text payload.
+	//
+	// Deprecated: use message.Text.
+	TextMessage struct {
+		Message    string
+		PreviewURL bool
+	}
+
+	// ReactMessage is the legacy reaction payload.
+	//
+	// Deprecated: use message.Reaction.
+	ReactMessage struct {
+		MessageID string
+		Emoji     string
+	}
+
+	// MediaMessage is the legacy media payload. Exactly one of MediaID and MediaLink
+	// should be set.
+	//
+	// Deprecated: use message.Image, message.Video, message.Audio, message.Document or message.Sticker.
+	MediaMessage struct {
+		Type      MediaType
+		MediaID   string
+		MediaLink string
+		Caption   string
+		Filename  string
+	}
+
+	// MediaType is the type of media sent with SendMedia.
+	//
+	// Deprecated: use the message.TypeXXX constants.
+	MediaType string
+
+	// ResponseMessage is the response of the legacy send methods.
+	//
+	// Deprecated: use message.Response.
+	ResponseMessage = message.Response
+
+	// StatusResponse is the response of MarkMessageRead.
+	//
+	// Deprecated: use message.StatusUpdateResponse.
+	StatusResponse = message.StatusUpdateResponse
+)
+
+const (
+	MediaTypeAudio    MediaType = "audio"
+	MediaTypeDocument MediaType = "document"
+	MediaTypeImage    MediaType = "image"
+	MediaTypeSticker  MediaType = "sticker"
+	MediaTypeVideo    MediaType = "video"
+)
+
+// SendTextMessage sends a text message.
+//
+// Deprecated: use message.BaseClient.SendText.
+func (client *Client) SendTextMessage(ctx context.Context, recipient string,
+	text *TextMessage,
+) (*ResponseMessage, error) {
+	return client.base.SendText(ctx, message.NewRequest(recipient, &message.Text{
+		PreviewURL: text.PreviewURL,
+		Body:       text.Message,
+	}, ""))
+}
+
+// SendLocationMessage sends a location message.
+//
+// Deprecated: use message.BaseClient.SendLocation.
+func (client *Client) SendLocationMessage(ctx context.Context, recipient string,
+	location *message.Location,
+) (*ResponseMessage, error) {
+	return client.base.SendLocation(ctx, message.NewRequest(recipient, location, ""))
+}
+
+// React sends a reaction to a message.
+//
+// Deprecated: use message.BaseClient.SendReaction.
+func (client *Client) React(ctx context.Context, recipient string, reaction *ReactMessage) (*ResponseMessage, error) {
+	return client.base.SendReaction(ctx, message.NewRequest(recipient, &message.Reaction{
+		MessageID: reaction.MessageID,
+		Emoji:     reaction.Emoji,
+	}, ""))
+}
+
+// SendContacts sends contact cards.
+//
+// Deprecated: use message.BaseClient.SendContacts.
+func (client *Client) SendContacts(ctx context.Context, recipient string,
+	contacts []*message.Contact,
+) (*ResponseMessage, error) {
+	c := message.Contacts(contacts)
+
+	return client.base.SendContacts(ctx, message.NewRequest(recipient, &c, ""))
+}
+
+// SendTemplate sends a template message.
+//
+// Deprecated: use message.BaseClient.SendTemplate.
+func (client *Client) SendTemplate(ctx context.Context, recipient string,
+	template *message.Template,
+) (*ResponseMessage, error) {
+	return client.base.SendTemplate(ctx, message.NewRequest(recipient, template, ""))
+}
+
+// SendInteractiveMessage sends an interactive message.
+//
+// Deprecated: use message.BaseClient.SendInteractiveMessage.
+func (client *Client) SendInteractiveMessage(ctx context.Context, recipient string,
+	interactive *message.Interactive,
+) (*ResponseMessage, error) {
+	return client.base.SendInteractiveMessage(ctx, message.NewRequest(recipient, interactive, ""))
+}
+
+// SendMedia sends an image, video, audio, document or sticker.
+//
+// Deprecated: use the matching message.BaseClient method, for example SendImage.
+func (client *Client) SendMedia(ctx context.Context, recipient string, media *MediaMessage) (*ResponseMessage, error) {
+	switch media.Type {
+	case MediaTypeImage:
+		return client.base.SendImage(ctx, message.NewRequest(recipient, &message.Image{
+			ID: media.MediaID, Link: media.MediaLink, Caption: media.Caption,
+		}, ""))
+	case MediaTypeVideo:
+		return client.base.SendVideo(ctx, message.NewRequest(recipient, &message.Video{
+			ID: media.MediaID, Link: media.MediaLink, Caption: media.Caption,
+		}, ""))
+	case MediaTypeAudio:
+		return client.base.SendAudio(ctx, message.NewRequest(recipient, &message.Audio{
+			ID: media.MediaID, Link: media.MediaLink,
+		}, ""))
+	case MediaTypeDocument:
+		return client.base.SendDocument(ctx, message.NewRequest(recipient, &message.Document{
+			ID: media.MediaID, Link: media.MediaLink, Caption: media.Caption, Filename: media.Filename,
+		}, ""))
+	case MediaTypeSticker:
+		return client.base.SendSticker(ctx, message.NewRequest(recipient, &message.Sticker{
+			ID: media.MediaID, Link: media.MediaLink,
+		}, ""))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, string(media.Type))
+	}
+}
+
+// Send sends a message built from options to params.Recipient, replying to params.ReplyID
+// when it is set. params.Metadata is attached to the request context.
+//
+// Deprecated: use message.BaseClient.SendMessage with message.New.
+func (client *Client) Send(ctx context.Context, params *RequestParams,
+	options ...message.Option,
+) (*ResponseMessage, error) {
+	if params.ReplyID != "" {
+		options = append(options, message.WithMessageAsReplyTo(params.ReplyID))
+	}
+
+	msg, err := message.New(params.Recipient, options...)
+	if err != nil {
+		return nil, fmt.Errorf("legacy send: %w", err)
+	}
+
+	if len(params.Metadata) > 0 {
+		metadata := make(map[string]any, len(params.Metadata))
+		for k, v := range params.Metadata {
+			metadata[k] = v
+		}
+		ctx = whttp.InjectMessageMetadata(ctx, metadata)
+	}
+
+	return client.base.SendMessage(ctx, msg)
+}
+
+// MarkMessageRead marks a received message as read.
+//
+// Deprecated: use message.BaseClient.MarkAsRead.
+func (client *Client) MarkMessageRead(ctx context.Context, messageID string) (*StatusResponse, error) {
+	return client.base.MarkAsRead(ctx, messageID)
+}
+
+// whatsappError is a custom error type for errors of the root package.
+type whatsappError string
+
+func (e whatsappError) Error() string {
+	return string(e)
+}
+
+const ErrUnsupportedMediaType = whatsappError("unsupported media type")
@@ -0,0 +1,62 @@
+package whatsapp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/whatsapp"
+)
+
+func TestLegacyClientSendTextMessage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		gotPath string
+		gotBody map[string]any
+		gotAuth string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer server.Close()
+
+	client := whatsapp.NewClient(
+		whatsapp.WithBaseURL(server.URL),
+		whatsapp.WithVersion("v20.0"),
+		whatsapp.WithAccessToken("token"),
+		whatsapp.WithPhoneNumberID("1234"),
+	)
+
+	resp, err := client.SendTextMessage(context.Background(), "255700000000", &whatsapp.TextMessage{
+		Message: "hello",
+	})
+	if err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+
+	if len(resp.Messages) != 1 || resp.Messages[0].ID != "wamid.1" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if gotPath != "/v20.0/1234/messages" {
+		t.Errorf("path = %q", gotPath)
+	}
+
+	if gotAuth != "Bearer token" {
+		t.Errorf("authorization = %q", gotAuth)
+	}
+
+	if gotBody["to"] != "255700000000" || gotBody["type"] != "text" {
+		t.Errorf("unexpected body: %v", gotBody)
+	}
+}
@@ -0,0 +1,367 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/media"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/qrcode"
+)
+
+// DefaultTenantRefreshInterval is how long a tenant Config is cached before it is read
+// again from the config.MultiReader, unless configured otherwise.
+const DefaultTenantRefreshInterval = 5 * time.Minute
+
+const ErrTenantClients = whatsappError("tenant clients")
+
+type (
+	// TenantClients are the API clients of one tenant. They share a Config that the
+	// ClientManager reads from its config.MultiReader and keeps fresh.
+	TenantClients struct {
+		TenantID string
+		Messages *message.BaseClient
+		Media    *media.BaseClient
+		QRCodes  *qrcode.BaseClient
+
+		sender whttp.AnySender
+		config config.Reader
+	}
+
+	ClientManagerOption func(*ClientManager)
+
+	// ClientManager is a registry of per-tenant clients keyed by tenant or WhatsApp Business
+	// Account ID. Clients are constructed on first use and cached. The Config of a tenant is
+	// read again after the refresh interval, so rotated credentials are picked up without
+	// rebuilding the clients. Tenants unused for longer than the idle timeout, or the least
+	// recently used ones beyond the maximum, are evicted.
+	//
+	//	manager := whatsapp.NewClientManager(tenantStore)
+	//	clients, err := manager.Get(ctx, tenantID)
+	//	resp, err := clients.Messages.SendText(ctx, request)
+	ClientManager struct {
+		reader          config.MultiReader
+		messageSender   whttp.Sender[message.Message]
+		sender          whttp.AnySender
+		refreshInterval time.Duration
+		idleTimeout     time.Duration
+		maxTenants      int
+		now             func() time.Time
+
+		mu      sync.Mutex
+		tenants map[string]*tenantEntry
+	}
+
+	tenantEntry struct {
+		clients  *TenantClients
+		reader   *tenantReader
+		lastUsed time.Time
+	}
+
+	// tenantReader is the config.Reader shared by the clients of one tenant.
+	tenantReader struct {
+		multi    config.MultiReader
+		tenantID string
+		interval time.Duration
+		now      func() time.Time
+
+		mu     sync.Mutex
+		conf   *config.Config
+		readAt time.Time
+	}
+)
+
+// WithClientManagerMessageSender sets the sender of the message clients. The default is a
+// whttp.CoreClient with default options.
+func WithClientManagerMessageSender(sender whttp.Sender[message.Message]) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.messageSender = sender
+	}
+}
+
+// WithClientManagerSender sets the sender of the media and qr code clients. The default is
+// a whttp.CoreClient with default options.
+func WithClientManagerSender(sender whttp.AnySender) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.sender = sender
+	}
+}
+
+// WithClientManagerRefreshInterval sets how long a tenant Config is cached. Zero reads the
+// Config on every request.
+func WithClientManagerRefreshInterval(interval time.Duration) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.refreshInterval = interval
+	}
+}
+
+// WithClientManagerIdleTimeout evicts tenants whose clients were not requested for the
+// given duration. Zero, the default, keeps idle tenants.
+func WithClientManagerIdleTimeout(timeout time.Duration) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.idleTimeout = timeout
+	}
+}
+
+// WithClientManagerMaxTenants caps the number of cached tenants, the least recently used
+// are evicted first. Zero, the default, means no limit.
+func WithClientManagerMaxTenants(n int) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.maxTenants = n
+	}
+}
+
+// WithClientManagerClock sets the clock used for refreshes and eviction.
+func WithClientManagerClock(now func() time.Time) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.now = now
+	}
+}
+
+// NewClientManager creates a ClientManager that reads tenant configs from reader.
+func NewClientManager(reader config.MultiReader, options ...ClientManagerOption) *ClientManager {
+	m := &ClientManager{
+		reader:          reader,
+		refreshInterval: DefaultTenantRefreshInterval,
+		now:             time.Now,
+		tenants:         make(map[string]*tenantEntry),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(m)
+		}
+	}
+
+	if m.messageSender == nil {
+		m.messageSender = whttp.NewSender[message.Message]()
+	}
+
+	if m.sender == nil {
+		m.sender = whttp.NewAnySender()
+	}
+
+	return m
+}
+
+// Get returns the clients of tenantID, constructing them on first use. The tenant Config
+// is read before the clients are cached, an unknown tenant is an error.
+func (m *ClientManager) Get(ctx context.Context, tenantID string) (*TenantClients, error) {
+	now := m.now()
+
+	m.mu.Lock()
+	m.evictIdle(now)
+	if entry, ok := m.tenants[tenantID]; ok {
+		entry.lastUsed = now
+		m.mu.Unlock()
+
+		return entry.clients, nil
+	}
+	m.mu.Unlock()
+
+	entry, err := m.newEntry(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	entry.lastUsed = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.tenants[tenantID]; ok {
+		existing.lastUsed = now
+
+		return existing.clients, nil
+	}
+
+	m.tenants[tenantID] = entry
+	m.evictOverflow()
+
+	return entry.clients, nil
+}
+
+// Messages returns the message client of tenantID.
+func (m *ClientManager) Messages(ctx context.Context, tenantID string) (*message.BaseClient, error) {
+	clients, err := m.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients.Messages, nil
+}
+
+// Media returns the media client of tenantID.
+func (m *ClientManager) Media(ctx context.Context, tenantID string) (*media.BaseClient, error) {
+	clients, err := m.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients.Media, nil
+}
+
+// QRCodes returns the qr code client of tenantID.
+func (m *ClientManager) QRCodes(ctx context.Context, tenantID string) (*qrcode.BaseClient, error) {
+	clients, err := m.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients.QRCodes, nil
+}
+
+// Refresh reads the Config of a cached tenant again, for example after its access token
+// was rotated. It does nothing for tenants that are not cached.
+func (m *ClientManager) Refresh(ctx context.Context, tenantID string) error {
+	m.mu.Lock()
+	entry, ok := m.tenants[tenantID]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	if _, err := entry.reader.refresh(ctx); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrTenantClients, tenantID, err)
+	}
+
+	return nil
+}
+
+// Evict removes the clients of tenantID, they are constructed again on the next Get.
+func (m *ClientManager) Evict(tenantID string) {
+	m.mu.Lock()
+	delete(m.tenants, tenantID)
+	m.mu.Unlock()
+}
+
+// Tenants returns the IDs of the cached tenants, sorted.
+func (m *ClientManager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (m *ClientManager) newEntry(ctx context.Context, tenantID string) (*tenantEntry, error) {
+	reader := &tenantReader{
+		multi:    m.reader,
+		tenantID: tenantID,
+		interval: m.refreshInterval,
+		now:      m.now,
+	}
+
+	if _, err := reader.refresh(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrTenantClients, tenantID, err)
+	}
+
+	messages, err := message.NewBaseClient(m.messageSender, reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrTenantClients, tenantID, err)
+	}
+
+	return &tenantEntry{
+		clients: &TenantClients{
+			TenantID: tenantID,
+			Messages: messages,
+			Media:    &media.BaseClient{ConfReader: reader, Sender: m.sender},
+			QRCodes:  qrcode.NewBaseClient(m.sender, reader),
+			sender:   m.sender,
+			config:   reader,
+		},
+		reader: reader,
+	}, nil
+}
+
+// evictIdle removes tenants idle for longer than the idle timeout. m.mu must be held.
+func (m *ClientManager) evictIdle(now time.Time) {
+	if m.idleTimeout <= 0 {
+		return
+	}
+
+	for id, entry := range m.tenants {
+		if now.Sub(entry.lastUsed) > m.idleTimeout {
+			delete(m.tenants, id)
+		}
+	}
+}
+
+// evictOverflow removes the least recently used tenants beyond the maximum. m.mu must be
+// held.
+func (m *ClientManager) evictOverflow() {
+	for m.maxTenants > 0 && len(m.tenants) > m.maxTenants {
+		var (
+			oldestID string
+			oldest   time.Time
+		)
+
+		for id, entry := range m.tenants {
+			if oldestID == "" || entry.lastUsed.Before(oldest) {
+				oldestID, oldest = id, entry.lastUsed
+			}
+		}
+
+		delete(m.tenants, oldestID)
+	}
+}
+
+// Read returns the cached tenant Config, reading it again once the refresh interval has
+// passed. A copy is returned so callers cannot change the cached Config.
+func (r *tenantReader) Read(ctx context.Context) (*config.Config, error) {
+	r.mu.Lock()
+	conf, readAt := r.conf, r.readAt
+	r.mu.Unlock()
+
+	if conf == nil || r.now().Sub(readAt) >= r.interval {
+		return r.refresh(ctx)
+	}
+
+	c := *conf
+
+	return &c, nil
+}
+
+func (r *tenantReader) refresh(ctx context.Context) (*config.Config, error) {
+	conf, err := r.multi.ReadTenant(ctx, r.tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *conf
+
+	r.mu.Lock()
+	r.conf, r.readAt = &c, r.now()
+	r.mu.Unlock()
+
+	return conf, nil
+}
@@ -0,0 +1,134 @@
+package whatsapp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp"
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/whatsapptest"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestClientManager(t *testing.T) {
+	t.Parallel()
+
+	server := whatsapptest.NewServer(whatsapptest.WithStatuses(0))
+	t.Cleanup(server.Close)
+
+	tenantConfig := func(token string) *config.Config {
+		conf := server.Config()
+		conf.AccessToken = token
+
+		return conf
+	}
+
+	store := config.NewStaticMultiReader(map[string]*config.Config{
+		"acme":    tenantConfig("stale-token"),
+		"globex":  tenantConfig(whatsapptest.DefaultAccessToken),
+		"initech": tenantConfig(whatsapptest.DefaultAccessToken),
+	})
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	manager := whatsapp.NewClientManager(store,
+		whatsapp.WithClientManagerRefreshInterval(time.Minute),
+		whatsapp.WithClientManagerIdleTimeout(time.Hour),
+		whatsapp.WithClientManagerMaxTenants(2),
+		whatsapp.WithClientManagerClock(clock.Now),
+	)
+
+	ctx := context.Background()
+	send := func(tenantID string) error {
+		client, err := manager.Messages(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.SendText(ctx, message.NewRequest("255700000000", &message.Text{Body: "hi"}, ""))
+
+		return err
+	}
+
+	if _, err := manager.Get(ctx, "unknown"); !errors.Is(err, config.ErrTenantNotFound) {
+		t.Fatalf("Get(unknown) error = %v, want %v", err, config.ErrTenantNotFound)
+	}
+
+	first, err := manager.Get(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := manager.Get(ctx, "acme")
+	if err != nil || again != first {
+		t.Fatalf("Get() did not return the cached clients: %v", err)
+	}
+
+	if err := send("acme"); err == nil {
+		t.Fatal("send with a stale token succeeded")
+	}
+
+	// Rotated credentials are picked up after the refresh interval or on Refresh.
+	store.Set("acme", tenantConfig(whatsapptest.DefaultAccessToken))
+	if err := send("acme"); err == nil {
+		t.Fatal("cached config was read again before the refresh interval")
+	}
+
+	if err := manager.Refresh(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := send("acme"); err != nil {
+		t.Fatalf("send after refresh error = %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if err := send("globex"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A third tenant evicts the least recently used one.
+	clock.Advance(time.Second)
+	if _, err := manager.Get(ctx, "initech"); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff([]string{"globex", "initech"}, manager.Tenants()); diff != "" {
+		t.Errorf("tenants mismatch (-want +got):\n%s", diff)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if _, err := manager.Get(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff([]string{"acme"}, manager.Tenants()); diff != "" {
+		t.Errorf("tenants after idle eviction mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(server.Messages()) != 2 {
+		t.Errorf("server received %d messages, want 2", len(server.Messages()))
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+var (
+	ErrMediaTooLarge = errors.New("media exceeds the size limit")
+	ErrMediaChecksum = errors.New("media checksum mismatch")
+)
+
+type (
+	// DownloadToRequest identifies the media to download. MaxSize limits the size of the
+	// media, zero uses the limit of its type in InfoMap, or DocMaxSize for unknown types.
+	DownloadToRequest struct {
+		BaseRequest
+		MaxSize int64
+	}
+
+	// DownloadResult describes downloaded media. ContentType is the mime type reported in
+	// the media information, or the content type of the download when it has none. SHA256
+	// is the hex encoded checksum of the downloaded bytes.
+	DownloadResult struct {
+		MediaID     string
+		ContentType string
+		Size        int64
+		SHA256      string
+	}
+)
+
+// DownloadTo looks up the media and streams it to w without buffering it. The download is
+// checked against the size limit and the sha256 checksum in the media information. The
+// checksum can only be verified once all the bytes were written, so w may have received
+// media that fails with ErrMediaChecksum or ErrMediaTooLarge. Downloads are not retried
+// since w cannot be rewound.
+func (s *BaseClient) DownloadTo(ctx context.Context, req *DownloadToRequest, w io.Writer) (*DownloadResult, error) {
+	info, err := s.GetInfo(ctx, &req.BaseRequest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMediaDownload, err)
+	}
+
+	maxSize := req.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxSizeOf(info.MimeType)
+	}
+
+	if info.FileSize > maxSize {
+		return nil, fmt.Errorf("%w: %w: %d bytes exceeds the %d bytes limit", ErrMediaDownload, ErrMediaTooLarge,
+			info.FileSize, maxSize)
+	}
+
+	result := &DownloadResult{MediaID: req.MediaID, ContentType: info.MimeType}
+	hash := sha256.New()
+
+	decoder := whttp.ResponseDecoderFunc(func(_ context.Context, response *http.Response) error {
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			return whttp.DecodeResponseJSON[any](response, nil, whttp.DecodeOptions{InspectResponseError: true})
+		}
+
+		if result.ContentType == "" {
+			result.ContentType = response.Header.Get("Content-Type")
+		}
+
+		n, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(response.Body, maxSize+1))
+		result.Size = n
+		if err != nil {
+			return fmt.Errorf("write media: %w", err)
+		}
+
+		if n > maxSize {
+			return fmt.Errorf("%w: more than %d bytes", ErrMediaTooLarge, maxSize)
+		}
+
+		return nil
+	})
+
+	if err := s.Download(ctx, &DownloadRequest{URL: info.URL}, decoder); err != nil {
+		return nil, err
+	}
+
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if info.SHA256 != "" && !strings.EqualFold(result.SHA256, info.SHA256) {
+		return nil, fmt.Errorf("%w: %w: got %s, want %s", ErrMediaDownload, ErrMediaChecksum,
+			result.SHA256, info.SHA256)
+	}
+
+	return result, nil
+}
+
+// DownloadToFile downloads the media to the file at path. The media is written to a
+// temporary file in the same directory that replaces path only once it is complete and
+// verified, so path never holds partial or corrupted media.
+func (s *BaseClient) DownloadToFile(ctx context.Context, req *DownloadToRequest, path string) (*DownloadResult, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".media-*")
+	if err != nil {
+		return nil, fmt.Errorf("%w: create file: %w", ErrMediaDownload, err)
+	}
+
+	result, err := s.DownloadTo(ctx, req, tmp)
+	if errClose := tmp.Close(); err == nil && errClose != nil {
+		err = fmt.Errorf("%w: write file: %w", ErrMediaDownload, errClose)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return nil, fmt.Errorf("%w: write file: %w", ErrMediaDownload, err)
+	}
+
+	return result, nil
+}
+
+func maxSizeOf(mimeType string) int64 {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	if info, ok := InfoMap[Type(strings.TrimSpace(mediaType))]; ok {
+		return info.MaxSize
+	}
+
+	return DocMaxSize
+}
@@ -0,0 +1,133 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/media"
+)
+
+func newDownloadClient(t *testing.T, content []byte, checksum string) *media.BaseClient {
+	t.Helper()
+
+	return newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file" {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(content)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"messaging_product":"whatsapp","url":"http://%s/file","mime_type":"image/png",`+
+			`"sha256":%q,"file_size":%d,"id":"media-id"}`, r.Host, checksum, len(content))
+	})
+}
+
+func checksumOf(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBaseClient_DownloadTo(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("png"), 1024)
+	client := newDownloadClient(t, content, checksumOf(content))
+
+	var buf bytes.Buffer
+	result, err := client.DownloadTo(context.Background(),
+		&media.DownloadToRequest{BaseRequest: media.BaseRequest{MediaID: "media-id"}}, &buf)
+	if err != nil {
+		t.Fatalf("DownloadTo() error = %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("DownloadTo() wrote %d bytes, want %d", buf.Len(), len(content))
+	}
+
+	want := media.DownloadResult{
+		MediaID:     "media-id",
+		ContentType: "image/png",
+		Size:        int64(len(content)),
+		SHA256:      checksumOf(content),
+	}
+	if *result != want {
+		t.Errorf("DownloadTo() = %+v, want %+v", *result, want)
+	}
+}
+
+func TestBaseClient_DownloadToFailures(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("x", 2048))
+
+	tests := []struct {
+		name     string
+		checksum string
+		maxSize  int64
+		wantErr  error
+	}{
+		{name: "checksum mismatch", checksum: checksumOf([]byte("other")), wantErr: media.ErrMediaChecksum},
+		{name: "too large", checksum: checksumOf(content), maxSize: 1024, wantErr: media.ErrMediaTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newDownloadClient(t, content, tt.checksum)
+			path := filepath.Join(t.TempDir(), "media.png")
+
+			_, err := client.DownloadToFile(context.Background(), &media.DownloadToRequest{
+				BaseRequest: media.BaseRequest{MediaID: "media-id"},
+				MaxSize:     tt.maxSize,
+			}, path)
+			if !errors.Is(err, tt.wantErr) || !errors.Is(err, media.ErrMediaDownload) {
+				t.Fatalf("DownloadToFile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			entries, _ := os.ReadDir(filepath.Dir(path))
+			if len(entries) != 0 {
+				t.Errorf("DownloadToFile() left %d files behind", len(entries))
+			}
+		})
+	}
+}
+
+func TestBaseClient_DownloadToFile(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("image content")
+	client := newDownloadClient(t, content, checksumOf(content))
+	path := filepath.Join(t.TempDir(), "media.png")
+
+	if _, err := client.DownloadToFile(context.Background(),
+		&media.DownloadToRequest{BaseRequest: media.BaseRequest{MediaID: "media-id"}}, path); err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, content) {
+		t.Errorf("file content = %q, want %q", data, content)
+	}
+}
@@ -233,12 +233,22 @@ type (
 		Retries int
 	}
 
+	// UploadRequest describes a file to upload. The file is read from the path Filename
+	// unless Reader is set, in which case it is streamed from Reader and Filename only
+	// names it. Size is the number of bytes in Reader, zero when unknown. Progress, when
+	// set, is called as the file content is sent.
 	UploadRequest struct {
 		MediaType Type
 		Filename  string
 		Reader    io.Reader
+		Size      int64
+		Progress  UploadProgressFunc
 	}
 
+	// UploadProgressFunc receives the number of bytes of the file sent so far and the total
+	// size, which is zero when unknown.
+	UploadProgressFunc func(sent, total int64)
+
 	UploadMediaResponse struct {
 		ID string `json:"id"` // ID of the uploaded media
 	}
@@ -394,6 +404,20 @@ func (s *BaseClient) Upload(ctx context.Context, req *UploadRequest) (*UploadMed
 		return nil, fmt.Errorf("%w: media type not supported", ErrMediaUpload)
 	}
 
+	if req.Reader != nil && req.Size > 0 {
+		// Static and animated stickers share a mime type, so the larger limit applies here.
+		// The sticker package checks the exact limit.
+		info := InfoMap[req.MediaType]
+		if isAnimated {
+			info.MaxSize = StickerAnimatedMaxSize
+		}
+
+		if req.Size > info.MaxSize {
+			return nil, fmt.Errorf("%w: %d bytes exceeds the %d bytes limit for %s", ErrMediaUpload,
+				req.Size, info.MaxSize, req.MediaType)
+		}
+	}
+
 	form := &whttp.RequestForm{
 		Fields: map[string]string{
 			"type":              string(req.MediaType),
@@ -405,6 +429,13 @@ func (s *BaseClient) Upload(ctx context.Context, req *UploadRequest) (*UploadMed
 		},
 	}
 
+	if req.Reader != nil {
+		form.FormFile.Path = ""
+		form.FormFile.Filename = uploadFilename(req)
+		form.FormFile.Size = req.Size
+		form.FormFile.Reader = &uploadReader{ctx: ctx, reader: req.Reader, total: req.Size, progress: req.Progress}
+	}
+
 	opts := []whttp.RequestOption[any]{
 		whttp.WithRequestAppSecret[any](conf.AppSecret),
 		whttp.WithRequestSecured[any](conf.SecureRequests),
@@ -428,3 +459,55 @@ func (s *BaseClient) Upload(ctx context.Context, req *UploadRequest) (*UploadMed
 
 	return &resp, nil
 }
+
+// UploadReader streams size bytes of media from reader without buffering them. A size of
+// zero means the size is unknown, the upload is then sent chunked. Canceling ctx aborts the
+// upload. Streamed uploads are sent once, they are not retried by the sender.
+func (s *BaseClient) UploadReader(ctx context.Context, reader io.Reader, size int64, mediaType Type,
+	progress UploadProgressFunc,
+) (*UploadMediaResponse, error) {
+	return s.Upload(ctx, &UploadRequest{
+		MediaType: mediaType,
+		Reader:    reader,
+		Size:      size,
+		Progress:  progress,
+	})
+}
+
+func uploadFilename(req *UploadRequest) string {
+	if req.Filename != "" {
+		return req.Filename
+	}
+
+	if info, ok := InfoMap[req.MediaType]; ok {
+		return "file" + info.Extension
+	}
+
+	return "file"
+}
+
+// uploadReader reads the file of a streamed upload, it stops once ctx is done and reports
+// the progress.
+type uploadReader struct {
+	ctx      context.Context //nolint:containedctx // bound to a single upload
+	reader   io.Reader
+	total    int64
+	sent     int64
+	progress UploadProgressFunc
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		if r.progress != nil {
+			r.progress(r.sent, r.total)
+		}
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must not be wrapped
+}
@@ -0,0 +1,117 @@
+package media_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/media"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func newClient(t *testing.T, handler http.HandlerFunc) *media.BaseClient {
+	t.Helper()
+
+	return &media.BaseClient{
+		ConfReader: apitest.NewConfigReader(t, handler),
+		Sender:     whttp.NewAnySender(),
+	}
+}
+
+func TestBaseClient_UploadReader(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("%PDF", 64*1024)
+	var received string
+
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		defer file.Close()
+
+		data, _ := io.ReadAll(file)
+		received = header.Filename + ":" + r.FormValue("type") + ":" + string(data)
+		_, _ = w.Write([]byte(`{"id":"media-id"}`))
+	})
+
+	var sent, total int64
+	resp, err := client.UploadReader(context.Background(), strings.NewReader(content), int64(len(content)),
+		media.TypeDocPDF, func(s, t int64) { sent, total = s, t })
+	if err != nil {
+		t.Fatalf("UploadReader() error = %v", err)
+	}
+
+	if resp.ID != "media-id" {
+		t.Errorf("ID = %q", resp.ID)
+	}
+
+	if want := "file.pdf:application/pdf:" + content; received != want {
+		t.Errorf("server received %d bytes, want %d", len(received), len(want))
+	}
+
+	if sent != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("progress = %d/%d, want %d/%d", sent, total, len(content), len(content))
+	}
+}
+
+func TestBaseClient_UploadReaderCanceled(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"id":"media-id"}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	content := strings.Repeat("v", 4*1024*1024)
+	_, err := client.UploadReader(ctx, strings.NewReader(content), int64(len(content)), media.TypeVideoMP4,
+		func(sent, _ int64) {
+			if sent > 1024*1024 {
+				cancel()
+			}
+		})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("UploadReader() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBaseClient_UploadReaderTooLarge(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected request")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.UploadReader(context.Background(), strings.NewReader(""), media.ImageMaxSize+1,
+		media.TypeImagePNG, nil)
+	if !errors.Is(err, media.ErrMediaUpload) {
+		t.Fatalf("UploadReader() error = %v, want ErrMediaUpload", err)
+	}
+}
+
+func TestBaseClient_UploadReaderAnimatedSticker(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"id":"sticker-id"}`))
+	})
+
+	// Animated stickers share the mime type of static ones but may be up to 500KB.
+	content := strings.Repeat("w", 300*1024)
+	if _, err := client.UploadReader(context.Background(), strings.NewReader(content), int64(len(content)),
+		media.TypeStickerAnimated, nil); err != nil {
+		t.Fatalf("UploadReader() error = %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package resumable implements the Resumable Upload API, which uploads files to the Meta app
+// in chunks and returns a handle for them. The handle is what template creation expects as
+// the sample of an image, video or document header, see template.HeaderMedia.
+//
+// An upload starts with a session. The file is then sent in chunks, each one starting at
+// the offset the session has reached, so an interrupted upload resumes where it stopped
+// when Upload is called again with the same session.
+package resumable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/media"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// DefaultChunkSize is the size of the chunks sent by Upload unless configured otherwise.
+const DefaultChunkSize = 4 * 1024 * 1024
+
+const (
+	EndpointUploads  = "uploads"
+	HeaderFileOffset = "file_offset"
+)
+
+var (
+	ErrCreateSession = errors.New("create upload session failed")
+	ErrGetSession    = errors.New("get upload session failed")
+	ErrUploadChunk   = errors.New("upload chunk failed")
+	ErrUpload        = errors.New("resumable upload failed")
+	ErrMissingAppID  = errors.New("app id is required to create an upload session")
+	ErrMissingHandle = errors.New("upload completed without a file handle")
+)
+
+type (
+	// SessionRequest describes the file to upload. FileType is its MIME type, for example
+	// image/jpeg or application/pdf.
+	SessionRequest struct {
+		FileName   string
+		FileLength int64
+		FileType   string
+	}
+
+	// Session is an upload session. FileOffset is the number of bytes received so far and
+	// is only reported by GetSession.
+	Session struct {
+		ID         string `json:"id"`
+		FileOffset int64  `json:"file_offset,omitempty"`
+	}
+
+	// UploadRequest uploads Size bytes of File to the session SessionID, starting at the
+	// offset the session has reached. Progress, when set, is called after every chunk.
+	UploadRequest struct {
+		SessionID string
+		File      io.ReaderAt
+		Size      int64
+		Progress  media.UploadProgressFunc
+	}
+
+	// UploadResponse carries the handle of the uploaded file once the last chunk is sent.
+	UploadResponse struct {
+		Handle string `json:"h"`
+	}
+
+	BaseClient struct {
+		ConfReader config.Reader
+		Sender     whttp.AnySender
+		ChunkSize  int64
+	}
+)
+
+// CreateSession starts an upload session on the app set in the config.
+func (c *BaseClient) CreateSession(ctx context.Context, req *SessionRequest) (*Session, error) {
+	conf, err := c.ConfReader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: config read: %w", ErrCreateSession, err)
+	}
+
+	if conf.AppID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrCreateSession, ErrMissingAppID)
+	}
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestType[any](whttp.RequestTypeCreateUploadSession),
+		whttp.WithRequestEndpoints[any](conf.APIVersion, conf.AppID, EndpointUploads),
+		whttp.WithRequestQueryParams[any](map[string]string{
+			"file_name":   req.FileName,
+			"file_length": strconv.FormatInt(req.FileLength, 10),
+			"file_type":   req.FileType,
+		}),
+	}
+
+	request := whttp.MakeRequest[any](http.MethodPost, conf.BaseURL, opts...)
+
+	var session Session
+	decoder := whttp.ResponseDecoderJSON(&session, whttp.DecodeOptions{
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := c.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateSession, err)
+	}
+
+	return &session, nil
+}
+
+// GetSession returns the session with the offset it has reached.
+func (c *BaseClient) GetSession(ctx context.Context, sessionID string) (*Session, error) {
+	conf, err := c.ConfReader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: config read: %w", ErrGetSession, err)
+	}
+
+	request := sessionRequest(conf, http.MethodGet, whttp.RequestTypeGetUploadSession, sessionID)
+
+	var session Session
+	decoder := whttp.ResponseDecoderJSON(&session, whttp.DecodeOptions{
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := c.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetSession, err)
+	}
+
+	return &session, nil
+}
+
+// UploadChunk sends chunk as the part of the file starting at offset. The response carries
+// the file handle once the file is complete.
+func (c *BaseClient) UploadChunk(ctx context.Context, sessionID string, offset int64,
+	chunk []byte,
+) (*UploadResponse, error) {
+	conf, err := c.ConfReader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: config read: %w", ErrUploadChunk, err)
+	}
+
+	request := sessionRequest(conf, http.MethodPost, whttp.RequestTypeUploadFileChunk, sessionID)
+	request.Headers[HeaderFileOffset] = strconv.FormatInt(offset, 10)
+
+	var body any = chunk
+	request.Message = &body
+
+	var resp UploadResponse
+	decoder := whttp.ResponseDecoderJSON(&resp, whttp.DecodeOptions{InspectResponseError: true})
+
+	if err := c.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("%w: offset %d: %w", ErrUploadChunk, offset, err)
+	}
+
+	return &resp, nil
+}
+
+// Upload sends the rest of the file to the session in chunks and returns the file handle.
+// When it fails, calling it again with the same request resumes the upload.
+func (c *BaseClient) Upload(ctx context.Context, req *UploadRequest) (*UploadResponse, error) {
+	session, err := c.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpload, err)
+	}
+
+	chunkSize := c.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	buf := make([]byte, min(chunkSize, max(req.Size-session.FileOffset, 0)))
+	for offset := session.FileOffset; ; {
+		n := min(chunkSize, max(req.Size-offset, 0))
+		chunk := buf[:n]
+		if _, err := req.File.ReadAt(chunk, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: read file at offset %d: %w", ErrUpload, offset, err)
+		}
+
+		resp, err := c.UploadChunk(ctx, req.SessionID, offset, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUpload, err)
+		}
+
+		offset += n
+		if req.Progress != nil {
+			req.Progress(offset, req.Size)
+		}
+
+		if offset < req.Size {
+			continue
+		}
+
+		if resp.Handle == "" {
+			return nil, fmt.Errorf("%w: %w", ErrUpload, ErrMissingHandle)
+		}
+
+		return resp, nil
+	}
+}
+
+// UploadFile uploads the file at path in a new session and returns its handle.
+func (c *BaseClient) UploadFile(ctx context.Context, path string, fileType media.Type) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUpload, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUpload, err)
+	}
+
+	session, err := c.CreateSession(ctx, &SessionRequest{
+		FileName:   filepath.Base(path),
+		FileLength: info.Size(),
+		FileType:   string(fileType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUpload, err)
+	}
+
+	resp, err := c.Upload(ctx, &UploadRequest{SessionID: session.ID, File: file, Size: info.Size()})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Handle, nil
+}
+
+// sessionRequest builds a request to the session endpoint. Session IDs look like
+// "upload:<id>?sig=<signature>", the signature is sent as a query parameter. The session
+// endpoints take the access token in an OAuth authorization header, which replaces the
+// bearer one.
+func sessionRequest(conf *config.Config, method string, requestType whttp.RequestType,
+	sessionID string,
+) *whttp.Request[any] {
+	id, query, _ := strings.Cut(sessionID, "?")
+	params := map[string]string{}
+	if values, err := url.ParseQuery(query); err == nil {
+		for key := range values {
+			params[key] = values.Get(key)
+		}
+	}
+
+	return whttp.MakeRequest[any](method, conf.BaseURL,
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestType[any](requestType),
+		whttp.WithRequestEndpoints[any](conf.APIVersion, id),
+		whttp.WithRequestQueryParams[any](params),
+		whttp.WithRequestHeaders[any](map[string]string{"Authorization": "OAuth " + conf.AccessToken}),
+	)
+}
@@ -0,0 +1,153 @@
+package resumable_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/media/resumable"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const sessionID = "upload:MTphdHRhY2htZW50?sig=ARZqkGCA"
+
+// uploadServer is a fake of the upload endpoints. It fails the chunk sent at failAt once.
+type uploadServer struct {
+	mu       sync.Mutex
+	received bytes.Buffer
+	size     int
+	failAt   int
+	failed   bool
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v20.0/app/uploads":
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("file_type") != "image/png" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		s.size, _ = strconv.Atoi(r.URL.Query().Get("file_length"))
+		fmt.Fprintf(w, `{"id":%q}`, sessionID)
+
+	case r.URL.Path == "/v20.0/upload:MTphdHRhY2htZW50" && r.URL.Query().Get("sig") == "ARZqkGCA":
+		if r.Header.Get("Authorization") != "OAuth token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"id":%q,"file_offset":%d}`, sessionID, s.received.Len())
+
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.Header.Get("file_offset"))
+		if offset != s.received.Len() {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		if offset == s.failAt && !s.failed {
+			s.failed = true
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"connection reset","code":1}}`))
+
+			return
+		}
+
+		chunk, _ := io.ReadAll(r.Body)
+		s.received.Write(chunk)
+		if s.received.Len() == s.size {
+			_, _ = w.Write([]byte(`{"h":"4::aW1hZ2U="}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestBaseClient_UploadResumes(t *testing.T) {
+	t.Parallel()
+
+	fake := &uploadServer{failAt: 20}
+	client := &resumable.BaseClient{
+		ConfReader: apitest.NewConfigReader(t, fake.ServeHTTP),
+		Sender:     whttp.NewAnySender(),
+		ChunkSize:  10,
+	}
+
+	ctx := context.Background()
+	content := strings.Repeat("0123456789", 4) + "tail"
+	file := strings.NewReader(content)
+
+	session, err := client.CreateSession(ctx, &resumable.SessionRequest{
+		FileName: "header.png", FileLength: int64(len(content)), FileType: "image/png",
+	})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	var progress []int64
+	request := &resumable.UploadRequest{
+		SessionID: session.ID,
+		File:      file,
+		Size:      int64(len(content)),
+		Progress:  func(sent, _ int64) { progress = append(progress, sent) },
+	}
+
+	if _, err := client.Upload(ctx, request); !errors.Is(err, resumable.ErrUploadChunk) {
+		t.Fatalf("first Upload() error = %v, want ErrUploadChunk", err)
+	}
+
+	resp, err := client.Upload(ctx, request)
+	if err != nil {
+		t.Fatalf("resumed Upload() error = %v", err)
+	}
+
+	if resp.Handle != "4::aW1hZ2U=" {
+		t.Errorf("Handle = %q", resp.Handle)
+	}
+
+	if fake.received.String() != content {
+		t.Errorf("server received %q, want %q", fake.received.String(), content)
+	}
+
+	if want := "[10 20 30 40 44]"; fmt.Sprint(progress) != want {
+		t.Errorf("progress = %v, want %s", progress, want)
+	}
+}
+
+func TestBaseClient_CreateSessionWithoutAppID(t *testing.T) {
+	t.Parallel()
+
+	client := &resumable.BaseClient{
+		ConfReader: config.ReaderFunc(func(context.Context) (*config.Config, error) {
+			return &config.Config{BaseURL: "http://localhost", APIVersion: "v21.0"}, nil
+		}),
+		Sender: whttp.NewAnySender(),
+	}
+
+	_, err := client.CreateSession(context.Background(), &resumable.SessionRequest{FileName: "a.png"})
+	if !errors.Is(err, resumable.ErrMissingAppID) {
+		t.Fatalf("CreateSession() error = %v, want ErrMissingAppID", err)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package sticker checks stickers against the WhatsApp requirements before they are
+// uploaded.
+//
+// Stickers must be 512x512 pixel WebP images, of at most 100KB when static and 500KB when
+// animated. The Cloud API rejects other images only when the message is delivered, so
+// Validate inspects the WebP headers locally and reports what needs converting: Info has
+// the dimensions found and whether the sticker is animated.
+//
+//	req, err := sticker.NewUploadRequest(data, "smile.webp")
+//	if err != nil {
+//		return err // errors.Is(err, sticker.ErrDimensions) means the image must be resized
+//	}
+//	resp, err := mediaClient.Upload(ctx, req)
+//	msg, err := message.NewStickerMessage(recipient, &message.Sticker{ID: resp.ID})
+package sticker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/media"
+)
+
+// Dimension is the required width and height of stickers in pixels.
+const Dimension = 512
+
+// Info describes a WebP image.
+type Info struct {
+	Width    int
+	Height   int
+	Animated bool
+	Size     int64
+}
+
+// MaxSize returns the size limit of the sticker, which depends on whether it is animated.
+func (info *Info) MaxSize() int64 {
+	if info.Animated {
+		return media.StickerAnimatedMaxSize
+	}
+
+	return media.StickerStaticMaxSize
+}
+
+// Uploader uploads media, it is implemented by media.BaseClient.
+type Uploader interface {
+	Upload(ctx context.Context, req *media.UploadRequest) (*media.UploadMediaResponse, error)
+}
+
+// Inspect reads the dimensions and the animation flag from the headers of a WebP image.
+func Inspect(data []byte) (*Info, error) {
+	const headerSize = 12 // "RIFF", the file size and "WEBP"
+	if len(data) < headerSize+8 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrNotWebP
+	}
+
+	info := &Info{Size: int64(len(data))}
+	chunk, payload := string(data[12:16]), data[20:]
+
+	switch chunk {
+	case "VP8X": // extended format, the canvas size is stored minus one on 24 bits
+		if len(payload) < 10 { //nolint:mnd // flags, reserved bytes and two 24 bit sizes
+			return nil, fmt.Errorf("%w: truncated VP8X chunk", ErrNotWebP)
+		}
+		info.Animated = payload[0]&0x02 != 0
+		info.Width = 1 + (int(payload[4]) | int(payload[5])<<8 | int(payload[6])<<16)
+		info.Height = 1 + (int(payload[7]) | int(payload[8])<<8 | int(payload[9])<<16)
+	case "VP8 ": // lossy, the frame header is followed by a start code and 14 bit sizes
+		if len(payload) < 10 || !bytes.Equal(payload[3:6], []byte{0x9d, 0x01, 0x2a}) {
+			return nil, fmt.Errorf("%w: invalid VP8 frame header", ErrNotWebP)
+		}
+		info.Width = int(binary.LittleEndian.Uint16(payload[6:8]) & 0x3fff)
+		info.Height = int(binary.LittleEndian.Uint16(payload[8:10]) & 0x3fff)
+	case "VP8L": // lossless, a signature byte followed by two 14 bit sizes minus one
+		if len(payload) < 5 || payload[0] != 0x2f {
+			return nil, fmt.Errorf("%w: invalid VP8L header", ErrNotWebP)
+		}
+		bits := binary.LittleEndian.Uint32(payload[1:5])
+		info.Width = 1 + int(bits&0x3fff)
+		info.Height = 1 + int(bits>>14&0x3fff)
+	default:
+		return nil, fmt.Errorf("%w: unknown chunk %q", ErrNotWebP, chunk)
+	}
+
+	return info, nil
+}
+
+// Validate checks that data is a sticker WhatsApp accepts. The returned error wraps
+// ErrInvalidSticker and one of ErrNotWebP, ErrDimensions and ErrTooLarge for every check
+// that failed. The Info is returned as long as data is a WebP image.
+func Validate(data []byte) (*Info, error) {
+	info, err := Inspect(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSticker, err)
+	}
+
+	var errs []error
+	if info.Width != Dimension || info.Height != Dimension {
+		errs = append(errs, fmt.Errorf("%w: got %dx%d, want %dx%d", ErrDimensions,
+			info.Width, info.Height, Dimension, Dimension))
+	}
+
+	if info.Size > info.MaxSize() {
+		errs = append(errs, fmt.Errorf("%w: %d bytes exceeds the %d bytes limit", ErrTooLarge,
+			info.Size, info.MaxSize()))
+	}
+
+	if len(errs) > 0 {
+		return info, fmt.Errorf("%w: %w", ErrInvalidSticker, errors.Join(errs...))
+	}
+
+	return info, nil
+}
+
+// NewUploadRequest validates the sticker and returns the request to upload it.
+func NewUploadRequest(data []byte, filename string) (*media.UploadRequest, error) {
+	info, err := Validate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := media.TypeStickerStatic
+	if info.Animated {
+		mediaType = media.TypeStickerAnimated
+	}
+
+	if filename == "" {
+		filename = "sticker.webp"
+	}
+
+	return &media.UploadRequest{
+		MediaType: mediaType,
+		Filename:  filename,
+		Reader:    bytes.NewReader(data),
+		Size:      info.Size,
+	}, nil
+}
+
+// Upload validates and uploads the sticker, returning its media ID.
+func Upload(ctx context.Context, uploader Uploader, data []byte, filename string) (string, error) {
+	req, err := NewUploadRequest(data, filename)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := uploader.Upload(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("upload sticker: %w", err)
+	}
+
+	return resp.ID, nil
+}
+
+// stickerError is a custom error type for sticker errors.
+type stickerError string
+
+func (e stickerError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrInvalidSticker = stickerError("invalid sticker")
+	ErrNotWebP        = stickerError("not a webp image")
+	ErrDimensions     = stickerError("sticker must be 512x512 pixels")
+	ErrTooLarge       = stickerError("sticker is too large")
+)
@@ -0,0 +1,151 @@
+package sticker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/media"
+	"github.com/piusalfred/whatsapp/media/sticker"
+)
+
+// webp builds a WebP file with a single chunk followed by padding up to size bytes.
+func webp(chunk string, payload []byte, size int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(max(size-8, 0)))
+	buf.WriteString("WEBP")
+	buf.WriteString(chunk)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(payload)))
+	buf.Write(payload)
+
+	if buf.Len() < size {
+		buf.Write(make([]byte, size-buf.Len()))
+	}
+
+	return buf.Bytes()
+}
+
+func vp8x(width, height int, animated bool) []byte {
+	payload := make([]byte, 10)
+	if animated {
+		payload[0] = 0x02
+	}
+
+	w, h := width-1, height-1
+	payload[4], payload[5], payload[6] = byte(w), byte(w>>8), byte(w>>16)
+	payload[7], payload[8], payload[9] = byte(h), byte(h>>8), byte(h>>16)
+
+	return payload
+}
+
+func vp8(width, height int) []byte {
+	payload := []byte{0, 0, 0, 0x9d, 0x01, 0x2a, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint16(payload[6:], uint16(width))
+	binary.LittleEndian.PutUint16(payload[8:], uint16(height))
+
+	return payload
+}
+
+func vp8l(width, height int) []byte {
+	payload := make([]byte, 5)
+	payload[0] = 0x2f
+	binary.LittleEndian.PutUint32(payload[1:], uint32(width-1)|uint32(height-1)<<14)
+
+	return payload
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *sticker.Info
+		wantErr []error
+	}{
+		{
+			name: "static lossy",
+			data: webp("VP8 ", vp8(512, 512), 1024),
+			want: &sticker.Info{Width: 512, Height: 512, Size: 1024},
+		},
+		{
+			name: "animated",
+			data: webp("VP8X", vp8x(512, 512, true), 300*1024),
+			want: &sticker.Info{Width: 512, Height: 512, Animated: true, Size: 300 * 1024},
+		},
+		{
+			name:    "static too large",
+			data:    webp("VP8L", vp8l(512, 512), 300*1024),
+			want:    &sticker.Info{Width: 512, Height: 512, Size: 300 * 1024},
+			wantErr: []error{sticker.ErrInvalidSticker, sticker.ErrTooLarge},
+		},
+		{
+			name:    "wrong dimensions",
+			data:    webp("VP8L", vp8l(640, 480), 2048),
+			want:    &sticker.Info{Width: 640, Height: 480, Size: 2048},
+			wantErr: []error{sticker.ErrInvalidSticker, sticker.ErrDimensions},
+		},
+		{
+			name:    "png",
+			data:    []byte("\x89PNG\r\n\x1a\n0000000000000000"),
+			wantErr: []error{sticker.ErrInvalidSticker, sticker.ErrNotWebP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := sticker.Validate(tt.data)
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate() error = %v, want %v", err, want)
+				}
+			}
+
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+
+			if diff := gcmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type uploaderFunc func(ctx context.Context, req *media.UploadRequest) (*media.UploadMediaResponse, error)
+
+func (fn uploaderFunc) Upload(ctx context.Context, req *media.UploadRequest) (*media.UploadMediaResponse, error) {
+	return fn(ctx, req)
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	data := webp("VP8X", vp8x(512, 512, true), 200*1024)
+	var got *media.UploadRequest
+	id, err := sticker.Upload(context.Background(), uploaderFunc(func(_ context.Context,
+		req *media.UploadRequest,
+	) (*media.UploadMediaResponse, error) {
+		got = req
+
+		return &media.UploadMediaResponse{ID: "sticker-id"}, nil
+	}), data, "")
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if id != "sticker-id" {
+		t.Errorf("Upload() = %q", id)
+	}
+
+	if got.MediaType != media.TypeStickerAnimated || got.Filename != "sticker.webp" || got.Size != int64(len(data)) {
+		t.Errorf("upload request = %+v", got)
+	}
+}
@@ -0,0 +1,350 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package mediaack acknowledges the media customers send, as document collection
+// workflows such as KYC or insurance claims expect.
+//
+// An Acknowledger replies to the media types of its Policy with a confirmation carrying a
+// generated reference, and stores a Receipt linking the reference to the media:
+//
+//	ack := mediaack.New(client, mediaack.NewMemoryStore(), mediaack.Policy{
+//		Types: []string{mediaack.TypeDocument, mediaack.TypeImage},
+//		Text:  "We received your {type}, ref #{reference}.",
+//	})
+//	handlers.DocumentMessage = ack.Handler(handlers.DocumentMessage)
+//	handlers.ImageMessage = ack.Handler(handlers.ImageMessage)
+//
+// Media is acknowledged once, webhook deliveries retried after a successful
+// acknowledgment reuse the stored receipt.
+package mediaack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// Media types that can be acknowledged.
+const (
+	TypeAudio    = "audio"
+	TypeDocument = "document"
+	TypeImage    = "image"
+	TypeSticker  = "sticker"
+	TypeVideo    = "video"
+)
+
+// DefaultText is the acknowledgment sent when the policy sets neither Text nor Message.
+const DefaultText = "We received your {type}. Your reference is {reference}."
+
+type (
+	// Receipt is an acknowledged media message. AckMessageID is the ID of the
+	// acknowledgment sent to the contact.
+	Receipt struct {
+		Reference     string              `json:"reference"`
+		PhoneNumberID string              `json:"phone_number_id,omitempty"`
+		Contact       string              `json:"contact"`
+		MessageID     string              `json:"message_id"`
+		Type          string              `json:"type"`
+		Media         *wmessage.MediaInfo `json:"media,omitempty"`
+		ReceivedAt    time.Time           `json:"received_at"`
+		AckMessageID  string              `json:"ack_message_id,omitempty"`
+	}
+
+	// Policy selects the media types to acknowledge and the acknowledgment. Text may use the
+	// {reference}, {type} and {filename} placeholders. Message, when set, builds the
+	// acknowledgment instead, for template messages.
+	Policy struct {
+		Types   []string
+		Text    string
+		Message func(receipt *Receipt) *wmessage.Message
+	}
+
+	Store interface {
+		// Save inserts the receipt or replaces the one with the same Reference.
+		Save(ctx context.Context, receipt *Receipt) error
+		Get(ctx context.Context, reference string) (*Receipt, error)
+		// GetByMessageID returns the receipt of the media message with the given ID.
+		GetByMessageID(ctx context.Context, messageID string) (*Receipt, error)
+		// List returns the receipts of contact, oldest first.
+		List(ctx context.Context, contact string) ([]*Receipt, error)
+	}
+
+	Option func(*Acknowledger)
+
+	// Acknowledger acknowledges media according to a Policy.
+	Acknowledger struct {
+		sender       wmessage.MessageSender
+		store        Store
+		policy       Policy
+		newReference func() string
+		now          func() time.Time
+	}
+
+	receiptContextKey struct{}
+)
+
+// WithReferenceGenerator sets the function generating references, the default generates
+// 10 random characters from the base32 alphabet.
+func WithReferenceGenerator(fn func() string) Option {
+	return func(a *Acknowledger) {
+		a.newReference = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(a *Acknowledger) {
+		a.now = now
+	}
+}
+
+func New(sender wmessage.MessageSender, store Store, policy Policy, options ...Option) *Acknowledger {
+	a := &Acknowledger{
+		sender:       sender,
+		store:        store,
+		policy:       policy,
+		newReference: NewReference,
+		now:          time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(a)
+		}
+	}
+
+	return a
+}
+
+// NewReference returns a random reference such as "K7QF2MZXAB".
+func NewReference() string {
+	b := make([]byte, 10) //nolint:mnd // encodes to 16 characters
+	_, _ = rand.Read(b)
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)[:10]
+}
+
+// ReceiptFromContext returns the receipt of the media being handled.
+func ReceiptFromContext(ctx context.Context) (*Receipt, bool) {
+	receipt, ok := ctx.Value(receiptContextKey{}).(*Receipt)
+
+	return receipt, ok
+}
+
+// Acknowledges reports whether the policy acknowledges mediaType.
+func (a *Acknowledger) Acknowledges(mediaType string) bool {
+	return slices.Contains(a.policy.Types, mediaType)
+}
+
+// Acknowledge sends the acknowledgment of the media message described by info and stores
+// its receipt. It returns nil when the policy does not acknowledge the media type, and the
+// stored receipt when the media was already acknowledged.
+func (a *Acknowledger) Acknowledge(ctx context.Context, phoneNumberID string, info *message.Info,
+	media *wmessage.MediaInfo,
+) (*Receipt, error) {
+	if info == nil || !a.Acknowledges(info.Type) {
+		return nil, nil //nolint:nilnil // not acknowledged
+	}
+
+	if receipt, err := a.store.GetByMessageID(ctx, info.ID); err == nil {
+		return receipt, nil
+	}
+
+	receipt := &Receipt{
+		Reference:     a.newReference(),
+		PhoneNumberID: phoneNumberID,
+		Contact:       info.From,
+		MessageID:     info.ID,
+		Type:          info.Type,
+		Media:         media,
+		ReceivedAt:    a.now(),
+	}
+
+	msg := a.message(receipt)
+	response, err := a.sender.SendMessage(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAcknowledge, err)
+	}
+
+	if response != nil && len(response.Messages) > 0 {
+		receipt.AckMessageID = response.Messages[0].ID
+	}
+
+	if err := a.store.Save(ctx, receipt); err != nil {
+		return nil, fmt.Errorf("save receipt: %w", err)
+	}
+
+	return receipt, nil
+}
+
+// Handler acknowledges the media handled by next before calling it with the receipt in
+// the context. next is not called when the acknowledgment fails.
+func (a *Acknowledger) Handler(next message.MediaMessageHandler) message.MediaMessageHandler {
+	return message.OnMediaMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, media *wmessage.MediaInfo,
+	) error {
+		var phoneNumberID string
+		if nctx != nil && nctx.Metadata != nil {
+			phoneNumberID = nctx.Metadata.PhoneNumberID
+		}
+
+		receipt, err := a.Acknowledge(ctx, phoneNumberID, mctx, media)
+		if err != nil {
+			return err
+		}
+
+		if receipt != nil {
+			ctx = context.WithValue(ctx, receiptContextKey{}, receipt)
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		return next.Handle(ctx, nctx, mctx, media)
+	})
+}
+
+func (a *Acknowledger) message(receipt *Receipt) *wmessage.Message {
+	if a.policy.Message != nil {
+		msg := a.policy.Message(receipt)
+		if msg.To == "" {
+			msg.To = receipt.Contact
+		}
+
+		return msg
+	}
+
+	text := a.policy.Text
+	if text == "" {
+		text = DefaultText
+	}
+
+	var filename string
+	if receipt.Media != nil {
+		filename = receipt.Media.Filename
+	}
+
+	body := strings.NewReplacer(
+		"{reference}", receipt.Reference,
+		"{type}", receipt.Type,
+		"{filename}", filename,
+	).Replace(text)
+
+	msg, _ := wmessage.New(receipt.Contact, wmessage.WithTextMessage(&wmessage.Text{Body: body}))
+	if wmessage.ValidateReplyTo(receipt.MessageID) == nil {
+		msg.Context = &wmessage.Context{MessageID: receipt.MessageID}
+	}
+
+	return msg
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps receipts in memory.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	receipts map[string]*Receipt
+	messages map[string]string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		receipts: make(map[string]*Receipt),
+		messages: make(map[string]string),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, receipt *Receipt) error {
+	if receipt == nil || receipt.Reference == "" {
+		return ErrInvalidReceipt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *receipt
+	s.receipts[receipt.Reference] = &stored
+	if receipt.MessageID != "" {
+		s.messages[receipt.MessageID] = receipt.Reference
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, reference string) (*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.get(reference)
+}
+
+func (s *MemoryStore) GetByMessageID(_ context.Context, messageID string) (*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.get(s.messages[messageID])
+}
+
+func (s *MemoryStore) List(_ context.Context, contact string) ([]*Receipt, error) {
+	s.mu.RLock()
+	result := make([]*Receipt, 0)
+	for _, receipt := range s.receipts {
+		if receipt.Contact == contact {
+			r := *receipt
+			result = append(result, &r)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ReceivedAt.Before(result[j].ReceivedAt)
+	})
+
+	return result, nil
+}
+
+func (s *MemoryStore) get(reference string) (*Receipt, error) {
+	receipt, ok := s.receipts[reference]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r := *receipt
+
+	return &r, nil
+}
+
+type ackError string
+
+func (e ackError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrAcknowledge    = ackError("send media acknowledgment failed")
+	ErrInvalidReceipt = ackError("receipt must have a reference")
+	ErrNotFound       = ackError("receipt not found")
+)
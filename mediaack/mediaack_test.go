@@ -0,0 +1,93 @@
+package mediaack_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/mediaack"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type recordingSender struct {
+	sent []*wmessage.Message
+	err  error
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, msg *wmessage.Message) (*wmessage.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.sent = append(s.sent, msg)
+
+	return &wmessage.Response{Messages: []*wmessage.ID{{ID: "wamid.ack"}}}, nil
+}
+
+func TestAcknowledger_Handler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sender := &recordingSender{}
+	store := mediaack.NewMemoryStore()
+	ack := mediaack.New(sender, store, mediaack.Policy{
+		Types: []string{mediaack.TypeDocument},
+		Text:  "We received {filename}, ref #{reference}",
+	}, mediaack.WithReferenceGenerator(func() string { return "REF1" }))
+
+	var handled *mediaack.Receipt
+	handler := ack.Handler(message.OnMediaMessageHook(func(ctx context.Context,
+		_ *message.NotificationContext, _ *message.Info, _ *wmessage.MediaInfo,
+	) error {
+		handled, _ = mediaack.ReceiptFromContext(ctx)
+
+		return nil
+	}))
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "111"}}
+	info := &message.Info{From: "255700000001", ID: "wamid.doc", Type: "document"}
+	doc := &wmessage.MediaInfo{ID: "media-1", Filename: "passport.pdf"}
+
+	// a retried delivery is not acknowledged twice.
+	for range 2 {
+		if err := handler.Handle(ctx, nctx, info, doc); err != nil {
+			t.Fatalf("handle: %v", err)
+		}
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d acknowledgments, want 1", len(sender.sent))
+	}
+
+	sent := sender.sent[0]
+	if sent.To != "255700000001" || sent.Text.Body != "We received passport.pdf, ref #REF1" ||
+		sent.Context == nil || sent.Context.MessageID != "wamid.doc" {
+		t.Fatalf("unexpected acknowledgment %+v %+v", sent, sent.Text)
+	}
+
+	if handled == nil || handled.Reference != "REF1" || handled.AckMessageID != "wamid.ack" {
+		t.Fatalf("unexpected receipt in context %+v", handled)
+	}
+
+	stored, err := store.Get(ctx, "REF1")
+	if err != nil || stored.Media.ID != "media-1" || stored.PhoneNumberID != "111" {
+		t.Fatalf("stored receipt = %+v, %v", stored, err)
+	}
+
+	// images are not acknowledged by the policy.
+	handled = nil
+	image := &message.Info{From: "255700000001", ID: "wamid.img", Type: "image"}
+	if err := handler.Handle(ctx, nctx, image, &wmessage.MediaInfo{ID: "media-2"}); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	if handled != nil || len(sender.sent) != 1 {
+		t.Fatal("image should not be acknowledged")
+	}
+
+	sender.err = errors.New("send failed")
+	other := &message.Info{From: "255700000002", ID: "wamid.doc2", Type: "document"}
+	if err := handler.Handle(ctx, nctx, other, doc); !errors.Is(err, mediaack.ErrAcknowledge) {
+		t.Fatalf("expected %v, got %v", mediaack.ErrAcknowledge, err)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	TypeInteractiveAddress   = "address_message"
+	InteractiveActionAddress = "address_message"
+	AddressCountryIndia      = "IN"
+	AddressCountrySingapore  = "SG"
+
+	// MaxAddressSavedAddresses is the maximum number of saved addresses of an address message.
+	MaxAddressSavedAddresses = 3
+)
+
+// Address fields name the fields of AddressValues, they key the validation errors.
+const (
+	AddressFieldName         = "name"
+	AddressFieldPhoneNumber  = "phone_number"
+	AddressFieldINPinCode    = "in_pin_code"
+	AddressFieldSGPostCode   = "sg_post_code"
+	AddressFieldHouseNumber  = "house_number"
+	AddressFieldFloorNumber  = "floor_number"
+	AddressFieldTowerNumber  = "tower_number"
+	AddressFieldBuildingName = "building_name"
+	AddressFieldAddress      = "address"
+	AddressFieldLandmarkArea = "landmark_area"
+	AddressFieldUnitNumber   = "unit_number"
+	AddressFieldCity         = "city"
+	AddressFieldState        = "state"
+)
+
+var (
+	ErrInvalidAddressMessage = errors.New("invalid address message")
+	ErrAddressCountry        = errors.New("address country must be IN or SG")
+	ErrAddressBody           = errors.New("address message body is required and at most 1024 characters")
+	ErrAddressSavedAddresses = errors.New("at most 3 saved addresses with unique ids are allowed")
+)
+
+type (
+	// AddressValues are the fields of an address message. India uses InPinCode and
+	// Singapore uses SgPostCode and UnitNumber, the rest are shared.
+	AddressValues struct {
+		Name         string `json:"name,omitempty"`
+		PhoneNumber  string `json:"phone_number,omitempty"`
+		InPinCode    string `json:"in_pin_code,omitempty"`
+		SgPostCode   string `json:"sg_post_code,omitempty"`
+		HouseNumber  string `json:"house_number,omitempty"`
+		FloorNumber  string `json:"floor_number,omitempty"`
+		TowerNumber  string `json:"tower_number,omitempty"`
+		BuildingName string `json:"building_name,omitempty"`
+		Address      string `json:"address,omitempty"`
+		LandmarkArea string `json:"landmark_area,omitempty"`
+		UnitNumber   string `json:"unit_number,omitempty"`
+		City         string `json:"city,omitempty"`
+		State        string `json:"state,omitempty"`
+	}
+
+	// SavedAddress is an address the user can pick instead of filling in the form.
+	SavedAddress struct {
+		ID    string         `json:"id"`
+		Value *AddressValues `json:"value"`
+	}
+
+	// InteractiveAddressRequest describes an address message. Values prefill the form
+	// and ValidationErrors, keyed by the AddressField constants, are shown next to the
+	// prefilled fields, for example when asking the user to correct an address they
+	// submitted. SavedAddresses let the user choose an address they used before.
+	InteractiveAddressRequest struct {
+		Body             string
+		Header           *InteractiveHeader
+		Footer           string
+		Country          string
+		Values           *AddressValues
+		SavedAddresses   []*SavedAddress
+		ValidationErrors map[string]string
+	}
+)
+
+// NewInteractiveAddressMessage returns a message that asks recipient for a delivery
+// address. Address messages are only available in India and Singapore. The request is
+// validated with ValidateInteractiveAddress.
+func NewInteractiveAddressMessage(recipient string, req *InteractiveAddressRequest) (*Message, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: nil request", ErrInvalidAddressMessage)
+	}
+
+	if err := ValidateInteractiveAddress(req); err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithInteractiveAddress(req))
+}
+
+// ValidateInteractiveAddress checks an address message request. Errors wrap
+// ErrInvalidAddressMessage and the error of the failed check.
+func ValidateInteractiveAddress(req *InteractiveAddressRequest) error {
+	if err := validateInteractiveAddress(req); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAddressMessage, err)
+	}
+
+	return nil
+}
+
+func validateInteractiveAddress(req *InteractiveAddressRequest) error {
+	switch req.Country {
+	case AddressCountryIndia, AddressCountrySingapore:
+	default:
+		return fmt.Errorf("%w: %q", ErrAddressCountry, req.Country)
+	}
+
+	if req.Body == "" || utf8.RuneCountInString(req.Body) > MaxInteractiveBodyLength {
+		return ErrAddressBody
+	}
+
+	if len(req.SavedAddresses) > MaxAddressSavedAddresses {
+		return ErrAddressSavedAddresses
+	}
+
+	seen := make(map[string]struct{}, len(req.SavedAddresses))
+	for _, saved := range req.SavedAddresses {
+		if saved == nil || saved.ID == "" || saved.Value == nil {
+			return fmt.Errorf("%w: saved address needs an id and a value", ErrAddressSavedAddresses)
+		}
+
+		if _, ok := seen[saved.ID]; ok {
+			return fmt.Errorf("%w: duplicate id %q", ErrAddressSavedAddresses, saved.ID)
+		}
+
+		seen[saved.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// WithInteractiveAddress sets the message content to the address message described by req.
+func WithInteractiveAddress(req *InteractiveAddressRequest) Option {
+	return func(message *Message) {
+		options := []InteractiveOption{
+			WithInteractiveHeader(req.Header),
+			WithInteractiveBody(req.Body),
+			WithInteractiveAction(&InteractiveAction{
+				Name: InteractiveActionAddress,
+				Parameters: &InteractiveActionParameters{
+					Country:          req.Country,
+					Values:           req.Values,
+					SavedAddresses:   req.SavedAddresses,
+					ValidationErrors: req.ValidationErrors,
+				},
+			}),
+		}
+
+		if req.Footer != "" {
+			options = append(options, WithInteractiveFooter(req.Footer))
+		}
+
+		message.Type = TypeInteractive
+		message.Interactive = NewInteractiveMessageContent(TypeInteractiveAddress, options...)
+	}
+}
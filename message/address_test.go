@@ -0,0 +1,97 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestNewInteractiveAddressMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.NewInteractiveAddressMessage("919800000000", &message.InteractiveAddressRequest{
+		Body:    "Where should we deliver?",
+		Country: message.AddressCountryIndia,
+		Values: &message.AddressValues{
+			Name:      "Asha",
+			InPinCode: "40006",
+		},
+		ValidationErrors: map[string]string{message.AddressFieldINPinCode: "Enter a 6 digit pin code"},
+	})
+	if err != nil {
+		t.Fatalf("NewInteractiveAddressMessage() error = %v", err)
+	}
+
+	got, err := json.Marshal(msg.Interactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"address_message","action":{"name":"address_message","parameters":{"country":"IN","values":{"name":"Asha","in_pin_code":"40006"},"validation_errors":{"in_pin_code":"Enter a 6 digit pin code"}}},"body":{"text":"Where should we deliver?"}}`
+	if diff := gcmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("interactive mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateInteractiveAddress(t *testing.T) {
+	t.Parallel()
+
+	saved := func(id string) *message.SavedAddress {
+		return &message.SavedAddress{ID: id, Value: &message.AddressValues{SgPostCode: "018956"}}
+	}
+
+	tests := []struct {
+		name string
+		req  *message.InteractiveAddressRequest
+		want error
+	}{
+		{
+			name: "saved addresses",
+			req: &message.InteractiveAddressRequest{
+				Body: "Pick an address", Country: message.AddressCountrySingapore,
+				SavedAddresses: []*message.SavedAddress{saved("home"), saved("work")},
+			},
+		},
+		{
+			name: "unsupported country",
+			req:  &message.InteractiveAddressRequest{Body: "Address", Country: "KE"},
+			want: message.ErrAddressCountry,
+		},
+		{
+			name: "missing body",
+			req:  &message.InteractiveAddressRequest{Country: message.AddressCountryIndia},
+			want: message.ErrAddressBody,
+		},
+		{
+			name: "duplicate saved address",
+			req: &message.InteractiveAddressRequest{
+				Body: "Address", Country: message.AddressCountrySingapore,
+				SavedAddresses: []*message.SavedAddress{saved("home"), saved("home")},
+			},
+			want: message.ErrAddressSavedAddresses,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := message.ValidateInteractiveAddress(tt.req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateInteractiveAddress() error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.want) || !errors.Is(err, message.ErrInvalidAddressMessage) {
+				t.Errorf("ValidateInteractiveAddress() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is the number of messages SendBatch sends at the same time unless
+// configured otherwise.
+const DefaultBatchConcurrency = 10
+
+var ErrBatchNoMessage = errors.New("batch builder returned no message")
+
+type (
+	// BatchBuilder builds the message sent to recipient. The recipient is set on messages
+	// that do not have one.
+	BatchBuilder func(recipient string) *Message
+
+	// BatchResult is the outcome of a recipient of a batch. Response is nil for dry runs and
+	// failed sends. Skipped is set for recipients the batch did not get to because the
+	// context was done, Err is then the context error.
+	BatchResult struct {
+		Recipient string
+		Message   *Message
+		Response  *Response
+		Err       error
+		Skipped   bool
+		Duration  time.Duration
+	}
+
+	// BatchReport aggregates the results of a batch. Results are in the order of the
+	// recipients. For dry runs Sent counts the messages that would have been sent.
+	BatchReport struct {
+		DryRun     bool
+		Results    []*BatchResult
+		Sent       int
+		Failed     int
+		Skipped    int
+		StartedAt  time.Time
+		FinishedAt time.Time
+	}
+
+	BatchOptions struct {
+		Concurrency int
+		DryRun      bool
+		OnResult    func(ctx context.Context, result *BatchResult)
+	}
+
+	BatchOption func(*BatchOptions)
+)
+
+// WithBatchConcurrency sets the number of messages sent at the same time. The Cloud API
+// throttles phone numbers sending too fast, see Queue for paced sending.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(options *BatchOptions) {
+		options.Concurrency = n
+	}
+}
+
+// WithBatchDryRun builds and checks the messages without sending them.
+func WithBatchDryRun() BatchOption {
+	return func(options *BatchOptions) {
+		options.DryRun = true
+	}
+}
+
+// WithBatchResultHandler sets a function called with every result as soon as it is known,
+// from the goroutine that produced it.
+func WithBatchResultHandler(fn func(ctx context.Context, result *BatchResult)) BatchOption {
+	return func(options *BatchOptions) {
+		options.OnResult = fn
+	}
+}
+
+// Failures returns the results of the recipients whose message failed or was skipped.
+func (r *BatchReport) Failures() []*BatchResult {
+	var failures []*BatchResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// SendBatch sends the message built by builder to every recipient with sender and reports
+// the outcome of each. A failed recipient does not stop the batch, the returned error is
+// only set when ctx is done before every recipient was handled.
+func SendBatch(ctx context.Context, sender MessageSender, recipients []string, builder BatchBuilder,
+	options ...BatchOption,
+) (*BatchReport, error) {
+	opts := &BatchOptions{Concurrency: DefaultBatchConcurrency}
+	for _, option := range options {
+		if option != nil {
+			option(opts)
+		}
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	report := &BatchReport{
+		DryRun:    opts.DryRun,
+		Results:   make([]*BatchResult, len(recipients)),
+		StartedAt: time.Now(),
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, opts.Concurrency)
+	)
+
+	for i, recipient := range recipients {
+		if err := acquire(ctx, sem); err != nil {
+			report.Results[i] = &BatchResult{Recipient: recipient, Err: err, Skipped: true}
+
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, recipient string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := sendBatchMessage(ctx, sender, recipient, builder, opts.DryRun)
+			report.Results[i] = result
+			if opts.OnResult != nil {
+				opts.OnResult(ctx, result)
+			}
+		}(i, recipient)
+	}
+
+	wg.Wait()
+	report.FinishedAt = time.Now()
+
+	for _, result := range report.Results {
+		switch {
+		case result.Skipped:
+			report.Skipped++
+		case result.Err != nil:
+			report.Failed++
+		default:
+			report.Sent++
+		}
+	}
+
+	if report.Skipped > 0 {
+		return report, fmt.Errorf("send batch: %d of %d recipients skipped: %w", report.Skipped,
+			len(recipients), ctx.Err())
+	}
+
+	return report, nil
+}
+
+// acquire takes a slot of sem, it fails once ctx is done even when a slot is free.
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case sem <- struct{}{}:
+		return nil
+	}
+}
+
+func sendBatchMessage(ctx context.Context, sender MessageSender, recipient string, builder BatchBuilder,
+	dryRun bool,
+) *BatchResult {
+	result := &BatchResult{Recipient: recipient}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	message := builder(recipient)
+	if message == nil {
+		result.Err = ErrBatchNoMessage
+
+		return result
+	}
+
+	if message.To == "" {
+		message.To = recipient
+	}
+	result.Message = message
+
+	if dryRun {
+		result.Err = validateMessageContext(message)
+
+		return result
+	}
+
+	result.Response, result.Err = sender.SendMessage(ctx, message)
+
+	return result
+}
+
+// SendBatch sends the message built by builder to every recipient, see SendBatch.
+func (c *BaseClient) SendBatch(ctx context.Context, recipients []string, builder BatchBuilder,
+	options ...BatchOption,
+) (*BatchReport, error) {
+	return SendBatch(ctx, c, recipients, builder, options...)
+}
+
+// SendBatch sends the message built by builder to every recipient, see SendBatch.
+func (c *Client) SendBatch(ctx context.Context, recipients []string, builder BatchBuilder,
+	options ...BatchOption,
+) (*BatchReport, error) {
+	return SendBatch(ctx, c, recipients, builder, options...)
+}
@@ -0,0 +1,89 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+)
+
+func TestSendBatch(t *testing.T) {
+	t.Parallel()
+
+	sender := &queueSender{
+		calls:   map[string]int{},
+		failing: map[string]error{"invalid": &werrors.Error{Code: 131030}},
+	}
+
+	var reported atomic.Int32
+	recipients := []string{"a", "invalid", "b", "skip", "c"}
+	report, err := message.SendBatch(context.Background(), sender, recipients,
+		func(recipient string) *message.Message {
+			if recipient == "skip" {
+				return nil
+			}
+
+			return &message.Message{Type: message.TypeText, Text: &message.Text{Body: "hello " + recipient}}
+		},
+		message.WithBatchConcurrency(2),
+		message.WithBatchResultHandler(func(context.Context, *message.BatchResult) { reported.Add(1) }),
+	)
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	if report.Sent != 3 || report.Failed != 2 || report.Skipped != 0 || reported.Load() != 5 {
+		t.Errorf("report = sent %d failed %d skipped %d, reported %d", report.Sent, report.Failed,
+			report.Skipped, reported.Load())
+	}
+
+	for i, result := range report.Results {
+		if result.Recipient != recipients[i] {
+			t.Errorf("result %d is for %q, want %q", i, result.Recipient, recipients[i])
+		}
+	}
+
+	if report.Results[0].Response.Messages[0].ID != "wamid.a" || report.Results[0].Message.To != "a" {
+		t.Errorf("unexpected result for a: %+v", report.Results[0])
+	}
+
+	if !errors.Is(report.Results[3].Err, message.ErrBatchNoMessage) {
+		t.Errorf("skip error = %v", report.Results[3].Err)
+	}
+
+	if len(report.Failures()) != 2 {
+		t.Errorf("Failures() = %d", len(report.Failures()))
+	}
+}
+
+func TestSendBatch_DryRun(t *testing.T) {
+	t.Parallel()
+
+	sender := &queueSender{calls: map[string]int{}}
+	report, err := message.SendBatch(context.Background(), sender, []string{"a", "b"},
+		func(string) *message.Message { return &message.Message{Type: message.TypeText} },
+		message.WithBatchDryRun())
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	if !report.DryRun || report.Sent != 2 || len(sender.calls) != 0 {
+		t.Errorf("dry run sent %d messages, report %+v", len(sender.calls), report)
+	}
+}
+
+func TestSendBatch_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := message.SendBatch(ctx, &queueSender{calls: map[string]int{}}, []string{"a", "b"},
+		func(string) *message.Message { return &message.Message{} })
+	if !errors.Is(err, context.Canceled) || report.Skipped != 2 {
+		t.Fatalf("SendBatch() = %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/pkg/types"
+)
+
+var (
+	ErrBuildMessage       = errors.New("failed to build message")
+	ErrMissingRecipient   = errors.New("recipient is required")
+	ErrMissingContent     = errors.New("message content is required")
+	ErrConflictingContent = errors.New("message has more than one content type")
+)
+
+// Builder builds a Message with chained calls instead of New and its options:
+//
+//	msg, err := message.NewBuilder().
+//		To("255700000000").
+//		Text("Your order has shipped").
+//		ReplyTo("wamid.HBgM...").
+//		Build()
+//
+// The content methods (Text, Image, Location, Interactive, Template...) are mutually
+// exclusive, calling two of them is reported by Build. Option values from this package
+// can still be applied with With.
+type Builder struct {
+	recipient string
+	group     bool
+	contents  []string
+	options   []Option
+	replyTo   string
+	metadata  types.Metadata
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// To sets the recipient phone number or WhatsApp ID.
+func (b *Builder) To(recipient string) *Builder {
+	b.recipient = recipient
+	b.group = false
+
+	return b
+}
+
+// ToGroup sends the message to the group with the given ID.
+func (b *Builder) ToGroup(groupID string) *Builder {
+	b.recipient = groupID
+	b.group = true
+
+	return b
+}
+
+// ReplyTo sends the message as a reply to the message with the given ID.
+func (b *Builder) ReplyTo(messageID string) *Builder {
+	b.replyTo = messageID
+
+	return b
+}
+
+// WithMetadata sets the metadata of the request built by BuildRequest.
+func (b *Builder) WithMetadata(metadata types.Metadata) *Builder {
+	b.metadata = metadata
+
+	return b
+}
+
+// With applies options after the content, for settings the builder has no method for.
+func (b *Builder) With(options ...Option) *Builder {
+	b.options = append(b.options, options...)
+
+	return b
+}
+
+func (b *Builder) content(messageType string, option Option) *Builder {
+	b.contents = append(b.contents, messageType)
+	b.options = append([]Option{option}, b.options...)
+
+	return b
+}
+
+func (b *Builder) Text(body string) *Builder {
+	return b.content(TypeText, WithTextMessage(&Text{Body: body}))
+}
+
+// TextWithPreview sends a text message that renders a preview of the first URL in body.
+func (b *Builder) TextWithPreview(body string) *Builder {
+	return b.content(TypeText, WithTextMessage(&Text{Body: body, PreviewURL: true}))
+}
+
+func (b *Builder) Image(image *Image) *Builder {
+	return b.content(TypeImage, WithImage(image))
+}
+
+func (b *Builder) Video(video *Video) *Builder {
+	return b.content(TypeVideo, WithVideo(video))
+}
+
+func (b *Builder) Audio(audio *Audio) *Builder {
+	return b.content(TypeAudio, WithAudio(audio))
+}
+
+func (b *Builder) Document(document *Document) *Builder {
+	return b.content(TypeDocument, WithDocument(document))
+}
+
+func (b *Builder) Sticker(sticker *Sticker) *Builder {
+	return b.content(TypeSticker, WithSticker(sticker))
+}
+
+func (b *Builder) Location(location *Location) *Builder {
+	return b.content(TypeLocation, WithLocationMessage(location))
+}
+
+func (b *Builder) Contacts(contacts Contacts) *Builder {
+	return b.content(TypeContacts, WithContacts(&contacts))
+}
+
+// Reaction reacts with emoji to the message with the given ID, an empty emoji removes
+// the reaction.
+func (b *Builder) Reaction(messageID, emoji string) *Builder {
+	return b.content(TypeReaction, WithReaction(&Reaction{MessageID: messageID, Emoji: emoji}))
+}
+
+func (b *Builder) Interactive(interactive *Interactive) *Builder {
+	return b.content(TypeInteractive, WithInteractiveMessage(interactive))
+}
+
+func (b *Builder) Template(tmpl *Template) *Builder {
+	return b.content(TypeTemplate, WithTemplateMessage(tmpl))
+}
+
+// Build returns the message. It fails when the recipient is missing, when there is not
+// exactly one content type, or when the reply to message ID is not valid. Errors wrap
+// ErrBuildMessage.
+func (b *Builder) Build() (*Message, error) {
+	msg, err := b.build()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBuildMessage, err)
+	}
+
+	return msg, nil
+}
+
+// BuildRequest builds the message and wraps it in a BaseRequest with the metadata set by
+// WithMetadata.
+func (b *Builder) BuildRequest(options ...BaseRequestOption) (*BaseRequest, error) {
+	msg, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if b.metadata != nil {
+		options = append([]BaseRequestOption{WithBaseRequestMetadata(b.metadata)}, options...)
+	}
+
+	return NewBaseRequest(msg, options...), nil
+}
+
+func (b *Builder) build() (*Message, error) {
+	if strings.TrimSpace(b.recipient) == "" {
+		return nil, ErrMissingRecipient
+	}
+
+	if len(b.contents) > 1 {
+		return nil, fmt.Errorf("%w: %s", ErrConflictingContent, strings.Join(b.contents, ", "))
+	}
+
+	options := slices.Clone(b.options)
+	if b.replyTo != "" {
+		if err := ValidateReplyTo(b.replyTo); err != nil {
+			return nil, err
+		}
+
+		options = append(options, ReplyTo(b.replyTo))
+	}
+
+	newMessage := New
+	if b.group {
+		newMessage = NewGroupMessage
+	}
+
+	msg, err := newMessage(b.recipient, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case msg.Type == "":
+		return nil, ErrMissingContent
+	case len(b.contents) == 1 && msg.Type != b.contents[0]:
+		return nil, fmt.Errorf("%w: %s, %s", ErrConflictingContent, b.contents[0], msg.Type)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,95 @@
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/pkg/types"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	got, err := message.NewBuilder().
+		To("255700000000").
+		TextWithPreview("https://example.com").
+		ReplyTo("wamid.HBgM").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want, _ := message.New("255700000000",
+		message.WithTextMessage(&message.Text{Body: "https://example.com", PreviewURL: true}),
+		message.ReplyTo("wamid.HBgM"),
+	)
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("message mismatch (-want +got):\n%s", diff)
+	}
+
+	request, err := message.NewBuilder().
+		ToGroup("group-1").
+		Image(&message.Image{ID: "media-1"}).
+		WithMetadata(types.Metadata{"campaign": "spring"}).
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	if request.Message.RecipientType != message.RecipientTypeGroup || request.Message.Type != message.TypeImage ||
+		request.Metadata["campaign"] != "spring" {
+		t.Errorf("unexpected request: %+v", request)
+	}
+}
+
+func TestBuilder_BuildErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		builder *message.Builder
+		want    error
+	}{
+		{
+			name:    "missing recipient",
+			builder: message.NewBuilder().Text("hi"),
+			want:    message.ErrMissingRecipient,
+		},
+		{
+			name:    "missing content",
+			builder: message.NewBuilder().To("255700000000"),
+			want:    message.ErrMissingContent,
+		},
+		{
+			name:    "two contents",
+			builder: message.NewBuilder().To("255700000000").Text("hi").Location(&message.Location{}),
+			want:    message.ErrConflictingContent,
+		},
+		{
+			name: "option overrides content",
+			builder: message.NewBuilder().To("255700000000").Text("hi").
+				With(message.WithImage(&message.Image{ID: "media-1"})),
+			want: message.ErrConflictingContent,
+		},
+		{
+			name:    "invalid reply to",
+			builder: message.NewBuilder().To("255700000000").Text("hi").ReplyTo("123"),
+			want:    message.ErrInvalidReplyTo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.builder.Build()
+			if !errors.Is(err, tt.want) || !errors.Is(err, message.ErrBuildMessage) {
+				t.Errorf("Build() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import "errors"
+
+const (
+	TypeInteractiveProduct          = "product"
+	TypeInteractiveProductList      = "product_list"
+	TypeInteractiveCatalogMessage   = "catalog_message"
+	InteractiveActionCatalogMessage = "catalog_message"
+)
+
+const (
+	// MaxProductListSections is the maximum number of sections in a multi-product message.
+	MaxProductListSections = 10
+
+	// MaxProductListItems is the maximum number of products across all the sections of a
+	// multi-product message.
+	MaxProductListItems = 30
+)
+
+type (
+	// InteractiveProductRequest describes a single product message. Body and Footer are
+	// optional, the product header is taken from the catalog.
+	InteractiveProductRequest struct {
+		CatalogID         string
+		ProductRetailerID string
+		Body              string
+		Footer            string
+	}
+
+	// InteractiveProductListRequest describes a multi-product message. Header must be a text
+	// header and Body is required.
+	InteractiveProductListRequest struct {
+		CatalogID string
+		Header    *InteractiveHeader
+		Body      string
+		Footer    string
+		Sections  []*InteractiveSection
+	}
+
+	// InteractiveCatalogRequest describes a catalog message that opens the business catalog.
+	// ThumbnailProductRetailerID selects the product shown as the message thumbnail, the
+	// first item in the catalog is used when it is empty.
+	InteractiveCatalogRequest struct {
+		Body                       string
+		Footer                     string
+		ThumbnailProductRetailerID string
+	}
+)
+
+// NewProductSection returns a product_list section listing the products with the given
+// retailer ids.
+func NewProductSection(title string, retailerIDs ...string) *InteractiveSection {
+	items := make([]*Product, 0, len(retailerIDs))
+	for _, id := range retailerIDs {
+		items = append(items, &Product{RetailerID: id})
+	}
+
+	return &InteractiveSection{
+		Title:        title,
+		ProductItems: items,
+	}
+}
+
+func NewInteractiveProduct(request *InteractiveProductRequest) *Interactive {
+	options := []InteractiveOption{
+		WithInteractiveAction(&InteractiveAction{
+			CatalogID:         request.CatalogID,
+			ProductRetailerID: request.ProductRetailerID,
+		}),
+	}
+
+	if request.Body != "" {
+		options = append(options, WithInteractiveBody(request.Body))
+	}
+
+	if request.Footer != "" {
+		options = append(options, WithInteractiveFooter(request.Footer))
+	}
+
+	return NewInteractiveMessageContent(TypeInteractiveProduct, options...)
+}
+
+func NewInteractiveProductList(request *InteractiveProductListRequest) *Interactive {
+	options := []InteractiveOption{
+		WithInteractiveHeader(request.Header),
+		WithInteractiveBody(request.Body),
+		WithInteractiveAction(&InteractiveAction{
+			CatalogID: request.CatalogID,
+			Sections:  request.Sections,
+		}),
+	}
+
+	if request.Footer != "" {
+		options = append(options, WithInteractiveFooter(request.Footer))
+	}
+
+	return NewInteractiveMessageContent(TypeInteractiveProductList, options...)
+}
+
+func NewInteractiveCatalog(request *InteractiveCatalogRequest) *Interactive {
+	action := &InteractiveAction{Name: InteractiveActionCatalogMessage}
+	if request.ThumbnailProductRetailerID != "" {
+		action.Parameters = &InteractiveActionParameters{
+			ThumbnailProductRetailerID: request.ThumbnailProductRetailerID,
+		}
+	}
+
+	options := []InteractiveOption{
+		WithInteractiveBody(request.Body),
+		WithInteractiveAction(action),
+	}
+
+	if request.Footer != "" {
+		options = append(options, WithInteractiveFooter(request.Footer))
+	}
+
+	return NewInteractiveMessageContent(TypeInteractiveCatalogMessage, options...)
+}
+
+func WithInteractiveProduct(request *InteractiveProductRequest) Option {
+	return WithInteractiveMessage(NewInteractiveProduct(request))
+}
+
+func WithInteractiveProductList(request *InteractiveProductListRequest) Option {
+	return WithInteractiveMessage(NewInteractiveProductList(request))
+}
+
+func WithInteractiveCatalog(request *InteractiveCatalogRequest) Option {
+	return WithInteractiveMessage(NewInteractiveCatalog(request))
+}
+
+var (
+	ErrMissingCatalogID    = errors.New("catalog id is required")
+	ErrProductListContent  = errors.New("product list requires a text header and a body")
+	ErrProductListSections = errors.New("product list requires 1 to 10 sections, titled when more than one")
+	ErrProductListItems    = errors.New("product list requires 1 to 30 products")
+)
+
+// ValidateProductList checks the section and product limits of a multi-product message.
+func ValidateProductList(request *InteractiveProductListRequest) error {
+	if request.CatalogID == "" {
+		return ErrMissingCatalogID
+	}
+
+	if request.Header == nil || request.Header.Text == "" || request.Body == "" {
+		return ErrProductListContent
+	}
+
+	if len(request.Sections) == 0 || len(request.Sections) > MaxProductListSections {
+		return ErrProductListSections
+	}
+
+	items := 0
+	for _, section := range request.Sections {
+		if len(request.Sections) > 1 && section.Title == "" {
+			return ErrProductListSections
+		}
+		items += len(section.ProductItems)
+	}
+
+	if items == 0 || items > MaxProductListItems {
+		return ErrProductListItems
+	}
+
+	return nil
+}
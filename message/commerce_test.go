@@ -0,0 +1,128 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestCommerceMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option message.Option
+		want   string
+	}{
+		{
+			name: "single product",
+			option: message.WithInteractiveProduct(&message.InteractiveProductRequest{
+				CatalogID:         "cat",
+				ProductRetailerID: "sku-1",
+				Body:              "Check this out",
+			}),
+			want: `{"type":"product","action":{"catalog_id":"cat","product_retailer_id":"sku-1"},"body":{"text":"Check this out"}}`,
+		},
+		{
+			name: "product list",
+			option: message.WithInteractiveProductList(&message.InteractiveProductListRequest{
+				CatalogID: "cat",
+				Header:    message.InteractiveHeaderText("Menu"),
+				Body:      "Pick one",
+				Sections:  []*message.InteractiveSection{message.NewProductSection("Drinks", "sku-1", "sku-2")},
+			}),
+			want: `{"type":"product_list","action":{"catalog_id":"cat","sections":[{"title":"Drinks","product_items":[{"product_retailer_id":"sku-1"},{"product_retailer_id":"sku-2"}]}]},"body":{"text":"Pick one"},"header":{"text":"Menu","type":"text"}}`,
+		},
+		{
+			name: "catalog",
+			option: message.WithInteractiveCatalog(&message.InteractiveCatalogRequest{
+				Body:                       "Browse our catalog",
+				ThumbnailProductRetailerID: "sku-1",
+			}),
+			want: `{"type":"catalog_message","action":{"name":"catalog_message","parameters":{"thumbnail_product_retailer_id":"sku-1"}},"body":{"text":"Browse our catalog"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := message.New("255700000000", tt.option)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if msg.Type != message.TypeInteractive {
+				t.Errorf("message type = %q", msg.Type)
+			}
+
+			got, err := json.Marshal(msg.Interactive)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := gcmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("interactive mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateProductList(t *testing.T) {
+	t.Parallel()
+
+	skus := make([]string, 31)
+	for i := range skus {
+		skus[i] = "sku"
+	}
+
+	valid := func() *message.InteractiveProductListRequest {
+		return &message.InteractiveProductListRequest{
+			CatalogID: "cat",
+			Header:    message.InteractiveHeaderText("Menu"),
+			Body:      "Pick one",
+			Sections:  []*message.InteractiveSection{message.NewProductSection("", "sku-1")},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*message.InteractiveProductListRequest)
+		wantErr error
+	}{
+		{name: "valid", modify: func(*message.InteractiveProductListRequest) {}},
+		{name: "no catalog", modify: func(r *message.InteractiveProductListRequest) { r.CatalogID = "" }, wantErr: message.ErrMissingCatalogID},
+		{name: "no header", modify: func(r *message.InteractiveProductListRequest) { r.Header = nil }, wantErr: message.ErrProductListContent},
+		{
+			name: "untitled sections",
+			modify: func(r *message.InteractiveProductListRequest) {
+				r.Sections = append(r.Sections, message.NewProductSection("", "sku-2"))
+			},
+			wantErr: message.ErrProductListSections,
+		},
+		{
+			name: "too many products",
+			modify: func(r *message.InteractiveProductListRequest) {
+				r.Sections = []*message.InteractiveSection{message.NewProductSection("All", skus...)}
+			},
+			wantErr: message.ErrProductListItems,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := valid()
+			tt.modify(request)
+
+			if err := message.ValidateProductList(request); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateProductList() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+var ErrDuplicateMessage = errors.New("identical message already sent to recipient")
+
+type (
+	// Duplicate describes a message identical to one sent to the same recipient within the
+	// window of a DedupGuard. Suppressed is true when the message was not sent.
+	Duplicate struct {
+		PhoneNumberID string
+		Recipient     string
+		Hash          string
+		LastSentAt    time.Time
+		Suppressed    bool
+	}
+
+	// DedupStats counts the messages checked by a DedupGuard, the duplicates found and the
+	// duplicates that were not sent.
+	DedupStats struct {
+		Checked    uint64
+		Duplicates uint64
+		Suppressed uint64
+	}
+
+	// DedupGuard detects messages whose content was already sent to the same recipient
+	// within a window, a common symptom of campaigns that run twice or retry loops that do
+	// not record their progress. It only reports duplicates unless WithDedupSuppress is set.
+	//
+	// Content is compared by hash, only sends that succeeded are recorded and the records
+	// are kept in memory.
+	DedupGuard struct {
+		window      time.Duration
+		suppress    bool
+		now         func() time.Time
+		onDuplicate func(ctx context.Context, duplicate *Duplicate)
+
+		mu        sync.Mutex
+		sent      map[dedupKey]time.Time
+		lastPrune time.Time
+
+		checked    atomic.Uint64
+		duplicates atomic.Uint64
+		suppressed atomic.Uint64
+	}
+
+	DedupOption func(*DedupGuard)
+
+	dedupKey struct {
+		phoneNumberID string
+		recipient     string
+		hash          string
+	}
+)
+
+// WithDedupSuppress makes the guard fail duplicates with ErrDuplicateMessage instead of
+// sending them.
+func WithDedupSuppress() DedupOption {
+	return func(g *DedupGuard) {
+		g.suppress = true
+	}
+}
+
+// WithDedupHandler sets the function called for every duplicate, to log or count them.
+func WithDedupHandler(fn func(ctx context.Context, duplicate *Duplicate)) DedupOption {
+	return func(g *DedupGuard) {
+		g.onDuplicate = fn
+	}
+}
+
+func WithDedupClock(now func() time.Time) DedupOption {
+	return func(g *DedupGuard) {
+		g.now = now
+	}
+}
+
+// NewDedupGuard returns a guard treating identical messages sent to a recipient within
+// window as duplicates.
+func NewDedupGuard(window time.Duration, options ...DedupOption) *DedupGuard {
+	guard := &DedupGuard{
+		window: window,
+		now:    time.Now,
+		sent:   make(map[dedupKey]time.Time),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(guard)
+		}
+	}
+
+	return guard
+}
+
+// ContentHash returns the hash of the content of message, which leaves out the recipient.
+func ContentHash(message *Message) (string, error) {
+	content := *message
+	content.To = ""
+
+	data, err := json.Marshal(&content)
+	if err != nil {
+		return "", fmt.Errorf("encode message: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Stats returns the counters of the guard.
+func (g *DedupGuard) Stats() DedupStats {
+	return DedupStats{
+		Checked:    g.checked.Load(),
+		Duplicates: g.duplicates.Load(),
+		Suppressed: g.suppressed.Load(),
+	}
+}
+
+// Middleware checks every message sent through the client. Status updates are not checked.
+func (g *DedupGuard) Middleware() SenderMiddleware {
+	return func(next SenderFunc) SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *BaseRequest) (*Response, error) {
+			message := request.Message
+			if request.Type != whttp.RequestTypeSendMessage || message == nil || message.Status != nil {
+				return next(ctx, conf, request)
+			}
+
+			hash, err := ContentHash(message)
+			if err != nil {
+				return next(ctx, conf, request)
+			}
+
+			key := dedupKey{phoneNumberID: conf.PhoneNumberID, recipient: message.To, hash: hash}
+			if err := g.check(ctx, key); err != nil {
+				return nil, err
+			}
+
+			response, err := next(ctx, conf, request)
+			if err == nil {
+				g.record(key)
+			}
+
+			return response, err
+		}
+	}
+}
+
+func (g *DedupGuard) check(ctx context.Context, key dedupKey) error {
+	g.checked.Add(1)
+
+	g.mu.Lock()
+	lastSentAt, ok := g.sent[key]
+	g.mu.Unlock()
+
+	if !ok || g.now().Sub(lastSentAt) >= g.window {
+		return nil
+	}
+
+	g.duplicates.Add(1)
+	if g.suppress {
+		g.suppressed.Add(1)
+	}
+
+	if g.onDuplicate != nil {
+		g.onDuplicate(ctx, &Duplicate{
+			PhoneNumberID: key.phoneNumberID,
+			Recipient:     key.recipient,
+			Hash:          key.hash,
+			LastSentAt:    lastSentAt,
+			Suppressed:    g.suppress,
+		})
+	}
+
+	if g.suppress {
+		return fmt.Errorf("send message to %s: %w", key.recipient, ErrDuplicateMessage)
+	}
+
+	return nil
+}
+
+func (g *DedupGuard) record(key dedupKey) {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sent[key] = now
+
+	// expired records are dropped at most once per window.
+	if now.Sub(g.lastPrune) < g.window {
+		return
+	}
+
+	g.lastPrune = now
+	for k, sentAt := range g.sent {
+		if now.Sub(sentAt) >= g.window {
+			delete(g.sent, k)
+		}
+	}
+}
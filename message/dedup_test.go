@@ -0,0 +1,85 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestDedupGuard(t *testing.T) {
+	t.Parallel()
+
+	sent := 0
+	sender := whttp.SenderFunc[message.Message](func(context.Context, *whttp.Request[message.Message],
+		whttp.ResponseDecoder,
+	) error {
+		sent++
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{BaseURL: "https://graph.facebook.com", APIVersion: "v20.0", PhoneNumberID: "111"}, nil
+	})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var duplicates []*message.Duplicate
+	guard := message.NewDedupGuard(time.Hour,
+		message.WithDedupSuppress(),
+		message.WithDedupClock(func() time.Time { return now }),
+		message.WithDedupHandler(func(_ context.Context, duplicate *message.Duplicate) {
+			duplicates = append(duplicates, duplicate)
+		}),
+	)
+
+	client, err := message.NewBaseClient(sender, reader, guard.Middleware())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	send := func(to, body string) error {
+		_, err := client.SendText(ctx, message.NewRequest(to, &message.Text{Body: body}, ""))
+
+		return err
+	}
+
+	if err := send("255700000001", "flash sale"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := send("255700000001", "flash sale"); !errors.Is(err, message.ErrDuplicateMessage) {
+		t.Fatalf("second send error = %v, want ErrDuplicateMessage", err)
+	}
+
+	if err := send("255700000002", "flash sale"); err != nil {
+		t.Fatalf("other recipient: %v", err)
+	}
+
+	if err := send("255700000001", "new content"); err != nil {
+		t.Fatalf("other content: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if err := send("255700000001", "flash sale"); err != nil {
+		t.Fatalf("after the window: %v", err)
+	}
+
+	if sent != 4 {
+		t.Errorf("sent = %d, want 4", sent)
+	}
+
+	stats := guard.Stats()
+	if stats.Checked != 5 || stats.Duplicates != 1 || stats.Suppressed != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if len(duplicates) != 1 || duplicates[0].Recipient != "255700000001" || !duplicates[0].Suppressed {
+		t.Errorf("duplicates = %+v", duplicates)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+var _ Outbox = (*EncryptedOutbox)(nil)
+
+// EncryptedOutbox seals the Message of entries before they reach the wrapped outbox, it
+// is kept in Sealed and Message is nil. Ids and timestamps stay in clear so outboxes can
+// still order and expire entries. Each message is sealed with the id of its entry as
+// associated data.
+type EncryptedOutbox struct {
+	outbox Outbox
+	sealer *crypto.Sealer
+}
+
+func NewEncryptedOutbox(outbox Outbox, sealer *crypto.Sealer) *EncryptedOutbox {
+	return &EncryptedOutbox{outbox: outbox, sealer: sealer}
+}
+
+func (o *EncryptedOutbox) Put(ctx context.Context, entry *OutboxEntry) error {
+	sealed, err := o.sealer.SealJSON(ctx, entry.Message, entry.ID)
+	if err != nil {
+		return fmt.Errorf("seal outbox entry %s: %w", entry.ID, err)
+	}
+
+	clone := *entry
+	clone.Message = nil
+	clone.Sealed = sealed
+
+	return o.outbox.Put(ctx, &clone)
+}
+
+func (o *EncryptedOutbox) Pending(ctx context.Context, limit int) ([]*OutboxEntry, error) {
+	entries, err := o.outbox.Pending(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := make([]*OutboxEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Sealed == "" {
+			opened = append(opened, entry)
+
+			continue
+		}
+
+		clone := *entry
+		clone.Message, clone.Sealed = nil, ""
+		if err := o.sealer.OpenJSON(ctx, entry.Sealed, entry.ID, &clone.Message); err != nil {
+			return nil, fmt.Errorf("open outbox entry %s: %w", entry.ID, err)
+		}
+		opened = append(opened, &clone)
+	}
+
+	return opened, nil
+}
+
+func (o *EncryptedOutbox) Delete(ctx context.Context, id string) error {
+	return o.outbox.Delete(ctx, id)
+}
@@ -0,0 +1,67 @@
+package message_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+func TestEncryptedOutbox(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	plain := message.NewMemoryOutbox()
+	sealer := crypto.NewSealer(crypto.NewStaticKeyProvider(&crypto.Key{ID: "k1", Secret: bytes.Repeat([]byte{7}, 32)}))
+	outbox := message.NewEncryptedOutbox(plain, sealer)
+
+	entry := &message.OutboxEntry{
+		ID:            message.OutboxIDPrefix + "1",
+		PhoneNumberID: "111",
+		Message: &message.Message{
+			Product: "whatsapp",
+			To:      "255700000001",
+			Type:    "text",
+			Text:    &message.Text{Body: "your code is 4242"},
+		},
+		StoredAt: time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	if err := outbox.Put(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := plain.Pending(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != 1 || stored[0].Message != nil || stored[0].Sealed == "" {
+		t.Fatalf("entry stored in clear: %+v", stored)
+	}
+
+	pending, err := outbox.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff([]*message.OutboxEntry{entry}, pending); diff != "" {
+		t.Errorf("pending mismatch (-want +got):\n%s", diff)
+	}
+
+	swapped := *stored[0]
+	swapped.ID = message.OutboxIDPrefix + "2"
+	if err := plain.Put(ctx, &swapped); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := outbox.Pending(ctx, 10); !errors.Is(err, crypto.ErrOpen) {
+		t.Errorf("Pending() error = %v, want %v for a message moved to another entry", err, crypto.ErrOpen)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// FlowMessageVersion is the flow message version sent when the request does not set one.
+	FlowMessageVersion = "3"
+
+	// FlowActionNavigate opens the flow on the screen of the flow action payload.
+	FlowActionNavigate = "navigate"
+
+	// FlowActionDataExchange asks the flow endpoint for the first screen, it requires a flow
+	// with an endpoint and takes no payload.
+	FlowActionDataExchange = "data_exchange"
+
+	// FlowModeDraft sends a flow that is not published yet, for testing.
+	FlowModeDraft     = "draft"
+	FlowModePublished = "published"
+
+	// MaxFlowCTALength is the maximum number of characters of the flow button label.
+	MaxFlowCTALength = 30
+
+	// MaxInteractiveBodyLength is the maximum number of characters of an interactive body.
+	MaxInteractiveBodyLength = 1024
+)
+
+var (
+	ErrInvalidFlowMessage = errors.New("invalid flow message")
+	ErrFlowMessageVersion = errors.New("flow message version is required")
+	ErrFlowIdentifier     = errors.New("exactly one of flow id and flow name is required")
+	ErrFlowCTA            = errors.New("flow cta is required and at most 30 characters")
+	ErrFlowAction         = errors.New("flow action must be navigate or data_exchange")
+	ErrFlowMode           = errors.New("flow mode must be draft or published")
+	ErrFlowScreen         = errors.New("navigate flow action requires a screen")
+	ErrFlowActionPayload  = errors.New("data_exchange flow action takes no payload")
+	ErrFlowBody           = errors.New("flow message body is required and at most 1024 characters")
+)
+
+// NewInteractiveFlowMessage returns a message that sends recipient the flow described by
+// req. The flow message version defaults to FlowMessageVersion and the flow action to
+// FlowActionNavigate. The request is validated with ValidateInteractiveFlow.
+func NewInteractiveFlowMessage(recipient string, req *InteractiveFlowRequest) (*Message, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: nil request", ErrInvalidFlowMessage)
+	}
+
+	r := *req
+	if r.FlowMessageVersion == "" {
+		r.FlowMessageVersion = FlowMessageVersion
+	}
+
+	if r.FlowAction == "" {
+		r.FlowAction = FlowActionNavigate
+	}
+
+	if err := ValidateInteractiveFlow(&r); err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithInteractiveFlow(&r))
+}
+
+// ValidateInteractiveFlow checks a flow message request. Errors wrap ErrInvalidFlowMessage
+// and the error of the failed check.
+func ValidateInteractiveFlow(req *InteractiveFlowRequest) error {
+	if err := validateInteractiveFlow(req); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFlowMessage, err)
+	}
+
+	return nil
+}
+
+func validateInteractiveFlow(req *InteractiveFlowRequest) error {
+	if req.FlowMessageVersion == "" {
+		return ErrFlowMessageVersion
+	}
+
+	if (req.FlowID == "") == (req.FlowName == "") {
+		return ErrFlowIdentifier
+	}
+
+	if req.FlowCTA == "" || utf8.RuneCountInString(req.FlowCTA) > MaxFlowCTALength {
+		return ErrFlowCTA
+	}
+
+	if req.Body == "" || utf8.RuneCountInString(req.Body) > MaxInteractiveBodyLength {
+		return ErrFlowBody
+	}
+
+	switch req.Mode {
+	case "", FlowModeDraft, FlowModePublished:
+	default:
+		return fmt.Errorf("%w: %q", ErrFlowMode, req.Mode)
+	}
+
+	switch req.FlowAction {
+	case "", FlowActionNavigate:
+		if req.FlowScreen == "" {
+			return ErrFlowScreen
+		}
+	case FlowActionDataExchange:
+		if req.FlowScreen != "" || req.FlowData != nil {
+			return ErrFlowActionPayload
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrFlowAction, req.FlowAction)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestNewInteractiveFlowMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		req  *message.InteractiveFlowRequest
+		want string
+	}{
+		{
+			name: "navigate by id",
+			req: &message.InteractiveFlowRequest{
+				Body:       "Book your table",
+				FlowToken:  "token-1",
+				FlowID:     "1234",
+				FlowCTA:    "Book now",
+				FlowScreen: "WELCOME",
+				FlowData:   map[string]any{"party": 2},
+			},
+			want: `{"type":"flow","action":{"name":"flow","parameters":{"flow_message_version":"3","flow_token":"token-1","flow_id":"1234","flow_cta":"Book now","flow_action":"navigate","flow_action_payload":{"screen":"WELCOME","data":{"party":2}}}},"body":{"text":"Book your table"}}`,
+		},
+		{
+			name: "draft data exchange by name",
+			req: &message.InteractiveFlowRequest{
+				Body:       "Sign up",
+				FlowName:   "signup",
+				FlowCTA:    "Start",
+				FlowAction: message.FlowActionDataExchange,
+				Mode:       message.FlowModeDraft,
+			},
+			want: `{"type":"flow","action":{"name":"flow","parameters":{"flow_message_version":"3","flow_name":"signup","flow_cta":"Start","flow_action":"data_exchange","mode":"draft"}},"body":{"text":"Sign up"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := message.NewInteractiveFlowMessage("255700000001", tt.req)
+			if err != nil {
+				t.Fatalf("NewInteractiveFlowMessage() error = %v", err)
+			}
+
+			if msg.Type != message.TypeInteractive || msg.To != "255700000001" {
+				t.Errorf("message type = %q, to = %q", msg.Type, msg.To)
+			}
+
+			got, err := json.Marshal(msg.Interactive)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := gcmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("interactive mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateInteractiveFlow(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *message.InteractiveFlowRequest {
+		return &message.InteractiveFlowRequest{
+			Body:               "Book your table",
+			FlowMessageVersion: message.FlowMessageVersion,
+			FlowID:             "1234",
+			FlowCTA:            "Book now",
+			FlowAction:         message.FlowActionNavigate,
+			FlowScreen:         "WELCOME",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *message.InteractiveFlowRequest)
+		want   error
+	}{
+		{name: "valid", modify: func(*message.InteractiveFlowRequest) {}},
+		{name: "no version", modify: func(r *message.InteractiveFlowRequest) { r.FlowMessageVersion = "" }, want: message.ErrFlowMessageVersion},
+		{name: "id and name", modify: func(r *message.InteractiveFlowRequest) { r.FlowName = "booking" }, want: message.ErrFlowIdentifier},
+		{name: "no id nor name", modify: func(r *message.InteractiveFlowRequest) { r.FlowID = "" }, want: message.ErrFlowIdentifier},
+		{name: "long cta", modify: func(r *message.InteractiveFlowRequest) { r.FlowCTA = strings.Repeat("a", 31) }, want: message.ErrFlowCTA},
+		{name: "no body", modify: func(r *message.InteractiveFlowRequest) { r.Body = "" }, want: message.ErrFlowBody},
+		{name: "bad mode", modify: func(r *message.InteractiveFlowRequest) { r.Mode = "test" }, want: message.ErrFlowMode},
+		{name: "bad action", modify: func(r *message.InteractiveFlowRequest) { r.FlowAction = "open" }, want: message.ErrFlowAction},
+		{name: "navigate without screen", modify: func(r *message.InteractiveFlowRequest) { r.FlowScreen = "" }, want: message.ErrFlowScreen},
+		{name: "data exchange with payload", modify: func(r *message.InteractiveFlowRequest) {
+			r.FlowAction = message.FlowActionDataExchange
+		}, want: message.ErrFlowActionPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := valid()
+			tt.modify(req)
+			err := message.ValidateInteractiveFlow(req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateInteractiveFlow() error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.want) || !errors.Is(err, message.ErrInvalidFlowMessage) {
+				t.Errorf("ValidateInteractiveFlow() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,465 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const (
+	// OutboxIDPrefix prefixes the ids of stored messages, which are returned in place of a
+	// WhatsApp message id until the message is forwarded.
+	OutboxIDPrefix = "outbox."
+
+	// MessageStatusStored is the status of a message accepted by StoreAndForward while the
+	// Graph API is unreachable.
+	MessageStatusStored = "stored"
+)
+
+var ErrMessageExpired = errors.New("stored message expired before it could be forwarded")
+
+type (
+	// OutboxEntry is a message stored while the Graph API was unreachable. ExpiresAt is zero
+	// when the message never expires. Sealed holds the Message in the outbox wrapped by an
+	// EncryptedOutbox.
+	OutboxEntry struct {
+		ID            string    `json:"id"`
+		PhoneNumberID string    `json:"phone_number_id,omitempty"`
+		Message       *Message  `json:"message,omitempty"`
+		Sealed        string    `json:"sealed,omitempty"`
+		StoredAt      time.Time `json:"stored_at"`
+		ExpiresAt     time.Time `json:"expires_at,omitempty"`
+	}
+
+	// Outbox persists stored messages. Pending returns up to limit entries in the order
+	// they were put. Implementations must be safe for concurrent use and should be durable
+	// when messages have to survive a restart.
+	Outbox interface {
+		Put(ctx context.Context, entry *OutboxEntry) error
+		Pending(ctx context.Context, limit int) ([]*OutboxEntry, error)
+		Delete(ctx context.Context, id string) error
+	}
+
+	// ForwardResult is the outcome of a stored message. Expired is true when it was dropped
+	// for being older than its max age, Err is then ErrMessageExpired.
+	ForwardResult struct {
+		Entry    *OutboxEntry
+		Response *Response
+		Err      error
+		Expired  bool
+	}
+
+	ForwardResultHandler func(ctx context.Context, result *ForwardResult)
+
+	// StoreAndForward keeps a client usable while the Graph API is unreachable. Its
+	// Middleware sends messages as usual until threshold consecutive sends fail with a
+	// network error or a 5xx response. From then on, like an open circuit breaker, sends are
+	// not attempted: messages are put in the Outbox and the middleware returns a Response
+	// whose message id starts with OutboxIDPrefix and whose status is MessageStatusStored.
+	//
+	// Run probes the API every cooldown by forwarding the stored messages, oldest first and
+	// paced to the throughput. Once the outbox is drained sends go straight to the API
+	// again. New messages keep being stored while older ones are pending so the order is
+	// preserved. Messages older than their max age, like one time passwords, are dropped
+	// instead of being sent late.
+	//
+	// Status updates, such as read receipts, are never stored.
+	StoreAndForward struct {
+		outbox    Outbox
+		threshold int
+		cooldown  time.Duration
+		interval  time.Duration
+		batch     int
+		maxAge    func(message *Message) time.Duration
+		handler   ForwardResultHandler
+		now       func() time.Time
+
+		mu       sync.Mutex
+		failures int
+		offline  bool
+		draining bool
+	}
+
+	ForwardOption func(*StoreAndForward)
+
+	forwardingKey struct{}
+)
+
+// WithForwardThreshold sets the number of consecutive failed sends after which messages
+// are stored. The default is 3.
+func WithForwardThreshold(n int) ForwardOption {
+	return func(s *StoreAndForward) {
+		if n > 0 {
+			s.threshold = n
+		}
+	}
+}
+
+// WithForwardCooldown sets how often Run tries to forward stored messages. The default
+// is 30 seconds.
+func WithForwardCooldown(d time.Duration) ForwardOption {
+	return func(s *StoreAndForward) {
+		if d > 0 {
+			s.cooldown = d
+		}
+	}
+}
+
+// WithForwardThroughput sets the number of stored messages forwarded per second. The
+// default is DefaultQueueThroughput.
+func WithForwardThroughput(perSecond int) ForwardOption {
+	return func(s *StoreAndForward) {
+		if perSecond > 0 {
+			s.interval = time.Second / time.Duration(perSecond)
+		}
+	}
+}
+
+// WithForwardMaxAge drops stored messages that could not be forwarded within maxAge.
+func WithForwardMaxAge(maxAge time.Duration) ForwardOption {
+	return func(s *StoreAndForward) {
+		s.maxAge = func(*Message) time.Duration { return maxAge }
+	}
+}
+
+// WithForwardMaxAgeFunc sets the max age per message, for instance a few minutes for
+// authentication templates and none for the rest. Zero means the message never expires.
+func WithForwardMaxAgeFunc(fn func(message *Message) time.Duration) ForwardOption {
+	return func(s *StoreAndForward) {
+		s.maxAge = fn
+	}
+}
+
+// WithForwardResultHandler sets the function called with the outcome of every stored
+// message.
+func WithForwardResultHandler(handler ForwardResultHandler) ForwardOption {
+	return func(s *StoreAndForward) {
+		s.handler = handler
+	}
+}
+
+func WithForwardClock(now func() time.Time) ForwardOption {
+	return func(s *StoreAndForward) {
+		s.now = now
+	}
+}
+
+// NewStoreAndForward creates a StoreAndForward storing messages in outbox.
+func NewStoreAndForward(outbox Outbox, options ...ForwardOption) *StoreAndForward {
+	s := &StoreAndForward{
+		outbox:    outbox,
+		threshold: 3,                //nolint:mnd // default
+		cooldown:  30 * time.Second, //nolint:mnd // default
+		interval:  time.Second / DefaultQueueThroughput,
+		batch:     100, //nolint:mnd // default
+		maxAge:    func(*Message) time.Duration { return 0 },
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(s)
+		}
+	}
+
+	return s
+}
+
+// Offline reports whether messages are currently being stored.
+func (s *StoreAndForward) Offline() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.offline || s.draining
+}
+
+// Middleware stores the messages sent through the client while the Graph API is
+// unreachable.
+func (s *StoreAndForward) Middleware() SenderMiddleware {
+	return func(next SenderFunc) SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *BaseRequest) (*Response, error) {
+			message := request.Message
+			if request.Type != whttp.RequestTypeSendMessage || message == nil || message.Status != nil ||
+				ctx.Value(forwardingKey{}) != nil {
+				return next(ctx, conf, request)
+			}
+
+			if s.Offline() {
+				return s.store(ctx, conf, message)
+			}
+
+			response, err := next(ctx, conf, request)
+			if !s.observe(err) {
+				return response, err
+			}
+
+			return s.store(ctx, conf, message)
+		}
+	}
+}
+
+// observe records the outcome of a send and reports whether the message should be
+// stored, which is when it failed because the API was unreachable and the threshold was
+// reached.
+func (s *StoreAndForward) observe(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !isUnreachable(err) {
+		s.failures = 0
+
+		return false
+	}
+
+	s.failures++
+	if s.failures >= s.threshold {
+		s.offline = true
+	}
+
+	return s.offline
+}
+
+func (s *StoreAndForward) store(ctx context.Context, conf *config.Config, message *Message) (*Response, error) {
+	id, err := outboxID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entry := &OutboxEntry{ID: id, PhoneNumberID: conf.PhoneNumberID, Message: message, StoredAt: now}
+	if maxAge := s.maxAge(message); maxAge > 0 {
+		entry.ExpiresAt = now.Add(maxAge)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.outbox.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("store message to %s: %w", message.To, err)
+	}
+	s.draining = true
+
+	return &Response{
+		Product:  MessagingProduct,
+		Contacts: []*ResponseContact{{Input: message.To}},
+		Messages: []*ID{{ID: id, MessageStatus: MessageStatusStored}},
+	}, nil
+}
+
+// Run forwards the stored messages with sender every cooldown until ctx is done, starting
+// with the ones left by a previous run. The sender is usually the client the Middleware is
+// installed on, the middleware lets the forwarded messages through. It returns ctx.Err().
+//
+//	saf := message.NewStoreAndForward(outbox, message.WithForwardMaxAge(10*time.Minute))
+//	client, err := message.NewBaseClient(sender, reader, saf.Middleware())
+//	...
+//	go saf.Run(ctx, client)
+func (s *StoreAndForward) Run(ctx context.Context, sender MessageSender) error {
+	ticker := time.NewTicker(s.cooldown)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Flush(ctx, sender); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush forwards the stored messages in order. It stops at the first message that fails
+// because the API is still unreachable and returns that error, messages rejected by the
+// API are reported and dropped.
+func (s *StoreAndForward) Flush(ctx context.Context, sender MessageSender) error {
+	pace := time.NewTicker(s.interval)
+	defer pace.Stop()
+
+	forwardCtx := context.WithValue(ctx, forwardingKey{}, true)
+
+	for {
+		entries, err := s.pending(ctx)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := s.forward(forwardCtx, sender, pace.C, entry); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// pending returns the next batch of stored messages. Sends go straight to the API again
+// once it is empty.
+func (s *StoreAndForward) pending(ctx context.Context) ([]*OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.outbox.Pending(ctx, s.batch)
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+
+	if len(entries) == 0 {
+		s.draining = false
+	}
+
+	return entries, nil
+}
+
+func (s *StoreAndForward) forward(ctx context.Context, sender MessageSender, pace <-chan time.Time,
+	entry *OutboxEntry,
+) error {
+	result := &ForwardResult{Entry: entry}
+
+	if !entry.ExpiresAt.IsZero() && !s.now().Before(entry.ExpiresAt) {
+		result.Expired = true
+		result.Err = fmt.Errorf("message %s: %w", entry.ID, ErrMessageExpired)
+	} else {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pace:
+		}
+
+		result.Response, result.Err = sender.SendMessage(ctx, entry.Message)
+		if s.probe(result.Err) {
+			return result.Err
+		}
+	}
+
+	if err := s.outbox.Delete(ctx, entry.ID); err != nil {
+		return fmt.Errorf("delete message %s from outbox: %w", entry.ID, err)
+	}
+
+	if s.handler != nil {
+		s.handler(ctx, result)
+	}
+
+	return nil
+}
+
+// probe records the outcome of forwarding a stored message, which decides alone whether
+// the API is back. It reports whether the API is still unreachable.
+func (s *StoreAndForward) probe(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offline = isUnreachable(err)
+	s.failures = 0
+	if s.offline {
+		s.failures = s.threshold
+	}
+
+	return s.offline
+}
+
+// isUnreachable reports whether err means the Graph API could not be reached or failed on
+// its side, as opposed to rejecting the message.
+func isUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var respErr *whttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.Code >= http.StatusInternalServerError
+	}
+
+	return errors.Is(err, whttp.ErrRequestFailure)
+}
+
+func outboxID() (string, error) {
+	b := make([]byte, 16) //nolint:mnd // 128 bits
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate outbox id: %w", err)
+	}
+
+	return OutboxIDPrefix + hex.EncodeToString(b), nil
+}
+
+// MemoryOutbox is an Outbox kept in memory, stored messages are lost on restart.
+type MemoryOutbox struct {
+	mu      sync.Mutex
+	entries []*OutboxEntry
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (m *MemoryOutbox) Put(_ context.Context, entry *OutboxEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = append(m.entries, entry)
+
+	return nil
+}
+
+func (m *MemoryOutbox) Pending(_ context.Context, limit int) ([]*OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.entries[:min(limit, len(m.entries))]), nil
+}
+
+func (m *MemoryOutbox) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = slices.DeleteFunc(m.entries, func(entry *OutboxEntry) bool {
+		return entry.ID == id
+	})
+
+	return nil
+}
+
+// Len returns the number of stored messages.
+func (m *MemoryOutbox) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+var _ Outbox = (*MemoryOutbox)(nil)
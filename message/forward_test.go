@@ -0,0 +1,113 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestStoreAndForward(t *testing.T) {
+	t.Parallel()
+
+	online := true
+	var delivered []string
+	sender := whttp.SenderFunc[message.Message](func(_ context.Context, request *whttp.Request[message.Message],
+		_ whttp.ResponseDecoder,
+	) error {
+		if !online {
+			return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}
+		delivered = append(delivered, request.Message.Text.Body)
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{BaseURL: "https://graph.facebook.com", APIVersion: "v20.0", PhoneNumberID: "111"}, nil
+	})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	outbox := message.NewMemoryOutbox()
+	var results []*message.ForwardResult
+	saf := message.NewStoreAndForward(outbox,
+		message.WithForwardThreshold(2),
+		message.WithForwardThroughput(1000),
+		message.WithForwardClock(func() time.Time { return now }),
+		message.WithForwardMaxAgeFunc(func(m *message.Message) time.Duration {
+			if strings.HasPrefix(m.Text.Body, "otp") {
+				return 5 * time.Minute
+			}
+
+			return 0
+		}),
+		message.WithForwardResultHandler(func(_ context.Context, result *message.ForwardResult) {
+			results = append(results, result)
+		}),
+	)
+
+	client, err := message.NewBaseClient(sender, reader, saf.Middleware())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	send := func(body string) (*message.Response, error) {
+		return client.SendText(ctx, message.NewRequest("255700000001", &message.Text{Body: body}, ""))
+	}
+
+	if _, err := send("before"); err != nil {
+		t.Fatal(err)
+	}
+
+	online = false
+	if _, err := send("first failure"); err == nil {
+		t.Fatal("send below the threshold should fail")
+	}
+
+	response, err := send("stored")
+	if err != nil || response.Messages[0].MessageStatus != message.MessageStatusStored ||
+		!strings.HasPrefix(response.Messages[0].ID, message.OutboxIDPrefix) {
+		t.Fatalf("send at the threshold = %+v, %v, want a stored response", response, err)
+	}
+
+	for _, body := range []string{"otp 1234", "after"} {
+		if _, err := send(body); err != nil || !saf.Offline() {
+			t.Fatalf("send %q while offline error = %v, offline = %v", body, err, saf.Offline())
+		}
+	}
+
+	if err := saf.Flush(ctx, client); err == nil || outbox.Len() != 3 {
+		t.Fatalf("Flush() while offline error = %v, outbox = %d", err, outbox.Len())
+	}
+
+	online = true
+	now = now.Add(10 * time.Minute)
+	if err := saf.Flush(ctx, client); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if diff := gcmp.Diff([]string{"before", "stored", "after"}, delivered); diff != "" {
+		t.Errorf("delivered mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(results) != 3 || !results[1].Expired || !errors.Is(results[1].Err, message.ErrMessageExpired) {
+		t.Errorf("results = %+v, want the otp to expire", results)
+	}
+
+	if saf.Offline() || outbox.Len() != 0 {
+		t.Errorf("offline = %v, outbox = %d after the flush", saf.Offline(), outbox.Len())
+	}
+
+	if _, err := send("direct"); err != nil || delivered[len(delivered)-1] != "direct" {
+		t.Errorf("send after the flush error = %v, delivered = %v", err, delivered)
+	}
+}
@@ -23,8 +23,10 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -49,15 +51,21 @@ type (
 		SendInteractiveMessage(ctx context.Context, request *Request[Interactive]) (*Response, error)
 	}
 
+	// Request is the input of the Send* methods. ReplyTo, when set, sends the message as a
+	// reply to the message with that ID. Options are applied after the message is built and
+	// can be used to set anything else, including ReplyTo.
 	Request[T any] struct {
 		Recipient string
 		ReplyTo   string
 		Message   *T
+		Options   []Option
 	}
 
 	BaseClient struct {
-		sender Sender
-		config config.Reader
+		sender      Sender
+		config      config.Reader
+		base        Sender
+		middlewares []Middleware
 	}
 
 	BaseRequest struct {
@@ -128,21 +136,18 @@ func WithBaseRequestType(reqType whttp.RequestType) BaseRequestOption {
 func NewBaseClient(sender whttp.Sender[Message], reader config.Reader,
 	middlewares ...SenderMiddleware,
 ) (*BaseClient, error) {
-	s := &BaseSender{sender}
-	sf := s.Send
-	if len(middlewares) > 0 {
-		for i := len(middlewares) - 1; i >= 0; i-- {
-			mw := middlewares[i]
-			if mw != nil {
-				sf = mw(sf)
-			}
-		}
-	}
 	c := &BaseClient{
-		sender: SenderFunc(sf),
 		config: reader,
+		base:   &BaseSender{sender},
 	}
 
+	for _, mw := range middlewares {
+		if mw != nil {
+			c.middlewares = append(c.middlewares, mw.Middleware())
+		}
+	}
+	c.sender = Chain(c.middlewares...)(c.base)
+
 	return c, nil
 }
 
@@ -151,10 +156,19 @@ func (c *BaseClient) SetConfigReader(fetcher config.Reader) {
 }
 
 func (c *BaseClient) SendMessage(ctx context.Context, message *Message) (*Response, error) {
+	if err := validateMessageContext(message); err != nil {
+		return nil, fmt.Errorf("base client: send message: %w", err)
+	}
+
+	if message.Profile != "" {
+		ctx = config.WithProfile(ctx, message.Profile)
+	}
+
 	conf, err := c.config.Read(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("base client: send message: read config: %w", err)
 	}
+	conf = config.ApplyContextOverride(ctx, conf)
 
 	req := NewBaseRequest(
 		message,
@@ -185,6 +199,10 @@ func (c *BaseClient) UpdateStatus(ctx context.Context, request *StatusUpdateRequ
 		MessageID: &request.MessageID,
 	}
 
+	if request.TypingIndicator {
+		message.TypingIndicator = &TypingIndicator{Type: TypingIndicatorText}
+	}
+
 	req := NewBaseRequest(
 		message,
 		WithBaseRequestMethod(http.MethodPut),
@@ -200,6 +218,7 @@ func (c *BaseClient) UpdateStatus(ctx context.Context, request *StatusUpdateRequ
 	if err != nil {
 		return nil, fmt.Errorf("base client: update message status: read config: %w", err)
 	}
+	conf = config.ApplyContextOverride(ctx, conf)
 
 	response, err := c.sender.Send(ctx, conf, req)
 	if err != nil {
@@ -260,6 +279,10 @@ func NewClient(ctx context.Context, reader config.Reader, sender whttp.Sender[Me
 }
 
 func (c *Client) SendMessage(ctx context.Context, message *Message) (*Response, error) {
+	if err := validateMessageContext(message); err != nil {
+		return nil, fmt.Errorf("send message: %w", err)
+	}
+
 	req := NewBaseRequest(
 		message,
 		WithBaseRequestMethod(http.MethodPost),
@@ -272,7 +295,7 @@ func (c *Client) SendMessage(ctx context.Context, message *Message) (*Response,
 		}),
 	)
 
-	response, err := c.sender.Send(ctx, c.config, req)
+	response, err := c.sender.Send(ctx, config.ApplyContextOverride(ctx, c.config), req)
 	if err != nil {
 		return nil, fmt.Errorf("send message: %w", err)
 	}
@@ -288,6 +311,10 @@ func (c *Client) UpdateStatus(ctx context.Context, request *StatusUpdateRequest)
 		MessageID: &request.MessageID,
 	}
 
+	if request.TypingIndicator {
+		message.TypingIndicator = &TypingIndicator{Type: TypingIndicatorText}
+	}
+
 	req := NewBaseRequest(
 		message,
 		WithBaseRequestMethod(http.MethodPut),
@@ -300,7 +327,7 @@ func (c *Client) UpdateStatus(ctx context.Context, request *StatusUpdateRequest)
 		}),
 	)
 
-	response, err := c.sender.Send(ctx, c.config, req)
+	response, err := c.sender.Send(ctx, config.ApplyContextOverride(ctx, c.config), req)
 	if err != nil {
 		return nil, fmt.Errorf("update message status: %w", err)
 	}
@@ -317,6 +344,8 @@ const (
 	StatusWarning   status = "warning"
 )
 
+const TypingIndicatorText = "text"
+
 type (
 	status string
 
@@ -324,9 +353,12 @@ type (
 		Success bool `json:"success"`
 	}
 
+	// StatusUpdateRequest updates the status of a received message. TypingIndicator shows
+	// the typing indicator to the user, it is only valid with StatusRead.
 	StatusUpdateRequest struct {
-		MessageID string
-		Status    status
+		MessageID       string
+		Status          status
+		TypingIndicator bool
 	}
 
 	StatusUpdater interface {
@@ -344,6 +376,8 @@ func (fn UpdateStatusFunc) UpdateStatus(ctx context.Context,
 	return fn(ctx, request)
 }
 
+var ErrInvalidReplyTo = errors.New("invalid reply to message id")
+
 var (
 	_ StatusUpdater = (*BaseClient)(nil)
 	_ StatusUpdater = (*Client)(nil)
@@ -400,22 +434,22 @@ func (c *BaseSender) Send(ctx context.Context, conf *config.Config, request *Bas
 	return response, nil
 }
 
-func NewRequest[T any](recipient string, message *T, replyTo string) *Request[T] {
-	return &Request[T]{Recipient: recipient, Message: message, ReplyTo: replyTo}
+func NewRequest[T any](recipient string, message *T, replyTo string, options ...Option) *Request[T] {
+	return &Request[T]{Recipient: recipient, Message: message, ReplyTo: replyTo, Options: options}
 }
 
-func buildOptions[T any](message *T, replyTo string, createMessageFunc func(*T) Option) []Option {
-	options := make([]Option, 1, 2)
-	options[0] = createMessageFunc(message)
-	if replyTo != "" {
-		options = append(options, WithMessageAsReplyTo(replyTo))
+func buildOptions[T any](request *Request[T], createMessageFunc func(*T) Option) []Option {
+	options := make([]Option, 1, 2+len(request.Options)) //nolint:mnd // message and reply options
+	options[0] = createMessageFunc(request.Message)
+	if request.ReplyTo != "" {
+		options = append(options, ReplyTo(request.ReplyTo))
 	}
 
-	return options
+	return append(options, request.Options...)
 }
 
 func (c *BaseClient) SendText(ctx context.Context, request *Request[Text]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithTextMessage)
+	options := buildOptions(request, WithTextMessage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -426,7 +460,7 @@ func (c *BaseClient) SendText(ctx context.Context, request *Request[Text]) (*Res
 }
 
 func (c *BaseClient) SendLocation(ctx context.Context, request *Request[Location]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithLocationMessage)
+	options := buildOptions(request, WithLocationMessage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -437,7 +471,7 @@ func (c *BaseClient) SendLocation(ctx context.Context, request *Request[Location
 }
 
 func (c *BaseClient) SendVideo(ctx context.Context, request *Request[Video]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithVideo)
+	options := buildOptions(request, WithVideo)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -448,7 +482,7 @@ func (c *BaseClient) SendVideo(ctx context.Context, request *Request[Video]) (*R
 }
 
 func (c *BaseClient) SendReaction(ctx context.Context, request *Request[Reaction]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithReaction)
+	options := buildOptions(request, WithReaction)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -459,7 +493,7 @@ func (c *BaseClient) SendReaction(ctx context.Context, request *Request[Reaction
 }
 
 func (c *BaseClient) SendTemplate(ctx context.Context, request *Request[Template]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithTemplateMessage)
+	options := buildOptions(request, WithTemplateMessage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -470,7 +504,7 @@ func (c *BaseClient) SendTemplate(ctx context.Context, request *Request[Template
 }
 
 func (c *BaseClient) SendImage(ctx context.Context, request *Request[Image]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithImage)
+	options := buildOptions(request, WithImage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -481,7 +515,7 @@ func (c *BaseClient) SendImage(ctx context.Context, request *Request[Image]) (*R
 }
 
 func (c *BaseClient) SendAudio(ctx context.Context, request *Request[Audio]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithAudio)
+	options := buildOptions(request, WithAudio)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -492,7 +526,7 @@ func (c *BaseClient) SendAudio(ctx context.Context, request *Request[Audio]) (*R
 }
 
 func (c *BaseClient) RequestLocation(ctx context.Context, request *Request[string]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithRequestLocationMessage)
+	options := buildOptions(request, WithRequestLocationMessage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -503,7 +537,7 @@ func (c *BaseClient) RequestLocation(ctx context.Context, request *Request[strin
 }
 
 func (c *BaseClient) SendDocument(ctx context.Context, request *Request[Document]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithDocument)
+	options := buildOptions(request, WithDocument)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -514,7 +548,7 @@ func (c *BaseClient) SendDocument(ctx context.Context, request *Request[Document
 }
 
 func (c *BaseClient) SendSticker(ctx context.Context, request *Request[Sticker]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithSticker)
+	options := buildOptions(request, WithSticker)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -525,7 +559,7 @@ func (c *BaseClient) SendSticker(ctx context.Context, request *Request[Sticker])
 }
 
 func (c *BaseClient) SendContacts(ctx context.Context, request *Request[Contacts]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithContacts)
+	options := buildOptions(request, WithContacts)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -536,7 +570,7 @@ func (c *BaseClient) SendContacts(ctx context.Context, request *Request[Contacts
 }
 
 func (c *BaseClient) SendInteractiveMessage(ctx context.Context, request *Request[Interactive]) (*Response, error) {
-	options := buildOptions(request.Message, request.ReplyTo, WithInteractiveMessage)
+	options := buildOptions(request, WithInteractiveMessage)
 
 	message, err := New(request.Recipient, options...)
 	if err != nil {
@@ -550,6 +584,7 @@ const (
 	Endpoint                = "/messages"
 	MessagingProduct        = "whatsapp"
 	RecipientTypeIndividual = "individual"
+	RecipientTypeGroup      = "group"
 	TypeText                = "text"
 	TypeVideo               = "video"
 	TypeAudio               = "audio"
@@ -591,7 +626,7 @@ type (
 		RecipientType string       `json:"recipient_type"`
 		Type          string       `json:"type"`
 		PreviewURL    bool         `json:"preview_url,omitempty"`
-		Context       *Context     `json:"config,omitempty"`
+		Context       *Context     `json:"context,omitempty"`
 		Text          *Text        `json:"text,omitempty"`
 		Location      *Location    `json:"location,omitempty"`
 		Reaction      *Reaction    `json:"reaction,omitempty"`
@@ -605,6 +640,17 @@ type (
 		Status        *string      `json:"status,omitempty"`     // used to update message status
 		MessageID     *string      `json:"message_id,omitempty"` // used to update message status
 		Template      *Template    `json:"template,omitempty"`
+
+		TypingIndicator *TypingIndicator `json:"typing_indicator,omitempty"` // used with status updates
+
+		// Profile names the config.Profile to send the message from, see WithProfile.
+		Profile string `json:"-"`
+	}
+
+	// TypingIndicator shows the user that a reply is being prepared. It is dismissed when
+	// the reply is sent or after 25 seconds.
+	TypingIndicator struct {
+		Type string `json:"type"`
 	}
 
 	Option func(message *Message)
@@ -648,6 +694,18 @@ func New(recipient string, options ...Option) (*Message, error) {
 	return msg, nil
 }
 
+// NewGroupMessage returns a message sent to the group with the given ID, the options are
+// those of New.
+func NewGroupMessage(groupID string, options ...Option) (*Message, error) {
+	msg, err := New(groupID, options...)
+	if err != nil {
+		return nil, err
+	}
+	msg.RecipientType = RecipientTypeGroup
+
+	return msg, nil
+}
+
 func WithImage(image *Image) Option {
 	return func(message *Message) {
 		message.Type = TypeImage
@@ -697,12 +755,50 @@ func WithReaction(reaction *Reaction) Option {
 	}
 }
 
-func WithMessageAsReplyTo(messageID string) Option {
+// ReplyTo sends the message as a reply to the message with the given ID. The ID is
+// checked when the message is sent, see ValidateReplyTo.
+func ReplyTo(messageID string) Option {
 	return func(message *Message) {
 		message.Context = &Context{MessageID: messageID}
 	}
 }
 
+// WithProfile sends the message from the phone number of the named profile. The client
+// must read its config from a config.ProfileReader, which resolves the name.
+//
+//	request := message.NewRequest(recipient, text, "", message.WithProfile("support"))
+//	resp, err := client.SendText(ctx, request)
+func WithProfile(name string) Option {
+	return func(message *Message) {
+		message.Profile = name
+	}
+}
+
+// WithMessageAsReplyTo is the same as ReplyTo.
+func WithMessageAsReplyTo(messageID string) Option {
+	return ReplyTo(messageID)
+}
+
+// ValidateReplyTo checks that messageID looks like a WhatsApp message ID (wamid).
+func ValidateReplyTo(messageID string) error {
+	id := strings.TrimSpace(messageID)
+	if len(id) <= len(wamidPrefix) || !strings.HasPrefix(id, wamidPrefix) {
+		return fmt.Errorf("%w: %q", ErrInvalidReplyTo, messageID)
+	}
+
+	return nil
+}
+
+const wamidPrefix = "wamid."
+
+func validateMessageContext(message *Message) error {
+	if message == nil || message.Context == nil {
+		return nil
+	}
+
+	return ValidateReplyTo(message.Context.MessageID)
+}
+
 func WithTextMessage(text *Text) Option {
 	return func(message *Message) {
 		message.Type = TypeText
@@ -756,11 +852,13 @@ type (
 	}
 
 	Sticker struct {
-		ID string `json:"id,omitempty"`
+		ID   string `json:"id,omitempty"`
+		Link string `json:"link,omitempty"`
 	}
 
 	Audio struct {
-		ID string `json:"id,omitempty"`
+		ID   string `json:"id,omitempty"`
+		Link string `json:"link,omitempty"`
 	}
 )
 
@@ -883,14 +981,17 @@ func WithContactEmails(emails ...*Email) ContactOption {
 }
 
 const (
-	TypeInteractiveLocationRequest = "location_request_message"
-	TypeInteractiveCTAURL          = "cta_url"
-	TypeInteractiveButton          = "button"
-	TypeInteractiveFlow            = "flow"
-	InteractionActionSendLocation  = "send_location"
-	InteractiveActionCTAURL        = "cta_url"
-	InteractiveActionButtonReply   = "reply"
-	InteractiveActionFlow          = "flow"
+	TypeInteractiveLocationRequest  = "location_request_message"
+	TypeInteractiveCTAURL           = "cta_url"
+	TypeInteractiveButton           = "button"
+	TypeInteractiveFlow             = "flow"
+	TypeInteractiveList             = "list"
+	TypeInteractiveCallPermission   = "call_permission_request"
+	InteractionActionSendLocation   = "send_location"
+	InteractiveActionCTAURL         = "cta_url"
+	InteractiveActionButtonReply    = "reply"
+	InteractiveActionFlow           = "flow"
+	InteractiveActionCallPermission = "call_permission_request"
 )
 
 type (
@@ -936,19 +1037,35 @@ type (
 	}
 
 	InteractiveActionParameters struct {
-		DisplayText        string             `json:"display_text,omitempty"`
-		URL                string             `json:"url,omitempty"`
-		FlowMessageVersion string             `json:"flow_message_version"`
-		FlowToken          string             `json:"flow_token"`
-		FlowID             string             `json:"flow_id"`
-		FlowCTA            string             `json:"flow_cta"`
-		FlowAction         string             `json:"flow_action"`
-		FlowActionPayload  *FlowActionPayload `json:"flow_action_payload"`
+		DisplayText                string             `json:"display_text,omitempty"`
+		URL                        string             `json:"url,omitempty"`
+		ThumbnailProductRetailerID string             `json:"thumbnail_product_retailer_id,omitempty"`
+		FlowMessageVersion         string             `json:"flow_message_version,omitempty"`
+		FlowToken                  string             `json:"flow_token,omitempty"`
+		FlowID                     string             `json:"flow_id,omitempty"`
+		FlowName                   string             `json:"flow_name,omitempty"`
+		FlowCTA                    string             `json:"flow_cta,omitempty"`
+		FlowAction                 string             `json:"flow_action,omitempty"`
+		FlowActionPayload          *FlowActionPayload `json:"flow_action_payload,omitempty"`
+		Mode                       string             `json:"mode,omitempty"`
+		Country                    string             `json:"country,omitempty"`
+		Values                     *AddressValues     `json:"values,omitempty"`
+		SavedAddresses             []*SavedAddress    `json:"saved_addresses,omitempty"`
+		ValidationErrors           map[string]string  `json:"validation_errors,omitempty"`
+		ReferenceID                string             `json:"reference_id,omitempty"`
+		Type                       string             `json:"type,omitempty"`
+		PaymentType                string             `json:"payment_type,omitempty"`
+		PaymentSettings            []*PaymentSetting  `json:"payment_settings,omitempty"`
+		PaymentConfiguration       string             `json:"payment_configuration,omitempty"`
+		Currency                   string             `json:"currency,omitempty"`
+		TotalAmount                *Amount            `json:"total_amount,omitempty"`
+		Order                      *Order             `json:"order,omitempty"`
+		Payment                    *OrderPayment      `json:"payment,omitempty"`
 	}
 
 	FlowActionPayload struct {
 		Screen string                 `json:"screen"`
-		Data   map[string]interface{} `json:"data"`
+		Data   map[string]interface{} `json:"data,omitempty"`
 	}
 
 	InteractiveHeader struct {
@@ -987,34 +1104,47 @@ type InteractiveFlowRequest struct {
 	FlowMessageVersion string             `json:"flow_message_version"`
 	FlowToken          string             `json:"flow_token"`
 	FlowID             string             `json:"flow_id"`
+	FlowName           string             `json:"flow_name"`
 	FlowCTA            string             `json:"flow_cta"`
 	FlowAction         string             `json:"flow_action"`
 	FlowScreen         string             `json:"flow_screen"`
 	FlowData           map[string]any     `json:"flow_data"`
+	Mode               string             `json:"mode"`
 }
 
 func WithInteractiveFlow(req *InteractiveFlowRequest) Option {
 	return func(message *Message) {
-		content := NewInteractiveMessageContent(
-			TypeInteractiveFlow,
-			WithInteractiveFooter(req.Footer),
+		params := &InteractiveActionParameters{
+			FlowMessageVersion: req.FlowMessageVersion,
+			FlowToken:          req.FlowToken,
+			FlowID:             req.FlowID,
+			FlowName:           req.FlowName,
+			FlowCTA:            req.FlowCTA,
+			FlowAction:         req.FlowAction,
+			Mode:               req.Mode,
+		}
+
+		if req.FlowAction != FlowActionDataExchange {
+			params.FlowActionPayload = &FlowActionPayload{
+				Screen: req.FlowScreen,
+				Data:   req.FlowData,
+			}
+		}
+
+		options := []InteractiveOption{
 			WithInteractiveHeader(req.Header),
 			WithInteractiveBody(req.Body),
 			WithInteractiveAction(&InteractiveAction{
-				Name: InteractiveActionFlow,
-				Parameters: &InteractiveActionParameters{
-					FlowMessageVersion: req.FlowMessageVersion,
-					FlowToken:          req.FlowToken,
-					FlowID:             req.FlowID,
-					FlowCTA:            req.FlowCTA,
-					FlowAction:         req.FlowAction,
-					FlowActionPayload: &FlowActionPayload{
-						Screen: req.FlowScreen,
-						Data:   req.FlowData,
-					},
-				},
+				Name:       InteractiveActionFlow,
+				Parameters: params,
 			}),
-		)
+		}
+
+		if req.Footer != "" {
+			options = append(options, WithInteractiveFooter(req.Footer))
+		}
+
+		content := NewInteractiveMessageContent(TypeInteractiveFlow, options...)
 
 		message.Type = TypeInteractive
 		message.Interactive = content
@@ -1052,6 +1182,40 @@ func WithInteractiveReplyButtons(params *InteractiveReplyButtonsRequest) Option
 	}
 }
 
+// InteractiveListMaxRows is the number of rows an interactive list can have across all its
+// sections.
+const InteractiveListMaxRows = 10
+
+// InteractiveListRequest is a list message. Button is the label of the button that opens
+// the list, the reply of the contact carries the ID of the selected row.
+type InteractiveListRequest struct {
+	Button   string
+	Body     string
+	Header   *InteractiveHeader
+	Footer   string
+	Sections []*InteractiveSection
+}
+
+func WithInteractiveList(params *InteractiveListRequest) Option {
+	return func(message *Message) {
+		options := []InteractiveOption{
+			WithInteractiveBody(params.Body),
+			WithInteractiveHeader(params.Header),
+			WithInteractiveAction(&InteractiveAction{
+				Button:   params.Button,
+				Sections: params.Sections,
+			}),
+		}
+
+		if params.Footer != "" {
+			options = append(options, WithInteractiveFooter(params.Footer))
+		}
+
+		message.Interactive = NewInteractiveMessageContent(TypeInteractiveList, options...)
+		message.Type = TypeInteractive
+	}
+}
+
 type InteractiveCTARequest struct {
 	DisplayText string
 	URL         string
@@ -1118,6 +1282,26 @@ func WithRequestLocationMessage(text *string) Option {
 	}
 }
 
+// WithCallPermissionRequest asks the recipient for the permission to call them. The footer
+// is optional.
+func WithCallPermissionRequest(body, footer string) Option {
+	return func(message *Message) {
+		options := []InteractiveOption{
+			WithInteractiveBody(body),
+			WithInteractiveAction(&InteractiveAction{
+				Name: InteractiveActionCallPermission,
+			}),
+		}
+
+		if footer != "" {
+			options = append(options, WithInteractiveFooter(footer))
+		}
+
+		message.Type = TypeInteractive
+		message.Interactive = NewInteractiveMessageContent(TypeInteractiveCallPermission, options...)
+	}
+}
+
 func WithInteractiveFooter(footer string) InteractiveOption {
 	return func(i *Interactive) {
 		i.Footer = &InteractiveFooter{
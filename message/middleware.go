@@ -0,0 +1,94 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+// ErrSendSuppressed wraps the errors of the checks of a Guard, so callers can tell a
+// message that was deliberately not sent apart from a failed send.
+var ErrSendSuppressed = errors.New("message send suppressed")
+
+// Middleware wraps a Sender. A middleware may change the context, config or request it
+// passes down, answer without calling next to short-circuit the chain, or inspect the
+// response on the way back.
+//
+// Middlewares run in the order they are given: the first one sees the request first and
+// the response last. SenderMiddleware values are converted with their Middleware method.
+type Middleware func(next Sender) Sender
+
+// Middleware converts mw to a Middleware.
+func (mw SenderMiddleware) Middleware() Middleware {
+	return func(next Sender) Sender {
+		return mw(next.Send)
+	}
+}
+
+// Chain composes middlewares into one, the first given running first. Nil middlewares are
+// skipped.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Sender) Sender {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+
+		return next
+	}
+}
+
+// Guard returns a Middleware that runs check before a request goes further down the
+// chain. When check fails the request is not sent and the error is returned wrapped in
+// ErrSendSuppressed, for example to suppress messages to recipients that opted out.
+func Guard(check func(ctx context.Context, conf *config.Config, request *BaseRequest) error) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, conf *config.Config, request *BaseRequest) (*Response, error) {
+			if err := check(ctx, conf, request); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrSendSuppressed, err)
+			}
+
+			return next.Send(ctx, conf, request)
+		})
+	}
+}
+
+// ContextMiddleware returns a Middleware that replaces the context passed down the chain
+// with the one returned by fn, for example to attach a deadline or a trace span.
+func ContextMiddleware(fn func(ctx context.Context, request *BaseRequest) context.Context) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, conf *config.Config, request *BaseRequest) (*Response, error) {
+			return next.Send(fn(ctx, request), conf, request)
+		})
+	}
+}
+
+// Use adds middlewares to the client. They run after the middlewares given to
+// NewBaseClient and the ones added before, in the order given. Use must not be called
+// concurrently with sends.
+func (c *BaseClient) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.sender = Chain(c.middlewares...)(c.base)
+}
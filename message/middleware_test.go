@@ -0,0 +1,92 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type traceKey struct{}
+
+func TestBaseClient_Use(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	trace := func(name string) message.Middleware {
+		return func(next message.Sender) message.Sender {
+			return message.SenderFunc(func(ctx context.Context, conf *config.Config,
+				request *message.BaseRequest,
+			) (*message.Response, error) {
+				calls = append(calls, name+":"+request.Message.To)
+
+				return next.Send(ctx, conf, request)
+			})
+		}
+	}
+
+	sender := whttp.SenderFunc[message.Message](func(ctx context.Context, _ *whttp.Request[message.Message],
+		_ whttp.ResponseDecoder,
+	) error {
+		id, _ := ctx.Value(traceKey{}).(string)
+		calls = append(calls, "http:"+id)
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{BaseURL: "https://graph.facebook.com", APIVersion: "v20.0"}, nil
+	})
+
+	constructed := message.SenderMiddleware(func(next message.SenderFunc) message.SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *message.BaseRequest) (*message.Response, error) {
+			calls = append(calls, "constructor:"+request.Message.To)
+
+			return next(ctx, conf, request)
+		}
+	})
+
+	client, err := message.NewBaseClient(sender, reader, constructed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := errors.New("recipient opted out")
+	client.Use(
+		trace("first"),
+		message.ContextMiddleware(func(ctx context.Context, request *message.BaseRequest) context.Context {
+			return context.WithValue(ctx, traceKey{}, "trace-"+request.Message.To)
+		}),
+		message.Guard(func(_ context.Context, _ *config.Config, request *message.BaseRequest) error {
+			if request.Message.To == "blocked" {
+				return blocked
+			}
+
+			return nil
+		}),
+	)
+	client.Use(trace("second"))
+
+	ctx := context.Background()
+	if _, err := client.SendText(ctx, message.NewRequest("allowed", &message.Text{Body: "hi"}, "")); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	_, err = client.SendText(ctx, message.NewRequest("blocked", &message.Text{Body: "hi"}, ""))
+	if !errors.Is(err, message.ErrSendSuppressed) || !errors.Is(err, blocked) {
+		t.Errorf("SendText() error = %v, want %v", err, message.ErrSendSuppressed)
+	}
+
+	want := []string{
+		"constructor:allowed", "first:allowed", "second:allowed", "http:trace-allowed",
+		"constructor:blocked", "first:blocked",
+	}
+	if diff := gcmp.Diff(want, calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxCouponCodeLength is the maximum length of the code of a copy_code button.
+	MaxCouponCodeLength = 15
+
+	// MaxTemplateButtonIndex is the index of the last of the 10 buttons a template can have.
+	MaxTemplateButtonIndex = 9
+
+	// minExpirationTimeMs is the first millisecond timestamp with 13 digits, in 2001. The
+	// expiration of offers is in milliseconds and smaller values are almost always unix
+	// seconds passed by mistake, which the API accepts and shows as a date in 1970.
+	minExpirationTimeMs = 1_000_000_000_000
+)
+
+var (
+	ErrInvalidLimitedTimeOffer = errors.New("invalid limited time offer")
+	ErrOfferExpirationUnit     = errors.New("offer expiration time must be in unix milliseconds")
+	ErrOfferExpired            = errors.New("offer expiration time is in the past")
+	ErrInvalidCouponCode       = errors.New("coupon code must be 1 to 15 characters")
+	ErrInvalidButtonIndex      = errors.New("template button index must be between 0 and 9")
+)
+
+// ExpirationTimeMs returns t as the unix milliseconds used by the expiration_time_ms
+// field of limited time offers.
+func ExpirationTimeMs(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+// ValidateOfferExpiration checks that expirationTimeMs is a unix time in milliseconds
+// after now.
+func ValidateOfferExpiration(expirationTimeMs int64, now time.Time) error {
+	if expirationTimeMs < minExpirationTimeMs {
+		return fmt.Errorf("%w: got %d", ErrOfferExpirationUnit, expirationTimeMs)
+	}
+
+	if expirationTimeMs <= now.UnixMilli() {
+		return fmt.Errorf("%w: %s", ErrOfferExpired, time.UnixMilli(expirationTimeMs).UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// NewLimitedTimeOfferComponent returns the limited_time_offer component of an offer
+// expiring at expiresAt, which must be in the future.
+func NewLimitedTimeOfferComponent(expiresAt time.Time) (*TemplateComponent, error) {
+	expirationTimeMs := ExpirationTimeMs(expiresAt)
+	if err := ValidateOfferExpiration(expirationTimeMs, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLimitedTimeOffer, err)
+	}
+
+	return NewTemplateComponentLimitedTimeOffer(expirationTimeMs), nil
+}
+
+// NewCouponCodeButton returns the parameters of the copy_code button at index, the
+// button copying code to the clipboard of the user.
+func NewCouponCodeButton(index int, code string) (*TemplateComponent, error) {
+	if err := validateButtonIndex(index); err != nil {
+		return nil, err
+	}
+
+	if err := validateCouponCode(code); err != nil {
+		return nil, err
+	}
+
+	return NewCopyCodeButton(&ButtonParams{Index: index, Text: code}), nil
+}
+
+// Validate checks the expiration of the offer against now and the coupon code when set.
+// Errors wrap ErrInvalidLimitedTimeOffer.
+func (req *LimitedTimeOfferTemplateRequest) Validate(now time.Time) error {
+	var errs []error
+	if err := ValidateOfferExpiration(req.ExpirationTime, now); err != nil {
+		errs = append(errs, err)
+	}
+
+	if req.CouponCode != nil {
+		if err := validateCouponCode(*req.CouponCode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidLimitedTimeOffer, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// Validate checks the coupon code and the index of its button.
+func (req *CouponCodeTemplateRequest) Validate() error {
+	if err := validateButtonIndex(req.ButtonIndex); err != nil {
+		return err
+	}
+
+	return validateCouponCode(req.CouponCode)
+}
+
+// NewLimitedTimeOfferTemplateMessage validates req and returns the template message
+// sending the offer to recipient.
+func NewLimitedTimeOfferTemplateMessage(recipient string, req *LimitedTimeOfferTemplateRequest) (*Message, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithTemplateMessage(NewLimitedTimeOfferTemplate(req)))
+}
+
+// NewCouponCodeTemplateMessage validates req and returns the template message sending
+// the coupon to recipient.
+func NewCouponCodeTemplateMessage(recipient string, req *CouponCodeTemplateRequest) (*Message, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithTemplateMessage(NewCouponCodeTemplate(req)))
+}
+
+func validateCouponCode(code string) error {
+	if n := utf8.RuneCountInString(code); n == 0 || n > MaxCouponCodeLength {
+		return fmt.Errorf("%w: got %q", ErrInvalidCouponCode, code)
+	}
+
+	return nil
+}
+
+func validateButtonIndex(index int) error {
+	if index < 0 || index > MaxTemplateButtonIndex {
+		return fmt.Errorf("%w: got %d", ErrInvalidButtonIndex, index)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestValidateOfferExpiration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ms      int64
+		wantErr error
+	}{
+		{name: "future", ms: now.Add(time.Hour).UnixMilli()},
+		{name: "seconds", ms: now.Add(time.Hour).Unix(), wantErr: message.ErrOfferExpirationUnit},
+		{name: "past", ms: now.Add(-time.Hour).UnixMilli(), wantErr: message.ErrOfferExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := message.ValidateOfferExpiration(tt.ms, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateOfferExpiration() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLimitedTimeOfferTemplateMessage(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	code := "SAVE20"
+	msg, err := message.NewLimitedTimeOfferTemplateMessage("255700000001", &message.LimitedTimeOfferTemplateRequest{
+		Name:           "summer_sale",
+		Language:       &message.TemplateLanguage{Code: "en_US", Policy: "deterministic"},
+		ExpirationTime: message.ExpirationTimeMs(expiresAt),
+		CouponCode:     &code,
+		URLVariable:    "summer",
+	})
+	if err != nil {
+		t.Fatalf("NewLimitedTimeOfferTemplateMessage() error = %v", err)
+	}
+
+	var types []string
+	for _, component := range msg.Template.Components {
+		types = append(types, component.Type+"/"+component.SubType)
+	}
+
+	want := []string{"body/", "limited_time_offer/", "button/copy_code", "button/url"}
+	if diff := gcmp.Diff(want, types); diff != "" {
+		t.Errorf("components mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = message.NewLimitedTimeOfferTemplateMessage("255700000001", &message.LimitedTimeOfferTemplateRequest{
+		Name:           "summer_sale",
+		ExpirationTime: expiresAt.Unix(),
+	})
+	if !errors.Is(err, message.ErrInvalidLimitedTimeOffer) || !errors.Is(err, message.ErrOfferExpirationUnit) {
+		t.Errorf("NewLimitedTimeOfferTemplateMessage() error = %v, want ErrOfferExpirationUnit", err)
+	}
+}
+
+func TestNewCouponCodeButton(t *testing.T) {
+	t.Parallel()
+
+	component, err := message.NewCouponCodeButton(1, "SAVE20")
+	if err != nil {
+		t.Fatalf("NewCouponCodeButton() error = %v", err)
+	}
+
+	got, err := json.Marshal(component.Parameters[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"coupon_code","text":"SAVE20","currency":null,"date_time":null,"image":null,` +
+		`"document":null,"video":null,"location":null}`
+	if diff := gcmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("parameter mismatch (-want +got):\n%s", diff)
+	}
+
+	if component.Index != 1 || component.SubType != message.TemplateButtonSubTypeCopyCode {
+		t.Errorf("component = %+v", component)
+	}
+
+	if _, err := message.NewCouponCodeButton(0, "THIS-CODE-IS-TOO-LONG"); !errors.Is(err, message.ErrInvalidCouponCode) {
+		t.Errorf("NewCouponCodeButton() error = %v, want ErrInvalidCouponCode", err)
+	}
+
+	if _, err := message.NewCouponCodeButton(10, "SAVE20"); !errors.Is(err, message.ErrInvalidButtonIndex) {
+		t.Errorf("NewCouponCodeButton() error = %v, want ErrInvalidButtonIndex", err)
+	}
+}
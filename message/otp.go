@@ -0,0 +1,165 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// OTPButtonType is the kind of button of an authentication template, set when the template
+// is created. COPY_CODE, ONE_TAP and ZERO_TAP are the otp_type of OTP buttons, URL is the
+// button of the authentication templates created before OTP buttons existed.
+type OTPButtonType string
+
+const (
+	OTPButtonCopyCode OTPButtonType = "COPY_CODE"
+	OTPButtonOneTap   OTPButtonType = "ONE_TAP"
+	OTPButtonZeroTap  OTPButtonType = "ZERO_TAP"
+	OTPButtonURL      OTPButtonType = "URL"
+	OTPButtonNone     OTPButtonType = "NONE"
+)
+
+const (
+	// MaxOTPLength is the maximum length of a one-time password.
+	MaxOTPLength = 15
+
+	// TemplateLanguagePolicyDeterministic is the only language policy of templates.
+	TemplateLanguagePolicyDeterministic = "deterministic"
+)
+
+var (
+	ErrInvalidOTP           = errors.New("one-time password must be 1 to 15 characters")
+	ErrInvalidOTPButtonType = errors.New("invalid otp button type")
+)
+
+type (
+	// OTPTemplateOptions are the options of NewOTPTemplate.
+	OTPTemplateOptions struct {
+		ButtonType   OTPButtonType
+		ButtonIndex  int
+		URLParameter string
+		Policy       string
+	}
+
+	OTPTemplateOption func(*OTPTemplateOptions)
+)
+
+// WithOTPButtonType sets the kind of button of the template, OTPButtonCopyCode by default.
+// Use OTPButtonNone for templates without a button.
+func WithOTPButtonType(buttonType OTPButtonType) OTPTemplateOption {
+	return func(o *OTPTemplateOptions) {
+		o.ButtonType = buttonType
+	}
+}
+
+// WithOTPButtonIndex sets the index of the button, 0 by default.
+func WithOTPButtonIndex(index int) OTPTemplateOption {
+	return func(o *OTPTemplateOptions) {
+		o.ButtonIndex = index
+	}
+}
+
+// WithOTPURLParameter sets the variable of the URL of an OTPButtonURL button. It is the
+// code by default.
+func WithOTPURLParameter(parameter string) OTPTemplateOption {
+	return func(o *OTPTemplateOptions) {
+		o.URLParameter = parameter
+	}
+}
+
+// WithOTPLanguagePolicy sets the language policy, TemplateLanguagePolicyDeterministic by
+// default.
+func WithOTPLanguagePolicy(policy string) OTPTemplateOption {
+	return func(o *OTPTemplateOptions) {
+		o.Policy = policy
+	}
+}
+
+// NewOTPTemplate returns the message sending the authentication template name in language
+// lang with the one-time password code.
+//
+// The code is the single body variable of authentication templates. Copy code, one-tap
+// and zero-tap buttons are all sent as a url button whose parameter is the code, the kind
+// of button and the validity period of the code being part of the template definition,
+// see template.NewAuthenticationCreateRequest. The button is left out with OTPButtonNone.
+//
+//	msg, err := message.NewOTPTemplate("255700000001", "login_code", "en_US", "482913",
+//		message.WithOTPButtonType(message.OTPButtonOneTap))
+func NewOTPTemplate(recipient, name, lang, code string, options ...OTPTemplateOption) (*Message, error) {
+	tmpl, err := NewOTPTemplateContent(name, lang, code, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithTemplateMessage(tmpl))
+}
+
+// NewOTPTemplateContent returns the template content of NewOTPTemplate.
+func NewOTPTemplateContent(name, lang, code string, options ...OTPTemplateOption) (*Template, error) {
+	opts := &OTPTemplateOptions{
+		ButtonType: OTPButtonCopyCode,
+		Policy:     TemplateLanguagePolicyDeterministic,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(opts)
+		}
+	}
+
+	if utf8.RuneCountInString(code) == 0 || utf8.RuneCountInString(code) > MaxOTPLength {
+		return nil, ErrInvalidOTP
+	}
+
+	components := []*TemplateComponent{{
+		Type:       TemplateComponentTypeBody,
+		Parameters: []*TemplateParameter{{Type: TemplateParameterTypeText, Text: code}},
+	}}
+
+	switch opts.ButtonType {
+	case OTPButtonCopyCode, OTPButtonOneTap, OTPButtonZeroTap, OTPButtonURL:
+		if err := validateButtonIndex(opts.ButtonIndex); err != nil {
+			return nil, err
+		}
+
+		parameter := code
+		if opts.ButtonType == OTPButtonURL && opts.URLParameter != "" {
+			parameter = opts.URLParameter
+		}
+
+		components = append(components, &TemplateComponent{
+			Type:       TemplateComponentTypeButton,
+			SubType:    TemplateButtonSubTypeURL,
+			Index:      opts.ButtonIndex,
+			Parameters: []*TemplateParameter{{Type: TemplateParameterTypeText, Text: parameter}},
+		})
+	case OTPButtonNone:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOTPButtonType, string(opts.ButtonType))
+	}
+
+	return &Template{
+		Name:       name,
+		Language:   &TemplateLanguage{Code: lang, Policy: opts.Policy},
+		Components: components,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestNewOTPTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []message.OTPTemplateOption
+		want    string
+		wantErr error
+	}{
+		{
+			name: "copy code",
+			want: `{"name":"login_code","language":{"code":"en_US","policy":"deterministic"},"components":[` +
+				`{"type":"body","index":0,"parameters":[{"type":"text","text":"482913","currency":null,` +
+				`"date_time":null,"image":null,"document":null,"video":null,"location":null}]},` +
+				`{"type":"button","sub_type":"url","index":0,"parameters":[{"type":"text","text":"482913",` +
+				`"currency":null,"date_time":null,"image":null,"document":null,"video":null,"location":null}]}]}`,
+		},
+		{
+			name: "url with parameter",
+			options: []message.OTPTemplateOption{
+				message.WithOTPButtonType(message.OTPButtonURL),
+				message.WithOTPButtonIndex(1),
+				message.WithOTPURLParameter("code-482913"),
+			},
+			want: `{"name":"login_code","language":{"code":"en_US","policy":"deterministic"},"components":[` +
+				`{"type":"body","index":0,"parameters":[{"type":"text","text":"482913","currency":null,` +
+				`"date_time":null,"image":null,"document":null,"video":null,"location":null}]},` +
+				`{"type":"button","sub_type":"url","index":1,"parameters":[{"type":"text","text":"code-482913",` +
+				`"currency":null,"date_time":null,"image":null,"document":null,"video":null,"location":null}]}]}`,
+		},
+		{
+			name:    "no button",
+			options: []message.OTPTemplateOption{message.WithOTPButtonType(message.OTPButtonNone)},
+			want: `{"name":"login_code","language":{"code":"en_US","policy":"deterministic"},"components":[` +
+				`{"type":"body","index":0,"parameters":[{"type":"text","text":"482913","currency":null,` +
+				`"date_time":null,"image":null,"document":null,"video":null,"location":null}]}]}`,
+		},
+		{
+			name:    "unknown button",
+			options: []message.OTPTemplateOption{message.WithOTPButtonType("MAGIC")},
+			wantErr: message.ErrInvalidOTPButtonType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := message.NewOTPTemplate("255700000001", "login_code", "en_US", "482913", tt.options...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewOTPTemplate() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewOTPTemplate() error = %v", err)
+			}
+
+			got, err := json.Marshal(msg.Template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := gcmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("template mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := message.NewOTPTemplate("255700000001", "login_code", "en_US", ""); !errors.Is(err,
+		message.ErrInvalidOTP) {
+		t.Errorf("NewOTPTemplate() error = %v, want ErrInvalidOTP", err)
+	}
+}
@@ -0,0 +1,125 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_ContextOverride(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	sender := whttp.SenderFunc[message.Message](func(_ context.Context, req *whttp.Request[message.Message],
+		_ whttp.ResponseDecoder,
+	) error {
+		sent = append(sent, req.Bearer+" "+req.Endpoints[1])
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{
+			BaseURL:       "https://graph.facebook.com",
+			APIVersion:    "v20.0",
+			AccessToken:   "default-token",
+			PhoneNumberID: "default-phone",
+		}, nil
+	})
+
+	client, err := message.NewBaseClient(sender, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := message.NewRequest("255700000000", &message.Text{Body: "hello"}, "")
+
+	ctx := context.Background()
+	if _, err := client.SendText(ctx, request); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	tenant := config.WithContextOverride(ctx, config.Override{
+		PhoneNumberID: "tenant-phone",
+		AccessToken:   "tenant-token",
+	})
+	if _, err := client.SendText(tenant, request); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	want := []string{
+		"default-token default-phone",
+		"tenant-token tenant-phone",
+	}
+	for i := range want {
+		if i >= len(sent) || sent[i] != want[i] {
+			t.Errorf("request %d = %v, want %s", i, sent, want[i])
+		}
+	}
+}
+
+func TestBaseClient_WithProfile(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	sender := whttp.SenderFunc[message.Message](func(_ context.Context, req *whttp.Request[message.Message],
+		_ whttp.ResponseDecoder,
+	) error {
+		sent = append(sent, req.Endpoints[1])
+
+		return nil
+	})
+
+	base := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{
+			BaseURL:       "https://graph.facebook.com",
+			APIVersion:    "v20.0",
+			AccessToken:   "token",
+			PhoneNumberID: "default-phone",
+		}, nil
+	})
+
+	reader, err := config.NewProfileReader(base, "sales",
+		&config.Profile{Name: "sales", PhoneNumberID: "sales-phone", DisplayName: "Acme Sales"},
+		&config.Profile{Name: "support", PhoneNumberID: "support-phone", DisplayName: "Acme Support"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := message.NewBaseClient(sender, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	text := &message.Text{Body: "hello"}
+	if _, err := client.SendText(ctx, message.NewRequest("255700000000", text, "")); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	request := message.NewRequest("255700000000", text, "", message.WithProfile("support"))
+	if _, err := client.SendText(ctx, request); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	unknown := message.NewRequest("255700000000", text, "", message.WithProfile("alerts"))
+	if _, err := client.SendText(ctx, unknown); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Errorf("SendText() error = %v, want %v", err, config.ErrProfileNotFound)
+	}
+
+	want := []string{"sales-phone", "support-phone"}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %v, want %v", sent, want)
+	}
+
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("request %d sent from %s, want %s", i, sent[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,410 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	TypeInteractiveOrderDetails   = "order_details"
+	TypeInteractiveOrderStatus    = "order_status"
+	InteractiveActionReviewAndPay = "review_and_pay"
+	InteractiveActionReviewOrder  = "review_order"
+)
+
+// Payment types, UPI payments are available in India and the "br" payment type in Brazil.
+const (
+	PaymentTypeUPI    = "upi"
+	PaymentTypeBrazil = "br"
+)
+
+const (
+	CurrencyINR = "INR"
+	CurrencyBRL = "BRL"
+)
+
+const (
+	GoodsTypeDigital  = "digital-goods"
+	GoodsTypePhysical = "physical-goods"
+)
+
+// Types of the PaymentSetting of an order. Payment gateways are used in India, the others
+// in Brazil.
+const (
+	PaymentSettingGateway        = "payment_gateway"
+	PaymentSettingPixDynamicCode = "pix_dynamic_code"
+	PaymentSettingPaymentLink    = "payment_link"
+	PaymentSettingBoleto         = "boleto"
+)
+
+const (
+	PaymentGatewayRazorpay = "razorpay"
+	PaymentGatewayPayU     = "payu"
+	PaymentGatewayZaakpay  = "zaakpay"
+)
+
+const (
+	OrderStatusPending          = "pending"
+	OrderStatusProcessing       = "processing"
+	OrderStatusPartiallyShipped = "partially_shipped"
+	OrderStatusShipped          = "shipped"
+	OrderStatusCompleted        = "completed"
+	OrderStatusCanceled         = "canceled"
+)
+
+// MaxOrderReferenceIDLength is the maximum length of the reference id of an order.
+const MaxOrderReferenceIDLength = 35
+
+var (
+	ErrInvalidOrderMessage = errors.New("invalid order message")
+	ErrOrderReferenceID    = errors.New("order reference id is required and at most 35 characters")
+	ErrOrderPaymentType    = errors.New("payment type must be upi with INR or br with BRL")
+	ErrOrderItems          = errors.New("order must have items with a positive quantity")
+	ErrOrderAmounts        = errors.New("order amounts do not add up")
+	ErrOrderStatus         = errors.New("unknown order status")
+)
+
+type (
+	// Amount is an amount of money, Value divided by Offset. 120.50 is Value 12050 with
+	// Offset 100, the offset used by the payments API.
+	Amount struct {
+		Value  int64 `json:"value"`
+		Offset int   `json:"offset"`
+	}
+
+	// OrderAmount is an amount of the order summary. Description is shown next to it and
+	// DiscountProgramName only applies to discounts.
+	OrderAmount struct {
+		Value               int64  `json:"value"`
+		Offset              int    `json:"offset"`
+		Description         string `json:"description,omitempty"`
+		DiscountProgramName string `json:"discount_program_name,omitempty"`
+	}
+
+	// OrderItem is a line of an order. Amount is the unit price and SaleAmount, when set,
+	// the discounted unit price. The importer fields are required in India for imported
+	// goods.
+	OrderItem struct {
+		RetailerID      string           `json:"retailer_id"`
+		Name            string           `json:"name"`
+		Amount          *Amount          `json:"amount"`
+		SaleAmount      *Amount          `json:"sale_amount,omitempty"`
+		Quantity        int              `json:"quantity"`
+		CountryOfOrigin string           `json:"country_of_origin,omitempty"`
+		ImporterName    string           `json:"importer_name,omitempty"`
+		ImporterAddress *ImporterAddress `json:"importer_address,omitempty"`
+	}
+
+	ImporterAddress struct {
+		AddressLine1 string `json:"address_line1"`
+		AddressLine2 string `json:"address_line2,omitempty"`
+		City         string `json:"city"`
+		ZoneCode     string `json:"zone_code"`
+		PostalCode   string `json:"postal_code"`
+		CountryCode  string `json:"country_code"`
+	}
+
+	// OrderExpiration makes the order unpayable after Timestamp, a unix time in seconds
+	// formatted as a string.
+	OrderExpiration struct {
+		Timestamp   string `json:"timestamp"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Order is the order of an order_details message, or the new status of the order in
+	// an order_status message, in which case only Status and Description are set.
+	Order struct {
+		Status      string           `json:"status"`
+		Description string           `json:"description,omitempty"`
+		CatalogID   string           `json:"catalog_id,omitempty"`
+		Expiration  *OrderExpiration `json:"expiration,omitempty"`
+		Items       []*OrderItem     `json:"items,omitempty"`
+		Subtotal    *Amount          `json:"subtotal,omitempty"`
+		Tax         *OrderAmount     `json:"tax,omitempty"`
+		Shipping    *OrderAmount     `json:"shipping,omitempty"`
+		Discount    *OrderAmount     `json:"discount,omitempty"`
+	}
+
+	// OrderPayment reports a payment received outside of WhatsApp in an order_status
+	// message, used in Brazil where payments are confirmed by the merchant.
+	OrderPayment struct {
+		Status    string `json:"status"`
+		Timestamp int64  `json:"timestamp,omitempty"`
+	}
+
+	// PaymentSetting is a way to pay the order, the field matching Type is set.
+	PaymentSetting struct {
+		Type           string          `json:"type"`
+		PaymentGateway *PaymentGateway `json:"payment_gateway,omitempty"`
+		PixDynamicCode *PixDynamicCode `json:"pix_dynamic_code,omitempty"`
+		PaymentLink    *PaymentLink    `json:"payment_link,omitempty"`
+		Boleto         *Boleto         `json:"boleto,omitempty"`
+	}
+
+	// PaymentGateway is a payment gateway configured on the WhatsApp Business Account.
+	// The options of the gateway, like the razorpay receipt and notes, are passed as is.
+	PaymentGateway struct {
+		Type              string         `json:"type"`
+		ConfigurationName string         `json:"configuration_name"`
+		Razorpay          map[string]any `json:"razorpay,omitempty"`
+		PayU              map[string]any `json:"payu,omitempty"`
+		Zaakpay           map[string]any `json:"zaakpay,omitempty"`
+	}
+
+	// PixDynamicCode is a Pix copy and paste code.
+	PixDynamicCode struct {
+		Code         string `json:"code"`
+		MerchantName string `json:"merchant_name"`
+		Key          string `json:"key"`
+		KeyType      string `json:"key_type"`
+	}
+
+	PaymentLink struct {
+		URI string `json:"uri"`
+	}
+
+	Boleto struct {
+		DigitableLine string `json:"digitable_line"`
+	}
+
+	// InteractiveOrderDetailsRequest describes an order_details message, which shows the
+	// order to the user with a button to pay for it. ReferenceID identifies the order in
+	// the payment webhooks and the order_status messages. PaymentConfiguration is the
+	// name of the payment configuration of the account in India, when PaymentSettings
+	// are not used.
+	InteractiveOrderDetailsRequest struct {
+		Body                 string
+		Header               *InteractiveHeader
+		Footer               string
+		ReferenceID          string
+		GoodsType            string
+		PaymentType          string
+		PaymentSettings      []*PaymentSetting
+		PaymentConfiguration string
+		Currency             string
+		TotalAmount          *Amount
+		Order                *Order
+	}
+
+	// InteractiveOrderStatusRequest describes an order_status message, which updates the
+	// status of the order sent with ReferenceID. Payment is only used in Brazil.
+	InteractiveOrderStatusRequest struct {
+		Body        string
+		Footer      string
+		ReferenceID string
+		Status      string
+		Description string
+		Payment     *OrderPayment
+	}
+)
+
+// NewOrderDetailsMessage returns an order_details message asking recipient to pay for
+// an order. The request is validated with ValidateOrderDetails.
+func NewOrderDetailsMessage(recipient string, req *InteractiveOrderDetailsRequest) (*Message, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: nil request", ErrInvalidOrderMessage)
+	}
+
+	if err := ValidateOrderDetails(req); err != nil {
+		return nil, err
+	}
+
+	return New(recipient, WithInteractiveOrderDetails(req))
+}
+
+// NewOrderStatusMessage returns an order_status message updating the status of an order.
+func NewOrderStatusMessage(recipient string, req *InteractiveOrderStatusRequest) (*Message, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: nil request", ErrInvalidOrderMessage)
+	}
+
+	if err := validateOrderStatus(req); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOrderMessage, err)
+	}
+
+	return New(recipient, WithInteractiveOrderStatus(req))
+}
+
+// ValidateOrderDetails checks an order_details request: the reference id, that the
+// currency matches the payment type, and that the amounts add up. The subtotal must be
+// the sum of the items and the total the subtotal plus tax and shipping minus discount,
+// all with the offset of the total. Errors wrap ErrInvalidOrderMessage.
+func ValidateOrderDetails(req *InteractiveOrderDetailsRequest) error {
+	if err := validateOrderDetails(req); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOrderMessage, err)
+	}
+
+	return nil
+}
+
+func validateOrderDetails(req *InteractiveOrderDetailsRequest) error {
+	if err := validateReferenceID(req.ReferenceID); err != nil {
+		return err
+	}
+
+	if req.Body == "" || utf8.RuneCountInString(req.Body) > MaxInteractiveBodyLength {
+		return fmt.Errorf("body is required and at most %d characters", MaxInteractiveBodyLength)
+	}
+
+	switch {
+	case req.PaymentType == PaymentTypeUPI && req.Currency == CurrencyINR:
+	case req.PaymentType == PaymentTypeBrazil && req.Currency == CurrencyBRL:
+	default:
+		return fmt.Errorf("%w: got %q with %q", ErrOrderPaymentType, req.PaymentType, req.Currency)
+	}
+
+	order := req.Order
+	if order == nil || len(order.Items) == 0 || req.TotalAmount == nil || order.Subtotal == nil {
+		return fmt.Errorf("%w: order, items, subtotal and total amount are required", ErrOrderItems)
+	}
+
+	offset := req.TotalAmount.Offset
+	var subtotal int64
+	for i, item := range order.Items {
+		if item == nil || item.Quantity < 1 || item.Amount == nil {
+			return fmt.Errorf("%w: item %d", ErrOrderItems, i)
+		}
+
+		price := item.Amount
+		if item.SaleAmount != nil {
+			price = item.SaleAmount
+		}
+
+		if price.Offset != offset {
+			return fmt.Errorf("%w: item %d has offset %d, want %d", ErrOrderAmounts, i, price.Offset, offset)
+		}
+		subtotal += price.Value * int64(item.Quantity)
+	}
+
+	if order.Subtotal.Offset != offset || order.Subtotal.Value != subtotal {
+		return fmt.Errorf("%w: subtotal is %d, items add up to %d", ErrOrderAmounts, order.Subtotal.Value, subtotal)
+	}
+
+	total := subtotal
+	for name, amount := range map[string]*OrderAmount{"tax": order.Tax, "shipping": order.Shipping} {
+		if amount == nil {
+			continue
+		}
+		if amount.Offset != offset {
+			return fmt.Errorf("%w: %s has offset %d, want %d", ErrOrderAmounts, name, amount.Offset, offset)
+		}
+		total += amount.Value
+	}
+
+	if order.Discount != nil {
+		if order.Discount.Offset != offset {
+			return fmt.Errorf("%w: discount has offset %d, want %d", ErrOrderAmounts, order.Discount.Offset, offset)
+		}
+		total -= order.Discount.Value
+	}
+
+	if req.TotalAmount.Value != total {
+		return fmt.Errorf("%w: total amount is %d, the order adds up to %d", ErrOrderAmounts,
+			req.TotalAmount.Value, total)
+	}
+
+	return nil
+}
+
+func validateOrderStatus(req *InteractiveOrderStatusRequest) error {
+	if err := validateReferenceID(req.ReferenceID); err != nil {
+		return err
+	}
+
+	switch req.Status {
+	case OrderStatusPending, OrderStatusProcessing, OrderStatusPartiallyShipped, OrderStatusShipped,
+		OrderStatusCompleted, OrderStatusCanceled:
+	default:
+		return fmt.Errorf("%w: %q", ErrOrderStatus, req.Status)
+	}
+
+	if req.Body == "" || utf8.RuneCountInString(req.Body) > MaxInteractiveBodyLength {
+		return fmt.Errorf("body is required and at most %d characters", MaxInteractiveBodyLength)
+	}
+
+	return nil
+}
+
+func validateReferenceID(id string) error {
+	if id == "" || utf8.RuneCountInString(id) > MaxOrderReferenceIDLength {
+		return ErrOrderReferenceID
+	}
+
+	return nil
+}
+
+// WithInteractiveOrderDetails sets the message content to the order_details message
+// described by req.
+func WithInteractiveOrderDetails(req *InteractiveOrderDetailsRequest) Option {
+	return func(message *Message) {
+		options := []InteractiveOption{
+			WithInteractiveHeader(req.Header),
+			WithInteractiveBody(req.Body),
+			WithInteractiveAction(&InteractiveAction{
+				Name: InteractiveActionReviewAndPay,
+				Parameters: &InteractiveActionParameters{
+					ReferenceID:          req.ReferenceID,
+					Type:                 req.GoodsType,
+					PaymentType:          req.PaymentType,
+					PaymentSettings:      req.PaymentSettings,
+					PaymentConfiguration: req.PaymentConfiguration,
+					Currency:             req.Currency,
+					TotalAmount:          req.TotalAmount,
+					Order:                req.Order,
+				},
+			}),
+		}
+
+		if req.Footer != "" {
+			options = append(options, WithInteractiveFooter(req.Footer))
+		}
+
+		message.Type = TypeInteractive
+		message.Interactive = NewInteractiveMessageContent(TypeInteractiveOrderDetails, options...)
+	}
+}
+
+// WithInteractiveOrderStatus sets the message content to the order_status message
+// described by req.
+func WithInteractiveOrderStatus(req *InteractiveOrderStatusRequest) Option {
+	return func(message *Message) {
+		options := []InteractiveOption{
+			WithInteractiveBody(req.Body),
+			WithInteractiveAction(&InteractiveAction{
+				Name: InteractiveActionReviewOrder,
+				Parameters: &InteractiveActionParameters{
+					ReferenceID: req.ReferenceID,
+					Order:       &Order{Status: req.Status, Description: req.Description},
+					Payment:     req.Payment,
+				},
+			}),
+		}
+
+		if req.Footer != "" {
+			options = append(options, WithInteractiveFooter(req.Footer))
+		}
+
+		message.Type = TypeInteractive
+		message.Interactive = NewInteractiveMessageContent(TypeInteractiveOrderStatus, options...)
+	}
+}
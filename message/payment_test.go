@@ -0,0 +1,158 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func orderDetailsRequest() *message.InteractiveOrderDetailsRequest {
+	return &message.InteractiveOrderDetailsRequest{
+		Body:                 "Your order",
+		ReferenceID:          "order-1",
+		GoodsType:            message.GoodsTypePhysical,
+		PaymentType:          message.PaymentTypeUPI,
+		PaymentConfiguration: "default",
+		Currency:             message.CurrencyINR,
+		TotalAmount:          &message.Amount{Value: 21000, Offset: 100},
+		Order: &message.Order{
+			Status: message.OrderStatusPending,
+			Items: []*message.OrderItem{{
+				RetailerID: "sku-1",
+				Name:       "Tea",
+				Amount:     &message.Amount{Value: 10000, Offset: 100},
+				Quantity:   2,
+			}},
+			Subtotal: &message.Amount{Value: 20000, Offset: 100},
+			Tax:      &message.OrderAmount{Value: 1500, Offset: 100},
+			Discount: &message.OrderAmount{Value: 500, Offset: 100},
+		},
+	}
+}
+
+func TestNewOrderDetailsMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.NewOrderDetailsMessage("919800000000", orderDetailsRequest())
+	if err != nil {
+		t.Fatalf("NewOrderDetailsMessage() error = %v", err)
+	}
+
+	got, err := json.Marshal(msg.Interactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"order_details","action":{"name":"review_and_pay","parameters":{"reference_id":"order-1",` +
+		`"type":"physical-goods","payment_type":"upi","payment_configuration":"default","currency":"INR",` +
+		`"total_amount":{"value":21000,"offset":100},"order":{"status":"pending","items":[{"retailer_id":"sku-1",` +
+		`"name":"Tea","amount":{"value":10000,"offset":100},"quantity":2}],"subtotal":{"value":20000,"offset":100},` +
+		`"tax":{"value":1500,"offset":100},"discount":{"value":500,"offset":100}}}},"body":{"text":"Your order"}}`
+	if diff := gcmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("interactive mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateOrderDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(req *message.InteractiveOrderDetailsRequest)
+		wantErr error
+	}{
+		{name: "valid", modify: func(*message.InteractiveOrderDetailsRequest) {}},
+		{
+			name:    "missing reference id",
+			modify:  func(req *message.InteractiveOrderDetailsRequest) { req.ReferenceID = "" },
+			wantErr: message.ErrOrderReferenceID,
+		},
+		{
+			name:    "currency does not match payment type",
+			modify:  func(req *message.InteractiveOrderDetailsRequest) { req.Currency = message.CurrencyBRL },
+			wantErr: message.ErrOrderPaymentType,
+		},
+		{
+			name:    "no items",
+			modify:  func(req *message.InteractiveOrderDetailsRequest) { req.Order.Items = nil },
+			wantErr: message.ErrOrderItems,
+		},
+		{
+			name:    "wrong subtotal",
+			modify:  func(req *message.InteractiveOrderDetailsRequest) { req.Order.Subtotal.Value = 10000 },
+			wantErr: message.ErrOrderAmounts,
+		},
+		{
+			name:    "wrong total",
+			modify:  func(req *message.InteractiveOrderDetailsRequest) { req.TotalAmount.Value = 20000 },
+			wantErr: message.ErrOrderAmounts,
+		},
+		{
+			name: "sale amount",
+			modify: func(req *message.InteractiveOrderDetailsRequest) {
+				req.Order.Items[0].SaleAmount = &message.Amount{Value: 9000, Offset: 100}
+				req.Order.Subtotal.Value = 18000
+				req.TotalAmount.Value = 19000
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := orderDetailsRequest()
+			tt.modify(req)
+
+			err := message.ValidateOrderDetails(req)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ValidateOrderDetails() error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, message.ErrInvalidOrderMessage) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateOrderDetails() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewOrderStatusMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.NewOrderStatusMessage("919800000000", &message.InteractiveOrderStatusRequest{
+		Body:        "Your order has shipped",
+		ReferenceID: "order-1",
+		Status:      message.OrderStatusShipped,
+	})
+	if err != nil {
+		t.Fatalf("NewOrderStatusMessage() error = %v", err)
+	}
+
+	got, err := json.Marshal(msg.Interactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"order_status","action":{"name":"review_order","parameters":{"reference_id":"order-1",` +
+		`"order":{"status":"shipped"}}},"body":{"text":"Your order has shipped"}}`
+	if diff := gcmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("interactive mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = message.NewOrderStatusMessage("919800000000", &message.InteractiveOrderStatusRequest{
+		Body:        "Lost",
+		ReferenceID: "order-1",
+		Status:      "lost",
+	})
+	if !errors.Is(err, message.ErrOrderStatus) {
+		t.Errorf("NewOrderStatusMessage() error = %v, want ErrOrderStatus", err)
+	}
+}
@@ -0,0 +1,324 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/pkg/ratelimit"
+)
+
+// DefaultQueueThroughput is the number of messages per second a business phone number can
+// send by default.
+const DefaultQueueThroughput = 80
+
+var (
+	ErrQueueClosed = errors.New("message queue closed")
+	ErrQueueFull   = errors.New("message queue full")
+)
+
+type (
+	// MessageSender sends a single message, both BaseClient and Client implement it.
+	MessageSender interface {
+		SendMessage(ctx context.Context, message *Message) (*Response, error)
+	}
+
+	// QueueResult is the outcome of a queued message. Key is the key it was enqueued with and
+	// Attempts the number of times it was sent, including retries after throttling.
+	QueueResult struct {
+		Key      string
+		Message  *Message
+		Response *Response
+		Err      error
+		Attempts int
+	}
+
+	// QueueResultHandler is called with the result of every queued message.
+	QueueResultHandler func(ctx context.Context, result *QueueResult)
+
+	// Queue sends messages in the background paced to the throughput of the phone number.
+	// Messages rejected with a throttling error are retried with exponential backoff, other
+	// failures are reported as they are. Results are delivered to the handler set with
+	// WithQueueResultHandler and the channel set with WithQueueResults.
+	Queue struct {
+		sender      MessageSender
+		interval    time.Duration
+		workers     int
+		maxAttempts int
+		backoff     time.Duration
+		maxBackoff  time.Duration
+		handler     QueueResultHandler
+		results     chan<- *QueueResult
+		items       chan *queueItem
+		closed      chan struct{}
+		closeOnce   sync.Once
+	}
+
+	QueueOption func(*Queue)
+
+	queueItem struct {
+		key     string
+		message *Message
+	}
+)
+
+// WithQueueThroughput sets the number of messages sent per second, upgraded phone numbers
+// can send up to 1000.
+func WithQueueThroughput(perSecond int) QueueOption {
+	return func(q *Queue) {
+		if perSecond > 0 {
+			q.interval = time.Second / time.Duration(perSecond)
+		}
+	}
+}
+
+// WithQueueWorkers sets the number of messages that can be in flight at once.
+func WithQueueWorkers(n int) QueueOption {
+	return func(q *Queue) {
+		if n > 0 {
+			q.workers = n
+		}
+	}
+}
+
+// WithQueueSize sets the number of messages that can wait in the queue.
+func WithQueueSize(n int) QueueOption {
+	return func(q *Queue) {
+		if n >= 0 {
+			q.items = make(chan *queueItem, n)
+		}
+	}
+}
+
+// WithQueueRetry sets how many times a throttled message is sent and the backoff between
+// attempts, which doubles after every attempt up to maxBackoff.
+func WithQueueRetry(maxAttempts int, backoff, maxBackoff time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.maxAttempts = maxAttempts
+		q.backoff = backoff
+		q.maxBackoff = maxBackoff
+	}
+}
+
+func WithQueueResultHandler(handler QueueResultHandler) QueueOption {
+	return func(q *Queue) {
+		q.handler = handler
+	}
+}
+
+// WithQueueResults sets a channel the results are sent to. Sending blocks, so the channel
+// must be drained while the queue runs.
+func WithQueueResults(results chan<- *QueueResult) QueueOption {
+	return func(q *Queue) {
+		q.results = results
+	}
+}
+
+// NewQueue creates a Queue that sends DefaultQueueThroughput messages per second with 16
+// workers, holds up to 1000 messages and sends a throttled message up to 5 times.
+func NewQueue(sender MessageSender, options ...QueueOption) *Queue {
+	queue := &Queue{
+		sender:      sender,
+		interval:    time.Second / DefaultQueueThroughput,
+		workers:     16,                          //nolint:mnd // default
+		maxAttempts: 5,                           //nolint:mnd // default
+		backoff:     time.Second,                 //nolint:mnd // default
+		maxBackoff:  30 * time.Second,            //nolint:mnd // default
+		items:       make(chan *queueItem, 1000), //nolint:mnd // default
+		closed:      make(chan struct{}),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(queue)
+		}
+	}
+
+	return queue
+}
+
+// Enqueue adds message to the queue, blocking while it is full. The key is passed back in
+// the QueueResult so that results can be matched to messages.
+func (q *Queue) Enqueue(ctx context.Context, key string, message *Message) error {
+	select {
+	case <-q.closed:
+		return ErrQueueClosed
+	default:
+	}
+
+	select {
+	case q.items <- &queueItem{key: key, message: message}:
+		return nil
+	case <-q.closed:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return fmt.Errorf("enqueue message %s: %w", key, ctx.Err())
+	}
+}
+
+// TryEnqueue is like Enqueue but fails with ErrQueueFull instead of blocking.
+func (q *Queue) TryEnqueue(key string, message *Message) error {
+	select {
+	case <-q.closed:
+		return ErrQueueClosed
+	default:
+	}
+
+	select {
+	case q.items <- &queueItem{key: key, message: message}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Len returns the number of messages waiting to be sent.
+func (q *Queue) Len() int {
+	return len(q.items)
+}
+
+// Close stops the queue from accepting messages. Run sends the messages already queued
+// and returns.
+func (q *Queue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+}
+
+// Run sends queued messages until ctx is done or the queue is closed and drained. It
+// returns ctx.Err() when ctx ended it and nil otherwise.
+func (q *Queue) Run(ctx context.Context) error {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	for range q.workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			q.work(ctx, ticker.C)
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (q *Queue) work(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-q.items:
+			q.process(ctx, ticks, item)
+		case <-q.closed:
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item := <-q.items:
+					q.process(ctx, ticks, item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *Queue) process(ctx context.Context, ticks <-chan time.Time, item *queueItem) {
+	result := &QueueResult{Key: item.key, Message: item.message}
+
+	for {
+		select {
+		case <-ctx.Done():
+			result.Err = fmt.Errorf("send message %s: %w", item.key, ctx.Err())
+			q.report(ctx, result)
+
+			return
+		case <-ticks:
+		}
+
+		result.Attempts++
+		result.Response, result.Err = q.sender.SendMessage(ctx, item.message)
+		if result.Err == nil || !isThrottled(result.Err) || result.Attempts >= q.maxAttempts {
+			q.report(ctx, result)
+
+			return
+		}
+
+		timer := time.NewTimer(q.backoffFor(result.Attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			q.report(ctx, result)
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *Queue) backoffFor(attempt int) time.Duration {
+	backoff := q.backoff << (attempt - 1)
+	if backoff <= 0 || (q.maxBackoff > 0 && backoff > q.maxBackoff) {
+		return q.maxBackoff
+	}
+
+	return backoff
+}
+
+func (q *Queue) report(ctx context.Context, result *QueueResult) {
+	if q.handler != nil {
+		q.handler(ctx, result)
+	}
+
+	if q.results != nil {
+		select {
+		case q.results <- result:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// throttlingCodes are the error codes of messages rejected for exceeding the throughput,
+// spam or pair rate limits.
+var throttlingCodes = []int{4, 80007, 130429, 131048, 131056} //nolint:gochecknoglobals,mnd // API error codes
+
+func isThrottled(err error) bool {
+	if errors.Is(err, ratelimit.ErrRateLimited) {
+		return true
+	}
+
+	var apiErr *werrors.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return slices.Contains(throttlingCodes, apiErr.Code)
+}
@@ -0,0 +1,92 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+)
+
+type queueSender struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	failing map[string]error
+}
+
+func (s *queueSender) SendMessage(_ context.Context, msg *message.Message) (*message.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[msg.To]++
+	if err, ok := s.failing[msg.To]; ok && s.calls[msg.To] < 3 {
+		return nil, err
+	}
+
+	return &message.Response{Messages: []*message.ID{{ID: "wamid." + msg.To}}}, nil
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	sender := &queueSender{
+		calls: map[string]int{},
+		failing: map[string]error{
+			"throttled": &werrors.Error{Code: 130429, Message: "rate limit hit"},
+			"invalid":   &werrors.Error{Code: 131030, Message: "recipient not allowed"},
+		},
+	}
+
+	results := make(chan *message.QueueResult, 3)
+	queue := message.NewQueue(sender,
+		message.WithQueueThroughput(1000),
+		message.WithQueueWorkers(2),
+		message.WithQueueRetry(5, time.Millisecond, 5*time.Millisecond),
+		message.WithQueueResults(results),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, to := range []string{"ok", "throttled", "invalid"} {
+		if err := queue.Enqueue(ctx, to, &message.Message{To: to}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queue.Close()
+
+	if err := queue.Run(ctx); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	close(results)
+
+	attempts := map[string]int{}
+	for result := range results {
+		attempts[result.Key] = result.Attempts
+
+		var apiErr *werrors.Error
+		if result.Key == "invalid" && !errors.As(result.Err, &apiErr) {
+			t.Errorf("expected the api error for %q, got %v", result.Key, result.Err)
+		}
+
+		if result.Key != "invalid" && result.Err != nil {
+			t.Errorf("unexpected error for %q: %v", result.Key, result.Err)
+		}
+	}
+
+	want := map[string]int{"ok": 1, "throttled": 3, "invalid": 1}
+	if diff := gcmp.Diff(want, attempts); diff != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := queue.Enqueue(ctx, "late", &message.Message{To: "late"}); !errors.Is(err, message.ErrQueueClosed) {
+		t.Errorf("expected %v, got %v", message.ErrQueueClosed, err)
+	}
+}
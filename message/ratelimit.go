@@ -0,0 +1,54 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/pkg/ratelimit"
+)
+
+// RateLimitMiddleware limits the messages sent through the client with limiter, keyed by the
+// phone number ID of the config and the recipient of the message. Status updates are not
+// limited. When wait is true the middleware blocks until the message is allowed, otherwise
+// it fails with an error wrapping ratelimit.ErrRateLimited.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, wait bool) SenderMiddleware {
+	return func(next SenderFunc) SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *BaseRequest) (*Response, error) {
+			if request.Type != whttp.RequestTypeSendMessage || request.Message == nil {
+				return next(ctx, conf, request)
+			}
+
+			check := limiter.Allow
+			if wait {
+				check = limiter.Wait
+			}
+
+			if err := check(ctx, conf.PhoneNumberID, request.Message.To); err != nil {
+				return nil, fmt.Errorf("send message to %s: %w", request.Message.To, err)
+			}
+
+			return next(ctx, conf, request)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// ReadReceiptsOptions controls how SendReadReceipts paces its requests. The Cloud API has
+	// no batch endpoint for read receipts so every message ID is a separate request.
+	//
+	// Interval is the minimum time between two requests, zero sends them back to back.
+	// StopOnError stops at the first failure instead of trying the remaining IDs.
+	ReadReceiptsOptions struct {
+		Interval    time.Duration
+		StopOnError bool
+	}
+
+	// ReadReceiptsResult lists the message IDs that were marked as read and the errors of
+	// the ones that failed. IDs that were not attempted because the context was canceled or
+	// StopOnError was set are in neither.
+	ReadReceiptsResult struct {
+		Read   []string
+		Failed map[string]error
+	}
+)
+
+var ErrReadReceiptsFailed = errors.New("failed to mark messages as read")
+
+// SendReadReceipts marks the inbound messages with the given IDs as read, one request at a
+// time. Duplicate and empty IDs are skipped. The returned error wraps ErrReadReceiptsFailed
+// and joins the errors of the failed IDs, the result is returned in both cases.
+func SendReadReceipts(ctx context.Context, updater StatusUpdater, messageIDs []string,
+	options *ReadReceiptsOptions,
+) (*ReadReceiptsResult, error) {
+	if options == nil {
+		options = &ReadReceiptsOptions{}
+	}
+
+	result := &ReadReceiptsResult{
+		Read:   make([]string, 0, len(messageIDs)),
+		Failed: make(map[string]error),
+	}
+
+	var ticker *time.Ticker
+	if options.Interval > 0 {
+		ticker = time.NewTicker(options.Interval)
+		defer ticker.Stop()
+	}
+
+	seen := make(map[string]struct{}, len(messageIDs))
+	var errs []error
+	for _, id := range messageIDs {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		if len(seen) > 1 && ticker != nil {
+			select {
+			case <-ctx.Done():
+				errs = append(errs, ctx.Err())
+
+				return result, fmt.Errorf("%w: %w", ErrReadReceiptsFailed, errors.Join(errs...))
+			case <-ticker.C:
+			}
+		}
+
+		response, err := updater.UpdateStatus(ctx, &StatusUpdateRequest{MessageID: id, Status: StatusRead})
+		if err == nil && !response.Success {
+			err = fmt.Errorf("message %s: status update not successful", id)
+		}
+
+		if err != nil {
+			result.Failed[id] = err
+			errs = append(errs, err)
+			if options.StopOnError || ctx.Err() != nil {
+				break
+			}
+
+			continue
+		}
+
+		result.Read = append(result.Read, id)
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("%w: %w", ErrReadReceiptsFailed, errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+// MarkAsRead marks the received message and the ones before it as read.
+func (c *BaseClient) MarkAsRead(ctx context.Context, messageID string) (*StatusUpdateResponse, error) {
+	return c.UpdateStatus(ctx, &StatusUpdateRequest{MessageID: messageID, Status: StatusRead})
+}
+
+// SendTypingIndicator marks the received message as read and shows the typing indicator
+// to the user while a reply is prepared.
+func (c *BaseClient) SendTypingIndicator(ctx context.Context, messageID string) (*StatusUpdateResponse, error) {
+	return c.UpdateStatus(ctx, &StatusUpdateRequest{MessageID: messageID, Status: StatusRead, TypingIndicator: true})
+}
+
+// MarkAsRead marks the received message and the ones before it as read.
+func (c *Client) MarkAsRead(ctx context.Context, messageID string) (*StatusUpdateResponse, error) {
+	return c.UpdateStatus(ctx, &StatusUpdateRequest{MessageID: messageID, Status: StatusRead})
+}
+
+// SendTypingIndicator marks the received message as read and shows the typing indicator
+// to the user while a reply is prepared.
+func (c *Client) SendTypingIndicator(ctx context.Context, messageID string) (*StatusUpdateResponse, error) {
+	return c.UpdateStatus(ctx, &StatusUpdateRequest{MessageID: messageID, Status: StatusRead, TypingIndicator: true})
+}
@@ -0,0 +1,57 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_TypingIndicator(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	sender := whttp.SenderFunc[message.Message](func(_ context.Context, req *whttp.Request[message.Message],
+		_ whttp.ResponseDecoder,
+	) error {
+		body, _ := json.Marshal(map[string]any{
+			"status":           req.Message.Status,
+			"message_id":       req.Message.MessageID,
+			"typing_indicator": req.Message.TypingIndicator,
+		})
+		sent = append(sent, req.Method+" "+string(body))
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{BaseURL: "https://graph.facebook.com", APIVersion: "v20.0"}, nil
+	})
+
+	client, err := message.NewBaseClient(sender, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.MarkAsRead(ctx, "wamid.1"); err != nil {
+		t.Fatalf("MarkAsRead() error = %v", err)
+	}
+
+	if _, err := client.SendTypingIndicator(ctx, "wamid.2"); err != nil {
+		t.Fatalf("SendTypingIndicator() error = %v", err)
+	}
+
+	want := []string{
+		`PUT {"message_id":"wamid.1","status":"read","typing_indicator":null}`,
+		`PUT {"message_id":"wamid.2","status":"read","typing_indicator":{"type":"text"}}`,
+	}
+	for i := range want {
+		if i >= len(sent) || sent[i] != want[i] {
+			t.Errorf("request %d = %v, want %s", i, sent, want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestReplyTo(t *testing.T) {
+	t.Parallel()
+
+	var sent *message.Message
+	sender := whttp.SenderFunc[message.Message](func(_ context.Context, req *whttp.Request[message.Message],
+		decoder whttp.ResponseDecoder,
+	) error {
+		sent = req.Message
+
+		return nil
+	})
+
+	reader := config.ReaderFunc(func(context.Context) (*config.Config, error) {
+		return &config.Config{BaseURL: "https://graph.facebook.com", APIVersion: "v20.0"}, nil
+	})
+
+	client, err := message.NewBaseClient(sender, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		send    func() error
+		wantErr error
+		wantID  string
+	}{
+		{
+			name: "text with reply id",
+			send: func() error {
+				_, err := client.SendText(context.Background(),
+					message.NewRequest("255700000000", &message.Text{Body: "hi"}, "wamid.HBgL"))
+
+				return err
+			},
+			wantID: "wamid.HBgL",
+		},
+		{
+			name: "image with ReplyTo option",
+			send: func() error {
+				_, err := client.SendImage(context.Background(),
+					message.NewRequest("255700000000", &message.Image{ID: "1"}, "", message.ReplyTo("wamid.IMG")))
+
+				return err
+			},
+			wantID: "wamid.IMG",
+		},
+		{
+			name: "template with invalid reply id",
+			send: func() error {
+				_, err := client.SendTemplate(context.Background(),
+					message.NewRequest("255700000000", &message.Template{Name: "hello"}, "1234"))
+
+				return err
+			},
+			wantErr: message.ErrInvalidReplyTo,
+		},
+	}
+
+	for _, tt := range tests {
+		sent = nil
+		err := tt.send()
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		payload, _ := json.Marshal(sent)
+		var decoded struct {
+			Context struct {
+				MessageID string `json:"message_id"`
+			} `json:"context"`
+		}
+		_ = json.Unmarshal(payload, &decoded)
+		if decoded.Context.MessageID != tt.wantID {
+			t.Errorf("%s: context.message_id = %q, want %q", tt.name, decoded.Context.MessageID, tt.wantID)
+		}
+	}
+}
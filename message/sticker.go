@@ -0,0 +1,38 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidStickerMessage = errors.New("invalid sticker message")
+
+// NewStickerMessage returns a message sending sticker to recipient. The sticker is either
+// an uploaded media ID or a link, exactly one of them must be set. Stickers are
+// checked before they are uploaded with the media/sticker package.
+func NewStickerMessage(recipient string, sticker *Sticker) (*Message, error) {
+	if sticker == nil || (sticker.ID == "") == (sticker.Link == "") {
+		return nil, fmt.Errorf("%w: exactly one of id and link must be set", ErrInvalidStickerMessage)
+	}
+
+	return New(recipient, WithSticker(sticker))
+}
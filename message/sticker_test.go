@@ -0,0 +1,28 @@
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestNewStickerMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := message.NewStickerMessage("255700000001", &message.Sticker{ID: "sticker-id"})
+	if err != nil {
+		t.Fatalf("NewStickerMessage() error = %v", err)
+	}
+
+	if msg.Type != message.TypeSticker || msg.Sticker.ID != "sticker-id" || msg.To != "255700000001" {
+		t.Errorf("NewStickerMessage() = %+v", msg)
+	}
+
+	for _, sticker := range []*message.Sticker{nil, {}, {ID: "id", Link: "https://example.com/s.webp"}} {
+		if _, err := message.NewStickerMessage("255700000001", sticker); !errors.Is(err,
+			message.ErrInvalidStickerMessage) {
+			t.Errorf("NewStickerMessage(%+v) error = %v, want ErrInvalidStickerMessage", sticker, err)
+		}
+	}
+}
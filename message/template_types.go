@@ -0,0 +1,208 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TemplateCategory is the category a template is approved under.
+type TemplateCategory string
+
+const (
+	TemplateCategoryMarketing      TemplateCategory = "MARKETING"
+	TemplateCategoryUtility        TemplateCategory = "UTILITY"
+	TemplateCategoryAuthentication TemplateCategory = "AUTHENTICATION"
+)
+
+// TemplateButtonType is the type of a button defined on a template.
+type TemplateButtonType string
+
+const (
+	TemplateButtonTypeQuickReply  TemplateButtonType = "QUICK_REPLY"
+	TemplateButtonTypeURL         TemplateButtonType = "URL"
+	TemplateButtonTypePhoneNumber TemplateButtonType = "PHONE_NUMBER"
+	TemplateButtonTypeCopyCode    TemplateButtonType = "COPY_CODE"
+	TemplateButtonTypeFlow        TemplateButtonType = "FLOW"
+	TemplateButtonTypeOTP         TemplateButtonType = "OTP"
+)
+
+// TemplateHeaderFormat is the format of a template header.
+type TemplateHeaderFormat string
+
+const (
+	TemplateHeaderFormatText     TemplateHeaderFormat = "TEXT"
+	TemplateHeaderFormatImage    TemplateHeaderFormat = "IMAGE"
+	TemplateHeaderFormatVideo    TemplateHeaderFormat = "VIDEO"
+	TemplateHeaderFormatDocument TemplateHeaderFormat = "DOCUMENT"
+	TemplateHeaderFormatLocation TemplateHeaderFormat = "LOCATION"
+)
+
+var (
+	ErrInvalidTemplateCategory     = errors.New("invalid template category")
+	ErrInvalidTemplateButtonType   = errors.New("invalid template button type")
+	ErrInvalidTemplateHeaderFormat = errors.New("invalid template header format")
+	ErrInvalidTemplate             = errors.New("invalid template")
+)
+
+// ParseTemplateCategory parses a category, ignoring case and surrounding spaces.
+func ParseTemplateCategory(s string) (TemplateCategory, error) {
+	c := TemplateCategory(strings.ToUpper(strings.TrimSpace(s)))
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	return c, nil
+}
+
+func (c TemplateCategory) Validate() error {
+	switch c {
+	case TemplateCategoryMarketing, TemplateCategoryUtility, TemplateCategoryAuthentication:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidTemplateCategory, string(c))
+	}
+}
+
+func (c TemplateCategory) String() string {
+	return string(c)
+}
+
+// ParseTemplateButtonType parses a button type, ignoring case and surrounding spaces.
+func ParseTemplateButtonType(s string) (TemplateButtonType, error) {
+	t := TemplateButtonType(strings.ToUpper(strings.TrimSpace(s)))
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+
+	return t, nil
+}
+
+func (t TemplateButtonType) Validate() error {
+	switch t {
+	case TemplateButtonTypeQuickReply, TemplateButtonTypeURL, TemplateButtonTypePhoneNumber,
+		TemplateButtonTypeCopyCode, TemplateButtonTypeFlow, TemplateButtonTypeOTP:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidTemplateButtonType, string(t))
+	}
+}
+
+func (t TemplateButtonType) String() string {
+	return string(t)
+}
+
+// ParseTemplateHeaderFormat parses a header format, ignoring case and surrounding spaces.
+func ParseTemplateHeaderFormat(s string) (TemplateHeaderFormat, error) {
+	f := TemplateHeaderFormat(strings.ToUpper(strings.TrimSpace(s)))
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+
+	return f, nil
+}
+
+func (f TemplateHeaderFormat) Validate() error {
+	switch f {
+	case TemplateHeaderFormatText, TemplateHeaderFormatImage, TemplateHeaderFormatVideo,
+		TemplateHeaderFormatDocument, TemplateHeaderFormatLocation:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidTemplateHeaderFormat, string(f))
+	}
+}
+
+func (f TemplateHeaderFormat) String() string {
+	return string(f)
+}
+
+// IsMedia reports whether the header carries an image, video or document.
+func (f TemplateHeaderFormat) IsMedia() bool {
+	return f == TemplateHeaderFormatImage || f == TemplateHeaderFormatVideo || f == TemplateHeaderFormatDocument
+}
+
+// Validate checks the typed fields of a template definition and the combinations Meta
+// rejects: carousel cards need an image or video header, flow buttons need a flow ID and
+// authentication templates only allow OTP, copy code and URL buttons.
+func (t *Template) Validate() error {
+	var errs []error
+	if t.Category != "" {
+		if err := t.Category.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, component := range t.Components {
+		if component == nil {
+			continue
+		}
+
+		for _, button := range component.Buttons {
+			errs = append(errs, t.validateButton(button)...)
+		}
+
+		for i, card := range component.Cards {
+			if card == nil {
+				continue
+			}
+
+			if card.Header != nil && card.Header.Format != TemplateHeaderFormatImage &&
+				card.Header.Format != TemplateHeaderFormatVideo {
+				errs = append(errs, fmt.Errorf("%w: card %d: header format %q, want IMAGE or VIDEO",
+					ErrInvalidTemplateHeaderFormat, i, string(card.Header.Format)))
+			}
+
+			for _, button := range card.Buttons {
+				errs = append(errs, t.validateButton(button)...)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidTemplate, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (t *Template) validateButton(button *TemplateButton) []error {
+	if button == nil {
+		return nil
+	}
+
+	var errs []error
+	if err := button.Type.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if button.Type == TemplateButtonTypeFlow && button.FlowID == "" {
+		errs = append(errs, fmt.Errorf("%w: flow button %q has no flow id", ErrInvalidTemplate, button.Text))
+	}
+
+	if t.Category == TemplateCategoryAuthentication && button.Type != TemplateButtonTypeOTP &&
+		button.Type != TemplateButtonTypeCopyCode && button.Type != TemplateButtonTypeURL {
+		errs = append(errs, fmt.Errorf("%w: %s button not allowed in authentication templates",
+			ErrInvalidTemplateButtonType, string(button.Type)))
+	}
+
+	return errs
+}
@@ -0,0 +1,135 @@
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+)
+
+func TestTemplateValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template *message.Template
+		wantErr  error
+	}{
+		{
+			name: "valid carousel",
+			template: &message.Template{
+				Category: message.TemplateCategoryMarketing,
+				Components: []*message.TemplateComponent{{
+					Type: message.TemplateComponentTypeCarousel,
+					Cards: []*message.MediaCard{{
+						Header:  &message.MediaCardHeader{Format: message.TemplateHeaderFormatImage},
+						Buttons: []*message.TemplateButton{{Type: message.TemplateButtonTypeQuickReply}},
+					}},
+				}},
+			},
+		},
+		{
+			name:     "unknown category",
+			template: &message.Template{Category: "PROMO"},
+			wantErr:  message.ErrInvalidTemplateCategory,
+		},
+		{
+			name: "carousel card with text header",
+			template: &message.Template{
+				Components: []*message.TemplateComponent{{
+					Cards: []*message.MediaCard{{
+						Header: &message.MediaCardHeader{Format: message.TemplateHeaderFormatText},
+					}},
+				}},
+			},
+			wantErr: message.ErrInvalidTemplateHeaderFormat,
+		},
+		{
+			name: "flow button without flow id",
+			template: &message.Template{
+				Components: []*message.TemplateComponent{{
+					Buttons: []*message.TemplateButton{{Type: message.TemplateButtonTypeFlow, Text: "Book"}},
+				}},
+			},
+			wantErr: message.ErrInvalidTemplate,
+		},
+		{
+			name: "quick reply in authentication template",
+			template: &message.Template{
+				Category: message.TemplateCategoryAuthentication,
+				Components: []*message.TemplateComponent{{
+					Buttons: []*message.TemplateButton{{Type: message.TemplateButtonTypeQuickReply}},
+				}},
+			},
+			wantErr: message.ErrInvalidTemplateButtonType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.template.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseTemplateButtonType(t *testing.T) {
+	t.Parallel()
+	got, err := message.ParseTemplateButtonType(" copy_code ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != message.TemplateButtonTypeCopyCode {
+		t.Errorf("got %q, want %q", got, message.TemplateButtonTypeCopyCode)
+	}
+
+	if _, err := message.ParseTemplateButtonType("call"); !errors.Is(err, message.ErrInvalidTemplateButtonType) {
+		t.Errorf("expected ErrInvalidTemplateButtonType, got %v", err)
+	}
+}
+
+func TestNewProfileTemplateMessage(t *testing.T) {
+	t.Parallel()
+
+	profile := &config.Profile{
+		Name:            "support",
+		PhoneNumberID:   "support-phone",
+		DefaultTemplate: &config.TemplateRef{Name: "ticket_update", Language: "en_US"},
+	}
+
+	body := &message.TemplateComponent{
+		Type:       message.TemplateComponentTypeBody,
+		Parameters: []*message.TemplateParameter{{Type: message.TemplateParameterTypeText, Text: "T-42"}},
+	}
+
+	msg, err := message.NewProfileTemplateMessage(profile, "255700000001", body)
+	if err != nil {
+		t.Fatalf("NewProfileTemplateMessage() error = %v", err)
+	}
+
+	want := &message.Template{
+		Name:       "ticket_update",
+		Language:   &message.TemplateLanguage{Code: "en_US", Policy: message.TemplateLanguagePolicyDeterministic},
+		Components: []*message.TemplateComponent{body},
+	}
+	if diff := gcmp.Diff(want, msg.Template); diff != "" {
+		t.Errorf("template mismatch (-want +got):\n%s", diff)
+	}
+
+	profile.DefaultTemplate = nil
+	if _, err := message.NewProfileTemplateMessage(profile, "255700000001"); !errors.Is(err,
+		message.ErrNoDefaultTemplate) {
+		t.Errorf("NewProfileTemplateMessage() error = %v, want ErrNoDefaultTemplate", err)
+	}
+}
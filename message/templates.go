@@ -19,6 +19,12 @@
 
 package message
 
+import (
+	"errors"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
 const (
 	TemplateComponentTypeCarousel         = "carousel"
 	TemplateComponentTypeHeader           = "header"
@@ -36,22 +42,24 @@ const (
 	TemplateComponentTypeLimitedTimeOffer = "limited_time_offer"
 	TemplateButtonSubTypeCopyCode         = "copy_code"
 	TemplateButtonSubTypeURL              = "url"
+	TemplateButtonSubTypeQuickReply       = "quick_reply"
+	TemplateParameterTypeCouponCode       = "coupon_code"
 )
 
 type (
 	TemplateButton struct {
-		Type           string `json:"type,omitempty"`
-		Payload        string `json:"payload,omitempty"`
-		Text           string `json:"text,omitempty"`
-		FlowID         string `json:"flow_id"`
-		NavigateScreen string `json:"navigate_screen"`
-		FlowAction     string `json:"flow_action"`
+		Type           TemplateButtonType `json:"type,omitempty"`
+		Payload        string             `json:"payload,omitempty"`
+		Text           string             `json:"text,omitempty"`
+		FlowID         string             `json:"flow_id"`
+		NavigateScreen string             `json:"navigate_screen"`
+		FlowAction     string             `json:"flow_action"`
 	}
 
 	Template struct {
 		Name       string               `json:"name,omitempty"`
 		Language   *TemplateLanguage    `json:"language,omitempty"`
-		Category   string               `json:"category,omitempty"`
+		Category   TemplateCategory     `json:"category,omitempty"`
 		Components []*TemplateComponent `json:"components,omitempty"`
 	}
 
@@ -70,8 +78,11 @@ type (
 		Cards      []*MediaCard         `json:"cards,omitempty"`
 	}
 
+	// TemplateParameter is the value of a template variable. ParameterName is set for
+	// templates created with named parameters.
 	TemplateParameter struct {
 		Type             string            `json:"type"`
+		ParameterName    string            `json:"parameter_name,omitempty"`
 		Text             string            `json:"text"`
 		Payload          string            `json:"payload,omitempty"`
 		Currency         *TemplateCurrency `json:"currency"`
@@ -111,11 +122,11 @@ type (
 	}
 
 	TemplateFlowButton struct {
-		Type           string `json:"type"`
-		Text           string `json:"text"`
-		FlowID         string `json:"flow_id"`
-		NavigateScreen string `json:"navigate_screen"`
-		FlowAction     string `json:"flow_action"`
+		Type           TemplateButtonType `json:"type"`
+		Text           string             `json:"text"`
+		FlowID         string             `json:"flow_id"`
+		NavigateScreen string             `json:"navigate_screen"`
+		FlowAction     string             `json:"flow_action"`
 	}
 )
 
@@ -126,6 +137,29 @@ func WithTemplateMessage(tmpl *Template) Option {
 	}
 }
 
+// ErrNoDefaultTemplate is returned when sending the default template of a profile that
+// has none.
+var ErrNoDefaultTemplate = errors.New("profile has no default template")
+
+// NewProfileTemplateMessage returns the message sending the default template of profile
+// to recipient. components fill the variables of the template.
+func NewProfileTemplateMessage(profile *config.Profile, recipient string,
+	components ...*TemplateComponent,
+) (*Message, error) {
+	if profile == nil || profile.DefaultTemplate == nil || profile.DefaultTemplate.Name == "" {
+		return nil, ErrNoDefaultTemplate
+	}
+
+	return New(recipient, WithTemplateMessage(&Template{
+		Name: profile.DefaultTemplate.Name,
+		Language: &TemplateLanguage{
+			Code:   profile.DefaultTemplate.Language,
+			Policy: TemplateLanguagePolicyDeterministic,
+		},
+		Components: components,
+	}))
+}
+
 func NewInteractiveTemplate(name string, language *TemplateLanguage, headers []*TemplateParameter,
 	bodies []*TemplateParameter, buttons []*InteractiveButtonTemplate,
 ) *Template {
@@ -149,7 +183,7 @@ func NewInteractiveTemplate(name string, language *TemplateLanguage, headers []*
 			Index:   button.Index,
 			Parameters: []*TemplateParameter{
 				{
-					Type:    button.Button.Type,
+					Type:    string(button.Button.Type),
 					Text:    button.Button.Text,
 					Payload: button.Button.Payload,
 				},
@@ -213,7 +247,7 @@ type (
 		Language *TemplateLanguage
 		BodyText string
 		Cards    []*MediaCard
-		Category string
+		Category TemplateCategory
 	}
 
 	MediaCard struct {
@@ -223,8 +257,8 @@ type (
 	}
 
 	MediaCardHeader struct {
-		Format string `json:"format"`
-		Handle string `json:"handle"`
+		Format TemplateHeaderFormat `json:"format"`
+		Handle string               `json:"handle"`
 	}
 
 	MediaCardBody struct {
@@ -334,14 +368,18 @@ func NewURLButton(params *ButtonParams) *TemplateComponent {
 }
 
 func NewLimitedTimeOfferTemplate(req *LimitedTimeOfferTemplateRequest) *Template {
-	components := []*TemplateComponent{
-		req.HeaderComponent,
-		{
+	var components []*TemplateComponent
+	if req.HeaderComponent != nil {
+		components = append(components, req.HeaderComponent)
+	}
+
+	components = append(components,
+		&TemplateComponent{
 			Type:       TemplateComponentTypeBody,
 			Parameters: req.Body,
 		},
 		NewTemplateComponentLimitedTimeOffer(req.ExpirationTime),
-	}
+	)
 
 	urlButtonIndex := 0
 	if req.CouponCode != nil {
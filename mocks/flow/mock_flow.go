@@ -154,6 +154,21 @@ func (mr *MockServiceMockRecorder) Get(ctx, request any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, request)
 }
 
+// GetBusinessPublicKey mocks base method.
+func (m *MockService) GetBusinessPublicKey(ctx context.Context) (*flow.BusinessPublicKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBusinessPublicKey", ctx)
+	ret0, _ := ret[0].(*flow.BusinessPublicKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBusinessPublicKey indicates an expected call of GetBusinessPublicKey.
+func (mr *MockServiceMockRecorder) GetBusinessPublicKey(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBusinessPublicKey", reflect.TypeOf((*MockService)(nil).GetBusinessPublicKey), ctx)
+}
+
 // GetFlowMetrics mocks base method.
 func (m *MockService) GetFlowMetrics(ctx context.Context, request *flow.MetricsRequest) (*flow.MetricsAPIResponse, error) {
 	m.ctrl.T.Helper()
@@ -214,6 +229,21 @@ func (mr *MockServiceMockRecorder) Publish(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockService)(nil).Publish), ctx, id)
 }
 
+// SetBusinessPublicKey mocks base method.
+func (m *MockService) SetBusinessPublicKey(ctx context.Context, publicKeyPEM string) (*flow.SuccessResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBusinessPublicKey", ctx, publicKeyPEM)
+	ret0, _ := ret[0].(*flow.SuccessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetBusinessPublicKey indicates an expected call of SetBusinessPublicKey.
+func (mr *MockServiceMockRecorder) SetBusinessPublicKey(ctx, publicKeyPEM any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBusinessPublicKey", reflect.TypeOf((*MockService)(nil).SetBusinessPublicKey), ctx, publicKeyPEM)
+}
+
 // Update mocks base method.
 func (m *MockService) Update(ctx context.Context, id string, request flow.UpdateRequest) (*flow.UpdateResponse, error) {
 	m.ctrl.T.Helper()
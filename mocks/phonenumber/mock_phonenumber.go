@@ -79,6 +79,20 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// DeleteWebhookOverride mocks base method.
+func (m *MockService) DeleteWebhookOverride(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookOverride", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookOverride indicates an expected call of DeleteWebhookOverride.
+func (mr *MockServiceMockRecorder) DeleteWebhookOverride(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookOverride", reflect.TypeOf((*MockService)(nil).DeleteWebhookOverride), ctx)
+}
+
 // Get mocks base method.
 func (m *MockService) Get(ctx context.Context, request *phonenumber.GetRequest) (*phonenumber.PhoneNumber, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +108,36 @@ func (mr *MockServiceMockRecorder) Get(ctx, request any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, request)
 }
 
+// GetSettings mocks base method.
+func (m *MockService) GetSettings(ctx context.Context, req *phonenumber.GetSettingsRequest) (*phonenumber.Settings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, req)
+	ret0, _ := ret[0].(*phonenumber.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockServiceMockRecorder) GetSettings(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockService)(nil).GetSettings), ctx, req)
+}
+
+// GetWebhookConfiguration mocks base method.
+func (m *MockService) GetWebhookConfiguration(ctx context.Context) (*phonenumber.WebhookConfiguration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookConfiguration", ctx)
+	ret0, _ := ret[0].(*phonenumber.WebhookConfiguration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookConfiguration indicates an expected call of GetWebhookConfiguration.
+func (mr *MockServiceMockRecorder) GetWebhookConfiguration(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookConfiguration", reflect.TypeOf((*MockService)(nil).GetWebhookConfiguration), ctx)
+}
+
 // List mocks base method.
 func (m *MockService) List(ctx context.Context) (*phonenumber.ListResponse, error) {
 	m.ctrl.T.Helper()
@@ -108,3 +152,31 @@ func (mr *MockServiceMockRecorder) List(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx)
 }
+
+// SetWebhookOverride mocks base method.
+func (m *MockService) SetWebhookOverride(ctx context.Context, req *phonenumber.WebhookOverrideRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookOverride", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookOverride indicates an expected call of SetWebhookOverride.
+func (mr *MockServiceMockRecorder) SetWebhookOverride(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookOverride", reflect.TypeOf((*MockService)(nil).SetWebhookOverride), ctx, req)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockService) UpdateSettings(ctx context.Context, settings *phonenumber.Settings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockServiceMockRecorder) UpdateSettings(ctx, settings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockService)(nil).UpdateSettings), ctx, settings)
+}
@@ -0,0 +1,217 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package onboarding implements the server side of the embedded signup flow used by Tech
+// Providers to onboard businesses, including those in coexistence with the WhatsApp
+// Business app.
+//
+// The embedded signup returns an authorization code to the browser. The server exchanges
+// it for a business token, inspects the token to find the WhatsApp Business Accounts the
+// business shared, and subscribes the app to their webhooks:
+//
+//	client := onboarding.NewClient(conf, sender)
+//	result, err := client.Onboard(ctx, code)
+//	if err != nil {
+//		return err
+//	}
+//	// store result.Token.AccessToken and result.BusinessAccountIDs
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/piusalfred/whatsapp/auth"
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/subscription"
+)
+
+const (
+	// ScopeBusinessManagement is the scope whose targets are the shared business accounts.
+	ScopeBusinessManagement = "whatsapp_business_management"
+
+	// ScopeBusinessMessaging is the scope whose targets are the accounts the token can
+	// send messages from.
+	ScopeBusinessMessaging = "whatsapp_business_messaging"
+)
+
+var (
+	ErrExchangeCode       = errors.New("failed to exchange authorization code")
+	ErrMissingCode        = errors.New("authorization code is required")
+	ErrMissingCredentials = errors.New("app id and app secret are required")
+	ErrInvalidToken       = errors.New("business token is not valid")
+	ErrNoSharedAccounts   = errors.New("no whatsapp business account was shared")
+	ErrSubscribeApp       = errors.New("failed to subscribe app")
+)
+
+type (
+	// BusinessToken is the token of the business that completed the embedded signup.
+	BusinessToken struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type,omitempty"`
+		ExpiresIn   int64  `json:"expires_in,omitempty"`
+	}
+
+	// Result is the outcome of Onboard.
+	Result struct {
+		Token              *BusinessToken
+		TokenInfo          *auth.TokenInfo
+		BusinessAccountIDs []string
+	}
+
+	// Client calls the endpoints of the onboarding flow on behalf of the app described by
+	// the Config. Only BaseURL, APIVersion, AppID and AppSecret are used, the tokens are
+	// passed per call.
+	Client struct {
+		conf   *config.Config
+		sender whttp.AnySender
+		auth   *auth.Client
+	}
+)
+
+func NewClient(conf *config.Config, sender whttp.AnySender) *Client {
+	return &Client{
+		conf:   conf,
+		sender: sender,
+		auth:   auth.NewClient(conf.BaseURL, conf.APIVersion, sender),
+	}
+}
+
+// ExchangeCode exchanges the authorization code returned by the embedded signup for a
+// business token.
+func (c *Client) ExchangeCode(ctx context.Context, code string) (*BusinessToken, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: %w", ErrExchangeCode, ErrMissingCode)
+	}
+
+	if c.conf.AppID == "" || c.conf.AppSecret == "" {
+		return nil, fmt.Errorf("%w: %w", ErrExchangeCode, ErrMissingCredentials)
+	}
+
+	req := whttp.MakeRequest[any](http.MethodGet, c.conf.BaseURL,
+		whttp.WithRequestType[any](whttp.RequestTypeExchangeCode),
+		whttp.WithRequestEndpoints[any](c.conf.APIVersion, "oauth", "access_token"),
+		whttp.WithRequestQueryParams[any](map[string]string{
+			"client_id":     c.conf.AppID,
+			"client_secret": c.conf.AppSecret,
+			"code":          code,
+		}),
+	)
+
+	token := &BusinessToken{}
+	decoder := whttp.ResponseDecoderJSON(token, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := c.sender.Send(ctx, req, decoder); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchangeCode, err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: empty access token", ErrExchangeCode)
+	}
+
+	return token, nil
+}
+
+// DebugToken inspects token with /debug_token, authenticated with the app access token.
+func (c *Client) DebugToken(ctx context.Context, token string) (*auth.TokenInfo, error) {
+	return c.auth.DebugToken(ctx, auth.DebugTokenParams{
+		InputToken:  token,
+		AccessToken: c.conf.AppID + "|" + c.conf.AppSecret,
+	})
+}
+
+// SharedAccountIDs returns the ids of the WhatsApp Business Accounts the token was granted
+// access to, in the order they are listed, without duplicates.
+func SharedAccountIDs(info *auth.TokenInfo) []string {
+	var ids []string
+	for _, scope := range info.GranularScopes {
+		if scope.Scope != ScopeBusinessManagement && scope.Scope != ScopeBusinessMessaging {
+			continue
+		}
+
+		for _, id := range scope.TargetIDs {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// SubscribeApp subscribes the app to the webhooks of the business account, using the
+// business token.
+func (c *Client) SubscribeApp(ctx context.Context, token, businessAccountID string) error {
+	conf := *c.conf
+	conf.AccessToken = token
+	conf.BusinessAccountID = businessAccountID
+
+	sender := &subscription.BaseSender{Sender: c.sender}
+	if _, err := subscription.Subscribe(ctx, sender, &conf, nil); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrSubscribeApp, businessAccountID, err)
+	}
+
+	return nil
+}
+
+// Onboard exchanges the code, checks the business token, and subscribes the app to every
+// shared business account. On error the returned Result holds what was done so far, so the
+// token is not lost when a subscription fails.
+func (c *Client) Onboard(ctx context.Context, code string) (*Result, error) {
+	token, err := c.ExchangeCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Token: token}
+
+	info, err := c.DebugToken(ctx, token.AccessToken)
+	if err != nil {
+		return result, err
+	}
+
+	result.TokenInfo = info
+
+	if !info.IsValid {
+		return result, ErrInvalidToken
+	}
+
+	ids := SharedAccountIDs(info)
+	if len(ids) == 0 {
+		return result, ErrNoSharedAccounts
+	}
+
+	for _, id := range ids {
+		if err := c.SubscribeApp(ctx, token.AccessToken, id); err != nil {
+			return result, err
+		}
+
+		result.BusinessAccountIDs = append(result.BusinessAccountIDs, id)
+	}
+
+	return result, nil
+}
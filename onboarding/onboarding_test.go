@@ -0,0 +1,80 @@
+package onboarding_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/onboarding"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestClient_Onboard(t *testing.T) {
+	t.Parallel()
+
+	var subscribed []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v20.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != "app" || q.Get("client_secret") != "secret" || q.Get("code") != "code-1" {
+			t.Errorf("unexpected exchange query: %v", q)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"business-token","token_type":"bearer"}`))
+	})
+	mux.HandleFunc("GET /v20.0/debug_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("input_token") != "business-token" || r.URL.Query().Get("access_token") != "app|secret" {
+			t.Errorf("unexpected debug query: %v", r.URL.Query())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"app_id":"app","is_valid":true,"granular_scopes":[
+{"scope":"whatsapp_business_management","target_ids":["waba-1","waba-2"]},
+{"scope":"whatsapp_business_messaging","target_ids":["waba-2"]},
+{"scope":"business_management","target_ids":["biz-1"]}]}}`))
+	})
+	mux.HandleFunc("POST /v20.0/{waba}/subscribed_apps", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer business-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+
+		subscribed = append(subscribed, r.PathValue("waba"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	conf := &config.Config{BaseURL: server.URL, APIVersion: "v20.0", AppID: "app", AppSecret: "secret"}
+	client := onboarding.NewClient(conf, whttp.NewAnySender())
+
+	result, err := client.Onboard(context.Background(), "code-1")
+	if err != nil {
+		t.Fatalf("Onboard() error = %v", err)
+	}
+
+	want := []string{"waba-1", "waba-2"}
+	if diff := gcmp.Diff(want, result.BusinessAccountIDs); diff != "" {
+		t.Errorf("business accounts mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff(want, subscribed); diff != "" {
+		t.Errorf("subscriptions mismatch (-want +got):\n%s", diff)
+	}
+
+	if result.Token.AccessToken != "business-token" {
+		t.Errorf("token = %q", result.Token.AccessToken)
+	}
+
+	if _, err := client.Onboard(context.Background(), ""); !errors.Is(err, onboarding.ErrMissingCode) {
+		t.Errorf("Onboard(\"\") error = %v, want %v", err, onboarding.ErrMissingCode)
+	}
+}
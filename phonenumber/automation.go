@@ -0,0 +1,200 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package phonenumber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// Limits of the conversational components.
+const (
+	MaxPrompts                = 4
+	MaxPromptLength           = 80
+	MaxCommands               = 30
+	MaxCommandNameLength      = 32
+	MaxCommandDescriptionSize = 256
+)
+
+// FieldConversationalAutomation requests the conversational components of the phone number.
+const FieldConversationalAutomation = "conversational_automation"
+
+type (
+	// ConversationalAutomation is the configuration of the conversational components of a
+	// phone number. When EnableWelcomeMessage is set, a request_welcome message webhook is
+	// sent when a user opens a chat with the business for the first time. Prompts are the
+	// ice breakers shown to users in a new chat and Commands the slash commands they can
+	// pick from.
+	ConversationalAutomation struct {
+		EnableWelcomeMessage bool       `json:"enable_welcome_message"`
+		Prompts              []string   `json:"prompts,omitempty"`
+		Commands             []*Command `json:"commands,omitempty"`
+	}
+
+	// Command is a command users can send by typing "/" followed by its Name.
+	Command struct {
+		Name        string `json:"command_name"`
+		Description string `json:"command_description"`
+	}
+)
+
+// Validate checks the configuration against the limits of the API: at most 4 prompts of
+// 80 characters, at most 30 commands with names of 32 characters and descriptions of 256.
+func (a *ConversationalAutomation) Validate() error {
+	var errs []error
+	if len(a.Prompts) > MaxPrompts {
+		errs = append(errs, fmt.Errorf("%d prompts, at most %d are allowed", len(a.Prompts), MaxPrompts))
+	}
+
+	for i, prompt := range a.Prompts {
+		if prompt == "" || utf8.RuneCountInString(prompt) > MaxPromptLength {
+			errs = append(errs, fmt.Errorf("prompt %d must have 1 to %d characters", i, MaxPromptLength))
+		}
+	}
+
+	if len(a.Commands) > MaxCommands {
+		errs = append(errs, fmt.Errorf("%d commands, at most %d are allowed", len(a.Commands), MaxCommands))
+	}
+
+	for i, command := range a.Commands {
+		if command == nil {
+			errs = append(errs, fmt.Errorf("command %d is nil", i))
+
+			continue
+		}
+
+		if command.Name == "" || utf8.RuneCountInString(command.Name) > MaxCommandNameLength {
+			errs = append(errs, fmt.Errorf("command %d: name must have 1 to %d characters", i,
+				MaxCommandNameLength))
+		}
+
+		if command.Description == "" || utf8.RuneCountInString(command.Description) > MaxCommandDescriptionSize {
+			errs = append(errs, fmt.Errorf("command %d: description must have 1 to %d characters", i,
+				MaxCommandDescriptionSize))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidAutomation, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// GetConversationalAutomation returns the conversational components of the phone number.
+func (c *BaseClient) GetConversationalAutomation(ctx context.Context) (*ConversationalAutomation, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return getConversationalAutomation(ctx, c.Sender, conf)
+}
+
+// UpdateConversationalAutomation replaces the conversational components of the phone
+// number. Empty Prompts and Commands remove the existing ones.
+func (c *BaseClient) UpdateConversationalAutomation(ctx context.Context, automation *ConversationalAutomation) error {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return updateConversationalAutomation(ctx, c.Sender, conf, automation)
+}
+
+func (c *Client) GetConversationalAutomation(ctx context.Context) (*ConversationalAutomation, error) {
+	return getConversationalAutomation(ctx, c.Sender, c.Config)
+}
+
+func (c *Client) UpdateConversationalAutomation(ctx context.Context, automation *ConversationalAutomation) error {
+	return updateConversationalAutomation(ctx, c.Sender, c.Config, automation)
+}
+
+func getConversationalAutomation(ctx context.Context, sender Sender, conf *config.Config,
+) (*ConversationalAutomation, error) {
+	request := &BaseRequest{
+		Type:   whttp.RequestTypeGetConversationalAutomation,
+		Method: http.MethodGet,
+		QueryParams: map[string]string{
+			"fields": FieldConversationalAutomation,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("get conversational automation: %w", err)
+	}
+
+	if response.ConversationalAutomation == nil {
+		return &ConversationalAutomation{}, nil
+	}
+
+	return response.ConversationalAutomation, nil
+}
+
+func updateConversationalAutomation(ctx context.Context, sender Sender, conf *config.Config,
+	automation *ConversationalAutomation,
+) error {
+	if err := automation.Validate(); err != nil {
+		return err
+	}
+
+	// Empty lists are sent as such, leaving them out would keep the existing ones.
+	body := struct {
+		EnableWelcomeMessage bool       `json:"enable_welcome_message"`
+		Prompts              []string   `json:"prompts"`
+		Commands             []*Command `json:"commands"`
+	}{
+		EnableWelcomeMessage: automation.EnableWelcomeMessage,
+		Prompts:              automation.Prompts,
+		Commands:             automation.Commands,
+	}
+
+	if body.Prompts == nil {
+		body.Prompts = []string{}
+	}
+
+	if body.Commands == nil {
+		body.Commands = []*Command{}
+	}
+
+	request := &BaseRequest{
+		Type:   whttp.RequestTypeUpdateConversationalAutomation,
+		Method: http.MethodPost,
+		Body:   body,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return fmt.Errorf("update conversational automation: %w", err)
+	}
+
+	if !response.Success {
+		return ErrAutomationNotUpdated
+	}
+
+	return nil
+}
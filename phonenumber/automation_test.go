@@ -0,0 +1,80 @@
+package phonenumber_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/phonenumber"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_ConversationalAutomation(t *testing.T) {
+	t.Parallel()
+
+	var posted map[string]any
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v20.0/phone/conversational_automation":
+			_ = json.NewDecoder(r.Body).Decode(&posted)
+			_, _ = w.Write([]byte(`{"success":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v20.0/phone" &&
+			r.URL.Query().Get("fields") == phonenumber.FieldConversationalAutomation:
+			_, _ = w.Write([]byte(`{"id":"phone","conversational_automation":{"enable_welcome_message":true,` +
+				`"prompts":["Book a flight"],"commands":[{"command_name":"tickets",` +
+				`"command_description":"Book flight tickets"}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	client, err := phonenumber.NewBaseClient(reader, &phonenumber.BaseSender{Sender: whttp.NewAnySender()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	got, err := client.GetConversationalAutomation(ctx)
+	if err != nil {
+		t.Fatalf("GetConversationalAutomation() error = %v", err)
+	}
+
+	want := &phonenumber.ConversationalAutomation{
+		EnableWelcomeMessage: true,
+		Prompts:              []string{"Book a flight"},
+		Commands:             []*phonenumber.Command{{Name: "tickets", Description: "Book flight tickets"}},
+	}
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("GetConversationalAutomation() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := client.UpdateConversationalAutomation(ctx, &phonenumber.ConversationalAutomation{
+		EnableWelcomeMessage: true,
+		Prompts:              []string{"Track my order"},
+	}); err != nil {
+		t.Fatalf("UpdateConversationalAutomation() error = %v", err)
+	}
+
+	wantBody := map[string]any{
+		"enable_welcome_message": true,
+		"prompts":                []any{"Track my order"},
+		"commands":               []any{},
+	}
+	if diff := gcmp.Diff(wantBody, posted); diff != "" {
+		t.Errorf("UpdateConversationalAutomation() body mismatch (-want +got):\n%s", diff)
+	}
+
+	err = client.UpdateConversationalAutomation(ctx, &phonenumber.ConversationalAutomation{
+		Prompts: []string{"1", "2", "3", "4", strings.Repeat("x", phonenumber.MaxPromptLength+1)},
+	})
+	if !errors.Is(err, phonenumber.ErrInvalidAutomation) {
+		t.Errorf("UpdateConversationalAutomation() error = %v, want ErrInvalidAutomation", err)
+	}
+}
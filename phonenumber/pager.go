@@ -0,0 +1,75 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package phonenumber
+
+import (
+	"context"
+	"strconv"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// Lister lists phone numbers page by page, it is implemented by BaseClient and Client.
+type Lister interface {
+	ListPage(ctx context.Context, req *ListRequest) (*ListResponse, error)
+}
+
+// NewPager creates a pager over the phone numbers of the business account. The Limit of
+// req sets the page size and its After cursor, when set, resumes a previous walk from that
+// page.
+func NewPager(lister Lister, req *ListRequest) *whttp.Pager[*PhoneNumber] {
+	var limit int
+	var after string
+	if req != nil {
+		limit, after = req.Limit, req.After
+	}
+
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*PhoneNumber], error) {
+		response, err := lister.ListPage(ctx, &ListRequest{Limit: limit, After: after})
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*PhoneNumber]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, after)
+}
+
+func (req *ListRequest) queryParams() map[string]string {
+	params := map[string]string{}
+	if req == nil {
+		return params
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.After != "" {
+		params["after"] = req.After
+	}
+
+	if req.Before != "" {
+		params["before"] = req.Before
+	}
+
+	return params
+}
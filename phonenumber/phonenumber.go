@@ -40,6 +40,37 @@ const (
 	NameStatusNone                   = "NONE"
 )
 
+// Status values of a phone number, only CONNECTED numbers can send messages.
+const (
+	StatusConnected    = "CONNECTED"
+	StatusPending      = "PENDING"
+	StatusDisconnected = "DISCONNECTED"
+	StatusFlagged      = "FLAGGED"
+	StatusRestricted   = "RESTRICTED"
+	StatusRateLimited  = "RATE_LIMITED"
+	StatusBanned       = "BANNED"
+	StatusDeleted      = "DELETED"
+	StatusMigrated     = "MIGRATED"
+	StatusUnverified   = "UNVERIFIED"
+	StatusOffline      = "OFFLINE"
+	StatusUnknown      = "UNKNOWN"
+)
+
+const (
+	PlatformTypeCloudAPI      = "CLOUD_API"
+	PlatformTypeOnPremise     = "ON_PREMISE"
+	PlatformTypeNotApplicable = "NOT_APPLICABLE"
+)
+
+const (
+	FieldIsOnBizApp         = "is_on_biz_app"
+	FieldPlatformType       = "platform_type"
+	FieldStatus             = "status"
+	FieldQualityRating      = "quality_rating"
+	FieldDisplayPhoneNumber = "display_phone_number"
+	FieldVerifiedName       = "verified_name"
+)
+
 type (
 	PhoneNumber struct {
 		ID                     string `json:"id"`
@@ -48,6 +79,9 @@ type (
 		QualityRating          string `json:"quality_rating"`
 		CodeVerificationStatus string `json:"code_verification_status,omitempty"`
 		NameStatus             string `json:"name_status,omitempty"`
+		IsOnBizApp             bool   `json:"is_on_biz_app,omitempty"`
+		PlatformType           string `json:"platform_type,omitempty"`
+		Status                 string `json:"status,omitempty"`
 	}
 
 	ListResponse struct {
@@ -55,24 +89,48 @@ type (
 		Paging *Paging        `json:"paging,omitempty"`
 	}
 
-	Paging struct {
-		Cursors *Cursors `json:"cursors"`
+	// ListRequest pages the phone numbers of the business account. Limit is the page size.
+	ListRequest struct {
+		Limit  int
+		After  string
+		Before string
 	}
 
-	Cursors struct {
-		Before string `json:"before"`
-		After  string `json:"after"`
-	}
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
 
 	Response struct {
-		Data                   []*PhoneNumber `json:"data,omitempty"`
-		CodeVerificationStatus string         `json:"code_verification_status,omitempty"`
-		DisplayPhoneNumber     string         `json:"display_phone_number,omitempty"`
-		ID                     string         `json:"id,omitempty"`
-		QualityRating          string         `json:"quality_rating,omitempty"`
-		VerifiedName           string         `json:"verified_name,omitempty"`
-		Paging                 *Paging        `json:"paging,omitempty"`
-		NameStatus             string         `json:"name_status,omitempty"`
+		Data                     []*PhoneNumber            `json:"data,omitempty"`
+		CodeVerificationStatus   string                    `json:"code_verification_status,omitempty"`
+		DisplayPhoneNumber       string                    `json:"display_phone_number,omitempty"`
+		ID                       string                    `json:"id,omitempty"`
+		QualityRating            string                    `json:"quality_rating,omitempty"`
+		VerifiedName             string                    `json:"verified_name,omitempty"`
+		Paging                   *Paging                   `json:"paging,omitempty"`
+		NameStatus               string                    `json:"name_status,omitempty"`
+		IsOnBizApp               bool                      `json:"is_on_biz_app,omitempty"`
+		PlatformType             string                    `json:"platform_type,omitempty"`
+		Status                   string                    `json:"status,omitempty"`
+		WebhookConfiguration     *WebhookConfiguration     `json:"webhook_configuration,omitempty"`
+		Success                  bool                      `json:"success,omitempty"`
+		Calling                  *CallingSettings          `json:"calling,omitempty"`
+		ConversationalAutomation *ConversationalAutomation `json:"conversational_automation,omitempty"`
+	}
+
+	// WebhookConfiguration lists the callback URLs that apply to a phone number, from the
+	// most specific to the least. Webhooks go to PhoneNumber when an override is set, then
+	// to WhatsAppBusinessAccount and finally to the Application callback.
+	WebhookConfiguration struct {
+		PhoneNumber             string `json:"phone_number,omitempty"`
+		WhatsAppBusinessAccount string `json:"whatsapp_business_account,omitempty"`
+		Application             string `json:"application,omitempty"`
+	}
+
+	// WebhookOverrideRequest sets an alternate callback URL for a single phone number.
+	// The URL is verified with VerifyToken before the override takes effect.
+	WebhookOverrideRequest struct {
+		CallbackURI string `json:"override_callback_uri"`
+		VerifyToken string `json:"verify_token,omitempty"`
 	}
 
 	BaseClient struct {
@@ -101,9 +159,18 @@ func (response *Response) PhoneNumber() *PhoneNumber {
 		QualityRating:          response.QualityRating,
 		CodeVerificationStatus: response.CodeVerificationStatus,
 		NameStatus:             response.NameStatus,
+		IsOnBizApp:             response.IsOnBizApp,
+		PlatformType:           response.PlatformType,
+		Status:                 response.Status,
 	}
 }
 
+// IsCoexistence reports whether the number is shared between the WhatsApp Business app
+// and the Cloud API. Both is_on_biz_app and platform_type must be requested as fields.
+func (p *PhoneNumber) IsCoexistence() bool {
+	return p.IsOnBizApp && p.PlatformType == PlatformTypeCloudAPI
+}
+
 func NewBaseClient(reader config.Reader, sender Sender, middlewares ...SenderMiddleware) (*BaseClient, error) {
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		mw := middlewares[i]
@@ -120,11 +187,91 @@ func NewBaseClient(reader config.Reader, sender Sender, middlewares ...SenderMid
 	return client, nil
 }
 
+// GetWebhookConfiguration returns the callback URLs configured for the phone number.
+func (c *BaseClient) GetWebhookConfiguration(ctx context.Context) (*WebhookConfiguration, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return getWebhookConfiguration(ctx, c.Sender, conf)
+}
+
+// SetWebhookOverride points the webhooks of the phone number to req.CallbackURI.
+func (c *BaseClient) SetWebhookOverride(ctx context.Context, req *WebhookOverrideRequest) error {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return updateWebhookConfiguration(ctx, c.Sender, conf, req)
+}
+
+// DeleteWebhookOverride removes the phone number override, webhooks go back to the
+// WhatsApp Business Account or app callback.
+func (c *BaseClient) DeleteWebhookOverride(ctx context.Context) error {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return updateWebhookConfiguration(ctx, c.Sender, conf, &WebhookOverrideRequest{})
+}
+
+func getWebhookConfiguration(ctx context.Context, sender Sender, conf *config.Config) (*WebhookConfiguration, error) {
+	request := &BaseRequest{
+		Type:   whttp.RequestTypeGetWebhookConfiguration,
+		Method: http.MethodGet,
+		QueryParams: map[string]string{
+			"fields": "webhook_configuration",
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook configuration: %w", err)
+	}
+
+	if response.WebhookConfiguration == nil {
+		return &WebhookConfiguration{}, nil
+	}
+
+	return response.WebhookConfiguration, nil
+}
+
+func updateWebhookConfiguration(ctx context.Context, sender Sender, conf *config.Config,
+	req *WebhookOverrideRequest,
+) error {
+	request := &BaseRequest{
+		Type:   whttp.RequestTypeUpdateWebhookConfiguration,
+		Method: http.MethodPost,
+		Body: map[string]any{
+			"webhook_configuration": req,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return fmt.Errorf("update webhook configuration: %w", err)
+	}
+
+	if !response.Success {
+		return ErrWebhookConfigurationNotUpdated
+	}
+
+	return nil
+}
+
 func (c *BaseClient) List(ctx context.Context) (*ListResponse, error) {
+	return c.ListPage(ctx, nil)
+}
+
+// ListPage returns the page of phone numbers selected by page.
+func (c *BaseClient) ListPage(ctx context.Context, page *ListRequest) (*ListResponse, error) {
 	req := &BaseRequest{
 		Type:        whttp.RequestTypeListPhoneNumbers,
 		Method:      http.MethodGet,
-		QueryParams: map[string]string{},
+		QueryParams: page.queryParams(),
 	}
 
 	conf, err := c.Config.Read(ctx)
@@ -145,7 +292,7 @@ func (c *BaseClient) Get(ctx context.Context, req *GetRequest) (*PhoneNumber, er
 		Type:   whttp.RequestTypeGetPhoneNumber,
 		Method: http.MethodGet,
 		QueryParams: map[string]string{
-			"fields": strings.Join(req.Fields, ";"),
+			"fields": strings.Join(req.Fields, ","),
 		},
 	}
 
@@ -167,6 +314,7 @@ type (
 		Type        whttp.RequestType
 		Method      string
 		QueryParams map[string]string
+		Body        any
 	}
 
 	BaseSender struct {
@@ -193,9 +341,14 @@ func (c *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseReq
 	req.QueryParams["access_token"] = conf.AccessToken
 
 	endpoints := []string{conf.APIVersion}
-	if req.Type == whttp.RequestTypeListPhoneNumbers {
+	switch req.Type {
+	case whttp.RequestTypeListPhoneNumbers:
 		endpoints = append(endpoints, conf.BusinessAccountID, "phone_numbers")
-	} else {
+	case whttp.RequestTypeGetPhoneNumberSettings, whttp.RequestTypeUpdatePhoneNumberSettings:
+		endpoints = append(endpoints, conf.PhoneNumberID, "settings")
+	case whttp.RequestTypeUpdateConversationalAutomation:
+		endpoints = append(endpoints, conf.PhoneNumberID, "conversational_automation")
+	default:
 		endpoints = append(endpoints, conf.PhoneNumberID)
 	}
 
@@ -208,6 +361,10 @@ func (c *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseReq
 		whttp.WithRequestSecured[any](conf.SecureRequests),
 	}
 
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
 	request := whttp.MakeRequest(req.Method, conf.BaseURL, opts...)
 
 	response := &Response{}
@@ -253,10 +410,15 @@ func NewClient(ctx context.Context, reader config.Reader, sender Sender,
 }
 
 func (c *Client) List(ctx context.Context) (*ListResponse, error) {
+	return c.ListPage(ctx, nil)
+}
+
+// ListPage returns the page of phone numbers selected by page.
+func (c *Client) ListPage(ctx context.Context, page *ListRequest) (*ListResponse, error) {
 	req := &BaseRequest{
 		Type:        whttp.RequestTypeListPhoneNumbers,
 		Method:      http.MethodGet,
-		QueryParams: map[string]string{},
+		QueryParams: page.queryParams(),
 	}
 
 	response, err := c.Sender.Send(ctx, c.Config, req)
@@ -272,7 +434,7 @@ func (c *Client) Get(ctx context.Context, req *GetRequest) (*PhoneNumber, error)
 		Type:   whttp.RequestTypeGetPhoneNumber,
 		Method: http.MethodGet,
 		QueryParams: map[string]string{
-			"fields": strings.Join(req.Fields, ";"),
+			"fields": strings.Join(req.Fields, ","),
 		},
 	}
 
@@ -284,12 +446,43 @@ func (c *Client) Get(ctx context.Context, req *GetRequest) (*PhoneNumber, error)
 	return response.PhoneNumber(), nil
 }
 
+func (c *Client) GetWebhookConfiguration(ctx context.Context) (*WebhookConfiguration, error) {
+	return getWebhookConfiguration(ctx, c.Sender, c.Config)
+}
+
+func (c *Client) SetWebhookOverride(ctx context.Context, req *WebhookOverrideRequest) error {
+	return updateWebhookConfiguration(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) DeleteWebhookOverride(ctx context.Context) error {
+	return updateWebhookConfiguration(ctx, c.Sender, c.Config, &WebhookOverrideRequest{})
+}
+
 type Service interface {
 	List(ctx context.Context) (*ListResponse, error)
 	Get(ctx context.Context, request *GetRequest) (*PhoneNumber, error)
+	GetWebhookConfiguration(ctx context.Context) (*WebhookConfiguration, error)
+	SetWebhookOverride(ctx context.Context, req *WebhookOverrideRequest) error
+	DeleteWebhookOverride(ctx context.Context) error
+	GetSettings(ctx context.Context, req *GetSettingsRequest) (*Settings, error)
+	UpdateSettings(ctx context.Context, settings *Settings) error
 }
 
 var (
 	_ Service = (*Client)(nil)
 	_ Service = (*BaseClient)(nil)
 )
+
+// phoneNumberError is a custom error type for phone number errors.
+type phoneNumberError string
+
+func (e phoneNumberError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrWebhookConfigurationNotUpdated = phoneNumberError("webhook configuration was not updated")
+	ErrSettingsNotUpdated             = phoneNumberError("phone number settings were not updated")
+	ErrAutomationNotUpdated           = phoneNumberError("conversational automation was not updated")
+	ErrInvalidAutomation              = phoneNumberError("invalid conversational automation")
+)
@@ -0,0 +1,51 @@
+package phonenumber_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/phonenumber"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_Get(t *testing.T) {
+	t.Parallel()
+
+	var query string
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		query = r.URL.Query().Get("fields")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"phone","display_phone_number":"+255 700 000 000","verified_name":"Shop"}`))
+	})
+
+	client, err := phonenumber.NewBaseClient(reader, &phonenumber.BaseSender{Sender: whttp.NewAnySender()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.Get(context.Background(), &phonenumber.GetRequest{
+		Fields: []string{"display_phone_number", "verified_name"},
+	})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if want := "display_phone_number,verified_name"; query != want {
+		t.Errorf("fields = %q, want %q", query, want)
+	}
+
+	want := &phonenumber.PhoneNumber{ID: "phone", DisplayPhoneNumber: "+255 700 000 000", VerifiedName: "Shop"}
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+}
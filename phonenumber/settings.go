@@ -0,0 +1,181 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package phonenumber
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const (
+	SettingStatusEnabled  = "ENABLED"
+	SettingStatusDisabled = "DISABLED"
+)
+
+const (
+	CallIconVisibilityDefault     = "DEFAULT"
+	CallIconVisibilityDisableAll  = "DISABLE_ALL"
+	CallIconVisibilityShowAll     = "SHOW_ALL"
+	CallIconVisibilityHideOutside = "HIDE_OUTSIDE_CALL_HOURS"
+)
+
+const (
+	DayMonday    = "MONDAY"
+	DayTuesday   = "TUESDAY"
+	DayWednesday = "WEDNESDAY"
+	DayThursday  = "THURSDAY"
+	DayFriday    = "FRIDAY"
+	DaySaturday  = "SATURDAY"
+	DaySunday    = "SUNDAY"
+)
+
+type (
+	// Settings is the settings object of a phone number. Only the calling settings are
+	// supported, they are the same settings delivered by the account_settings_update webhook.
+	Settings struct {
+		Calling *CallingSettings `json:"calling,omitempty"`
+	}
+
+	// CallingSettings enables calling on the phone number and controls when and how users
+	// can call the business.
+	CallingSettings struct {
+		Status                   string     `json:"status,omitempty"`
+		CallIconVisibility       string     `json:"call_icon_visibility,omitempty"`
+		CallHours                *CallHours `json:"call_hours,omitempty"`
+		CallbackPermissionStatus string     `json:"callback_permission_status,omitempty"`
+		SIP                      *SIP       `json:"sip,omitempty"`
+	}
+
+	// CallHours limits the times when the business accepts calls. Times are in 24 hour
+	// HHMM format, dates in YYYY-MM-DD format, both in the TimezoneID timezone.
+	CallHours struct {
+		Status               string           `json:"status,omitempty"`
+		TimezoneID           string           `json:"timezone_id,omitempty"`
+		WeeklyOperatingHours []*OperatingHour `json:"weekly_operating_hours,omitempty"`
+		HolidaySchedule      []*Holiday       `json:"holiday_schedule,omitempty"`
+	}
+
+	OperatingHour struct {
+		DayOfWeek string `json:"day_of_week"`
+		OpenTime  string `json:"open_time"`
+		CloseTime string `json:"close_time"`
+	}
+
+	Holiday struct {
+		Date      string `json:"date"`
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+	}
+
+	// SIP routes calls to the listed SIP servers instead of the Graph API call webhooks.
+	SIP struct {
+		Status  string       `json:"status,omitempty"`
+		Servers []*SIPServer `json:"servers,omitempty"`
+	}
+
+	// SIPServer is a SIP server calls are forwarded to. SIPUserPassword is only returned
+	// when the settings are fetched with credentials.
+	SIPServer struct {
+		Hostname             string            `json:"hostname"`
+		Port                 int               `json:"port,omitempty"`
+		RequestURIUserParams map[string]string `json:"request_uri_user_params,omitempty"`
+		SIPUserPassword      string            `json:"sip_user_password,omitempty"`
+	}
+
+	GetSettingsRequest struct {
+		IncludeSIPCredentials bool
+	}
+)
+
+// CallingEnabled reports whether calling is enabled on the phone number.
+func (s *Settings) CallingEnabled() bool {
+	return s != nil && s.Calling != nil && s.Calling.Status == SettingStatusEnabled
+}
+
+// GetSettings returns the calling settings of the phone number.
+func (c *BaseClient) GetSettings(ctx context.Context, req *GetSettingsRequest) (*Settings, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return getSettings(ctx, c.Sender, conf, req)
+}
+
+// UpdateSettings replaces the calling settings of the phone number. Settings left out
+// are not changed.
+func (c *BaseClient) UpdateSettings(ctx context.Context, settings *Settings) error {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return updateSettings(ctx, c.Sender, conf, settings)
+}
+
+func (c *Client) GetSettings(ctx context.Context, req *GetSettingsRequest) (*Settings, error) {
+	return getSettings(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) UpdateSettings(ctx context.Context, settings *Settings) error {
+	return updateSettings(ctx, c.Sender, c.Config, settings)
+}
+
+func getSettings(ctx context.Context, sender Sender, conf *config.Config, req *GetSettingsRequest) (*Settings, error) {
+	request := &BaseRequest{
+		Type:        whttp.RequestTypeGetPhoneNumberSettings,
+		Method:      http.MethodGet,
+		QueryParams: map[string]string{},
+	}
+
+	if req != nil && req.IncludeSIPCredentials {
+		request.QueryParams["include_sip_credentials"] = "true"
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("get phone number settings: %w", err)
+	}
+
+	return &Settings{Calling: response.Calling}, nil
+}
+
+func updateSettings(ctx context.Context, sender Sender, conf *config.Config, settings *Settings) error {
+	request := &BaseRequest{
+		Type:   whttp.RequestTypeUpdatePhoneNumberSettings,
+		Method: http.MethodPost,
+		Body:   settings,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return fmt.Errorf("update phone number settings: %w", err)
+	}
+
+	if !response.Success {
+		return ErrSettingsNotUpdated
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package phonenumber_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/phonenumber"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestBaseClient_Settings(t *testing.T) {
+	t.Parallel()
+
+	var posted map[string]any
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone/settings" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&posted)
+			_, _ = w.Write([]byte(`{"success":true}`))
+
+			return
+		}
+
+		if r.URL.Query().Get("include_sip_credentials") != "true" {
+			t.Errorf("include_sip_credentials = %q", r.URL.Query().Get("include_sip_credentials"))
+		}
+		_, _ = w.Write([]byte(`{"calling":{"status":"ENABLED","call_icon_visibility":"DEFAULT",` +
+			`"call_hours":{"status":"ENABLED","timezone_id":"Africa/Dar_es_Salaam","weekly_operating_hours":` +
+			`[{"day_of_week":"MONDAY","open_time":"0800","close_time":"1700"}]},` +
+			`"sip":{"status":"ENABLED","servers":[{"hostname":"sip.example.com","port":5061,"sip_user_password":"secret"}]}}}`))
+	})
+
+	client, err := phonenumber.NewBaseClient(reader, &phonenumber.BaseSender{Sender: whttp.NewAnySender()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	settings, err := client.GetSettings(ctx, &phonenumber.GetSettingsRequest{IncludeSIPCredentials: true})
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+
+	want := &phonenumber.Settings{Calling: &phonenumber.CallingSettings{
+		Status:             phonenumber.SettingStatusEnabled,
+		CallIconVisibility: phonenumber.CallIconVisibilityDefault,
+		CallHours: &phonenumber.CallHours{
+			Status:     phonenumber.SettingStatusEnabled,
+			TimezoneID: "Africa/Dar_es_Salaam",
+			WeeklyOperatingHours: []*phonenumber.OperatingHour{
+				{DayOfWeek: phonenumber.DayMonday, OpenTime: "0800", CloseTime: "1700"},
+			},
+		},
+		SIP: &phonenumber.SIP{
+			Status:  phonenumber.SettingStatusEnabled,
+			Servers: []*phonenumber.SIPServer{{Hostname: "sip.example.com", Port: 5061, SIPUserPassword: "secret"}},
+		},
+	}}
+	if diff := gcmp.Diff(want, settings); diff != "" {
+		t.Errorf("GetSettings() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !settings.CallingEnabled() {
+		t.Error("CallingEnabled() = false")
+	}
+
+	err = client.UpdateSettings(ctx, &phonenumber.Settings{Calling: &phonenumber.CallingSettings{
+		Status:                   phonenumber.SettingStatusDisabled,
+		CallbackPermissionStatus: phonenumber.SettingStatusEnabled,
+	}})
+	if err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+
+	wantBody := map[string]any{"calling": map[string]any{"status": "DISABLED", "callback_permission_status": "ENABLED"}}
+	if diff := gcmp.Diff(wantBody, posted); diff != "" {
+		t.Errorf("UpdateSettings() body mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,11 +1,11 @@
 package crypto
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 )
 
 var ErrCreateAppSecretProof = errors.New("failed to create appsecret_proof")
@@ -25,12 +25,18 @@ func GenerateAppSecretProof(accessToken, appSecret string) (string, error) {
 		return "", fmt.Errorf("%w: access token and app secret are required", ErrCreateAppSecretProof)
 	}
 
-	h := hmac.New(sha256.New, []byte(appSecret))
+	return hex.EncodeToString(HMACSHA256([]byte(appSecret), []byte(accessToken))), nil
+}
 
-	_, err := h.Write([]byte(accessToken))
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrCreateAppSecretProof, err)
+// GenerateTimedAppSecretProof generates a time based app secret proof, the HMAC-SHA-256 of
+// "{access-token}|{unix-time}" keyed with the app secret. It is sent with the unix time
+// as appsecret_time and is only accepted for a few minutes after at.
+func GenerateTimedAppSecretProof(accessToken, appSecret string, at time.Time) (string, error) {
+	if accessToken == "" || appSecret == "" {
+		return "", fmt.Errorf("%w: access token and app secret are required", ErrCreateAppSecretProof)
 	}
 
-	return hex.EncodeToString(h.Sum(nil)), nil
+	payload := accessToken + "|" + strconv.FormatInt(at.Unix(), 10)
+
+	return hex.EncodeToString(HMACSHA256([]byte(appSecret), []byte(payload))), nil
 }
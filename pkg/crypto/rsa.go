@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// DefaultRSAKeySize is the size of the keys generated by GenerateRSAKey. WhatsApp Flows
+// require 2048 bit keys.
+const DefaultRSAKeySize = 2048
+
+const (
+	pemTypePrivateKey    = "PRIVATE KEY"
+	pemTypeRSAPrivateKey = "RSA PRIVATE KEY"
+	pemTypePublicKey     = "PUBLIC KEY"
+)
+
+var (
+	ErrGenerateKey = errors.New("failed to generate key")
+	ErrInvalidKey  = errors.New("invalid key")
+)
+
+// GenerateRSAKey generates an RSA key of DefaultRSAKeySize bits, for example the key pair
+// used to encrypt the requests of a Flow endpoint. The public key is registered with
+// flow.BaseClient.SetBusinessPublicKey and the private key decrypts the requests.
+func GenerateRSAKey() (*rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, DefaultRSAKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGenerateKey, err)
+	}
+
+	return key, nil
+}
+
+// MarshalPrivateKeyPEM encodes key as an unencrypted PKCS #8 PEM block.
+func MarshalPrivateKeyPEM(key *rsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM encodes key as a PKIX PEM block, the format expected by the
+// business public key endpoint.
+func MarshalPublicKeyPEM(key *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses an unencrypted RSA private key in PKCS #1 or PKCS #8 PEM form.
+func ParsePrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidKey)
+	}
+
+	switch block.Type {
+	case pemTypeRSAPrivateKey:
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		}
+
+		return key, nil
+	case pemTypePrivateKey:
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		}
+
+		key, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidKey)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidKey, block.Type)
+	}
+}
+
+// ParsePublicKeyPEM parses an RSA public key in PKIX PEM form.
+func ParsePublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypePublicKey {
+		return nil, fmt.Errorf("%w: no public key PEM block found", ErrInvalidKey)
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidKey)
+	}
+
+	return key, nil
+}
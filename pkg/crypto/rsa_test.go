@@ -0,0 +1,52 @@
+package crypto_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+func TestRSAKeyPEM(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateRSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.N.BitLen() != crypto.DefaultRSAKeySize {
+		t.Errorf("key size = %d, want %d", key.N.BitLen(), crypto.DefaultRSAKeySize)
+	}
+
+	privatePEM, err := crypto.MarshalPrivateKeyPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := crypto.ParsePrivateKeyPEM(privatePEM)
+	if err != nil || !parsed.Equal(key) {
+		t.Fatalf("ParsePrivateKeyPEM() = %v, want the generated key", err)
+	}
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if parsed, err := crypto.ParsePrivateKeyPEM(pkcs1); err != nil || !parsed.Equal(key) {
+		t.Errorf("ParsePrivateKeyPEM(PKCS #1) error = %v", err)
+	}
+
+	publicPEM, err := crypto.MarshalPublicKeyPEM(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := crypto.ParsePublicKeyPEM(publicPEM)
+	if err != nil || !public.Equal(&key.PublicKey) {
+		t.Fatalf("ParsePublicKeyPEM() = %v, want the generated public key", err)
+	}
+
+	if _, err := crypto.ParsePrivateKeyPEM(publicPEM); !errors.Is(err, crypto.ErrInvalidKey) {
+		t.Errorf("ParsePrivateKeyPEM(public key) error = %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,253 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSeal        = errors.New("failed to seal data")
+	ErrOpen        = errors.New("failed to open sealed data")
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// sealVersion prefixes every sealed value, so the format can evolve without breaking data
+// sealed by older releases.
+const sealVersion byte = 1
+
+type (
+	// Key is a secret identified by ID. Sealed values and signatures record the ID of the
+	// key that produced them, so they can still be opened or verified after the primary key
+	// was rotated.
+	//
+	// NotBefore and NotAfter bound when signatures made with the key are accepted, zero
+	// values leave that side open. They do not apply to sealed data, which must stay
+	// readable for as long as it is stored.
+	Key struct {
+		ID        string
+		Secret    []byte
+		NotBefore time.Time
+		NotAfter  time.Time
+	}
+
+	// KeyProvider supplies keys, typically backed by a KMS or a secret manager.
+	KeyProvider interface {
+		// PrimaryKey returns the key new values are sealed with.
+		PrimaryKey(ctx context.Context) (*Key, error)
+
+		// Key returns the key with the given ID, ErrKeyNotFound when it is unknown.
+		Key(ctx context.Context, id string) (*Key, error)
+	}
+
+	// StaticKeyProvider is a KeyProvider over a fixed set of keys. Rotate makes a new key
+	// primary while keeping the previous ones available for opening.
+	StaticKeyProvider struct {
+		mu      sync.RWMutex
+		primary string
+		keys    map[string]*Key
+	}
+)
+
+// NewStaticKeyProvider returns a provider sealing with primary and opening with primary
+// and any of previous.
+func NewStaticKeyProvider(primary *Key, previous ...*Key) *StaticKeyProvider {
+	provider := &StaticKeyProvider{keys: make(map[string]*Key, len(previous)+1)}
+	for _, key := range previous {
+		provider.keys[key.ID] = key
+	}
+	provider.Rotate(primary)
+
+	return provider
+}
+
+func (p *StaticKeyProvider) PrimaryKey(_ context.Context) (*Key, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.keys[p.primary], nil
+}
+
+func (p *StaticKeyProvider) Key(_ context.Context, id string) (*Key, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+
+	return key, nil
+}
+
+// Rotate adds key and makes it the primary key.
+func (p *StaticKeyProvider) Rotate(key *Key) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keys[key.ID] = key
+	p.primary = key.ID
+}
+
+// RotateWithGrace makes key primary and stops accepting signatures made with the previous
+// primary key once grace has elapsed from now.
+func (p *StaticKeyProvider) RotateWithGrace(key *Key, now time.Time, grace time.Duration) {
+	p.mu.Lock()
+	if previous, ok := p.keys[p.primary]; ok && p.primary != key.ID {
+		retired := *previous
+		retired.NotAfter = now.Add(grace)
+		p.keys[retired.ID] = &retired
+	}
+	p.mu.Unlock()
+
+	p.Rotate(key)
+}
+
+// ValidAt reports whether signatures made with the key are accepted at t.
+func (k *Key) ValidAt(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+
+	return k.NotAfter.IsZero() || t.Before(k.NotAfter)
+}
+
+// Sealer encrypts values at rest with AES-GCM. Keys must be 16, 24 or 32 bytes long for
+// AES-128, AES-192 or AES-256.
+//
+// Sealed values are laid out as version, key id length, key id, nonce and ciphertext.
+// The associated data passed to Seal must be passed to Open as well, binding a value to
+// its owner, for example a record id, so it cannot be swapped with another one.
+type Sealer struct {
+	keys KeyProvider
+}
+
+func NewSealer(keys KeyProvider) *Sealer {
+	return &Sealer{keys: keys}
+}
+
+func (s *Sealer) Seal(ctx context.Context, plaintext, associatedData []byte) ([]byte, error) {
+	key, err := s.keys.PrimaryKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSeal, err)
+	}
+
+	if key == nil || len(key.ID) > 255 { //nolint:mnd // the id length is stored in one byte
+		return nil, fmt.Errorf("%w: invalid primary key", ErrSeal)
+	}
+
+	aead, err := newAEAD(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSeal, err)
+	}
+
+	out := make([]byte, 0, 2+len(key.ID)+aead.NonceSize()+len(plaintext)+aead.Overhead()) //nolint:mnd // header
+	out = append(out, sealVersion, byte(len(key.ID)))
+	out = append(out, key.ID...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSeal, err)
+	}
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plaintext, associatedData), nil
+}
+
+func (s *Sealer) Open(ctx context.Context, sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < 2 || sealed[0] != sealVersion || len(sealed) < 2+int(sealed[1]) {
+		return nil, fmt.Errorf("%w: malformed value", ErrOpen)
+	}
+
+	idEnd := 2 + int(sealed[1])
+	key, err := s.keys.Key(ctx, string(sealed[2:idEnd]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOpen, err)
+	}
+
+	aead, err := newAEAD(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOpen, err)
+	}
+
+	rest := sealed[idEnd:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed value", ErrOpen)
+	}
+
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOpen, err)
+	}
+
+	return plaintext, nil
+}
+
+// SealString seals s and encodes the result with base64, for stores with text columns.
+func (s *Sealer) SealString(ctx context.Context, plaintext, associatedData string) (string, error) {
+	sealed, err := s.Seal(ctx, []byte(plaintext), []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *Sealer) OpenString(ctx context.Context, sealed, associatedData string) (string, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrOpen, err)
+	}
+
+	plaintext, err := s.Open(ctx, raw, []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// SealJSON seals the JSON encoding of v as a base64 string.
+func (s *Sealer) SealJSON(ctx context.Context, v any, associatedData string) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSeal, err)
+	}
+
+	return s.SealString(ctx, string(data), associatedData)
+}
+
+// OpenJSON opens a value sealed with SealJSON into v.
+func (s *Sealer) OpenJSON(ctx context.Context, sealed, associatedData string, v any) error {
+	data, err := s.OpenString(ctx, sealed, associatedData)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("%w: %w", ErrOpen, err)
+	}
+
+	return nil
+}
+
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	return aead, nil
+}
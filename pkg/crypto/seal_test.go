@@ -0,0 +1,45 @@
+package crypto_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+func TestSealer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	oldKey := &crypto.Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, 32)}
+	keys := crypto.NewStaticKeyProvider(oldKey)
+	sealer := crypto.NewSealer(keys)
+
+	sealed, err := sealer.SealString(ctx, "hello", "record-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.Rotate(&crypto.Key{ID: "k2", Secret: bytes.Repeat([]byte{2}, 32)})
+
+	got, err := sealer.OpenString(ctx, sealed, "record-1")
+	if err != nil || got != "hello" {
+		t.Fatalf("OpenString() after rotation = %q, %v", got, err)
+	}
+
+	if _, err := sealer.OpenString(ctx, sealed, "record-2"); !errors.Is(err, crypto.ErrOpen) {
+		t.Errorf("expected %v with the wrong associated data, got %v", crypto.ErrOpen, err)
+	}
+
+	resealed, err := sealer.Seal(ctx, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = crypto.NewSealer(crypto.NewStaticKeyProvider(oldKey)).Open(ctx, resealed, nil)
+	if !errors.Is(err, crypto.ErrKeyNotFound) {
+		t.Errorf("expected %v for a value sealed with an unknown key, got %v", crypto.ErrKeyNotFound, err)
+	}
+}
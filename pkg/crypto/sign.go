@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSign             = errors.New("failed to sign payload")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrKeyNotValid      = errors.New("signing key is not valid at this time")
+)
+
+// HMACSHA256 returns the HMAC-SHA256 of payload keyed with secret.
+func HMACSHA256(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+type (
+	// Signer signs payloads with the primary key of a KeyProvider and verifies them with
+	// whichever key signed them, as long as that key is still valid. Rotating keys with
+	// StaticKeyProvider.RotateWithGrace leaves a window in which both keys are accepted.
+	Signer struct {
+		keys KeyProvider
+		now  func() time.Time
+	}
+
+	SignerOption func(*Signer)
+)
+
+func WithSignerClock(now func() time.Time) SignerOption {
+	return func(s *Signer) {
+		s.now = now
+	}
+}
+
+func NewSigner(keys KeyProvider, options ...SignerOption) *Signer {
+	signer := &Signer{keys: keys, now: time.Now}
+	for _, option := range options {
+		if option != nil {
+			option(signer)
+		}
+	}
+
+	return signer
+}
+
+// Sign returns a token carrying payload, formatted as <payload>.<key id>.<mac> with the
+// payload and mac base64url encoded. Key IDs must not contain dots.
+func (s *Signer) Sign(ctx context.Context, payload []byte) (string, error) {
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	keyID, mac, err := s.sign(ctx, encoded)
+	if err != nil {
+		return "", err
+	}
+
+	return encoded + "." + keyID + "." + mac, nil
+}
+
+// Verify checks a token made by Sign and returns its payload.
+func (s *Signer) Verify(ctx context.Context, token string) ([]byte, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidSignature)
+	}
+
+	if err := s.VerifyDetached(ctx, []byte(encoded), signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	return payload, nil
+}
+
+// SignDetached returns a signature of payload formatted as <key id>.<mac>, for payloads
+// that travel separately, such as request bodies.
+func (s *Signer) SignDetached(ctx context.Context, payload []byte) (string, error) {
+	keyID, mac, err := s.sign(ctx, string(payload))
+	if err != nil {
+		return "", err
+	}
+
+	return keyID + "." + mac, nil
+}
+
+// VerifyDetached checks a signature made by SignDetached.
+func (s *Signer) VerifyDetached(ctx context.Context, payload []byte, signature string) error {
+	keyID, encodedMAC, ok := strings.Cut(signature, ".")
+	if !ok {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	key, err := s.keys.Key(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	if !hmac.Equal(mac, HMACSHA256(key.Secret, signedBytes(keyID, string(payload)))) {
+		return ErrInvalidSignature
+	}
+
+	if !key.ValidAt(s.now()) {
+		return fmt.Errorf("%w: %q", ErrKeyNotValid, keyID)
+	}
+
+	return nil
+}
+
+func (s *Signer) sign(ctx context.Context, payload string) (string, string, error) {
+	key, err := s.keys.PrimaryKey(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrSign, err)
+	}
+
+	if key == nil || key.ID == "" || strings.Contains(key.ID, ".") {
+		return "", "", fmt.Errorf("%w: invalid primary key", ErrSign)
+	}
+
+	if !key.ValidAt(s.now()) {
+		return "", "", fmt.Errorf("%w: %w: %q", ErrSign, ErrKeyNotValid, key.ID)
+	}
+
+	mac := HMACSHA256(key.Secret, signedBytes(key.ID, payload))
+
+	return key.ID, base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+// signedBytes binds the key id to the mac, so a signature cannot be replayed under another
+// key id.
+func signedBytes(keyID, payload string) []byte {
+	return []byte(keyID + "." + payload)
+}
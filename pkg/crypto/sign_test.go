@@ -0,0 +1,65 @@
+package crypto_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+func TestSignerRotation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	keys := crypto.NewStaticKeyProvider(&crypto.Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, 32)})
+	signer := crypto.NewSigner(keys, crypto.WithSignerClock(clock))
+
+	token, err := signer.Sign(ctx, []byte(`{"flow":"signup"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.RotateWithGrace(&crypto.Key{ID: "k2", Secret: bytes.Repeat([]byte{2}, 32)}, now, time.Hour)
+
+	payload, err := signer.Verify(ctx, token)
+	if err != nil || string(payload) != `{"flow":"signup"}` {
+		t.Fatalf("Verify() within grace = %q, %v", payload, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+
+	if _, err := signer.Verify(ctx, token); !errors.Is(err, crypto.ErrKeyNotValid) {
+		t.Errorf("expected %v after grace, got %v", crypto.ErrKeyNotValid, err)
+	}
+
+	fresh, err := signer.Sign(ctx, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := []byte(fresh)
+	tampered[0] ^= 1
+
+	if _, err := signer.Verify(ctx, string(tampered)); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("expected %v for a tampered token, got %v", crypto.ErrInvalidSignature, err)
+	}
+
+	signature, err := signer.SignDetached(ctx, []byte("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := signer.VerifyDetached(ctx, []byte("body"), signature); err != nil {
+		t.Errorf("VerifyDetached() = %v", err)
+	}
+
+	if err := signer.VerifyDetached(ctx, []byte("other"), signature); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("expected %v for another body, got %v", crypto.ErrInvalidSignature, err)
+	}
+}
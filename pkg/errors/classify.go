@@ -0,0 +1,170 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package errors
+
+import "errors"
+
+// Category groups error codes by the way a caller recovers from them, see
+// DeveloperErrorDescLink for the documented codes.
+type Category string
+
+const (
+	// CategoryUnknown is the category of the codes that are not classified.
+	CategoryUnknown Category = "unknown"
+
+	// CategoryAuth errors are fixed by renewing the access token or granting the
+	// permissions it lacks.
+	CategoryAuth Category = "auth"
+
+	// CategoryThrottling errors are retried after a while.
+	CategoryThrottling Category = "throttling"
+
+	// CategoryIntegrity errors mean the account is restricted for policy violations, they
+	// are not retried.
+	CategoryIntegrity Category = "integrity"
+
+	// CategoryParameter errors are caused by the request and are not retried as they are.
+	CategoryParameter Category = "parameter"
+)
+
+// Documented error codes.
+const (
+	CodeAPIMethod                = 3
+	CodeAPITooManyCalls          = 4
+	CodePermissionDenied         = 10
+	CodeAccessTokenExpired       = 190
+	CodeInvalidParameter         = 100
+	CodeTemporarilyBlocked       = 368
+	CodeRateLimitIssues          = 80007
+	CodeCloudAPIThroughput       = 130429
+	CodeCountryRestricted        = 130497
+	CodeRequiredParameterMissing = 131008
+	CodeParameterValueInvalid    = 131009
+	CodeRecipientIsSender        = 131021
+	CodeMessageUndeliverable     = 131026
+	CodeAccountLocked            = 131031
+	CodeSpamRateLimit            = 131048
+	CodePairRateLimit            = 131056
+	CodeTemplateParamCount       = 132000
+	CodeTemplateNotFound         = 132001
+	CodeTemplateTextTooLong      = 132005
+	CodeTemplateFormatPolicy     = 132007
+	CodeTemplateParamFormat      = 132012
+	CodeRegistrationRateLimit    = 133016
+	codePermissionRangeStart     = 200
+	codePermissionRangeEnd       = 299
+)
+
+var categories = map[int]Category{ //nolint:gochecknoglobals // lookup table
+	CodeAPIMethod:                CategoryAuth,
+	CodePermissionDenied:         CategoryAuth,
+	CodeAccessTokenExpired:       CategoryAuth,
+	CodeAPITooManyCalls:          CategoryThrottling,
+	CodeRateLimitIssues:          CategoryThrottling,
+	CodeCloudAPIThroughput:       CategoryThrottling,
+	CodeSpamRateLimit:            CategoryThrottling,
+	CodePairRateLimit:            CategoryThrottling,
+	CodeRegistrationRateLimit:    CategoryThrottling,
+	CodeTemporarilyBlocked:       CategoryIntegrity,
+	CodeCountryRestricted:        CategoryIntegrity,
+	CodeAccountLocked:            CategoryIntegrity,
+	CodeInvalidParameter:         CategoryParameter,
+	CodeRequiredParameterMissing: CategoryParameter,
+	CodeParameterValueInvalid:    CategoryParameter,
+	CodeRecipientIsSender:        CategoryParameter,
+	CodeTemplateParamCount:       CategoryParameter,
+	CodeTemplateNotFound:         CategoryParameter,
+	CodeTemplateTextTooLong:      CategoryParameter,
+	CodeTemplateFormatPolicy:     CategoryParameter,
+	CodeTemplateParamFormat:      CategoryParameter,
+}
+
+// CategoryOf returns the category of the error code.
+func CategoryOf(code int) Category {
+	if category, ok := categories[code]; ok {
+		return category
+	}
+
+	if code >= codePermissionRangeStart && code <= codePermissionRangeEnd {
+		return CategoryAuth
+	}
+
+	return CategoryUnknown
+}
+
+// Category returns the category of the error code.
+func (e *Error) Category() Category {
+	if e == nil {
+		return CategoryUnknown
+	}
+
+	return CategoryOf(e.Code)
+}
+
+// CategoryOfError returns the category of the WhatsApp error in the chain of err, and
+// CategoryUnknown when there is none.
+func CategoryOfError(err error) Category {
+	var e *Error
+	if !errors.As(err, &e) {
+		return CategoryUnknown
+	}
+
+	return e.Category()
+}
+
+// IsRateLimited reports whether err is a WhatsApp error caused by a rate limit, such as
+// the throughput, spam or pair rate limits of the phone number.
+func IsRateLimited(err error) bool {
+	return CategoryOfError(err) == CategoryThrottling
+}
+
+// IsRecipientNotOnWhatsApp reports whether err is a WhatsApp error saying the message could
+// not be delivered, which the API returns for recipients without a WhatsApp account.
+func IsRecipientNotOnWhatsApp(err error) bool {
+	return HasCode(err, CodeMessageUndeliverable)
+}
+
+// IsTokenExpired reports whether err is a WhatsApp error caused by an expired or invalidated
+// access token.
+func IsTokenExpired(err error) bool {
+	return HasCode(err, CodeAccessTokenExpired)
+}
+
+// IsTemplateParamMismatch reports whether err is a WhatsApp error caused by template
+// parameters that do not match the ones of the template in number or format.
+func IsTemplateParamMismatch(err error) bool {
+	return HasCode(err, CodeTemplateParamCount, CodeTemplateParamFormat)
+}
+
+// HasCode reports whether the WhatsApp error in the chain of err has one of codes.
+func HasCode(err error, codes ...int) bool {
+	var e *Error
+	if !errors.As(err, &e) || e == nil {
+		return false
+	}
+
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
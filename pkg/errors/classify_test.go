@@ -0,0 +1,67 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(code int) error {
+		return fmt.Errorf("send message: %w", &werrors.Error{Code: code})
+	}
+
+	tests := []struct {
+		name                string
+		err                 error
+		category            werrors.Category
+		rateLimited         bool
+		recipientNotOnWA    bool
+		tokenExpired        bool
+		templateParamsWrong bool
+	}{
+		{name: "throughput", err: wrap(130429), category: werrors.CategoryThrottling, rateLimited: true},
+		{name: "pair rate limit", err: wrap(131056), category: werrors.CategoryThrottling, rateLimited: true},
+		{name: "token expired", err: wrap(190), category: werrors.CategoryAuth, tokenExpired: true},
+		{name: "permission range", err: wrap(230), category: werrors.CategoryAuth},
+		{name: "account locked", err: wrap(131031), category: werrors.CategoryIntegrity},
+		{name: "undeliverable", err: wrap(131026), category: werrors.CategoryUnknown, recipientNotOnWA: true},
+		{
+			name: "template param count", err: wrap(132000), category: werrors.CategoryParameter,
+			templateParamsWrong: true,
+		},
+		{name: "invalid parameter", err: wrap(100), category: werrors.CategoryParameter},
+		{name: "unclassified code", err: wrap(131000), category: werrors.CategoryUnknown},
+		{name: "not a whatsapp error", err: errors.New("timeout"), category: werrors.CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := werrors.CategoryOfError(tt.err); got != tt.category {
+				t.Errorf("CategoryOfError() = %q, want %q", got, tt.category)
+			}
+
+			if got := werrors.IsRateLimited(tt.err); got != tt.rateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", got, tt.rateLimited)
+			}
+
+			if got := werrors.IsRecipientNotOnWhatsApp(tt.err); got != tt.recipientNotOnWA {
+				t.Errorf("IsRecipientNotOnWhatsApp() = %v, want %v", got, tt.recipientNotOnWA)
+			}
+
+			if got := werrors.IsTokenExpired(tt.err); got != tt.tokenExpired {
+				t.Errorf("IsTokenExpired() = %v, want %v", got, tt.tokenExpired)
+			}
+
+			if got := werrors.IsTemplateParamMismatch(tt.err); got != tt.templateParamsWrong {
+				t.Errorf("IsTemplateParamMismatch() = %v, want %v", got, tt.templateParamsWrong)
+			}
+		})
+	}
+}
@@ -58,7 +58,10 @@ type (
 	//	        "error_user_msg": "Add recipient phone number to recipient list and try again.",
 	//	        "fbtrace_id": "AI5Ob2z72R0JAUB5zOF-nao"
 	//	}
+	//
+	// Errors reported in webhook notifications also carry a Title.
 	Error struct {
+		Title     string     `json:"title,omitempty"`
 		Message   string     `json:"message,omitempty"`
 		Type      string     `json:"type,omitempty"`
 		Code      int        `json:"code,omitempty"`
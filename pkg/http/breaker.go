@@ -0,0 +1,344 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// CircuitState is the state of the circuit of an endpoint.
+	CircuitState int
+
+	// CircuitEvent describes a change of the state of the circuit of the endpoint Key.
+	// Failures is the number of consecutive failures that led to it.
+	CircuitEvent struct {
+		Key      string
+		From     CircuitState
+		To       CircuitState
+		Failures int
+	}
+
+	// CircuitHookFunc is called every time the circuit of an endpoint changes state.
+	CircuitHookFunc func(ctx context.Context, event *CircuitEvent)
+
+	// CircuitRejectHookFunc is called for every request rejected by an open circuit.
+	CircuitRejectHookFunc func(ctx context.Context, key string)
+
+	// CircuitKeyFunc returns the endpoint a request is accounted to.
+	CircuitKeyFunc func(request *http.Request) string
+
+	CircuitBreakerOption func(*CircuitBreaker)
+
+	// CircuitBreaker stops sending requests to an endpoint that keeps failing. After
+	// FailureThreshold consecutive 5xx responses or transport errors, timeouts included,
+	// the circuit of the endpoint opens and its requests fail with a CircuitOpenError
+	// without being sent. Once OpenTimeout has passed the circuit is half open and lets
+	// HalfOpenProbes requests through: the first that succeeds closes it again and the
+	// first that fails reopens it.
+	//
+	// Requests canceled by their context and 4xx responses, rate limiting included, are
+	// not failures of the endpoint.
+	CircuitBreaker struct {
+		failureThreshold int
+		openTimeout      time.Duration
+		halfOpenProbes   int
+		key              CircuitKeyFunc
+		onStateChange    CircuitHookFunc
+		onReject         CircuitRejectHookFunc
+		now              func() time.Time
+		mu               sync.Mutex
+		circuits         map[string]*circuit
+	}
+
+	// CircuitOpenError is returned for requests rejected by an open circuit. Until is
+	// when the circuit becomes half open.
+	CircuitOpenError struct {
+		Key   string
+		Until time.Time
+	}
+
+	circuit struct {
+		state    CircuitState
+		failures int
+		openedAt time.Time
+		probes   int
+	}
+
+	circuitTransport struct {
+		next    http.RoundTripper
+		breaker *CircuitBreaker
+	}
+)
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s until %s", ErrCircuitOpen, e.Key, e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// WithCircuitFailureThreshold sets the number of consecutive failures that open the
+// circuit of an endpoint, 5 by default.
+func WithCircuitFailureThreshold(threshold int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.failureThreshold = threshold
+	}
+}
+
+// WithCircuitOpenTimeout sets how long a circuit stays open before probing the endpoint
+// again, 30s by default.
+func WithCircuitOpenTimeout(timeout time.Duration) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.openTimeout = timeout
+	}
+}
+
+// WithCircuitHalfOpenProbes sets the number of requests let through at once by a half
+// open circuit, 1 by default.
+func WithCircuitHalfOpenProbes(probes int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.halfOpenProbes = probes
+	}
+}
+
+// WithCircuitKeyFunc sets how requests are grouped into endpoints, CircuitKeyEndpoint by
+// default. CircuitKeyHost uses a single circuit per host.
+func WithCircuitKeyFunc(fn CircuitKeyFunc) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.key = fn
+	}
+}
+
+// WithCircuitOnStateChange sets the hook called when a circuit changes state.
+func WithCircuitOnStateChange(hook CircuitHookFunc) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onStateChange = hook
+	}
+}
+
+// WithCircuitOnReject sets the hook called for every request rejected by an open circuit.
+func WithCircuitOnReject(hook CircuitRejectHookFunc) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onReject = hook
+	}
+}
+
+// NewCircuitBreaker creates a CircuitBreaker. It can be shared by several clients.
+func NewCircuitBreaker(options ...CircuitBreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		failureThreshold: 5,                //nolint:mnd // default consecutive failures
+		openTimeout:      30 * time.Second, //nolint:mnd // default open duration
+		halfOpenProbes:   1,
+		key:              CircuitKeyEndpoint,
+		now:              time.Now,
+		circuits:         make(map[string]*circuit),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(b)
+		}
+	}
+
+	return b
+}
+
+// WithCoreClientCircuitBreaker makes the CoreClient send its requests through breaker. It
+// wraps the transport of the http.Client of the CoreClient, including one set after it with
+// WithCoreClientHTTPClient or SetHTTPClient. Requests rejected by an open circuit are not
+// retried, their error wraps ErrCircuitOpen.
+func WithCoreClientCircuitBreaker[T any](breaker *CircuitBreaker) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.breaker = breaker
+	}
+}
+
+// CircuitKeyHost accounts requests to their host.
+func CircuitKeyHost(request *http.Request) string {
+	return request.URL.Host
+}
+
+// CircuitKeyEndpoint accounts requests to their host and path, with the numeric
+// segments of the path, like phone number and media IDs, replaced by "{id}" so that
+// "/v21.0/1234/messages" and "/v21.0/5678/messages" share a circuit.
+func CircuitKeyEndpoint(request *http.Request) string {
+	segments := strings.Split(request.URL.Path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{id}"
+		}
+	}
+
+	return request.URL.Host + strings.Join(segments, "/")
+}
+
+// State returns the state of the circuit of the endpoint key.
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.openTimeout)) {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// Transport returns a http.RoundTripper sending requests through next, http.DefaultTransport
+// when nil, unless the circuit of their endpoint is open.
+func (b *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &circuitTransport{next: next, breaker: b}
+}
+
+func (t *circuitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx, key := request.Context(), t.breaker.key(request)
+	if err := t.breaker.allow(ctx, key); err != nil {
+		return nil, err
+	}
+
+	response, err := t.next.RoundTrip(request)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		t.breaker.release(key)
+	case err != nil || response.StatusCode >= http.StatusInternalServerError:
+		t.breaker.record(ctx, key, false)
+	default:
+		t.breaker.record(ctx, key, true)
+	}
+
+	return response, err
+}
+
+// allow reserves a slot for a request to key, or returns a CircuitOpenError.
+func (b *CircuitBreaker) allow(ctx context.Context, key string) error {
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	var event *CircuitEvent
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.openTimeout)) {
+		event = &CircuitEvent{Key: key, From: CircuitOpen, To: CircuitHalfOpen, Failures: c.failures}
+		c.state, c.probes = CircuitHalfOpen, 0
+	}
+
+	var err error
+	switch {
+	case c.state == CircuitOpen, c.state == CircuitHalfOpen && c.probes >= max(b.halfOpenProbes, 1):
+		until := c.openedAt.Add(b.openTimeout)
+		if c.state == CircuitHalfOpen {
+			until = b.now()
+		}
+		err = &CircuitOpenError{Key: key, Until: until}
+	case c.state == CircuitHalfOpen:
+		c.probes++
+	}
+	b.mu.Unlock()
+
+	if event != nil && b.onStateChange != nil {
+		b.onStateChange(ctx, event)
+	}
+
+	if err != nil && b.onReject != nil {
+		b.onReject(ctx, key)
+	}
+
+	return err
+}
+
+// release gives back the slot of a request that ended without telling anything about the
+// health of the endpoint.
+func (b *CircuitBreaker) release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.circuits[key]; c != nil && c.state == CircuitHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+func (b *CircuitBreaker) record(ctx context.Context, key string, success bool) {
+	b.mu.Lock()
+	c := b.circuits[key]
+	from := c.state
+
+	switch {
+	case c.state == CircuitOpen && success:
+		// A request sent before the circuit opened, only half-open probes close it.
+	case c.state == CircuitOpen:
+		// A request sent before the circuit opened, the circuit is already open.
+		c.failures++
+	case success:
+		c.failures = 0
+		c.state = CircuitClosed
+	default:
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= max(b.failureThreshold, 1) {
+			c.state, c.openedAt = CircuitOpen, b.now()
+		}
+	}
+
+	event := &CircuitEvent{Key: key, From: from, To: c.state, Failures: c.failures}
+	b.mu.Unlock()
+
+	if from != event.To && b.onStateChange != nil {
+		b.onStateChange(ctx, event)
+	}
+}
+
+// ErrCircuitOpen is wrapped by the errors of requests rejected by an open circuit.
+const ErrCircuitOpen = httpError("circuit breaker is open")
@@ -0,0 +1,160 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestCircuitKeyEndpoint(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodPost, "https://graph.facebook.com/v21.0/1234567/messages", nil)
+	if got, want := whttp.CircuitKeyEndpoint(request), "graph.facebook.com/v21.0/{id}/messages"; got != want {
+		t.Errorf("CircuitKeyEndpoint() = %q, want %q", got, want)
+	}
+}
+
+func TestCoreClient_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		healthy atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var (
+		transitions []string
+		rejected    int
+	)
+	breaker := whttp.NewCircuitBreaker(
+		whttp.WithCircuitFailureThreshold(2),
+		whttp.WithCircuitOpenTimeout(20*time.Millisecond),
+		whttp.WithCircuitKeyFunc(whttp.CircuitKeyHost),
+		whttp.WithCircuitOnStateChange(func(_ context.Context, event *whttp.CircuitEvent) {
+			transitions = append(transitions, event.From.String()+"->"+event.To.String())
+		}),
+		whttp.WithCircuitOnReject(func(context.Context, string) {
+			rejected++
+		}),
+	)
+
+	policy := whttp.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	sender := whttp.NewSender[TestMessage](
+		whttp.WithCoreClientCircuitBreaker[TestMessage](breaker),
+		whttp.WithCoreClientRetryPolicy[TestMessage](policy),
+	)
+
+	send := func() error {
+		request := whttp.MakeRequest(http.MethodPost, server.URL,
+			whttp.WithRequestMessage(&TestMessage{Name: "breaker"}))
+		var got TestMessage
+
+		return sender.Send(context.Background(), request, whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{}))
+	}
+
+	// Two failed attempts open the circuit and the third attempt is rejected.
+	err := send()
+	var openErr *whttp.CircuitOpenError
+	if !errors.Is(err, whttp.ErrCircuitOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("Send() error = %v, want a CircuitOpenError", err)
+	}
+
+	key := whttp.CircuitKeyHost(httptest.NewRequest(http.MethodGet, server.URL, nil))
+	if openErr.Key != key || breaker.State(key) != whttp.CircuitOpen {
+		t.Errorf("key = %q, state = %v, want %q and open", openErr.Key, breaker.State(key), key)
+	}
+
+	if calls.Load() != 2 || rejected != 1 {
+		t.Errorf("server calls = %d, rejected = %d, want 2 and 1", calls.Load(), rejected)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	healthy.Store(true)
+
+	if err := send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
+
+func TestCircuitBreaker_StaleSuccess(t *testing.T) {
+	t.Parallel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	breaker := whttp.NewCircuitBreaker(
+		whttp.WithCircuitFailureThreshold(2),
+		whttp.WithCircuitOpenTimeout(time.Hour),
+		whttp.WithCircuitKeyFunc(whttp.CircuitKeyHost),
+	)
+	client := &http.Client{Transport: breaker.Transport(nil)}
+
+	get := func(path string) error {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			return err
+		}
+
+		return response.Body.Close()
+	}
+
+	// A request sent while the circuit is closed completes successfully after
+	// the circuit opened.
+	done := make(chan error, 1)
+	go func() { done <- get("/slow") }()
+	<-started
+
+	for range 2 {
+		if err := get("/fail"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	key := whttp.CircuitKeyHost(httptest.NewRequest(http.MethodGet, server.URL, nil))
+	if got := breaker.State(key); got != whttp.CircuitOpen {
+		t.Errorf("state = %v, want %v", got, whttp.CircuitOpen)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/piusalfred/whatsapp/pkg/crypto"
 	werrors "github.com/piusalfred/whatsapp/pkg/errors"
@@ -47,6 +48,13 @@ type (
 		resHook     ResponseInterceptorFunc
 		middlewares []Middleware[T]
 		sender      Sender[T]
+		retry       *RetryPolicy
+		telemetry   Telemetry
+		logging     func(next http.RoundTripper) http.RoundTripper
+		proof       *appSecretProof
+		metaHook    ResponseMetaHook
+		throttle    *Throttle
+		breaker     *CircuitBreaker
 	}
 
 	CoreClientOption[T any] func(client *CoreClient[T])
@@ -54,7 +62,7 @@ type (
 
 func (core *CoreClient[T]) SetHTTPClient(httpClient *http.Client) {
 	if httpClient != nil {
-		core.http = httpClient
+		core.http = core.withTransports(httpClient)
 	}
 }
 
@@ -115,6 +123,8 @@ func NewSender[T any](options ...CoreClientOption[T]) *CoreClient[T] {
 		}
 	}
 
+	core.http = core.withTransports(core.http)
+
 	return core
 }
 
@@ -131,11 +141,48 @@ func NewAnySender(options ...CoreClientOption[any]) *CoreClient[any] {
 		}
 	}
 
+	core.http = core.withTransports(core.http)
+
 	return core
 }
 
+// withTransports returns a copy of httpClient whose transport is wrapped by the circuit
+// breaker and the logging transport of the CoreClient, if any. The logging transport is
+// the outer one so requests rejected by an open circuit are logged.
+func (core *CoreClient[T]) withTransports(httpClient *http.Client) *http.Client {
+	if (core.logging == nil && core.breaker == nil) || httpClient == nil {
+		return httpClient
+	}
+
+	wrapped := *httpClient
+	if core.breaker != nil {
+		wrapped.Transport = core.breaker.Transport(wrapped.Transport)
+	}
+
+	if core.logging != nil {
+		wrapped.Transport = core.logging(wrapped.Transport)
+	}
+
+	return &wrapped
+}
+
 func (core *CoreClient[T]) send(ctx context.Context, request *Request[T], decoder ResponseDecoder) error {
-	if err := SendFuncWithInterceptors[T](core.http, core.reqHook, core.resHook)(ctx, request, decoder); err != nil {
+	request, err := signRequest(ctx, core.proof, request)
+	if err != nil {
+		return fmt.Errorf("core send: %w", err)
+	}
+
+	if core.throttle != nil {
+		if err := core.throttle.Wait(ctx); err != nil {
+			return fmt.Errorf("core send: %w", err)
+		}
+	}
+
+	if hook := core.responseMetaHook(); hook != nil {
+		ctx = context.WithValue(ctx, responseMetaHookContextKey{}, hook)
+	}
+
+	if err := sendFunc[T](core.http, core.reqHook, core.resHook, core.retry, core.telemetry)(ctx, request, decoder); err != nil {
 		return err
 	}
 
@@ -144,48 +191,133 @@ func (core *CoreClient[T]) send(ctx context.Context, request *Request[T], decode
 
 func SendFuncWithInterceptors[T any](client *http.Client, reqHook RequestInterceptorFunc,
 	resHook ResponseInterceptorFunc,
+) SenderFunc[T] {
+	return SendFuncWithRetry[T](client, reqHook, resHook, nil)
+}
+
+// SendFuncWithRetry is like SendFuncWithInterceptors but retries transient failures
+// according to policy. A nil policy sends the request once.
+func SendFuncWithRetry[T any](client *http.Client, reqHook RequestInterceptorFunc,
+	resHook ResponseInterceptorFunc, policy *RetryPolicy,
+) SenderFunc[T] {
+	return sendFunc[T](client, reqHook, resHook, policy, nil)
+}
+
+func sendFunc[T any](client *http.Client, reqHook RequestInterceptorFunc,
+	resHook ResponseInterceptorFunc, policy *RetryPolicy, telemetry Telemetry,
 ) SenderFunc[T] {
 	fn := SenderFunc[T](func(ctx context.Context, request *Request[T], decoder ResponseDecoder) error {
-		req, err := RequestWithContext(ctx, request)
-		if err != nil {
-			return err
+		attempts := policy.maxAttempts()
+		if attempts > 1 && !replayable(request) {
+			attempts = 1
 		}
 
-		if reqHook != nil {
-			if errHook := reqHook(ctx, req); errHook != nil {
-				return errHook
+		for attempt := 1; ; attempt++ {
+			attemptCtx := ctx
+			if policy != nil {
+				attemptCtx = context.WithValue(ctx, retryAttemptContextKey{}, attempt)
 			}
-		}
 
-		response, err := client.Do(req) //nolint:bodyclose
-		if err != nil {
-			return fmt.Errorf("send request: %w", err)
-		}
-
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close()
-		}(response.Body)
+			event, err := sendAttempt(attemptCtx, client, reqHook, resHook, request, decoder, policy,
+				telemetry, attempt, attempt < attempts)
+			if event == nil {
+				return err
+			}
 
-		if resHook != nil {
-			bodyBytes, errRead := io.ReadAll(response.Body)
-			if errRead != nil && !errors.Is(errRead, io.EOF) {
-				return fmt.Errorf("read response body: %w", errRead)
+			event.RequestType = request.Type
+			event.Attempt = attempt
+			if policy.OnRetry != nil {
+				policy.OnRetry(attemptCtx, event)
 			}
-			response.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			if errHook := resHook.InterceptResponse(ctx, response); errHook != nil {
-				return errHook
+
+			if errSleep := sleepContext(ctx, event.Wait); errSleep != nil {
+				if err != nil {
+					return err
+				}
+
+				return fmt.Errorf("%w: status code: %d: %w", ErrRequestFailure, event.StatusCode, errSleep)
 			}
-			response.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
+	})
+
+	return fn
+}
+
+// sendAttempt sends the request once. It returns a non-nil RetryEvent when canRetry is true
+// and the attempt failed with a transient error, in which case the response is discarded.
+func sendAttempt[T any](ctx context.Context, client *http.Client, reqHook RequestInterceptorFunc,
+	resHook ResponseInterceptorFunc, request *Request[T], decoder ResponseDecoder, policy *RetryPolicy,
+	telemetry Telemetry, attempt int, canRetry bool,
+) (event *RetryEvent, err error) {
+	metric := &RequestMetric{Attempt: attempt}
+	if telemetry != nil {
+		var span Span
+		ctx, span = startAttemptSpan(ctx, telemetry, request, attempt)
+		start := time.Now()
+		defer func() {
+			metric.Duration = time.Since(start)
+			finishAttemptSpan(ctx, telemetry, span, request, metric, event, err)
+		}()
+	}
+
+	req, err := RequestWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if telemetry != nil {
+		telemetry.Inject(ctx, req.Header)
+	}
+
+	if reqHook != nil {
+		if errHook := reqHook(ctx, req); errHook != nil {
+			return nil, errHook
+		}
+	}
 
-		if err := decoder.Decode(ctx, response); err != nil {
-			return fmt.Errorf("core send: decode: %w", err)
+	response, err := client.Do(req) //nolint:bodyclose
+	if err != nil {
+		err = fmt.Errorf("send request: %w", err)
+		if canRetry && ctx.Err() == nil && !errors.Is(err, ErrCircuitOpen) {
+			return &RetryEvent{Err: err, Wait: policy.backoff(attempt, nil)}, err
 		}
 
-		return nil
-	})
+		return nil, err
+	}
 
-	return fn
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	metric.StatusCode = response.StatusCode
+	reportResponseMeta(ctx, request.Type, response)
+
+	if resHook != nil {
+		bodyBytes, errRead := io.ReadAll(response.Body)
+		if errRead != nil && !errors.Is(errRead, io.EOF) {
+			return nil, fmt.Errorf("read response body: %w", errRead)
+		}
+		response.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		if errHook := resHook.InterceptResponse(ctx, response); errHook != nil {
+			return nil, errHook
+		}
+		response.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	}
+
+	if canRetry && policy.retryableStatus(response.StatusCode) {
+		_, _ = io.Copy(io.Discard, response.Body)
+
+		return &RetryEvent{
+			StatusCode: response.StatusCode,
+			Wait:       policy.backoff(attempt, response),
+		}, nil
+	}
+
+	if err := decoder.Decode(ctx, response); err != nil {
+		return nil, fmt.Errorf("core send: decode: %w", err)
+	}
+
+	return nil, nil
 }
 
 func (core *CoreClient[T]) Send(ctx context.Context, request *Request[T], decoder ResponseDecoder) error {
@@ -263,6 +395,52 @@ const (
 	RequestTypeFetchConversationAnalytics
 	RequestTypeEnableTemplatesAnalytics
 	RequestTypeDisableButtonClickTracking
+	RequestTypeGetWebhookConfiguration
+	RequestTypeUpdateWebhookConfiguration
+	RequestTypeDebugToken
+	RequestTypeCreateTemplate
+	RequestTypeListTemplates
+	RequestTypeGetTemplate
+	RequestTypeUpdateTemplate
+	RequestTypeDeleteTemplate
+	RequestTypeListCatalogs
+	RequestTypeListProducts
+	RequestTypeGetProduct
+	RequestTypeGetCommerceSettings
+	RequestTypeUpdateCommerceSettings
+	RequestTypeCreateUploadSession
+	RequestTypeGetUploadSession
+	RequestTypeUploadFileChunk
+	RequestTypeListTemplateLibrary
+	RequestTypeCreateGroup
+	RequestTypeListGroups
+	RequestTypeGetGroup
+	RequestTypeUpdateGroup
+	RequestTypeDeleteGroup
+	RequestTypeGetGroupInviteLink
+	RequestTypeResetGroupInviteLink
+	RequestTypeRemoveGroupParticipants
+	RequestTypeListGroupJoinRequests
+	RequestTypeApproveGroupJoinRequests
+	RequestTypeRejectGroupJoinRequests
+	RequestTypeInitiateCall
+	RequestTypeManageCall
+	RequestTypeGetCallPermissions
+	RequestTypeGetPhoneNumberSettings
+	RequestTypeUpdatePhoneNumberSettings
+	RequestTypeBlockUsers
+	RequestTypeUnblockUsers
+	RequestTypeListBlockedUsers
+	RequestTypeSubscribeApp
+	RequestTypeListSubscribedApps
+	RequestTypeUnsubscribeApp
+	RequestTypeSetBusinessPublicKey
+	RequestTypeGetBusinessPublicKey
+	RequestTypeSyncAppData
+	RequestTypeExchangeCode
+	RequestTypeDownloadQRImage
+	RequestTypeGetConversationalAutomation
+	RequestTypeUpdateConversationalAutomation
 )
 
 // String returns the string representation of the request type.
@@ -304,6 +482,52 @@ func (r RequestType) String() string {
 		"fetch_conversation_analytics",
 		"enable_templates_analytics",
 		"disable_button_click_tracking",
+		"get_webhook_configuration",
+		"update_webhook_configuration",
+		"debug_token",
+		"create_template",
+		"list_templates",
+		"get_template",
+		"update_template",
+		"delete_template",
+		"list_catalogs",
+		"list_products",
+		"get_product",
+		"get_commerce_settings",
+		"update_commerce_settings",
+		"create_upload_session",
+		"get_upload_session",
+		"upload_file_chunk",
+		"list_template_library",
+		"create_group",
+		"list_groups",
+		"get_group",
+		"update_group",
+		"delete_group",
+		"get_group_invite_link",
+		"reset_group_invite_link",
+		"remove_group_participants",
+		"list_group_join_requests",
+		"approve_group_join_requests",
+		"reject_group_join_requests",
+		"initiate_call",
+		"manage_call",
+		"get_call_permissions",
+		"get_phone_number_settings",
+		"update_phone_number_settings",
+		"block_users",
+		"unblock_users",
+		"list_blocked_users",
+		"subscribe_app",
+		"list_subscribed_apps",
+		"unsubscribe_app",
+		"set_business_public_key",
+		"get_business_public_key",
+		"sync_app_data",
+		"exchange_code",
+		"download_qr_image",
+		"get_conversational_automation",
+		"update_conversational_automation",
 	}[r]
 }
 
@@ -330,9 +554,16 @@ type (
 		FormFile *FormFile
 	}
 
+	// FormFile is the file part of a RequestForm. It is read from Path unless Reader is set,
+	// in which case it is streamed from Reader as the request is sent and Filename names
+	// the part. Size is the number of bytes Reader yields, when it is known the request is
+	// sent with a Content-Length instead of chunked.
 	FormFile struct {
-		Name string
-		Path string
+		Name     string
+		Path     string
+		Reader   io.Reader
+		Filename string
+		Size     int64
 	}
 
 	RequestOption[T any] func(request *Request[T])
@@ -423,7 +654,7 @@ func WithRequestForm[T any](form *RequestForm) RequestOption[T] {
 // WithRequestAppSecret sets the app secret for the request and turns on secure requests.
 func WithRequestAppSecret[T any](appSecret string) RequestOption[T] {
 	return func(request *Request[T]) {
-		if request.AppSecret != "" {
+		if appSecret != "" {
 			request.AppSecret = appSecret
 		}
 	}
@@ -465,21 +696,39 @@ func RequestWithContext[T any](ctx context.Context, req *Request[T]) (*http.Requ
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate app secret proof: %w", err)
 		}
-		q.Set("appsecret_proof", proof)
+		q.Set(QueryParamAppSecretProof, proof)
 	}
 
 	parsedURL.RawQuery = q.Encode()
 
-	var body io.Reader
+	var (
+		body          io.Reader
+		contentLength int64
+	)
 	contentType := "application/json"
 
-	if req.Message != nil {
-		encodeResp, err := EncodePayload(req.Message)
+	var payload any
+	switch {
+	case req.Form != nil:
+		payload = req.Form
+	case req.Message != nil:
+		// raw bodies are sent as they are instead of being encoded as JSON.
+		switch message := any(*req.Message).(type) {
+		case io.Reader, []byte:
+			payload = message
+		default:
+			payload = req.Message
+		}
+	}
+
+	if payload != nil {
+		encodeResp, err := EncodePayload(payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encode request payload: %w", err)
 		}
 		body = encodeResp.Body
 		contentType = encodeResp.ContentType
+		contentLength = encodeResp.ContentLength
 	}
 
 	r, err := http.NewRequestWithContext(ctx, req.Method, parsedURL.String(), body)
@@ -487,6 +736,10 @@ func RequestWithContext[T any](ctx context.Context, req *Request[T]) (*http.Requ
 		return nil, fmt.Errorf("create http request: %w", err)
 	}
 
+	if contentLength > 0 {
+		r.ContentLength = contentLength
+	}
+
 	r.Header.Set("Content-Type", contentType)
 
 	if req.Bearer != "" {
@@ -500,9 +753,12 @@ func RequestWithContext[T any](ctx context.Context, req *Request[T]) (*http.Requ
 	return r, nil
 }
 
+// EncodeResponse is an encoded payload. ContentLength is set when it is known and Body
+// does not report it itself, like a streamed form file.
 type EncodeResponse struct {
-	Body        io.Reader
-	ContentType string
+	Body          io.Reader
+	ContentType   string
+	ContentLength int64
 }
 
 // EncodePayload takes different types of payloads (form data, readers, JSON) and returns an EncodeResponse.
@@ -514,6 +770,10 @@ func EncodePayload(payload any) (*EncodeResponse, error) {
 			ContentType: "application/json",
 		}, nil
 	case *RequestForm:
+		if p.FormFile != nil && p.FormFile.Reader != nil {
+			return streamFormData(p)
+		}
+
 		body, contentType, err := encodeFormData(p)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encode form data: %w", err)
@@ -592,6 +852,43 @@ func encodeFormData(formData *RequestForm) (io.Reader, string, error) {
 	return &payload, writer.FormDataContentType(), nil
 }
 
+// streamFormData encodes a form whose file is read from FormFile.Reader. Only the fields and
+// the multipart boundaries are buffered, the file content is read as the body is sent.
+func streamFormData(formData *RequestForm) (*EncodeResponse, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for key, value := range formData.Fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, fmt.Errorf("failed to write form field %s: %w", key, err)
+		}
+	}
+
+	if _, err := writer.CreateFormFile(formData.FormFile.Name, filepath.Base(formData.FormFile.Filename)); err != nil {
+		return nil, fmt.Errorf("failed to create form file part: %w", err)
+	}
+
+	head := bytes.Clone(buf.Bytes())
+	buf.Reset()
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	tail := bytes.Clone(buf.Bytes())
+
+	response := &EncodeResponse{
+		Body:        io.MultiReader(bytes.NewReader(head), formData.FormFile.Reader, bytes.NewReader(tail)),
+		ContentType: writer.FormDataContentType(),
+	}
+
+	if formData.FormFile.Size > 0 {
+		response.ContentLength = int64(len(head)) + formData.FormFile.Size + int64(len(tail))
+	}
+
+	return response, nil
+}
+
 type DecodeOptions struct {
 	DisallowUnknownFields bool
 	DisallowEmptyResponse bool
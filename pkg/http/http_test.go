@@ -635,3 +635,53 @@ func ExampleNewSender() {
 	// Just intercepted the response and status code: 200
 	// called after request send execution and the err is: <nil>
 }
+
+func TestRequestWithContext_StreamedForm(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("video bytes ", 1024)
+	request := whttp.MakeRequest[any](http.MethodPost, "http://localhost/media",
+		whttp.WithRequestForm[any](&whttp.RequestForm{
+			Fields: map[string]string{"messaging_product": "whatsapp"},
+			FormFile: &whttp.FormFile{
+				Name:     "file",
+				Filename: "clip.mp4",
+				Reader:   strings.NewReader(content),
+				Size:     int64(len(content)),
+			},
+		}))
+
+	req, err := whttp.RequestWithContext(context.Background(), request)
+	if err != nil {
+		t.Fatalf("RequestWithContext() error = %v", err)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	if req.ContentLength != int64(len(body)) {
+		t.Errorf("ContentLength = %d, body has %d bytes", req.ContentLength, len(body))
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm() error = %v", err)
+	}
+
+	if got := req.FormValue("messaging_product"); got != "whatsapp" {
+		t.Errorf("messaging_product = %q", got)
+	}
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		t.Fatalf("FormFile() error = %v", err)
+	}
+	defer file.Close()
+
+	got, _ := io.ReadAll(file)
+	if header.Filename != "clip.mp4" || string(got) != content {
+		t.Errorf("file %q has %d bytes, want clip.mp4 with %d bytes", header.Filename, len(got), len(content))
+	}
+}
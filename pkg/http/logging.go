@@ -0,0 +1,295 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the values of secrets in logged urls and bodies.
+const Redacted = "REDACTED"
+
+// DefaultLogBodyLimit is the number of bytes of a body that is logged when bodies are on.
+const DefaultLogBodyLimit = 4096
+
+// Log attribute keys.
+const (
+	LogKeyMethod       = "http.request.method"
+	LogKeyURL          = "url.full"
+	LogKeyStatusCode   = "http.response.status_code"
+	LogKeyDuration     = "duration"
+	LogKeyRequestBody  = "http.request.body"
+	LogKeyResponseBody = "http.response.body"
+	LogKeyError        = "error"
+)
+
+// SensitiveParams are the query parameters and body fields whose values are never logged.
+// The access token itself is sent in the Authorization header, which is not logged.
+var SensitiveParams = []string{ //nolint:gochecknoglobals // read only list of names
+	"access_token",
+	"appsecret_proof",
+	"app_secret",
+	"client_secret",
+	"fb_exchange_token",
+	"input_token",
+	"pin",
+}
+
+type (
+	// LoggingOption configures the logging of requests.
+	LoggingOption func(*loggingTransport)
+
+	loggingTransport struct {
+		next      http.RoundTripper
+		logger    *slog.Logger
+		level     slog.Level
+		bodies    bool
+		bodyLimit int
+		sensitive map[string]bool
+		pattern   *regexp.Regexp
+	}
+)
+
+// WithLogLevel sets the level of the logs of successful requests. Failed requests and
+// responses with a status code of 400 and above are logged at slog.LevelError and
+// slog.LevelWarn. The default is slog.LevelInfo.
+func WithLogLevel(level slog.Level) LoggingOption {
+	return func(t *loggingTransport) {
+		t.level = level
+	}
+}
+
+// WithLogBodies logs the JSON and url encoded bodies of requests and responses, with
+// their secrets redacted, up to limit bytes each. A limit of zero or less uses
+// DefaultLogBodyLimit. Multipart bodies, like media uploads, are never logged.
+func WithLogBodies(limit int) LoggingOption {
+	return func(t *loggingTransport) {
+		t.bodies = true
+		t.bodyLimit = limit
+		if limit <= 0 {
+			t.bodyLimit = DefaultLogBodyLimit
+		}
+	}
+}
+
+// WithLogRedactedParams adds names to the query parameters and body fields that are
+// redacted, on top of SensitiveParams.
+func WithLogRedactedParams(names ...string) LoggingOption {
+	return func(t *loggingTransport) {
+		for _, name := range names {
+			t.sensitive[name] = true
+		}
+	}
+}
+
+// NewLoggingTransport returns a http.RoundTripper that logs every request sent through
+// next with its method, url, status code and latency. Secrets in the url, and in bodies
+// when they are logged, are replaced by Redacted. A nil next uses
+// http.DefaultTransport.
+func NewLoggingTransport(logger *slog.Logger, next http.RoundTripper, options ...LoggingOption) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	t := &loggingTransport{
+		next:      next,
+		logger:    logger,
+		level:     slog.LevelInfo,
+		sensitive: make(map[string]bool, len(SensitiveParams)),
+	}
+
+	for _, name := range SensitiveParams {
+		t.sensitive[name] = true
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(t)
+		}
+	}
+
+	names := make([]string, 0, len(t.sensitive))
+	for name := range t.sensitive {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	t.pattern = regexp.MustCompile(`("(?:` + strings.Join(names, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	return t
+}
+
+// WithCoreClientLogger logs every request sent by the CoreClient to logger. It wraps the
+// transport of the http.Client of the CoreClient, including one set after it with
+// WithCoreClientHTTPClient or SetHTTPClient.
+//
+//	sender := whttp.NewAnySender(
+//		whttp.WithCoreClientLogger[any](logger, whttp.WithLogBodies(0)),
+//	)
+func WithCoreClientLogger[T any](logger *slog.Logger, options ...LoggingOption) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.logging = func(next http.RoundTripper) http.RoundTripper {
+			return NewLoggingTransport(logger, next, options...)
+		}
+	}
+}
+
+func (t *loggingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	attrs := []slog.Attr{
+		slog.String(LogKeyMethod, request.Method),
+		slog.String(LogKeyURL, t.redactURL(request.URL)),
+	}
+
+	if t.bodies && request.Body != nil && request.GetBody != nil && t.loggable(request.Header) {
+		if body, err := request.GetBody(); err == nil {
+			attrs = append(attrs, slog.String(LogKeyRequestBody, t.readBody(body, request.Header)))
+		}
+	}
+
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	attrs = append(attrs, slog.Duration(LogKeyDuration, time.Since(start)))
+
+	if err != nil {
+		attrs = append(attrs, slog.String(LogKeyError, err.Error()))
+		t.logger.LogAttrs(ctx, slog.LevelError, "whatsapp request failed", attrs...)
+
+		return response, err
+	}
+
+	attrs = append(attrs, slog.Int(LogKeyStatusCode, response.StatusCode))
+
+	if t.bodies && response.Body != nil && t.loggable(response.Header) {
+		body, errRead := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		if errRead == nil {
+			attrs = append(attrs, slog.String(LogKeyResponseBody,
+				t.readBody(io.NopCloser(bytes.NewReader(body)), response.Header)))
+		}
+	}
+
+	level := t.level
+	if response.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+
+	t.logger.LogAttrs(ctx, level, "whatsapp request", attrs...)
+
+	return response, nil
+}
+
+// loggable reports whether a body with the content type in header is logged.
+func (t *loggingTransport) loggable(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "application/x-www-form-urlencoded" ||
+		strings.HasPrefix(mediaType, "text/")
+}
+
+func (t *loggingTransport) readBody(body io.ReadCloser, header http.Header) string {
+	defer func() {
+		_ = body.Close()
+	}()
+
+	content, err := io.ReadAll(io.LimitReader(body, int64(t.bodyLimit)+1))
+	if err != nil {
+		return ""
+	}
+
+	truncated := len(content) > t.bodyLimit
+	if truncated {
+		content = content[:t.bodyLimit]
+	}
+
+	redacted := t.redactBody(string(content), header.Get("Content-Type"))
+	if truncated {
+		redacted += "...(truncated)"
+	}
+
+	return redacted
+}
+
+func (t *loggingTransport) redactBody(body, contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(body); err == nil {
+			return t.redactValues(values).Encode()
+		}
+	}
+
+	return t.pattern.ReplaceAllString(body, `${1}"`+Redacted+`"`)
+}
+
+func (t *loggingTransport) redactURL(u *url.URL) string {
+	return redactURL(u, t.sensitive)
+}
+
+func (t *loggingTransport) redactValues(values url.Values) url.Values {
+	return redactValues(values, t.sensitive)
+}
+
+var _ http.RoundTripper = (*loggingTransport)(nil)
+
+// RedactURL returns u as a string with the values of SensitiveParams and the password of
+// the user info replaced by Redacted.
+func RedactURL(u *url.URL) string {
+	sensitive := make(map[string]bool, len(SensitiveParams))
+	for _, name := range SensitiveParams {
+		sensitive[name] = true
+	}
+
+	return redactURL(u, sensitive)
+}
+
+func redactURL(u *url.URL, sensitive map[string]bool) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+
+	redacted := *u
+	redacted.RawQuery = redactValues(u.Query(), sensitive).Encode()
+
+	return redacted.Redacted()
+}
+
+func redactValues(values url.Values, sensitive map[string]bool) url.Values {
+	for name := range values {
+		if sensitive[name] {
+			values[name] = []string{Redacted}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,76 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestCoreClient_Logger(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "query-token" || r.URL.Query().Get("appsecret_proof") == "" {
+			t.Errorf("query = %q, want the secrets to reach the server", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"response-token","name":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	sender := whttp.NewSender[TestMessage](
+		whttp.WithCoreClientHTTPClient[TestMessage](&http.Client{}),
+		whttp.WithCoreClientLogger[TestMessage](logger, whttp.WithLogBodies(0)),
+	)
+
+	request := whttp.MakeRequest(http.MethodPost, server.URL,
+		whttp.WithRequestBearer[TestMessage]("bearer-token"),
+		whttp.WithRequestQueryParams[TestMessage](map[string]string{"access_token": "query-token", "fields": "id"}),
+		whttp.WithRequestMessage(&TestMessage{Name: "logged"}))
+	request.AppSecret = "app-secret"
+	request.SecureRequests = true
+
+	var got TestMessage
+	if err := sender.Send(context.Background(), request, whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{})); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got.Name != "ok" {
+		t.Errorf("decoded name = %q, want the response body to still be readable", got.Name)
+	}
+
+	for _, secret := range []string{"query-token", "response-token", "bearer-token", "app-secret"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("logs leak %q:\n%s", secret, logs.String())
+		}
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log entry: %v", err)
+	}
+
+	if entry[whttp.LogKeyStatusCode] != float64(http.StatusOK) || entry[whttp.LogKeyMethod] != http.MethodPost {
+		t.Errorf("log entry = %v", entry)
+	}
+
+	if url, _ := entry[whttp.LogKeyURL].(string); !strings.Contains(url, "appsecret_proof=REDACTED") ||
+		!strings.Contains(url, "fields=id") {
+		t.Errorf("logged url = %q", url)
+	}
+
+	if body, _ := entry[whttp.LogKeyRequestBody].(string); !strings.Contains(body, "logged") {
+		t.Errorf("logged request body = %q", body)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderAppUsage             = "X-App-Usage"
+	HeaderBusinessUseCaseUsage = "X-Business-Use-Case-Usage"
+	HeaderTraceID              = "X-Fb-Trace-Id"
+)
+
+type (
+	// Usage is the share, in percent, of a rate limit used up. Requests are throttled
+	// once any of the values reaches 100.
+	Usage struct {
+		CallCount    int `json:"call_count"`
+		TotalCPUTime int `json:"total_cputime"`
+		TotalTime    int `json:"total_time"`
+	}
+
+	// BusinessUseCaseUsage is the usage of the rate limit of a business use case, for
+	// example the WhatsApp Business Management API of a WhatsApp Business Account.
+	// EstimatedTimeToRegainAccess is zero unless the business is being throttled.
+	BusinessUseCaseUsage struct {
+		Usage
+		BusinessID                  string
+		Type                        string
+		EstimatedTimeToRegainAccess time.Duration
+	}
+
+	// ResponseMeta is the information the Graph API returns in the headers of a
+	// response: the usage of the rate limits and the trace id to quote to support.
+	// AppUsage is nil and BusinessUseCaseUsage empty when the response has no usage
+	// headers.
+	ResponseMeta struct {
+		RequestType          RequestType
+		StatusCode           int
+		TraceID              string
+		AppUsage             *Usage
+		BusinessUseCaseUsage []*BusinessUseCaseUsage
+	}
+
+	// ResponseMetaHook is called with the ResponseMeta of every response received,
+	// including the responses of attempts that are retried.
+	ResponseMetaHook func(ctx context.Context, meta *ResponseMeta)
+)
+
+// MaxUsage returns the highest usage percentage in the headers, 0 when there is none.
+func (meta *ResponseMeta) MaxUsage() int {
+	if meta == nil {
+		return 0
+	}
+
+	var usage int
+	if meta.AppUsage != nil {
+		usage = meta.AppUsage.max()
+	}
+
+	for _, bucUsage := range meta.BusinessUseCaseUsage {
+		usage = max(usage, bucUsage.max())
+	}
+
+	return usage
+}
+
+// RegainAccessIn returns the longest estimated time to regain access among the throttled
+// business use cases, 0 when none is throttled.
+func (meta *ResponseMeta) RegainAccessIn() time.Duration {
+	if meta == nil {
+		return 0
+	}
+
+	var wait time.Duration
+	for _, bucUsage := range meta.BusinessUseCaseUsage {
+		wait = max(wait, bucUsage.EstimatedTimeToRegainAccess)
+	}
+
+	return wait
+}
+
+func (usage *Usage) max() int {
+	return max(usage.CallCount, usage.TotalCPUTime, usage.TotalTime)
+}
+
+// ParseResponseMeta reads the usage and trace headers. Malformed usage headers are
+// ignored, they are informational and must not fail the request.
+func ParseResponseMeta(header http.Header) *ResponseMeta {
+	meta := &ResponseMeta{TraceID: header.Get(HeaderTraceID)}
+
+	if value := header.Get(HeaderAppUsage); value != "" {
+		var usage Usage
+		if err := json.Unmarshal([]byte(value), &usage); err == nil {
+			meta.AppUsage = &usage
+		}
+	}
+
+	if value := header.Get(HeaderBusinessUseCaseUsage); value != "" {
+		meta.BusinessUseCaseUsage = parseBusinessUseCaseUsage(value)
+	}
+
+	return meta
+}
+
+func parseBusinessUseCaseUsage(value string) []*BusinessUseCaseUsage {
+	var entries map[string][]struct {
+		Usage
+		Type                        string `json:"type"`
+		EstimatedTimeToRegainAccess int    `json:"estimated_time_to_regain_access"` // minutes
+	}
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil
+	}
+
+	var usages []*BusinessUseCaseUsage
+	for businessID, list := range entries {
+		for _, entry := range list {
+			usages = append(usages, &BusinessUseCaseUsage{
+				Usage:                       entry.Usage,
+				BusinessID:                  businessID,
+				Type:                        entry.Type,
+				EstimatedTimeToRegainAccess: time.Duration(entry.EstimatedTimeToRegainAccess) * time.Minute,
+			})
+		}
+	}
+
+	return usages
+}
+
+// WithCoreClientResponseMetaHook calls hook with the ResponseMeta of every response
+// received by the CoreClient.
+func WithCoreClientResponseMetaHook[T any](hook ResponseMetaHook) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.metaHook = hook
+	}
+}
+
+func (core *CoreClient[T]) SetResponseMetaHook(hook ResponseMetaHook) {
+	core.metaHook = hook
+}
+
+// responseMetaHook returns the hook reporting the ResponseMeta to the hook and the
+// throttle of the CoreClient, nil when it has neither.
+func (core *CoreClient[T]) responseMetaHook() ResponseMetaHook {
+	switch {
+	case core.throttle == nil:
+		return core.metaHook
+	case core.metaHook == nil:
+		return core.throttle.Observe
+	default:
+		return func(ctx context.Context, meta *ResponseMeta) {
+			core.throttle.Observe(ctx, meta)
+			core.metaHook(ctx, meta)
+		}
+	}
+}
+
+type (
+	responseMetaHookContextKey    struct{}
+	responseMetaCaptureContextKey struct{}
+
+	responseMetaCapture struct {
+		mu   sync.Mutex
+		meta *ResponseMeta
+	}
+)
+
+// CaptureResponseMeta returns a context that records the ResponseMeta of the responses
+// received while sending requests with it. The ResponseMeta of the last response is
+// read with ResponseMetaFromContext.
+//
+//	ctx = whttp.CaptureResponseMeta(ctx)
+//	resp, err := client.SendText(ctx, request)
+//	if meta, ok := whttp.ResponseMetaFromContext(ctx); ok {
+//		log.Printf("trace id: %s, usage: %d%%", meta.TraceID, meta.MaxUsage())
+//	}
+func CaptureResponseMeta(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseMetaCaptureContextKey{}, &responseMetaCapture{})
+}
+
+// ResponseMetaFromContext returns the ResponseMeta of the last response received with a
+// context created by CaptureResponseMeta.
+func ResponseMetaFromContext(ctx context.Context) (*ResponseMeta, bool) {
+	capture, ok := ctx.Value(responseMetaCaptureContextKey{}).(*responseMetaCapture)
+	if !ok {
+		return nil, false
+	}
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	return capture.meta, capture.meta != nil
+}
+
+// reportResponseMeta hands the ResponseMeta of response to the hook of the CoreClient and
+// to the capture of the context, if any.
+func reportResponseMeta(ctx context.Context, requestType RequestType, response *http.Response) {
+	hook, _ := ctx.Value(responseMetaHookContextKey{}).(ResponseMetaHook)
+	capture, _ := ctx.Value(responseMetaCaptureContextKey{}).(*responseMetaCapture)
+	if hook == nil && capture == nil {
+		return
+	}
+
+	meta := ParseResponseMeta(response.Header)
+	meta.RequestType = requestType
+	meta.StatusCode = response.StatusCode
+
+	if capture != nil {
+		capture.mu.Lock()
+		capture.meta = meta
+		capture.mu.Unlock()
+	}
+
+	if hook != nil {
+		hook(ctx, meta)
+	}
+}
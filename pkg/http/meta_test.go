@@ -0,0 +1,99 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestParseResponseMeta(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set(whttp.HeaderTraceID, "AbCdEf")
+	header.Set(whttp.HeaderAppUsage, `{"call_count":28,"total_time":25,"total_cputime":12}`)
+	header.Set(whttp.HeaderBusinessUseCaseUsage, `{"102290129340398":[{"type":"whatsapp_business_management",`+
+		`"call_count":95,"total_cputime":10,"total_time":20,"estimated_time_to_regain_access":3}]}`)
+
+	got := whttp.ParseResponseMeta(header)
+	want := &whttp.ResponseMeta{
+		TraceID:  "AbCdEf",
+		AppUsage: &whttp.Usage{CallCount: 28, TotalCPUTime: 12, TotalTime: 25},
+		BusinessUseCaseUsage: []*whttp.BusinessUseCaseUsage{{
+			Usage:                       whttp.Usage{CallCount: 95, TotalCPUTime: 10, TotalTime: 20},
+			BusinessID:                  "102290129340398",
+			Type:                        "whatsapp_business_management",
+			EstimatedTimeToRegainAccess: 3 * time.Minute,
+		}},
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseResponseMeta() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got.MaxUsage() != 95 || got.RegainAccessIn() != 3*time.Minute {
+		t.Errorf("MaxUsage() = %d, RegainAccessIn() = %v", got.MaxUsage(), got.RegainAccessIn())
+	}
+
+	header.Set(whttp.HeaderAppUsage, "not json")
+	if meta := whttp.ParseResponseMeta(header); meta.AppUsage != nil {
+		t.Errorf("ParseResponseMeta() AppUsage = %+v, want nil for a malformed header", meta.AppUsage)
+	}
+}
+
+func TestCoreClient_ResponseMeta(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(whttp.HeaderTraceID, "trace-1")
+		w.Header().Set(whttp.HeaderAppUsage, `{"call_count":40,"total_time":5,"total_cputime":5}`)
+		_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var hooked *whttp.ResponseMeta
+	sender := whttp.NewSender[TestMessage](
+		whttp.WithCoreClientResponseMetaHook[TestMessage](func(_ context.Context, meta *whttp.ResponseMeta) {
+			hooked = meta
+		}),
+	)
+
+	request := whttp.MakeRequest(http.MethodPost, server.URL,
+		whttp.WithRequestType[TestMessage](whttp.RequestTypeSendMessage),
+		whttp.WithRequestMessage(&TestMessage{Name: "meta"}))
+
+	ctx := whttp.CaptureResponseMeta(context.Background())
+	var got TestMessage
+	if err := sender.Send(ctx, request, whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{})); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	captured, ok := whttp.ResponseMetaFromContext(ctx)
+	if !ok {
+		t.Fatal("ResponseMetaFromContext() found no ResponseMeta")
+	}
+
+	want := &whttp.ResponseMeta{
+		RequestType: whttp.RequestTypeSendMessage,
+		StatusCode:  http.StatusOK,
+		TraceID:     "trace-1",
+		AppUsage:    &whttp.Usage{CallCount: 40, TotalCPUTime: 5, TotalTime: 5},
+	}
+
+	if diff := gcmp.Diff(want, captured); diff != "" {
+		t.Errorf("captured ResponseMeta mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff(want, hooked); diff != "" {
+		t.Errorf("hooked ResponseMeta mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, ok := whttp.ResponseMetaFromContext(context.Background()); ok {
+		t.Error("ResponseMetaFromContext() found a ResponseMeta without CaptureResponseMeta")
+	}
+}
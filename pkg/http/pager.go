@@ -0,0 +1,190 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"iter"
+)
+
+type (
+	// Paging is the paging object of Graph API list responses.
+	Paging struct {
+		Cursors  *Cursors `json:"cursors,omitempty"`
+		Next     string   `json:"next,omitempty"`
+		Previous string   `json:"previous,omitempty"`
+	}
+
+	Cursors struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	}
+
+	// Summary is returned with a page when the API reports the size of the list.
+	Summary struct {
+		TotalCount int `json:"total_count"`
+	}
+
+	// Page is a page of a list. Summary is nil when the API does not report the size of
+	// the list.
+	Page[T any] struct {
+		Data    []T
+		Paging  *Paging
+		Summary *Summary
+	}
+
+	// PageFunc fetches the page starting at the after cursor, the first page when after is
+	// empty. The page size and filters are fixed by the PageFunc.
+	PageFunc[T any] func(ctx context.Context, after string) (*Page[T], error)
+
+	// Pager walks a list one page at a time, following the after cursor of every page
+	// until the last one. The list APIs provide constructors returning a Pager.
+	//
+	//	pager := template.NewPager(client, &template.ListRequest{Limit: 100})
+	//	for tmpl, err := range pager.Items(ctx) {
+	//		...
+	//	}
+	Pager[T any] struct {
+		fetch    PageFunc[T]
+		after    string
+		current  string
+		buffered []T
+		done     bool
+		count    int
+		total    int
+	}
+)
+
+// NextCursor returns the cursor of the next page, empty on the last page.
+func (p *Paging) NextCursor() string {
+	if p == nil || p.Next == "" || p.Cursors == nil {
+		return ""
+	}
+
+	return p.Cursors.After
+}
+
+// NewPager creates a Pager fetching pages with fetch. A non empty after cursor resumes a
+// previous walk from that page, see Cursor.
+func NewPager[T any](fetch PageFunc[T], after string) *Pager[T] {
+	return &Pager[T]{fetch: fetch, after: after, total: -1}
+}
+
+// HasNext reports whether there are pages left.
+func (p *Pager[T]) HasNext() bool {
+	return !p.done || len(p.buffered) > 0
+}
+
+// Next fetches the next page. It returns nil and no error once the last page was fetched.
+// When an Items loop stopped in the middle of a page, Next returns the rest of that page
+// before fetching the next one.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if len(p.buffered) > 0 {
+		items := p.buffered
+		p.buffered = nil
+
+		return items, nil
+	}
+
+	if p.done {
+		return nil, nil
+	}
+
+	page, err := p.fetch(ctx, p.after)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Summary != nil {
+		p.total = page.Summary.TotalCount
+	}
+
+	// A cursor that does not move would fetch the same page forever.
+	next := page.Paging.NextCursor()
+	p.done = next == "" || next == p.after || len(page.Data) == 0
+	p.current = p.after
+	p.after = next
+	p.count += len(page.Data)
+
+	return page.Data, nil
+}
+
+// Cursor returns the after cursor of the next page. It can be saved and passed to the
+// constructor of the pager to resume the walk later. While the rest of a page is kept for
+// Items, Cursor returns the cursor of that page, so a resumed walk fetches it again rather
+// than skipping its remaining items.
+func (p *Pager[T]) Cursor() string {
+	if len(p.buffered) > 0 {
+		return p.current
+	}
+
+	return p.after
+}
+
+// Count returns the number of items fetched so far.
+func (p *Pager[T]) Count() int {
+	return p.count
+}
+
+// TotalCount returns the size of the list when the API reported it, -1 otherwise.
+func (p *Pager[T]) TotalCount() int {
+	return p.total
+}
+
+// All fetches the remaining pages and returns their items. The items fetched before an
+// error are returned with it.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for p.HasNext() {
+		page, err := p.Next(ctx)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, page...)
+	}
+
+	return items, nil
+}
+
+// Items iterates over the remaining items, fetching pages as needed. Iteration stops
+// after yielding an error. When the loop breaks in the middle of a page, the rest of the
+// page is kept and the next call to Items or Next starts with it.
+func (p *Pager[T]) Items(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.HasNext() {
+			page, err := p.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+
+				return
+			}
+
+			p.buffered = page
+			for len(p.buffered) > 0 {
+				item := p.buffered[0]
+				p.buffered = p.buffered[1:]
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// pages serves pages of numbers keyed by their after cursor.
+func pages(t *testing.T, fetched *[]string) whttp.PageFunc[int] {
+	t.Helper()
+
+	next := func(after string) *whttp.Paging {
+		return &whttp.Paging{Cursors: &whttp.Cursors{After: after}, Next: "https://graph.facebook.com/next"}
+	}
+
+	data := map[string]*whttp.Page[int]{
+		"":   {Data: []int{1, 2}, Paging: next("c1"), Summary: &whttp.Summary{TotalCount: 5}},
+		"c1": {Data: []int{3, 4}, Paging: next("c2")},
+		"c2": {Data: []int{5}, Paging: &whttp.Paging{Cursors: &whttp.Cursors{After: "c3"}}},
+	}
+
+	return func(_ context.Context, after string) (*whttp.Page[int], error) {
+		*fetched = append(*fetched, after)
+		page, ok := data[after]
+		if !ok {
+			return nil, errors.New("unknown cursor")
+		}
+
+		return page, nil
+	}
+}
+
+func TestPager(t *testing.T) {
+	t.Parallel()
+
+	var fetched []string
+	pager := whttp.NewPager(pages(t, &fetched), "")
+
+	items, err := pager.All(context.Background())
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	if diff := gcmp.Diff([]int{1, 2, 3, 4, 5}, items); diff != "" {
+		t.Errorf("All() mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]string{"", "c1", "c2"}, fetched); diff != "" {
+		t.Errorf("fetched cursors mismatch (-want +got):\n%s", diff)
+	}
+
+	if pager.HasNext() || pager.Count() != 5 || pager.TotalCount() != 5 || pager.Cursor() != "" {
+		t.Errorf("HasNext = %v, Count = %d, TotalCount = %d, Cursor = %q",
+			pager.HasNext(), pager.Count(), pager.TotalCount(), pager.Cursor())
+	}
+}
+
+func TestPager_Items(t *testing.T) {
+	t.Parallel()
+
+	var fetched []string
+	pager := whttp.NewPager(pages(t, &fetched), "c1")
+
+	var items []int
+	for item, err := range pager.Items(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items = append(items, item)
+		if item == 3 {
+			break
+		}
+	}
+
+	if diff := gcmp.Diff([]int{3}, items); diff != "" {
+		t.Errorf("Items() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The rest of the page is kept, a walk resumed from the cursor fetches the page again.
+	if pager.Cursor() != "c1" || !pager.HasNext() || pager.TotalCount() != -1 {
+		t.Errorf("Cursor = %q, HasNext = %v, TotalCount = %d", pager.Cursor(), pager.HasNext(), pager.TotalCount())
+	}
+
+	for item, err := range pager.Items(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items = append(items, item)
+	}
+
+	if diff := gcmp.Diff([]int{3, 4, 5}, items); diff != "" {
+		t.Errorf("Items() after break mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]string{"c1", "c2"}, fetched); diff != "" {
+		t.Errorf("fetched cursors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPager_StuckCursor(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	pager := whttp.NewPager(func(context.Context, string) (*whttp.Page[int], error) {
+		calls++
+
+		return &whttp.Page[int]{
+			Data:   []int{calls},
+			Paging: &whttp.Paging{Cursors: &whttp.Cursors{After: "same"}, Next: "https://graph.facebook.com/next"},
+		}, nil
+	}, "same")
+
+	if _, err := pager.All(context.Background()); err != nil || calls != 1 {
+		t.Errorf("All() error = %v after %d calls, want a single call", err, calls)
+	}
+}
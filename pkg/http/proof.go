@@ -0,0 +1,153 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+const (
+	QueryParamAppSecretProof = "appsecret_proof"
+	QueryParamAppSecretTime  = "appsecret_time"
+)
+
+type (
+	// AppSecretProvider returns the app secret used to compute the appsecret_proof of the
+	// requests. It is called for every request so that the secret can be rotated.
+	AppSecretProvider interface {
+		AppSecret(ctx context.Context) (string, error)
+	}
+
+	AppSecretProviderFunc func(ctx context.Context) (string, error)
+
+	AppSecretProofOption func(*appSecretProof)
+
+	appSecretProof struct {
+		provider AppSecretProvider
+		timed    bool
+		now      func() time.Time
+	}
+)
+
+func (fn AppSecretProviderFunc) AppSecret(ctx context.Context) (string, error) {
+	return fn(ctx)
+}
+
+// StaticAppSecret returns an AppSecretProvider that always returns secret.
+func StaticAppSecret(secret string) AppSecretProviderFunc {
+	return func(context.Context) (string, error) {
+		return secret, nil
+	}
+}
+
+// WithTimedAppSecretProof sends time based proofs, computed over the access token and the
+// current time, together with the appsecret_time parameter. now defaults to time.Now.
+func WithTimedAppSecretProof(now func() time.Time) AppSecretProofOption {
+	return func(proof *appSecretProof) {
+		proof.timed = true
+		if now != nil {
+			proof.now = now
+		}
+	}
+}
+
+// WithAppSecretProof makes the CoreClient attach an appsecret_proof, computed from the
+// bearer token and the secret returned by provider, to every request that has a bearer
+// token. Requests that already carry a proof, because they are made with
+// WithRequestSecured and WithRequestAppSecret, are sent unchanged.
+func WithAppSecretProof[T any](provider AppSecretProvider, options ...AppSecretProofOption) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.SetAppSecretProof(provider, options...)
+	}
+}
+
+// SetAppSecretProof is the setter of WithAppSecretProof, a nil provider turns the proofs off.
+func (core *CoreClient[T]) SetAppSecretProof(provider AppSecretProvider, options ...AppSecretProofOption) {
+	if provider == nil {
+		core.proof = nil
+
+		return
+	}
+
+	proof := &appSecretProof{
+		provider: provider,
+		now:      time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(proof)
+		}
+	}
+
+	core.proof = proof
+}
+
+// signRequest returns a copy of request with the proof in its query parameters, the
+// request itself is not modified since callers may reuse it.
+func signRequest[T any](ctx context.Context, proof *appSecretProof, request *Request[T]) (*Request[T], error) {
+	if proof == nil || request == nil || request.Bearer == "" || request.SecureRequests {
+		return request, nil
+	}
+
+	if _, ok := request.QueryParams[QueryParamAppSecretProof]; ok {
+		return request, nil
+	}
+
+	secret, err := proof.provider.AppSecret(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", crypto.ErrCreateAppSecretProof, err)
+	}
+
+	signed := *request
+	signed.QueryParams = maps.Clone(request.QueryParams)
+	if signed.QueryParams == nil {
+		signed.QueryParams = make(map[string]string, 2) //nolint:mnd // proof and time
+	}
+
+	if !proof.timed {
+		value, err := crypto.GenerateAppSecretProof(request.Bearer, secret)
+		if err != nil {
+			return nil, err
+		}
+
+		signed.QueryParams[QueryParamAppSecretProof] = value
+
+		return &signed, nil
+	}
+
+	at := proof.now()
+
+	value, err := crypto.GenerateTimedAppSecretProof(request.Bearer, secret, at)
+	if err != nil {
+		return nil, err
+	}
+
+	signed.QueryParams[QueryParamAppSecretProof] = value
+	signed.QueryParams[QueryParamAppSecretTime] = strconv.FormatInt(at.Unix(), 10)
+
+	return &signed, nil
+}
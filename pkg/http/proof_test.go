@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestCoreClient_AppSecretProof(t *testing.T) {
+	t.Parallel()
+
+	at := time.Unix(1700000000, 0)
+	plain, _ := crypto.GenerateAppSecretProof("token", "secret")
+	timed, _ := crypto.GenerateTimedAppSecretProof("token", "secret", at)
+
+	tests := []struct {
+		name    string
+		options []whttp.AppSecretProofOption
+		bearer  string
+		want    url.Values
+	}{
+		{
+			name:   "proof",
+			bearer: "token",
+			want:   url.Values{"fields": {"id"}, "appsecret_proof": {plain}},
+		},
+		{
+			name:    "timed proof",
+			options: []whttp.AppSecretProofOption{whttp.WithTimedAppSecretProof(func() time.Time { return at })},
+			bearer:  "token",
+			want:    url.Values{"fields": {"id"}, "appsecret_proof": {timed}, "appsecret_time": {"1700000000"}},
+		},
+		{
+			name: "no bearer",
+			want: url.Values{"fields": {"id"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Query()
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			sender := whttp.NewAnySender(whttp.WithAppSecretProof[any](whttp.StaticAppSecret("secret"), tt.options...))
+
+			params := map[string]string{"fields": "id"}
+			req := whttp.MakeRequest[any](http.MethodGet, server.URL,
+				whttp.WithRequestBearer[any](tt.bearer),
+				whttp.WithRequestQueryParams[any](params),
+			)
+
+			if err := sender.Send(context.Background(), req, whttp.ResponseDecoderFunc(
+				func(context.Context, *http.Response) error { return nil })); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if diff := gcmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("query mismatch (-want +got):\n%s", diff)
+			}
+
+			if len(params) != 1 {
+				t.Errorf("request query params were modified: %v", params)
+			}
+		})
+	}
+}
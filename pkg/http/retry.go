@@ -0,0 +1,197 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryPolicy configures how the CoreClient retries requests that fail with a transient
+	// error. A request is retried when sending it fails at the transport level or when the
+	// response status code is one of RetryableStatusCodes.
+	//
+	// The wait between attempts grows exponentially from InitialBackoff by Multiplier up to
+	// MaxBackoff, and is randomized by Jitter, a fraction between 0 and 1 of the computed
+	// backoff. A Retry-After header on the response takes precedence over the backoff.
+	//
+	// Note that 5xx responses to POST requests may be retried even though the API could
+	// have processed the request, set RetryableStatusCodes to only 429 to avoid that.
+	RetryPolicy struct {
+		MaxAttempts          int
+		InitialBackoff       time.Duration
+		MaxBackoff           time.Duration
+		Multiplier           float64
+		Jitter               float64
+		RetryableStatusCodes []int
+		OnRetry              RetryHookFunc
+	}
+
+	// RetryEvent describes a failed attempt that is about to be retried. StatusCode is zero
+	// when the attempt failed at the transport level, in which case Err is set.
+	RetryEvent struct {
+		RequestType RequestType
+		Attempt     int
+		StatusCode  int
+		Err         error
+		Wait        time.Duration
+	}
+
+	// RetryHookFunc is called before waiting for the next attempt.
+	RetryHookFunc func(ctx context.Context, event *RetryEvent)
+)
+
+// DefaultRetryPolicy returns a policy with 3 attempts that retries 429 and 5xx responses
+// starting with a 500ms backoff capped at 10s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,                      //nolint:mnd // default attempts
+		InitialBackoff: 500 * time.Millisecond, //nolint:mnd // default backoff
+		MaxBackoff:     10 * time.Second,       //nolint:mnd // default backoff cap
+		Multiplier:     2,                      //nolint:mnd // doubles on each attempt
+		Jitter:         0.2,                    //nolint:mnd // 20% jitter
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithCoreClientRetryPolicy makes the CoreClient retry transient failures according to
+// policy. Each attempt goes through the request and response interceptors, which can read
+// the attempt number with RetryAttemptFromContext.
+func WithCoreClientRetryPolicy[T any](policy *RetryPolicy) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.retry = policy
+	}
+}
+
+func (core *CoreClient[T]) SetRetryPolicy(policy *RetryPolicy) {
+	core.retry = policy
+}
+
+type retryAttemptContextKey struct{}
+
+// RetryAttemptFromContext returns the attempt number, starting at 1, of the request being
+// sent by a CoreClient configured with a RetryPolicy.
+func RetryAttemptFromContext(ctx context.Context) (int, bool) {
+	attempt, ok := ctx.Value(retryAttemptContextKey{}).(int)
+
+	return attempt, ok
+}
+
+func (policy *RetryPolicy) maxAttempts() int {
+	if policy == nil || policy.MaxAttempts < 1 {
+		return 1
+	}
+
+	return policy.MaxAttempts
+}
+
+func (policy *RetryPolicy) retryableStatus(code int) bool {
+	return slices.Contains(policy.RetryableStatusCodes, code)
+}
+
+// backoff returns the wait before the attempt following attempt.
+func (policy *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if wait, ok := parseRetryAfter(response); ok {
+		if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+
+		return wait
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && wait > float64(policy.MaxBackoff) {
+		wait = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		jitter := math.Min(policy.Jitter, 1)
+		wait += wait * jitter * (2*rand.Float64() - 1) //nolint:gosec,mnd // jitter in [-j, +j]
+	}
+
+	return time.Duration(wait)
+}
+
+func parseRetryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// replayable reports whether the request payload can be encoded again for another attempt,
+// streamed payloads are consumed by the first attempt.
+func replayable[T any](request *Request[T]) bool {
+	if request.Form != nil && request.Form.FormFile != nil && request.Form.FormFile.Reader != nil {
+		return false
+	}
+
+	if request.Message == nil {
+		return true
+	}
+
+	_, isReader := any(*request.Message).(io.Reader)
+
+	return !isReader
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
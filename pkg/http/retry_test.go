@@ -0,0 +1,151 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func TestCoreClient_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{name: "succeeds after transient failures", statuses: []int{503, 429, 200}, maxAttempts: 3, wantAttempts: 3},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500}, maxAttempts: 2, wantAttempts: 2, wantErr: true},
+		{name: "does not retry client errors", statuses: []int{400, 200}, maxAttempts: 3, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := calls.Add(1)
+				if tt.statuses[n-1] == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(tt.statuses[n-1])
+				_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+			}))
+			t.Cleanup(server.Close)
+
+			var (
+				attempts []int
+				retried  []int
+			)
+
+			policy := whttp.DefaultRetryPolicy()
+			policy.MaxAttempts = tt.maxAttempts
+			policy.InitialBackoff = time.Millisecond
+			policy.OnRetry = func(_ context.Context, event *whttp.RetryEvent) {
+				retried = append(retried, event.StatusCode)
+			}
+
+			sender := whttp.NewSender[TestMessage](
+				whttp.WithCoreClientRetryPolicy[TestMessage](policy),
+				whttp.WithCoreClientRequestInterceptor[TestMessage](func(ctx context.Context, _ *http.Request) error {
+					attempt, _ := whttp.RetryAttemptFromContext(ctx)
+					attempts = append(attempts, attempt)
+
+					return nil
+				}),
+			)
+
+			request := whttp.MakeRequest(http.MethodPost, server.URL,
+				whttp.WithRequestMessage(&TestMessage{Name: "retry"}))
+
+			var got TestMessage
+			err := sender.Send(context.Background(), request,
+				whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if calls.Load() != tt.wantAttempts {
+				t.Errorf("server calls = %d, want %d", calls.Load(), tt.wantAttempts)
+			}
+
+			if len(attempts) != int(tt.wantAttempts) || attempts[len(attempts)-1] != int(tt.wantAttempts) {
+				t.Errorf("interceptor attempts = %v", attempts)
+			}
+
+			if diff := gcmp.Diff(tt.statuses[:tt.wantAttempts-1], retried, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("retried statuses mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCoreClient_RetryContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	sender := whttp.NewAnySender(whttp.WithCoreClientRetryPolicy[any](whttp.DefaultRetryPolicy()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := sender.Send(ctx, whttp.MakeRequest[any](http.MethodGet, server.URL),
+		whttp.ResponseDecoderJSON[any](nil, whttp.DecodeOptions{}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded while honoring Retry-After, got %v", err)
+	}
+}
+
+func TestCoreClient_RetryAfterCappedByMaxBackoff(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var waits []time.Duration
+	policy := whttp.DefaultRetryPolicy()
+	policy.MaxBackoff = 10 * time.Millisecond
+	policy.OnRetry = func(_ context.Context, event *whttp.RetryEvent) {
+		waits = append(waits, event.Wait)
+	}
+	sender := whttp.NewSender[TestMessage](whttp.WithCoreClientRetryPolicy[TestMessage](policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got TestMessage
+	err := sender.Send(ctx, whttp.MakeRequest[TestMessage](http.MethodGet, server.URL),
+		whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{}))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if diff := gcmp.Diff([]time.Duration{10 * time.Millisecond}, waits); diff != "" {
+		t.Errorf("retry waits mismatch (-want +got):\n%s", diff)
+	}
+}
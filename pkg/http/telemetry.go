@@ -0,0 +1,135 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type (
+	// Telemetry instruments the requests sent by a CoreClient. It is the seam between this
+	// package and a tracing and metrics SDK, the OpenTelemetry implementation is in the
+	// extras/otel module: it starts client spans, records RequestMetric in a duration
+	// histogram and an error counter keyed by RequestType, and injects the trace context
+	// with a propagator.
+	//
+	// Every attempt of a request is instrumented separately, so retries show up as sibling
+	// spans and separate measurements.
+	Telemetry interface {
+		// StartSpan starts the span of an attempt. The returned context is used to send
+		// the attempt and is passed to Inject and RecordRequest.
+		StartSpan(ctx context.Context, name string, attributes []Attribute) (context.Context, Span)
+
+		// Inject propagates the trace context of ctx to the headers of the outgoing request.
+		Inject(ctx context.Context, header http.Header)
+
+		// RecordRequest records a finished attempt.
+		RecordRequest(ctx context.Context, metric *RequestMetric)
+	}
+
+	Span interface {
+		SetAttributes(attributes ...Attribute)
+		RecordError(err error)
+		End()
+	}
+
+	// Attribute is a key value pair attached to spans.
+	Attribute struct {
+		Key   string
+		Value any
+	}
+
+	// RequestMetric describes a finished attempt. StatusCode is zero when the attempt failed
+	// before a response was received. Err is set for failed attempts, including the ones
+	// that are retried.
+	RequestMetric struct {
+		RequestType RequestType
+		Method      string
+		Attempt     int
+		StatusCode  int
+		Duration    time.Duration
+		Err         error
+	}
+)
+
+// Attribute keys set on spans.
+const (
+	AttributeRequestType = "whatsapp.request.type"
+	AttributeAttempt     = "whatsapp.request.attempt"
+	AttributeHTTPMethod  = "http.request.method"
+	AttributeStatusCode  = "http.response.status_code"
+)
+
+// WithCoreClientTelemetry instruments every request sent by the CoreClient.
+func WithCoreClientTelemetry[T any](telemetry Telemetry) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.telemetry = telemetry
+	}
+}
+
+func (core *CoreClient[T]) SetTelemetry(telemetry Telemetry) {
+	core.telemetry = telemetry
+}
+
+// SpanName returns the name of the spans of requestType, for example
+// "whatsapp send message".
+func SpanName(requestType RequestType) string {
+	return "whatsapp " + requestType.String()
+}
+
+func startAttemptSpan[T any](ctx context.Context, telemetry Telemetry, request *Request[T],
+	attempt int,
+) (context.Context, Span) {
+	return telemetry.StartSpan(ctx, SpanName(request.Type), []Attribute{
+		{Key: AttributeRequestType, Value: request.Type.String()},
+		{Key: AttributeHTTPMethod, Value: request.Method},
+		{Key: AttributeAttempt, Value: attempt},
+	})
+}
+
+func finishAttemptSpan[T any](ctx context.Context, telemetry Telemetry, span Span, request *Request[T],
+	metric *RequestMetric, retry *RetryEvent, err error,
+) {
+	metric.RequestType = request.Type
+	metric.Method = request.Method
+
+	switch {
+	case err != nil:
+		metric.Err = err
+	case retry != nil && retry.Err != nil:
+		metric.Err = retry.Err
+	case retry != nil:
+		metric.Err = fmt.Errorf("%w: status code: %d", ErrRequestFailure, retry.StatusCode)
+	}
+
+	if metric.StatusCode != 0 {
+		span.SetAttributes(Attribute{Key: AttributeStatusCode, Value: metric.StatusCode})
+	}
+
+	if metric.Err != nil {
+		span.RecordError(metric.Err)
+	}
+
+	telemetry.RecordRequest(ctx, metric)
+	span.End()
+}
@@ -0,0 +1,145 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type traceKey struct{}
+
+type fakeSpan struct {
+	name  string
+	attrs []whttp.Attribute
+	errs  int
+	ended bool
+}
+
+func (s *fakeSpan) SetAttributes(attributes ...whttp.Attribute) {
+	s.attrs = append(s.attrs, attributes...)
+}
+
+func (s *fakeSpan) RecordError(error) { s.errs++ }
+
+func (s *fakeSpan) End() { s.ended = true }
+
+type fakeTelemetry struct {
+	mu      sync.Mutex
+	spans   []*fakeSpan
+	metrics []whttp.RequestMetric
+}
+
+func (f *fakeTelemetry) StartSpan(ctx context.Context, name string,
+	attributes []whttp.Attribute,
+) (context.Context, whttp.Span) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	span := &fakeSpan{name: name, attrs: attributes}
+	f.spans = append(f.spans, span)
+
+	return context.WithValue(ctx, traceKey{}, name), span
+}
+
+func (f *fakeTelemetry) Inject(ctx context.Context, header http.Header) {
+	name, _ := ctx.Value(traceKey{}).(string)
+	header.Set("Traceparent", name)
+}
+
+func (f *fakeTelemetry) RecordRequest(_ context.Context, metric *whttp.RequestMetric) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metrics = append(f.metrics, *metric)
+}
+
+func TestCoreClient_Telemetry(t *testing.T) {
+	t.Parallel()
+
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	var (
+		calls       int
+		traceparent []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = append(traceparent, r.Header.Get("Traceparent"))
+		w.WriteHeader(statuses[calls])
+		calls++
+		_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	policy := whttp.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	telemetry := &fakeTelemetry{}
+	sender := whttp.NewSender[TestMessage](
+		whttp.WithCoreClientRetryPolicy[TestMessage](policy),
+		whttp.WithCoreClientTelemetry[TestMessage](telemetry),
+	)
+
+	request := whttp.MakeRequest(http.MethodPost, server.URL,
+		whttp.WithRequestType[TestMessage](whttp.RequestTypeSendMessage),
+		whttp.WithRequestMessage(&TestMessage{Name: "telemetry"}))
+
+	var got TestMessage
+	if err := sender.Send(context.Background(), request, whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{})); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	name := whttp.SpanName(whttp.RequestTypeSendMessage)
+	if diff := gcmp.Diff([]string{name, name}, traceparent); diff != "" {
+		t.Errorf("propagated headers mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(telemetry.spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(telemetry.spans))
+	}
+
+	for i, span := range telemetry.spans {
+		if !span.ended {
+			t.Errorf("span %d was not ended", i)
+		}
+	}
+
+	if telemetry.spans[0].errs != 1 || telemetry.spans[1].errs != 0 {
+		t.Errorf("recorded errors = %d, %d, want 1, 0", telemetry.spans[0].errs, telemetry.spans[1].errs)
+	}
+
+	type summary struct {
+		RequestType whttp.RequestType
+		Method      string
+		Attempt     int
+		StatusCode  int
+		Failed      bool
+	}
+
+	want := []summary{
+		{RequestType: whttp.RequestTypeSendMessage, Method: http.MethodPost, Attempt: 1, StatusCode: 503, Failed: true},
+		{RequestType: whttp.RequestTypeSendMessage, Method: http.MethodPost, Attempt: 2, StatusCode: 200},
+	}
+
+	gotMetrics := make([]summary, 0, len(telemetry.metrics))
+	for _, metric := range telemetry.metrics {
+		if metric.Duration <= 0 {
+			t.Errorf("attempt %d has no duration", metric.Attempt)
+		}
+
+		gotMetrics = append(gotMetrics, summary{
+			RequestType: metric.RequestType,
+			Method:      metric.Method,
+			Attempt:     metric.Attempt,
+			StatusCode:  metric.StatusCode,
+			Failed:      metric.Err != nil,
+		})
+	}
+
+	if diff := gcmp.Diff(want, gotMetrics); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// ThrottlePolicy configures how a Throttle reacts to the usage reported in the
+	// x-app-usage and x-business-use-case-usage headers, see ResponseMeta.MaxUsage.
+	//
+	// Once the usage reaches SlowThreshold percent every send waits SlowDelay. Once it
+	// reaches PauseThreshold percent, or the API reports an estimated time to regain
+	// access, sends are held until that time has passed, or for Pause when the API did
+	// not give an estimate.
+	ThrottlePolicy struct {
+		SlowThreshold  int
+		PauseThreshold int
+		SlowDelay      time.Duration
+		Pause          time.Duration
+		OnChange       ThrottleHookFunc
+	}
+
+	// ThrottleState is the state of a Throttle.
+	ThrottleState int
+
+	// ThrottleEvent describes a change of the state of a Throttle. Until is the time sends
+	// resume when State is ThrottleStatePaused.
+	ThrottleEvent struct {
+		State ThrottleState
+		Usage int
+		Until time.Time
+		Meta  *ResponseMeta
+	}
+
+	// ThrottleHookFunc is called every time the state of a Throttle changes.
+	ThrottleHookFunc func(ctx context.Context, event *ThrottleEvent)
+
+	// Throttle slows down and pauses sends as the rate limits of the API get used up. It
+	// is safe for concurrent use, and is meant to be shared by the clients sending with the
+	// same app and business account since they share the same limits.
+	Throttle struct {
+		policy *ThrottlePolicy
+		mu     sync.Mutex
+		state  ThrottleState
+		until  time.Time
+		now    func() time.Time
+	}
+)
+
+const (
+	ThrottleStateNormal ThrottleState = iota
+	ThrottleStateSlowed
+	ThrottleStatePaused
+)
+
+func (s ThrottleState) String() string {
+	switch s {
+	case ThrottleStateNormal:
+		return "normal"
+	case ThrottleStateSlowed:
+		return "slowed"
+	case ThrottleStatePaused:
+		return "paused"
+	default:
+		return fmt.Sprintf("ThrottleState(%d)", int(s))
+	}
+}
+
+// DefaultThrottlePolicy returns a policy that slows sends by 1s from 75% usage and pauses
+// them from 100% usage, for 1 minute when the API does not say when access is regained.
+func DefaultThrottlePolicy() *ThrottlePolicy {
+	return &ThrottlePolicy{
+		SlowThreshold:  75,          //nolint:mnd // default slow threshold in percent
+		PauseThreshold: 100,         //nolint:mnd // the limit is reached at 100 percent
+		SlowDelay:      time.Second, // default delay between sends when slowed
+		Pause:          time.Minute, // default pause without an estimate from the API
+	}
+}
+
+// NewThrottle creates a Throttle applying policy, DefaultThrottlePolicy when nil.
+func NewThrottle(policy *ThrottlePolicy) *Throttle {
+	if policy == nil {
+		policy = DefaultThrottlePolicy()
+	}
+
+	return &Throttle{policy: policy, now: time.Now}
+}
+
+// WithCoreClientThrottle makes the CoreClient wait for throttle before sending requests
+// and feeds it the ResponseMeta of every response.
+func WithCoreClientThrottle[T any](throttle *Throttle) CoreClientOption[T] {
+	return func(client *CoreClient[T]) {
+		client.throttle = throttle
+	}
+}
+
+func (core *CoreClient[T]) SetThrottle(throttle *Throttle) {
+	core.throttle = throttle
+}
+
+// State returns the current state of the throttle.
+func (t *Throttle) State() ThrottleState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == ThrottleStatePaused && !t.now().Before(t.until) {
+		return ThrottleStateSlowed
+	}
+
+	return t.state
+}
+
+// Wait blocks until a request can be sent: for SlowDelay when the throttle is slowed and
+// until the end of the pause when it is paused. It returns early with the error of ctx.
+func (t *Throttle) Wait(ctx context.Context) error {
+	t.mu.Lock()
+	var wait time.Duration
+	switch t.state {
+	case ThrottleStateSlowed:
+		wait = t.policy.SlowDelay
+	case ThrottleStatePaused:
+		// After the pause requests go out slowly until a response reports the new usage.
+		wait = max(t.until.Sub(t.now()), t.policy.SlowDelay)
+	case ThrottleStateNormal:
+	}
+	t.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	if err := sleepContext(ctx, wait); err != nil {
+		return fmt.Errorf("%w: %w", ErrThrottled, err)
+	}
+
+	return nil
+}
+
+// Observe updates the state of the throttle from the usage reported by a response.
+// Responses without usage headers leave the state unchanged.
+func (t *Throttle) Observe(ctx context.Context, meta *ResponseMeta) {
+	if meta == nil || (meta.AppUsage == nil && len(meta.BusinessUseCaseUsage) == 0) {
+		return
+	}
+
+	usage, regain := meta.MaxUsage(), meta.RegainAccessIn()
+	event := &ThrottleEvent{Usage: usage, Meta: meta}
+
+	switch {
+	case regain > 0 || (t.policy.PauseThreshold > 0 && usage >= t.policy.PauseThreshold):
+		if regain <= 0 {
+			regain = t.policy.Pause
+		}
+		event.State, event.Until = ThrottleStatePaused, t.now().Add(regain)
+	case t.policy.SlowThreshold > 0 && usage >= t.policy.SlowThreshold:
+		event.State = ThrottleStateSlowed
+	default:
+		event.State = ThrottleStateNormal
+	}
+
+	t.mu.Lock()
+	changed := event.State != t.state || !event.Until.Equal(t.until)
+	if t.state == ThrottleStatePaused && event.State == ThrottleStatePaused {
+		// Responses to requests sent before the pause keep reporting the old estimate.
+		changed = event.Until.After(t.until)
+		event.Until = later(event.Until, t.until)
+	}
+	t.state, t.until = event.State, event.Until
+	t.mu.Unlock()
+
+	if changed && t.policy.OnChange != nil {
+		t.policy.OnChange(ctx, event)
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+// ErrThrottled is returned when the context is done while a send waits for the Throttle.
+const ErrThrottled = httpError("request throttled")
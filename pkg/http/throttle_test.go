@@ -0,0 +1,120 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+func usageMeta(callCount, regainMinutes int) *whttp.ResponseMeta {
+	return &whttp.ResponseMeta{
+		BusinessUseCaseUsage: []*whttp.BusinessUseCaseUsage{{
+			Usage:                       whttp.Usage{CallCount: callCount},
+			EstimatedTimeToRegainAccess: time.Duration(regainMinutes) * time.Minute,
+		}},
+	}
+}
+
+func TestThrottle_Observe(t *testing.T) {
+	t.Parallel()
+
+	var events []whttp.ThrottleState
+	policy := whttp.DefaultThrottlePolicy()
+	policy.OnChange = func(_ context.Context, event *whttp.ThrottleEvent) {
+		events = append(events, event.State)
+	}
+	throttle := whttp.NewThrottle(policy)
+	ctx := context.Background()
+
+	steps := []struct {
+		meta *whttp.ResponseMeta
+		want whttp.ThrottleState
+	}{
+		{meta: usageMeta(10, 0), want: whttp.ThrottleStateNormal},
+		{meta: &whttp.ResponseMeta{TraceID: "no usage headers"}, want: whttp.ThrottleStateNormal},
+		{meta: usageMeta(80, 0), want: whttp.ThrottleStateSlowed},
+		{meta: usageMeta(85, 0), want: whttp.ThrottleStateSlowed},
+		{meta: usageMeta(100, 2), want: whttp.ThrottleStatePaused},
+		{meta: usageMeta(100, 1), want: whttp.ThrottleStatePaused},
+		{meta: usageMeta(20, 0), want: whttp.ThrottleStateNormal},
+	}
+
+	for i, step := range steps {
+		throttle.Observe(ctx, step.meta)
+		if got := throttle.State(); got != step.want {
+			t.Errorf("step %d: State() = %v, want %v", i, got, step.want)
+		}
+	}
+
+	want := []whttp.ThrottleState{whttp.ThrottleStateSlowed, whttp.ThrottleStatePaused, whttp.ThrottleStateNormal}
+	if len(events) != len(want) {
+		t.Fatalf("OnChange events = %v, want %v", events, want)
+	}
+
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("OnChange events = %v, want %v", events, want)
+		}
+	}
+}
+
+func TestThrottle_Wait(t *testing.T) {
+	t.Parallel()
+
+	throttle := whttp.NewThrottle(nil)
+	throttle.Observe(context.Background(), usageMeta(100, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := throttle.Wait(ctx); !errors.Is(err, whttp.ErrThrottled) {
+		t.Fatalf("Wait() error = %v, want ErrThrottled", err)
+	}
+
+	throttle.Observe(context.Background(), usageMeta(0, 0))
+	if err := throttle.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
+
+func TestCoreClient_Throttle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(whttp.HeaderAppUsage, `{"call_count":90,"total_time":5,"total_cputime":5}`)
+		_, _ = w.Write([]byte(`{"name":"ok","value":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	policy := whttp.DefaultThrottlePolicy()
+	policy.SlowDelay = time.Millisecond
+	throttle := whttp.NewThrottle(policy)
+
+	var hooked bool
+	sender := whttp.NewSender[TestMessage](
+		whttp.WithCoreClientThrottle[TestMessage](throttle),
+		whttp.WithCoreClientResponseMetaHook[TestMessage](func(context.Context, *whttp.ResponseMeta) {
+			hooked = true
+		}),
+	)
+
+	for range 2 {
+		request := whttp.MakeRequest(http.MethodPost, server.URL,
+			whttp.WithRequestMessage(&TestMessage{Name: "throttle"}))
+
+		var got TestMessage
+		if err := sender.Send(context.Background(), request,
+			whttp.ResponseDecoderJSON(&got, whttp.DecodeOptions{})); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	if throttle.State() != whttp.ThrottleStateSlowed || !hooked {
+		t.Errorf("State() = %v, hooked = %v, want slowed and hooked", throttle.State(), hooked)
+	}
+}
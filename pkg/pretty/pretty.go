@@ -0,0 +1,368 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package pretty renders webhook notifications and outgoing messages as stable, optionally
+// colorized trees for debugging, and diffs two payloads. Values are compared and printed
+// through their JSON encoding, so what is shown is what goes over the wire, with object
+// keys sorted.
+//
+//	fmt.Print(pretty.Sprint(notification))
+//
+//	if diff := pretty.Diff(want, got); diff != "" {
+//		t.Errorf("payload mismatch (-want +got):\n%s", diff)
+//	}
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape codes used when Color is set.
+const (
+	colorReset  = "\x1b[0m"
+	colorKey    = "\x1b[36m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[33m"
+	colorBool   = "\x1b[35m"
+	colorNull   = "\x1b[90m"
+	colorRemove = "\x1b[31m"
+	colorAdd    = "\x1b[32m"
+)
+
+const defaultIndent = "  "
+
+// Printer renders values as indented trees. Objects are printed as "key: value" lines
+// ordered by key and arrays as "- value" lines. The zero value prints without colors.
+type Printer struct {
+	Color  bool
+	Indent string
+}
+
+// Sprint renders v without colors, see Printer.Sprint.
+func Sprint(v any) string {
+	return (&Printer{}).Sprint(v)
+}
+
+// Sprint renders v. Values that cannot be encoded to JSON are rendered with %+v.
+func (p *Printer) Sprint(v any) string {
+	var buf bytes.Buffer
+	_ = p.Fprint(&buf, v)
+
+	return buf.String()
+}
+
+// Fprint writes the rendering of v to w.
+func (p *Printer) Fprint(w io.Writer, v any) error {
+	tree, err := normalize(v)
+	if err != nil {
+		_, werr := fmt.Fprintf(w, "%+v\n", v)
+
+		return werr
+	}
+
+	var buf bytes.Buffer
+	if isContainer(tree) && !isEmpty(tree) {
+		p.render(&buf, tree, 0)
+	} else {
+		buf.WriteString(p.scalar(tree))
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func (p *Printer) render(buf *bytes.Buffer, node any, depth int) {
+	indent := strings.Repeat(p.indent(), depth)
+
+	switch n := node.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(n) {
+			child := n[key]
+			buf.WriteString(indent + p.paint(colorKey, key) + ":")
+			if isContainer(child) && !isEmpty(child) {
+				buf.WriteByte('\n')
+				p.render(buf, child, depth+1)
+
+				continue
+			}
+			buf.WriteString(" " + p.scalar(child) + "\n")
+		}
+	case []any:
+		for _, child := range n {
+			buf.WriteString(indent + "-")
+			if isContainer(child) && !isEmpty(child) {
+				buf.WriteByte('\n')
+				p.render(buf, child, depth+1)
+
+				continue
+			}
+			buf.WriteString(" " + p.scalar(child) + "\n")
+		}
+	}
+}
+
+func (p *Printer) scalar(node any) string {
+	switch n := node.(type) {
+	case nil:
+		return p.paint(colorNull, "null")
+	case string:
+		return p.paint(colorString, strconv.Quote(n))
+	case json.Number:
+		return p.paint(colorNumber, n.String())
+	case bool:
+		return p.paint(colorBool, strconv.FormatBool(n))
+	case map[string]any:
+		return "{}"
+	case []any:
+		return "[]"
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+func (p *Printer) paint(color, s string) string {
+	if !p.Color {
+		return s
+	}
+
+	return color + s + colorReset
+}
+
+func (p *Printer) indent() string {
+	if p.Indent == "" {
+		return defaultIndent
+	}
+
+	return p.Indent
+}
+
+// ChangeKind tells how a value differs between two payloads.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a difference between two payloads. Path locates the value, for example
+// "entry[0].changes[0].field". Want is nil for added values and Got for removed ones.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Want any
+	Got  any
+}
+
+// Changes lists the differences between want and got ordered by path.
+func Changes(want, got any) ([]*Change, error) {
+	wantTree, err := normalize(want)
+	if err != nil {
+		return nil, fmt.Errorf("pretty: encode want: %w", err)
+	}
+
+	gotTree, err := normalize(got)
+	if err != nil {
+		return nil, fmt.Errorf("pretty: encode got: %w", err)
+	}
+
+	var changes []*Change
+	compare("", wantTree, gotTree, true, true, &changes)
+
+	return changes, nil
+}
+
+// Diff returns a line per difference between want and got, prefixed with "-" for the
+// value in want and "+" for the value in got, and an empty string when they are equal.
+func Diff(want, got any) string {
+	return (&Printer{}).Diff(want, got)
+}
+
+// Diff is like the package level Diff, removed lines are red and added ones green when
+// Color is set.
+func (p *Printer) Diff(want, got any) string {
+	changes, err := Changes(want, got)
+	if err != nil {
+		return err.Error()
+	}
+
+	var buf strings.Builder
+	for _, change := range changes {
+		path := change.Path
+		if path == "" {
+			path = "."
+		}
+
+		if change.Kind != ChangeAdded {
+			buf.WriteString(p.paint(colorRemove, "- "+path+": "+compact(change.Want)) + "\n")
+		}
+
+		if change.Kind != ChangeRemoved {
+			buf.WriteString(p.paint(colorAdd, "+ "+path+": "+compact(change.Got)) + "\n")
+		}
+	}
+
+	return buf.String()
+}
+
+func compare(path string, want, got any, hasWant, hasGot bool, changes *[]*Change) {
+	switch {
+	case !hasWant:
+		*changes = append(*changes, &Change{Path: path, Kind: ChangeAdded, Got: got})
+
+		return
+	case !hasGot:
+		*changes = append(*changes, &Change{Path: path, Kind: ChangeRemoved, Want: want})
+
+		return
+	}
+
+	wantMap, wantIsMap := want.(map[string]any)
+	gotMap, gotIsMap := got.(map[string]any)
+	if wantIsMap && gotIsMap {
+		keys := sortedKeys(wantMap)
+		for _, key := range sortedKeys(gotMap) {
+			if _, ok := wantMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			w, okWant := wantMap[key]
+			g, okGot := gotMap[key]
+			compare(joinPath(path, key), w, g, okWant, okGot, changes)
+		}
+
+		return
+	}
+
+	wantSlice, wantIsSlice := want.([]any)
+	gotSlice, gotIsSlice := got.([]any)
+	if wantIsSlice && gotIsSlice {
+		for i := range max(len(wantSlice), len(gotSlice)) {
+			var w, g any
+			if i < len(wantSlice) {
+				w = wantSlice[i]
+			}
+			if i < len(gotSlice) {
+				g = gotSlice[i]
+			}
+			compare(path+"["+strconv.Itoa(i)+"]", w, g, i < len(wantSlice), i < len(gotSlice), changes)
+		}
+
+		return
+	}
+
+	if !equalScalars(want, got) {
+		*changes = append(*changes, &Change{Path: path, Kind: ChangeModified, Want: want, Got: got})
+	}
+}
+
+func equalScalars(want, got any) bool {
+	if isContainer(want) || isContainer(got) {
+		return false
+	}
+
+	return want == got
+}
+
+// compact encodes a node of the JSON tree on a single line.
+func compact(node any) string {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return fmt.Sprint(node)
+	}
+
+	return string(data)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// normalize converts v to the generic JSON tree of its encoding. Byte slices and raw
+// messages are taken as encoded JSON.
+func normalize(v any) (any, error) {
+	var data []byte
+	switch value := v.(type) {
+	case json.RawMessage:
+		data = value
+	case []byte:
+		data = value
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = encoded
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+func isContainer(node any) bool {
+	switch node.(type) {
+	case map[string]any, []any:
+		return true
+	default:
+		return false
+	}
+}
+
+func isEmpty(node any) bool {
+	switch n := node.(type) {
+	case map[string]any:
+		return len(n) == 0
+	case []any:
+		return len(n) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package pretty_test
+
+import (
+	"strings"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/pkg/pretty"
+)
+
+type text struct {
+	Body string `json:"body"`
+}
+
+type payload struct {
+	To     string   `json:"to"`
+	Type   string   `json:"type"`
+	Text   *text    `json:"text,omitempty"`
+	Tags   []string `json:"tags"`
+	Urgent bool     `json:"urgent"`
+	Count  int      `json:"count"`
+}
+
+func TestSprint(t *testing.T) {
+	t.Parallel()
+
+	got := pretty.Sprint(&payload{To: "2557", Type: "text", Text: &text{Body: "hi"}, Tags: []string{"a"}, Count: 2})
+	want := strings.Join([]string{
+		`count: 2`,
+		`tags:`,
+		`  - "a"`,
+		`text:`,
+		`  body: "hi"`,
+		`to: "2557"`,
+		`type: "text"`,
+		`urgent: false`,
+		``,
+	}, "\n")
+	if got != want {
+		t.Errorf("Sprint() mismatch (-want +got):\n%s", gcmp.Diff(want, got))
+	}
+
+	if got := pretty.Sprint([]byte(`{"b":null,"a":[]}`)); got != "a: []\nb: null\n" {
+		t.Errorf("Sprint(raw) = %q", got)
+	}
+
+	colored := (&pretty.Printer{Color: true}).Sprint(map[string]string{"k": "v"})
+	if !strings.Contains(colored, "\x1b[") {
+		t.Errorf("Sprint() with Color = %q, want ANSI codes", colored)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	want := &payload{To: "2557", Type: "text", Text: &text{Body: "hi"}, Tags: []string{"a", "b"}}
+	got := `{"to":"2557","type":"text","text":{"body":"hello"},"tags":["a"],"urgent":false,"count":0,"extra":{"x":1}}`
+
+	if diff := pretty.Diff(want, want); diff != "" {
+		t.Errorf("Diff() of equal payloads = %q", diff)
+	}
+
+	diff := pretty.Diff(want, []byte(got))
+	wantDiff := strings.Join([]string{
+		`+ extra: {"x":1}`,
+		`- tags[1]: "b"`,
+		`- text.body: "hi"`,
+		`+ text.body: "hello"`,
+		``,
+	}, "\n")
+	if diff != wantDiff {
+		t.Errorf("Diff() mismatch (-want +got):\n%s", gcmp.Diff(wantDiff, diff))
+	}
+
+	changes, err := pretty.Changes(want, []byte(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := make([]pretty.ChangeKind, len(changes))
+	for i, change := range changes {
+		kinds[i] = change.Kind
+	}
+	if diff := gcmp.Diff([]pretty.ChangeKind{pretty.ChangeAdded, pretty.ChangeRemoved, pretty.ChangeModified}, kinds); diff != "" {
+		t.Errorf("Changes() kinds mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,392 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package ratelimit limits outgoing messages to stay within the Cloud API messaging limits.
+//
+// Limits are enforced with token buckets. A Limiter checks three buckets before a message is
+// sent: the daily messaging tier of the business phone number, the per second throughput of
+// the phone number and the pair rate limit between the phone number and a recipient. Bucket
+// state is kept in a Store, MemoryStore works for a single process while distributed
+// deployments can implement Store on top of Redis or a similar shared database.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+)
+
+// Messaging tiers, the number of messages a business phone number can send in 24 hours.
+const (
+	Tier250  = 250
+	Tier1K   = 1_000
+	Tier10K  = 10_000
+	Tier100K = 100_000
+)
+
+// Limit is the refill rate of a bucket in tokens per second and the maximum number of tokens
+// it can hold. A zero Limit is unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerDay returns a limit of n messages per 24 hours that can all be sent at once.
+func PerDay(n int) Limit {
+	return Limit{Rate: float64(n) / (24 * time.Hour).Seconds(), Burst: n}
+}
+
+// Every returns a limit of one message per interval with the given burst.
+func Every(interval time.Duration, burst int) Limit {
+	return Limit{Rate: 1 / interval.Seconds(), Burst: burst}
+}
+
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+var (
+	// DefaultThroughput is the default Cloud API throughput of 80 messages per second.
+	DefaultThroughput = Limit{Rate: 80, Burst: 80} //nolint:mnd // documented throughput
+
+	// DefaultPairLimit allows one message every 6 seconds to the same recipient.
+	DefaultPairLimit = Every(6*time.Second, 1) //nolint:mnd // documented pair rate limit
+)
+
+type (
+	// Bucket identifies a token bucket and its limit.
+	Bucket struct {
+		Key   string
+		Limit Limit
+	}
+
+	// Reservation is the outcome of taking a token from buckets. When Allowed is false no
+	// token was taken, Bucket is the index of the empty bucket with the longest wait and
+	// RetryAfter is the time until it has a token. Remaining is the lowest number of tokens
+	// left in the buckets.
+	Reservation struct {
+		Allowed    bool
+		Bucket     int
+		Remaining  float64
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the state of token buckets. Take must take one token from every bucket
+	// atomically, creating full buckets when they do not exist, and take none when any of
+	// them is empty.
+	Store interface {
+		Take(ctx context.Context, buckets []Bucket, now time.Time) (*Reservation, error)
+	}
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps buckets in memory. Buckets that refilled completely
+// are dropped, a new full bucket is created the next time they are used.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	evicted time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// evictInterval is how often a MemoryStore looks for full buckets to drop.
+const evictInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, buckets []Bucket, now time.Time) (*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(now)
+
+	reservation := &Reservation{Allowed: true, Remaining: math.Inf(1)}
+	states := make([]*bucket, len(buckets))
+	for i, spec := range buckets {
+		b, ok := s.buckets[spec.Key]
+		if !ok {
+			b = &bucket{tokens: float64(spec.Limit.Burst), last: now}
+			s.buckets[spec.Key] = b
+		}
+		b.limit = spec.Limit
+		b.refill(now)
+		states[i] = b
+
+		if b.tokens >= 1 {
+			continue
+		}
+
+		wait := time.Duration((1 - b.tokens) / spec.Limit.Rate * float64(time.Second))
+		if reservation.Allowed || wait > reservation.RetryAfter {
+			reservation = &Reservation{Bucket: i, Remaining: b.tokens, RetryAfter: wait}
+		}
+	}
+
+	if !reservation.Allowed {
+		return reservation, nil
+	}
+
+	for _, b := range states {
+		b.tokens--
+		reservation.Remaining = math.Min(reservation.Remaining, b.tokens)
+	}
+
+	return reservation, nil
+}
+
+// Len returns the number of buckets kept in memory.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
+
+// evict drops the buckets that are full at now, at most once per evictInterval.
+func (s *MemoryStore) evict(now time.Time) {
+	if now.Sub(s.evicted) < evictInterval {
+		return
+	}
+	s.evicted = now
+
+	for key, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
+
+type (
+	// Limiter enforces the messaging tier, throughput and pair rate limits. Zero limits are
+	// not enforced.
+	Limiter struct {
+		store      Store
+		tier       Limit
+		throughput Limit
+		numbers    map[string]Limit
+		pair       Limit
+		now        func() time.Time
+	}
+
+	LimiterOption func(*Limiter)
+)
+
+// WithTier sets the daily messaging tier of the phone numbers, for example Tier1K.
+func WithTier(messagesPerDay int) LimiterOption {
+	return func(l *Limiter) {
+		l.tier = PerDay(messagesPerDay)
+	}
+}
+
+func WithThroughput(limit Limit) LimiterOption {
+	return func(l *Limiter) {
+		l.throughput = limit
+	}
+}
+
+// WithPhoneNumberThroughput sets the throughput of one phone number, overriding the
+// throughput of the other numbers.
+func WithPhoneNumberThroughput(phoneNumberID string, limit Limit) LimiterOption {
+	return func(l *Limiter) {
+		if l.numbers == nil {
+			l.numbers = make(map[string]Limit)
+		}
+		l.numbers[phoneNumberID] = limit
+	}
+}
+
+// WithProfiles sets the throughput of the phone number of every profile with a
+// MessagesPerSecond, see config.Profile.
+func WithProfiles(profiles ...*config.Profile) LimiterOption {
+	return func(l *Limiter) {
+		for _, profile := range profiles {
+			if profile == nil || profile.MessagesPerSecond <= 0 {
+				continue
+			}
+
+			rate := profile.MessagesPerSecond
+			WithPhoneNumberThroughput(profile.PhoneNumberID, Limit{Rate: float64(rate), Burst: rate})(l)
+		}
+	}
+}
+
+func WithPairLimit(limit Limit) LimiterOption {
+	return func(l *Limiter) {
+		l.pair = limit
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) LimiterOption {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// NewLimiter creates a Limiter with DefaultThroughput and DefaultPairLimit and no messaging
+// tier limit. A nil store uses a new MemoryStore.
+func NewLimiter(store Store, options ...LimiterOption) *Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	limiter := &Limiter{
+		store:      store,
+		throughput: DefaultThroughput,
+		pair:       DefaultPairLimit,
+		now:        time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(limiter)
+		}
+	}
+
+	return limiter
+}
+
+// Allow takes a token for a message from phoneNumberID to recipient from the pair,
+// throughput and tier buckets. It returns a *LimitError for the limit with the longest wait
+// when any of them is reached, and then takes no token from the others.
+func (l *Limiter) Allow(ctx context.Context, phoneNumberID, recipient string) error {
+	checks := []struct {
+		scope Scope
+		key   string
+		limit Limit
+	}{
+		{ScopePair, "pair:" + phoneNumberID + ":" + recipient, l.pair},
+		{ScopeThroughput, "throughput:" + phoneNumberID, l.throughputOf(phoneNumberID)},
+		{ScopeTier, "tier:" + phoneNumberID, l.tier},
+	}
+
+	var (
+		buckets []Bucket
+		scopes  []Scope
+	)
+	for _, check := range checks {
+		if check.limit.unlimited() || (check.scope == ScopePair && recipient == "") {
+			continue
+		}
+
+		buckets = append(buckets, Bucket{Key: check.key, Limit: check.limit})
+		scopes = append(scopes, check.scope)
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	reservation, err := l.store.Take(ctx, buckets, l.now())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStore, err)
+	}
+
+	if !reservation.Allowed {
+		rejected := reservation.Bucket
+
+		return &LimitError{Scope: scopes[rejected], Key: buckets[rejected].Key, RetryAfter: reservation.RetryAfter}
+	}
+
+	return nil
+}
+
+func (l *Limiter) throughputOf(phoneNumberID string) Limit {
+	if limit, ok := l.numbers[phoneNumberID]; ok {
+		return limit
+	}
+
+	return l.throughput
+}
+
+// Wait is like Allow but blocks until the message is allowed or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, phoneNumberID, recipient string) error {
+	for {
+		err := l.Allow(ctx, phoneNumberID, recipient)
+
+		limitErr, ok := err.(*LimitError) //nolint:errorlint // Allow returns it unwrapped
+		if !ok {
+			return err
+		}
+
+		timer := time.NewTimer(limitErr.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("%w: %w", limitErr, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Scope is the limit a message was rejected by.
+type Scope string
+
+const (
+	ScopeTier       Scope = "tier"
+	ScopeThroughput Scope = "throughput"
+	ScopePair       Scope = "pair"
+)
+
+// LimitError is returned when a message would exceed a limit. It unwraps to ErrRateLimited.
+type LimitError struct {
+	Scope      Scope
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit reached for %s, retry after %s", ErrRateLimited, e.Scope, e.Key, e.RetryAfter)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// rateLimitError is a custom error type for rate limit errors.
+type rateLimitError string
+
+func (e rateLimitError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrRateLimited = rateLimitError("rate limited")
+	ErrStore       = rateLimitError("rate limit store failed")
+)
@@ -0,0 +1,199 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/pkg/ratelimit"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter(nil,
+		ratelimit.WithTier(3),
+		ratelimit.WithClock(func() time.Time { return now }),
+	)
+
+	ctx := context.Background()
+	if err := limiter.Allow(ctx, "phone", "alice"); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+
+	var limitErr *ratelimit.LimitError
+	err := limiter.Allow(ctx, "phone", "alice")
+	if !errors.As(err, &limitErr) || limitErr.Scope != ratelimit.ScopePair || !errors.Is(err, ratelimit.ErrRateLimited) {
+		t.Fatalf("expected pair limit error, got %v", err)
+	}
+
+	if limitErr.RetryAfter != 6*time.Second {
+		t.Errorf("retry after = %s, want 6s", limitErr.RetryAfter)
+	}
+
+	for _, recipient := range []string{"bob", "carol"} {
+		if err := limiter.Allow(ctx, "phone", recipient); err != nil {
+			t.Fatalf("message to %s: %v", recipient, err)
+		}
+	}
+
+	err = limiter.Allow(ctx, "phone", "dave")
+	if !errors.As(err, &limitErr) || limitErr.Scope != ratelimit.ScopeTier {
+		t.Fatalf("expected tier limit error, got %v", err)
+	}
+
+	if err := limiter.Allow(ctx, "other-phone", "dave"); err != nil {
+		t.Errorf("limits must be per phone number: %v", err)
+	}
+
+	now = now.Add(6 * time.Second)
+	err = limiter.Allow(ctx, "phone", "alice")
+	if !errors.As(err, &limitErr) || limitErr.Scope != ratelimit.ScopeTier {
+		t.Errorf("expected tier limit after pair refill, got %v", err)
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	limiter := ratelimit.NewLimiter(nil, ratelimit.WithPairLimit(ratelimit.Every(20*time.Millisecond, 1)))
+
+	ctx := context.Background()
+	start := time.Now()
+	for range 3 {
+		if err := limiter.Wait(ctx, "phone", "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("elapsed = %s, expected the limiter to wait", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(canceled, "phone", "alice"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+}
+
+func TestLimiter_WithProfiles(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter(nil,
+		ratelimit.WithPairLimit(ratelimit.Limit{}),
+		ratelimit.WithClock(func() time.Time { return now }),
+		ratelimit.WithProfiles(
+			&config.Profile{Name: "alerts", PhoneNumberID: "alerts-phone", MessagesPerSecond: 2},
+			&config.Profile{Name: "sales", PhoneNumberID: "sales-phone"},
+		),
+	)
+
+	ctx := context.Background()
+	for i := range 2 {
+		if err := limiter.Allow(ctx, "alerts-phone", "alice"); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+
+	var limitErr *ratelimit.LimitError
+	if err := limiter.Allow(ctx, "alerts-phone", "alice"); !errors.As(err, &limitErr) ||
+		limitErr.Scope != ratelimit.ScopeThroughput {
+		t.Fatalf("expected throughput limit error, got %v", err)
+	}
+
+	for i := range 3 {
+		if err := limiter.Allow(ctx, "sales-phone", "alice"); err != nil {
+			t.Fatalf("profile without throughput, message %d: %v", i, err)
+		}
+	}
+}
+
+func TestLimiter_AllowTakesNoTokenOnRejection(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter(nil,
+		ratelimit.WithTier(2),
+		ratelimit.WithThroughput(ratelimit.Every(time.Second, 1)),
+		ratelimit.WithClock(func() time.Time { return now }),
+	)
+
+	ctx := context.Background()
+	if err := limiter.Allow(ctx, "phone", "alice"); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+
+	var limitErr *ratelimit.LimitError
+	if err := limiter.Allow(ctx, "phone", "bob"); !errors.As(err, &limitErr) ||
+		limitErr.Scope != ratelimit.ScopeThroughput {
+		t.Fatalf("expected throughput limit error, got %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if err := limiter.Allow(ctx, "phone", "bob"); err != nil {
+		t.Fatalf("the pair bucket of bob must not be drained by the throughput rejection: %v", err)
+	}
+
+	now = now.Add(time.Second)
+	for range 3 {
+		if err := limiter.Allow(ctx, "phone", "carol"); !errors.As(err, &limitErr) ||
+			limitErr.Scope != ratelimit.ScopeTier {
+			t.Fatalf("expected tier limit error, got %v", err)
+		}
+	}
+
+	if err := limiter.Allow(ctx, "other-phone", "carol"); err != nil {
+		t.Errorf("limits must be per phone number: %v", err)
+	}
+}
+
+func TestLimiter_WaitAfterThroughput(t *testing.T) {
+	t.Parallel()
+
+	limiter := ratelimit.NewLimiter(nil, ratelimit.WithThroughput(ratelimit.Every(20*time.Millisecond, 1)))
+
+	ctx := context.Background()
+	start := time.Now()
+	for _, recipient := range []string{"alice", "bob"} {
+		if err := limiter.Wait(ctx, "phone", recipient); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed = %s, expected to wait for the throughput only", elapsed)
+	}
+}
+
+func TestMemoryStore_Evict(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore()
+	limiter := ratelimit.NewLimiter(store, ratelimit.WithClock(func() time.Time { return now }))
+
+	ctx := context.Background()
+	for _, recipient := range []string{"alice", "bob", "carol"} {
+		if err := limiter.Allow(ctx, "phone", recipient); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := store.Len(); got != 4 {
+		t.Fatalf("Len() = %d, want 3 pair buckets and the throughput bucket", got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := limiter.Allow(ctx, "phone", "dave"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.Len(); got != 2 {
+		t.Errorf("Len() = %d, want the full buckets evicted", got)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package poller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/piusalfred/whatsapp/phonenumber"
+	"github.com/piusalfred/whatsapp/template"
+)
+
+var _ Cache = (*MemoryCache)(nil)
+
+// MemoryCache is a Cache kept in memory, suitable for a single process.
+type MemoryCache struct {
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+	number    *phonenumber.PhoneNumber
+	blocked   map[string]struct{}
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		templates: make(map[string]*template.Template),
+		blocked:   make(map[string]struct{}),
+	}
+}
+
+func (c *MemoryCache) StoreTemplates(_ context.Context, templates []*template.Template) error {
+	index := make(map[string]*template.Template, len(templates))
+	for _, tpl := range templates {
+		index[templateKey(tpl.Name, tpl.Language)] = tpl
+	}
+
+	c.mu.Lock()
+	c.templates = index
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *MemoryCache) StorePhoneNumber(_ context.Context, number *phonenumber.PhoneNumber) error {
+	c.mu.Lock()
+	c.number = number
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *MemoryCache) StoreBlockedUsers(_ context.Context, waIDs []string) error {
+	blocked := make(map[string]struct{}, len(waIDs))
+	for _, id := range waIDs {
+		blocked[id] = struct{}{}
+	}
+
+	c.mu.Lock()
+	c.blocked = blocked
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Template returns the template with the given name and language.
+func (c *MemoryCache) Template(name, language string) (*template.Template, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tpl, ok := c.templates[templateKey(name, language)]
+
+	return tpl, ok
+}
+
+// PhoneNumber returns the last reconciled phone number, nil before the first poll.
+func (c *MemoryCache) PhoneNumber() *phonenumber.PhoneNumber {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.number
+}
+
+func (c *MemoryCache) IsBlocked(waID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.blocked[waID]
+
+	return ok
+}
+
+func templateKey(name, language string) string {
+	return name + "/" + language
+}
@@ -0,0 +1,451 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package poller keeps local copies of management data converged when webhook delivery is
+// degraded. A Poller watches a Heartbeat fed by the webhook listener and, once no valid
+// notification has arrived for a while, periodically lists message templates, reads the
+// phone number quality rating and fetches the blocked users, storing them in a Cache so
+// downstream logic that normally relies on webhooks keeps working during the outage.
+//
+// Polling backs off exponentially while the Graph API reports throttling.
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piusalfred/whatsapp/phonenumber"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/template"
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+const (
+	DefaultInterval   = 5 * time.Minute
+	DefaultStaleAfter = 15 * time.Minute
+	DefaultMaxBackoff = time.Hour
+)
+
+type (
+	// TemplateLister is implemented by template.BaseClient and template.Client.
+	TemplateLister interface {
+		ListAll(ctx context.Context, req *template.ListRequest) ([]*template.Template, error)
+	}
+
+	// PhoneNumberGetter is implemented by phonenumber.BaseClient and phonenumber.Client.
+	PhoneNumberGetter interface {
+		Get(ctx context.Context, req *phonenumber.GetRequest) (*phonenumber.PhoneNumber, error)
+	}
+
+	// BlockedUsersLister returns the WhatsApp ids blocked by the business phone number.
+	BlockedUsersLister interface {
+		ListBlockedUsers(ctx context.Context) ([]string, error)
+	}
+
+	BlockedUsersListerFunc func(ctx context.Context) ([]string, error)
+
+	// Heartbeat reports when the last valid webhook notification was received. Beat and
+	// webhooks.Watchdog implement it.
+	Heartbeat interface {
+		LastSeen() time.Time
+	}
+
+	// Cache stores the reconciled state. Every call replaces what was stored before.
+	Cache interface {
+		StoreTemplates(ctx context.Context, templates []*template.Template) error
+		StorePhoneNumber(ctx context.Context, number *phonenumber.PhoneNumber) error
+		StoreBlockedUsers(ctx context.Context, waIDs []string) error
+	}
+
+	// TemplateStatusHook is called for every template whose status or quality score changed
+	// since the previous poll, previous is nil for templates seen for the first time after
+	// the first poll.
+	TemplateStatusHook func(ctx context.Context, previous, current *template.Template)
+
+	// ErrorHook is called with the error of every failed reconciliation in Run.
+	ErrorHook func(ctx context.Context, err error)
+)
+
+func (fn BlockedUsersListerFunc) ListBlockedUsers(ctx context.Context) ([]string, error) {
+	return fn(ctx)
+}
+
+type Poller struct {
+	cache       Cache
+	heartbeat   Heartbeat
+	templates   TemplateLister
+	templateReq *template.ListRequest
+	phone       PhoneNumberGetter
+	blocked     BlockedUsersLister
+	interval    time.Duration
+	staleAfter  time.Duration
+	spacing     time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+	onStatus    TemplateStatusHook
+	onError     ErrorHook
+
+	mu       sync.Mutex
+	seen     map[string]*template.Template
+	polled   bool
+	backoff  time.Duration
+	lastPoll time.Time
+}
+
+type Option func(*Poller)
+
+// WithTemplates reconciles the templates matching req, all templates when req is nil.
+func WithTemplates(lister TemplateLister, req *template.ListRequest) Option {
+	return func(p *Poller) {
+		p.templates = lister
+		p.templateReq = req
+	}
+}
+
+// WithPhoneNumber reconciles the quality rating and status of the configured phone number.
+func WithPhoneNumber(getter PhoneNumberGetter) Option {
+	return func(p *Poller) {
+		p.phone = getter
+	}
+}
+
+func WithBlockedUsers(lister BlockedUsersLister) Option {
+	return func(p *Poller) {
+		p.blocked = lister
+	}
+}
+
+// WithInterval sets the time between polls while webhooks are degraded.
+func WithInterval(interval time.Duration) Option {
+	return func(p *Poller) {
+		p.interval = interval
+	}
+}
+
+// WithStaleAfter sets how long after the last notification webhooks are considered down.
+func WithStaleAfter(d time.Duration) Option {
+	return func(p *Poller) {
+		p.staleAfter = d
+	}
+}
+
+// WithSpacing sets a pause between the API calls of a single reconciliation, to stay well
+// under the business use case rate limits.
+func WithSpacing(d time.Duration) Option {
+	return func(p *Poller) {
+		p.spacing = d
+	}
+}
+
+// WithMaxBackoff caps the wait between polls while the API is throttling.
+func WithMaxBackoff(d time.Duration) Option {
+	return func(p *Poller) {
+		p.maxBackoff = d
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(p *Poller) {
+		p.now = now
+	}
+}
+
+func WithTemplateStatusHook(hook TemplateStatusHook) Option {
+	return func(p *Poller) {
+		p.onStatus = hook
+	}
+}
+
+func WithErrorHook(hook ErrorHook) Option {
+	return func(p *Poller) {
+		p.onError = hook
+	}
+}
+
+// NewPoller creates a Poller storing reconciled state in cache. A nil heartbeat makes the
+// Poller consider webhooks always degraded, so it polls on every interval.
+func NewPoller(cache Cache, heartbeat Heartbeat, options ...Option) *Poller {
+	poller := &Poller{
+		cache:      cache,
+		heartbeat:  heartbeat,
+		interval:   DefaultInterval,
+		staleAfter: DefaultStaleAfter,
+		maxBackoff: DefaultMaxBackoff,
+		now:        time.Now,
+		seen:       make(map[string]*template.Template),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(poller)
+		}
+	}
+
+	return poller
+}
+
+// Degraded reports whether no notification was received within the stale threshold.
+func (p *Poller) Degraded() bool {
+	if p.heartbeat == nil {
+		return true
+	}
+
+	return p.now().Sub(p.heartbeat.LastSeen()) > p.staleAfter
+}
+
+// LastPoll returns the time the last reconciliation finished, successful or not.
+func (p *Poller) LastPoll() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastPoll
+}
+
+// Run polls every interval while webhooks are degraded until ctx is done. Failed
+// reconciliations are reported to the error hook, throttling errors double the wait up to
+// the maximum backoff.
+func (p *Poller) Run(ctx context.Context) error {
+	for {
+		wait := p.interval
+
+		if p.Degraded() {
+			err := p.Reconcile(ctx)
+			if err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err != nil && p.onError != nil {
+				p.onError(ctx, err)
+			}
+
+			wait = p.nextWait(err)
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Poller) nextWait(err error) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !errors.Is(err, ErrThrottled) {
+		p.backoff = 0
+
+		return p.interval
+	}
+
+	p.backoff = min(max(2*p.backoff, 2*p.interval), p.maxBackoff) //nolint:mnd // doubles the wait
+
+	return p.backoff
+}
+
+// Reconcile fetches every configured source once and stores the results, regardless of the
+// heartbeat. A failing source does not prevent the others from being reconciled, the
+// errors are joined and wrap ErrThrottled when the API reported throttling.
+func (p *Poller) Reconcile(ctx context.Context) error {
+	steps := make([]func(context.Context) error, 0, 3) //nolint:mnd // number of sources
+	if p.templates != nil {
+		steps = append(steps, p.reconcileTemplates)
+	}
+
+	if p.phone != nil {
+		steps = append(steps, p.reconcilePhoneNumber)
+	}
+
+	if p.blocked != nil {
+		steps = append(steps, p.reconcileBlockedUsers)
+	}
+
+	var errs []error
+	for i, step := range steps {
+		if i > 0 && p.spacing > 0 {
+			if err := sleepContext(ctx, p.spacing); err != nil {
+				return err
+			}
+		}
+
+		if err := step(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	p.mu.Lock()
+	p.lastPoll = p.now()
+	p.mu.Unlock()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	if slices.ContainsFunc(errs, isThrottled) {
+		return fmt.Errorf("%w: %w", ErrThrottled, err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrReconcile, err)
+}
+
+func (p *Poller) reconcileTemplates(ctx context.Context) error {
+	templates, err := p.templates.ListAll(ctx, p.templateReq)
+	if err != nil {
+		return fmt.Errorf("list templates: %w", err)
+	}
+
+	if err := p.cache.StoreTemplates(ctx, templates); err != nil {
+		return fmt.Errorf("store templates: %w", err)
+	}
+
+	p.mu.Lock()
+	var changed [][2]*template.Template
+	for _, current := range templates {
+		previous, ok := p.seen[current.ID]
+		if (ok && templateChanged(previous, current)) || (!ok && p.polled) {
+			changed = append(changed, [2]*template.Template{previous, current})
+		}
+		p.seen[current.ID] = current
+	}
+	p.polled = true
+	p.mu.Unlock()
+
+	if p.onStatus != nil {
+		for _, pair := range changed {
+			p.onStatus(ctx, pair[0], pair[1])
+		}
+	}
+
+	return nil
+}
+
+func templateChanged(previous, current *template.Template) bool {
+	if previous.Status != current.Status {
+		return true
+	}
+
+	score := func(t *template.Template) string {
+		if t.QualityScore == nil {
+			return ""
+		}
+
+		return t.QualityScore.Score
+	}
+
+	return score(previous) != score(current)
+}
+
+func (p *Poller) reconcilePhoneNumber(ctx context.Context) error {
+	number, err := p.phone.Get(ctx, &phonenumber.GetRequest{
+		Fields: []string{"id", "display_phone_number", "verified_name", "quality_rating", "name_status"},
+	})
+	if err != nil {
+		return fmt.Errorf("get phone number: %w", err)
+	}
+
+	if err := p.cache.StorePhoneNumber(ctx, number); err != nil {
+		return fmt.Errorf("store phone number: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Poller) reconcileBlockedUsers(ctx context.Context) error {
+	users, err := p.blocked.ListBlockedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("list blocked users: %w", err)
+	}
+
+	if err := p.cache.StoreBlockedUsers(ctx, users); err != nil {
+		return fmt.Errorf("store blocked users: %w", err)
+	}
+
+	return nil
+}
+
+// throttlingCodes are the Graph API error codes reporting that a rate limit was hit.
+var throttlingCodes = []int{4, 17, 32, 613, 80007, 130429} //nolint:gochecknoglobals,mnd // API error codes
+
+func isThrottled(err error) bool {
+	var apiErr *werrors.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return slices.Contains(throttlingCodes, apiErr.Code)
+}
+
+// Beat is a Heartbeat updated by the webhook handlers.
+type Beat struct {
+	last atomic.Int64
+}
+
+func (b *Beat) Touch(t time.Time) {
+	b.last.Store(t.UnixNano())
+}
+
+func (b *Beat) LastSeen() time.Time {
+	nanos := b.last.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
+
+// HeartbeatMiddleware touches beat for every notification that reaches the handler, that
+// is every notification that passed signature validation.
+func HeartbeatMiddleware[T any](beat *Beat) webhooks.HandleMiddleware[T] {
+	return func(next webhooks.NotificationHandlerFunc[T]) webhooks.NotificationHandlerFunc[T] {
+		return func(ctx context.Context, notification *T) *webhooks.Response {
+			beat.Touch(time.Now())
+
+			return next(ctx, notification)
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pollerError is a custom error type for poller errors.
+type pollerError string
+
+func (e pollerError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrReconcile = pollerError("reconciliation failed")
+	ErrThrottled = pollerError("reconciliation throttled by the api")
+)
@@ -0,0 +1,120 @@
+package poller_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/phonenumber"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/poller"
+	"github.com/piusalfred/whatsapp/template"
+)
+
+type templateLister struct {
+	templates []*template.Template
+	err       error
+}
+
+func (l *templateLister) ListAll(context.Context, *template.ListRequest) ([]*template.Template, error) {
+	return l.templates, l.err
+}
+
+type phoneNumberGetter struct{}
+
+func (phoneNumberGetter) Get(context.Context, *phonenumber.GetRequest) (*phonenumber.PhoneNumber, error) {
+	return &phonenumber.PhoneNumber{ID: "phone", QualityRating: "YELLOW"}, nil
+}
+
+func TestPoller_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	lister := &templateLister{templates: []*template.Template{
+		{ID: "1", Name: "welcome", Language: "en", Status: template.StatusApproved},
+		{ID: "2", Name: "offer", Language: "en", Status: template.StatusPending},
+	}}
+
+	var changes []string
+	cache := poller.NewMemoryCache()
+	p := poller.NewPoller(cache, nil,
+		poller.WithTemplates(lister, nil),
+		poller.WithPhoneNumber(phoneNumberGetter{}),
+		poller.WithBlockedUsers(poller.BlockedUsersListerFunc(func(context.Context) ([]string, error) {
+			return []string{"255700"}, nil
+		})),
+		poller.WithTemplateStatusHook(func(_ context.Context, previous, current *template.Template) {
+			changes = append(changes, previous.Status+">"+current.Status)
+		}),
+	)
+
+	if err := p.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if tpl, ok := cache.Template("offer", "en"); !ok || tpl.Status != template.StatusPending {
+		t.Errorf("cached template = %+v, %v", tpl, ok)
+	}
+
+	if cache.PhoneNumber().QualityRating != "YELLOW" || !cache.IsBlocked("255700") {
+		t.Errorf("phone number or blocked users not reconciled")
+	}
+
+	lister.templates = []*template.Template{
+		{ID: "1", Name: "welcome", Language: "en", Status: template.StatusApproved},
+		{ID: "2", Name: "offer", Language: "en", Status: template.StatusRejected},
+	}
+
+	if err := p.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := gcmp.Diff([]string{"PENDING>REJECTED"}, changes); diff != "" {
+		t.Errorf("status changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPoller_ReconcileThrottled(t *testing.T) {
+	t.Parallel()
+
+	lister := &templateLister{err: &werrors.Error{Code: 80007, Message: "rate limit"}}
+	p := poller.NewPoller(poller.NewMemoryCache(), nil,
+		poller.WithTemplates(lister, nil),
+		poller.WithPhoneNumber(phoneNumberGetter{}))
+
+	err := p.Reconcile(context.Background())
+	if !errors.Is(err, poller.ErrThrottled) {
+		t.Errorf("Reconcile() error = %v, want %v", err, poller.ErrThrottled)
+	}
+
+	lister.err = errors.New("boom")
+	if err := p.Reconcile(context.Background()); !errors.Is(err, poller.ErrReconcile) {
+		t.Errorf("Reconcile() error = %v, want %v", err, poller.ErrReconcile)
+	}
+}
+
+func TestPoller_Degraded(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	beat := &poller.Beat{}
+	p := poller.NewPoller(poller.NewMemoryCache(), beat,
+		poller.WithStaleAfter(time.Minute),
+		poller.WithClock(func() time.Time { return now }))
+
+	if !p.Degraded() {
+		t.Error("expected degraded before any notification")
+	}
+
+	beat.Touch(now.Add(-30 * time.Second))
+	if p.Degraded() {
+		t.Error("expected healthy after a recent notification")
+	}
+
+	beat.Touch(now.Add(-2 * time.Minute))
+	if !p.Degraded() {
+		t.Error("expected degraded after the stale threshold")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package qrcode
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// MaxImageSize limits the size of downloaded QR code images, they are a few KB.
+const MaxImageSize = 1 << 20
+
+// DownloadImage fetches the image of the QR code in format, PNG when empty. The image is
+// generated by Meta and served from the qr_image_url of the QR code.
+func (c *BaseClient) DownloadImage(ctx context.Context, qrCodeID string, format ImageFormat) ([]byte, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return DownloadImage(ctx, c.Sender, c.Downloader, conf, qrCodeID, format)
+}
+
+// DownloadImage fetches the image of the QR code in format, PNG when empty.
+func (c *Client) DownloadImage(ctx context.Context, qrCodeID string, format ImageFormat) ([]byte, error) {
+	return DownloadImage(ctx, c.Sender, c.Downloader, c.Config, qrCodeID, format)
+}
+
+// DownloadImage looks up the qr_image_url of the QR code in format with sender and fetches
+// the image from it with downloader. A sender without middlewares is used when downloader
+// is nil.
+func DownloadImage(ctx context.Context, sender Sender, downloader whttp.AnySender, conf *config.Config,
+	qrCodeID string, format ImageFormat,
+) ([]byte, error) {
+	if format == "" {
+		format = ImageFormatPNG
+	}
+
+	if format != ImageFormatPNG && format != ImageFormatSVG {
+		return nil, fmt.Errorf("%w: %w: %q", ErrDownloadQRImage, ErrInvalidImageFormat, format)
+	}
+
+	request := &BaseRequest{
+		Method:   http.MethodGet,
+		Type:     whttp.RequestTypeGetQR,
+		QRCodeID: qrCodeID,
+		QueryParams: map[string]string{
+			"fields": fmt.Sprintf("code,prefilled_message,deep_link_url,qr_image_url.format(%s)", format),
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDownloadQRImage, err)
+	}
+
+	if len(response.Data) == 0 || response.Data[0].QRImageURL == "" {
+		return nil, fmt.Errorf("%w: qr code %s has no image url", ErrDownloadQRImage, qrCodeID)
+	}
+
+	if downloader == nil {
+		downloader = whttp.NewAnySender()
+	}
+
+	var image []byte
+	decoder := whttp.ResponseDecoderFunc(func(_ context.Context, response *http.Response) error {
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%w: status code: %d", whttp.ErrRequestFailure, response.StatusCode)
+		}
+
+		image, err = io.ReadAll(io.LimitReader(response.Body, MaxImageSize+1))
+		if err != nil {
+			return fmt.Errorf("read image: %w", err)
+		}
+
+		if len(image) > MaxImageSize {
+			return fmt.Errorf("image exceeds %d bytes", MaxImageSize)
+		}
+
+		return nil
+	})
+
+	req := whttp.MakeRequest[any](http.MethodGet, response.Data[0].QRImageURL,
+		whttp.WithRequestType[any](whttp.RequestTypeDownloadQRImage))
+
+	if err := downloader.Send(ctx, req, decoder); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDownloadQRImage, err)
+	}
+
+	return image, nil
+}
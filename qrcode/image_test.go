@@ -0,0 +1,49 @@
+package qrcode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/qrcode"
+)
+
+func TestBaseClient_DownloadImage(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v20.0/phone/message_qrdls/CODE":
+			want := "code,prefilled_message,deep_link_url,qr_image_url.format(SVG)"
+			if got := r.URL.Query().Get("fields"); got != want {
+				t.Errorf("fields = %q, want %q", got, want)
+			}
+
+			_, _ = fmt.Fprintf(w, `{"data":[{"code":"CODE","prefilled_message":"hi",`+
+				`"deep_link_url":"https://wa.me/message/CODE","qr_image_url":"http://%s/images/CODE.svg"}]}`, r.Host)
+		case "/images/CODE.svg":
+			_, _ = w.Write([]byte("<svg></svg>"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	client := qrcode.NewBaseClient(whttp.NewAnySender(), reader)
+
+	image, err := client.DownloadImage(context.Background(), "CODE", qrcode.ImageFormatSVG)
+	if err != nil {
+		t.Fatalf("DownloadImage() error = %v", err)
+	}
+
+	if string(image) != "<svg></svg>" {
+		t.Errorf("DownloadImage() = %q", image)
+	}
+
+	_, err = client.DownloadImage(context.Background(), "CODE", "JPEG")
+	if !errors.Is(err, qrcode.ErrInvalidImageFormat) {
+		t.Errorf("DownloadImage() error = %v, want ErrInvalidImageFormat", err)
+	}
+}
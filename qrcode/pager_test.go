@@ -0,0 +1,43 @@
+package qrcode_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/qrcode"
+)
+
+func TestNewPager(t *testing.T) {
+	t.Parallel()
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "1" {
+			t.Errorf("limit = %q, want 1", r.URL.Query().Get("limit"))
+		}
+
+		switch after := r.URL.Query().Get("after"); after {
+		case "":
+			_, _ = fmt.Fprint(w, `{"data":[{"code":"A","prefilled_message":"a","deep_link_url":"https://wa.me/message/A"}],`+
+				`"paging":{"cursors":{"before":"","after":"cA"},"next":"https://graph.facebook.com/next"}}`)
+		case "cA":
+			_, _ = fmt.Fprint(w, `{"data":[{"code":"B","prefilled_message":"b","deep_link_url":"https://wa.me/message/B"}],`+
+				`"paging":{"cursors":{"before":"cA","after":"cB"}}}`)
+		default:
+			t.Errorf("unexpected cursor %q", after)
+		}
+	})
+	client := qrcode.NewBaseClient(whttp.NewAnySender(), reader)
+
+	codes, err := qrcode.NewPager(client, &qrcode.ListRequest{Limit: 1}).All(context.Background())
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	if len(codes) != 2 || codes[0].Code != "A" || codes[1].Code != "B" {
+		t.Errorf("All() = %+v", codes)
+	}
+}
@@ -63,19 +63,34 @@ type (
 		Code             string `json:"code"`
 		PrefilledMessage string `json:"prefilled_message"`
 		DeepLinkURL      string `json:"deep_link_url"`
+		QRImageURL       string `json:"qr_image_url,omitempty"`
 	}
 
 	ListResponse struct {
-		Data []*Information `json:"data,omitempty"`
+		Data   []*Information `json:"data,omitempty"`
+		Paging *Paging        `json:"paging,omitempty"`
 	}
 
+	// ListRequest pages the QR codes of the phone number. Limit is the page size.
+	ListRequest struct {
+		Limit  int
+		After  string
+		Before string
+	}
+
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
+
 	SuccessResponse struct {
 		Success bool `json:"success"`
 	}
 
+	// BaseClient manages QR codes. Downloader fetches the QR code images, a sender
+	// without middlewares is used when it is nil.
 	BaseClient struct {
-		Sender Sender
-		Config config.Reader
+		Sender     Sender
+		Config     config.Reader
+		Downloader whttp.AnySender
 	}
 )
 
@@ -83,8 +98,9 @@ func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...Sende
 	sender := &BaseSender{Sender: s}
 
 	return &BaseClient{
-		Sender: wrapMiddlewares(sender.Send, middlewares),
-		Config: reader,
+		Sender:     wrapMiddlewares(sender.Send, middlewares),
+		Config:     reader,
+		Downloader: s,
 	}
 }
 
@@ -106,6 +122,16 @@ func (c *BaseClient) List(ctx context.Context) (*ListResponse, error) {
 	return List(ctx, c.Sender, conf)
 }
 
+// ListPage returns the page of QR codes selected by req.
+func (c *BaseClient) ListPage(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListPage(ctx, c.Sender, conf, req)
+}
+
 func (c *BaseClient) Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error) {
 	conf, err := c.Config.Read(ctx)
 	if err != nil {
@@ -134,8 +160,9 @@ func (c *BaseClient) Get(ctx context.Context, qrCodeID string) (*Information, er
 }
 
 type Client struct {
-	Config *config.Config
-	Sender Sender
+	Config     *config.Config
+	Sender     Sender
+	Downloader whttp.AnySender
 }
 
 // NewClient ...
@@ -163,6 +190,11 @@ func (c *Client) List(ctx context.Context) (*ListResponse, error) {
 	return List(ctx, c.Sender, c.Config)
 }
 
+// ListPage returns the page of QR codes selected by req.
+func (c *Client) ListPage(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return ListPage(ctx, c.Sender, c.Config, req)
+}
+
 func (c *Client) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
 	return Create(ctx, c.Sender, c.Config, req)
 }
@@ -181,6 +213,9 @@ var (
 	ErrListQRCode   = errors.New("failed to list qr codes")
 	ErrDeleteQRCode = errors.New("failed to delete qr code")
 	ErrUpdateQRCode = errors.New("failed to update qr code")
+
+	ErrDownloadQRImage    = errors.New("failed to download qr code image")
+	ErrInvalidImageFormat = errors.New("qr code image format must be PNG or SVG")
 )
 
 func Create(ctx context.Context, sender Sender, conf *config.Config, req *CreateRequest) (*CreateResponse, error) {
@@ -224,10 +259,15 @@ func Get(ctx context.Context, sender Sender, conf *config.Config, qrCodeID strin
 }
 
 func List(ctx context.Context, sender Sender, conf *config.Config) (*ListResponse, error) {
+	return ListPage(ctx, sender, conf, nil)
+}
+
+// ListPage returns the page of QR codes selected by req, the first page when req is nil.
+func ListPage(ctx context.Context, sender Sender, conf *config.Config, req *ListRequest) (*ListResponse, error) {
 	request := &BaseRequest{
 		Method:      http.MethodGet,
 		Type:        whttp.RequestTypeListQR,
-		QueryParams: map[string]string{},
+		QueryParams: req.queryParams(),
 	}
 
 	response, err := sender.Send(ctx, conf, request)
@@ -285,6 +325,7 @@ type (
 
 	Response struct {
 		Data             []*Information `json:"data,omitempty"`
+		Paging           *Paging        `json:"paging,omitempty"`
 		Success          bool           `json:"success"`
 		Code             string         `json:"code"`
 		PrefilledMessage string         `json:"prefilled_message"`
@@ -323,7 +364,7 @@ func (response *Response) CreateResponse() *CreateResponse {
 }
 
 func (response *Response) ListResponse() *ListResponse {
-	return &ListResponse{Data: response.Data}
+	return &ListResponse{Data: response.Data, Paging: response.Paging}
 }
 
 type BaseSender struct {
@@ -0,0 +1,478 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package referral turns the referral messages sent by customers who clicked a
+// Click to WhatsApp ad into daily conversion reports.
+//
+// An Exporter records an Event for every referral message and the time the business first
+// responded to the customer. Report aggregates the events per day and ad, and WriteCSV
+// exports the reports for analysts:
+//
+//	exporter := referral.NewExporter(referral.NewMemoryStore())
+//	handlers.ReferralMessage = exporter.Handler()
+//	handlers.MessageEcho = exporter.EchoHandler()
+//	client, _ := wmessage.NewBaseClient(sender, reader, exporter.Middleware())
+//
+// Responses are the messages sent to the customer through the client, and the echoes of
+// the messages sent from the WhatsApp Business app on coexistence numbers.
+package referral
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// DateLayout is the layout of the dates of reports.
+const DateLayout = "2006-01-02"
+
+type (
+	// Event is a referral message. RespondedAt is zero until the business responds to the
+	// customer.
+	Event struct {
+		MessageID     string    `json:"message_id"`
+		PhoneNumberID string    `json:"phone_number_id,omitempty"`
+		Contact       string    `json:"contact"`
+		SourceID      string    `json:"source_id"`
+		SourceType    string    `json:"source_type,omitempty"`
+		SourceURL     string    `json:"source_url,omitempty"`
+		Headline      string    `json:"headline,omitempty"`
+		CtwaClid      string    `json:"ctwa_clid,omitempty"`
+		ReceivedAt    time.Time `json:"received_at"`
+		RespondedAt   time.Time `json:"responded_at"`
+	}
+
+	// Query filters events. Zero values are ignored, Until is exclusive.
+	Query struct {
+		PhoneNumberID string
+		SourceID      string
+		Since         time.Time
+		Until         time.Time
+	}
+
+	Store interface {
+		// Save inserts the event or replaces the one with the same MessageID.
+		Save(ctx context.Context, event *Event) error
+
+		// Respond sets RespondedAt of the events of contact received before at that were
+		// not responded to yet.
+		Respond(ctx context.Context, phoneNumberID, contact string, at time.Time) error
+
+		List(ctx context.Context, query *Query) ([]*Event, error)
+	}
+
+	// DailyReport aggregates the referrals from one ad on one day. Referrals counts the
+	// referral messages, Contacts the distinct customers who sent them and Responded the
+	// referrals the business responded to. The first response latencies are computed over
+	// the responded referrals.
+	DailyReport struct {
+		Date                 string        `json:"date"`
+		SourceID             string        `json:"source_id"`
+		SourceType           string        `json:"source_type,omitempty"`
+		Headline             string        `json:"headline,omitempty"`
+		Referrals            int           `json:"referrals"`
+		Contacts             int           `json:"contacts"`
+		Responded            int           `json:"responded"`
+		AverageFirstResponse time.Duration `json:"average_first_response"`
+		MedianFirstResponse  time.Duration `json:"median_first_response"`
+		MaxFirstResponse     time.Duration `json:"max_first_response"`
+	}
+
+	ExporterOption func(*Exporter)
+
+	// Exporter records referral events and the responses to them, and reports on them.
+	Exporter struct {
+		store    Store
+		now      func() time.Time
+		location *time.Location
+	}
+)
+
+func WithExporterClock(now func() time.Time) ExporterOption {
+	return func(e *Exporter) {
+		e.now = now
+	}
+}
+
+// WithExporterLocation sets the time zone the days of the reports are in, UTC by default.
+func WithExporterLocation(location *time.Location) ExporterOption {
+	return func(e *Exporter) {
+		e.location = location
+	}
+}
+
+func NewExporter(store Store, options ...ExporterOption) *Exporter {
+	exporter := &Exporter{
+		store:    store,
+		now:      time.Now,
+		location: time.UTC,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(exporter)
+		}
+	}
+
+	return exporter
+}
+
+// Record saves a referral message received by the phone number phoneNumberID. The event is
+// received now when info has no valid timestamp.
+func (e *Exporter) Record(ctx context.Context, phoneNumberID string, info *message.Info,
+	referral *message.Referral,
+) error {
+	if info == nil || referral == nil {
+		return ErrInvalidEvent
+	}
+
+	receivedAt := parseTimestamp(info.Timestamp)
+	if receivedAt.IsZero() {
+		receivedAt = e.now()
+	}
+
+	event := &Event{
+		MessageID:     info.ID,
+		PhoneNumberID: phoneNumberID,
+		Contact:       info.From,
+		SourceID:      referral.SourceID,
+		SourceType:    referral.SourceType,
+		SourceURL:     referral.SourceURL,
+		Headline:      referral.Headline,
+		CtwaClid:      referral.CtwaClid,
+		ReceivedAt:    receivedAt,
+	}
+
+	if err := e.store.Save(ctx, event); err != nil {
+		return fmt.Errorf("save referral event: %w", err)
+	}
+
+	return nil
+}
+
+// Respond records a response of the business to contact.
+func (e *Exporter) Respond(ctx context.Context, phoneNumberID, contact string, at time.Time) error {
+	if err := e.store.Respond(ctx, phoneNumberID, contact, at); err != nil {
+		return fmt.Errorf("record referral response: %w", err)
+	}
+
+	return nil
+}
+
+// Handler records the referral messages, set it as the referral message handler.
+func (e *Exporter) Handler() message.ReferralMessageHandler {
+	return message.OnReferralMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, notification *message.ReferralNotification,
+	) error {
+		return e.Record(ctx, phoneNumberID(nctx), mctx, notification.Referral)
+	})
+}
+
+// EchoHandler records the messages sent from the WhatsApp Business app as responses.
+func (e *Exporter) EchoHandler() message.MessageEchoHandler {
+	return message.OnMessageEchoHook(func(ctx context.Context, nctx *message.NotificationContext,
+		echo *message.MessageEcho,
+	) error {
+		at := parseTimestamp(echo.Timestamp)
+		if at.IsZero() {
+			at = e.now()
+		}
+
+		return e.Respond(ctx, phoneNumberID(nctx), echo.To, at)
+	})
+}
+
+// Middleware records the messages sent through the client as responses. Failed sends
+// and status updates are not responses, and a store failure does not fail the send.
+func (e *Exporter) Middleware() wmessage.SenderMiddleware {
+	return func(next wmessage.SenderFunc) wmessage.SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *wmessage.BaseRequest) (*wmessage.Response, error) {
+			response, err := next(ctx, conf, request)
+			if err != nil || request.Type != whttp.RequestTypeSendMessage ||
+				request.Message == nil || request.Message.Status != nil {
+				return response, err
+			}
+
+			_ = e.Respond(ctx, conf.PhoneNumberID, request.Message.To, e.now())
+
+			return response, nil
+		}
+	}
+}
+
+// Report aggregates the events matching query per day and source ID. Reports are ordered
+// by date, then by source ID. The headline and source type of a report are the ones of its
+// latest event.
+func (e *Exporter) Report(ctx context.Context, query *Query) ([]*DailyReport, error) {
+	events, err := e.store.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list referral events: %w", err)
+	}
+
+	return Aggregate(events, e.location), nil
+}
+
+// Aggregate groups events per day in location and source ID, see Exporter.Report.
+func Aggregate(events []*Event, location *time.Location) []*DailyReport {
+	if location == nil {
+		location = time.UTC
+	}
+
+	type group struct {
+		report    *DailyReport
+		latest    time.Time
+		contacts  map[string]struct{}
+		latencies []time.Duration
+	}
+
+	type groupKey struct {
+		date     string
+		sourceID string
+	}
+
+	groups := make(map[groupKey]*group)
+	for _, event := range events {
+		key := groupKey{date: event.ReceivedAt.In(location).Format(DateLayout), sourceID: event.SourceID}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{
+				report:   &DailyReport{Date: key.date, SourceID: key.sourceID},
+				contacts: make(map[string]struct{}),
+			}
+			groups[key] = g
+		}
+
+		g.report.Referrals++
+		g.contacts[event.Contact] = struct{}{}
+		if !event.ReceivedAt.Before(g.latest) {
+			g.latest = event.ReceivedAt
+			g.report.SourceType = event.SourceType
+			g.report.Headline = event.Headline
+		}
+
+		if !event.RespondedAt.IsZero() {
+			g.latencies = append(g.latencies, event.RespondedAt.Sub(event.ReceivedAt))
+		}
+	}
+
+	reports := make([]*DailyReport, 0, len(groups))
+	for _, g := range groups {
+		report := g.report
+		report.Contacts = len(g.contacts)
+		report.Responded = len(g.latencies)
+
+		if len(g.latencies) > 0 {
+			sort.Slice(g.latencies, func(i, j int) bool { return g.latencies[i] < g.latencies[j] })
+
+			var total time.Duration
+			for _, latency := range g.latencies {
+				total += latency
+			}
+
+			report.AverageFirstResponse = total / time.Duration(len(g.latencies))
+			report.MedianFirstResponse = median(g.latencies)
+			report.MaxFirstResponse = g.latencies[len(g.latencies)-1]
+		}
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Date == reports[j].Date {
+			return reports[i].SourceID < reports[j].SourceID
+		}
+
+		return reports[i].Date < reports[j].Date
+	})
+
+	return reports
+}
+
+// CSVHeader is the header row written by WriteCSV.
+var CSVHeader = []string{ //nolint:gochecknoglobals // read only
+	"date", "source_id", "source_type", "headline", "referrals", "contacts", "responded",
+	"average_first_response_seconds", "median_first_response_seconds", "max_first_response_seconds",
+}
+
+// WriteCSV writes reports to w as CSV with a header row. Latencies are in seconds.
+func WriteCSV(w io.Writer, reports []*DailyReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, report := range reports {
+		record := []string{
+			report.Date,
+			report.SourceID,
+			report.SourceType,
+			report.Headline,
+			strconv.Itoa(report.Referrals),
+			strconv.Itoa(report.Contacts),
+			strconv.Itoa(report.Responded),
+			seconds(report.AverageFirstResponse),
+			seconds(report.MedianFirstResponse),
+			seconds(report.MaxFirstResponse),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("write csv record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps events in memory.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	events map[string]*Event
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{events: make(map[string]*Event)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, event *Event) error {
+	if event == nil || event.MessageID == "" {
+		return ErrInvalidEvent
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *event
+	s.events[event.MessageID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStore) Respond(_ context.Context, phoneNumberID, contact string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event.Contact != contact || !event.RespondedAt.IsZero() || at.Before(event.ReceivedAt) {
+			continue
+		}
+
+		if phoneNumberID != "" && event.PhoneNumberID != "" && event.PhoneNumberID != phoneNumberID {
+			continue
+		}
+
+		event.RespondedAt = at
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, query *Query) ([]*Event, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	s.mu.RLock()
+	result := make([]*Event, 0, len(s.events))
+	for _, event := range s.events {
+		if query.matches(event) {
+			e := *event
+			result = append(result, &e)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].ReceivedAt.Equal(result[j].ReceivedAt) {
+			return result[i].MessageID < result[j].MessageID
+		}
+
+		return result[i].ReceivedAt.Before(result[j].ReceivedAt)
+	})
+
+	return result, nil
+}
+
+func (q *Query) matches(event *Event) bool {
+	if q.PhoneNumberID != "" && event.PhoneNumberID != q.PhoneNumberID {
+		return false
+	}
+
+	if q.SourceID != "" && event.SourceID != q.SourceID {
+		return false
+	}
+
+	if !q.Since.IsZero() && event.ReceivedAt.Before(q.Since) {
+		return false
+	}
+
+	return q.Until.IsZero() || event.ReceivedAt.Before(q.Until)
+}
+
+func phoneNumberID(nctx *message.NotificationContext) string {
+	if nctx == nil || nctx.Metadata == nil {
+		return ""
+	}
+
+	return nctx.Metadata.PhoneNumberID
+}
+
+func parseTimestamp(ts string) time.Time {
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(unix, 0).UTC()
+}
+
+func median(sorted []time.Duration) time.Duration {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 0, 64)
+}
+
+type referralError string
+
+func (e referralError) Error() string {
+	return string(e)
+}
+
+const ErrInvalidEvent = referralError("referral event must have a message id and a referral")
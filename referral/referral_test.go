@@ -0,0 +1,116 @@
+package referral_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/referral"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestExporter_Report(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	now := day
+	exporter := referral.NewExporter(referral.NewMemoryStore(),
+		referral.WithExporterClock(func() time.Time { return now }))
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "111"}}
+	receive := func(id, from, sourceID, headline string, at time.Time) {
+		t.Helper()
+		info := &message.Info{ID: id, From: from, Timestamp: strconv.FormatInt(at.Unix(), 10)}
+		notification := &message.ReferralNotification{Referral: &message.Referral{
+			SourceID: sourceID, SourceType: "ad", Headline: headline,
+		}}
+		if err := exporter.Handler().Handle(ctx, nctx, info, notification); err != nil {
+			t.Fatalf("record referral: %v", err)
+		}
+	}
+
+	receive("m1", "255700000001", "ad-1", "Old headline", day)
+	receive("m2", "255700000002", "ad-1", "Spring sale", day.Add(time.Hour))
+	receive("m3", "255700000001", "ad-1", "Spring sale", day.Add(2*time.Hour))
+	receive("m4", "255700000003", "ad-2", "Free delivery", day.Add(24*time.Hour))
+
+	send := exporter.Middleware()(func(context.Context, *config.Config, *wmessage.BaseRequest) (*wmessage.Response, error) {
+		return &wmessage.Response{}, nil
+	})
+	reply := func(to string, at time.Time) {
+		t.Helper()
+		now = at
+		request := &wmessage.BaseRequest{Type: whttp.RequestTypeSendMessage, Message: &wmessage.Message{To: to}}
+		if _, err := send(ctx, &config.Config{PhoneNumberID: "111"}, request); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	// m1 and m3 are answered by the same reply, m2 is answered later and m4 never.
+	reply("255700000001", day.Add(2*time.Hour+time.Minute))
+	reply("255700000002", day.Add(time.Hour+3*time.Minute))
+	reply("255700000002", day.Add(5*time.Hour))
+
+	reports, err := exporter.Report(ctx, nil)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	want := []*referral.DailyReport{
+		{
+			Date:                 "2024-05-01",
+			SourceID:             "ad-1",
+			SourceType:           "ad",
+			Headline:             "Spring sale",
+			Referrals:            3,
+			Contacts:             2,
+			Responded:            3,
+			AverageFirstResponse: (121 + 3 + 1) * time.Minute / 3,
+			MedianFirstResponse:  3 * time.Minute,
+			MaxFirstResponse:     121 * time.Minute,
+		},
+		{
+			Date:       "2024-05-02",
+			SourceID:   "ad-2",
+			SourceType: "ad",
+			Headline:   "Free delivery",
+			Referrals:  1,
+			Contacts:   1,
+		},
+	}
+
+	if diff := gcmp.Diff(want, reports); diff != "" {
+		t.Fatalf("reports mismatch (-want +got):\n%s", diff)
+	}
+
+	filtered, err := exporter.Report(ctx, &referral.Query{SourceID: "ad-2"})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	if len(filtered) != 1 || filtered[0].SourceID != "ad-2" {
+		t.Fatalf("expected only the ad-2 report, got %+v", filtered)
+	}
+
+	var buf bytes.Buffer
+	if err := referral.WriteCSV(&buf, reports); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	wantCSV := "date,source_id,source_type,headline,referrals,contacts,responded," +
+		"average_first_response_seconds,median_first_response_seconds,max_first_response_seconds\n" +
+		"2024-05-01,ad-1,ad,Spring sale,3,2,3,2500,180,7260\n" +
+		"2024-05-02,ad-2,ad,Free delivery,1,1,0,0,0,0\n"
+
+	if diff := gcmp.Diff(wantCSV, buf.String()); diff != "" {
+		t.Fatalf("csv mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,315 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package subscription manages the apps subscribed to the webhooks of a WhatsApp Business
+// Account. An app must be subscribed to the account to receive its webhooks, and the
+// subscription can override the callback URL of the app for that account.
+package subscription
+
+//go:generate mockgen -destination=../mocks/subscription/mock_subscription.go -package=subscription -source=subscription.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/config"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const EndpointSubscribedApps = "subscribed_apps"
+
+type (
+	// SubscribeRequest subscribes the app of the access token. OverrideCallbackURI, when
+	// set, receives the webhooks of the account instead of the callback URL of the app,
+	// it is verified with VerifyToken first.
+	SubscribeRequest struct {
+		OverrideCallbackURI string `json:"override_callback_uri,omitempty"`
+		VerifyToken         string `json:"verify_token,omitempty"`
+	}
+
+	SubscribedApp struct {
+		Data                *AppData `json:"whatsapp_business_api_data,omitempty"`
+		OverrideCallbackURI string   `json:"override_callback_uri,omitempty"`
+	}
+
+	AppData struct {
+		ID       string `json:"id"`
+		Name     string `json:"name,omitempty"`
+		Link     string `json:"link,omitempty"`
+		Category string `json:"category,omitempty"`
+	}
+
+	ListResponse struct {
+		Data []*SubscribedApp `json:"data"`
+	}
+
+	SuccessResponse struct {
+		Success bool `json:"success"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+// App returns the subscribed app with the given id, or nil.
+func (r *ListResponse) App(appID string) *SubscribedApp {
+	for _, app := range r.Data {
+		if app.Data != nil && app.Data.ID == appID {
+			return app
+		}
+	}
+
+	return nil
+}
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Subscribe(ctx context.Context, req *SubscribeRequest) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Subscribe(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) List(ctx context.Context) (*ListResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return List(ctx, c.Sender, conf)
+}
+
+func (c *BaseClient) Unsubscribe(ctx context.Context) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Unsubscribe(ctx, c.Sender, conf)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Subscribe(ctx context.Context, req *SubscribeRequest) (*SuccessResponse, error) {
+	return Subscribe(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) List(ctx context.Context) (*ListResponse, error) {
+	return List(ctx, c.Sender, c.Config)
+}
+
+func (c *Client) Unsubscribe(ctx context.Context) (*SuccessResponse, error) {
+	return Unsubscribe(ctx, c.Sender, c.Config)
+}
+
+var (
+	ErrSubscribe         = errors.New("failed to subscribe app")
+	ErrListSubscriptions = errors.New("failed to list subscribed apps")
+	ErrUnsubscribe       = errors.New("failed to unsubscribe app")
+	ErrMissingAccountID  = errors.New("business account id is required")
+	ErrMissingToken      = errors.New("verify token is required with an override callback uri")
+)
+
+// Subscribe subscribes the app to the webhooks of the business account, or changes the
+// callback override of an existing subscription. An empty request removes the override.
+func Subscribe(ctx context.Context, sender Sender, conf *config.Config, req *SubscribeRequest) (*SuccessResponse, error) {
+	if conf.BusinessAccountID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrSubscribe, ErrMissingAccountID)
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodPost,
+		Type:      whttp.RequestTypeSubscribeApp,
+		Endpoints: []string{conf.BusinessAccountID, EndpointSubscribedApps},
+	}
+
+	if req != nil && req.OverrideCallbackURI != "" {
+		if req.VerifyToken == "" {
+			return nil, fmt.Errorf("%w: %w", ErrSubscribe, ErrMissingToken)
+		}
+		request.Body = req
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSubscribe, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+// List lists the apps subscribed to the business account.
+func List(ctx context.Context, sender Sender, conf *config.Config) (*ListResponse, error) {
+	if conf.BusinessAccountID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrListSubscriptions, ErrMissingAccountID)
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodGet,
+		Type:      whttp.RequestTypeListSubscribedApps,
+		Endpoints: []string{conf.BusinessAccountID, EndpointSubscribedApps},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListSubscriptions, err)
+	}
+
+	return &ListResponse{Data: response.Data}, nil
+}
+
+// Unsubscribe stops the webhooks of the business account from reaching the app.
+func Unsubscribe(ctx context.Context, sender Sender, conf *config.Config) (*SuccessResponse, error) {
+	if conf.BusinessAccountID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrUnsubscribe, ErrMissingAccountID)
+	}
+
+	request := &BaseRequest{
+		Method:    http.MethodDelete,
+		Type:      whttp.RequestTypeUnsubscribeApp,
+		Endpoints: []string{conf.BusinessAccountID, EndpointSubscribedApps},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsubscribe, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+type (
+	// BaseRequest reads or changes the apps subscribed to the webhooks of the business account.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response lists the subscribed apps or reports the outcome of a change.
+	Response struct {
+		Data    []*SubscribedApp `json:"data,omitempty"`
+		Success bool             `json:"success,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	Subscribe(ctx context.Context, req *SubscribeRequest) (*SuccessResponse, error)
+	List(ctx context.Context) (*ListResponse, error)
+	Unsubscribe(ctx context.Context) (*SuccessResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
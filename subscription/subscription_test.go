@@ -0,0 +1,84 @@
+package subscription_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/subscription"
+)
+
+func TestBaseClient(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/waba/subscribed_apps" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		encoded, _ := json.Marshal(body)
+		requests = append(requests, r.Method+" "+string(encoded))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"data":[{"whatsapp_business_api_data":{"id":"app-1","name":"Support",` +
+				`"link":"https://example.com"},"override_callback_uri":"https://example.com/waba"}]}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	client := subscription.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	if response, err := client.Subscribe(ctx, nil); err != nil || !response.Success {
+		t.Fatalf("Subscribe() = %+v, %v", response, err)
+	}
+
+	_, err := client.Subscribe(ctx, &subscription.SubscribeRequest{
+		OverrideCallbackURI: "https://example.com/waba",
+		VerifyToken:         "secret",
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	apps, err := client.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if app := apps.App("app-1"); app == nil || app.OverrideCallbackURI != "https://example.com/waba" {
+		t.Errorf("App() = %+v", app)
+	}
+
+	if response, err := client.Unsubscribe(ctx); err != nil || !response.Success {
+		t.Fatalf("Unsubscribe() = %+v, %v", response, err)
+	}
+
+	want := []string{
+		"POST {}",
+		`POST {"override_callback_uri":"https://example.com/waba","verify_token":"secret"}`,
+		"GET {}",
+		"DELETE {}",
+	}
+	if diff := gcmp.Diff(want, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = client.Subscribe(ctx, &subscription.SubscribeRequest{OverrideCallbackURI: "https://example.com"})
+	if !errors.Is(err, subscription.ErrMissingToken) {
+		t.Errorf("Subscribe() error = %v, want ErrMissingToken", err)
+	}
+}
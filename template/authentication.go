@@ -0,0 +1,123 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+const (
+	// MaxCodeExpirationMinutes is the longest validity period of authentication codes.
+	MaxCodeExpirationMinutes = 90
+
+	// MaxSupportedApps is the maximum number of apps of one-tap and zero-tap buttons.
+	MaxSupportedApps = 5
+)
+
+var ErrInvalidAuthenticationTemplate = errors.New("invalid authentication template")
+
+// Authentication describes an authentication template. Its body and footer are fixed by
+// Meta: the body shows the code, with a security recommendation when
+// AddSecurityRecommendation is set, and the footer says how long the code is valid when
+// CodeExpirationMinutes is set.
+//
+// OTPType is the kind of button. ONE_TAP and ZERO_TAP buttons hand the code to one of
+// SupportedApps and fall back to copying the code on devices without them, with
+// ButtonText on the copy code button and AutofillText on the autofill one. Zero-tap
+// buttons also need ZeroTapTermsAccepted.
+type Authentication struct {
+	OTPType                   message.OTPButtonType
+	ButtonText                string
+	AutofillText              string
+	SupportedApps             []*SupportedApp
+	ZeroTapTermsAccepted      bool
+	AddSecurityRecommendation bool
+	CodeExpirationMinutes     int
+}
+
+// OTPButton creates the OTP button of auth.
+func OTPButton(auth *Authentication) *Button {
+	return &Button{
+		Type:                 message.TemplateButtonTypeOTP,
+		OTPType:              string(auth.OTPType),
+		Text:                 auth.ButtonText,
+		AutofillText:         auth.AutofillText,
+		SupportedApps:        auth.SupportedApps,
+		ZeroTapTermsAccepted: auth.ZeroTapTermsAccepted,
+	}
+}
+
+// NewAuthenticationCreateRequest validates auth and creates the request creating the
+// authentication template. Messages are sent with message.NewOTPTemplate.
+func NewAuthenticationCreateRequest(name, language string, auth *Authentication) (*CreateRequest, error) {
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+
+	var footer *Component
+	if auth.CodeExpirationMinutes > 0 {
+		footer = &Component{Type: ComponentTypeFooter, CodeExpirationMinutes: auth.CodeExpirationMinutes}
+	}
+
+	return NewCreateRequest(name, language, message.TemplateCategoryAuthentication,
+		&Component{Type: ComponentTypeBody, AddSecurityRecommendation: auth.AddSecurityRecommendation},
+		footer,
+		Buttons(OTPButton(auth)),
+	), nil
+}
+
+// Validate checks the OTP type, the validity period and the apps of one-tap and zero-tap
+// buttons. Errors wrap ErrInvalidAuthenticationTemplate.
+func (auth *Authentication) Validate() error {
+	var errs []error
+	switch auth.OTPType {
+	case message.OTPButtonCopyCode:
+	case message.OTPButtonOneTap, message.OTPButtonZeroTap:
+		if len(auth.SupportedApps) == 0 || len(auth.SupportedApps) > MaxSupportedApps {
+			errs = append(errs, fmt.Errorf("%s buttons need 1 to %d supported apps", auth.OTPType, MaxSupportedApps))
+		}
+
+		for i, app := range auth.SupportedApps {
+			if app == nil || app.PackageName == "" || app.SignatureHash == "" {
+				errs = append(errs, fmt.Errorf("supported app %d needs a package name and a signature hash", i))
+			}
+		}
+
+		if auth.OTPType == message.OTPButtonZeroTap && !auth.ZeroTapTermsAccepted {
+			errs = append(errs, errors.New("zero-tap terms must be accepted"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", message.ErrInvalidOTPButtonType, string(auth.OTPType)))
+	}
+
+	if auth.CodeExpirationMinutes < 0 || auth.CodeExpirationMinutes > MaxCodeExpirationMinutes {
+		errs = append(errs, fmt.Errorf("code expiration must be between 1 and %d minutes, got %d",
+			MaxCodeExpirationMinutes, auth.CodeExpirationMinutes))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidAuthenticationTemplate, errors.Join(errs...))
+	}
+
+	return nil
+}
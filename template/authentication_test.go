@@ -0,0 +1,81 @@
+package template_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/template"
+)
+
+func TestNewAuthenticationCreateRequest(t *testing.T) {
+	t.Parallel()
+
+	req, err := template.NewAuthenticationCreateRequest("login_code", "en_US", &template.Authentication{
+		OTPType:                   message.OTPButtonOneTap,
+		ButtonText:                "Copy code",
+		AutofillText:              "Autofill",
+		SupportedApps:             []*template.SupportedApp{{PackageName: "com.example", SignatureHash: "K8a/AINcGX7"}},
+		AddSecurityRecommendation: true,
+		CodeExpirationMinutes:     10,
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticationCreateRequest() error = %v", err)
+	}
+
+	got, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"name":"login_code","language":"en_US","category":"AUTHENTICATION","components":[` +
+		`{"type":"BODY","add_security_recommendation":true},{"type":"FOOTER","code_expiration_minutes":10},` +
+		`{"type":"BUTTONS","buttons":[{"type":"OTP","text":"Copy code","otp_type":"ONE_TAP",` +
+		`"autofill_text":"Autofill","supported_apps":[{"package_name":"com.example","signature_hash":"K8a/AINcGX7"}]}]}]}`
+	if diff := gcmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+
+	tmpl := &message.Template{Category: message.TemplateCategoryAuthentication, Components: []*message.TemplateComponent{
+		{Buttons: []*message.TemplateButton{{Type: message.TemplateButtonTypeOTP}}},
+	}}
+	if err := tmpl.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestAuthentication_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		auth *template.Authentication
+	}{
+		{name: "unknown otp type", auth: &template.Authentication{OTPType: message.OTPButtonURL}},
+		{name: "one tap without apps", auth: &template.Authentication{OTPType: message.OTPButtonOneTap}},
+		{
+			name: "zero tap terms",
+			auth: &template.Authentication{
+				OTPType:       message.OTPButtonZeroTap,
+				SupportedApps: []*template.SupportedApp{{PackageName: "com.example", SignatureHash: "hash"}},
+			},
+		},
+		{
+			name: "code expiration",
+			auth: &template.Authentication{OTPType: message.OTPButtonCopyCode, CodeExpirationMinutes: 120},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.auth.Validate(); !errors.Is(err, template.ErrInvalidAuthenticationTemplate) {
+				t.Errorf("Validate() error = %v, want ErrInvalidAuthenticationTemplate", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+const (
+	ParameterFormatPositional = "POSITIONAL"
+	ParameterFormatNamed      = "NAMED"
+
+	// ValueKeyHeader is the key of the header media or location in the values passed to
+	// Binder.Bind.
+	ValueKeyHeader = "header"
+
+	// ValueKeyButtonPrefix prefixes the index of a button in the values passed to
+	// Binder.Bind, "button.0" is the value of the first button.
+	ValueKeyButtonPrefix = "button."
+)
+
+var (
+	ErrBindTemplate       = errors.New("failed to bind template")
+	ErrMissingValue       = errors.New("missing value")
+	ErrUnexpectedValue    = errors.New("value is not used by the template")
+	ErrInvalidValueType   = errors.New("invalid value type")
+	ErrInvalidPlaceholder = errors.New("invalid placeholder")
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]*)\s*\}\}`)
+
+// BindingError reports a value that does not fit the template definition.
+type BindingError struct {
+	Component string
+	Key       string
+	Err       error
+}
+
+func (e *BindingError) Error() string {
+	return fmt.Sprintf("%s %q: %v", strings.ToLower(e.Component), e.Key, e.Err)
+}
+
+func (e *BindingError) Unwrap() error {
+	return e.Err
+}
+
+// Binder turns named values into the components of a template message, checking them
+// against the template definition so that mismatches are reported before the API rejects
+// the message with error 132000.
+//
+// The values are keyed by placeholder, "1" for {{1}} in positional templates and
+// "first_name" for {{first_name}} in named ones. Body values are strings,
+// *message.TemplateCurrency or *message.TemplateDateTime, header text values are strings.
+// The header media, a *message.Image, *message.Video, *message.Document or
+// *message.Location matching the header format, is keyed by ValueKeyHeader. Buttons are
+// keyed by ValueKeyButtonPrefix and their index: URL buttons with a variable and copy code
+// buttons need a string, quick reply buttons take an optional payload string.
+type Binder struct {
+	template *Template
+}
+
+// NewBinder creates a Binder for a template fetched with Get or List.
+func NewBinder(tmpl *Template) *Binder {
+	return &Binder{template: tmpl}
+}
+
+// Bind returns the template message for values. All the mismatches are reported at once,
+// the returned error wraps ErrBindTemplate and a *BindingError per mismatch.
+func (b *Binder) Bind(values map[string]any) (*message.Template, error) {
+	binding := &binding{
+		values: values,
+		used:   make(map[string]bool, len(values)),
+		named:  strings.EqualFold(b.template.ParameterFormat, ParameterFormatNamed),
+	}
+
+	components := make([]*message.TemplateComponent, 0, len(b.template.Components))
+	for _, component := range b.template.Components {
+		var bound []*message.TemplateComponent
+
+		switch component.Type {
+		case ComponentTypeHeader:
+			bound = binding.header(component)
+		case ComponentTypeBody:
+			bound = binding.body(component)
+		case ComponentTypeButtons:
+			bound = binding.buttons(component)
+		}
+
+		components = append(components, bound...)
+	}
+
+	binding.unused()
+
+	if len(binding.errs) > 0 {
+		return nil, fmt.Errorf("%w %q: %w", ErrBindTemplate, b.template.Name, errors.Join(binding.errs...))
+	}
+
+	return &message.Template{
+		Name: b.template.Name,
+		Language: &message.TemplateLanguage{
+			Code:   b.template.Language,
+			Policy: "deterministic",
+		},
+		Components: components,
+	}, nil
+}
+
+type binding struct {
+	values map[string]any
+	used   map[string]bool
+	named  bool
+	errs   []error
+}
+
+func (b *binding) fail(component, key string, err error) {
+	b.errs = append(b.errs, &BindingError{Component: component, Key: key, Err: err})
+}
+
+func (b *binding) value(key string) (any, bool) {
+	value, ok := b.values[key]
+	if ok {
+		b.used[key] = true
+	}
+
+	return value, ok && value != nil
+}
+
+// placeholders returns the distinct placeholders of text in order of appearance.
+func (b *binding) placeholders(component, text string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		if !b.validPlaceholder(name) {
+			b.fail(component, name, ErrInvalidPlaceholder)
+
+			continue
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (b *binding) validPlaceholder(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	if b.named {
+		return strings.ToLower(name) == name && strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789_") == ""
+	}
+
+	n, err := strconv.Atoi(name)
+
+	return err == nil && n > 0
+}
+
+func (b *binding) textParameters(component, text string, allowRich bool) []*message.TemplateParameter {
+	names := b.placeholders(component, text)
+	parameters := make([]*message.TemplateParameter, 0, len(names))
+
+	for _, name := range names {
+		value, ok := b.value(name)
+		if !ok {
+			b.fail(component, name, ErrMissingValue)
+
+			continue
+		}
+
+		parameter, err := textParameter(value, allowRich)
+		if err != nil {
+			b.fail(component, name, err)
+
+			continue
+		}
+
+		if b.named {
+			parameter.ParameterName = name
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func (b *binding) header(component *Component) []*message.TemplateComponent {
+	var parameters []*message.TemplateParameter
+
+	switch {
+	case component.Format == message.TemplateHeaderFormatText || component.Format == "":
+		parameters = b.textParameters(component.Type, component.Text, false)
+	case component.Format.IsMedia() || component.Format == message.TemplateHeaderFormatLocation:
+		value, ok := b.value(ValueKeyHeader)
+		if !ok {
+			b.fail(component.Type, ValueKeyHeader, ErrMissingValue)
+
+			return nil
+		}
+
+		parameter, err := headerParameter(component.Format, value)
+		if err != nil {
+			b.fail(component.Type, ValueKeyHeader, err)
+
+			return nil
+		}
+
+		parameters = []*message.TemplateParameter{parameter}
+	}
+
+	if len(parameters) == 0 {
+		return nil
+	}
+
+	return []*message.TemplateComponent{{
+		Type:       message.TemplateComponentTypeHeader,
+		Parameters: parameters,
+	}}
+}
+
+func (b *binding) body(component *Component) []*message.TemplateComponent {
+	parameters := b.textParameters(component.Type, component.Text, true)
+	if len(parameters) == 0 {
+		return nil
+	}
+
+	return []*message.TemplateComponent{{
+		Type:       message.TemplateComponentTypeBody,
+		Parameters: parameters,
+	}}
+}
+
+func (b *binding) buttons(component *Component) []*message.TemplateComponent {
+	var components []*message.TemplateComponent
+
+	for index, button := range component.Buttons {
+		key := ValueKeyButtonPrefix + strconv.Itoa(index)
+		value, ok := b.value(key)
+
+		var subType, parameterType string
+
+		switch button.Type {
+		case message.TemplateButtonTypeURL:
+			if !strings.Contains(button.URL, "{{") {
+				continue
+			}
+
+			subType, parameterType = message.TemplateButtonSubTypeURL, message.TemplateParameterTypeText
+		case message.TemplateButtonTypeCopyCode:
+			subType, parameterType = message.TemplateButtonSubTypeCopyCode, message.TemplateParameterTypeCouponCode
+		case message.TemplateButtonTypeQuickReply:
+			if !ok {
+				continue
+			}
+
+			subType, parameterType = message.TemplateButtonSubTypeQuickReply, message.TemplateParameterTypePayload
+		default:
+			continue
+		}
+
+		if !ok {
+			b.fail(ComponentTypeButtons, key, ErrMissingValue)
+
+			continue
+		}
+
+		text, isString := value.(string)
+		if !isString {
+			b.fail(ComponentTypeButtons, key, fmt.Errorf("%w: %T, want string", ErrInvalidValueType, value))
+
+			continue
+		}
+
+		parameter := &message.TemplateParameter{Type: parameterType}
+		if parameterType == message.TemplateParameterTypePayload {
+			parameter.Payload = text
+		} else {
+			parameter.Text = text
+		}
+
+		components = append(components, &message.TemplateComponent{
+			Type:       message.TemplateComponentTypeButton,
+			SubType:    subType,
+			Index:      index,
+			Parameters: []*message.TemplateParameter{parameter},
+		})
+	}
+
+	return components
+}
+
+// unused reports the values the template has no placeholder for, in key order.
+func (b *binding) unused() {
+	keys := make([]string, 0, len(b.values))
+	for key := range b.values {
+		if !b.used[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b.fail("values", key, ErrUnexpectedValue)
+	}
+}
+
+func textParameter(value any, allowRich bool) (*message.TemplateParameter, error) {
+	switch v := value.(type) {
+	case string:
+		return &message.TemplateParameter{Type: message.TemplateParameterTypeText, Text: v}, nil
+	case *message.TemplateCurrency:
+		if allowRich {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeCurrency, Currency: v}, nil
+		}
+	case *message.TemplateDateTime:
+		if allowRich {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeDateTime, DateTime: v}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrInvalidValueType, value)
+}
+
+func headerParameter(format message.TemplateHeaderFormat, value any) (*message.TemplateParameter, error) {
+	switch v := value.(type) {
+	case *message.Image:
+		if format == message.TemplateHeaderFormatImage {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeImage, Image: v}, nil
+		}
+	case *message.Video:
+		if format == message.TemplateHeaderFormatVideo {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeVideo, Video: v}, nil
+		}
+	case *message.Document:
+		if format == message.TemplateHeaderFormatDocument {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeDocument, Document: v}, nil
+		}
+	case *message.Location:
+		if format == message.TemplateHeaderFormatLocation {
+			return &message.TemplateParameter{Type: message.TemplateParameterTypeLocation, Location: v}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %T for a %s header", ErrInvalidValueType, value, format)
+}
@@ -0,0 +1,106 @@
+package template_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/template"
+)
+
+func TestBinder_Bind(t *testing.T) {
+	t.Parallel()
+
+	tmpl := &template.Template{
+		Name:            "order_shipped",
+		Language:        "en_US",
+		ParameterFormat: template.ParameterFormatNamed,
+		Components: []*template.Component{
+			template.HeaderMedia(message.TemplateHeaderFormatImage, "handle"),
+			template.Body("Hi {{first_name}}, order {{order_id}} ships today. Thanks {{first_name}}!"),
+			template.Footer("Reply STOP to opt out"),
+			template.Buttons(
+				template.URLButton("Track", "https://example.com/track/{{1}}"),
+				template.QuickReplyButton("Stop"),
+			),
+		},
+	}
+
+	got, err := template.NewBinder(tmpl).Bind(map[string]any{
+		"header":     &message.Image{Link: "https://example.com/box.png"},
+		"first_name": "Asha",
+		"order_id":   "A-42",
+		"button.0":   "A-42",
+	})
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	encoded, err := json.Marshal(got.Components)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var components []map[string]any
+	if err := json.Unmarshal(encoded, &components); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"header", "body", "button"}
+	gotTypes := make([]string, 0, len(components))
+	for _, component := range components {
+		gotTypes = append(gotTypes, component["type"].(string))
+	}
+
+	if diff := gcmp.Diff(want, gotTypes); diff != "" {
+		t.Errorf("component types mismatch (-want +got):\n%s", diff)
+	}
+
+	body := got.Components[1].Parameters
+	if len(body) != 2 || body[0].ParameterName != "first_name" || body[0].Text != "Asha" ||
+		body[1].ParameterName != "order_id" || body[1].Text != "A-42" {
+		t.Errorf("unexpected body parameters: %+v, %+v", body[0], body[1])
+	}
+
+	if button := got.Components[2]; button.SubType != message.TemplateButtonSubTypeURL || button.Index != 0 ||
+		button.Parameters[0].Text != "A-42" {
+		t.Errorf("unexpected button: %+v", button)
+	}
+}
+
+func TestBinder_BindMismatches(t *testing.T) {
+	t.Parallel()
+
+	tmpl := &template.Template{
+		Name:     "reminder",
+		Language: "en_US",
+		Components: []*template.Component{
+			template.HeaderMedia(message.TemplateHeaderFormatDocument, "handle"),
+			template.Body("Your appointment is on {{1}} at {{2}}."),
+			template.Buttons(template.CopyCodeButton("SAVE10")),
+		},
+	}
+
+	_, err := template.NewBinder(tmpl).Bind(map[string]any{
+		"header": &message.Image{ID: "media"},
+		"1":      42,
+		"3":      "extra",
+	})
+
+	for _, target := range []error{
+		template.ErrBindTemplate, template.ErrInvalidValueType,
+		template.ErrMissingValue, template.ErrUnexpectedValue,
+	} {
+		if !errors.Is(err, target) {
+			t.Errorf("Bind() error = %v, want it to wrap %v", err, target)
+		}
+	}
+
+	var bindingErr *template.BindingError
+	if !errors.As(err, &bindingErr) || bindingErr.Key != template.ValueKeyHeader {
+		t.Errorf("first binding error = %+v, want the header", bindingErr)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+import "github.com/piusalfred/whatsapp/message"
+
+// HeaderText creates a text header. examples are the sample values of the variables in
+// text, if any.
+func HeaderText(text string, examples ...string) *Component {
+	component := &Component{
+		Type:   ComponentTypeHeader,
+		Format: message.TemplateHeaderFormatText,
+		Text:   text,
+	}
+
+	if len(examples) > 0 {
+		component.Example = &Example{HeaderText: examples}
+	}
+
+	return component
+}
+
+// HeaderMedia creates an image, video or document header. handle is the sample media
+// handle obtained from the resumable upload API.
+func HeaderMedia(format message.TemplateHeaderFormat, handle string) *Component {
+	return &Component{
+		Type:    ComponentTypeHeader,
+		Format:  format,
+		Example: &Example{HeaderHandle: []string{handle}},
+	}
+}
+
+// HeaderLocation creates a location header, the location is set when sending.
+func HeaderLocation() *Component {
+	return &Component{
+		Type:   ComponentTypeHeader,
+		Format: message.TemplateHeaderFormatLocation,
+	}
+}
+
+// Body creates the body component. examples are the sample values of the variables in
+// text, in order.
+func Body(text string, examples ...string) *Component {
+	component := &Component{
+		Type: ComponentTypeBody,
+		Text: text,
+	}
+
+	if len(examples) > 0 {
+		component.Example = &Example{BodyText: [][]string{examples}}
+	}
+
+	return component
+}
+
+func Footer(text string) *Component {
+	return &Component{
+		Type: ComponentTypeFooter,
+		Text: text,
+	}
+}
+
+func Buttons(buttons ...*Button) *Component {
+	return &Component{
+		Type:    ComponentTypeButtons,
+		Buttons: buttons,
+	}
+}
+
+func QuickReplyButton(text string) *Button {
+	return &Button{
+		Type: message.TemplateButtonTypeQuickReply,
+		Text: text,
+	}
+}
+
+// URLButton creates a URL button. If url ends with a {{1}} variable, example is the full
+// sample URL.
+func URLButton(text, url string, example ...string) *Button {
+	return &Button{
+		Type:    message.TemplateButtonTypeURL,
+		Text:    text,
+		URL:     url,
+		Example: example,
+	}
+}
+
+func PhoneNumberButton(text, phoneNumber string) *Button {
+	return &Button{
+		Type:        message.TemplateButtonTypePhoneNumber,
+		Text:        text,
+		PhoneNumber: phoneNumber,
+	}
+}
+
+// CopyCodeButton creates a copy code button, example is a sample coupon code.
+func CopyCodeButton(example string) *Button {
+	return &Button{
+		Type:    message.TemplateButtonTypeCopyCode,
+		Example: []string{example},
+	}
+}
+
+func FlowButton(text, flowID, flowAction, navigateScreen string) *Button {
+	return &Button{
+		Type:           message.TemplateButtonTypeFlow,
+		Text:           text,
+		FlowID:         flowID,
+		FlowAction:     flowAction,
+		NavigateScreen: navigateScreen,
+	}
+}
+
+// NewCreateRequest creates a CreateRequest from the given components, nil components are
+// skipped.
+func NewCreateRequest(name, language string, category message.TemplateCategory,
+	components ...*Component,
+) *CreateRequest {
+	req := &CreateRequest{
+		Name:       name,
+		Language:   language,
+		Category:   category,
+		Components: make([]*Component, 0, len(components)),
+	}
+
+	for _, component := range components {
+		if component != nil {
+			req.Components = append(req.Components, component)
+		}
+	}
+
+	return req
+}
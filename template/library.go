@@ -0,0 +1,269 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// EndpointLibrary lists the pre-approved utility and authentication templates Meta offers.
+// Templates created from the library are approved without review.
+const EndpointLibrary = "message_template_library"
+
+const (
+	LibraryButtonTypeURL         = "URL"
+	LibraryButtonTypePhoneNumber = "PHONE_NUMBER"
+	LibraryButtonTypeQuickReply  = "QUICK_REPLY"
+	LibraryButtonTypeOTP         = "OTP"
+)
+
+var (
+	ErrListLibrary       = errors.New("failed to list template library")
+	ErrCreateFromLibrary = errors.New("failed to create template from library")
+)
+
+type (
+	// LibraryTemplate is a template of the template library. Header and Body contain
+	// the text with its {{n}} placeholders and BodyParams the sample values of the body
+	// placeholders, in order.
+	LibraryTemplate struct {
+		ID             string                   `json:"id"`
+		Name           string                   `json:"name"`
+		Language       string                   `json:"language"`
+		Category       message.TemplateCategory `json:"category"`
+		Topic          string                   `json:"topic,omitempty"`
+		UseCase        string                   `json:"usecase,omitempty"`
+		Industry       []string                 `json:"industry,omitempty"`
+		Header         string                   `json:"header,omitempty"`
+		Body           string                   `json:"body"`
+		BodyParams     []string                 `json:"body_params,omitempty"`
+		BodyParamTypes []string                 `json:"body_param_types,omitempty"`
+		Buttons        []*LibraryButton         `json:"buttons,omitempty"`
+	}
+
+	LibraryButton struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		URL         string `json:"url,omitempty"`
+		PhoneNumber string `json:"phone_number,omitempty"`
+	}
+
+	// LibraryListRequest filters and pages the template library. Search matches the name
+	// and the content of the templates.
+	LibraryListRequest struct {
+		Search   string
+		Topic    string
+		UseCase  string
+		Industry string
+		Language string
+		Limit    int
+		After    string
+		Before   string
+	}
+
+	LibraryListResponse struct {
+		Data   []*LibraryTemplate `json:"data"`
+		Paging *Paging            `json:"paging,omitempty"`
+	}
+
+	// LibraryCreateRequest creates a template in the business account from the library
+	// template named LibraryTemplateName. URL and phone number buttons need ButtonInputs,
+	// authentication templates take their options from BodyInputs.
+	LibraryCreateRequest struct {
+		Name                string                   `json:"name"`
+		Language            string                   `json:"language"`
+		Category            message.TemplateCategory `json:"category"`
+		LibraryTemplateName string                   `json:"library_template_name"`
+		ButtonInputs        []*LibraryButtonInput    `json:"library_template_button_inputs,omitempty"`
+		BodyInputs          *LibraryBodyInputs       `json:"library_template_body_inputs,omitempty"`
+	}
+
+	// LibraryButtonInput fills in a button of the library template, buttons are matched
+	// by their position.
+	LibraryButtonInput struct {
+		Type        string           `json:"type"`
+		URL         *LibraryURLInput `json:"url,omitempty"`
+		PhoneNumber string           `json:"phone_number,omitempty"`
+		OTPType     string           `json:"otp_type,omitempty"`
+	}
+
+	// LibraryURLInput is the URL of a button. BaseURL may end with a {{1}} placeholder,
+	// URLSuffixExample is then a sample of the full URL.
+	LibraryURLInput struct {
+		BaseURL          string `json:"base_url"`
+		URLSuffixExample string `json:"url_suffix_example,omitempty"`
+	}
+
+	LibraryBodyInputs struct {
+		AddContactNumber          bool `json:"add_contact_number,omitempty"`
+		AddLearnMoreLink          bool `json:"add_learn_more_link,omitempty"`
+		AddSecurityRecommendation bool `json:"add_security_recommendation,omitempty"`
+		AddTrackPackageLink       bool `json:"add_track_package_link,omitempty"`
+		CodeExpirationMinutes     int  `json:"code_expiration_minutes,omitempty"`
+	}
+)
+
+// Instantiate returns a request creating this library template as name. inputs fill in its
+// URL and phone number buttons.
+func (t *LibraryTemplate) Instantiate(name string, inputs ...*LibraryButtonInput) *LibraryCreateRequest {
+	return &LibraryCreateRequest{
+		Name:                name,
+		Language:            t.Language,
+		Category:            t.Category,
+		LibraryTemplateName: t.Name,
+		ButtonInputs:        inputs,
+	}
+}
+
+func ListLibrary(ctx context.Context, sender Sender, conf *config.Config,
+	req *LibraryListRequest,
+) (*LibraryListResponse, error) {
+	if req == nil {
+		req = &LibraryListRequest{}
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListTemplateLibrary,
+		Endpoint:    EndpointLibrary,
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListLibrary, err)
+	}
+
+	var list LibraryListResponse
+	if err := json.Unmarshal(response.Raw, &list); err != nil {
+		return nil, fmt.Errorf("%w: decode response: %w", ErrListLibrary, err)
+	}
+
+	return &list, nil
+}
+
+// ListAllLibrary follows the after cursor until every library template matching req has
+// been fetched.
+func ListAllLibrary(ctx context.Context, sender Sender, conf *config.Config,
+	req *LibraryListRequest,
+) ([]*LibraryTemplate, error) {
+	list := LibraryListerFunc(func(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error) {
+		return ListLibrary(ctx, sender, conf, req)
+	})
+
+	templates, err := NewLibraryPager(list, req).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func CreateFromLibrary(ctx context.Context, sender Sender, conf *config.Config,
+	req *LibraryCreateRequest,
+) (*CreateResponse, error) {
+	request := &BaseRequest{
+		Method: http.MethodPost,
+		Type:   whttp.RequestTypeCreateTemplate,
+		Body:   req,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateFromLibrary, err)
+	}
+
+	return &CreateResponse{
+		ID:       response.ID,
+		Status:   response.Status,
+		Category: response.Category,
+	}, nil
+}
+
+func (c *BaseClient) ListLibrary(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListLibrary(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) ListAllLibrary(ctx context.Context, req *LibraryListRequest) ([]*LibraryTemplate, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListAllLibrary(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) CreateFromLibrary(ctx context.Context, req *LibraryCreateRequest) (*CreateResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return CreateFromLibrary(ctx, c.Sender, conf, req)
+}
+
+func (c *Client) ListLibrary(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error) {
+	return ListLibrary(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) ListAllLibrary(ctx context.Context, req *LibraryListRequest) ([]*LibraryTemplate, error) {
+	return ListAllLibrary(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) CreateFromLibrary(ctx context.Context, req *LibraryCreateRequest) (*CreateResponse, error) {
+	return CreateFromLibrary(ctx, c.Sender, c.Config, req)
+}
+
+func (req *LibraryListRequest) queryParams() map[string]string {
+	params := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	set("search", req.Search)
+	set("topic", req.Topic)
+	set("usecase", req.UseCase)
+	set("industry", req.Industry)
+	set("language", req.Language)
+	set("after", req.After)
+	set("before", req.Before)
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	return params
+}
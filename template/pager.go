@@ -0,0 +1,99 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+import (
+	"context"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+type (
+	// Lister lists templates, it is implemented by BaseClient and Client.
+	Lister interface {
+		List(ctx context.Context, req *ListRequest) (*ListResponse, error)
+	}
+
+	ListerFunc func(ctx context.Context, req *ListRequest) (*ListResponse, error)
+
+	// LibraryLister lists the template library, it is implemented by BaseClient and Client.
+	LibraryLister interface {
+		ListLibrary(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error)
+	}
+
+	LibraryListerFunc func(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error)
+)
+
+func (fn ListerFunc) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return fn(ctx, req)
+}
+
+func (fn LibraryListerFunc) ListLibrary(ctx context.Context, req *LibraryListRequest) (*LibraryListResponse, error) {
+	return fn(ctx, req)
+}
+
+// NewPager creates a pager over the templates matching req. The Limit of req sets the page
+// size and its After cursor, when set, resumes a previous walk from that page.
+func NewPager(lister Lister, req *ListRequest) *whttp.Pager[*Template] {
+	page := &ListRequest{}
+	if req != nil {
+		*page = *req
+	}
+	page.Before = ""
+
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*Template], error) {
+		r := *page
+		r.After = after
+
+		response, err := lister.List(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*Template]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, page.After)
+}
+
+// NewLibraryPager creates a pager over the library templates matching req. The Limit of
+// req sets the page size and its After cursor, when set, resumes a previous walk from that
+// page.
+func NewLibraryPager(lister LibraryLister, req *LibraryListRequest) *whttp.Pager[*LibraryTemplate] {
+	page := &LibraryListRequest{}
+	if req != nil {
+		*page = *req
+	}
+	page.Before = ""
+
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*LibraryTemplate], error) {
+		r := *page
+		r.After = after
+
+		response, err := lister.ListLibrary(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*LibraryTemplate]{Data: response.Data, Paging: response.Paging}, nil
+	}
+
+	return whttp.NewPager(fetch, page.After)
+}
@@ -0,0 +1,552 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package template
+
+//go:generate mockgen -destination=../mocks/template/mock_template.go -package=template -source=template.go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/config"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const Endpoint = "message_templates"
+
+const (
+	StatusApproved        = "APPROVED"
+	StatusPending         = "PENDING"
+	StatusRejected        = "REJECTED"
+	StatusPaused          = "PAUSED"
+	StatusDisabled        = "DISABLED"
+	StatusInAppeal        = "IN_APPEAL"
+	StatusPendingDeletion = "PENDING_DELETION"
+)
+
+const (
+	ComponentTypeHeader   = "HEADER"
+	ComponentTypeBody     = "BODY"
+	ComponentTypeFooter   = "FOOTER"
+	ComponentTypeButtons  = "BUTTONS"
+	ComponentTypeCarousel = "CAROUSEL"
+)
+
+type (
+	// Template is a message template as stored on the WhatsApp Business Account.
+	Template struct {
+		ID                  string                   `json:"id,omitempty"`
+		Name                string                   `json:"name"`
+		Language            string                   `json:"language"`
+		Status              string                   `json:"status,omitempty"`
+		Category            message.TemplateCategory `json:"category"`
+		PreviousCategory    string                   `json:"previous_category,omitempty"`
+		ParameterFormat     string                   `json:"parameter_format,omitempty"`
+		RejectedReason      string                   `json:"rejected_reason,omitempty"`
+		QualityScore        *QualityScore            `json:"quality_score,omitempty"`
+		Components          []*Component             `json:"components,omitempty"`
+		SubCategory         string                   `json:"sub_category,omitempty"`
+		CorrectCategory     string                   `json:"correct_category,omitempty"`
+		LibraryTemplateName string                   `json:"library_template_name,omitempty"`
+	}
+
+	QualityScore struct {
+		Score string `json:"score"`
+		Date  int64  `json:"date,omitempty"`
+	}
+
+	// Component is a part of a template definition. Which fields apply depends on Type.
+	Component struct {
+		Type    string                       `json:"type"`
+		Format  message.TemplateHeaderFormat `json:"format,omitempty"`
+		Text    string                       `json:"text,omitempty"`
+		Example *Example                     `json:"example,omitempty"`
+		Buttons []*Button                    `json:"buttons,omitempty"`
+		Cards   []*Card                      `json:"cards,omitempty"`
+
+		// AddSecurityRecommendation and CodeExpirationMinutes apply to authentication
+		// template body and footer components.
+		AddSecurityRecommendation bool `json:"add_security_recommendation,omitempty"`
+		CodeExpirationMinutes     int  `json:"code_expiration_minutes,omitempty"`
+	}
+
+	// Example holds the sample values Meta reviews a template with.
+	Example struct {
+		HeaderText   []string   `json:"header_text,omitempty"`
+		HeaderHandle []string   `json:"header_handle,omitempty"`
+		BodyText     [][]string `json:"body_text,omitempty"`
+	}
+
+	Button struct {
+		Type           message.TemplateButtonType `json:"type"`
+		Text           string                     `json:"text,omitempty"`
+		URL            string                     `json:"url,omitempty"`
+		PhoneNumber    string                     `json:"phone_number,omitempty"`
+		Example        []string                   `json:"example,omitempty"`
+		FlowID         string                     `json:"flow_id,omitempty"`
+		FlowAction     string                     `json:"flow_action,omitempty"`
+		NavigateScreen string                     `json:"navigate_screen,omitempty"`
+		OTPType        string                     `json:"otp_type,omitempty"`
+
+		// AutofillText, SupportedApps and ZeroTapTermsAccepted apply to one-tap and
+		// zero-tap OTP buttons.
+		AutofillText         string          `json:"autofill_text,omitempty"`
+		SupportedApps        []*SupportedApp `json:"supported_apps,omitempty"`
+		ZeroTapTermsAccepted bool            `json:"zero_tap_terms_accepted,omitempty"`
+	}
+
+	// SupportedApp is an Android app the code of a one-tap or zero-tap button is handed to.
+	SupportedApp struct {
+		PackageName   string `json:"package_name"`
+		SignatureHash string `json:"signature_hash"`
+	}
+
+	Card struct {
+		Components []*Component `json:"components"`
+	}
+
+	CreateRequest struct {
+		Name                string                   `json:"name"`
+		Language            string                   `json:"language"`
+		Category            message.TemplateCategory `json:"category"`
+		AllowCategoryChange bool                     `json:"allow_category_change,omitempty"`
+		ParameterFormat     string                   `json:"parameter_format,omitempty"`
+		Components          []*Component             `json:"components"`
+	}
+
+	CreateResponse struct {
+		ID       string                   `json:"id"`
+		Status   string                   `json:"status"`
+		Category message.TemplateCategory `json:"category"`
+	}
+
+	// UpdateRequest edits an existing template. Only approved, rejected and paused
+	// templates can be edited.
+	UpdateRequest struct {
+		TemplateID string                   `json:"-"`
+		Category   message.TemplateCategory `json:"category,omitempty"`
+		Components []*Component             `json:"components,omitempty"`
+	}
+
+	// DeleteRequest deletes templates by name. Setting TemplateID (hsm_id) deletes only the
+	// template in that language.
+	DeleteRequest struct {
+		Name       string
+		TemplateID string
+	}
+
+	// ListRequest filters and pages the templates of the business account.
+	ListRequest struct {
+		Name     string
+		Status   string
+		Category message.TemplateCategory
+		Language string
+		Fields   []string
+		Limit    int
+		After    string
+		Before   string
+	}
+
+	ListResponse struct {
+		Data   []*Template `json:"data"`
+		Paging *Paging     `json:"paging,omitempty"`
+	}
+
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
+
+	SuccessResponse struct {
+		Success bool `json:"success"`
+	}
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Create(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return List(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) ListAll(ctx context.Context, req *ListRequest) ([]*Template, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListAll(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) Get(ctx context.Context, templateID string) (*Template, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Get(ctx, c.Sender, conf, templateID)
+}
+
+func (c *BaseClient) Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Update(ctx, c.Sender, conf, req)
+}
+
+func (c *BaseClient) Delete(ctx context.Context, req *DeleteRequest) (*SuccessResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Delete(ctx, c.Sender, conf, req)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	return Create(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return List(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) ListAll(ctx context.Context, req *ListRequest) ([]*Template, error) {
+	return ListAll(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) Get(ctx context.Context, templateID string) (*Template, error) {
+	return Get(ctx, c.Sender, c.Config, templateID)
+}
+
+func (c *Client) Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error) {
+	return Update(ctx, c.Sender, c.Config, req)
+}
+
+func (c *Client) Delete(ctx context.Context, req *DeleteRequest) (*SuccessResponse, error) {
+	return Delete(ctx, c.Sender, c.Config, req)
+}
+
+var (
+	ErrCreateTemplate = errors.New("failed to create template")
+	ErrGetTemplate    = errors.New("failed to get template")
+	ErrListTemplates  = errors.New("failed to list templates")
+	ErrUpdateTemplate = errors.New("failed to update template")
+	ErrDeleteTemplate = errors.New("failed to delete template")
+)
+
+func Create(ctx context.Context, sender Sender, conf *config.Config, req *CreateRequest) (*CreateResponse, error) {
+	request := &BaseRequest{
+		Method: http.MethodPost,
+		Type:   whttp.RequestTypeCreateTemplate,
+		Body:   req,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateTemplate, err)
+	}
+
+	return &CreateResponse{
+		ID:       response.ID,
+		Status:   response.Status,
+		Category: response.Category,
+	}, nil
+}
+
+func Get(ctx context.Context, sender Sender, conf *config.Config, templateID string) (*Template, error) {
+	request := &BaseRequest{
+		Method:     http.MethodGet,
+		Type:       whttp.RequestTypeGetTemplate,
+		TemplateID: templateID,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetTemplate, err)
+	}
+
+	return &response.Template, nil
+}
+
+func List(ctx context.Context, sender Sender, conf *config.Config, req *ListRequest) (*ListResponse, error) {
+	if req == nil {
+		req = &ListRequest{}
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListTemplates,
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListTemplates, err)
+	}
+
+	return &ListResponse{Data: response.Data, Paging: response.Paging}, nil
+}
+
+// ListAll follows the after cursor until every template matching req has been fetched.
+func ListAll(ctx context.Context, sender Sender, conf *config.Config, req *ListRequest) ([]*Template, error) {
+	list := ListerFunc(func(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+		return List(ctx, sender, conf, req)
+	})
+
+	templates, err := NewPager(list, req).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func Update(ctx context.Context, sender Sender, conf *config.Config, req *UpdateRequest) (*SuccessResponse, error) {
+	request := &BaseRequest{
+		Method:     http.MethodPost,
+		Type:       whttp.RequestTypeUpdateTemplate,
+		TemplateID: req.TemplateID,
+		Body:       req,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateTemplate, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+func Delete(ctx context.Context, sender Sender, conf *config.Config, req *DeleteRequest) (*SuccessResponse, error) {
+	queryParams := map[string]string{"name": req.Name}
+	if req.TemplateID != "" {
+		queryParams["hsm_id"] = req.TemplateID
+	}
+
+	request := &BaseRequest{
+		Method:      http.MethodDelete,
+		Type:        whttp.RequestTypeDeleteTemplate,
+		QueryParams: queryParams,
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDeleteTemplate, err)
+	}
+
+	return &SuccessResponse{Success: response.Success}, nil
+}
+
+func (req *ListRequest) queryParams() map[string]string {
+	params := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	set("name", req.Name)
+	set("status", req.Status)
+	set("category", string(req.Category))
+	set("language", req.Language)
+	set("fields", strings.Join(req.Fields, ","))
+	set("after", req.After)
+	set("before", req.Before)
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	return params
+}
+
+type (
+	// BaseRequest is a request to the template endpoints. Endpoint replaces the
+	// message_templates edge of the business account, for endpoints that are not scoped to
+	// it like the template library.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		TemplateID  string
+		Endpoint    string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response is the union of the bodies returned by the template endpoints. Raw is the
+	// body as received, for the responses the union does not model.
+	Response struct {
+		Template
+
+		Data    []*Template     `json:"data,omitempty"`
+		Paging  *Paging         `json:"paging,omitempty"`
+		Success bool            `json:"success,omitempty"`
+		Raw     json.RawMessage `json:"-"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+// Send sends req to /{template-id} when TemplateID is set, to /{endpoint} when Endpoint is
+// set and to /{waba-id}/message_templates otherwise.
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := []string{conf.APIVersion, conf.BusinessAccountID, Endpoint}
+	switch {
+	case req.TemplateID != "":
+		endpoints = []string{conf.APIVersion, req.TemplateID}
+	case req.Endpoint != "":
+		endpoints = []string{conf.APIVersion, req.Endpoint}
+	}
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decodeJSON := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	decoder := whttp.ResponseDecoderFunc(func(ctx context.Context, resp *http.Response) error {
+		if err := decodeJSON(ctx, resp); err != nil {
+			return err
+		}
+
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+		response.Raw = raw
+
+		return nil
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error)
+	List(ctx context.Context, req *ListRequest) (*ListResponse, error)
+	ListAll(ctx context.Context, req *ListRequest) ([]*Template, error)
+	Get(ctx context.Context, templateID string) (*Template, error)
+	Update(ctx context.Context, req *UpdateRequest) (*SuccessResponse, error)
+	Delete(ctx context.Context, req *DeleteRequest) (*SuccessResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
@@ -0,0 +1,158 @@
+package template_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	"github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/template"
+)
+
+func TestBaseClient_ListAll(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"":   `{"data":[{"id":"1","name":"a","language":"en","category":"UTILITY"}],"paging":{"cursors":{"before":"b0","after":"c1"},"next":"https://graph/next"}}`,
+		"c1": `{"data":[{"id":"2","name":"b","language":"en","category":"MARKETING"}],"paging":{"cursors":{"before":"c1","after":"c2"}}}`,
+	}
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/waba/message_templates" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if got := r.URL.Query().Get("status"); got != template.StatusApproved {
+			t.Errorf("status filter = %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("after")]))
+	})
+	client := template.NewBaseClient(whttp.NewAnySender(), reader)
+
+	templates, err := client.ListAll(context.Background(), &template.ListRequest{Status: template.StatusApproved})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, tpl := range templates {
+		ids = append(ids, tpl.ID)
+	}
+
+	if diff := gcmp.Diff([]string{"1", "2"}, ids); diff != "" {
+		t.Errorf("ids mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBaseClient_Create(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v20.0/waba/message_templates" {
+			http.NotFound(w, r)
+
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"99","status":"PENDING","category":"UTILITY"}`))
+	})
+	client := template.NewBaseClient(whttp.NewAnySender(), reader)
+
+	req := template.NewCreateRequest("order_update", "en_US", message.TemplateCategoryUtility,
+		template.HeaderText("Order {{1}}", "#123"),
+		template.Body("Hi {{1}}, your order ships {{2}}.", "Ann", "today"),
+		template.Footer("Reply STOP to opt out"),
+		template.Buttons(template.QuickReplyButton("Track"), template.URLButton("Open", "https://x.io/{{1}}", "https://x.io/1")),
+	)
+
+	resp, err := client.Create(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.ID != "99" || resp.Status != template.StatusPending {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	components, _ := body["components"].([]any)
+	if len(components) != 4 || body["category"] != "UTILITY" {
+		t.Errorf("unexpected request body: %v", body)
+	}
+}
+
+func TestBaseClient_TemplateLibrary(t *testing.T) {
+	t.Parallel()
+
+	var created map[string]any
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v20.0/message_template_library":
+			if r.URL.Query().Get("topic") != "ORDER_MANAGEMENT" || r.URL.Query().Get("search") != "shipped" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"7","name":"order_shipped","language":"en_US",
+"category":"UTILITY","topic":"ORDER_MANAGEMENT","usecase":"SHIPMENT_UPDATE",
+"body":"Your order {{1}} has shipped.","body_params":["#1234"],
+"buttons":[{"type":"URL","text":"Track","url":"https://example.com/{{1}}"}]}]}`))
+		case "/v20.0/waba/message_templates":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"99","status":"APPROVED","category":"UTILITY"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	client := template.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	library, err := client.ListAllLibrary(ctx, &template.LibraryListRequest{Topic: "ORDER_MANAGEMENT", Search: "shipped"})
+	if err != nil {
+		t.Fatalf("ListAllLibrary() error = %v", err)
+	}
+
+	if len(library) != 1 || library[0].Body != "Your order {{1}} has shipped." || len(library[0].Buttons) != 1 {
+		t.Fatalf("library = %+v", library)
+	}
+
+	req := library[0].Instantiate("acme_order_shipped", &template.LibraryButtonInput{
+		Type: template.LibraryButtonTypeURL,
+		URL:  &template.LibraryURLInput{BaseURL: "https://acme.com/track/{{1}}", URLSuffixExample: "https://acme.com/track/1234"},
+	})
+
+	resp, err := client.CreateFromLibrary(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateFromLibrary() error = %v", err)
+	}
+
+	if resp.ID != "99" || resp.Status != template.StatusApproved {
+		t.Errorf("response = %+v", resp)
+	}
+
+	want := map[string]any{
+		"name":                  "acme_order_shipped",
+		"language":              "en_US",
+		"category":              "UTILITY",
+		"library_template_name": "order_shipped",
+		"library_template_button_inputs": []any{map[string]any{
+			"type": "URL",
+			"url":  map[string]any{"base_url": "https://acme.com/track/{{1}}", "url_suffix_example": "https://acme.com/track/1234"},
+		}},
+	}
+	if diff := gcmp.Diff(want, created); diff != "" {
+		t.Errorf("create body mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package tracking
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalConversationPrefix starts the conversation IDs derived by LocalConversationID, they
+// never collide with the IDs assigned by WhatsApp.
+const LocalConversationPrefix = "local."
+
+// ConversationWindow is how long a conversation lasts after the message that opened it.
+const ConversationWindow = 24 * time.Hour
+
+// Label keys of the metrics recorded for tracked messages.
+const (
+	LabelConversationID = "conversation_id"
+	LabelPhoneNumberID  = "phone_number_id"
+	LabelDirection      = "direction"
+	LabelType           = "type"
+)
+
+// LocalConversationID derives the ID of the conversation opened at start between the phone
+// number and the contact. The same inputs always give the same ID, so the IDs stay stable
+// across processes and can be joined in BI systems like the IDs assigned by WhatsApp.
+func LocalConversationID(phoneNumberID, contact string, start time.Time) string {
+	sum := sha256.Sum256([]byte(phoneNumberID + "\x00" + contact + "\x00" + strconv.FormatInt(start.Unix(), 10)))
+
+	return LocalConversationPrefix + hex.EncodeToString(sum[:12])
+}
+
+// IsLocalConversationID reports whether id was derived by LocalConversationID.
+func IsLocalConversationID(id string) bool {
+	return strings.HasPrefix(id, LocalConversationPrefix)
+}
+
+// AssignConversationIDs sets the ConversationID of the records that have none. Records of
+// a phone number and contact are grouped in windows of ConversationWindow opened by the
+// first message after the previous window closed. A window takes the WhatsApp conversation
+// ID of one of its records, usually learned from a status webhook, and a LocalConversationID
+// when none has one. Records are sorted with SortRecords.
+func AssignConversationIDs(records []*Record) {
+	SortRecords(records)
+
+	type key struct{ phoneNumberID, contact string }
+	windows := make(map[key][][]*Record)
+	for _, record := range records {
+		k := key{record.PhoneNumberID, record.Contact}
+		contactWindows := windows[k]
+		last := len(contactWindows) - 1
+		if last < 0 || !record.Timestamp.Before(contactWindows[last][0].Timestamp.Add(ConversationWindow)) {
+			windows[k] = append(contactWindows, []*Record{record})
+
+			continue
+		}
+		contactWindows[last] = append(contactWindows[last], record)
+	}
+
+	for k, contactWindows := range windows {
+		for _, window := range contactWindows {
+			id := ""
+			for _, record := range window {
+				if record.ConversationID != "" && !IsLocalConversationID(record.ConversationID) {
+					id = record.ConversationID
+
+					break
+				}
+			}
+
+			if id == "" {
+				id = LocalConversationID(k.phoneNumberID, k.contact, window[0].Timestamp)
+			}
+
+			for _, record := range window {
+				if record.ConversationID == "" {
+					record.ConversationID = id
+				}
+			}
+		}
+	}
+}
+
+// Labels returns the metric labels of the record. They include the conversation ID so that
+// metrics can be joined with the conversation analytics of WhatsApp.
+func (r *Record) Labels() map[string]string {
+	return map[string]string{
+		LabelConversationID: r.ConversationID,
+		LabelPhoneNumberID:  r.PhoneNumberID,
+		LabelDirection:      string(r.Direction),
+		LabelType:           r.Type,
+	}
+}
@@ -0,0 +1,78 @@
+package tracking_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/tracking"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestAssignConversationIDs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	record := func(id, contact string, after time.Duration, conversationID string) *tracking.Record {
+		return &tracking.Record{
+			ID: id, PhoneNumberID: "phone", Contact: contact, Timestamp: start.Add(after), ConversationID: conversationID,
+		}
+	}
+
+	records := []*tracking.Record{
+		record("a1", "alice", 0, ""),
+		record("a2", "alice", time.Hour, "wa-conv-1"),
+		record("a3", "alice", 23*time.Hour, ""),
+		record("a4", "alice", 24*time.Hour, ""),
+		record("b1", "bob", time.Hour, ""),
+	}
+	tracking.AssignConversationIDs(records)
+
+	got := make(map[string]string, len(records))
+	for _, r := range records {
+		got[r.ID] = r.ConversationID
+	}
+
+	want := map[string]string{
+		"a1": "wa-conv-1",
+		"a2": "wa-conv-1",
+		"a3": "wa-conv-1",
+		"a4": tracking.LocalConversationID("phone", "alice", start.Add(24*time.Hour)),
+		"b1": tracking.LocalConversationID("phone", "bob", start.Add(time.Hour)),
+	}
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("conversation IDs mismatch (-want +got):\n%s", diff)
+	}
+
+	if !tracking.IsLocalConversationID(got["b1"]) || got["b1"] == got["a4"] {
+		t.Errorf("local IDs = %q, %q", got["a4"], got["b1"])
+	}
+
+	if labels := records[0].Labels(); labels[tracking.LabelConversationID] != "wa-conv-1" {
+		t.Errorf("Labels() = %v", labels)
+	}
+}
+
+func TestStatusTrackerConversationID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := tracking.NewMemoryStore()
+	_ = store.Save(ctx, &tracking.Record{ID: "wamid.1", Contact: "alice", ConversationID: "local.abc"})
+
+	tracker := tracking.NewStatusTracker(store)
+	err := tracker.Handle(ctx, nil, &message.Status{
+		ID: "wamid.1", StatusValue: "sent", Timestamp: 1700000000,
+		Conversation: &message.Conversation{ID: "wa-conv-1"},
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	record, _ := store.Get(ctx, "wamid.1")
+	if record.ConversationID != "wa-conv-1" || record.Status != "sent" {
+		t.Errorf("record = %+v", record)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	"github.com/piusalfred/whatsapp/pkg/types"
+)
+
+// sealedMetadataKey holds the sealed Metadata of records saved by EncryptedStore.
+const sealedMetadataKey = "$sealed"
+
+var _ Store = (*EncryptedStore)(nil)
+
+// EncryptedStore seals the Body and Metadata of records before they reach the wrapped
+// store, the fields queries filter on stay in clear. Each record is sealed with its ID as
+// associated data.
+type EncryptedStore struct {
+	store  Store
+	sealer *crypto.Sealer
+}
+
+func NewEncryptedStore(store Store, sealer *crypto.Sealer) *EncryptedStore {
+	return &EncryptedStore{store: store, sealer: sealer}
+}
+
+func (s *EncryptedStore) Save(ctx context.Context, record *Record) error {
+	sealed := *record
+
+	if record.Body != "" {
+		body, err := s.sealer.SealString(ctx, record.Body, record.ID)
+		if err != nil {
+			return fmt.Errorf("seal record %s: %w", record.ID, err)
+		}
+		sealed.Body = body
+	}
+
+	if len(record.Metadata) > 0 {
+		metadata, err := s.sealer.SealJSON(ctx, record.Metadata, record.ID)
+		if err != nil {
+			return fmt.Errorf("seal record %s: %w", record.ID, err)
+		}
+		sealed.Metadata = types.Metadata{sealedMetadataKey: metadata}
+	}
+
+	return s.store.Save(ctx, &sealed)
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, id string) (*Record, error) {
+	record, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.open(ctx, record)
+}
+
+func (s *EncryptedStore) UpdateStatus(ctx context.Context, id, status string, at time.Time) error {
+	return s.store.UpdateStatus(ctx, id, status, at)
+}
+
+func (s *EncryptedStore) List(ctx context.Context, query *Query) ([]*Record, error) {
+	records, err := s.store.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := make([]*Record, 0, len(records))
+	for _, record := range records {
+		record, err := s.open(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		opened = append(opened, record)
+	}
+
+	return opened, nil
+}
+
+func (s *EncryptedStore) open(ctx context.Context, sealed *Record) (*Record, error) {
+	record := *sealed
+	record.Metadata = maps.Clone(sealed.Metadata)
+
+	if record.Body != "" {
+		body, err := s.sealer.OpenString(ctx, record.Body, record.ID)
+		if err != nil {
+			return nil, fmt.Errorf("open record %s: %w", record.ID, err)
+		}
+		record.Body = body
+	}
+
+	if metadata, ok := record.Metadata[sealedMetadataKey].(string); ok {
+		record.Metadata = nil
+		if err := s.sealer.OpenJSON(ctx, metadata, record.ID, &record.Metadata); err != nil {
+			return nil, fmt.Errorf("open record %s: %w", record.ID, err)
+		}
+	}
+
+	return &record, nil
+}
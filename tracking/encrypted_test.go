@@ -0,0 +1,59 @@
+package tracking_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	"github.com/piusalfred/whatsapp/pkg/types"
+	"github.com/piusalfred/whatsapp/tracking"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	plain := tracking.NewMemoryStore()
+	sealer := crypto.NewSealer(crypto.NewStaticKeyProvider(&crypto.Key{ID: "k1", Secret: bytes.Repeat([]byte{7}, 32)}))
+	store := tracking.NewEncryptedStore(plain, sealer)
+
+	record := &tracking.Record{
+		ID:        "wamid.1",
+		Contact:   "255700",
+		Direction: tracking.DirectionInbound,
+		Body:      "my card number is 4242",
+		Timestamp: time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC),
+		Metadata:  types.Metadata{"order": "A-1"},
+	}
+
+	if err := store.Save(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := plain.Get(ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(stored.Body, "4242") || stored.Metadata["order"] != nil {
+		t.Errorf("record stored in clear: %+v", stored)
+	}
+
+	records, err := store.List(ctx, &tracking.Query{Contact: "255700"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("List() returned %d records", len(records))
+	}
+
+	if diff := gcmp.Diff(record, records[0]); diff != "" {
+		t.Errorf("record mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// StatusFailed is the status of outbound messages WhatsApp could not deliver.
+const StatusFailed = "failed"
+
+type (
+	// FailedDelivery describes an outbound message that reached a terminal failure. Record
+	// is the tracked message and is nil when the message is not in the store, the
+	// recipient is then all there is to route the fallback.
+	FailedDelivery struct {
+		MessageID     string
+		PhoneNumberID string
+		Recipient     string
+		Record        *Record
+		Errors        []*werrors.Error
+		CallbackData  string
+		FailedAt      time.Time
+	}
+
+	// FallbackSender delivers a failed message through another channel, such as an SMS
+	// provider for one-time passwords and alerts.
+	FallbackSender interface {
+		SendFallback(ctx context.Context, delivery *FailedDelivery) error
+	}
+
+	FallbackSenderFunc func(ctx context.Context, delivery *FailedDelivery) error
+
+	StatusTrackerOption func(*StatusTracker)
+)
+
+func (fn FallbackSenderFunc) SendFallback(ctx context.Context, delivery *FailedDelivery) error {
+	return fn(ctx, delivery)
+}
+
+// DefaultFallbackCodes are the error codes that make a failed message go to the fallback:
+// undeliverable messages, which includes recipients without a WhatsApp account.
+var DefaultFallbackCodes = []int{werrors.CodeMessageUndeliverable} //nolint:gochecknoglobals // defaults
+
+// StatusTracker updates the store with the delivery statuses received from webhooks, and
+// the conversation IDs they carry, and passes messages that failed with one of the fallback codes to the FallbackSender.
+// Messages already recorded as failed are not passed again, so redelivered webhooks do
+// not send duplicates.
+type StatusTracker struct {
+	store    Store
+	fallback FallbackSender
+	codes    []int
+	now      func() time.Time
+}
+
+// WithFallback sets the sender for failed messages. When codes are given they replace
+// DefaultFallbackCodes.
+func WithFallback(sender FallbackSender, codes ...int) StatusTrackerOption {
+	return func(t *StatusTracker) {
+		t.fallback = sender
+		if len(codes) > 0 {
+			t.codes = codes
+		}
+	}
+}
+
+// WithStatusClock sets the clock used when a status has no timestamp.
+func WithStatusClock(now func() time.Time) StatusTrackerOption {
+	return func(t *StatusTracker) {
+		t.now = now
+	}
+}
+
+func NewStatusTracker(store Store, options ...StatusTrackerOption) *StatusTracker {
+	t := &StatusTracker{
+		store: store,
+		codes: DefaultFallbackCodes,
+		now:   time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(t)
+		}
+	}
+
+	return t
+}
+
+// Handler returns the tracker as a message status change handler.
+func (t *StatusTracker) Handler() message.StatusChangeHandler {
+	return message.OnMessageStatusChangeHook(t.Handle)
+}
+
+func (t *StatusTracker) Handle(ctx context.Context, nctx *message.NotificationContext, status *message.Status) error {
+	at := t.now()
+	if status.Timestamp > 0 {
+		at = time.Unix(status.Timestamp, 0)
+	}
+
+	record, err := t.store.Get(ctx, status.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("track status of %s: %w", status.ID, err)
+	}
+
+	alreadyFailed := false
+	if record != nil {
+		alreadyFailed = record.Status == StatusFailed
+		if err := t.store.UpdateStatus(ctx, status.ID, status.StatusValue, at); err != nil {
+			return fmt.Errorf("track status of %s: %w", status.ID, err)
+		}
+
+		if err := t.recordConversation(ctx, record, status); err != nil {
+			return fmt.Errorf("track status of %s: %w", status.ID, err)
+		}
+	}
+
+	if status.StatusValue != StatusFailed || alreadyFailed || t.fallback == nil || !t.isTerminal(status.Errors) {
+		return nil
+	}
+
+	delivery := &FailedDelivery{
+		MessageID:    status.ID,
+		Recipient:    status.RecipientID,
+		Record:       record,
+		Errors:       status.Errors,
+		CallbackData: status.BizOpaqueCallbackData,
+		FailedAt:     at,
+	}
+	if nctx != nil && nctx.Metadata != nil {
+		delivery.PhoneNumberID = nctx.Metadata.PhoneNumberID
+	}
+
+	if err := t.fallback.SendFallback(ctx, delivery); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrFallback, status.ID, err)
+	}
+
+	return nil
+}
+
+// recordConversation saves the WhatsApp conversation ID of the status on the record, it
+// replaces the ID derived by AssignConversationIDs.
+func (t *StatusTracker) recordConversation(ctx context.Context, record *Record, status *message.Status) error {
+	id := StatusConversationID(status)
+	if id == "" || (record.ConversationID != "" && !IsLocalConversationID(record.ConversationID)) {
+		return nil
+	}
+
+	updated, err := t.store.Get(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	updated.ConversationID = id
+	record.ConversationID = id
+
+	return t.store.Save(ctx, updated)
+}
+
+// StatusConversationID returns the WhatsApp conversation ID of a status, it is empty for
+// statuses without conversation information such as read statuses.
+func StatusConversationID(status *message.Status) string {
+	if status == nil || status.Conversation == nil {
+		return ""
+	}
+
+	return status.Conversation.ID
+}
+
+func (t *StatusTracker) isTerminal(errs []*werrors.Error) bool {
+	for _, e := range errs {
+		if e != nil && slices.Contains(t.codes, e.Code) {
+			return true
+		}
+	}
+
+	return false
+}
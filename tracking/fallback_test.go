@@ -0,0 +1,82 @@
+package tracking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/tracking"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestStatusTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := tracking.NewMemoryStore()
+	_ = store.Save(ctx, &tracking.Record{
+		ID: "wamid.otp", Contact: "255700000001", Direction: tracking.DirectionOutbound,
+		Body: "Your code is 123456", Status: "sent",
+	})
+
+	var deliveries []*tracking.FailedDelivery
+	tracker := tracking.NewStatusTracker(store, tracking.WithFallback(tracking.FallbackSenderFunc(
+		func(_ context.Context, delivery *tracking.FailedDelivery) error {
+			deliveries = append(deliveries, delivery)
+
+			return nil
+		})))
+	handler := tracker.Handler()
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "phone"}}
+	undeliverable := []*werrors.Error{{Code: werrors.CodeMessageUndeliverable, Title: "Message undeliverable"}}
+	statuses := []*message.Status{
+		{ID: "wamid.otp", RecipientID: "255700000001", StatusValue: "failed", Timestamp: 1700000000, Errors: undeliverable},
+		// redelivered webhook.
+		{ID: "wamid.otp", RecipientID: "255700000001", StatusValue: "failed", Timestamp: 1700000000, Errors: undeliverable},
+		{ID: "wamid.other", RecipientID: "255700000002", StatusValue: "failed", Errors: []*werrors.Error{{Code: 131047}}},
+		{ID: "wamid.untracked", RecipientID: "255700000003", StatusValue: "failed", Errors: undeliverable},
+	}
+	for _, status := range statuses {
+		if err := handler.Handle(ctx, nctx, status); err != nil {
+			t.Fatalf("Handle(%s) error = %v", status.ID, err)
+		}
+	}
+
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d fallback deliveries, want 2", len(deliveries))
+	}
+
+	first := deliveries[0]
+	if first.Record == nil || first.Record.Body != "Your code is 123456" || first.PhoneNumberID != "phone" ||
+		!first.FailedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("first delivery = %+v", first)
+	}
+
+	if deliveries[1].Record != nil || deliveries[1].Recipient != "255700000003" {
+		t.Errorf("untracked delivery = %+v", deliveries[1])
+	}
+
+	record, _ := store.Get(ctx, "wamid.otp")
+	if record.Status != tracking.StatusFailed {
+		t.Errorf("status = %q, want failed", record.Status)
+	}
+}
+
+func TestStatusTrackerFallbackError(t *testing.T) {
+	t.Parallel()
+
+	tracker := tracking.NewStatusTracker(tracking.NewMemoryStore(), tracking.WithFallback(
+		tracking.FallbackSenderFunc(func(context.Context, *tracking.FailedDelivery) error {
+			return errors.New("sms provider down")
+		}), 131047))
+
+	err := tracker.Handle(context.Background(), nil, &message.Status{
+		ID: "wamid.1", StatusValue: "failed", Errors: []*werrors.Error{{Code: 131047}},
+	})
+	if !errors.Is(err, tracking.ErrFallback) {
+		t.Errorf("Handle() error = %v, want ErrFallback", err)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+// MarkAllRead marks the unread inbound records matched by query as read, for inbox "mark
+// all as read" operations. Marking a message as read also marks the earlier messages of the
+// conversation, so only the most recent unread message of each contact is sent to the API
+// and the store is then updated for all unread records of the contacts that succeeded.
+//
+// It returns the number of records updated in the store.
+func MarkAllRead(ctx context.Context, store Store, updater message.StatusUpdater, query *Query,
+	options *message.ReadReceiptsOptions,
+) (int, error) {
+	q := Query{}
+	if query != nil {
+		q = *query
+	}
+	q.Unread = true
+	q.Limit = 0
+
+	records, err := store.List(ctx, &q)
+	if err != nil {
+		return 0, fmt.Errorf("mark all read: list unread: %w", err)
+	}
+
+	byContact := make(map[string][]*Record)
+	for _, record := range records {
+		byContact[record.Contact] = append(byContact[record.Contact], record)
+	}
+
+	// records are sorted oldest first, so the last record of a contact is the latest.
+	latest := make([]string, 0, len(byContact))
+	contactOf := make(map[string]string, len(byContact))
+	for _, record := range records {
+		contactRecords := byContact[record.Contact]
+		if contactRecords[len(contactRecords)-1] == record {
+			latest = append(latest, record.ID)
+			contactOf[record.ID] = record.Contact
+		}
+	}
+
+	result, sendErr := message.SendReadReceipts(ctx, updater, latest, options)
+
+	now := time.Now()
+	updated := 0
+	var errs []error
+	for _, id := range result.Read {
+		for _, record := range byContact[contactOf[id]] {
+			if err := store.UpdateStatus(ctx, record.ID, StatusRead, now); err != nil {
+				errs = append(errs, fmt.Errorf("update %s: %w", record.ID, err))
+
+				continue
+			}
+			updated++
+		}
+	}
+
+	if sendErr != nil {
+		errs = append(errs, sendErr)
+	}
+
+	if len(errs) > 0 {
+		return updated, fmt.Errorf("mark all read: %w", errors.Join(errs...))
+	}
+
+	return updated, nil
+}
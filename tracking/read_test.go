@@ -0,0 +1,67 @@
+package tracking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/tracking"
+)
+
+func TestMarkAllRead(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := tracking.NewMemoryStore()
+	base := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+
+	records := []*tracking.Record{
+		{ID: "a1", Contact: "alice", Direction: tracking.DirectionInbound, Timestamp: base},
+		{ID: "a2", Contact: "alice", Direction: tracking.DirectionInbound, Timestamp: base.Add(time.Minute)},
+		{ID: "b1", Contact: "bob", Direction: tracking.DirectionInbound, Timestamp: base.Add(2 * time.Minute)},
+		{ID: "c1", Contact: "carol", Direction: tracking.DirectionInbound, Timestamp: base.Add(3 * time.Minute)},
+		{ID: "o1", Contact: "alice", Direction: tracking.DirectionOutbound, Timestamp: base.Add(4 * time.Minute)},
+		{ID: "r1", Contact: "dave", Direction: tracking.DirectionInbound, Status: tracking.StatusRead, Timestamp: base},
+	}
+	for _, record := range records {
+		if err := store.Save(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sent []string
+	updater := message.UpdateStatusFunc(func(_ context.Context, request *message.StatusUpdateRequest) (*message.StatusUpdateResponse, error) {
+		sent = append(sent, request.MessageID)
+		if request.MessageID == "c1" {
+			return nil, errors.New("rate limited")
+		}
+
+		return &message.StatusUpdateResponse{Success: true}, nil
+	})
+
+	updated, err := tracking.MarkAllRead(ctx, store, updater, nil, &message.ReadReceiptsOptions{Interval: time.Millisecond})
+	if !errors.Is(err, message.ErrReadReceiptsFailed) {
+		t.Errorf("expected ErrReadReceiptsFailed, got %v", err)
+	}
+
+	if diff := gcmp.Diff([]string{"a2", "b1", "c1"}, sent); diff != "" {
+		t.Errorf("sent receipts mismatch (-want +got):\n%s", diff)
+	}
+
+	if updated != 3 {
+		t.Errorf("updated = %d, want 3", updated)
+	}
+
+	unread, err := store.List(ctx, &tracking.Query{Unread: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(unread) != 1 || unread[0].ID != "c1" {
+		t.Errorf("unexpected unread records: %v", unread)
+	}
+}
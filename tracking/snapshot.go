@@ -0,0 +1,242 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Roles of the messages in a Snapshot, named after the chat roles of LLM APIs.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// customerServiceWindow is how long after the last inbound message the business can send
+// free-form messages.
+const customerServiceWindow = 24 * time.Hour
+
+type (
+	// SnapshotOptions controls what goes into a Snapshot. Zero values use the defaults of
+	// BuildSnapshot.
+	SnapshotOptions struct {
+		// MaxMessages is the number of most recent messages considered.
+		MaxMessages int
+
+		// MaxTokens is the budget of the snapshot as counted by CountTokens. The oldest
+		// messages are dropped until the snapshot fits.
+		MaxTokens int
+
+		// MaxBodyLength truncates long message bodies to this many characters.
+		MaxBodyLength int
+
+		// Since drops the messages sent before it.
+		Since time.Time
+
+		// MetadataKeys lists the record metadata copied to the snapshot, none by default.
+		MetadataKeys []string
+
+		// CountTokens estimates the tokens of a text, EstimateTokens by default.
+		CountTokens func(text string) int
+
+		// Now is used to tell whether the customer service window is open.
+		Now time.Time
+	}
+
+	// Snapshot is a compact view of the recent conversation with a contact, meant to be
+	// serialized into an LLM prompt or served as an MCP resource. Messages are ordered
+	// oldest first.
+	Snapshot struct {
+		Contact         string             `json:"contact"`
+		PhoneNumberID   string             `json:"phone_number_id,omitempty"`
+		LastInboundAt   time.Time          `json:"last_inbound_at"`
+		WindowOpen      bool               `json:"window_open"`
+		Messages        []*SnapshotMessage `json:"messages"`
+		Omitted         int                `json:"omitted,omitempty"`
+		EstimatedTokens int                `json:"estimated_tokens"`
+	}
+
+	SnapshotMessage struct {
+		ID        string         `json:"id"`
+		Role      string         `json:"role"`
+		Type      string         `json:"type,omitempty"`
+		Text      string         `json:"text,omitempty"`
+		Status    string         `json:"status,omitempty"`
+		Timestamp time.Time      `json:"timestamp"`
+		Metadata  map[string]any `json:"metadata,omitempty"`
+	}
+)
+
+// EstimateTokens approximates the number of tokens of text at four characters per token,
+// close enough for budgeting English and most Latin script text.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4 //nolint:mnd // characters per token
+}
+
+// BuildSnapshot assembles the Snapshot of contact from store. By default it considers the
+// last 20 messages, keeps them within 2000 tokens and truncates bodies longer than 500
+// characters.
+func BuildSnapshot(ctx context.Context, store Store, contact string, options *SnapshotOptions) (*Snapshot, error) {
+	opts := SnapshotOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = 20
+	}
+
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = 2000
+	}
+
+	if opts.MaxBodyLength <= 0 {
+		opts.MaxBodyLength = 500
+	}
+
+	if opts.CountTokens == nil {
+		opts.CountTokens = EstimateTokens
+	}
+
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	records, err := store.List(ctx, &Query{Contact: contact, Since: opts.Since, Limit: opts.MaxMessages})
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot of %s: %w", contact, err)
+	}
+
+	snapshot := &Snapshot{Contact: contact, Messages: []*SnapshotMessage{}}
+	for _, record := range records {
+		if snapshot.PhoneNumberID == "" {
+			snapshot.PhoneNumberID = record.PhoneNumberID
+		}
+
+		if record.Direction == DirectionInbound && record.Timestamp.After(snapshot.LastInboundAt) {
+			snapshot.LastInboundAt = record.Timestamp
+		}
+	}
+
+	snapshot.WindowOpen = !snapshot.LastInboundAt.IsZero() &&
+		opts.Now.Sub(snapshot.LastInboundAt) < customerServiceWindow
+
+	// walk from the newest message so that the budget drops the oldest ones.
+	for i := len(records) - 1; i >= 0; i-- {
+		msg := snapshotMessage(records[i], &opts)
+		tokens := opts.CountTokens(msg.String())
+		if snapshot.EstimatedTokens+tokens > opts.MaxTokens {
+			snapshot.Omitted = i + 1
+
+			break
+		}
+
+		snapshot.EstimatedTokens += tokens
+		snapshot.Messages = append(snapshot.Messages, msg)
+	}
+
+	slices.Reverse(snapshot.Messages)
+
+	return snapshot, nil
+}
+
+func snapshotMessage(record *Record, opts *SnapshotOptions) *SnapshotMessage {
+	role := RoleUser
+	if record.Direction == DirectionOutbound {
+		role = RoleAssistant
+	}
+
+	text := record.Body
+	if utf8.RuneCountInString(text) > opts.MaxBodyLength {
+		text = string([]rune(text)[:opts.MaxBodyLength]) + "…"
+	}
+
+	msg := &SnapshotMessage{
+		ID:        record.ID,
+		Role:      role,
+		Type:      record.Type,
+		Text:      text,
+		Status:    record.Status,
+		Timestamp: record.Timestamp,
+	}
+
+	for _, key := range opts.MetadataKeys {
+		if value, ok := record.Metadata[key]; ok {
+			if msg.Metadata == nil {
+				msg.Metadata = make(map[string]any, len(opts.MetadataKeys))
+			}
+			msg.Metadata[key] = value
+		}
+	}
+
+	return msg
+}
+
+// String formats the message as a single transcript line.
+func (m *SnapshotMessage) String() string {
+	var b strings.Builder
+	b.WriteString("[" + m.Timestamp.UTC().Format(time.RFC3339) + "] " + m.Role)
+
+	if m.Type != "" && m.Type != "text" {
+		b.WriteString(" (" + m.Type + ")")
+	}
+
+	b.WriteString(": " + m.Text)
+
+	if m.Status != "" {
+		b.WriteString(" [" + m.Status + "]")
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(m.Metadata)) {
+		fmt.Fprintf(&b, " %s=%v", key, m.Metadata[key])
+	}
+
+	return b.String()
+}
+
+// Transcript formats the snapshot as plain text, one message per line, for prompts that do
+// not take structured input.
+func (s *Snapshot) Transcript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conversation with %s", s.Contact)
+
+	if s.Omitted > 0 {
+		fmt.Fprintf(&b, " (%d earlier messages omitted)", s.Omitted)
+	}
+
+	if !s.WindowOpen {
+		b.WriteString(" (customer service window closed, only templates can be sent)")
+	}
+
+	b.WriteString("\n")
+
+	for _, msg := range s.Messages {
+		b.WriteString(msg.String())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
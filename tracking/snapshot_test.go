@@ -0,0 +1,62 @@
+package tracking_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/pkg/types"
+	"github.com/piusalfred/whatsapp/tracking"
+)
+
+func TestBuildSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := tracking.NewMemoryStore()
+	base := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+
+	records := []*tracking.Record{
+		{ID: "m1", Contact: "alice", Direction: tracking.DirectionInbound, Type: "text", Body: strings.Repeat("a", 200), Timestamp: base},
+		{ID: "m2", Contact: "alice", Direction: tracking.DirectionOutbound, Type: "text", Body: "How can I help?", Status: "read", Timestamp: base.Add(time.Minute)},
+		{ID: "m3", Contact: "alice", Direction: tracking.DirectionInbound, Type: "image", Body: "receipt", Timestamp: base.Add(2 * time.Minute), Metadata: types.Metadata{"order": "42", "internal": "x"}},
+		{ID: "b1", Contact: "bob", Direction: tracking.DirectionInbound, Body: "hi", Timestamp: base},
+	}
+	for _, record := range records {
+		if err := store.Save(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot, err := tracking.BuildSnapshot(ctx, store, "alice", &tracking.SnapshotOptions{
+		MaxTokens:    40,
+		MetadataKeys: []string{"order"},
+		Now:          base.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*tracking.SnapshotMessage{
+		{ID: "m2", Role: tracking.RoleAssistant, Type: "text", Text: "How can I help?", Status: "read", Timestamp: base.Add(time.Minute)},
+		{ID: "m3", Role: tracking.RoleUser, Type: "image", Text: "receipt", Timestamp: base.Add(2 * time.Minute), Metadata: map[string]any{"order": "42"}},
+	}
+	if diff := gcmp.Diff(want, snapshot.Messages); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+
+	if snapshot.Omitted != 1 || !snapshot.WindowOpen || !snapshot.LastInboundAt.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("unexpected snapshot: omitted=%d window_open=%t last_inbound_at=%s",
+			snapshot.Omitted, snapshot.WindowOpen, snapshot.LastInboundAt)
+	}
+
+	wantTranscript := "Conversation with alice (1 earlier messages omitted)\n" +
+		"[2024-05-01T08:01:00Z] assistant: How can I help? [read]\n" +
+		"[2024-05-01T08:02:00Z] user (image): receipt order=42\n"
+	if got := snapshot.Transcript(); got != wantTranscript {
+		t.Errorf("Transcript() = %q, want %q", got, wantTranscript)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package tracking keeps a record of the messages exchanged with customers.
+//
+// A Record is stored for every inbound and outbound message the application learns about,
+// whether from webhooks, history sync or the send APIs, and is updated as delivery
+// statuses arrive. Store is the storage contract, MemoryStore is an in-process
+// implementation suitable for tests and small deployments.
+package tracking
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/types"
+)
+
+type Direction string
+
+const (
+	DirectionInbound  Direction = "inbound"
+	DirectionOutbound Direction = "outbound"
+)
+
+// StatusRead is the status of inbound messages the business has marked as read.
+const StatusRead = "read"
+
+// Source tells where a Record was learned from.
+type Source string
+
+const (
+	SourceWebhook Source = "webhook"
+	SourceHistory Source = "history"
+	SourceAPI     Source = "api"
+)
+
+type (
+	// Record is a single message exchanged with a customer. ID is the WhatsApp message ID.
+	Record struct {
+		ID              string         `json:"id"`
+		PhoneNumberID   string         `json:"phone_number_id,omitempty"`
+		Contact         string         `json:"contact"`
+		Direction       Direction      `json:"direction"`
+		Type            string         `json:"type,omitempty"`
+		Body            string         `json:"body,omitempty"`
+		Timestamp       time.Time      `json:"timestamp"`
+		Status          string         `json:"status,omitempty"`
+		StatusUpdatedAt time.Time      `json:"status_updated_at"`
+		ConversationID  string         `json:"conversation_id,omitempty"`
+		Source          Source         `json:"source,omitempty"`
+		Metadata        types.Metadata `json:"metadata,omitempty"`
+	}
+
+	// Query filters records. Zero values are ignored. Results are ordered by Timestamp,
+	// oldest first, and Limit keeps the most recent ones. Unread matches inbound records
+	// whose status is not StatusRead.
+	Query struct {
+		Contact   string
+		Direction Direction
+		Since     time.Time
+		Until     time.Time
+		Limit     int
+		Unread    bool
+	}
+
+	Store interface {
+		// Save inserts the record or replaces the one with the same ID.
+		Save(ctx context.Context, record *Record) error
+		Get(ctx context.Context, id string) (*Record, error)
+		UpdateStatus(ctx context.Context, id, status string, at time.Time) error
+		List(ctx context.Context, query *Query) ([]*Record, error)
+	}
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps records in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]*Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, record *Record) error {
+	if record == nil || record.ID == "" {
+		return ErrInvalidRecord
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *record
+	s.records[record.ID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r := *record
+
+	return &r, nil
+}
+
+// UpdateStatus sets the status of a record. Updates older than the current status are
+// ignored since webhooks are not delivered in order.
+func (s *MemoryStore) UpdateStatus(_ context.Context, id, status string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if at.Before(record.StatusUpdatedAt) {
+		return nil
+	}
+	record.Status = status
+	record.StatusUpdatedAt = at
+
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, query *Query) ([]*Record, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	s.mu.RLock()
+	result := make([]*Record, 0, len(s.records))
+	for _, record := range s.records {
+		if query.matches(record) {
+			r := *record
+			result = append(result, &r)
+		}
+	}
+	s.mu.RUnlock()
+
+	SortRecords(result)
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+
+	return result, nil
+}
+
+func (q *Query) matches(record *Record) bool {
+	if q.Contact != "" && record.Contact != q.Contact {
+		return false
+	}
+
+	if q.Direction != "" && record.Direction != q.Direction {
+		return false
+	}
+
+	if q.Unread && (record.Direction != DirectionInbound || record.Status == StatusRead) {
+		return false
+	}
+
+	if !q.Since.IsZero() && record.Timestamp.Before(q.Since) {
+		return false
+	}
+
+	return q.Until.IsZero() || record.Timestamp.Before(q.Until)
+}
+
+// SortRecords orders records by timestamp, oldest first, using the ID to break ties.
+func SortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].ID < records[j].ID
+		}
+
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+}
+
+// trackingError is a custom error type for tracking errors.
+type trackingError string
+
+func (e trackingError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNotFound      = trackingError("record not found")
+	ErrInvalidRecord = trackingError("record must have an id")
+	ErrFallback      = trackingError("fallback delivery failed")
+)
@@ -0,0 +1,250 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package translate lets handlers work in the language of the business while customers
+// write in theirs.
+//
+// A Translation detects the language of inbound text messages and hands the handlers the
+// text translated to the business language. The language of every contact is remembered,
+// and the sender middleware translates the text replies sent to them back:
+//
+//	translation := translate.New(provider, "en")
+//	handlers.TextMessage = translation.TextHandler(handlers.TextMessage)
+//	client, _ := wmessage.NewBaseClient(sender, reader, translation.Middleware())
+//
+// Translator is the seam to a translation provider and Nop is the default that leaves
+// text as it is. An adapter for a LibreTranslate server is in the extras/translate module.
+package translate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type (
+	// Translator detects the language of text and translates it. Languages are ISO 639-1
+	// codes such as "en" or "sw".
+	Translator interface {
+		// Detect returns the language of text, or an empty string when it is not known.
+		Detect(ctx context.Context, text string) (string, error)
+		Translate(ctx context.Context, text, from, to string) (string, error)
+	}
+
+	// Nop is a Translator that detects no language and returns text unchanged.
+	Nop struct{}
+
+	// Inbound is the translation of an inbound text message, available to handlers through
+	// InboundFromContext. Text is in the business language.
+	Inbound struct {
+		Language string
+		Original string
+		Text     string
+	}
+
+	Option func(*Translation)
+
+	// Translation translates inbound text messages to the business language and outbound
+	// text replies to the language of the contact. A Translation is safe for concurrent use.
+	Translation struct {
+		translator Translator
+		language   string
+		onError    func(ctx context.Context, err error)
+
+		mu        sync.RWMutex
+		languages map[string]string
+	}
+
+	inboundContextKey struct{}
+)
+
+var _ Translator = Nop{}
+
+func (Nop) Detect(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (Nop) Translate(_ context.Context, text, _, _ string) (string, error) {
+	return text, nil
+}
+
+// WithErrorHandler sets the function called when a translation fails. The text is then
+// passed on untranslated, a failed translation never fails a handler or a send.
+func WithErrorHandler(fn func(ctx context.Context, err error)) Option {
+	return func(t *Translation) {
+		t.onError = fn
+	}
+}
+
+// New returns a Translation to the business language with translator, or Nop when it is nil.
+func New(translator Translator, language string, options ...Option) *Translation {
+	if translator == nil {
+		translator = Nop{}
+	}
+
+	t := &Translation{
+		translator: translator,
+		language:   language,
+		languages:  make(map[string]string),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(t)
+		}
+	}
+
+	return t
+}
+
+// InboundFromContext returns the translation of the inbound message being handled.
+func InboundFromContext(ctx context.Context) (*Inbound, bool) {
+	inbound, ok := ctx.Value(inboundContextKey{}).(*Inbound)
+
+	return inbound, ok
+}
+
+// Language returns the last language detected for contact.
+func (t *Translation) Language(contact string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	language, ok := t.languages[contact]
+
+	return language, ok
+}
+
+// SetLanguage sets the language of contact, for languages known from elsewhere such as a
+// customer profile.
+func (t *Translation) SetLanguage(contact, language string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.languages[contact] = language
+}
+
+// Inbound detects the language of text sent by contact, remembers it and translates text
+// to the business language.
+func (t *Translation) Inbound(ctx context.Context, contact, text string) *Inbound {
+	inbound := &Inbound{Original: text, Text: text}
+	if text == "" {
+		return inbound
+	}
+
+	language, err := t.translator.Detect(ctx, text)
+	if err != nil {
+		t.fail(ctx, fmt.Errorf("%w: %w", ErrDetect, err))
+
+		return inbound
+	}
+
+	if language == "" {
+		return inbound
+	}
+
+	inbound.Language = language
+	t.SetLanguage(contact, language)
+
+	if language == t.language {
+		return inbound
+	}
+
+	translated, err := t.translator.Translate(ctx, text, language, t.language)
+	if err != nil {
+		t.fail(ctx, fmt.Errorf("%w: %w", ErrTranslate, err))
+
+		return inbound
+	}
+	inbound.Text = translated
+
+	return inbound
+}
+
+// TextHandler translates the text messages handled by next to the business language. The
+// original text and its language are available with InboundFromContext.
+func (t *Translation) TextHandler(next message.TextMessageHandler) message.TextMessageHandler {
+	if next == nil {
+		return nil
+	}
+
+	return message.OnTextMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		mctx *message.Info, text *message.Text,
+	) error {
+		inbound := t.Inbound(ctx, mctx.From, text.Body)
+		ctx = context.WithValue(ctx, inboundContextKey{}, inbound)
+
+		return next.Handle(ctx, nctx, mctx, &message.Text{Body: inbound.Text})
+	})
+}
+
+// Middleware translates the text messages sent to contacts whose language is known and is
+// not the business language. The message of the request is copied, not modified.
+func (t *Translation) Middleware() wmessage.SenderMiddleware {
+	return func(next wmessage.SenderFunc) wmessage.SenderFunc {
+		return func(ctx context.Context, conf *config.Config, request *wmessage.BaseRequest) (*wmessage.Response, error) {
+			msg := request.Message
+			if request.Type != whttp.RequestTypeSendMessage || msg == nil || msg.Text == nil ||
+				msg.Text.Body == "" {
+				return next(ctx, conf, request)
+			}
+
+			language, ok := t.Language(msg.To)
+			if !ok || language == t.language {
+				return next(ctx, conf, request)
+			}
+
+			body, err := t.translator.Translate(ctx, msg.Text.Body, t.language, language)
+			if err != nil {
+				t.fail(ctx, fmt.Errorf("%w: %w", ErrTranslate, err))
+
+				return next(ctx, conf, request)
+			}
+
+			text := *msg.Text
+			text.Body = body
+			translated := *msg
+			translated.Text = &text
+			req := *request
+			req.Message = &translated
+
+			return next(ctx, conf, &req)
+		}
+	}
+}
+
+func (t *Translation) fail(ctx context.Context, err error) {
+	if t.onError != nil {
+		t.onError(ctx, err)
+	}
+}
+
+type translateError string
+
+func (e translateError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrDetect    = translateError("detect language failed")
+	ErrTranslate = translateError("translate text failed")
+	ErrProvider  = translateError("translation provider error")
+)
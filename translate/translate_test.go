@@ -0,0 +1,88 @@
+package translate_test
+
+import (
+	"context"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/translate"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type dictionary map[string]string
+
+func (d dictionary) Detect(_ context.Context, text string) (string, error) {
+	if text == "habari" || text == "karibu" {
+		return "sw", nil
+	}
+
+	return "en", nil
+}
+
+func (d dictionary) Translate(_ context.Context, text, from, to string) (string, error) {
+	return d[from+":"+to+":"+text], nil
+}
+
+func TestTranslation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	translation := translate.New(dictionary{
+		"sw:en:habari":  "hello",
+		"en:sw:welcome": "karibu",
+	}, "en")
+
+	var handled []string
+	handler := translation.TextHandler(message.OnTextMessageHook(func(ctx context.Context,
+		_ *message.NotificationContext, _ *message.Info, text *message.Text,
+	) error {
+		inbound, ok := translate.InboundFromContext(ctx)
+		if !ok {
+			t.Fatal("inbound translation missing from context")
+		}
+		handled = append(handled, inbound.Language+":"+inbound.Original+":"+text.Body)
+
+		return nil
+	}))
+
+	for _, from := range []string{"255700000001", "255700000002"} {
+		body := map[string]string{"255700000001": "habari", "255700000002": "thanks"}[from]
+		if err := handler.Handle(ctx, &message.NotificationContext{}, &message.Info{From: from},
+			&message.Text{Body: body}); err != nil {
+			t.Fatalf("handle: %v", err)
+		}
+	}
+
+	if diff := gcmp.Diff([]string{"sw:habari:hello", "en:thanks:thanks"}, handled); diff != "" {
+		t.Fatalf("handled mismatch (-want +got):\n%s", diff)
+	}
+
+	var sent []string
+	send := translation.Middleware()(func(_ context.Context, _ *config.Config,
+		request *wmessage.BaseRequest,
+	) (*wmessage.Response, error) {
+		sent = append(sent, request.Message.Text.Body)
+
+		return &wmessage.Response{}, nil
+	})
+
+	for _, to := range []string{"255700000001", "255700000002", "255700000003"} {
+		msg := &wmessage.Message{To: to, Text: &wmessage.Text{Body: "welcome"}}
+		request := &wmessage.BaseRequest{Type: whttp.RequestTypeSendMessage, Message: msg}
+		if _, err := send(ctx, &config.Config{}, request); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+
+		if msg.Text.Body != "welcome" {
+			t.Fatalf("message of the caller modified: %q", msg.Text.Body)
+		}
+	}
+
+	if diff := gcmp.Diff([]string{"karibu", "welcome", "welcome"}, sent); diff != "" {
+		t.Fatalf("sent mismatch (-want +got):\n%s", diff)
+	}
+}
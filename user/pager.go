@@ -0,0 +1,65 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package user
+
+import (
+	"context"
+	"iter"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// BlockedUsersPager walks the block list one page at a time, following the after cursor
+// of every page until the last one.
+//
+//	pager := user.NewBlockedUsersPager(client, &user.ListBlockedRequest{Limit: 100})
+//	for blocked, err := range pager.Users(ctx) {
+//		...
+//	}
+type BlockedUsersPager struct {
+	*whttp.Pager[*BlockedUser]
+}
+
+// NewBlockedUsersPager creates a pager over the block list. The Limit of req sets the page
+// size and its After cursor, when set, resumes a previous walk from that page.
+func NewBlockedUsersPager(service Service, req *ListBlockedRequest) *BlockedUsersPager {
+	var limit int
+	var after string
+	if req != nil {
+		limit, after = req.Limit, req.After
+	}
+
+	fetch := func(ctx context.Context, after string) (*whttp.Page[*BlockedUser], error) {
+		response, err := service.ListBlocked(ctx, &ListBlockedRequest{Limit: limit, After: after})
+		if err != nil {
+			return nil, err
+		}
+
+		return &whttp.Page[*BlockedUser]{Data: response.Data, Paging: response.Paging, Summary: response.Summary}, nil
+	}
+
+	return &BlockedUsersPager{Pager: whttp.NewPager(fetch, after)}
+}
+
+// Users iterates over the remaining blocked users, fetching pages as needed. Iteration
+// stops after yielding an error.
+func (p *BlockedUsersPager) Users(ctx context.Context) iter.Seq2[*BlockedUser, error] {
+	return p.Items(ctx)
+}
@@ -0,0 +1,293 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSyncPageSize = 100
+	DefaultSyncWorkers  = 4
+	DefaultSyncSpacing  = 200 * time.Millisecond
+)
+
+type (
+	// SyncSink receives the blocked users one page at a time. Pages are written
+	// concurrently and may arrive out of order, so implementations must be safe for
+	// concurrent use.
+	SyncSink interface {
+		WriteBlockedUsers(ctx context.Context, users []*BlockedUser) error
+	}
+
+	SyncSinkFunc func(ctx context.Context, users []*BlockedUser) error
+
+	// CursorStore persists the after cursor between runs of SyncBlockedUsers. The saved
+	// cursor is always one whose preceding pages were all written to the sink, and it
+	// is reset to empty once a run reaches the end of the block list.
+	CursorStore interface {
+		LoadCursor(ctx context.Context) (string, error)
+		SaveCursor(ctx context.Context, cursor string) error
+	}
+
+	// SyncResult summarizes a run of SyncBlockedUsers. Cursor is the last cursor that was
+	// persisted, empty when the block list was walked to the end.
+	SyncResult struct {
+		Pages   int
+		Users   int
+		Cursor  string
+		Resumed bool
+	}
+
+	SyncOption func(*syncer)
+)
+
+func (fn SyncSinkFunc) WriteBlockedUsers(ctx context.Context, users []*BlockedUser) error {
+	return fn(ctx, users)
+}
+
+// WithSyncPageSize sets the number of blocked users requested per page.
+func WithSyncPageSize(size int) SyncOption {
+	return func(s *syncer) {
+		if size > 0 {
+			s.pageSize = size
+		}
+	}
+}
+
+// WithSyncWorkers sets how many pages are written to the sink at the same time.
+func WithSyncWorkers(workers int) SyncOption {
+	return func(s *syncer) {
+		if workers > 0 {
+			s.workers = workers
+		}
+	}
+}
+
+// WithSyncSpacing sets the minimum time between two page requests, keeping a long walk
+// under the business use case rate limits. Zero disables the spacing.
+func WithSyncSpacing(d time.Duration) SyncOption {
+	return func(s *syncer) {
+		if d >= 0 {
+			s.spacing = d
+		}
+	}
+}
+
+// WithCursorStore makes the sync resume from the cursor saved by a previous run and
+// persist its progress as pages are written.
+func WithCursorStore(store CursorStore) SyncOption {
+	return func(s *syncer) {
+		s.store = store
+	}
+}
+
+type syncer struct {
+	pageSize int
+	workers  int
+	spacing  time.Duration
+	store    CursorStore
+}
+
+type syncPage struct {
+	index  int
+	users  []*BlockedUser
+	cursor string
+}
+
+// SyncBlockedUsers walks the whole block list and streams every page to the sink.
+//
+// Pages are fetched one after the other, since each needs the cursor of the previous
+// one, and spaced by WithSyncSpacing. Writing them to the sink is done by a pool of
+// WithSyncWorkers goroutines so that a slow sink does not hold back the walk. With a
+// CursorStore the progress is saved after every page, so an interrupted sync picks up
+// where it stopped instead of starting over.
+//
+//	result, err := user.SyncBlockedUsers(ctx, client, sink,
+//		user.WithCursorStore(store),
+//		user.WithSyncWorkers(8),
+//	)
+func SyncBlockedUsers(ctx context.Context, service Service, sink SyncSink,
+	options ...SyncOption,
+) (*SyncResult, error) {
+	s := &syncer{
+		pageSize: DefaultSyncPageSize,
+		workers:  DefaultSyncWorkers,
+		spacing:  DefaultSyncSpacing,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	result, err := s.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	tracker := &cursorTracker{store: s.store, done: make(map[int]syncPage), result: result}
+	pages := make(chan syncPage, s.workers)
+
+	wg := s.spawnWriters(ctx, cancel, sink, tracker, pages)
+
+	pager := NewBlockedUsersPager(service, &ListBlockedRequest{Limit: s.pageSize, After: result.Cursor})
+	for index := 0; pager.HasNext() && ctx.Err() == nil; index++ {
+		if index > 0 && s.spacing > 0 {
+			if err := sleepContext(ctx, s.spacing); err != nil {
+				break
+			}
+		}
+
+		users, err := pager.Next(ctx)
+		if err != nil {
+			cancel(err)
+
+			break
+		}
+
+		select {
+		case pages <- syncPage{index: index, users: users, cursor: pager.Cursor()}:
+		case <-ctx.Done():
+		}
+	}
+
+	close(pages)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return result, err
+	}
+
+	if s.store != nil {
+		if err := s.store.SaveCursor(ctx, ""); err != nil {
+			return result, fmt.Errorf("%w: %w", ErrSyncCursor, err)
+		}
+	}
+	result.Cursor = ""
+
+	return result, nil
+}
+
+func (s *syncer) start(ctx context.Context) (*SyncResult, error) {
+	result := &SyncResult{}
+	if s.store == nil {
+		return result, nil
+	}
+
+	cursor, err := s.store.LoadCursor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyncCursor, err)
+	}
+	result.Cursor = cursor
+	result.Resumed = cursor != ""
+
+	return result, nil
+}
+
+// spawnWriters starts the workers that drain pages into the sink. The first failure
+// cancels ctx and the remaining pages are dropped.
+func (s *syncer) spawnWriters(ctx context.Context, cancel context.CancelCauseFunc, sink SyncSink,
+	tracker *cursorTracker, pages <-chan syncPage,
+) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := s.write(ctx, sink, tracker, page); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+	return wg
+}
+
+func (s *syncer) write(ctx context.Context, sink SyncSink, tracker *cursorTracker, page syncPage) error {
+	if len(page.users) > 0 {
+		if err := sink.WriteBlockedUsers(ctx, page.users); err != nil {
+			return fmt.Errorf("%w: %w", ErrSyncSink, err)
+		}
+	}
+
+	return tracker.complete(ctx, page)
+}
+
+// cursorTracker persists the cursor of the highest page for which every page before it
+// was written, so that resuming never skips a page that failed or was still in flight.
+type cursorTracker struct {
+	mu     sync.Mutex
+	store  CursorStore
+	next   int
+	done   map[int]syncPage
+	result *SyncResult
+}
+
+func (t *cursorTracker) complete(ctx context.Context, page syncPage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done[page.index] = page
+	advanced := false
+	for {
+		completed, ok := t.done[t.next]
+		if !ok {
+			break
+		}
+		delete(t.done, t.next)
+		t.next++
+		t.result.Pages++
+		t.result.Users += len(completed.users)
+		t.result.Cursor = completed.cursor
+		advanced = true
+	}
+
+	if !advanced || t.store == nil || t.result.Cursor == "" {
+		return nil
+	}
+
+	if err := t.store.SaveCursor(ctx, t.result.Cursor); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncCursor, err)
+	}
+
+	return nil
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
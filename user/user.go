@@ -0,0 +1,359 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package user blocks and unblocks WhatsApp users on behalf of a business phone number and
+// lists the blocked ones. Blocked users cannot call or message the business and the
+// business cannot message them. BlockedUsersPager walks the whole block list and
+// SyncBlockedUsers streams it to a sink, resuming from a saved cursor.
+package user
+
+//go:generate mockgen -destination=../mocks/user/mock_user.go -package=user -source=user.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/piusalfred/whatsapp/config"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+const EndpointBlockUsers = "block_users"
+
+const messagingProduct = "whatsapp"
+
+type (
+	// BlockUser identifies a user to block or unblock by phone number or WhatsApp id.
+	BlockUser struct {
+		User string `json:"user"`
+	}
+
+	// BlockResponse reports the users whose block status changed and the ones that failed.
+	// Input is the value sent in the request.
+	BlockResponse struct {
+		AddedUsers   []*BlockedUserResult `json:"added_users,omitempty"`
+		RemovedUsers []*BlockedUserResult `json:"removed_users,omitempty"`
+		FailedUsers  []*BlockedUserResult `json:"failed_users,omitempty"`
+	}
+
+	BlockedUserResult struct {
+		Input  string           `json:"input"`
+		WaID   string           `json:"wa_id,omitempty"`
+		Errors []*werrors.Error `json:"errors,omitempty"`
+	}
+
+	BlockedUser struct {
+		Product string `json:"messaging_product,omitempty"`
+		WaID    string `json:"wa_id"`
+	}
+
+	// ListBlockedRequest selects a page of blocked users. Limit is the page size.
+	ListBlockedRequest struct {
+		Limit  int
+		After  string
+		Before string
+	}
+
+	ListBlockedResponse struct {
+		Data    []*BlockedUser `json:"data"`
+		Paging  *Paging        `json:"paging,omitempty"`
+		Summary *Summary       `json:"summary,omitempty"`
+	}
+
+	Paging  = whttp.Paging
+	Cursors = whttp.Cursors
+
+	// Summary is returned with a page when the API reports the size of the block list.
+	Summary = whttp.Summary
+
+	BaseClient struct {
+		Sender Sender
+		Config config.Reader
+	}
+)
+
+func NewBaseClient(s whttp.AnySender, reader config.Reader, middlewares ...SenderMiddleware) *BaseClient {
+	sender := &BaseSender{Sender: s}
+
+	return &BaseClient{
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+		Config: reader,
+	}
+}
+
+func (c *BaseClient) Block(ctx context.Context, users ...string) (*BlockResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Block(ctx, c.Sender, conf, users...)
+}
+
+func (c *BaseClient) Unblock(ctx context.Context, users ...string) (*BlockResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Unblock(ctx, c.Sender, conf, users...)
+}
+
+func (c *BaseClient) ListBlocked(ctx context.Context, req *ListBlockedRequest) (*ListBlockedResponse, error) {
+	conf, err := c.Config.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return ListBlocked(ctx, c.Sender, conf, req)
+}
+
+type Client struct {
+	Config *config.Config
+	Sender Sender
+}
+
+func NewClient(ctx context.Context, reader config.Reader,
+	sender Sender, middlewares ...SenderMiddleware,
+) (*Client, error) {
+	conf, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	client := &Client{
+		Config: conf,
+		Sender: wrapMiddlewares(sender.Send, middlewares),
+	}
+
+	return client, nil
+}
+
+func (c *Client) Block(ctx context.Context, users ...string) (*BlockResponse, error) {
+	return Block(ctx, c.Sender, c.Config, users...)
+}
+
+func (c *Client) Unblock(ctx context.Context, users ...string) (*BlockResponse, error) {
+	return Unblock(ctx, c.Sender, c.Config, users...)
+}
+
+func (c *Client) ListBlocked(ctx context.Context, req *ListBlockedRequest) (*ListBlockedResponse, error) {
+	return ListBlocked(ctx, c.Sender, c.Config, req)
+}
+
+var (
+	ErrBlockUsers   = errors.New("failed to block users")
+	ErrUnblockUsers = errors.New("failed to unblock users")
+	ErrListBlocked  = errors.New("failed to list blocked users")
+	ErrNoUsers      = errors.New("at least one user is required")
+	ErrSyncSink     = errors.New("blocked users sink failed")
+	ErrSyncCursor   = errors.New("failed to persist the sync cursor")
+)
+
+// Block blocks the users, identified by phone number or WhatsApp id. Only users that
+// messaged the business in the last 24 hours can be blocked.
+func Block(ctx context.Context, sender Sender, conf *config.Config, users ...string) (*BlockResponse, error) {
+	response, err := blockUsers(ctx, sender, conf, http.MethodPost, whttp.RequestTypeBlockUsers, users)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBlockUsers, err)
+	}
+
+	return response, nil
+}
+
+// Unblock removes the users from the block list.
+func Unblock(ctx context.Context, sender Sender, conf *config.Config, users ...string) (*BlockResponse, error) {
+	response, err := blockUsers(ctx, sender, conf, http.MethodDelete, whttp.RequestTypeUnblockUsers, users)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnblockUsers, err)
+	}
+
+	return response, nil
+}
+
+func blockUsers(ctx context.Context, sender Sender, conf *config.Config, method string,
+	requestType whttp.RequestType, users []string,
+) (*BlockResponse, error) {
+	if len(users) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	blockUsers := make([]*BlockUser, len(users))
+	for i, u := range users {
+		blockUsers[i] = &BlockUser{User: u}
+	}
+
+	request := &BaseRequest{
+		Method:    method,
+		Type:      requestType,
+		Endpoints: []string{conf.PhoneNumberID, EndpointBlockUsers},
+		Body: map[string]any{
+			"messaging_product": messagingProduct,
+			"block_users":       blockUsers,
+		},
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.BlockUsers == nil {
+		return &BlockResponse{}, nil
+	}
+
+	return response.BlockUsers, nil
+}
+
+// ListBlocked returns a page of the users blocked by the phone number.
+func ListBlocked(ctx context.Context, sender Sender, conf *config.Config,
+	req *ListBlockedRequest,
+) (*ListBlockedResponse, error) {
+	request := &BaseRequest{
+		Method:      http.MethodGet,
+		Type:        whttp.RequestTypeListBlockedUsers,
+		Endpoints:   []string{conf.PhoneNumberID, EndpointBlockUsers},
+		QueryParams: req.queryParams(),
+	}
+
+	response, err := sender.Send(ctx, conf, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListBlocked, err)
+	}
+
+	return &ListBlockedResponse{
+		Data:    response.Data,
+		Paging:  response.Paging,
+		Summary: response.Summary,
+	}, nil
+}
+
+func (req *ListBlockedRequest) queryParams() map[string]string {
+	params := map[string]string{}
+	if req == nil {
+		return params
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.After != "" {
+		params["after"] = req.After
+	}
+
+	if req.Before != "" {
+		params["before"] = req.Before
+	}
+
+	return params
+}
+
+type (
+	// BaseRequest blocks, unblocks or lists the users blocked by the phone number.
+	BaseRequest struct {
+		Method      string
+		Type        whttp.RequestType
+		Endpoints   []string
+		QueryParams map[string]string
+		Body        any
+	}
+
+	// Response decodes the block_users replies, the list pages included.
+	Response struct {
+		Product    string         `json:"messaging_product,omitempty"`
+		BlockUsers *BlockResponse `json:"block_users,omitempty"`
+		Data       []*BlockedUser `json:"data,omitempty"`
+		Paging     *Paging        `json:"paging,omitempty"`
+		Summary    *Summary       `json:"summary,omitempty"`
+	}
+
+	Sender interface {
+		Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+	}
+
+	SenderFunc func(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error)
+)
+
+func (fn SenderFunc) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	return fn(ctx, conf, req)
+}
+
+type SenderMiddleware func(senderFunc SenderFunc) SenderFunc
+
+func wrapMiddlewares(next SenderFunc, middlewares []SenderMiddleware) SenderFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			next = middlewares[i](next)
+		}
+	}
+
+	return next
+}
+
+type BaseSender struct {
+	Sender whttp.AnySender
+}
+
+func (sender *BaseSender) Send(ctx context.Context, conf *config.Config, req *BaseRequest) (*Response, error) {
+	endpoints := append([]string{conf.APIVersion}, req.Endpoints...)
+
+	opts := []whttp.RequestOption[any]{
+		whttp.WithRequestType[any](req.Type),
+		whttp.WithRequestEndpoints[any](endpoints...),
+		whttp.WithRequestQueryParams[any](req.QueryParams),
+		whttp.WithRequestBearer[any](conf.AccessToken),
+		whttp.WithRequestAppSecret[any](conf.AppSecret),
+		whttp.WithRequestSecured[any](conf.SecureRequests),
+	}
+
+	if req.Body != nil {
+		opts = append(opts, whttp.WithRequestMessage[any](&req.Body))
+	}
+
+	request := whttp.MakeRequest[any](req.Method, conf.BaseURL, opts...)
+
+	response := &Response{}
+
+	decoder := whttp.ResponseDecoderJSON(response, whttp.DecodeOptions{
+		DisallowUnknownFields: false,
+		DisallowEmptyResponse: true,
+		InspectResponseError:  true,
+	})
+
+	if err := sender.Sender.Send(ctx, request, decoder); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+type Service interface {
+	Block(ctx context.Context, users ...string) (*BlockResponse, error)
+	Unblock(ctx context.Context, users ...string) (*BlockResponse, error)
+	ListBlocked(ctx context.Context, req *ListBlockedRequest) (*ListBlockedResponse, error)
+}
+
+var (
+	_ Service = (*BaseClient)(nil)
+	_ Service = (*Client)(nil)
+)
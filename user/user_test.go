@@ -0,0 +1,237 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/internal/apitest"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/user"
+)
+
+func TestBaseClient_Block(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/phone/block_users" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		encoded, _ := json.Marshal(body)
+		requests = append(requests, r.Method+" "+string(encoded))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","block_users":{"removed_users":` +
+				`[{"input":"255700000001","wa_id":"255700000001"}]}}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","block_users":{"added_users":` +
+			`[{"input":"255700000001","wa_id":"255700000001"}],"failed_users":[{"input":"255700000002",` +
+			`"errors":[{"message":"Re-engagement check failed","code":139100}]}]}}`))
+	})
+	client := user.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	blocked, err := client.Block(ctx, "255700000001", "255700000002")
+	if err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+
+	if len(blocked.AddedUsers) != 1 || len(blocked.FailedUsers) != 1 || blocked.FailedUsers[0].Errors[0].Code != 139100 {
+		t.Errorf("Block() = %+v", blocked)
+	}
+
+	unblocked, err := client.Unblock(ctx, "255700000001")
+	if err != nil || len(unblocked.RemovedUsers) != 1 {
+		t.Fatalf("Unblock() = %+v, %v", unblocked, err)
+	}
+
+	want := []string{
+		`POST {"block_users":[{"user":"255700000001"},{"user":"255700000002"}],"messaging_product":"whatsapp"}`,
+		`DELETE {"block_users":[{"user":"255700000001"}],"messaging_product":"whatsapp"}`,
+	}
+	if diff := gcmp.Diff(want, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.Block(ctx); !errors.Is(err, user.ErrNoUsers) || !errors.Is(err, user.ErrBlockUsers) {
+		t.Errorf("Block() error = %v, want ErrNoUsers", err)
+	}
+}
+
+func TestBlockedUsersPager(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"": `{"data":[{"messaging_product":"whatsapp","wa_id":"1"},{"messaging_product":"whatsapp","wa_id":"2"}],` +
+			`"paging":{"cursors":{"before":"b1","after":"a1"},"next":"https://graph.facebook.com/next"},` +
+			`"summary":{"total_count":3}}`,
+		"a1": `{"data":[{"messaging_product":"whatsapp","wa_id":"3"}],"paging":{"cursors":{"before":"b2","after":"a2"}}}`,
+	}
+
+	reader := apitest.NewConfigReader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("limit = %q, want 2", r.URL.Query().Get("limit"))
+		}
+
+		page, ok := pages[r.URL.Query().Get("after")]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, page)
+	})
+	client := user.NewBaseClient(whttp.NewAnySender(), reader)
+
+	ctx := context.Background()
+	pager := user.NewBlockedUsersPager(client, &user.ListBlockedRequest{Limit: 2})
+
+	var got []string
+	for blocked, err := range pager.Users(ctx) {
+		if err != nil {
+			t.Fatalf("Users() error = %v", err)
+		}
+		got = append(got, blocked.WaID)
+	}
+
+	if diff := gcmp.Diff([]string{"1", "2", "3"}, got); diff != "" {
+		t.Errorf("Users() mismatch (-want +got):\n%s", diff)
+	}
+
+	if pager.HasNext() || pager.Count() != 3 || pager.TotalCount() != 3 {
+		t.Errorf("pager HasNext = %v, Count = %d, TotalCount = %d", pager.HasNext(), pager.Count(), pager.TotalCount())
+	}
+
+	// resume from the saved cursor.
+	resumed := user.NewBlockedUsersPager(client, &user.ListBlockedRequest{Limit: 2, After: "a1"})
+	users, err := resumed.All(ctx)
+	if err != nil || len(users) != 1 || users[0].WaID != "3" || resumed.TotalCount() != -1 {
+		t.Errorf("All() = %v, %v, TotalCount = %d", users, err, resumed.TotalCount())
+	}
+}
+
+type pagedService struct {
+	user.Service
+	pages map[string]*user.ListBlockedResponse
+}
+
+func (s *pagedService) ListBlocked(_ context.Context, req *user.ListBlockedRequest) (*user.ListBlockedResponse, error) {
+	page, ok := s.pages[req.After]
+	if !ok {
+		return nil, user.ErrListBlocked
+	}
+
+	return page, nil
+}
+
+type memoryCursor struct {
+	mu    sync.Mutex
+	saved []string
+}
+
+func (m *memoryCursor) LoadCursor(context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.saved) == 0 {
+		return "", nil
+	}
+
+	return m.saved[len(m.saved)-1], nil
+}
+
+func (m *memoryCursor) SaveCursor(_ context.Context, cursor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved = append(m.saved, cursor)
+
+	return nil
+}
+
+func TestSyncBlockedUsers(t *testing.T) {
+	t.Parallel()
+
+	page := func(after string, ids ...string) *user.ListBlockedResponse {
+		response := &user.ListBlockedResponse{}
+		for _, id := range ids {
+			response.Data = append(response.Data, &user.BlockedUser{WaID: id})
+		}
+		if after != "" {
+			response.Paging = &user.Paging{Cursors: &user.Cursors{After: after}, Next: "next"}
+		}
+
+		return response
+	}
+
+	service := &pagedService{pages: map[string]*user.ListBlockedResponse{
+		"":   page("a1", "1", "2"),
+		"a1": page("a2", "3", "4"),
+		"a2": page("", "5"),
+	}}
+
+	store := &memoryCursor{}
+	sinkErr := errors.New("sink down")
+
+	var mu sync.Mutex
+	written := map[string]bool{}
+	sink := func(fail string) user.SyncSinkFunc {
+		return func(_ context.Context, users []*user.BlockedUser) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, blocked := range users {
+				if blocked.WaID == fail {
+					return sinkErr
+				}
+				written[blocked.WaID] = true
+			}
+
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	options := []user.SyncOption{user.WithCursorStore(store), user.WithSyncSpacing(0), user.WithSyncWorkers(1)}
+
+	result, err := user.SyncBlockedUsers(ctx, service, sink("3"), options...)
+	if !errors.Is(err, user.ErrSyncSink) || !errors.Is(err, sinkErr) {
+		t.Fatalf("SyncBlockedUsers() error = %v, want ErrSyncSink", err)
+	}
+
+	if result.Cursor != "a1" || result.Users != 2 {
+		t.Errorf("interrupted result = %+v, want cursor a1 after 2 users", result)
+	}
+
+	result, err = user.SyncBlockedUsers(ctx, service, sink(""), options...)
+	if err != nil {
+		t.Fatalf("SyncBlockedUsers() resumed error = %v", err)
+	}
+
+	want := &user.SyncResult{Pages: 2, Users: 3, Resumed: true}
+	if diff := gcmp.Diff(want, result); diff != "" {
+		t.Errorf("resumed result mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]string{"a1", "a2", ""}, store.saved); diff != "" {
+		t.Errorf("saved cursors mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(written) != 5 {
+		t.Errorf("written users = %v, want all 5", written)
+	}
+}
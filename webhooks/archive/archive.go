@@ -0,0 +1,266 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package archive keeps an audit trail of raw webhook notifications.
+//
+// A Writer receives the raw payload of every validated notification through Handler
+// and appends it as a JSON line to a sink. Writes are buffered and happen on a background
+// goroutine, so a slow disk never holds up the webhook response. When the buffer is full
+// records are dropped and counted instead of blocking the request. RotatingFile is a sink that rotates files by
+// size and age and can gzip them.
+//
+// Archived payloads can be fed back through a webhooks.NotificationHandler with Replay.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/store"
+)
+
+// Record is a single archived notification.
+type Record struct {
+	ReceivedAt time.Time       `json:"received_at"`
+	Signature  string          `json:"signature,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type (
+	// Writer appends records to a sink from a background goroutine.
+	Writer struct {
+		sink        io.Writer
+		records     chan *Record
+		done        chan struct{}
+		dropped     atomic.Uint64
+		errorHandle func(err error)
+		closeOnce   sync.Once
+		mu          sync.RWMutex
+		closed      bool
+	}
+
+	WriterOption func(*writerConfig)
+
+	writerConfig struct {
+		bufferSize  int
+		errorHandle func(err error)
+	}
+)
+
+const defaultBufferSize = 1024
+
+// WithBufferSize sets how many records can be queued before new ones are dropped.
+func WithBufferSize(size int) WriterOption {
+	return func(c *writerConfig) {
+		if size > 0 {
+			c.bufferSize = size
+		}
+	}
+}
+
+// WithErrorHandler sets a function that is called when writing to the sink fails.
+func WithErrorHandler(fn func(err error)) WriterOption {
+	return func(c *writerConfig) {
+		c.errorHandle = fn
+	}
+}
+
+// NewWriter creates a Writer and starts its background loop. The sink is closed by
+// Close if it implements io.Closer.
+func NewWriter(sink io.Writer, options ...WriterOption) *Writer {
+	conf := &writerConfig{
+		bufferSize:  defaultBufferSize,
+		errorHandle: func(error) {},
+	}
+	for _, option := range options {
+		option(conf)
+	}
+
+	w := &Writer{
+		sink:        sink,
+		records:     make(chan *Record, conf.bufferSize),
+		done:        make(chan struct{}),
+		errorHandle: conf.errorHandle,
+	}
+
+	go w.loop()
+
+	return w
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+	encoder := json.NewEncoder(w.sink)
+	for record := range w.records {
+		if err := encoder.Encode(record); err != nil {
+			w.errorHandle(fmt.Errorf("%w: %w", ErrWriteRecord, err))
+		}
+	}
+}
+
+// Archive queues a record without blocking. It returns ErrBufferFull when the queue is
+// full and ErrWriterClosed after Close has been called.
+func (w *Writer) Archive(record *Record) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.records <- record:
+		return nil
+	default:
+		w.dropped.Add(1)
+
+		return ErrBufferFull
+	}
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (w *Writer) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops accepting records, waits for the queued ones to be written and closes
+// the sink when possible. The context bounds how long Close waits.
+func (w *Writer) Close(ctx context.Context) error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		close(w.records)
+		w.mu.Unlock()
+	})
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return fmt.Errorf("close archive writer: %w", ctx.Err())
+	}
+
+	if closer, ok := w.sink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("close archive sink: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Handler archives the raw payload of every notification and then passes it to next. It
+// reads the payload from the context, which webhooks.Listener only sets after the
+// X-Hub-Signature-256 header has been validated, so forged requests never reach the
+// archive. Wrap the http.Handler with store.Middleware to keep the signature header on
+// the record as well.
+func Handler[T any](w *Writer, next webhooks.NotificationHandler[T]) webhooks.NotificationHandler[T] {
+	return webhooks.NotificationHandlerFunc[T](func(ctx context.Context, notification *T) *webhooks.Response {
+		if payload, ok := webhooks.RawPayloadFromContext(ctx); ok && json.Valid(payload) {
+			_ = w.Archive(&Record{
+				ReceivedAt: time.Now().UTC(),
+				Signature:  store.SignatureFromContext(ctx),
+				Payload:    payload,
+			})
+		}
+
+		return next.HandleNotification(ctx, notification)
+	})
+}
+
+// Replay reads archived records from r and passes each payload to handler. Gzip
+// compressed archives are detected automatically. It returns the number of records
+// replayed and stops at the first error.
+func Replay[T any](ctx context.Context, r io.Reader, handler webhooks.NotificationHandler[T]) (int, error) {
+	reader, err := maybeGzip(r)
+	if err != nil {
+		return 0, err
+	}
+
+	decoder := json.NewDecoder(reader)
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, fmt.Errorf("replay: %w", err)
+		}
+
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			return count, fmt.Errorf("%w: %w", ErrReadRecord, err)
+		}
+
+		var notification T
+		if err := json.Unmarshal(record.Payload, &notification); err != nil {
+			return count, fmt.Errorf("%w: record %d: %w", ErrReadRecord, count, err)
+		}
+
+		response := handler.HandleNotification(ctx, &notification)
+		if response != nil && response.StatusCode >= http.StatusBadRequest {
+			return count, fmt.Errorf("%w: record %d: status %d", ErrReplayHandler, count, response.StatusCode)
+		}
+		count++
+	}
+}
+
+func maybeGzip(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrReadRecord, err)
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrReadRecord, err)
+		}
+		gz.Multistream(true)
+
+		return gz, nil
+	}
+
+	return buffered, nil
+}
+
+// archiveError is a custom error type for archive errors.
+type archiveError string
+
+func (e archiveError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrBufferFull    = archiveError("archive buffer is full")
+	ErrWriterClosed  = archiveError("archive writer is closed")
+	ErrWriteRecord   = archiveError("could not write archive record")
+	ErrReadRecord    = archiveError("could not read archive record")
+	ErrReplayHandler = archiveError("replay handler failed")
+)
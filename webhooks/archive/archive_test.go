@@ -0,0 +1,169 @@
+package archive_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/archive"
+)
+
+type notification struct {
+	Object string `json:"object"`
+}
+
+func TestArchiveAndReplay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{name: "plain", compress: false},
+		{name: "gzip", compress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			sink, err := archive.NewRotatingFile(dir, "notifications",
+				archive.WithMaxBytes(64), archive.WithCompression(tt.compress))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			writer := archive.NewWriter(sink)
+			var seen []string
+			handler := newListener(writer, func(n *notification) {
+				seen = append(seen, n.Object)
+			})
+
+			payloads := []string{
+				`{"object":"first"}`,
+				`{"object":"second"}`,
+				`{"object":"third"}`,
+			}
+			for _, payload := range payloads {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, signedRequest(payload, appSecret))
+				if recorder.Code != http.StatusOK {
+					t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+				}
+			}
+
+			if err := writer.Close(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(seen) != len(payloads) {
+				t.Fatalf("next handler called %d times, want %d", len(seen), len(payloads))
+			}
+
+			files, err := filepath.Glob(filepath.Join(dir, "notifications-*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(files) < 2 {
+				t.Fatalf("expected files to rotate, got %d file(s)", len(files))
+			}
+
+			var replayed []string
+			replayHandler := webhooks.NotificationHandlerFunc[notification](
+				func(_ context.Context, n *notification) *webhooks.Response {
+					replayed = append(replayed, n.Object)
+
+					return &webhooks.Response{StatusCode: http.StatusOK}
+				})
+
+			for _, name := range files {
+				file, err := os.Open(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := archive.Replay[notification](context.TODO(), file, replayHandler); err != nil {
+					t.Fatal(err)
+				}
+				_ = file.Close()
+			}
+
+			want := "first,second,third"
+			if got := strings.Join(replayed, ","); got != want {
+				t.Errorf("replayed %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWriterArchiveAfterClose(t *testing.T) {
+	t.Parallel()
+	writer := archive.NewWriter(io.Discard, archive.WithBufferSize(1))
+	if err := writer.Close(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Archive(&archive.Record{}); !errors.Is(err, archive.ErrWriterClosed) {
+		t.Fatalf("expected ErrWriterClosed, got %v", err)
+	}
+}
+
+func TestHandlerSkipsForgedNotifications(t *testing.T) {
+	t.Parallel()
+
+	var sink strings.Builder
+	writer := archive.NewWriter(&sink)
+	called := false
+	handler := newListener(writer, func(*notification) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, signedRequest(`{"object":"forged"}`, "not-the-app-secret"))
+
+	if recorder.Code == http.StatusOK {
+		t.Errorf("status = %d, want an error status", recorder.Code)
+	}
+
+	if called {
+		t.Error("next handler called for a forged notification")
+	}
+
+	if err := writer.Close(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sink.Len() != 0 {
+		t.Errorf("archived %d bytes for a forged notification", sink.Len())
+	}
+}
+
+const appSecret = "app-secret"
+
+func newListener(writer *archive.Writer, fn func(n *notification)) http.Handler {
+	next := webhooks.NotificationHandlerFunc[notification](
+		func(_ context.Context, n *notification) *webhooks.Response {
+			fn(n)
+
+			return &webhooks.Response{StatusCode: http.StatusOK}
+		})
+
+	listener := webhooks.NewListener[notification](archive.Handler[notification](writer, next).HandleNotification,
+		nil, &webhooks.ValidateOptions{Validate: true, AppSecret: appSecret})
+
+	return http.HandlerFunc(listener.HandleNotification)
+}
+
+func signedRequest(payload, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(payload))
+	req.Header.Set(webhooks.SignatureHeaderKey, webhooks.SignPayload([]byte(payload), secret))
+
+	return req
+}
@@ -0,0 +1,202 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package archive
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	// RotatingFile is an io.WriteCloser that writes to files in a directory and starts a
+	// new file once the current one exceeds MaxBytes or is older than MaxAge.
+	RotatingFile struct {
+		mu       sync.Mutex
+		dir      string
+		prefix   string
+		maxBytes int64
+		maxAge   time.Duration
+		compress bool
+		now      func() time.Time
+
+		file   *os.File
+		gz     *gzip.Writer
+		out    io.Writer
+		size   int64
+		opened time.Time
+		seq    int
+	}
+
+	RotateOption func(*RotatingFile)
+)
+
+// WithMaxBytes rotates the file once this many uncompressed bytes have been written.
+func WithMaxBytes(n int64) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxBytes = n
+	}
+}
+
+// WithMaxAge rotates the file once it has been open for longer than d.
+func WithMaxAge(d time.Duration) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxAge = d
+	}
+}
+
+// WithCompression gzips each file. Compressed files get a .gz suffix.
+func WithCompression(compress bool) RotateOption {
+	return func(f *RotatingFile) {
+		f.compress = compress
+	}
+}
+
+// WithClock replaces time.Now, mainly useful in tests.
+func WithClock(now func() time.Time) RotateOption {
+	return func(f *RotatingFile) {
+		f.now = now
+	}
+}
+
+// NewRotatingFile creates dir if needed and returns a RotatingFile that names files
+// <prefix>-<timestamp>-<seq>.jsonl. The first file is opened on the first write.
+func NewRotatingFile(dir, prefix string, options ...RotateOption) (*RotatingFile, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create archive dir: %w", err)
+	}
+
+	f := &RotatingFile{
+		dir:    dir,
+		prefix: prefix,
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(f)
+	}
+
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.shouldRotate() {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.out.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write archive file: %w", err)
+	}
+
+	return n, nil
+}
+
+// Name returns the path of the file currently being written, or "" if none is open.
+func (f *RotatingFile) Name() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return ""
+	}
+
+	return f.file.Name()
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.closeCurrent()
+}
+
+func (f *RotatingFile) shouldRotate() bool {
+	if f.file == nil {
+		return true
+	}
+
+	if f.maxBytes > 0 && f.size >= f.maxBytes {
+		return true
+	}
+
+	return f.maxAge > 0 && f.now().Sub(f.opened) >= f.maxAge
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.closeCurrent(); err != nil {
+		return err
+	}
+
+	now := f.now().UTC()
+	f.seq++
+	name := fmt.Sprintf("%s-%s-%06d.jsonl", f.prefix, now.Format("20060102T150405"), f.seq)
+	if f.compress {
+		name += ".gz"
+	}
+
+	file, err := os.OpenFile(filepath.Join(f.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open archive file: %w", err)
+	}
+
+	f.file = file
+	f.out = file
+	f.size = 0
+	f.opened = now
+	if f.compress {
+		f.gz = gzip.NewWriter(file)
+		f.out = f.gz
+	}
+
+	return nil
+}
+
+func (f *RotatingFile) closeCurrent() error {
+	if f.file == nil {
+		return nil
+	}
+
+	var err error
+	if f.gz != nil {
+		err = f.gz.Close()
+		f.gz = nil
+	}
+
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	f.file = nil
+	f.out = nil
+
+	if err != nil {
+		return fmt.Errorf("close archive file: %w", err)
+	}
+
+	return nil
+}
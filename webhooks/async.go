@@ -0,0 +1,301 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Delivery is a notification accepted by the Listener and waiting to be processed.
+	// Payload is the raw body, its signature already validated.
+	Delivery struct {
+		Payload    []byte
+		ReceivedAt time.Time
+	}
+
+	// Queue holds the deliveries between the Listener that accepts them and the workers
+	// started by ProcessQueue. Enqueue must not block for long since the webhook waits for
+	// it, it fails when the queue is full so that WhatsApp retries the delivery later.
+	// Dequeue blocks until a delivery is available and returns ErrQueueClosed once the
+	// queue is closed and drained. A durable implementation keeps accepted notifications
+	// across restarts, MemoryQueue does not.
+	Queue interface {
+		Enqueue(ctx context.Context, delivery *Delivery) error
+		Dequeue(ctx context.Context) (*Delivery, error)
+	}
+
+	// DeadLetterHandler receives the deliveries that could not be processed. They were
+	// already acknowledged, so WhatsApp will not deliver them again.
+	DeadLetterHandler interface {
+		HandleDeadLetter(ctx context.Context, delivery *Delivery, err error)
+	}
+
+	DeadLetterHandlerFunc func(ctx context.Context, delivery *Delivery, err error)
+
+	// ProcessOption configures the workers started by ProcessQueue.
+	ProcessOption func(*processConfig)
+
+	processConfig struct {
+		workers     int
+		maxAttempts int
+		backoff     time.Duration
+		deadLetter  DeadLetterHandler
+	}
+)
+
+func (fn DeadLetterHandlerFunc) HandleDeadLetter(ctx context.Context, delivery *Delivery, err error) {
+	fn(ctx, delivery, err)
+}
+
+// WithProcessWorkers sets the number of deliveries processed at the same time, 4 by default.
+func WithProcessWorkers(n int) ProcessOption {
+	return func(c *processConfig) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithProcessRetry sets how many times a delivery is handled before it is dead-lettered
+// and the wait between attempts. A handler fails when it returns a status code of 300 or
+// above or panics. The default is a single attempt.
+func WithProcessRetry(maxAttempts int, backoff time.Duration) ProcessOption {
+	return func(c *processConfig) {
+		if maxAttempts > 0 {
+			c.maxAttempts = maxAttempts
+		}
+		c.backoff = backoff
+	}
+}
+
+// WithDeadLetter sets the handler of the deliveries that failed to decode or to be
+// handled. Without one they are dropped.
+func WithDeadLetter(handler DeadLetterHandler) ProcessOption {
+	return func(c *processConfig) {
+		c.deadLetter = handler
+	}
+}
+
+// enqueue hands the validated payload to the Queue and acknowledges the notification, or
+// answers 503 when the queue does not take it.
+func (listener *Listener[T]) enqueue(ctx context.Context, writer http.ResponseWriter, payload []byte) {
+	delivery := &Delivery{Payload: payload, ReceivedAt: time.Now()}
+	if err := listener.Queue.Enqueue(ctx, delivery); err != nil {
+		http.Error(writer, fmt.Errorf("%w: %w", ErrEnqueue, err).Error(), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// ProcessQueue runs the workers that decode and handle the deliveries of the Queue of the
+// Listener, the second half of the asynchronous mode. The Mirror and the RawHandler are
+// applied by the workers as they would be inline. It runs until ctx is done, returning
+// ctx.Err(), or until the queue is closed and drained, returning nil.
+//
+//	listener.Queue = webhooks.NewMemoryQueue(1000)
+//	go listener.ProcessQueue(ctx,
+//		webhooks.WithProcessWorkers(8),
+//		webhooks.WithDeadLetter(deadLetters),
+//	)
+func (listener *Listener[T]) ProcessQueue(ctx context.Context, options ...ProcessOption) error {
+	if listener.Queue == nil {
+		return ErrNoQueue
+	}
+
+	config := &processConfig{workers: 4, maxAttempts: 1} //nolint:mnd // default
+	for _, option := range options {
+		if option != nil {
+			option(config)
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		runErr  error
+	)
+
+	for range config.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				delivery, err := listener.Queue.Dequeue(ctx)
+				if err != nil {
+					if !errors.Is(err, ErrQueueClosed) {
+						errOnce.Do(func() { runErr = err })
+					}
+
+					return
+				}
+
+				listener.process(ctx, config, delivery)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return runErr
+}
+
+func (listener *Listener[T]) process(ctx context.Context, config *processConfig, delivery *Delivery) {
+	err := listener.processDelivery(ctx, config, delivery)
+	if err != nil && config.deadLetter != nil {
+		config.deadLetter.HandleDeadLetter(ctx, delivery, err)
+	}
+}
+
+func (listener *Listener[T]) processDelivery(ctx context.Context, config *processConfig, delivery *Delivery) error {
+	notification, response := listener.decode(ctx, delivery.Payload)
+	if response != nil {
+		if response.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%w: raw handler answered %d", ErrMessageDecode, response.StatusCode)
+		}
+
+		return nil
+	}
+
+	if notification == nil {
+		return ErrMessageDecode
+	}
+
+	if listener.Mirror != nil {
+		listener.Mirror.Dispatch(ctx, delivery.Payload)
+	}
+
+	handlerCtx := ContextWithRawPayload(ctx, delivery.Payload)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = listener.handleSafely(handlerCtx, notification); err == nil {
+			return nil
+		}
+
+		if attempt >= config.maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(config.backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// handleSafely calls the handler, turning failed responses and panics into errors.
+func (listener *Listener[T]) handleSafely(ctx context.Context, notification *T) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: panic: %v", ErrProcessNotification, recovered)
+		}
+	}()
+
+	response := listener.Handler.HandleNotification(ctx, notification)
+	if response == nil || response.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	return fmt.Errorf("%w: status code %d", ErrProcessNotification, response.StatusCode)
+}
+
+// MemoryQueue is a bounded Queue kept in memory. Deliveries still queued are lost when the
+// process stops, so it suits deployments that can afford to lose some notifications.
+type MemoryQueue struct {
+	items     chan *Delivery
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryQueue creates a MemoryQueue holding up to size deliveries.
+func NewMemoryQueue(size int) *MemoryQueue {
+	return &MemoryQueue{
+		items:  make(chan *Delivery, size),
+		closed: make(chan struct{}),
+	}
+}
+
+// Enqueue adds delivery to the queue, failing with ErrQueueFull instead of waiting.
+func (q *MemoryQueue) Enqueue(_ context.Context, delivery *Delivery) error {
+	select {
+	case <-q.closed:
+		return ErrQueueClosed
+	default:
+	}
+
+	select {
+	case q.items <- delivery:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	select {
+	case delivery := <-q.items:
+		return delivery, nil
+	default:
+	}
+
+	select {
+	case delivery := <-q.items:
+		return delivery, nil
+	case <-q.closed:
+		select {
+		case delivery := <-q.items:
+			return delivery, nil
+		default:
+			return nil, ErrQueueClosed
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Len returns the number of queued deliveries. It can be passed to NewBackpressure.
+func (q *MemoryQueue) Len() int {
+	return len(q.items)
+}
+
+// Close stops the queue from accepting deliveries, the queued ones can still be dequeued.
+func (q *MemoryQueue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+}
+
+var _ Queue = (*MemoryQueue)(nil)
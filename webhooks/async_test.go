@@ -0,0 +1,93 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+func TestListenerQueue(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		handled []string
+		dead    []string
+	)
+
+	listener := webhooks.NewListener[map[string]any](func(_ context.Context, n *map[string]any) *webhooks.Response {
+		id, _ := (*n)["id"].(string)
+		if id == "panic" {
+			panic("boom")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, id)
+
+		if id == "fail" {
+			return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+		}
+
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	}, nil, nil)
+
+	queue := webhooks.NewMemoryQueue(4)
+	listener.Queue = queue
+
+	post := func(body string) int {
+		recorder := httptest.NewRecorder()
+		listener.HandleNotification(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+		return recorder.Code
+	}
+
+	for _, body := range []string{`{"id":"ok"}`, `{"id":"fail"}`, `{"id":"panic"}`, `not json`} {
+		if code := post(body); code != http.StatusOK {
+			t.Fatalf("post %s = %d, want an immediate 200", body, code)
+		}
+	}
+
+	if code := post(`{"id":"overflow"}`); code != http.StatusServiceUnavailable {
+		t.Errorf("post to a full queue = %d, want 503", code)
+	}
+
+	if len(handled) != 0 || queue.Len() != 4 {
+		t.Fatalf("handled = %v, queued = %d before the workers run", handled, queue.Len())
+	}
+
+	queue.Close()
+	err := listener.ProcessQueue(context.Background(),
+		webhooks.WithProcessWorkers(2),
+		webhooks.WithProcessRetry(2, 0),
+		webhooks.WithDeadLetter(webhooks.DeadLetterHandlerFunc(
+			func(_ context.Context, delivery *webhooks.Delivery, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch {
+				case errors.Is(err, webhooks.ErrMessageDecode):
+					dead = append(dead, "decode")
+				case errors.Is(err, webhooks.ErrProcessNotification):
+					dead = append(dead, string(delivery.Payload))
+				}
+			})),
+	)
+	if err != nil {
+		t.Fatalf("ProcessQueue() error = %v", err)
+	}
+
+	if len(handled) != 3 {
+		t.Errorf("handled = %v, want ok once and fail twice", handled)
+	}
+
+	if len(dead) != 3 {
+		t.Errorf("dead letters = %v, want fail, panic and the undecodable payload", dead)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBackpressureMinRetryAfter = 5 * time.Second
+	DefaultBackpressureMaxRetryAfter = 5 * time.Minute
+	DefaultBackpressureMaxDuration   = time.Hour
+)
+
+type (
+	// QueueDepthFunc returns the number of notifications waiting in the asynchronous
+	// processing queues behind a Listener.
+	QueueDepthFunc func() int
+
+	// BackpressureMetrics receives the decisions of a Backpressure, for example to count
+	// rejected notifications and export the current mode as a gauge.
+	BackpressureMetrics interface {
+		// Rejected is called for every notification answered with 503.
+		Rejected(depth int, retryAfter time.Duration)
+
+		// StateChanged is called when back-pressure is engaged or released. Expired is set
+		// when it was released because it lasted longer than the maximum duration.
+		StateChanged(active, expired bool, depth int)
+	}
+
+	// BackpressureStats is a snapshot of a Backpressure.
+	BackpressureStats struct {
+		Active   bool
+		Expired  bool
+		Since    time.Time
+		Depth    int
+		Rejected uint64
+	}
+
+	BackpressureOption func(*Backpressure)
+)
+
+// Backpressure makes the Listener answer 503 Service Unavailable with a Retry-After header
+// while the processing queues are too deep. WhatsApp retries failed deliveries with a
+// decreasing frequency for up to 7 days, so its retries act as a buffer instead of the
+// notifications being dropped.
+//
+// Back-pressure is engaged when the depth reaches the high watermark and released when it
+// drops to the low watermark. Retry-After grows from the minimum to the maximum as the
+// depth grows from the high watermark to twice its value. To avoid leaning on the retries
+// for too long, back-pressure gives up after the maximum duration and notifications are
+// accepted again until the depth drops to the low watermark.
+type Backpressure struct {
+	depth         QueueDepthFunc
+	high          int
+	low           int
+	minRetryAfter time.Duration
+	maxRetryAfter time.Duration
+	maxDuration   time.Duration
+	metrics       BackpressureMetrics
+	now           func() time.Time
+
+	mu       sync.Mutex
+	active   bool
+	expired  bool
+	since    time.Time
+	rejected uint64
+}
+
+// WithBackpressureLowWatermark sets the depth at which back-pressure is released, half the
+// high watermark by default.
+func WithBackpressureLowWatermark(low int) BackpressureOption {
+	return func(b *Backpressure) {
+		b.low = low
+	}
+}
+
+// WithBackpressureRetryAfter sets the range of the computed Retry-After values.
+func WithBackpressureRetryAfter(minRetryAfter, maxRetryAfter time.Duration) BackpressureOption {
+	return func(b *Backpressure) {
+		b.minRetryAfter = minRetryAfter
+		b.maxRetryAfter = max(minRetryAfter, maxRetryAfter)
+	}
+}
+
+// WithBackpressureMaxDuration caps how long back-pressure may last, zero means no cap.
+func WithBackpressureMaxDuration(d time.Duration) BackpressureOption {
+	return func(b *Backpressure) {
+		b.maxDuration = d
+	}
+}
+
+func WithBackpressureMetrics(metrics BackpressureMetrics) BackpressureOption {
+	return func(b *Backpressure) {
+		b.metrics = metrics
+	}
+}
+
+func WithBackpressureClock(now func() time.Time) BackpressureOption {
+	return func(b *Backpressure) {
+		b.now = now
+	}
+}
+
+// NewBackpressure creates a Backpressure engaged when depth reaches high.
+func NewBackpressure(depth QueueDepthFunc, high int, options ...BackpressureOption) *Backpressure {
+	b := &Backpressure{
+		depth:         depth,
+		high:          max(high, 1),
+		low:           -1,
+		minRetryAfter: DefaultBackpressureMinRetryAfter,
+		maxRetryAfter: DefaultBackpressureMaxRetryAfter,
+		maxDuration:   DefaultBackpressureMaxDuration,
+		now:           time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(b)
+		}
+	}
+
+	if b.low < 0 || b.low >= b.high {
+		b.low = b.high / 2 //nolint:mnd // half the high watermark
+	}
+
+	return b
+}
+
+// Check reports whether the next notification should be rejected and the Retry-After to
+// answer with.
+func (b *Backpressure) Check() (time.Duration, bool) {
+	depth := b.depth()
+	now := b.now()
+
+	b.mu.Lock()
+	changed, expired := b.update(depth, now)
+	active := b.active && !b.expired
+
+	var retryAfter time.Duration
+	if active {
+		retryAfter = b.retryAfter(depth)
+		b.rejected++
+	}
+	b.mu.Unlock()
+
+	if b.metrics != nil {
+		if changed {
+			b.metrics.StateChanged(active, expired, depth)
+		}
+
+		if active {
+			b.metrics.Rejected(depth, retryAfter)
+		}
+	}
+
+	return retryAfter, active
+}
+
+// update moves between the accepting and rejecting modes, it reports whether the mode
+// changed and whether it changed because the maximum duration was reached.
+func (b *Backpressure) update(depth int, now time.Time) (bool, bool) {
+	switch {
+	case !b.active && depth >= b.high:
+		b.active, b.expired, b.since = true, false, now
+
+		return true, false
+	case b.active && depth <= b.low:
+		wasExpired := b.expired
+		b.active, b.expired, b.since = false, false, time.Time{}
+
+		return !wasExpired, false
+	case b.active && !b.expired && b.maxDuration > 0 && now.Sub(b.since) >= b.maxDuration:
+		b.expired = true
+
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func (b *Backpressure) retryAfter(depth int) time.Duration {
+	overflow := math.Min(1, float64(depth-b.high)/float64(b.high))
+	overflow = math.Max(0, overflow)
+
+	return b.minRetryAfter + time.Duration(overflow*float64(b.maxRetryAfter-b.minRetryAfter))
+}
+
+// Stats returns the current state of the back-pressure.
+func (b *Backpressure) Stats() BackpressureStats {
+	depth := b.depth()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BackpressureStats{
+		Active:   b.active && !b.expired,
+		Expired:  b.expired,
+		Since:    b.since,
+		Depth:    depth,
+		Rejected: b.rejected,
+	}
+}
+
+// Middleware answers 503 with a Retry-After header while back-pressure is engaged, it can
+// wrap handlers other than a Listener.
+func (b *Backpressure) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if b.reject(writer) {
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// reject writes the 503 response when back-pressure is engaged.
+func (b *Backpressure) reject(writer http.ResponseWriter) bool {
+	retryAfter, reject := b.Check()
+	if !reject {
+		return false
+	}
+
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	writer.Header().Set("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+	http.Error(writer, ErrBackpressure.Error(), http.StatusServiceUnavailable)
+
+	return true
+}
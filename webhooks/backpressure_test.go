@@ -0,0 +1,107 @@
+package webhooks_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+type recordingBackpressureMetrics struct {
+	rejected []time.Duration
+	changes  []string
+}
+
+func (m *recordingBackpressureMetrics) Rejected(_ int, retryAfter time.Duration) {
+	m.rejected = append(m.rejected, retryAfter)
+}
+
+func (m *recordingBackpressureMetrics) StateChanged(active, expired bool, _ int) {
+	switch {
+	case expired:
+		m.changes = append(m.changes, "expired")
+	case active:
+		m.changes = append(m.changes, "engaged")
+	default:
+		m.changes = append(m.changes, "released")
+	}
+}
+
+func TestListenerBackpressure(t *testing.T) {
+	t.Parallel()
+
+	depth := 0
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	metrics := &recordingBackpressureMetrics{}
+
+	handled := 0
+	listener := webhooks.NewListener[map[string]any](func(context.Context, *map[string]any) *webhooks.Response {
+		handled++
+
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	}, nil, nil)
+	listener.Backpressure = webhooks.NewBackpressure(func() int { return depth }, 100,
+		webhooks.WithBackpressureRetryAfter(10*time.Second, 110*time.Second),
+		webhooks.WithBackpressureMaxDuration(time.Hour),
+		webhooks.WithBackpressureMetrics(metrics),
+		webhooks.WithBackpressureClock(func() time.Time { return now }),
+	)
+
+	post := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		listener.HandleNotification(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+
+		return recorder
+	}
+
+	if code := post().Code; code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	depth = 150
+	response := post()
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("Retry-After") != "60" {
+		t.Fatalf("status = %d, Retry-After = %q, want 503 and 60", response.Code, response.Header().Get("Retry-After"))
+	}
+
+	// stays engaged above the low watermark.
+	depth = 60
+	if code := post().Code; code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", code)
+	}
+
+	depth = 50
+	if code := post().Code; code != http.StatusOK {
+		t.Errorf("status = %d after draining, want 200", code)
+	}
+
+	// gives up after the maximum duration.
+	depth = 500
+	_ = post()
+	now = now.Add(time.Hour)
+	if code := post().Code; code != http.StatusOK {
+		t.Errorf("status = %d after the maximum duration, want 200", code)
+	}
+
+	if stats := listener.Backpressure.Stats(); stats.Active || !stats.Expired || stats.Rejected != 3 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if handled != 3 {
+		t.Errorf("handled %d notifications, want 3", handled)
+	}
+
+	want := &recordingBackpressureMetrics{
+		rejected: []time.Duration{60 * time.Second, 10 * time.Second, 110 * time.Second},
+		changes:  []string{"engaged", "released", "engaged", "expired"},
+	}
+	if diff := gcmp.Diff(want, metrics, gcmp.AllowUnexported(recordingBackpressureMetrics{})); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package coexistence decodes the webhook fields that are only delivered for phone numbers
+// shared between the WhatsApp Business app and the Cloud API.
+package coexistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/pkg/types"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const (
+	// FieldSMBAppStateSync carries contact changes made in the WhatsApp Business app.
+	FieldSMBAppStateSync = "smb_app_state_sync"
+
+	// FieldSMBMessageEchoes carries messages sent from the WhatsApp Business app. They are
+	// decoded by the webhooks/message package, see message.Handlers.SetMessageEchoHandler.
+	FieldSMBMessageEchoes = message.ChangeFieldSMBMessageEchoes
+
+	// FieldHistory carries past conversations shared by the business when a number is
+	// onboarded in coexistence mode. It arrives in chunks and phases.
+	FieldHistory = "history"
+)
+
+const (
+	StateSyncTypeContact = "contact"
+
+	StateSyncActionAdd    = "add"
+	StateSyncActionRemove = "remove"
+)
+
+type (
+	Notification struct {
+		Object string   `json:"object"`
+		Entry  []*Entry `json:"entry"`
+	}
+
+	Entry struct {
+		ID      string    `json:"id"`
+		Time    int64     `json:"time"`
+		Changes []*Change `json:"changes"`
+	}
+
+	Change struct {
+		Value *Value `json:"value"`
+		Field string `json:"field"`
+	}
+
+	Value struct {
+		MessagingProduct string            `json:"messaging_product,omitempty"`
+		Metadata         *message.Metadata `json:"metadata,omitempty"`
+		StateSync        []*StateSync      `json:"state_sync,omitempty"`
+		History          []*History        `json:"history,omitempty"`
+	}
+
+	// History is one chunk of conversation history. When the business declines to share
+	// history Threads is empty and Errors explains why.
+	History struct {
+		Metadata *HistoryMetadata `json:"metadata,omitempty"`
+		Threads  []*HistoryThread `json:"threads,omitempty"`
+		Errors   []*werrors.Error `json:"errors,omitempty"`
+	}
+
+	// HistoryMetadata describes the position of a chunk. Phase is 0, 1 or 2 covering the
+	// last day, the last 90 days and up to 180 days, Progress is a percentage.
+	HistoryMetadata struct {
+		Phase      int `json:"phase"`
+		ChunkOrder int `json:"chunk_order"`
+		Progress   int `json:"progress"`
+	}
+
+	// HistoryThread holds the messages exchanged with one customer, ID is the customer's
+	// WhatsApp number.
+	HistoryThread struct {
+		ID       string            `json:"id"`
+		Messages []*HistoryMessage `json:"messages,omitempty"`
+	}
+
+	HistoryMessage struct {
+		message.Message
+		To             string          `json:"to,omitempty"`
+		HistoryContext *HistoryContext `json:"history_context,omitempty"`
+	}
+
+	HistoryContext struct {
+		Status string `json:"status,omitempty"`
+	}
+
+	// StateSync is a single change made in the WhatsApp Business app, for example a contact
+	// being added to or removed from the address book.
+	StateSync struct {
+		Type     string             `json:"type,omitempty"`
+		Contact  *StateSyncContact  `json:"contact,omitempty"`
+		Action   string             `json:"action,omitempty"`
+		Metadata *StateSyncMetadata `json:"metadata,omitempty"`
+	}
+
+	StateSyncContact struct {
+		FullName    string `json:"full_name,omitempty"`
+		FirstName   string `json:"first_name,omitempty"`
+		PhoneNumber string `json:"phone_number,omitempty"`
+	}
+
+	StateSyncMetadata struct {
+		Timestamp string `json:"timestamp,omitempty"`
+	}
+
+	// NotificationContext identifies where an event came from.
+	NotificationContext struct {
+		Object      string
+		EntryID     string
+		EntryTime   int64
+		ChangeField string
+		Metadata    *message.Metadata
+	}
+)
+
+type (
+	EventHandler[T any] interface {
+		HandleEvent(ctx context.Context, ntx *NotificationContext, event *T) error
+	}
+
+	EventHandlerFunc[T any] func(ctx context.Context, ntx *NotificationContext, event *T) error
+)
+
+func (fn EventHandlerFunc[T]) HandleEvent(ctx context.Context, ntx *NotificationContext, event *T) error {
+	return fn(ctx, ntx, event)
+}
+
+type (
+	NotificationHandler     webhooks.NotificationHandler[Notification]
+	NotificationHandlerFunc webhooks.NotificationHandlerFunc[Notification]
+)
+
+func (e NotificationHandlerFunc) HandleNotification(ctx context.Context,
+	notification *Notification,
+) *webhooks.Response {
+	return e(ctx, notification)
+}
+
+var _ NotificationHandler = (*Handlers)(nil)
+
+// Handlers dispatches coexistence notifications. Nil handlers are skipped.
+type Handlers struct {
+	StateSyncHandler EventHandler[StateSync]
+	HistoryHandler   EventHandler[History]
+
+	// ContactSyncHandler receives the contact events of a smb_app_state_sync change at
+	// once, validated and deduplicated by NewContactSync.
+	ContactSyncHandler EventHandler[ContactSync]
+}
+
+func (handlers *Handlers) HandleNotification(ctx context.Context, notification *Notification) *webhooks.Response {
+	if err := handlers.dispatchNotification(ctx, notification); err != nil {
+		return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+	}
+
+	return &webhooks.Response{StatusCode: http.StatusOK}
+}
+
+func (handlers *Handlers) dispatchNotification(ctx context.Context, notification *Notification) error {
+	if notification == nil {
+		return nil
+	}
+
+	for _, entry := range notification.Entry {
+		for _, change := range entry.Changes {
+			if change.Value == nil {
+				continue
+			}
+
+			notificationCtx := &NotificationContext{
+				Object:      notification.Object,
+				EntryID:     entry.ID,
+				EntryTime:   entry.Time,
+				ChangeField: change.Field,
+				Metadata:    change.Value.Metadata,
+			}
+
+			if err := handlers.dispatchChange(ctx, notificationCtx, change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (handlers *Handlers) dispatchChange(ctx context.Context, nctx *NotificationContext, change *Change) error {
+	if change.Field == FieldSMBAppStateSync && handlers.StateSyncHandler != nil {
+		for _, event := range change.Value.StateSync {
+			if err := handlers.StateSyncHandler.HandleEvent(ctx, nctx, event); err != nil {
+				return fmt.Errorf("handle state sync event: %w", err)
+			}
+		}
+	}
+
+	if change.Field == FieldSMBAppStateSync && handlers.ContactSyncHandler != nil {
+		phoneNumberID := ""
+		if nctx.Metadata != nil {
+			phoneNumberID = nctx.Metadata.PhoneNumberID
+		}
+
+		sync := NewContactSync(phoneNumberID, change.Value.StateSync)
+		if err := handlers.ContactSyncHandler.HandleEvent(ctx, nctx, sync); err != nil {
+			return fmt.Errorf("handle contact sync: %w", err)
+		}
+	}
+
+	if change.Field == FieldHistory && handlers.HistoryHandler != nil {
+		for _, event := range change.Value.History {
+			if err := handlers.HistoryHandler.HandleEvent(ctx, nctx, event); err != nil {
+				return fmt.Errorf("handle history event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes the embedded message.Message, whose UnmarshalJSON would otherwise be
+// promoted and skip To and HistoryContext.
+func (msg *HistoryMessage) UnmarshalJSON(data []byte) error {
+	if err := msg.Message.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	var aux struct {
+		To             types.LenientString `json:"to,omitempty"`
+		HistoryContext *HistoryContext     `json:"history_context,omitempty"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	msg.To, msg.HistoryContext = string(aux.To), aux.HistoryContext
+
+	return nil
+}
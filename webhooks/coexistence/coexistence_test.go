@@ -0,0 +1,72 @@
+package coexistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/coexistence"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestHandlers_StateSync(t *testing.T) {
+	t.Parallel()
+
+	var notification coexistence.Notification
+	if err := json.Unmarshal([]byte(stateSyncPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		contexts []*coexistence.NotificationContext
+		events   []*coexistence.StateSync
+	)
+	handlers := &coexistence.Handlers{StateSyncHandler: coexistence.EventHandlerFunc[coexistence.StateSync](
+		func(_ context.Context, nctx *coexistence.NotificationContext, event *coexistence.StateSync) error {
+			contexts = append(contexts, nctx)
+			events = append(events, event)
+
+			return nil
+		},
+	)}
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("got %d state sync events, want 5", len(events))
+	}
+
+	wantFirst := &coexistence.StateSync{
+		Type: coexistence.StateSyncTypeContact,
+		Contact: &coexistence.StateSyncContact{
+			FullName:    "Pablo Morales",
+			FirstName:   "Pablo",
+			PhoneNumber: "+1 650-555-1234",
+		},
+		Action:   coexistence.StateSyncActionAdd,
+		Metadata: &coexistence.StateSyncMetadata{Timestamp: "1738346006"},
+	}
+	if diff := gcmp.Diff(wantFirst, events[0]); diff != "" {
+		t.Errorf("first event mismatch (-want +got):\n%s", diff)
+	}
+
+	if events[3].Action != coexistence.StateSyncActionRemove || events[2].Metadata != nil {
+		t.Errorf("events[3].Action = %q, events[2].Metadata = %+v", events[3].Action, events[2].Metadata)
+	}
+
+	wantContext := &coexistence.NotificationContext{
+		Object:      "whatsapp_business_account",
+		EntryID:     "102290129340398",
+		EntryTime:   1739321024,
+		ChangeField: coexistence.FieldSMBAppStateSync,
+		Metadata:    &message.Metadata{DisplayPhoneNumber: "15550783881", PhoneNumberID: "106540352242922"},
+	}
+	if diff := gcmp.Diff(wantContext, contexts[0]); diff != "" {
+		t.Errorf("notification context mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package coexistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+)
+
+// Phone numbers are at most 15 digits long (E.164), the shortest national numbers have 7.
+const (
+	minContactDigits = 7
+	maxContactDigits = 15
+)
+
+// DefaultContactBatchSize is the number of contacts a ContactIngester writes at once.
+const DefaultContactBatchSize = 100
+
+var (
+	ErrInvalidContactPhone  = errors.New("invalid contact phone number")
+	ErrInvalidContactAction = errors.New("unknown contact sync action")
+)
+
+var _ EventHandler[ContactSync] = (*ContactIngester)(nil)
+
+type (
+	// Contact is an entry of the address book of the WhatsApp Business app. PhoneNumber
+	// holds digits only.
+	Contact struct {
+		PhoneNumberID string
+		PhoneNumber   string
+		FullName      string
+		FirstName     string
+		UpdatedAt     time.Time
+	}
+
+	// ContactSync is the validated outcome of the contact state sync events of a change.
+	// When a contact changes more than once in the change only its last action is kept.
+	// Invalid lists the events that were left out and why.
+	ContactSync struct {
+		Added   []*Contact
+		Removed []*Contact
+		Invalid []*InvalidStateSync
+	}
+
+	InvalidStateSync struct {
+		Event *StateSync
+		Err   error
+	}
+
+	// ContactStore keeps the contacts synced from the WhatsApp Business app.
+	ContactStore interface {
+		SaveContacts(ctx context.Context, contacts []*Contact) error
+		DeleteContacts(ctx context.Context, contacts []*Contact) error
+	}
+)
+
+// NewContactSync validates the contact events of a smb_app_state_sync change. Phone
+// numbers are normalized to digits, events of other types are ignored.
+func NewContactSync(phoneNumberID string, events []*StateSync) *ContactSync {
+	sync := &ContactSync{}
+	latest := make(map[string]int)
+
+	type change struct {
+		contact *Contact
+		remove  bool
+	}
+
+	var changes []*change
+	for _, event := range events {
+		if event == nil || event.Type != StateSyncTypeContact {
+			continue
+		}
+
+		contact, err := contactOf(phoneNumberID, event)
+		if err != nil {
+			sync.Invalid = append(sync.Invalid, &InvalidStateSync{Event: event, Err: err})
+
+			continue
+		}
+
+		c := &change{contact: contact, remove: event.Action == StateSyncActionRemove}
+		if i, ok := latest[contact.PhoneNumber]; ok {
+			changes[i] = c
+
+			continue
+		}
+
+		latest[contact.PhoneNumber] = len(changes)
+		changes = append(changes, c)
+	}
+
+	for _, c := range changes {
+		if c.remove {
+			sync.Removed = append(sync.Removed, c.contact)
+		} else {
+			sync.Added = append(sync.Added, c.contact)
+		}
+	}
+
+	return sync
+}
+
+func contactOf(phoneNumberID string, event *StateSync) (*Contact, error) {
+	if event.Action != StateSyncActionAdd && event.Action != StateSyncActionRemove {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidContactAction, event.Action)
+	}
+
+	if event.Contact == nil {
+		return nil, fmt.Errorf("%w: missing contact", ErrInvalidContactPhone)
+	}
+
+	phone, err := NormalizeContactPhone(event.Contact.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	contact := &Contact{
+		PhoneNumberID: phoneNumberID,
+		PhoneNumber:   phone,
+		FullName:      event.Contact.FullName,
+		FirstName:     event.Contact.FirstName,
+	}
+
+	if event.Metadata != nil {
+		contact.UpdatedAt = ParseTimestamp(event.Metadata.Timestamp)
+	}
+
+	return contact, nil
+}
+
+// NormalizeContactPhone strips the plus sign, spaces, dashes and parentheses from phone
+// and checks that what is left is a plausible international number.
+func NormalizeContactPhone(phone string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case '+', ' ', '-', '(', ')', '.':
+			return -1
+		default:
+			return r
+		}
+	}, phone)
+
+	if len(normalized) < minContactDigits || len(normalized) > maxContactDigits {
+		return "", fmt.Errorf("%w: %q", ErrInvalidContactPhone, phone)
+	}
+
+	for _, r := range normalized {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidContactPhone, phone)
+		}
+	}
+
+	return normalized, nil
+}
+
+// ContactIngester keeps a ContactStore aligned with the address book of the WhatsApp
+// Business app. Added contacts are saved and removed ones deleted, BatchSize at a time.
+// Use it as Handlers.ContactSyncHandler.
+type ContactIngester struct {
+	Store     ContactStore
+	BatchSize int
+
+	// OnInvalid, if set, is called for every event that failed validation. Invalid events
+	// are skipped so that one bad entry does not block the sync.
+	OnInvalid func(ctx context.Context, invalid *InvalidStateSync)
+}
+
+func (ingester *ContactIngester) HandleEvent(ctx context.Context, _ *NotificationContext, sync *ContactSync) error {
+	if sync == nil {
+		return nil
+	}
+
+	if ingester.OnInvalid != nil {
+		for _, invalid := range sync.Invalid {
+			ingester.OnInvalid(ctx, invalid)
+		}
+	}
+
+	size := ingester.BatchSize
+	if size <= 0 {
+		size = DefaultContactBatchSize
+	}
+
+	for batch := range chunks(sync.Added, size) {
+		if err := ingester.Store.SaveContacts(ctx, batch); err != nil {
+			return fmt.Errorf("save %d contacts: %w", len(batch), err)
+		}
+	}
+
+	for batch := range chunks(sync.Removed, size) {
+		if err := ingester.Store.DeleteContacts(ctx, batch); err != nil {
+			return fmt.Errorf("delete %d contacts: %w", len(batch), err)
+		}
+	}
+
+	return nil
+}
+
+func chunks(contacts []*Contact, size int) iter.Seq[[]*Contact] {
+	return func(yield func([]*Contact) bool) {
+		for start := 0; start < len(contacts); start += size {
+			if !yield(contacts[start:min(start+size, len(contacts))]) {
+				return
+			}
+		}
+	}
+}
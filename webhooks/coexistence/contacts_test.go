@@ -0,0 +1,96 @@
+package coexistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/coexistence"
+)
+
+const stateSyncPayload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "102290129340398",
+    "time": 1739321024,
+    "changes": [{
+      "field": "smb_app_state_sync",
+      "value": {
+        "messaging_product": "whatsapp",
+        "metadata": {"display_phone_number": "15550783881", "phone_number_id": "106540352242922"},
+        "state_sync": [
+          {"type": "contact", "contact": {"full_name": "Pablo Morales", "first_name": "Pablo", "phone_number": "+1 650-555-1234"}, "action": "add", "metadata": {"timestamp": "1738346006"}},
+          {"type": "contact", "contact": {"full_name": "Ana", "phone_number": "16505550000"}, "action": "add", "metadata": {"timestamp": "1738346007"}},
+          {"type": "contact", "contact": {"phone_number": "not a number"}, "action": "add"},
+          {"type": "contact", "contact": {"full_name": "Ana", "phone_number": "16505550000"}, "action": "remove", "metadata": {"timestamp": "1738346008"}},
+          {"type": "contact", "contact": {"full_name": "Zed", "phone_number": "16505559999"}, "action": "add"}
+        ]
+      }
+    }]
+  }]
+}`
+
+type contactStore struct {
+	saved   [][]string
+	deleted [][]string
+}
+
+func phones(contacts []*coexistence.Contact) []string {
+	out := make([]string, len(contacts))
+	for i, contact := range contacts {
+		out[i] = contact.PhoneNumber
+	}
+
+	return out
+}
+
+func (s *contactStore) SaveContacts(_ context.Context, contacts []*coexistence.Contact) error {
+	s.saved = append(s.saved, phones(contacts))
+
+	return nil
+}
+
+func (s *contactStore) DeleteContacts(_ context.Context, contacts []*coexistence.Contact) error {
+	s.deleted = append(s.deleted, phones(contacts))
+
+	return nil
+}
+
+func TestContactIngester(t *testing.T) {
+	t.Parallel()
+
+	var notification coexistence.Notification
+	if err := json.Unmarshal([]byte(stateSyncPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &contactStore{}
+	var invalid []error
+	handlers := &coexistence.Handlers{ContactSyncHandler: &coexistence.ContactIngester{
+		Store:     store,
+		BatchSize: 1,
+		OnInvalid: func(_ context.Context, event *coexistence.InvalidStateSync) {
+			invalid = append(invalid, event.Err)
+		},
+	}}
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	if diff := gcmp.Diff([][]string{{"16505551234"}, {"16505559999"}}, store.saved); diff != "" {
+		t.Errorf("saved batches mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([][]string{{"16505550000"}}, store.deleted); diff != "" {
+		t.Errorf("deleted batches mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(invalid) != 1 || !errors.Is(invalid[0], coexistence.ErrInvalidContactPhone) {
+		t.Errorf("invalid = %v, want one ErrInvalidContactPhone", invalid)
+	}
+}
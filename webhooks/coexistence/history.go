@@ -0,0 +1,214 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package coexistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piusalfred/whatsapp/tracking"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+var _ EventHandler[History] = (*HistoryIngester)(nil)
+
+// HistoryIngester saves history sync chunks to a tracking.Store so that conversations
+// started on the WhatsApp Business app show up alongside the ones handled by the Cloud API.
+// Chunks may arrive in any order, messages are stored with their original timestamps and
+// tracking.Store.List returns them in order. Messages that are already stored, because a
+// live webhook was received for them, are not replaced: only a newer status is taken from
+// the history. Use it as Handlers.HistoryHandler.
+type HistoryIngester struct {
+	Store tracking.Store
+
+	// OnChunk, if set, is called after every chunk is stored. It can be used to report
+	// onboarding progress.
+	OnChunk func(ctx context.Context, metadata *HistoryMetadata)
+}
+
+func (ingester *HistoryIngester) HandleEvent(ctx context.Context, ntx *NotificationContext, history *History) error {
+	if history == nil {
+		return nil
+	}
+
+	if len(history.Errors) > 0 && len(history.Threads) == 0 {
+		return nil
+	}
+
+	phoneNumberID := ""
+	if ntx != nil && ntx.Metadata != nil {
+		phoneNumberID = ntx.Metadata.PhoneNumberID
+	}
+
+	for _, thread := range history.Threads {
+		records := HistoryRecords(phoneNumberID, thread)
+		for _, record := range records {
+			if err := ingester.merge(ctx, record); err != nil {
+				return fmt.Errorf("save history message %s: %w", record.ID, err)
+			}
+		}
+
+		if len(records) > 0 {
+			if err := ingester.assignConversationIDs(ctx, phoneNumberID, thread.ID); err != nil {
+				return fmt.Errorf("assign conversation ids of %s: %w", thread.ID, err)
+			}
+		}
+	}
+
+	if ingester.OnChunk != nil && history.Metadata != nil {
+		ingester.OnChunk(ctx, history.Metadata)
+	}
+
+	return nil
+}
+
+// merge saves record unless a record with its ID is stored, in which case the history
+// status is applied with UpdateStatus, which ignores it when the stored one is newer.
+func (ingester *HistoryIngester) merge(ctx context.Context, record *tracking.Record) error {
+	_, err := ingester.Store.Get(ctx, record.ID)
+	switch {
+	case errors.Is(err, tracking.ErrNotFound):
+		return ingester.Store.Save(ctx, record)
+	case err != nil:
+		return err
+	case record.Status == "":
+		return nil
+	default:
+		return ingester.Store.UpdateStatus(ctx, record.ID, record.Status, record.StatusUpdatedAt)
+	}
+}
+
+// assignConversationIDs groups all the stored messages exchanged with contact into
+// conversations again, since a chunk received late can open a window before the ones
+// derived from the chunks stored earlier. Conversation IDs assigned by WhatsApp are kept.
+func (ingester *HistoryIngester) assignConversationIDs(ctx context.Context, phoneNumberID, contact string) error {
+	stored, err := ingester.Store.List(ctx, &tracking.Query{Contact: contact})
+	if err != nil {
+		return err
+	}
+
+	records := make([]*tracking.Record, 0, len(stored))
+	previous := make(map[string]string, len(stored))
+	for _, record := range stored {
+		if record.PhoneNumberID != phoneNumberID {
+			continue
+		}
+
+		previous[record.ID] = record.ConversationID
+		if tracking.IsLocalConversationID(record.ConversationID) {
+			record.ConversationID = ""
+		}
+		records = append(records, record)
+	}
+
+	tracking.AssignConversationIDs(records)
+
+	for _, record := range records {
+		if record.ConversationID == previous[record.ID] {
+			continue
+		}
+
+		// read the record again so that only the conversation ID changes.
+		current, err := ingester.Store.Get(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		current.ConversationID = record.ConversationID
+
+		if err := ingester.Store.Save(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HistoryRecords converts the messages of a thread to tracking records ordered by time.
+// Messages sent by the customer are inbound, the rest were sent by the business. The
+// records have no conversation IDs, HistoryIngester assigns them once they are stored.
+func HistoryRecords(phoneNumberID string, thread *HistoryThread) []*tracking.Record {
+	if thread == nil {
+		return nil
+	}
+
+	records := make([]*tracking.Record, 0, len(thread.Messages))
+	for _, msg := range thread.Messages {
+		if msg == nil || msg.ID == "" {
+			continue
+		}
+
+		direction := tracking.DirectionOutbound
+		if msg.From == thread.ID {
+			direction = tracking.DirectionInbound
+		}
+
+		record := &tracking.Record{
+			ID:            msg.ID,
+			PhoneNumberID: phoneNumberID,
+			Contact:       thread.ID,
+			Direction:     direction,
+			Type:          msg.Type,
+			Body:          messageBody(&msg.Message),
+			Timestamp:     ParseTimestamp(msg.Timestamp),
+			Source:        tracking.SourceHistory,
+		}
+
+		if msg.HistoryContext != nil {
+			record.Status = strings.ToLower(msg.HistoryContext.Status)
+			record.StatusUpdatedAt = record.Timestamp
+		}
+
+		records = append(records, record)
+	}
+
+	tracking.SortRecords(records)
+
+	return records
+}
+
+// ParseTimestamp parses the unix seconds timestamp used in webhook payloads. Invalid
+// values give the zero time.
+func ParseTimestamp(ts string) time.Time {
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0).UTC()
+}
+
+func messageBody(msg *message.Message) string {
+	switch {
+	case msg.Text != nil:
+		return msg.Text.Body
+	case msg.Image != nil:
+		return msg.Image.Caption
+	case msg.Video != nil:
+		return msg.Video.Caption
+	case msg.Document != nil:
+		return msg.Document.Caption
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,197 @@
+package coexistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/tracking"
+	"github.com/piusalfred/whatsapp/webhooks/coexistence"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const historyPayload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "102290129340398",
+    "time": 1739321024,
+    "changes": [{
+      "field": "history",
+      "value": {
+        "messaging_product": "whatsapp",
+        "metadata": {"display_phone_number": "15550783881", "phone_number_id": "106540352242922"},
+        "history": [{
+          "metadata": {"phase": 0, "chunk_order": 1, "progress": 100},
+          "threads": [{
+            "id": "16505551234",
+            "messages": [
+              {
+                "from": "15550783881", "to": "16505551234", "id": "wamid.2", "timestamp": "1739230960",
+                "type": "text", "text": {"body": "Hello, how can we help?"},
+                "history_context": {"status": "READ"}
+              },
+              {
+                "from": "16505551234", "id": "wamid.1", "timestamp": "1739230955",
+                "type": "text", "text": {"body": "Hi"},
+                "history_context": {"status": "DELIVERED"}
+              }
+            ]
+          }]
+        }]
+      }
+    }]
+  }]
+}`
+
+func TestHistoryIngester(t *testing.T) {
+	t.Parallel()
+	store := tracking.NewMemoryStore()
+	var progress []int
+	handlers := &coexistence.Handlers{
+		HistoryHandler: &coexistence.HistoryIngester{
+			Store: store,
+			OnChunk: func(_ context.Context, metadata *coexistence.HistoryMetadata) {
+				progress = append(progress, metadata.Progress)
+			},
+		},
+	}
+
+	var notification coexistence.Notification
+	if err := json.Unmarshal([]byte(historyPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	if response := handlers.HandleNotification(context.TODO(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	records, err := store.List(context.TODO(), &tracking.Query{Contact: "16505551234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		ID        string
+		Direction tracking.Direction
+		Body      string
+		Status    string
+		PhoneID   string
+	}
+
+	got := make([]summary, 0, len(records))
+	for _, r := range records {
+		got = append(got, summary{r.ID, r.Direction, r.Body, r.Status, r.PhoneNumberID})
+	}
+
+	want := []summary{
+		{"wamid.1", tracking.DirectionInbound, "Hi", "delivered", "106540352242922"},
+		{"wamid.2", tracking.DirectionOutbound, "Hello, how can we help?", "read", "106540352242922"},
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]int{100}, progress); diff != "" {
+		t.Errorf("progress mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHistoryIngesterOutOfOrderChunks(t *testing.T) {
+	t.Parallel()
+
+	const (
+		phoneNumberID = "106540352242922"
+		contact       = "16505551234"
+	)
+
+	ctx := context.Background()
+	store := tracking.NewMemoryStore()
+
+	// the live webhooks already saw wamid.3 and moved it to read.
+	readAt := time.Unix(1739231000, 0).UTC()
+	live := &tracking.Record{
+		ID:            "wamid.3",
+		PhoneNumberID: phoneNumberID,
+		Contact:       contact,
+		Direction:     tracking.DirectionOutbound,
+		Type:          "text",
+		Body:          "Your order has shipped",
+		Timestamp:     time.Unix(1739230990, 0).UTC(),
+		Source:        tracking.SourceWebhook,
+	}
+	if err := store.Save(ctx, live); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UpdateStatus(ctx, "wamid.3", "read", readAt); err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := func(order int, messages ...*coexistence.HistoryMessage) *coexistence.History {
+		return &coexistence.History{
+			Metadata: &coexistence.HistoryMetadata{ChunkOrder: order},
+			Threads:  []*coexistence.HistoryThread{{ID: contact, Messages: messages}},
+		}
+	}
+
+	text := func(id, from, ts, body, status string) *coexistence.HistoryMessage {
+		msg := &coexistence.HistoryMessage{HistoryContext: &coexistence.HistoryContext{Status: status}}
+		msg.ID, msg.From, msg.Timestamp, msg.Type = id, from, ts, "text"
+		msg.Text = &message.Text{Body: body}
+
+		return msg
+	}
+
+	ingester := &coexistence.HistoryIngester{Store: store}
+	ntx := &coexistence.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: phoneNumberID}}
+
+	// the second chunk arrives first.
+	second := chunk(2,
+		text("wamid.2", "15550783881", "1739230960", "Hello, how can we help?", "READ"),
+		text("wamid.3", "15550783881", "1739230990", "Your order has shipped", "DELIVERED"),
+	)
+	first := chunk(1, text("wamid.1", contact, "1739227355", "Hi", "DELIVERED"))
+
+	for _, history := range []*coexistence.History{second, first} {
+		if err := ingester.HandleEvent(ctx, ntx, history); err != nil {
+			t.Fatalf("HandleEvent() error = %v", err)
+		}
+	}
+
+	records, err := store.List(ctx, &tracking.Query{Contact: contact})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		ID             string
+		Status         string
+		Source         tracking.Source
+		ConversationID string
+	}
+
+	conversationID := tracking.LocalConversationID(phoneNumberID, contact, time.Unix(1739227355, 0))
+	want := []summary{
+		{"wamid.1", "delivered", tracking.SourceHistory, conversationID},
+		{"wamid.2", "read", tracking.SourceHistory, conversationID},
+		{"wamid.3", "read", tracking.SourceWebhook, conversationID},
+	}
+
+	got := make([]summary, 0, len(records))
+	for _, r := range records {
+		got = append(got, summary{r.ID, r.Status, r.Source, r.ConversationID})
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	if stored, _ := store.Get(ctx, "wamid.3"); !stored.StatusUpdatedAt.Equal(readAt) {
+		t.Errorf("live status updated at %v, want %v", stored.StatusUpdatedAt, readAt)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDedupTTL is how long delivery keys are remembered. WhatsApp retries failed
+// deliveries for up to 7 days but nearly all duplicates arrive within a day.
+const DefaultDedupTTL = 24 * time.Hour
+
+type (
+	// DedupStore remembers the keys of handled deliveries. Claim records key for ttl and
+	// reports whether it was not recorded yet, it must be atomic so that concurrent
+	// deliveries of the same notification are handled once. A Redis implementation maps
+	// Claim to SET key 1 NX PX ttl and Release to DEL key.
+	DedupStore interface {
+		Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+		Release(ctx context.Context, key string) error
+	}
+
+	// DedupKeysFunc returns the keys identifying what a notification delivers, for example
+	// the ids of its messages. A notification without keys is always handled.
+	DedupKeysFunc[T any] func(notification *T) []string
+
+	// DedupStats counts the notifications checked by a Deduplicator, the ones skipped as
+	// duplicates and the store errors, on which notifications are handled anyway.
+	DedupStats struct {
+		Checked    uint64
+		Duplicates uint64
+		Errors     uint64
+	}
+
+	// Deduplicator skips notifications that were already handled. WhatsApp delivers every
+	// notification at least once, and retries it when the webhook is slow or fails, so the
+	// same message or status update can arrive more than once.
+	//
+	// A notification is skipped, and acknowledged with 200, when every one of its keys was
+	// claimed before. Keys are claimed before the handler runs and released when it fails,
+	// so a redelivery after a failure is handled again.
+	Deduplicator struct {
+		store DedupStore
+		ttl   time.Duration
+
+		checked    atomic.Uint64
+		duplicates atomic.Uint64
+		errors     atomic.Uint64
+	}
+)
+
+// NewDeduplicator creates a Deduplicator remembering keys in store for ttl, DefaultDedupTTL
+// when ttl is zero.
+func NewDeduplicator(store DedupStore, ttl time.Duration) *Deduplicator {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+
+	return &Deduplicator{store: store, ttl: ttl}
+}
+
+// Stats returns the counters of the deduplicator.
+func (d *Deduplicator) Stats() DedupStats {
+	return DedupStats{
+		Checked:    d.checked.Load(),
+		Duplicates: d.duplicates.Load(),
+		Errors:     d.errors.Load(),
+	}
+}
+
+// DedupMiddleware skips the notifications d has seen, identifying them with keys.
+//
+//	dedup := webhooks.NewDeduplicator(webhooks.NewMemoryDedupStore(100_000), 0)
+//	listener := webhooks.NewListener(handlers.HandleNotification, reader, opts,
+//		webhooks.DedupMiddleware(dedup, message.DeliveryKeys),
+//	)
+func DedupMiddleware[T any](d *Deduplicator, keys DedupKeysFunc[T]) HandleMiddleware[T] {
+	return func(next NotificationHandlerFunc[T]) NotificationHandlerFunc[T] {
+		return func(ctx context.Context, notification *T) *Response {
+			claimed, duplicate := d.claim(ctx, keys(notification))
+			if duplicate {
+				return &Response{StatusCode: http.StatusOK}
+			}
+
+			response := next(ctx, notification)
+			if response == nil || response.StatusCode >= http.StatusMultipleChoices {
+				d.release(ctx, claimed)
+			}
+
+			return response
+		}
+	}
+}
+
+// claim claims every key and returns the ones it claimed. The notification is a duplicate
+// when there were keys and none of them could be claimed.
+func (d *Deduplicator) claim(ctx context.Context, keys []string) ([]string, bool) {
+	if len(keys) == 0 {
+		return nil, false
+	}
+
+	d.checked.Add(1)
+
+	claimed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ok, err := d.store.Claim(ctx, key, d.ttl)
+		if err != nil {
+			// fail open: a duplicate is better than a lost notification.
+			d.errors.Add(1)
+
+			return claimed, false
+		}
+
+		if ok {
+			claimed = append(claimed, key)
+		}
+	}
+
+	if len(claimed) == 0 {
+		d.duplicates.Add(1)
+
+		return nil, true
+	}
+
+	return claimed, false
+}
+
+func (d *Deduplicator) release(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := d.store.Release(ctx, key); err != nil {
+			d.errors.Add(1)
+		}
+	}
+}
+
+// MemoryDedupStore is a DedupStore kept in memory. It holds up to capacity keys and
+// evicts the least recently claimed ones first, so memory stays bounded during bursts.
+// Keys are lost on restart and are not shared between instances, use a shared store like
+// Redis when running more than one listener.
+type MemoryDedupStore struct {
+	mu       sync.Mutex
+	capacity int
+	now      func() time.Time
+	order    *list.List
+	keys     map[string]*list.Element
+}
+
+type dedupEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+type MemoryDedupOption func(*MemoryDedupStore)
+
+func WithMemoryDedupClock(now func() time.Time) MemoryDedupOption {
+	return func(s *MemoryDedupStore) {
+		s.now = now
+	}
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore holding up to capacity keys.
+func NewMemoryDedupStore(capacity int, options ...MemoryDedupOption) *MemoryDedupStore {
+	store := &MemoryDedupStore{
+		capacity: max(capacity, 1),
+		now:      time.Now,
+		order:    list.New(),
+		keys:     make(map[string]*list.Element),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(store)
+		}
+	}
+
+	return store
+}
+
+func (s *MemoryDedupStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if element, ok := s.keys[key]; ok {
+		entry, _ := element.Value.(*dedupEntry)
+		if now.Before(entry.expiresAt) {
+			return false, nil
+		}
+
+		s.order.Remove(element)
+		delete(s.keys, key)
+	}
+
+	for s.order.Len() >= s.capacity {
+		oldest := s.order.Back()
+		entry, _ := oldest.Value.(*dedupEntry)
+		s.order.Remove(oldest)
+		delete(s.keys, entry.key)
+	}
+
+	s.keys[key] = s.order.PushFront(&dedupEntry{key: key, expiresAt: now.Add(ttl)})
+
+	return true, nil
+}
+
+func (s *MemoryDedupStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.keys[key]; ok {
+		s.order.Remove(element)
+		delete(s.keys, key)
+	}
+
+	return nil
+}
+
+// Len returns the number of keys held, including expired ones not evicted yet.
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+var _ DedupStore = (*MemoryDedupStore)(nil)
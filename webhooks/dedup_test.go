@@ -0,0 +1,76 @@
+package webhooks_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+type delivery struct {
+	IDs  []string
+	Fail bool
+}
+
+func TestDedupMiddleware(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	store := webhooks.NewMemoryDedupStore(3, webhooks.WithMemoryDedupClock(func() time.Time { return now }))
+	dedup := webhooks.NewDeduplicator(store, time.Hour)
+
+	handled := 0
+	handler := webhooks.DedupMiddleware(dedup, func(d *delivery) []string { return d.IDs })(
+		func(_ context.Context, d *delivery) *webhooks.Response {
+			handled++
+			if d.Fail {
+				return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+			}
+
+			return &webhooks.Response{StatusCode: http.StatusOK}
+		})
+
+	ctx := context.Background()
+	steps := []struct {
+		name    string
+		ids     []string
+		fail    bool
+		handled int
+	}{
+		{name: "first delivery", ids: []string{"a"}, handled: 1},
+		{name: "redelivery", ids: []string{"a"}, handled: 1},
+		{name: "partly new", ids: []string{"a", "b"}, handled: 2},
+		{name: "failed", ids: []string{"c"}, fail: true, handled: 3},
+		{name: "retried after failure", ids: []string{"c"}, handled: 4},
+		{name: "no keys", handled: 5},
+	}
+
+	for _, step := range steps {
+		response := handler(ctx, &delivery{IDs: step.ids, Fail: step.fail})
+		if handled != step.handled {
+			t.Fatalf("%s: handled = %d, want %d", step.name, handled, step.handled)
+		}
+
+		if !step.fail && response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d", step.name, response.StatusCode)
+		}
+	}
+
+	stats := dedup.Stats()
+	if stats.Checked != 5 || stats.Duplicates != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// the store is full with a, b and c: claiming d evicts a.
+	handler(ctx, &delivery{IDs: []string{"d"}})
+	if handler(ctx, &delivery{IDs: []string{"a"}}); handled != 7 || store.Len() != 3 {
+		t.Errorf("after eviction handled = %d, keys = %d", handled, store.Len())
+	}
+
+	now = now.Add(2 * time.Hour)
+	if handler(ctx, &delivery{IDs: []string{"d"}}); handled != 8 {
+		t.Errorf("after the ttl handled = %d, want the key to have expired", handled)
+	}
+}
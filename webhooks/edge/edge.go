@@ -0,0 +1,256 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package edge is the dependency light core of webhook processing. It verifies payload
+// signatures and decodes the notification envelope without net/http or any other package
+// of this module, so a filter program importing it compiles with TinyGo for WASI and
+// WebAssembly targets. The module does not ship such a program.
+//
+// Edge runtimes and proxies can use it to reject forged requests and drop events the
+// backend is not interested in before forwarding the original payload. Change values and
+// messages are kept raw: the notification models still live in the webhooks packages, such
+// as webhooks/message, and the backend decodes the forwarded payload with them.
+package edge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// SignatureHeader is the header carrying the payload signature.
+	SignatureHeader = "X-Hub-Signature-256"
+
+	signaturePrefix = "sha256="
+)
+
+// Verify checks the value of the X-Hub-Signature-256 header, "sha256=" followed by the
+// hex encoded HMAC-SHA256 of payload keyed with the app secret. It is the only verifier of
+// that header in this module, webhooks.ValidateSignature uses it too.
+func Verify(payload []byte, header, appSecret string) error {
+	if !strings.HasPrefix(header, signaturePrefix) {
+		return ErrSignatureNotFound
+	}
+
+	return VerifySignature(payload, header[len(signaturePrefix):], appSecret)
+}
+
+// VerifySignature checks a hex encoded HMAC-SHA256 signature without the "sha256=" prefix.
+func VerifySignature(payload []byte, signature, appSecret string) error {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrMalformedSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(payload)
+
+	if !hmac.Equal(decoded, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Sign returns the X-Hub-Signature-256 header value for payload.
+func Sign(payload []byte, appSecret string) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+type (
+	// Notification is the envelope shared by all webhook notifications.
+	Notification struct {
+		Object string  `json:"object"`
+		Entry  []Entry `json:"entry"`
+	}
+
+	Entry struct {
+		ID      string   `json:"id"`
+		Time    int64    `json:"time,omitempty"`
+		Changes []Change `json:"changes"`
+	}
+
+	Change struct {
+		Field string          `json:"field"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	// Event summarizes one change for filtering. The message and status fields are only
+	// set for the messages and smb_message_echoes fields.
+	Event struct {
+		EntryID       string
+		Field         string
+		PhoneNumberID string
+		Messages      []MessageHeader
+		Statuses      []StatusHeader
+		Value         json.RawMessage
+	}
+
+	MessageHeader struct {
+		ID        string `json:"id"`
+		From      string `json:"from"`
+		Type      string `json:"type"`
+		Timestamp string `json:"timestamp"`
+	}
+
+	StatusHeader struct {
+		ID          string `json:"id"`
+		Status      string `json:"status"`
+		RecipientID string `json:"recipient_id"`
+	}
+
+	changeValue struct {
+		Metadata struct {
+			PhoneNumberID string `json:"phone_number_id"`
+		} `json:"metadata"`
+		Messages      []MessageHeader `json:"messages"`
+		MessageEchoes []MessageHeader `json:"message_echoes"`
+		Statuses      []StatusHeader  `json:"statuses"`
+	}
+)
+
+// Decode decodes the notification envelope and returns one Event per change.
+func Decode(payload []byte) (*Notification, []*Event, error) {
+	var notification Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return nil, nil, ErrDecode
+	}
+
+	var events []*Event
+	for _, entry := range notification.Entry {
+		for _, change := range entry.Changes {
+			event := &Event{EntryID: entry.ID, Field: change.Field, Value: change.Value}
+
+			var value changeValue
+			if len(change.Value) > 0 && json.Unmarshal(change.Value, &value) == nil {
+				event.PhoneNumberID = value.Metadata.PhoneNumberID
+				event.Messages = append(value.Messages, value.MessageEchoes...)
+				event.Statuses = value.Statuses
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	return &notification, events, nil
+}
+
+// Filter reports whether an event should be forwarded.
+type Filter func(event *Event) bool
+
+// FieldIn matches events of the given change fields.
+func FieldIn(fields ...string) Filter {
+	return func(event *Event) bool {
+		return contains(fields, event.Field)
+	}
+}
+
+// PhoneNumberIDIn matches events for the given business phone numbers.
+func PhoneNumberIDIn(ids ...string) Filter {
+	return func(event *Event) bool {
+		return contains(ids, event.PhoneNumberID)
+	}
+}
+
+// MessageTypeIn matches events with at least one message of the given types.
+func MessageTypeIn(types ...string) Filter {
+	return func(event *Event) bool {
+		for _, msg := range event.Messages {
+			if contains(types, msg.Type) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not inverts a filter.
+func Not(filter Filter) Filter {
+	return func(event *Event) bool {
+		return !filter(event)
+	}
+}
+
+// Match reports whether any event passes all the filters.
+func Match(events []*Event, filters ...Filter) bool {
+	for _, event := range events {
+		matched := true
+		for _, filter := range filters {
+			if !filter(event) {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Inspect verifies the signature header when appSecret is not empty, decodes the payload
+// and reports whether it should be forwarded according to filters.
+func Inspect(payload []byte, header, appSecret string, filters ...Filter) (bool, error) {
+	if appSecret != "" {
+		if err := Verify(payload, header, appSecret); err != nil {
+			return false, err
+		}
+	}
+
+	_, events, err := Decode(payload)
+	if err != nil {
+		return false, err
+	}
+
+	return Match(events, filters...), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// edgeError is a custom error type for edge errors.
+type edgeError string
+
+func (e edgeError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrSignatureNotFound  = edgeError("signature not found")
+	ErrMalformedSignature = edgeError("signature is not hex encoded")
+	ErrInvalidSignature   = edgeError("signature is invalid")
+	ErrDecode             = edgeError("could not decode notification")
+)
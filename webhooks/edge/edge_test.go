@@ -0,0 +1,103 @@
+package edge_test
+
+import (
+	"errors"
+	"go/build"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/webhooks/edge"
+)
+
+const payload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": "waba",
+    "changes": [
+      {"field": "messages", "value": {"metadata": {"phone_number_id": "111"}, "messages": [{"id": "wamid.1", "from": "255700", "type": "image", "timestamp": "1700000000"}]}},
+      {"field": "messages", "value": {"metadata": {"phone_number_id": "222"}, "statuses": [{"id": "wamid.2", "status": "read", "recipient_id": "255701"}]}},
+      {"field": "account_update", "value": {"event": "VERIFIED_ACCOUNT"}}
+    ]
+  }]
+}`
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	header := edge.Sign([]byte(payload), "secret")
+
+	tests := []struct {
+		name    string
+		header  string
+		secret  string
+		wantErr error
+	}{
+		{name: "valid", header: header, secret: "secret"},
+		{name: "wrong secret", header: header, secret: "other", wantErr: edge.ErrInvalidSignature},
+		{name: "missing prefix", header: strings.TrimPrefix(header, "sha256="), secret: "secret", wantErr: edge.ErrSignatureNotFound},
+		{name: "not hex", header: "sha256=zz", secret: "secret", wantErr: edge.ErrMalformedSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := edge.Verify([]byte(payload), tt.header, tt.secret); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	header := edge.Sign([]byte(payload), "secret")
+
+	tests := []struct {
+		name    string
+		filters []edge.Filter
+		want    bool
+	}{
+		{name: "no filters", want: true},
+		{name: "field", filters: []edge.Filter{edge.FieldIn("account_update")}, want: true},
+		{name: "message type", filters: []edge.Filter{edge.MessageTypeIn("image")}, want: true},
+		{name: "type on other number", filters: []edge.Filter{edge.PhoneNumberIDIn("222"), edge.MessageTypeIn("image")}},
+		{name: "negated", filters: []edge.Filter{edge.Not(edge.FieldIn("messages", "account_update"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := edge.Inspect([]byte(payload), header, "secret", tt.filters...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Inspect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := edge.Inspect([]byte("{"), "", ""); !errors.Is(err, edge.ErrDecode) {
+		t.Errorf("Inspect() error = %v, want %v", err, edge.ErrDecode)
+	}
+}
+
+// TestImports keeps the package within what TinyGo can compile.
+func TestImports(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, imp := range pkg.Imports {
+		if strings.Contains(imp, ".") || strings.HasPrefix(imp, "net") {
+			t.Errorf("unexpected import %q", imp)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package events normalizes message webhook notifications into flat events and fans them
+// out to subscribers, so that downstream services can consume them without knowing the
+// nesting of the notification payloads.
+//
+// An Event carries the business and customer it is about next to the original message,
+// status or error. Events are produced by Normalize, and a Hub delivers them to any number
+// of subscriptions. The Hub is transport agnostic: the optional extras/grpc module exposes
+// it as a gRPC stream and the webhooks/publish package forwards events to message brokers.
+package events
+
+import (
+	"strconv"
+	"time"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const (
+	KindMessage Kind = "message"
+	KindStatus  Kind = "status"
+	KindError   Kind = "error"
+)
+
+type (
+	// Kind tells what an Event carries.
+	Kind string
+
+	// Event is a normalized webhook event. WaID is the customer the event is about: the
+	// sender of a message or the recipient of a status. Only the field matching Kind among
+	// Message, Status and Error is set.
+	Event struct {
+		ID                 string           `json:"id"`
+		Kind               Kind             `json:"kind"`
+		BusinessAccountID  string           `json:"business_account_id,omitempty"`
+		PhoneNumberID      string           `json:"phone_number_id,omitempty"`
+		DisplayPhoneNumber string           `json:"display_phone_number,omitempty"`
+		WaID               string           `json:"wa_id,omitempty"`
+		ProfileName        string           `json:"profile_name,omitempty"`
+		Timestamp          time.Time        `json:"timestamp"`
+		Message            *message.Message `json:"message,omitempty"`
+		Status             *message.Status  `json:"status,omitempty"`
+		Error              *werrors.Error   `json:"error,omitempty"`
+	}
+)
+
+// Key returns the key events are partitioned by so that the events of one customer stay
+// ordered: the WaID, or the PhoneNumberID for errors not tied to a customer.
+func (e *Event) Key() string {
+	if e.WaID != "" {
+		return e.WaID
+	}
+
+	return e.PhoneNumberID
+}
+
+// Normalize flattens the messages, statuses and errors of the messages changes of a
+// notification into events, in payload order. Other change fields are skipped.
+func Normalize(notification *message.Notification) []*Event {
+	if notification == nil {
+		return nil
+	}
+
+	var events []*Event
+	for _, entry := range notification.Entry {
+		if entry == nil {
+			continue
+		}
+
+		for _, change := range entry.Changes {
+			if change == nil || change.Value == nil || change.Field != message.ChangeFieldMessages {
+				continue
+			}
+
+			events = append(events, normalizeValue(entry, change.Value)...)
+		}
+	}
+
+	return events
+}
+
+func normalizeValue(entry *message.Entry, value *message.Value) []*Event {
+	base := Event{BusinessAccountID: entry.ID}
+	if entry.Time > 0 {
+		base.Timestamp = time.Unix(entry.Time, 0).UTC()
+	}
+	if value.Metadata != nil {
+		base.PhoneNumberID = value.Metadata.PhoneNumberID
+		base.DisplayPhoneNumber = value.Metadata.DisplayPhoneNumber
+	}
+
+	names := make(map[string]string, len(value.Contacts))
+	for _, contact := range value.Contacts {
+		if contact != nil && contact.Profile != nil {
+			names[contact.WaID] = contact.Profile.Name
+		}
+	}
+
+	events := make([]*Event, 0, len(value.Messages)+len(value.Statuses)+len(value.Errors))
+	for _, msg := range value.Messages {
+		if msg == nil {
+			continue
+		}
+
+		event := base
+		event.ID = msg.ID
+		event.Kind = KindMessage
+		event.WaID = msg.From
+		event.ProfileName = names[msg.From]
+		event.Timestamp = parseTimestamp(msg.Timestamp, base.Timestamp)
+		event.Message = msg
+		events = append(events, &event)
+	}
+
+	for _, status := range value.Statuses {
+		if status == nil {
+			continue
+		}
+
+		event := base
+		event.ID = status.ID + ":" + status.StatusValue
+		event.Kind = KindStatus
+		event.WaID = status.RecipientID
+		if status.Timestamp > 0 {
+			event.Timestamp = time.Unix(status.Timestamp, 0).UTC()
+		}
+		event.Status = status
+		events = append(events, &event)
+	}
+
+	for i, apiErr := range value.Errors {
+		if apiErr == nil {
+			continue
+		}
+
+		event := base
+		event.ID = entry.ID + ":error:" + strconv.Itoa(apiErr.Code) + ":" + strconv.Itoa(i)
+		event.Kind = KindError
+		event.Error = apiErr
+		events = append(events, &event)
+	}
+
+	return events
+}
+
+func parseTimestamp(value string, fallback time.Time) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return fallback
+	}
+
+	return time.Unix(seconds, 0).UTC()
+}
@@ -0,0 +1,123 @@
+package events_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/events"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func testNotification() *message.Notification {
+	return &message.Notification{Object: "whatsapp_business_account", Entry: []*message.Entry{{
+		ID:   "waba",
+		Time: 1700000000,
+		Changes: []*message.Change{
+			{
+				Field: message.ChangeFieldMessages,
+				Value: &message.Value{
+					Metadata: &message.Metadata{PhoneNumberID: "phone", DisplayPhoneNumber: "15550001111"},
+					Contacts: []*message.Contact{{WaID: "255700000001", Profile: &message.Profile{Name: "Asha"}}},
+					Messages: []*message.Message{{
+						From: "255700000001", ID: "wamid.in", Timestamp: "1700000010", Type: "text",
+						Text: &message.Text{Body: "hi"},
+					}},
+					Statuses: []*message.Status{{
+						ID: "wamid.out", StatusValue: "read", RecipientID: "255700000002", Timestamp: 1700000020,
+					}},
+					Errors: []*werrors.Error{{Code: 131000, Message: "Something went wrong"}},
+				},
+			},
+			{Field: "account_update", Value: &message.Value{}},
+		},
+	}}}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	got := events.Normalize(testNotification())
+
+	type summary struct {
+		ID, Kind, WaID, Name, PhoneNumberID, Key string
+		Timestamp                                int64
+	}
+
+	summaries := make([]summary, 0, len(got))
+	for _, event := range got {
+		summaries = append(summaries, summary{
+			ID: event.ID, Kind: string(event.Kind), WaID: event.WaID, Name: event.ProfileName,
+			PhoneNumberID: event.PhoneNumberID, Key: event.Key(), Timestamp: event.Timestamp.Unix(),
+		})
+	}
+
+	want := []summary{
+		{"wamid.in", "message", "255700000001", "Asha", "phone", "255700000001", 1700000010},
+		{"wamid.out:read", "status", "255700000002", "", "phone", "255700000002", 1700000020},
+		{"waba:error:131000:0", "error", "", "", "phone", "phone", 1700000000},
+	}
+
+	if diff := gcmp.Diff(want, summaries); diff != "" {
+		t.Errorf("Normalize() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got[0].Message.Text.Body != "hi" || got[1].Status.StatusValue != "read" || got[2].Error.Code != 131000 {
+		t.Errorf("payloads not carried: %+v", got)
+	}
+}
+
+func TestHub(t *testing.T) {
+	t.Parallel()
+
+	hub := events.NewHub(events.WithSubscriptionBuffer(1))
+	all := hub.Subscribe(nil)
+	statuses := hub.Subscribe(&events.Filter{Kinds: []events.Kind{events.KindStatus}})
+
+	var handled bool
+	handler := hub.Middleware()(func(context.Context, *message.Notification) *webhooks.Response {
+		handled = true
+
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	})
+
+	response := handler(context.Background(), testNotification())
+	if response.StatusCode != http.StatusOK || !handled {
+		t.Fatalf("next handler not called: %+v", response)
+	}
+
+	receive := func(s *events.Subscription) *events.Event {
+		select {
+		case event := <-s.Events():
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no event received")
+
+			return nil
+		}
+	}
+
+	if event := receive(all); event.Kind != events.KindMessage || all.Dropped() != 2 {
+		t.Errorf("all: first event %s, dropped %d", event.Kind, all.Dropped())
+	}
+
+	if event := receive(statuses); event.Kind != events.KindStatus || statuses.Dropped() != 0 {
+		t.Errorf("statuses: first event %s, dropped %d", event.Kind, statuses.Dropped())
+	}
+
+	statuses.Close()
+	hub.Close()
+
+	if _, ok := <-all.Events(); ok {
+		t.Error("subscription still open after Hub.Close")
+	}
+
+	if _, ok := <-hub.Subscribe(nil).Events(); ok {
+		t.Error("subscription to a closed hub is open")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package events
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// DefaultSubscriptionBuffer is the number of events a subscription holds before events are
+// dropped for it, unless configured otherwise.
+const DefaultSubscriptionBuffer = 256
+
+type (
+	// Filter selects the events a subscription receives. Empty fields match every event.
+	Filter struct {
+		Kinds          []Kind
+		PhoneNumberIDs []string
+		WaIDs          []string
+	}
+
+	HubOption func(*Hub)
+
+	// Hub fans normalized events out to subscriptions. Publishing never blocks: a
+	// subscription whose buffer is full misses the event, which is counted in its Dropped,
+	// so a slow consumer cannot hold up the webhook.
+	Hub struct {
+		buffer int
+
+		mu            sync.RWMutex
+		subscriptions map[*Subscription]struct{}
+		closed        bool
+	}
+
+	// Subscription receives the events matching its Filter until it is closed.
+	Subscription struct {
+		hub     *Hub
+		filter  Filter
+		events  chan *Event
+		dropped atomic.Uint64
+		once    sync.Once
+	}
+)
+
+// WithSubscriptionBuffer sets the buffer size of new subscriptions.
+func WithSubscriptionBuffer(size int) HubOption {
+	return func(h *Hub) {
+		h.buffer = max(size, 0)
+	}
+}
+
+// NewHub creates a Hub.
+func NewHub(options ...HubOption) *Hub {
+	h := &Hub{
+		buffer:        DefaultSubscriptionBuffer,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(h)
+		}
+	}
+
+	return h
+}
+
+// Match reports whether the event passes the filter.
+func (f *Filter) Match(event *Event) bool {
+	if f == nil {
+		return true
+	}
+
+	return matchAny(f.Kinds, event.Kind) &&
+		matchAny(f.PhoneNumberIDs, event.PhoneNumberID) &&
+		matchAny(f.WaIDs, event.WaID)
+}
+
+func matchAny[T comparable](allowed []T, value T) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, value)
+}
+
+// Subscribe starts a subscription to the events matching filter, nil matches all events.
+// The subscription of a closed Hub is closed straight away.
+func (h *Hub) Subscribe(filter *Filter) *Subscription {
+	s := &Subscription{hub: h, events: make(chan *Event, h.buffer)}
+	if filter != nil {
+		s.filter = *filter
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		s.once.Do(func() { close(s.events) })
+
+		return s
+	}
+
+	h.subscriptions[s] = struct{}{}
+
+	return s
+}
+
+// Publish delivers events to the matching subscriptions.
+func (h *Hub) Publish(events ...*Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, event := range events {
+		for s := range h.subscriptions {
+			if !s.filter.Match(event) {
+				continue
+			}
+
+			select {
+			case s.events <- event:
+			default:
+				s.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// HandleNotification publishes the events of notification. It can be used as the handler
+// of a webhooks.Listener when the Hub is the only consumer.
+func (h *Hub) HandleNotification(_ context.Context, notification *message.Notification) *webhooks.Response {
+	h.Publish(Normalize(notification)...)
+
+	return &webhooks.Response{StatusCode: http.StatusOK}
+}
+
+// Middleware publishes the events of every notification before it is handled by the next
+// handler, so the Hub can be added next to existing handlers.
+func (h *Hub) Middleware() webhooks.HandleMiddleware[message.Notification] {
+	return func(next webhooks.NotificationHandlerFunc[message.Notification],
+	) webhooks.NotificationHandlerFunc[message.Notification] {
+		return func(ctx context.Context, notification *message.Notification) *webhooks.Response {
+			h.Publish(Normalize(notification)...)
+
+			return next(ctx, notification)
+		}
+	}
+}
+
+// Close closes all subscriptions. Later subscriptions are closed straight away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for s := range h.subscriptions {
+		delete(h.subscriptions, s)
+		s.once.Do(func() { close(s.events) })
+	}
+}
+
+// Events returns the channel of events, closed when the subscription is closed.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Dropped returns the number of events missed because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	delete(s.hub.subscriptions, s)
+	s.once.Do(func() { close(s.events) })
+}
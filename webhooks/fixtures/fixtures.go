@@ -0,0 +1,708 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package fixtures generates sample webhook payloads from the notification types used by
+// the webhooks packages. Every fixture is built from the same structs the handlers decode
+// into, so a payload produced here always matches what the decoders expect.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/business"
+	"github.com/piusalfred/whatsapp/webhooks/coexistence"
+	"github.com/piusalfred/whatsapp/webhooks/flow"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const (
+	ObjectWhatsAppBusinessAccount = "whatsapp_business_account"
+	MessagingProduct              = "whatsapp"
+)
+
+// Fixture is a sample notification for one change field and, for message notifications,
+// one message type.
+type Fixture struct {
+	// Name identifies the fixture, for example "messages/text" or "statuses/read".
+	Name  string
+	Field string
+
+	// Notification is a *message.Notification, *business.Notification,
+	// *flow.Notification or *coexistence.Notification.
+	Notification any
+}
+
+// JSON returns the indented JSON payload of the fixture.
+func (f *Fixture) JSON() ([]byte, error) {
+	payload, err := json.MarshalIndent(f.Notification, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", f.Name, err)
+	}
+
+	return payload, nil
+}
+
+// Generator produces fixtures with fake but realistic values. Generators created with the
+// same seed and time produce the same fixtures.
+type Generator struct {
+	rand          *rand.Rand
+	now           time.Time
+	wabaID        string
+	phoneNumberID string
+	businessPhone string
+	customerPhone string
+}
+
+func NewGenerator(seed uint64, now time.Time) *Generator {
+	g := &Generator{
+		rand: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)), //nolint:gosec // fake data
+		now:  now.UTC(),
+	}
+
+	g.wabaID = g.digits(15)
+	g.phoneNumberID = g.digits(15)
+	g.businessPhone = "1555" + g.digits(7)
+	g.customerPhone = "2557" + g.digits(8)
+
+	return g
+}
+
+// All returns every fixture the generator knows about, sorted by name.
+func (g *Generator) All() []*Fixture {
+	var all []*Fixture
+	all = append(all, g.Messages()...)
+	all = append(all, g.Statuses()...)
+	all = append(all, g.MessageEcho())
+	all = append(all, g.StateSync(), g.History())
+	all = append(all, g.Business()...)
+	all = append(all, g.Flows()...)
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	return all
+}
+
+// MessageTypes lists the inbound message types Messages generates fixtures for.
+func MessageTypes() []string {
+	return []string{
+		"audio", "button", "contacts", "document", "image", "interactive.button_reply",
+		"interactive.list_reply", "interactive.nfm_reply", "location", "order", "reaction",
+		"sticker", "system", "text", "video",
+	}
+}
+
+// Messages returns one "messages" notification per message type.
+func (g *Generator) Messages() []*Fixture {
+	types := MessageTypes()
+	fixtures := make([]*Fixture, 0, len(types))
+	for _, typ := range types {
+		msg := g.inboundMessage(typ)
+		value := g.messageValue()
+		value.Contacts = []*message.Contact{{Profile: &message.Profile{Name: g.name()}, WaID: g.customerPhone}}
+		value.Messages = []*message.Message{msg}
+
+		fixtures = append(fixtures, &Fixture{
+			Name:         "messages/" + typ,
+			Field:        message.ChangeFieldMessages,
+			Notification: g.messageNotification(message.ChangeFieldMessages, value),
+		})
+	}
+
+	return fixtures
+}
+
+// Statuses returns a status notification for sent, delivered, read and failed messages.
+func (g *Generator) Statuses() []*Fixture {
+	statuses := []string{"sent", "delivered", "read", "failed"}
+	fixtures := make([]*Fixture, 0, len(statuses))
+	for _, status := range statuses {
+		s := &message.Status{
+			ID:          g.wamid(),
+			RecipientID: g.customerPhone,
+			StatusValue: status,
+			Timestamp:   g.now.Unix(),
+		}
+
+		switch status {
+		case "failed":
+			s.Errors = g.errors()
+		case "read":
+		default:
+			s.Conversation = &message.Conversation{
+				ID:     g.hex(32),
+				Origin: &message.ConversationOrigin{Type: "service"},
+				Expiry: int(g.now.Add(24 * time.Hour).Unix()),
+			}
+			s.Pricing = &message.Pricing{Billable: true, Category: "service", PricingModel: "CBP"}
+		}
+
+		value := g.messageValue()
+		value.Statuses = []*message.Status{s}
+		fixtures = append(fixtures, &Fixture{
+			Name:         "statuses/" + status,
+			Field:        message.ChangeFieldMessages,
+			Notification: g.messageNotification(message.ChangeFieldMessages, value),
+		})
+	}
+
+	return fixtures
+}
+
+// MessageEcho returns a smb_message_echoes notification for a text sent from the
+// WhatsApp Business app.
+func (g *Generator) MessageEcho() *Fixture {
+	msg := g.inboundMessage("text")
+	msg.From = g.businessPhone
+
+	value := g.messageValue()
+	value.MessageEchoes = []*message.MessageEcho{{Message: *msg, To: g.customerPhone}}
+
+	return &Fixture{
+		Name:         message.ChangeFieldSMBMessageEchoes,
+		Field:        message.ChangeFieldSMBMessageEchoes,
+		Notification: g.messageNotification(message.ChangeFieldSMBMessageEchoes, value),
+	}
+}
+
+// StateSync returns a smb_app_state_sync notification adding a contact.
+func (g *Generator) StateSync() *Fixture {
+	first, last := g.pick(firstNames), g.pick(lastNames)
+	value := &coexistence.Value{
+		MessagingProduct: MessagingProduct,
+		Metadata:         g.metadata(),
+		StateSync: []*coexistence.StateSync{{
+			Type:   "contact",
+			Action: coexistence.StateSyncActionAdd,
+			Contact: &coexistence.StateSyncContact{
+				FullName:    first + " " + last,
+				FirstName:   first,
+				PhoneNumber: g.customerPhone,
+			},
+			Metadata: &coexistence.StateSyncMetadata{Timestamp: g.timestamp()},
+		}},
+	}
+
+	return g.coexistenceFixture(coexistence.FieldSMBAppStateSync, value)
+}
+
+// History returns a history notification with one thread of two messages.
+func (g *Generator) History() *Fixture {
+	inbound := g.inboundMessage("text")
+	outbound := g.inboundMessage("text")
+	outbound.From = g.businessPhone
+
+	value := &coexistence.Value{
+		MessagingProduct: MessagingProduct,
+		Metadata:         g.metadata(),
+		History: []*coexistence.History{{
+			Metadata: &coexistence.HistoryMetadata{Phase: 0, ChunkOrder: 1, Progress: 100}, //nolint:mnd // sample
+			Threads: []*coexistence.HistoryThread{{
+				ID: g.customerPhone,
+				Messages: []*coexistence.HistoryMessage{
+					{Message: *inbound, HistoryContext: &coexistence.HistoryContext{Status: "READ"}},
+					{Message: *outbound, To: g.customerPhone, HistoryContext: &coexistence.HistoryContext{Status: "DELIVERED"}},
+				},
+			}},
+		}},
+	}
+
+	return g.coexistenceFixture(coexistence.FieldHistory, value)
+}
+
+// businessFields maps the account and template change fields to the value keys they carry.
+var businessFields = map[string][]string{
+	"message_template_status_update":  {"event", "message_template_id", "message_template_name", "message_template_language", "reason"},
+	"message_template_quality_update": {"message_template_id", "message_template_name", "message_template_language"},
+	"template_category_update":        {"message_template_id", "message_template_name", "message_template_language", "previous_category", "new_category"},
+	"phone_number_quality_update":     {"display_phone_number", "event", "current_limit"},
+	"phone_number_name_update":        {"display_phone_number", "decision", "requested_verified_name", "rejection_reason"},
+	"account_update":                  {"event", "ban_info", "restriction_info", "violation_info"},
+	"account_review_update":           {"decision"},
+	"business_capability_update":      {"max_daily_conversation_per_phone", "max_phone_numbers_per_business", "max_phone_numbers_per_waba"},
+}
+
+// businessEvents overrides the generated event of the change fields that report one.
+var businessEvents = map[string]string{
+	"message_template_status_update": "APPROVED",
+	"phone_number_quality_update":    "UPGRADE",
+	"account_update":                 "ACCOUNT_VIOLATION",
+}
+
+// Business returns one notification per account and template change field.
+func (g *Generator) Business() []*Fixture {
+	fields := make([]string, 0, len(businessFields))
+	for field := range businessFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	fixtures := make([]*Fixture, 0, len(fields))
+	for _, field := range fields {
+		value := &business.Value{}
+		g.Fill(value)
+		keepJSONFields(value, businessFields[field])
+		if event, ok := businessEvents[field]; ok {
+			value.Event = event
+		}
+
+		fixtures = append(fixtures, &Fixture{
+			Name:  field,
+			Field: field,
+			Notification: &business.Notification{
+				Object: ObjectWhatsAppBusinessAccount,
+				Entry: []business.Entry{{
+					ID:      g.wabaID,
+					Time:    g.now.Unix(),
+					Changes: []business.Change{{Field: field, Value: value}},
+				}},
+			},
+		})
+	}
+
+	return fixtures
+}
+
+// flowEvents maps flow events to the value keys they carry.
+var flowEvents = map[string][]string{
+	flow.EventFlowStatusChange:     {"event", "message", "flow_id", "old_status", "new_status"},
+	flow.EventClientErrorRate:      {"event", "message", "flow_id", "error_rate", "threshold", "alert_state", "errors"},
+	flow.EventEndpointErrorRate:    {"event", "message", "flow_id", "error_rate", "threshold", "alert_state", "errors"},
+	flow.EventEndpointLatency:      {"event", "message", "flow_id", "p50_latency", "p90_latency", "requests_count", "threshold", "alert_state"},
+	flow.EventEndpointAvailability: {"event", "message", "flow_id", "availability", "threshold", "alert_state"},
+}
+
+// Flows returns one "flows" notification per flow event.
+func (g *Generator) Flows() []*Fixture {
+	events := make([]string, 0, len(flowEvents))
+	for event := range flowEvents {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	fixtures := make([]*Fixture, 0, len(events))
+	for _, event := range events {
+		value := &flow.Value{}
+		g.Fill(value)
+		keepJSONFields(value, flowEvents[event])
+		value.Event = event
+
+		switch event {
+		case flow.EventFlowStatusChange:
+			value.OldStatus, value.NewStatus = flow.StatusDraft, flow.StatusPublished
+		case flow.EventClientErrorRate, flow.EventEndpointErrorRate:
+			value.Errors = []flow.ErrorInfo{{
+				ErrorType:  g.stringFor("error_type"),
+				ErrorRate:  value.ErrorRate,
+				ErrorCount: g.intFor("error_count"),
+			}}
+		}
+
+		fixtures = append(fixtures, &Fixture{
+			Name:  "flows/" + strings.ToLower(event),
+			Field: "flows",
+			Notification: &flow.Notification{
+				Object: ObjectWhatsAppBusinessAccount,
+				Entry: []*flow.Entry{{
+					ID:      g.wabaID,
+					Time:    g.now.Unix(),
+					Changes: []*flow.Changes{{Field: "flows", Value: value}},
+				}},
+			},
+		})
+	}
+
+	return fixtures
+}
+
+// mediaMimeTypes are the MIME types used for media messages, documents use the default.
+var mediaMimeTypes = map[string]string{
+	"audio":   "audio/ogg; codecs=opus",
+	"image":   "image/jpeg",
+	"sticker": "image/webp",
+	"video":   "video/mp4",
+}
+
+func (g *Generator) inboundMessage(typ string) *message.Message {
+	msg := &message.Message{
+		From:      g.customerPhone,
+		ID:        g.wamid(),
+		Timestamp: g.timestamp(),
+	}
+
+	base, sub, _ := strings.Cut(typ, ".")
+	msg.Type = base
+
+	rv := reflect.ValueOf(msg).Elem()
+	for i := range rv.NumField() {
+		if jsonName(rv.Type().Field(i)) == base {
+			g.fill(rv.Field(i), base, 0)
+		}
+	}
+
+	switch base {
+	case "interactive":
+		msg.Interactive = g.interactive(sub)
+	case "system":
+		msg.System = &message.System{
+			Type:    "user_changed_number",
+			WaID:    g.customerPhone,
+			NewWaID: "2556" + g.digits(8),
+		}
+		msg.System.Body = "User " + g.name() + " changed from " + msg.System.WaID + " to " + msg.System.NewWaID
+	case "reaction":
+		msg.Reaction.MessageID = g.wamid()
+	}
+
+	for name, media := range map[string]*wmessage.MediaInfo{
+		"audio": msg.Audio, "document": msg.Document, "image": msg.Image, "sticker": msg.Sticker, "video": msg.Video,
+	} {
+		if media == nil {
+			continue
+		}
+
+		media.ID = g.digits(15)
+		media.Animated = false
+		if mime, ok := mediaMimeTypes[name]; ok {
+			media.MimeType = mime
+			media.Filename = ""
+		}
+		if name == "audio" || name == "sticker" {
+			media.Caption = ""
+		}
+	}
+
+	return msg
+}
+
+func (g *Generator) interactive(typ string) *message.Interactive {
+	interactive := &message.Interactive{Type: typ}
+	switch typ {
+	case "button_reply":
+		interactive.ButtonReply = &message.ButtonReply{ID: "btn_" + g.digits(3), Title: g.pick(buttonTitles)}
+	case "list_reply":
+		interactive.ListReply = &message.ListReply{
+			ID:          "row_" + g.digits(3),
+			Title:       g.pick(buttonTitles),
+			Description: g.sentence(),
+		}
+	case "nfm_reply":
+		// The Cloud API delivers response_json as a string holding the JSON document.
+		response, _ := json.Marshal(fmt.Sprintf(`{"flow_token":"%s","screen_0_name":"%s"}`, g.hex(16), g.name()))
+		interactive.NFMReply = &message.NFMReply{
+			Name:         "flow",
+			Body:         "Sent",
+			ResponseJSON: response,
+		}
+	}
+
+	return interactive
+}
+
+func (g *Generator) messageValue() *message.Value {
+	return &message.Value{
+		MessagingProduct: MessagingProduct,
+		Metadata:         g.metadata(),
+	}
+}
+
+func (g *Generator) metadata() *message.Metadata {
+	return &message.Metadata{DisplayPhoneNumber: g.businessPhone, PhoneNumberID: g.phoneNumberID}
+}
+
+func (g *Generator) messageNotification(field string, value *message.Value) *message.Notification {
+	return &message.Notification{
+		Object: ObjectWhatsAppBusinessAccount,
+		Entry: []*message.Entry{{
+			ID:      g.wabaID,
+			Changes: []*message.Change{{Field: field, Value: value}},
+		}},
+	}
+}
+
+func (g *Generator) coexistenceFixture(field string, value *coexistence.Value) *Fixture {
+	return &Fixture{
+		Name:  field,
+		Field: field,
+		Notification: &coexistence.Notification{
+			Object: ObjectWhatsAppBusinessAccount,
+			Entry: []*coexistence.Entry{{
+				ID:      g.wabaID,
+				Time:    g.now.Unix(),
+				Changes: []*coexistence.Change{{Field: field, Value: value}},
+			}},
+		},
+	}
+}
+
+// Fill sets every exported field of the struct v points to with a fake value picked from
+// the JSON name of the field. Fields without a JSON name and "errors" lists are skipped.
+func (g *Generator) Fill(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+
+	g.fill(rv.Elem(), "", 0)
+}
+
+const maxFillDepth = 10
+
+func (g *Generator) fill(v reflect.Value, name string, depth int) {
+	if depth > maxFillDepth || !v.CanSet() {
+		return
+	}
+
+	if v.Type() == reflect.TypeOf(json.RawMessage{}) {
+		v.SetBytes([]byte(`{}`))
+
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		g.fill(elem.Elem(), name, depth+1)
+		v.Set(elem)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			tag := jsonName(field)
+			if !field.IsExported() || (tag == "" && !field.Anonymous) || tag == "errors" {
+				continue
+			}
+			g.fill(v.Field(i), tag, depth+1)
+		}
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), 1, 1)
+		g.fill(slice.Index(0), name, depth+1)
+		v.Set(slice)
+	case reflect.String:
+		v.SetString(g.stringFor(name))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(g.intFor(name))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(g.floatFor(name))
+	case reflect.Bool:
+		v.SetBool(false)
+	default:
+	}
+}
+
+func (g *Generator) stringFor(name string) string {
+	switch {
+	case name == "id":
+		return g.wamid()
+	case name == "timestamp":
+		return g.timestamp()
+	case slices.Contains([]string{"from", "to", "wa_id", "new_wa_id", "recipient_id", "phone", "phone_number"}, name):
+		return g.customerPhone
+	case name == "display_phone_number":
+		return g.businessPhone
+	case name == "mime_type":
+		return "application/pdf"
+	case name == "sha256":
+		return g.hex(64)
+	case name == "filename":
+		return "invoice-" + g.digits(4) + ".pdf"
+	case name == "emoji":
+		return g.pick([]string{"👍", "❤️", "😂", "🙏"})
+	case name == "currency":
+		return "USD"
+	case name == "item_price":
+		return strconv.Itoa(g.rand.IntN(90)+10) + ".99" //nolint:mnd // price range
+	case name == "quantity":
+		return strconv.Itoa(g.rand.IntN(4) + 1) //nolint:mnd // quantity range
+	case name == "language" || name == "message_template_language":
+		return "en_US"
+	case name == "first_name":
+		return g.pick(firstNames)
+	case name == "last_name":
+		return g.pick(lastNames)
+	case name == "middle_name" || name == "prefix" || name == "suffix":
+		return ""
+	case name == "company":
+		return "Example Ltd"
+	case name == "department":
+		return "Sales"
+	case name == "title":
+		return "Manager"
+	case name == "type":
+		return "WORK"
+	case name == "country":
+		return "Tanzania"
+	case name == "country_code":
+		return "TZ"
+	case name == "city" || name == "state":
+		return "Dar es Salaam"
+	case name == "zip":
+		return "14111"
+	case name == "street":
+		return "Samora Avenue " + g.digits(2)
+	case name == "email":
+		return strings.ToLower(g.pick(firstNames)) + "@example.com"
+	case strings.HasSuffix(name, "url") || name == "link":
+		return "https://example.com/" + g.hex(8)
+	case strings.HasSuffix(name, "_id") || name == "hash":
+		return g.digits(15)
+	case strings.HasSuffix(name, "date") || name == "expiration" || name == "birthday":
+		return g.now.AddDate(0, 1, 0).Format(time.DateOnly)
+	case strings.HasSuffix(name, "name"):
+		return g.name()
+	case name == "event":
+		return "APPROVED"
+	case name == "current_limit":
+		return "TIER_1K"
+	case name == "decision":
+		return "APPROVED"
+	case name == "alert_state":
+		return "ACTIVATED"
+	case name == "reason":
+		return "NONE"
+	case name == "previous_category":
+		return "UTILITY"
+	case name == "new_category":
+		return "MARKETING"
+	case name == "waba_ban_state":
+		return "SCHEDULE_FOR_DISABLE"
+	case name == "restriction_type":
+		return "RESTRICTED_BIZ_INITIATED_MESSAGING"
+	case name == "violation_type":
+		return "ACCOUNT_VIOLATION"
+	case name == "error_type":
+		return "INVALID_SCREEN_TRANSITION"
+	default:
+		return g.sentence()
+	}
+}
+
+func (g *Generator) intFor(name string) int64 {
+	switch {
+	case name == "time" || strings.HasSuffix(name, "timestamp"):
+		return g.now.Unix()
+	case strings.HasSuffix(name, "_id"):
+		return g.rand.Int64N(1e15) + 1e14 //nolint:mnd // 15 digit id
+	case name == "availability":
+		return int64(g.rand.IntN(20) + 80) //nolint:mnd // percentage
+	default:
+		return int64(g.rand.IntN(1000) + 1) //nolint:mnd // small count
+	}
+}
+
+func (g *Generator) floatFor(name string) float64 {
+	switch name {
+	case "latitude":
+		return -6.7924 + g.rand.Float64()/10 //nolint:mnd // around Dar es Salaam
+	case "longitude":
+		return 39.2083 + g.rand.Float64()/10 //nolint:mnd // around Dar es Salaam
+	default:
+		return float64(g.rand.IntN(100)) / 100 //nolint:mnd // ratio
+	}
+}
+
+func (g *Generator) wamid() string {
+	return "wamid." + g.hex(40)
+}
+
+func (g *Generator) timestamp() string {
+	return strconv.FormatInt(g.now.Unix(), 10)
+}
+
+func (g *Generator) name() string {
+	return g.pick(firstNames) + " " + g.pick(lastNames)
+}
+
+func (g *Generator) sentence() string {
+	return g.pick(sentences)
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rand.IntN(len(values))]
+}
+
+func (g *Generator) digits(n int) string {
+	var b strings.Builder
+	b.WriteByte(byte('1' + g.rand.IntN(9))) //nolint:mnd // no leading zero
+	for range n - 1 {
+		b.WriteByte(byte('0' + g.rand.IntN(10))) //nolint:mnd // decimal digit
+	}
+
+	return b.String()
+}
+
+func (g *Generator) hex(n int) string {
+	const alphabet = "0123456789ABCDEF"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rand.IntN(len(alphabet))]
+	}
+
+	return string(b)
+}
+
+func (g *Generator) errors() []*werrors.Error {
+	return []*werrors.Error{{
+		Code:    131026, //nolint:mnd // undeliverable
+		Message: "Message undeliverable",
+		Data:    &werrors.ErrorData{Details: "Message could not be delivered to " + g.customerPhone},
+	}}
+}
+
+// keepJSONFields zeroes the fields of the struct v points to whose JSON name is not in keep.
+func keepJSONFields(v any, keep []string) {
+	rv := reflect.ValueOf(v).Elem()
+	for i := range rv.NumField() {
+		if !slices.Contains(keep, jsonName(rv.Type().Field(i))) {
+			rv.Field(i).SetZero()
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+var (
+	firstNames   = []string{"Amina", "Baraka", "Neema", "Juma", "Grace", "Daudi", "Rehema", "Peter"}
+	lastNames    = []string{"Mushi", "Mwakyusa", "Kimaro", "Ochieng", "Mrema", "Njoroge", "Massawe"}
+	buttonTitles = []string{"Yes", "No", "Track order", "Talk to agent", "Book now"}
+	sentences    = []string{
+		"Hello, is my order ready?",
+		"Can I change the delivery address?",
+		"Thanks, that works for me.",
+		"What time do you open tomorrow?",
+		"Please send me the invoice.",
+	}
+)
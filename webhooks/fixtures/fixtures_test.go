@@ -0,0 +1,80 @@
+package fixtures_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/fixtures"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+var fixedTime = time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+func TestGenerator_All(t *testing.T) {
+	t.Parallel()
+
+	all := fixtures.NewGenerator(42, fixedTime).All()
+	names := make(map[string]bool, len(all))
+
+	for _, fixture := range all {
+		if names[fixture.Name] {
+			t.Errorf("duplicate fixture %s", fixture.Name)
+		}
+		names[fixture.Name] = true
+
+		payload, err := fixture.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Decode strictly into a fresh value of the same type, so every key the
+		// fixture emits is known to the decoder and survives a round trip.
+		decoded := reflect.New(reflect.TypeOf(fixture.Notification).Elem()).Interface()
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(decoded); err != nil {
+			t.Errorf("%s: decode: %v", fixture.Name, err)
+
+			continue
+		}
+
+		if diff := gcmp.Diff(fixture.Notification, decoded); diff != "" {
+			t.Errorf("%s: round trip mismatch (-want +got):\n%s", fixture.Name, diff)
+		}
+	}
+
+	for _, want := range []string{"messages/text", "messages/interactive.nfm_reply", "statuses/failed", "flows/endpoint_latency", "history"} {
+		if !names[want] {
+			t.Errorf("missing fixture %s", want)
+		}
+	}
+}
+
+func TestGenerator_Messages(t *testing.T) {
+	t.Parallel()
+
+	first := fixtures.NewGenerator(7, fixedTime).Messages()
+	second := fixtures.NewGenerator(7, fixedTime).Messages()
+
+	if diff := gcmp.Diff(first, second); diff != "" {
+		t.Errorf("same seed produced different fixtures (-first +second):\n%s", diff)
+	}
+
+	for i, fixture := range first {
+		notification, ok := fixture.Notification.(*message.Notification)
+		if !ok {
+			t.Fatalf("%s: unexpected notification type %T", fixture.Name, fixture.Notification)
+		}
+
+		msg := notification.Entry[0].Changes[0].Value.Messages[0]
+		wantType := fixtures.MessageTypes()[i]
+		if msg.Type != wantType && msg.Type+"."+msg.Interactive.Type != wantType {
+			t.Errorf("%s: message type = %q", fixture.Name, msg.Type)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// DecodeError describes a part of a notification that could not be decoded. Path is the
+	// JSON path of the value, for example entry[0].changes[0].value.statuses[1].timestamp,
+	// and is empty when the payload as a whole could not be decoded.
+	DecodeError struct {
+		Path string
+		Err  error
+	}
+
+	// RawNotificationHandler receives the raw body of notifications that failed to decode,
+	// together with what went wrong, so they can be stored or decoded another way. Returning
+	// a non nil Response ends the request with it; returning nil lets the Listener carry on
+	// with the partially decoded notification in lenient mode, or fail the request otherwise.
+	RawNotificationHandler interface {
+		HandleRawNotification(ctx context.Context, payload []byte, errs []*DecodeError) *Response
+	}
+
+	RawNotificationHandlerFunc func(ctx context.Context, payload []byte, errs []*DecodeError) *Response
+)
+
+func (fn RawNotificationHandlerFunc) HandleRawNotification(ctx context.Context, payload []byte,
+	errs []*DecodeError,
+) *Response {
+	return fn(ctx, payload, errs)
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %v", ErrMessageDecode, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %v", ErrMessageDecode, e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() []error {
+	return []error{ErrMessageDecode, e.Err}
+}
+
+// DecodeLenient decodes payload into a T, skipping the values that do not match their
+// field types instead of failing. Numbers sent as strings and ids sent as numbers are
+// converted rather than skipped. The skipped values are reported as DecodeErrors, the
+// returned notification is nil only when payload is not a JSON document at all.
+func DecodeLenient[T any](payload []byte) (*T, []*DecodeError) {
+	var notification T
+
+	if err := json.Unmarshal(payload, &notification); err == nil {
+		return &notification, nil
+	}
+
+	if !json.Valid(payload) {
+		return nil, []*DecodeError{{Err: errors.New("invalid json")}}
+	}
+
+	notification = *new(T)
+	var errs []*DecodeError
+	decodeLenient(reflect.ValueOf(&notification).Elem(), payload, "", &errs)
+
+	return &notification, errs
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+//nolint:cyclop // one branch per kind
+func decodeLenient(value reflect.Value, raw json.RawMessage, path string, errs *[]*DecodeError) {
+	report := func(err error) {
+		*errs = append(*errs, &DecodeError{Path: path, Err: err})
+	}
+
+	if string(raw) == "null" {
+		return
+	}
+
+	if value.Kind() != reflect.Pointer && value.Addr().Type().Implements(unmarshalerType) &&
+		value.Kind() != reflect.Struct {
+		if err := json.Unmarshal(raw, value.Addr().Interface()); err != nil {
+			report(err)
+		}
+
+		return
+	}
+
+	switch value.Kind() { //nolint:exhaustive // everything else is decoded as a whole
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		decodeLenient(elem.Elem(), raw, path, errs)
+		value.Set(elem)
+
+	case reflect.Struct:
+		// Try the struct as a whole first, so custom unmarshalers still apply when they can.
+		if err := json.Unmarshal(raw, value.Addr().Interface()); err == nil {
+			return
+		}
+
+		value.SetZero()
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			report(err)
+
+			return
+		}
+
+		decodeStructFields(value, fields, path, errs)
+
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if err := json.Unmarshal(raw, value.Addr().Interface()); err != nil {
+				report(err)
+			}
+
+			return
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			report(err)
+
+			return
+		}
+
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			decodeLenient(slice.Index(i), item, path+"["+strconv.Itoa(i)+"]", errs)
+		}
+		value.Set(slice)
+
+	default:
+		if err := json.Unmarshal(raw, value.Addr().Interface()); err != nil && !coerce(value, raw) {
+			report(err)
+		}
+	}
+}
+
+// coerce sets numbers sent as strings and ids sent as numbers, the usual mismatches.
+func coerce(value reflect.Value, raw json.RawMessage) bool {
+	text := strings.Trim(string(raw), `"`)
+
+	switch value.Kind() { //nolint:exhaustive // only scalars are coerced
+	case reflect.String:
+		if _, err := strconv.ParseFloat(string(raw), 64); err == nil {
+			value.SetString(string(raw))
+
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil && !value.OverflowInt(n) {
+			value.SetInt(n)
+
+			return true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil && !value.OverflowUint(n) {
+			value.SetUint(n)
+
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			value.SetFloat(f)
+
+			return true
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			value.SetBool(b)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeStructFields(value reflect.Value, fields map[string]json.RawMessage, path string,
+	errs *[]*DecodeError,
+) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			target := value.Field(i)
+			if target.Kind() == reflect.Pointer {
+				target.Set(reflect.New(field.Type.Elem()))
+				target = target.Elem()
+			}
+
+			if target.Kind() == reflect.Struct {
+				decodeStructFields(target, fields, path, errs)
+
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		decodeLenient(value.Field(i), raw, fieldPath, errs)
+	}
+}
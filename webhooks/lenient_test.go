@@ -0,0 +1,112 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const statusPayload = `{
+  "object": "whatsapp_business_account",
+  "entry": [{
+    "id": 102290129340398,
+    "time": "1700000000",
+    "changes": [{
+      "field": "messages",
+      "value": {
+        "messaging_product": "whatsapp",
+        "statuses": [
+          {"id": "wamid.1", "status": "read", "timestamp": "1700000001", "recipient_id": 255700000000},
+          {"id": "wamid.2", "status": "sent", "timestamp": {"seconds": 1}, "recipient_id": "255700000001"}
+        ]
+      }
+    }]
+  }]
+}`
+
+func TestDecodeLenient(t *testing.T) {
+	t.Parallel()
+
+	notification, errs := webhooks.DecodeLenient[message.Notification]([]byte(statusPayload))
+	if notification == nil {
+		t.Fatal("expected a partial notification")
+	}
+
+	if len(errs) != 1 || errs[0].Path != "entry[0].changes[0].value.statuses[1].timestamp" {
+		t.Fatalf("unexpected decode errors: %v", errs)
+	}
+
+	if !errors.Is(errs[0], webhooks.ErrMessageDecode) {
+		t.Errorf("decode error does not wrap %v", webhooks.ErrMessageDecode)
+	}
+
+	entry := notification.Entry[0]
+	statuses := entry.Changes[0].Value.Statuses
+
+	got := []any{entry.ID, entry.Time, statuses[0].Timestamp, statuses[0].RecipientID, statuses[1].ID, statuses[1].Timestamp}
+	want := []any{"102290129340398", int64(1700000000), int64(1700000001), "255700000000", "wamid.2", int64(0)}
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("partial notification mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestListener_Lenient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		lenient     bool
+		rawStatus   int
+		wantStatus  int
+		wantHandled bool
+		wantRaw     int
+	}{
+		{name: "strict fails", wantStatus: http.StatusInternalServerError, wantRaw: 1},
+		{name: "strict recovered by raw handler", rawStatus: http.StatusAccepted, wantStatus: http.StatusAccepted, wantRaw: 1},
+		{name: "lenient handles partial notification", lenient: true, wantStatus: http.StatusOK, wantHandled: true, wantRaw: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				handled  bool
+				rawCalls int
+			)
+
+			listener := webhooks.NewListener(
+				func(_ context.Context, notification *message.Notification) *webhooks.Response {
+					handled = len(notification.Entry[0].Changes[0].Value.Statuses) == 2
+
+					return &webhooks.Response{StatusCode: http.StatusOK}
+				}, nil, &webhooks.ValidateOptions{})
+			listener.Lenient = tt.lenient
+			listener.RawHandler = webhooks.RawNotificationHandlerFunc(
+				func(_ context.Context, _ []byte, errs []*webhooks.DecodeError) *webhooks.Response {
+					rawCalls += len(errs)
+					if tt.rawStatus == 0 {
+						return nil
+					}
+
+					return &webhooks.Response{StatusCode: tt.rawStatus}
+				})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(statusPayload))
+			listener.HandleNotification(recorder, request)
+
+			if recorder.Code != tt.wantStatus || handled != tt.wantHandled || rawCalls != tt.wantRaw {
+				t.Errorf("status = %d, handled = %v, raw errors = %d", recorder.Code, handled, rawCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package mediafetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+)
+
+var _ BlobStore = (*EncryptedStore)(nil)
+
+// EncryptedStore seals media before it reaches the wrapped BlobStore. The media is read in
+// memory to be sealed, with its key as associated data, so the size limit of the Fetcher
+// bounds the memory used. Open turns the stored content back into the media.
+type EncryptedStore struct {
+	store  BlobStore
+	sealer *crypto.Sealer
+}
+
+func NewEncryptedStore(store BlobStore, sealer *crypto.Sealer) *EncryptedStore {
+	return &EncryptedStore{store: store, sealer: sealer}
+}
+
+func (s *EncryptedStore) Put(ctx context.Context, key string, content io.Reader, _ int64, contentType string) error {
+	plaintext, err := io.ReadAll(content)
+	if err != nil {
+		return fmt.Errorf("read media %s: %w", key, err)
+	}
+
+	sealed, err := s.sealer.Seal(ctx, plaintext, []byte(key))
+	if err != nil {
+		return fmt.Errorf("seal media %s: %w", key, err)
+	}
+
+	return s.store.Put(ctx, key, bytes.NewReader(sealed), int64(len(sealed)), contentType)
+}
+
+// Open returns the media stored under key from its sealed content, as read from the
+// wrapped store.
+func (s *EncryptedStore) Open(ctx context.Context, key string, sealed io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("read media %s: %w", key, err)
+	}
+
+	plaintext, err := s.sealer.Open(ctx, content, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("open media %s: %w", key, err)
+	}
+
+	return plaintext, nil
+}
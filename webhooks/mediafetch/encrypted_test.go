@@ -0,0 +1,57 @@
+package mediafetch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	"github.com/piusalfred/whatsapp/webhooks/mediafetch"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	files := mediafetch.NewFileStore(t.TempDir())
+	sealer := crypto.NewSealer(crypto.NewStaticKeyProvider(&crypto.Key{ID: "k1", Secret: bytes.Repeat([]byte{7}, 32)}))
+	store := mediafetch.NewEncryptedStore(files, sealer)
+	fetcher := mediafetch.NewFetcher(&fakeDownloader{content: map[string]string{"media-1": "jpeg bytes"}}, store)
+
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "PHONE_ID"}}
+	stored, err := fetcher.Fetch(ctx, nctx, &message.Info{}, &wmessage.MediaInfo{ID: "media-1", MimeType: "image/jpeg"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	path, err := files.Path(stored.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(sealed, []byte("jpeg bytes")) {
+		t.Errorf("media stored in clear: %q", sealed)
+	}
+
+	content, err := store.Open(ctx, stored.Key, bytes.NewReader(sealed))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if string(content) != "jpeg bytes" {
+		t.Errorf("Open() = %q", content)
+	}
+
+	if _, err := store.Open(ctx, "PHONE_ID/media-2.jpeg", bytes.NewReader(sealed)); !errors.Is(err, crypto.ErrOpen) {
+		t.Errorf("Open() error = %v, want %v for media moved to another key", err, crypto.ErrOpen)
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package mediafetch downloads the media of incoming messages as they arrive.
+//
+// The media of a message is only referenced by its ID in the webhook, and the download
+// URL it resolves to expires after a few minutes. A Fetcher sits in front of the media
+// message handlers: for every image, audio, video and document message it resolves the
+// URL, streams the media through the media client into a BlobStore and hands the stored
+// reference to the handler, so the handler never deals with media IDs or URLs.
+//
+//	fetcher := mediafetch.NewFetcher(mediaClient, mediafetch.NewFileStore("/var/lib/whatsapp/media"))
+//	fetcher.Register(handlers, mediafetch.OnStoredMediaHook(func(ctx context.Context,
+//		nctx *message.NotificationContext, info *message.Info, stored *mediafetch.StoredMedia,
+//	) error {
+//		return attach(ctx, info.From, stored.Key)
+//	}))
+//
+// FileStore keeps the media on the local filesystem. S3 compatible object stores are
+// used by implementing BlobStore on top of their client, Put maps to a PutObject call.
+// EncryptedStore seals the media before it reaches any of them.
+package mediafetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/piusalfred/whatsapp/media"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type (
+	// Downloader streams media to a writer, it is implemented by media.BaseClient.
+	Downloader interface {
+		DownloadTo(ctx context.Context, req *media.DownloadToRequest, w io.Writer) (*media.DownloadResult, error)
+	}
+
+	// BlobStore stores the content of the received media under key. size is zero when
+	// unknown. Put must fail when reading content fails, content is the download itself.
+	BlobStore interface {
+		Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	}
+
+	// KeyFunc picks the key media is stored under.
+	KeyFunc func(nctx *message.NotificationContext, info *message.Info, media *wmessage.MediaInfo) string
+
+	// StoredMedia is media of a message that was saved to the BlobStore under Key. Media
+	// is the media as received in the webhook, with the caption and filename.
+	StoredMedia struct {
+		Key         string
+		MediaID     string
+		ContentType string
+		Size        int64
+		SHA256      string
+		Media       *wmessage.MediaInfo
+	}
+
+	StoredMediaHandler = message.Handler[StoredMedia]
+	OnStoredMediaHook  = message.HandlerFunc[StoredMedia]
+
+	FetcherOption func(*Fetcher)
+
+	// Fetcher downloads the media of incoming messages to a BlobStore.
+	Fetcher struct {
+		downloader Downloader
+		store      BlobStore
+		key        KeyFunc
+		maxSize    int64
+	}
+)
+
+// WithKeyFunc sets how the keys of stored media are picked, DefaultKey by default.
+func WithKeyFunc(fn KeyFunc) FetcherOption {
+	return func(f *Fetcher) {
+		f.key = fn
+	}
+}
+
+// WithMaxSize sets the size limit of downloaded media. By default the limit of the media
+// type applies, see media.DownloadToRequest.
+func WithMaxSize(size int64) FetcherOption {
+	return func(f *Fetcher) {
+		f.maxSize = size
+	}
+}
+
+// NewFetcher creates a Fetcher downloading media with downloader into store.
+func NewFetcher(downloader Downloader, store BlobStore, options ...FetcherOption) *Fetcher {
+	f := &Fetcher{
+		downloader: downloader,
+		store:      store,
+		key:        DefaultKey,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(f)
+		}
+	}
+
+	return f
+}
+
+// DefaultKey stores media as "<phone number id>/<media id><extension>", the extension
+// being the one of its mime type in media.InfoMap.
+func DefaultKey(nctx *message.NotificationContext, _ *message.Info, info *wmessage.MediaInfo) string {
+	phoneNumberID := "unknown"
+	if nctx != nil && nctx.Metadata != nil && nctx.Metadata.PhoneNumberID != "" {
+		phoneNumberID = nctx.Metadata.PhoneNumberID
+	}
+
+	mimeType, _, _ := strings.Cut(info.MimeType, ";")
+
+	return path.Join(phoneNumberID, info.ID+media.InfoMap[media.Type(strings.TrimSpace(mimeType))].Extension)
+}
+
+// Fetch downloads the media into the BlobStore. The download is streamed into the store,
+// the store sees a read error when the download fails, including when the media does not
+// match its checksum.
+func (f *Fetcher) Fetch(ctx context.Context, nctx *message.NotificationContext, info *message.Info,
+	mediaInfo *wmessage.MediaInfo,
+) (*StoredMedia, error) {
+	if mediaInfo == nil || mediaInfo.ID == "" {
+		return nil, ErrNoMedia
+	}
+
+	req := &media.DownloadToRequest{MaxSize: f.maxSize}
+	req.MediaID = mediaInfo.ID
+	if nctx != nil && nctx.Metadata != nil {
+		req.PhoneNumberID = nctx.Metadata.PhoneNumberID
+	}
+
+	key := f.key(nctx, info, mediaInfo)
+	reader, writer := io.Pipe()
+	downloaded := make(chan *media.DownloadResult, 1)
+	downloadErr := make(chan error, 1)
+
+	go func() {
+		result, err := f.downloader.DownloadTo(ctx, req, writer)
+		_ = writer.CloseWithError(err)
+		downloaded <- result
+		downloadErr <- err
+	}()
+
+	errPut := f.store.Put(ctx, key, reader, 0, mediaInfo.MimeType)
+	_ = reader.CloseWithError(io.ErrClosedPipe) // unblocks the download when Put stopped reading early
+
+	result, err := <-downloaded, <-downloadErr
+	if err != nil && (errPut == nil || !errors.Is(err, io.ErrClosedPipe)) {
+		return nil, fmt.Errorf("%w: media %s: %w", ErrFetch, mediaInfo.ID, err)
+	}
+
+	if errPut != nil {
+		return nil, fmt.Errorf("%w: media %s: %w", ErrStore, mediaInfo.ID, errPut)
+	}
+
+	return &StoredMedia{
+		Key:         key,
+		MediaID:     mediaInfo.ID,
+		ContentType: result.ContentType,
+		Size:        result.Size,
+		SHA256:      result.SHA256,
+		Media:       mediaInfo,
+	}, nil
+}
+
+// Handler returns a media message handler that stores the media before handing it to
+// next. When the media cannot be stored the error is returned and next is not called.
+func (f *Fetcher) Handler(next StoredMediaHandler) message.MediaMessageHandler {
+	return message.OnMediaMessageHook(func(ctx context.Context, nctx *message.NotificationContext,
+		info *message.Info, mediaInfo *wmessage.MediaInfo,
+	) error {
+		stored, err := f.Fetch(ctx, nctx, info, mediaInfo)
+		if err != nil {
+			return err
+		}
+
+		return next.Handle(ctx, nctx, info, stored)
+	})
+}
+
+// Register sets the image, audio, video and document message handlers of handlers to
+// store the media and then call next.
+func (f *Fetcher) Register(handlers *message.Handlers, next StoredMediaHandler) {
+	handler := f.Handler(next)
+	handlers.SetImageMessageHandler(handler)
+	handlers.SetAudioMessageHandler(handler)
+	handlers.SetVideoMessageHandler(handler)
+	handlers.SetDocumentMessageHandler(handler)
+}
+
+// FileStore is a BlobStore keeping media in files under a directory, the key being the
+// path relative to it.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore writing under dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Path returns the path of the file holding the media stored under key.
+func (s *FileStore) Path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes content to a temporary file that is moved to the path of key once complete,
+// so the file at that path is never partial.
+func (s *FileStore) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	name, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil { //nolint:mnd // owner and group only
+		return fmt.Errorf("create media directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create media file: %w", err)
+	}
+
+	_, err = io.Copy(tmp, content)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write media file: %w", err)
+	}
+
+	return nil
+}
+
+// fetchError is a custom error type for media fetch errors.
+type fetchError string
+
+func (e fetchError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNoMedia    = fetchError("message has no media")
+	ErrFetch      = fetchError("download media")
+	ErrStore      = fetchError("store media")
+	ErrInvalidKey = fetchError("invalid media key")
+)
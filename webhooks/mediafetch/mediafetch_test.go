@@ -0,0 +1,129 @@
+package mediafetch_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/media"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/mediafetch"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type fakeDownloader struct {
+	content map[string]string
+	err     error
+}
+
+func (d *fakeDownloader) DownloadTo(_ context.Context, req *media.DownloadToRequest, w io.Writer,
+) (*media.DownloadResult, error) {
+	content, ok := d.content[req.MediaID]
+	if !ok {
+		return nil, media.ErrMediaGetInfo
+	}
+
+	if _, err := io.WriteString(w, content); err != nil {
+		return nil, err
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &media.DownloadResult{
+		MediaID:     req.MediaID,
+		ContentType: "image/jpeg",
+		Size:        int64(len(content)),
+		SHA256:      "checksum",
+	}, nil
+}
+
+func TestFetcher_Register(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := mediafetch.NewFileStore(dir)
+	fetcher := mediafetch.NewFetcher(&fakeDownloader{content: map[string]string{"media-1": "jpeg bytes"}}, store)
+
+	var got *mediafetch.StoredMedia
+	handlers := &message.Handlers{}
+	fetcher.Register(handlers, mediafetch.OnStoredMediaHook(func(_ context.Context, _ *message.NotificationContext,
+		_ *message.Info, stored *mediafetch.StoredMedia,
+	) error {
+		got = stored
+
+		return nil
+	}))
+
+	image := &wmessage.MediaInfo{ID: "media-1", MimeType: "image/jpeg", Caption: "receipt"}
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "PHONE_ID"}}
+
+	if err := handlers.ImageMessage.Handle(context.Background(), nctx, &message.Info{From: "255700000001"},
+		image); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := &mediafetch.StoredMedia{
+		Key:         "PHONE_ID/media-1.jpeg",
+		MediaID:     "media-1",
+		ContentType: "image/jpeg",
+		Size:        10,
+		SHA256:      "checksum",
+		Media:       image,
+	}
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("stored media mismatch (-want +got):\n%s", diff)
+	}
+
+	path, err := store.Path(got.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "jpeg bytes" {
+		t.Errorf("stored content = %q", data)
+	}
+}
+
+func TestFetcher_FetchFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	downloader := &fakeDownloader{
+		content: map[string]string{"media-1": "corrupted"},
+		err:     media.ErrMediaChecksum,
+	}
+	fetcher := mediafetch.NewFetcher(downloader, mediafetch.NewFileStore(dir))
+
+	_, err := fetcher.Fetch(context.Background(), nil, nil, &wmessage.MediaInfo{ID: "media-1"})
+	if !errors.Is(err, mediafetch.ErrFetch) || !errors.Is(err, media.ErrMediaChecksum) {
+		t.Fatalf("Fetch() error = %v, want ErrFetch wrapping ErrMediaChecksum", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*"))
+	if len(files) != 0 {
+		t.Errorf("Fetch() left files behind: %v", files)
+	}
+}
+
+func TestFileStore_Path(t *testing.T) {
+	t.Parallel()
+
+	store := mediafetch.NewFileStore(t.TempDir())
+	for _, key := range []string{"", "../outside", "/etc/passwd"} {
+		if _, err := store.Path(key); !errors.Is(err, mediafetch.ErrInvalidKey) {
+			t.Errorf("Path(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/message"
+)
+
+const (
+	NFMReplyNameFlow    = "flow"
+	NFMReplyNameAddress = "address_message"
+)
+
+// AddressSubmission is the address a user submitted in reply to an address message.
+// SavedAddressID is set when the user picked one of the saved addresses of the message.
+type AddressSubmission struct {
+	SavedAddressID string                 `json:"saved_address_id,omitempty"`
+	Values         *message.AddressValues `json:"values,omitempty"`
+}
+
+// IsAddressSubmission reports whether the reply answers an address message.
+func (reply *NFMReply) IsAddressSubmission() bool {
+	return reply != nil && reply.Name == NFMReplyNameAddress
+}
+
+// AddressSubmission decodes the response of an address message reply. The response is
+// accepted both as a JSON object and as a JSON encoded string, which is how it is
+// delivered in the webhook payloads.
+func (reply *NFMReply) AddressSubmission() (*AddressSubmission, error) {
+	if !reply.IsAddressSubmission() {
+		return nil, fmt.Errorf("%w: reply is not an address message reply", ErrAddressSubmission)
+	}
+
+	data := bytes.TrimSpace(reply.ResponseJSON)
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAddressSubmission, err)
+		}
+
+		data = []byte(encoded)
+	}
+
+	var submission AddressSubmission
+	if err := json.Unmarshal(data, &submission); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAddressSubmission, err)
+	}
+
+	return &submission, nil
+}
+
+func (handler *Handlers) handleAddressSubmission(ctx context.Context, nctx *NotificationContext,
+	mctx *Info, reply *NFMReply,
+) error {
+	submission, err := reply.AddressSubmission()
+	if err != nil {
+		return err
+	}
+
+	if err := handler.AddressSubmission.Handle(ctx, nctx, mctx, submission); err != nil {
+		return fmt.Errorf("%w: %w", ErrAddressSubmissionHandler, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	whatsapp "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const addressReplyPayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"messages","value":{"messaging_product":"whatsapp","messages":[
+{"from":"919800000000","id":"wamid.1","timestamp":"1","type":"interactive","interactive":{"type":"nfm_reply",
+"nfm_reply":{"name":"address_message","body":"Sent","response_json":"{\"saved_address_id\":\"home\",\"values\":{\"name\":\"Asha\",\"in_pin_code\":\"400063\",\"city\":\"Mumbai\"}}"}}},
+{"from":"919800000000","id":"wamid.2","timestamp":"1","type":"interactive","interactive":{"type":"nfm_reply",
+"nfm_reply":{"name":"flow","body":"Sent","response_json":{"flow_token":"t"}}}}]}}]}]}`
+
+func TestHandlers_AddressSubmission(t *testing.T) {
+	t.Parallel()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(addressReplyPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		submissions []*message.AddressSubmission
+		flows       []string
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetAddressSubmissionHandler(message.OnAddressSubmissionHook(
+		func(_ context.Context, _ *message.NotificationContext, _ *message.Info, s *message.AddressSubmission) error {
+			submissions = append(submissions, s)
+
+			return nil
+		}))
+	handlers.SetFlowCompletionMessageHandler(message.OnFlowCompletionMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, _ *message.Info, r *message.NFMReply) error {
+			flows = append(flows, r.Name)
+
+			return nil
+		}))
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	want := []*message.AddressSubmission{{
+		SavedAddressID: "home",
+		Values:         &whatsapp.AddressValues{Name: "Asha", InPinCode: "400063", City: "Mumbai"},
+	}}
+
+	if diff := gcmp.Diff(want, submissions); diff != "" {
+		t.Errorf("submissions mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]string{message.NFMReplyNameFlow}, flows); diff != "" {
+		t.Errorf("flows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNFMReply_AddressSubmission(t *testing.T) {
+	t.Parallel()
+
+	reply := &message.NFMReply{
+		Name:         message.NFMReplyNameAddress,
+		ResponseJSON: json.RawMessage(`{"values":{"sg_post_code":"018956","unit_number":"#12-01"}}`),
+	}
+
+	got, err := reply.AddressSubmission()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Values.SgPostCode != "018956" || got.Values.UnitNumber != "#12-01" || got.SavedAddressID != "" {
+		t.Errorf("unexpected submission: %+v", got.Values)
+	}
+
+	reply.Name = message.NFMReplyNameFlow
+	if _, err := reply.AddressSubmission(); !errors.Is(err, message.ErrAddressSubmission) {
+		t.Errorf("error = %v, want %v", err, message.ErrAddressSubmission)
+	}
+}
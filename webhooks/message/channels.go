@@ -0,0 +1,333 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+)
+
+// DefaultChannelBuffer is the buffer size of the channels of a Channels unless configured
+// otherwise.
+const DefaultChannelBuffer = 64
+
+const (
+	// ChannelPolicyBlock makes the handler wait for room in a full channel, or for the
+	// request context to be done. The webhook answers late, or with an error that makes
+	// WhatsApp deliver the notification again.
+	ChannelPolicyBlock ChannelPolicy = iota
+
+	// ChannelPolicyDrop drops the notifications that do not fit in a full channel. See
+	// Channels.Dropped.
+	ChannelPolicyDrop
+
+	// ChannelPolicyReject fails the handler with ErrChannelFull when a channel is full, so
+	// the webhook answers with an error straight away and WhatsApp retries later.
+	ChannelPolicyReject
+)
+
+const (
+	ErrChannelFull    = messageError("notification channel is full")
+	ErrChannelsClosed = messageError("notification channels are closed")
+)
+
+type (
+	// ChannelPolicy is what a Channels does with a notification when its channel is full.
+	ChannelPolicy int
+
+	// Incoming is a message received by the business, delivered on a channel.
+	Incoming[T any] struct {
+		Context *NotificationContext
+		Info    *Info
+		Message *T
+	}
+
+	IncomingText        = Incoming[Text]
+	IncomingMedia       = Incoming[message.MediaInfo]
+	IncomingReaction    = Incoming[message.Reaction]
+	IncomingLocation    = Incoming[message.Location]
+	IncomingButtonReply = Incoming[ButtonReply]
+	IncomingListReply   = Incoming[ListReply]
+	IncomingFlowReply   = Incoming[NFMReply]
+
+	// StatusUpdate is a change in the status of a message sent by the business.
+	StatusUpdate struct {
+		Context *NotificationContext
+		Status  *Status
+	}
+
+	// NotificationErrorEvent is an error reported in a notification.
+	NotificationErrorEvent struct {
+		Context *NotificationContext
+		Error   *werrors.Error
+	}
+
+	ChannelsOption func(*Channels)
+
+	// Channels turns the notifications routed by a Handlers into typed Go channels for
+	// applications that prefer select loops to callbacks.
+	//
+	// A channel is created, and the matching handlers set, the first time its accessor is
+	// called, so notifications of kinds nobody consumes are left to the other handlers.
+	// Call the accessors before the webhook starts receiving notifications.
+	//
+	//	channels := message.NewChannels(handlers, message.WithChannelBuffer(256))
+	//	texts, statuses := channels.Texts(), channels.Statuses()
+	//	for {
+	//		select {
+	//		case text := <-texts:
+	//			reply(text)
+	//		case update := <-statuses:
+	//			track(update)
+	//		}
+	//	}
+	Channels struct {
+		handlers *Handlers
+		buffer   int
+		policy   ChannelPolicy
+		dropped  atomic.Uint64
+
+		mu        sync.RWMutex
+		closeOnce sync.Once
+		done      chan struct{}
+		closed    bool
+		closers   []func()
+
+		texts         chan *IncomingText
+		media         chan *IncomingMedia
+		reactions     chan *IncomingReaction
+		locations     chan *IncomingLocation
+		buttonReplies chan *IncomingButtonReply
+		listReplies   chan *IncomingListReply
+		flowReplies   chan *IncomingFlowReply
+		statuses      chan *StatusUpdate
+		errors        chan *NotificationErrorEvent
+	}
+)
+
+// WithChannelBuffer sets the buffer size of the channels, DefaultChannelBuffer by default.
+func WithChannelBuffer(size int) ChannelsOption {
+	return func(c *Channels) {
+		c.buffer = max(size, 0)
+	}
+}
+
+// WithChannelPolicy sets what happens to a notification when its channel is full,
+// ChannelPolicyBlock by default.
+func WithChannelPolicy(policy ChannelPolicy) ChannelsOption {
+	return func(c *Channels) {
+		c.policy = policy
+	}
+}
+
+// NewChannels creates a Channels that delivers the notifications routed by handlers.
+func NewChannels(handlers *Handlers, options ...ChannelsOption) *Channels {
+	c := &Channels{
+		handlers: handlers,
+		buffer:   DefaultChannelBuffer,
+		done:     make(chan struct{}),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(c)
+		}
+	}
+
+	return c
+}
+
+// Texts returns the channel of text messages. It sets Handlers.TextMessage.
+func (c *Channels) Texts() <-chan *IncomingText {
+	if c.texts == nil {
+		c.texts = makeChannel[IncomingText](c)
+		c.handlers.SetTextMessageHandler(incomingHandler(c, c.texts))
+	}
+
+	return c.texts
+}
+
+// Media returns the channel of audio, document, image, sticker and video messages, Info.Type
+// tells them apart. It sets the handlers of the five media types.
+func (c *Channels) Media() <-chan *IncomingMedia {
+	if c.media == nil {
+		c.media = makeChannel[IncomingMedia](c)
+		h := incomingHandler(c, c.media)
+		c.handlers.SetAudioMessageHandler(h)
+		c.handlers.SetDocumentMessageHandler(h)
+		c.handlers.SetImageMessageHandler(h)
+		c.handlers.SetStickerMessageHandler(h)
+		c.handlers.SetVideoMessageHandler(h)
+	}
+
+	return c.media
+}
+
+// Reactions returns the channel of message reactions. It sets Handlers.MessageReaction.
+func (c *Channels) Reactions() <-chan *IncomingReaction {
+	if c.reactions == nil {
+		c.reactions = makeChannel[IncomingReaction](c)
+		c.handlers.SetMessageReactionHandler(incomingHandler(c, c.reactions))
+	}
+
+	return c.reactions
+}
+
+// Locations returns the channel of location messages. It sets Handlers.LocationMessage.
+func (c *Channels) Locations() <-chan *IncomingLocation {
+	if c.locations == nil {
+		c.locations = makeChannel[IncomingLocation](c)
+		c.handlers.SetLocationMessageHandler(incomingHandler(c, c.locations))
+	}
+
+	return c.locations
+}
+
+// ButtonReplies returns the channel of interactive button replies. It sets
+// Handlers.ButtonReply.
+func (c *Channels) ButtonReplies() <-chan *IncomingButtonReply {
+	if c.buttonReplies == nil {
+		c.buttonReplies = makeChannel[IncomingButtonReply](c)
+		c.handlers.SetButtonReplyMessageHandler(incomingHandler(c, c.buttonReplies))
+	}
+
+	return c.buttonReplies
+}
+
+// ListReplies returns the channel of interactive list replies. It sets Handlers.ListReply.
+func (c *Channels) ListReplies() <-chan *IncomingListReply {
+	if c.listReplies == nil {
+		c.listReplies = makeChannel[IncomingListReply](c)
+		c.handlers.SetListReplyMessageHandler(incomingHandler(c, c.listReplies))
+	}
+
+	return c.listReplies
+}
+
+// FlowReplies returns the channel of flow completion replies. It sets Handlers.FlowReply.
+func (c *Channels) FlowReplies() <-chan *IncomingFlowReply {
+	if c.flowReplies == nil {
+		c.flowReplies = makeChannel[IncomingFlowReply](c)
+		c.handlers.SetFlowCompletionMessageHandler(incomingHandler(c, c.flowReplies))
+	}
+
+	return c.flowReplies
+}
+
+// Statuses returns the channel of message status updates. It sets
+// Handlers.MessageStatusChange.
+func (c *Channels) Statuses() <-chan *StatusUpdate {
+	if c.statuses == nil {
+		c.statuses = makeChannel[StatusUpdate](c)
+		c.handlers.SetMessageStatusChangeHandler(OnMessageStatusChangeHook(
+			func(ctx context.Context, nctx *NotificationContext, status *Status) error {
+				return deliver(ctx, c, c.statuses, &StatusUpdate{Context: nctx, Status: status})
+			}))
+	}
+
+	return c.statuses
+}
+
+// Errors returns the channel of errors reported in notifications. It sets
+// Handlers.NotificationError.
+func (c *Channels) Errors() <-chan *NotificationErrorEvent {
+	if c.errors == nil {
+		c.errors = makeChannel[NotificationErrorEvent](c)
+		c.handlers.SetNotificationErrorHandler(OnNotificationErrorHook(
+			func(ctx context.Context, nctx *NotificationContext, err *werrors.Error) error {
+				return deliver(ctx, c, c.errors, &NotificationErrorEvent{Context: nctx, Error: err})
+			}))
+	}
+
+	return c.errors
+}
+
+// Dropped returns the number of notifications dropped under ChannelPolicyDrop.
+func (c *Channels) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// Close closes the channels, ending range loops over them. Handlers blocked on a full
+// channel return ErrChannelsClosed, as do the handlers called afterwards.
+func (c *Channels) Close() {
+	c.closeOnce.Do(func() {
+		// Release the blocked senders before waiting for them to let go of the lock.
+		close(c.done)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.closed = true
+		for _, closeChannel := range c.closers {
+			closeChannel()
+		}
+	})
+}
+
+func makeChannel[T any](c *Channels) chan *T {
+	ch := make(chan *T, c.buffer)
+	c.closers = append(c.closers, func() { close(ch) })
+
+	return ch
+}
+
+func incomingHandler[T any](c *Channels, ch chan *Incoming[T]) HandlerFunc[T] {
+	return func(ctx context.Context, nctx *NotificationContext, info *Info, msg *T) error {
+		return deliver(ctx, c, ch, &Incoming[T]{Context: nctx, Info: info, Message: msg})
+	}
+}
+
+func deliver[T any](ctx context.Context, c *Channels, ch chan *T, value *T) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return ErrChannelsClosed
+	}
+
+	select {
+	case ch <- value:
+		return nil
+	default:
+	}
+
+	switch c.policy {
+	case ChannelPolicyDrop:
+		c.dropped.Add(1)
+
+		return nil
+	case ChannelPolicyReject:
+		return ErrChannelFull
+	case ChannelPolicyBlock:
+	}
+
+	select {
+	case ch <- value:
+		return nil
+	case <-c.done:
+		return ErrChannelsClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,115 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func channelsNotification() *message.Notification {
+	return &message.Notification{Entry: []*message.Entry{{
+		ID: "waba",
+		Changes: []*message.Change{{
+			Field: message.ChangeFieldMessages,
+			Value: &message.Value{
+				Messages: []*message.Message{{
+					From: "255700000001", ID: "wamid.1", Type: "text", Text: &message.Text{Body: "hi"},
+				}},
+				Statuses: []*message.Status{{ID: "wamid.2", StatusValue: "delivered"}},
+			},
+		}},
+	}}}
+}
+
+func TestChannels(t *testing.T) {
+	t.Parallel()
+
+	handlers := &message.Handlers{}
+	channels := message.NewChannels(handlers)
+	texts, statuses := channels.Texts(), channels.Statuses()
+
+	response := handlers.HandleNotification(context.Background(), channelsNotification())
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	for range 2 {
+		select {
+		case text := <-texts:
+			if text.Info.ID != "wamid.1" || text.Message.Body != "hi" || text.Context.ID != "waba" {
+				t.Errorf("unexpected text: %+v", text)
+			}
+		case update := <-statuses:
+			if update.Status.ID != "wamid.2" || update.Status.StatusValue != "delivered" {
+				t.Errorf("unexpected status: %+v", update.Status)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("notification not delivered")
+		}
+	}
+
+	channels.Close()
+	if _, ok := <-texts; ok {
+		t.Error("texts channel is still open")
+	}
+
+	response = handlers.HandleNotification(context.Background(), channelsNotification())
+	if response.StatusCode == http.StatusOK {
+		t.Error("notification handled after Close")
+	}
+}
+
+func TestChannels_Policies(t *testing.T) {
+	t.Parallel()
+
+	handlers := &message.Handlers{}
+	dropping := message.NewChannels(handlers, message.WithChannelBuffer(0),
+		message.WithChannelPolicy(message.ChannelPolicyDrop))
+	dropping.Texts()
+
+	response := handlers.HandleNotification(context.Background(), channelsNotification())
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	if dropping.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropping.Dropped())
+	}
+
+	rejecting := message.NewChannels(handlers, message.WithChannelBuffer(0),
+		message.WithChannelPolicy(message.ChannelPolicyReject))
+	rejecting.Texts()
+
+	response = handlers.HandleNotification(context.Background(), channelsNotification())
+	if response.StatusCode == http.StatusOK {
+		t.Error("full channel was not rejected")
+	}
+
+	blocking := message.NewChannels(handlers, message.WithChannelBuffer(0))
+	blocking.Texts()
+
+	done := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		handler := handlers.TextMessage
+		done <- handler.Handle(ctx, &message.NotificationContext{}, &message.Info{}, &message.Text{})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	blocking.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, message.ErrChannelsClosed) {
+			t.Errorf("blocked handler error = %v, want %v", err, message.ErrChannelsClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not release the blocked handler")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// SetConcurrency handles the changes of a notification with up to workers goroutines and
+// bounds the handling to timeout, zero meaning no bound. See Handlers.Concurrency and
+// Handlers.Timeout.
+func (handler *Handlers) SetConcurrency(workers int, timeout time.Duration) {
+	handler.Concurrency = workers
+	handler.Timeout = timeout
+}
+
+// changeTask is a change of a notification along with its location in the payload.
+type changeTask struct {
+	path    payloadPath
+	entryID string
+	change  *Change
+}
+
+// handleConcurrently spreads the changes over handler.Concurrency lanes keyed by the user
+// each change is about, so the changes of a user share a lane and are handled in the
+// order they were received while different users are handled in parallel. A lane stops
+// at its first error or when ctx is done, the other lanes carry on.
+func (handler *Handlers) handleConcurrently(ctx context.Context, notification *Notification) error {
+	lanes := make([][]*changeTask, handler.Concurrency)
+	for i, entry := range notification.Entry {
+		for j, change := range entry.Changes {
+			if change == nil || change.Value == nil {
+				continue
+			}
+
+			lane := laneOf(changeKey(change.Value), len(lanes))
+			lanes[lane] = append(lanes[lane], &changeTask{
+				path:    payloadPath{entry: i, change: j},
+				entryID: entry.ID,
+				change:  change,
+			})
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, tasks := range lanes {
+		if len(tasks) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, task := range tasks {
+				err := ctx.Err()
+				if err == nil {
+					err = handler.handleNotificationChange(ctx, task.path, task.entryID, task.change)
+				}
+
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// changeKey returns the wa_id of the user a change value is about, empty when there is
+// none like for account level errors.
+func changeKey(value *Value) string {
+	switch {
+	case len(value.Messages) > 0:
+		return value.Messages[0].From
+	case len(value.Statuses) > 0:
+		return value.Statuses[0].RecipientID
+	case len(value.MessageEchoes) > 0:
+		return value.MessageEchoes[0].To
+	case len(value.Contacts) > 0:
+		return value.Contacts[0].WaID
+	default:
+		return ""
+	}
+}
+
+func laneOf(key string, lanes int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(lanes)) //nolint:gosec // lanes is a small positive number
+}
@@ -0,0 +1,102 @@
+package message_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestHandlers_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	users := []string{"255700000001", "255700000002", "255700000003", "255700000004"}
+	notification := &message.Notification{}
+	for i := range 3 {
+		entry := &message.Entry{ID: "waba"}
+		for _, user := range users {
+			entry.Changes = append(entry.Changes, &message.Change{
+				Field: message.ChangeFieldMessages,
+				Value: &message.Value{Messages: []*message.Message{{
+					From: user, ID: user + "." + strconv.Itoa(i), Type: "text", Text: &message.Text{Body: "hi"},
+				}}},
+			})
+		}
+		notification.Entry = append(notification.Entry, entry)
+	}
+
+	var (
+		mu       sync.Mutex
+		received = map[string][]string{}
+		inFlight atomic.Int32
+		peak     atomic.Int32
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetConcurrency(4, time.Second)
+	handlers.SetTextMessageHandler(message.OnTextMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, info *message.Info, _ *message.Text) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			received[info.From] = append(received[info.From], info.ID)
+			mu.Unlock()
+
+			return nil
+		}))
+
+	response := handlers.HandleNotification(context.Background(), notification)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	for _, user := range users {
+		want := []string{user + ".0", user + ".1", user + ".2"}
+		if diff := gcmp.Diff(want, received[user]); diff != "" {
+			t.Errorf("messages of %s out of order (-want +got):\n%s", user, diff)
+		}
+	}
+
+	if peak.Load() < 2 {
+		t.Errorf("peak concurrency = %d, want changes of different users handled in parallel", peak.Load())
+	}
+}
+
+func TestHandlers_Timeout(t *testing.T) {
+	t.Parallel()
+
+	handlers := &message.Handlers{}
+	handlers.SetConcurrency(0, 10*time.Millisecond)
+	handlers.SetTextMessageHandler(message.OnTextMessageHook(
+		func(ctx context.Context, _ *message.NotificationContext, _ *message.Info, _ *message.Text) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}))
+
+	notification := &message.Notification{Entry: []*message.Entry{{Changes: []*message.Change{{
+		Field: message.ChangeFieldMessages,
+		Value: &message.Value{Messages: []*message.Message{{From: "1", Type: "text", Text: &message.Text{}}}},
+	}}}}}
+
+	if response := handlers.HandleNotification(context.Background(), notification); response.StatusCode !=
+		http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 once the timeout is reached", response.StatusCode)
+	}
+}
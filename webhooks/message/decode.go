@@ -0,0 +1,116 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"encoding/json"
+
+	"github.com/piusalfred/whatsapp/pkg/types"
+)
+
+// The methods below decode timestamps and ids whether they are sent as strings or numbers,
+// so a single differently encoded field does not drop the whole notification.
+
+func (entry *Entry) UnmarshalJSON(data []byte) error {
+	type plain Entry
+	aux := struct {
+		*plain
+		ID   types.LenientString `json:"id,omitempty"`
+		Time types.LenientInt64  `json:"time,omitempty"`
+	}{plain: (*plain)(entry)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	entry.ID, entry.Time = string(aux.ID), int64(aux.Time)
+
+	return nil
+}
+
+func (contact *Contact) UnmarshalJSON(data []byte) error {
+	type plain Contact
+	aux := struct {
+		*plain
+		WaID types.LenientString `json:"wa_id,omitempty"`
+	}{plain: (*plain)(contact)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	contact.WaID = string(aux.WaID)
+
+	return nil
+}
+
+func (msg *Message) UnmarshalJSON(data []byte) error {
+	type plain Message
+	aux := struct {
+		*plain
+		ID        types.LenientString `json:"id,omitempty"`
+		From      types.LenientString `json:"from,omitempty"`
+		Timestamp types.LenientString `json:"timestamp,omitempty"`
+	}{plain: (*plain)(msg)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	msg.ID, msg.From, msg.Timestamp = string(aux.ID), string(aux.From), string(aux.Timestamp)
+
+	return nil
+}
+
+// UnmarshalJSON is needed because the promoted Message.UnmarshalJSON would otherwise skip To.
+func (echo *MessageEcho) UnmarshalJSON(data []byte) error {
+	if err := echo.Message.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	var aux struct {
+		To types.LenientString `json:"to,omitempty"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	echo.To = string(aux.To)
+
+	return nil
+}
+
+func (status *Status) UnmarshalJSON(data []byte) error {
+	type plain Status
+	aux := struct {
+		*plain
+		ID          types.LenientString `json:"id,omitempty"`
+		RecipientID types.LenientString `json:"recipient_id,omitempty"`
+		Timestamp   types.LenientInt64  `json:"timestamp,omitempty"`
+	}{plain: (*plain)(status)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	status.ID, status.RecipientID, status.Timestamp = string(aux.ID), string(aux.RecipientID), int64(aux.Timestamp)
+
+	return nil
+}
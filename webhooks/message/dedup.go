@@ -0,0 +1,59 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+// DeliveryKeys returns the keys identifying what notification delivers, for use with
+// webhooks.DedupMiddleware: the ids of the received and echoed messages and, since a
+// message goes through several statuses, the id of every status update along with the
+// status.
+func DeliveryKeys(notification *Notification) []string {
+	if notification == nil {
+		return nil
+	}
+
+	var keys []string
+	for _, entry := range notification.Entry {
+		for _, change := range entry.Changes {
+			if change == nil || change.Value == nil {
+				continue
+			}
+
+			for _, message := range change.Value.Messages {
+				if message.ID != "" {
+					keys = append(keys, "message:"+message.ID)
+				}
+			}
+
+			for _, echo := range change.Value.MessageEchoes {
+				if echo.ID != "" {
+					keys = append(keys, "echo:"+echo.ID)
+				}
+			}
+
+			for _, status := range change.Value.Statuses {
+				if status.ID != "" {
+					keys = append(keys, "status:"+status.ID+":"+status.StatusValue)
+				}
+			}
+		}
+	}
+
+	return keys
+}
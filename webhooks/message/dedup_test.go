@@ -0,0 +1,28 @@
+package message_test
+
+import (
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+func TestDeliveryKeys(t *testing.T) {
+	t.Parallel()
+
+	notification := &message.Notification{Entry: []*message.Entry{{Changes: []*message.Change{
+		{Value: &message.Value{Messages: []*message.Message{{ID: "wamid.1"}}}},
+		{Value: &message.Value{Statuses: []*message.Status{
+			{ID: "wamid.2", StatusValue: "sent"},
+			{ID: "wamid.2", StatusValue: "delivered"},
+		}}},
+		{Value: &message.Value{MessageEchoes: []*message.MessageEcho{{Message: message.Message{ID: "wamid.3"}}}}},
+		{},
+	}}}}
+
+	want := []string{"message:wamid.1", "status:wamid.2:sent", "status:wamid.2:delivered", "echo:wamid.3"}
+	if diff := gcmp.Diff(want, message.DeliveryKeys(notification)); diff != "" {
+		t.Errorf("DeliveryKeys() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,76 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const messageEchoPayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"smb_message_echoes","value":{"messaging_product":"whatsapp",
+"metadata":{"display_phone_number":"15550783881","phone_number_id":"106540352242922"},
+"message_echoes":[{"from":"15550783881","to":"16505551234","id":"wamid.echo","timestamp":"1739321024",
+"type":"text","text":{"body":"Your order has shipped"}}]}}]}]}`
+
+func TestHandlers_MessageEcho(t *testing.T) {
+	t.Parallel()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(messageEchoPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		echoes   []*message.MessageEcho
+		metadata []*message.Metadata
+		received int
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetMessageEchoHandler(message.OnMessageEchoHook(
+		func(_ context.Context, nctx *message.NotificationContext, echo *message.MessageEcho) error {
+			echoes = append(echoes, echo)
+			metadata = append(metadata, nctx.Metadata)
+
+			return nil
+		}))
+	handlers.SetMessageReceivedHandler(message.OnMessageReceivedHook(
+		func(context.Context, *message.NotificationContext, *message.Message) error {
+			received++
+
+			return nil
+		}))
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	want := []*message.MessageEcho{{
+		Message: message.Message{
+			From:      "15550783881",
+			ID:        "wamid.echo",
+			Timestamp: "1739321024",
+			Type:      "text",
+			Text:      &message.Text{Body: "Your order has shipped"},
+		},
+		To: "16505551234",
+	}}
+
+	if diff := gcmp.Diff(want, echoes); diff != "" {
+		t.Errorf("echoes mismatch (-want +got):\n%s", diff)
+	}
+
+	wantMetadata := []*message.Metadata{{DisplayPhoneNumber: "15550783881", PhoneNumberID: "106540352242922"}}
+	if diff := gcmp.Diff(wantMetadata, metadata); diff != "" {
+		t.Errorf("metadata mismatch (-want +got):\n%s", diff)
+	}
+
+	if received != 0 {
+		t.Errorf("received handler called %d times for an echo", received)
+	}
+}
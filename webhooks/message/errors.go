@@ -0,0 +1,170 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+)
+
+// Error codes Meta reports in the errors of inbound messages.
+const (
+	ErrorCodeUnsupportedMessage = 131051
+	ErrorCodeMediaDownload      = 131052
+)
+
+const (
+	ErrorCategoryUnknown       ErrorCategory = "unknown"
+	ErrorCategoryUnsupported   ErrorCategory = "unsupported"
+	ErrorCategoryDeleted       ErrorCategory = "deleted"
+	ErrorCategoryMediaDownload ErrorCategory = "media_download"
+)
+
+// ErrorCategory groups the errors Meta attaches to inbound messages it could not deliver in
+// full, so that they can be handled without matching codes and descriptions.
+type ErrorCategory string
+
+// CategorizeError returns the category of err.
+//
+// Messages deleted by the customer have no code of their own, they are reported as
+// unsupported messages whose title or details mention the deletion.
+func CategorizeError(err *werrors.Error) ErrorCategory {
+	if err == nil {
+		return ErrorCategoryUnknown
+	}
+
+	switch err.Code {
+	case ErrorCodeUnsupportedMessage:
+		if mentionsDeletion(err) {
+			return ErrorCategoryDeleted
+		}
+
+		return ErrorCategoryUnsupported
+	case ErrorCodeMediaDownload:
+		return ErrorCategoryMediaDownload
+	default:
+		if mentionsDeletion(err) {
+			return ErrorCategoryDeleted
+		}
+
+		return ErrorCategoryUnknown
+	}
+}
+
+// CategorizeErrors returns the category of the first error in errs that has one.
+func CategorizeErrors(errs []*werrors.Error) ErrorCategory {
+	for _, err := range errs {
+		if category := CategorizeError(err); category != ErrorCategoryUnknown {
+			return category
+		}
+	}
+
+	return ErrorCategoryUnknown
+}
+
+// ErrorCategory returns the category of the errors of the message, ErrorCategoryUnknown
+// when it has none or none is recognized.
+func (message *Message) ErrorCategory() ErrorCategory {
+	return CategorizeErrors(message.Errors)
+}
+
+func IsUnsupportedMessage(errs []*werrors.Error) bool {
+	return CategorizeErrors(errs) == ErrorCategoryUnsupported
+}
+
+func IsDeletedMessage(errs []*werrors.Error) bool {
+	return CategorizeErrors(errs) == ErrorCategoryDeleted
+}
+
+func IsMediaDownloadFailure(errs []*werrors.Error) bool {
+	return CategorizeErrors(errs) == ErrorCategoryMediaDownload
+}
+
+func mentionsDeletion(err *werrors.Error) bool {
+	texts := []string{err.Title, err.Message}
+	if err.Data != nil {
+		texts = append(texts, err.Data.Details)
+	}
+
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		if strings.Contains(text, "deleted") || strings.Contains(text, "revoked") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDeletedMessageHandler sets the handler of messages deleted by the customer.
+func (handler *Handlers) SetDeletedMessageHandler(h ErrorsHandler) {
+	handler.DeletedMessage = h
+}
+
+// SetUnsupportedMessageHandler sets the handler of messages of types the Cloud API does not
+// support.
+func (handler *Handlers) SetUnsupportedMessageHandler(h ErrorsHandler) {
+	handler.UnsupportedMessage = h
+}
+
+// SetMediaDownloadErrorHandler sets the handler of messages whose media Meta could not
+// download.
+func (handler *Handlers) SetMediaDownloadErrorHandler(h ErrorsHandler) {
+	handler.MediaDownloadError = h
+}
+
+// categoryErrorsHandler returns the handler set for category, nil when there is none.
+func (handler *Handlers) categoryErrorsHandler(category ErrorCategory) (ErrorsHandler, error) {
+	switch category {
+	case ErrorCategoryDeleted:
+		return handler.DeletedMessage, ErrDeletedMessageHandler
+	case ErrorCategoryUnsupported:
+		return handler.UnsupportedMessage, ErrUnsupportedMessageHandler
+	case ErrorCategoryMediaDownload:
+		return handler.MediaDownloadError, ErrMediaDownloadErrorHandler
+	case ErrorCategoryUnknown:
+	}
+
+	return nil, nil
+}
+
+// handleMessageErrors passes the errors of message to the handler of their category. When
+// it is not set they go to MessageErrors if fallback is true and are ignored otherwise.
+func (handler *Handlers) handleMessageErrors(ctx context.Context, nctx *NotificationContext,
+	message *Message, mctx *Info, fallback bool,
+) (bool, error) {
+	h, errHandler := handler.categoryErrorsHandler(message.ErrorCategory())
+	if h == nil && fallback {
+		h, errHandler = handler.MessageErrors, ErrUnknownMessageHandler
+	}
+
+	if h == nil {
+		return false, nil
+	}
+
+	if err := h.Handle(ctx, nctx, mctx, message.Errors); err != nil {
+		return true, fmt.Errorf("%w: %w", errHandler, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const messageErrorsPayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"messages","value":{"messaging_product":"whatsapp","messages":[
+{"from":"255700000000","id":"wamid.1","timestamp":"1","type":"unsupported","errors":[{"code":131051,
+"title":"Message type unknown","message":"Message type unknown",
+"error_data":{"details":"Message type is currently not supported"}}]},
+{"from":"255700000000","id":"wamid.2","timestamp":"1","type":"unsupported","errors":[{"code":131051,
+"title":"Message type unknown","error_data":{"details":"Message was deleted by the sender"}}]},
+{"from":"255700000000","id":"wamid.3","timestamp":"1","type":"image","image":{"id":"media"},"errors":[
+{"code":131052,"title":"Media download error"}]},
+{"from":"255700000000","id":"wamid.4","timestamp":"1","type":"unknown","errors":[{"code":1,"title":"Other"}]}
+]}}]}]}`
+
+func TestHandlers_MessageErrors(t *testing.T) {
+	t.Parallel()
+
+	routed := map[string]string{}
+	record := func(name string) message.ErrorsHandlerFunc {
+		return func(_ context.Context, _ *message.NotificationContext, mctx *message.Info, _ []*werrors.Error) error {
+			routed[mctx.ID] = name
+
+			return nil
+		}
+	}
+
+	handlers := &message.Handlers{}
+	handlers.SetMessageErrorsHandler(record("errors"))
+	handlers.SetUnsupportedMessageHandler(record("unsupported"))
+	handlers.SetDeletedMessageHandler(record("deleted"))
+	handlers.SetMediaDownloadErrorHandler(record("media"))
+	handlers.SetImageMessageHandler(message.OnMediaMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, mctx *message.Info, _ *wmessage.MediaInfo) error {
+			routed[mctx.ID] = "image"
+
+			return nil
+		}))
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(messageErrorsPayload), &notification); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != 200 {
+		t.Fatalf("status code = %d", response.StatusCode)
+	}
+
+	want := map[string]string{
+		"wamid.1": "unsupported",
+		"wamid.2": "deleted",
+		"wamid.3": "media",
+		"wamid.4": "errors",
+	}
+	if diff := gcmp.Diff(want, routed); diff != "" {
+		t.Errorf("routing mismatch (-want +got):\n%s", diff)
+	}
+
+	if !message.IsMediaDownloadFailure(notification.Entry[0].Changes[0].Value.Messages[2].Errors) {
+		t.Error("IsMediaDownloadFailure() = false")
+	}
+}
+
+func TestCategorizeError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  *werrors.Error
+		want message.ErrorCategory
+	}{
+		{err: nil, want: message.ErrorCategoryUnknown},
+		{err: &werrors.Error{Code: 131051}, want: message.ErrorCategoryUnsupported},
+		{err: &werrors.Error{Code: 131051, Title: "Message deleted"}, want: message.ErrorCategoryDeleted},
+		{err: &werrors.Error{Code: 131052}, want: message.ErrorCategoryMediaDownload},
+		{err: &werrors.Error{Code: 500}, want: message.ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := message.CategorizeError(tt.err); got != tt.want {
+			t.Errorf("CategorizeError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/piusalfred/whatsapp/message"
 	werrors "github.com/piusalfred/whatsapp/pkg/errors"
@@ -60,12 +62,23 @@ type (
 	}
 
 	Value struct {
-		MessagingProduct string           `json:"messaging_product,omitempty"`
-		Metadata         *Metadata        `json:"metadata,omitempty"`
-		Errors           []*werrors.Error `json:"errors,omitempty"`
-		Contacts         []*Contact       `json:"contacts,omitempty"`
-		Messages         []*Message       `json:"messages,omitempty"`
-		Statuses         []*Status        `json:"statuses,omitempty"`
+		MessagingProduct string            `json:"messaging_product,omitempty"`
+		Metadata         *Metadata         `json:"metadata,omitempty"`
+		Errors           []*werrors.Error  `json:"errors,omitempty"`
+		Contacts         []*Contact        `json:"contacts,omitempty"`
+		Messages         []*Message        `json:"messages,omitempty"`
+		Statuses         []*Status         `json:"statuses,omitempty"`
+		MessageEchoes    []*MessageEcho    `json:"message_echoes,omitempty"`
+		UserPreferences  []*UserPreference `json:"user_preferences,omitempty"`
+	}
+
+	// MessageEcho is a message sent by the business from the WhatsApp Business app on a number
+	// that is onboarded in coexistence mode. It is delivered on the smb_message_echoes field and
+	// has the same shape as an inbound Message, From is the business number and To is the
+	// customer the message was sent to.
+	MessageEcho struct {
+		Message
+		To string `json:"to,omitempty"`
 	}
 
 	Contact struct {
@@ -107,6 +120,8 @@ type (
 		Pricing               *Pricing         `json:"pricing,omitempty"`
 		Errors                []*werrors.Error `json:"errors,omitempty"`
 		BizOpaqueCallbackData string           `json:"biz_opaque_callback_data,omitempty"`
+		Type                  string           `json:"type,omitempty"`
+		Payment               *Payment         `json:"payment,omitempty"`
 	}
 
 	Metadata struct {
@@ -160,7 +175,7 @@ type (
 	}
 
 	NFMReply struct {
-		Name         string          `json:"name"`          // Always "flow"
+		Name         string          `json:"name"`          // "flow" or "address_message"
 		Body         string          `json:"body"`          // Always "Sent"
 		ResponseJSON json.RawMessage `json:"response_json"` // Flow-specific data (JSON string)
 	}
@@ -233,6 +248,15 @@ type (
 	}
 )
 
+const (
+	// ChangeFieldMessages is the webhook field for inbound messages and status updates.
+	ChangeFieldMessages = "messages"
+
+	// ChangeFieldSMBMessageEchoes is the webhook field for messages the business sends from
+	// the WhatsApp Business app while the number is shared with the Cloud API.
+	ChangeFieldSMBMessageEchoes = "smb_message_echoes"
+)
+
 // PayloadMaxSize is the maximum size of the payload that can be sent to the webhook.
 // Webhooks payloads can be up to 3MB.
 const PayloadMaxSize = 3 * 1024 * 1024
@@ -284,7 +308,11 @@ type Handlers struct {
 	ButtonReply         ButtonReplyMessageHandler
 	ListReply           ListReplyMessageHandler
 	FlowReply           FlowCompletionMessageHandler
+	AddressSubmission   AddressSubmissionHandler
 	MessageErrors       ErrorsHandler
+	DeletedMessage      ErrorsHandler
+	UnsupportedMessage  ErrorsHandler
+	MediaDownloadError  ErrorsHandler
 	TextMessage         TextMessageHandler
 	ReferralMessage     ReferralMessageHandler
 	CustomerIDChange    CustomerIDChangeHandler
@@ -296,7 +324,28 @@ type Handlers struct {
 	StickerMessage      MediaMessageHandler
 	NotificationError   ErrorHandler
 	MessageStatusChange StatusChangeHandler
+	PaymentStatus       PaymentStatusHandler
 	MessageReceived     ReceivedHandler
+	MessageEcho         MessageEchoHandler
+	UserPreference      UserPreferenceHandler
+	UnknownPayload      UnknownPayloadHandler
+
+	// Strict reports unknown change fields, message types and interactive types to
+	// UnknownPayload instead of silently routing them to the fallback handlers.
+	Strict bool
+
+	// Concurrency is the number of goroutines the changes of a notification are handled
+	// with. Changes about the same user are always handled in order. Zero or one handles
+	// them serially.
+	Concurrency int
+
+	// Timeout bounds the time spent handling a notification, so that the webhook answers
+	// before Meta gives up on the request and delivers the notification again.
+	Timeout time.Duration
+
+	// Metrics, when set, records the number of notifications of every field and type, the
+	// latency of the handlers and their errors.
+	Metrics MetricsRecorder
 }
 
 // SetOrderMessageHandler sets the order message handler.
@@ -354,6 +403,12 @@ func (handler *Handlers) SetFlowCompletionMessageHandler(h FlowCompletionMessage
 	handler.FlowReply = h
 }
 
+// SetAddressSubmissionHandler sets the handler of address message replies. Without it
+// address message replies are handled by the flow completion message handler.
+func (handler *Handlers) SetAddressSubmissionHandler(h AddressSubmissionHandler) {
+	handler.AddressSubmission = h
+}
+
 // SetMessageErrorsHandler sets the message errors handler.
 func (handler *Handlers) SetMessageErrorsHandler(h ErrorsHandler) {
 	handler.MessageErrors = h
@@ -419,8 +474,19 @@ func (handler *Handlers) SetMessageReceivedHandler(h ReceivedHandler) {
 	handler.MessageReceived = h
 }
 
+// SetMessageEchoHandler sets the handler for messages echoed from the WhatsApp Business app.
+func (handler *Handlers) SetMessageEchoHandler(h MessageEchoHandler) {
+	handler.MessageEcho = h
+}
+
 func (handler *Handlers) HandleNotification(ctx context.Context, notification *Notification) *webhooks.Response {
-	if err := handler.handleNotification(ctx, notification); err != nil {
+	start := time.Now()
+	err := handler.handleNotification(ctx, notification)
+	if handler.Metrics != nil {
+		handler.Metrics.RecordNotification(ctx, time.Since(start), err)
+	}
+
+	if err != nil {
 		return &webhooks.Response{StatusCode: http.StatusInternalServerError}
 	}
 
@@ -434,8 +500,18 @@ func (handler *Handlers) handleNotification(ctx context.Context,
 		return nil
 	}
 
-	for _, entry := range notification.Entry {
-		if err := handler.handleNotificationEntry(ctx, entry); err != nil {
+	if handler.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, handler.Timeout)
+		defer cancel()
+	}
+
+	if handler.Concurrency > 1 {
+		return handler.handleConcurrently(ctx, notification)
+	}
+
+	for i, entry := range notification.Entry {
+		if err := handler.handleNotificationEntry(ctx, i, entry); err != nil {
 			return err
 		}
 	}
@@ -443,15 +519,9 @@ func (handler *Handlers) handleNotification(ctx context.Context,
 	return nil
 }
 
-func (handler *Handlers) handleNotificationEntry(ctx context.Context, entry *Entry) error {
-	entryID := entry.ID
-	changes := entry.Changes
-	for _, change := range changes {
-		value := change.Value
-		if value == nil {
-			continue
-		}
-		if err := handler.handleNotificationChangeValue(ctx, entryID, value); err != nil {
+func (handler *Handlers) handleNotificationEntry(ctx context.Context, index int, entry *Entry) error {
+	for i, change := range entry.Changes {
+		if err := handler.handleNotificationChange(ctx, payloadPath{entry: index, change: i}, entry.ID, change); err != nil {
 			return err
 		}
 	}
@@ -459,8 +529,33 @@ func (handler *Handlers) handleNotificationEntry(ctx context.Context, entry *Ent
 	return nil
 }
 
+func (handler *Handlers) handleNotificationChange(ctx context.Context, path payloadPath, entryID string,
+	change *Change,
+) error {
+	value := change.Value
+	if value == nil {
+		return nil
+	}
+
+	if handler.Strict && change.Field != ChangeFieldMessages && change.Field != ChangeFieldSMBMessageEchoes &&
+		change.Field != ChangeFieldUserPreferences {
+		nctx := &NotificationContext{ID: entryID, Contacts: value.Contacts, Metadata: value.Metadata}
+
+		return handler.observe(ctx, change.Field, MetricKindUnknown, change.Field, func() error {
+			return handler.handleUnknownPayload(ctx, nctx, &UnknownPayload{
+				Kind:  UnknownPayloadKindField,
+				Field: change.Field,
+				Type:  change.Field,
+				Raw:   rawValue(ctx, path, value),
+			})
+		})
+	}
+
+	return handler.handleNotificationChangeValue(ctx, path, change.Field, entryID, value)
+}
+
 func (handler *Handlers) handleNotificationChangeValue(ctx context.Context,
-	id string, value *Value,
+	path payloadPath, field, id string, value *Value,
 ) error {
 	notificationCtx := &NotificationContext{
 		ID:       id,
@@ -468,30 +563,75 @@ func (handler *Handlers) handleNotificationChangeValue(ctx context.Context,
 		Metadata: value.Metadata,
 	}
 
-	if handler.NotificationError != nil {
-		for _, ev := range value.Errors {
+	for _, ev := range value.Errors {
+		err := handler.observe(ctx, field, MetricKindError, strconv.Itoa(ev.Code), func() error {
+			if handler.NotificationError == nil {
+				return nil
+			}
+
 			if err := handler.NotificationError.Handle(ctx, notificationCtx, ev); err != nil {
 				return fmt.Errorf("%w: %w", ErrNotificationErrorHandler, err)
 			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, sv := range value.Statuses {
+		if err := handler.handleStatus(ctx, field, notificationCtx, sv); err != nil {
+			return err
 		}
 	}
 
-	if handler.MessageStatusChange != nil {
-		for _, sv := range value.Statuses {
-			if err := handler.MessageStatusChange.Handle(ctx, notificationCtx, sv); err != nil {
-				return fmt.Errorf("%w: %w", ErrMessageStatusChangeHandler, err)
+	for _, ev := range value.MessageEchoes {
+		err := handler.observe(ctx, field, MetricKindEcho, ev.Type, func() error {
+			if handler.MessageEcho == nil {
+				return nil
+			}
+
+			if err := handler.MessageEcho.Handle(ctx, notificationCtx, ev); err != nil {
+				return fmt.Errorf("%w: %w", ErrMessageEchoHandler, err)
 			}
+
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 
-	for _, mv := range value.Messages {
-		if handler.MessageReceived != nil {
-			if err := handler.MessageReceived.Handle(ctx, notificationCtx, mv); err != nil {
-				return fmt.Errorf("%w: %w", ErrMessageReceivedNotificationHandler, err)
+	for _, pv := range value.UserPreferences {
+		err := handler.observe(ctx, field, MetricKindUserPreference, pv.Value, func() error {
+			if handler.UserPreference == nil {
+				return nil
+			}
+
+			if err := handler.UserPreference.Handle(ctx, notificationCtx, pv); err != nil {
+				return fmt.Errorf("%w: %w", ErrUserPreferenceHandler, err)
 			}
+
+			return nil
+		})
+		if err != nil {
+			return err
 		}
+	}
 
-		if err := handler.handleNotificationMessage(ctx, notificationCtx, mv); err != nil {
+	for i, mv := range value.Messages {
+		path.message = i
+		err := handler.observe(ctx, field, MetricKindMessage, mv.Type, func() error {
+			if handler.MessageReceived != nil {
+				if err := handler.MessageReceived.Handle(ctx, notificationCtx, mv); err != nil {
+					return fmt.Errorf("%w: %w", ErrMessageReceivedNotificationHandler, err)
+				}
+			}
+
+			return handler.handleNotificationMessage(ctx, path, notificationCtx, mv)
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -499,7 +639,35 @@ func (handler *Handlers) handleNotificationChangeValue(ctx context.Context,
 	return nil
 }
 
-func (handler *Handlers) handleNotificationMessage(ctx context.Context,
+// handleStatus passes payment statuses to the payment status handler when there is one
+// and the other statuses to the message status change handler.
+func (handler *Handlers) handleStatus(ctx context.Context, field string, nctx *NotificationContext,
+	status *Status,
+) error {
+	if handler.PaymentStatus != nil && status.IsPayment() {
+		return handler.observe(ctx, field, MetricKindPayment, status.StatusValue, func() error {
+			if err := handler.PaymentStatus.Handle(ctx, nctx, status); err != nil {
+				return fmt.Errorf("%w: %w", ErrPaymentStatusHandler, err)
+			}
+
+			return nil
+		})
+	}
+
+	return handler.observe(ctx, field, MetricKindStatus, status.StatusValue, func() error {
+		if handler.MessageStatusChange == nil {
+			return nil
+		}
+
+		if err := handler.MessageStatusChange.Handle(ctx, nctx, status); err != nil {
+			return fmt.Errorf("%w: %w", ErrMessageStatusChangeHandler, err)
+		}
+
+		return nil
+	})
+}
+
+func (handler *Handlers) handleNotificationMessage(ctx context.Context, path payloadPath,
 	nctx *NotificationContext, message *Message,
 ) error {
 	mctx := &Info{
@@ -511,6 +679,21 @@ func (handler *Handlers) handleNotificationMessage(ctx context.Context,
 	}
 
 	messageType := ParseType(message.Type)
+	if handler.Strict && messageType == "" {
+		return handler.handleUnknownPayload(ctx, nctx, &UnknownPayload{
+			Kind:  UnknownPayloadKindMessageType,
+			Field: ChangeFieldMessages,
+			Type:  message.Type,
+			Raw:   rawMessage(ctx, path, message),
+		})
+	}
+
+	if len(message.Errors) > 0 && messageType != TypeUnknown && messageType != TypeUnsupported {
+		if handled, err := handler.handleMessageErrors(ctx, nctx, message, mctx, false); handled {
+			return err
+		}
+	}
+
 	switch messageType {
 	case TypeOrder:
 		if err := handler.OrderMessage.Handle(ctx, nctx, mctx, message.Order); err != nil {
@@ -530,7 +713,7 @@ func (handler *Handlers) handleNotificationMessage(ctx context.Context,
 		return handler.handleMediaMessage(ctx, nctx, message, mctx)
 
 	case TypeInteractive:
-		return handler.handleInteractiveNotification(ctx, nctx, message, mctx)
+		return handler.handleInteractiveNotification(ctx, path, nctx, message, mctx)
 
 	case TypeSystem:
 		if err := handler.SystemMessage.Handle(ctx, nctx, mctx, message.System); err != nil {
@@ -539,12 +722,10 @@ func (handler *Handlers) handleNotificationMessage(ctx context.Context,
 
 		return nil
 
-	case TypeUnknown:
-		if err := handler.MessageErrors.Handle(ctx, nctx, mctx, message.Errors); err != nil {
-			return fmt.Errorf("%w: %w", ErrUnknownMessageHandler, err)
-		}
+	case TypeUnknown, TypeUnsupported:
+		_, err := handler.handleMessageErrors(ctx, nctx, message, mctx, true)
 
-		return nil
+		return err
 
 	case TypeText:
 		return handler.handleTextNotification(ctx, nctx, message, mctx)
@@ -678,7 +859,7 @@ func (handler *Handlers) handleDefaultNotificationMessage(ctx context.Context, n
 	return fmt.Errorf("%w: unsupported message type", ErrHandleMessage)
 }
 
-func (handler *Handlers) handleInteractiveNotification(ctx context.Context,
+func (handler *Handlers) handleInteractiveNotification(ctx context.Context, path payloadPath,
 	nctx *NotificationContext, message *Message, mctx *Info,
 ) error {
 	switch message.Interactive.Type {
@@ -695,12 +876,25 @@ func (handler *Handlers) handleInteractiveNotification(ctx context.Context,
 
 		return nil
 	case InteractiveTypeNFMReply:
+		if handler.AddressSubmission != nil && message.Interactive.NFMReply.IsAddressSubmission() {
+			return handler.handleAddressSubmission(ctx, nctx, mctx, message.Interactive.NFMReply)
+		}
+
 		if err := handler.FlowReply.Handle(ctx, nctx, mctx, message.Interactive.NFMReply); err != nil {
 			return fmt.Errorf("handle flow reply: %w", err)
 		}
 
 		return nil
 	default:
+		if handler.Strict {
+			return handler.handleUnknownPayload(ctx, nctx, &UnknownPayload{
+				Kind:  UnknownPayloadKindInteractiveType,
+				Field: ChangeFieldMessages,
+				Type:  message.Interactive.Type,
+				Raw:   rawMessage(ctx, path, message),
+			})
+		}
+
 		if err := handler.InteractiveMessage.Handle(ctx, nctx, mctx, message.Interactive); err != nil {
 			return fmt.Errorf("handle interactive message: %w", err)
 		}
@@ -721,13 +915,17 @@ type (
 	ButtonReplyMessageHandler    = Handler[ButtonReply]
 	ListReplyMessageHandler      = Handler[ListReply]
 	FlowCompletionMessageHandler = Handler[NFMReply]
+	AddressSubmissionHandler     = Handler[AddressSubmission]
 	ReferralMessageHandler       = Handler[ReferralNotification]
 	CustomerIDChangeHandler      = Handler[Identity]
 	SystemMessageHandler         = Handler[System]
 	MediaMessageHandler          = Handler[message.MediaInfo]
 	ErrorHandler                 = ChangeValueHandler[werrors.Error]
 	StatusChangeHandler          = ChangeValueHandler[Status]
+	PaymentStatusHandler         = ChangeValueHandler[Status]
 	ReceivedHandler              = ChangeValueHandler[Message]
+	MessageEchoHandler           = ChangeValueHandler[MessageEcho]
+	UserPreferenceHandler        = ChangeValueHandler[UserPreference]
 	OnButtonMessageHook          = HandlerFunc[Button]
 	OnTextMessageHook            = HandlerFunc[Text]
 	OnOrderMessageHook           = HandlerFunc[Order]
@@ -739,13 +937,17 @@ type (
 	OnButtonReplyMessageHook     = HandlerFunc[ButtonReply]
 	OnListReplyMessageHook       = HandlerFunc[ListReply]
 	OnFlowCompletionMessageHook  = HandlerFunc[NFMReply]
+	OnAddressSubmissionHook      = HandlerFunc[AddressSubmission]
 	OnReferralMessageHook        = HandlerFunc[ReferralNotification]
 	OnCustomerIDChangeHook       = HandlerFunc[Identity]
 	OnSystemMessageHook          = HandlerFunc[System]
 	OnMediaMessageHook           = HandlerFunc[message.MediaInfo]
 	OnNotificationErrorHook      = ChangeValueHandlerFunc[werrors.Error]
 	OnMessageStatusChangeHook    = ChangeValueHandlerFunc[Status]
+	OnPaymentStatusHook          = ChangeValueHandlerFunc[Status]
 	OnMessageReceivedHook        = ChangeValueHandlerFunc[Message]
+	OnMessageEchoHook            = ChangeValueHandlerFunc[MessageEcho]
+	OnUserPreferenceHook         = ChangeValueHandlerFunc[UserPreference]
 )
 
 type (
@@ -817,7 +1019,17 @@ const (
 	ErrUnknownMessageHandler              = messageError("unknown message handler failed")
 	ErrContactsMessageHandler             = messageError("contacts message handler failed")
 	ErrMessageStatusChangeHandler         = messageError("message status change handler failed")
+	ErrPaymentStatusHandler               = messageError("payment status handler failed")
 	ErrMessageReceivedNotificationHandler = messageError("message received notification handler failed")
+	ErrMessageEchoHandler                 = messageError("message echo handler failed")
+	ErrUnknownPayload                     = messageError("unknown payload in strict mode")
+	ErrUnknownPayloadHandler              = messageError("unknown payload handler failed")
+	ErrDeletedMessageHandler              = messageError("deleted message handler failed")
+	ErrUnsupportedMessageHandler          = messageError("unsupported message handler failed")
+	ErrMediaDownloadErrorHandler          = messageError("media download error handler failed")
+	ErrAddressSubmission                  = messageError("invalid address submission")
+	ErrAddressSubmissionHandler           = messageError("address submission handler failed")
+	ErrUserPreferenceHandler              = messageError("user preference handler failed")
 )
 
 const (
@@ -835,6 +1047,7 @@ const (
 	TypeLocation    Type = "location"
 	TypeReaction    Type = "reaction"
 	TypeContacts    Type = "contacts"
+	TypeUnsupported Type = "unsupported"
 )
 
 // Type is type of message that has been received by the business that has subscribed
@@ -861,6 +1074,7 @@ func ParseType(s string) Type {
 		"location":    TypeLocation,
 		"reaction":    TypeReaction,
 		"contacts":    TypeContacts,
+		"unsupported": TypeUnsupported,
 	}
 
 	msgType, ok := msgMap[strings.TrimSpace(strings.ToLower(s))]
@@ -0,0 +1,90 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of the items of a change, in MetricsEvent.Kind.
+const (
+	MetricKindMessage        = "message"
+	MetricKindStatus         = "status"
+	MetricKindPayment        = "payment"
+	MetricKindEcho           = "echo"
+	MetricKindUserPreference = "user_preference"
+	MetricKindError          = "error"
+	MetricKindUnknown        = "unknown"
+)
+
+type (
+	// MetricsEvent describes an item of a notification that went through the handlers: a
+	// message, a status, an echo, a user preference or an error. Field is the change field
+	// the item came in. Type is the message type for messages and echoes, the status for
+	// statuses and payments, the preference value for user preferences, the error code
+	// for errors and the field for unknown fields in strict mode. Duration is the time
+	// spent in the handlers and Err what they returned.
+	MetricsEvent struct {
+		Field    string
+		Kind     string
+		Type     string
+		Duration time.Duration
+		Err      error
+	}
+
+	// MetricsRecorder receives the measurements of Handlers, to count the notifications
+	// of every field and type, export the latency of the handlers and count the errors.
+	// It is called from the goroutines handling the notifications, so implementations
+	// must be safe for concurrent use. Prometheus and OpenTelemetry implementations are
+	// available in the extras/metrics module.
+	MetricsRecorder interface {
+		// RecordNotification is called once per notification with the time spent handling
+		// it and the error that made the webhook answer 500, nil otherwise.
+		RecordNotification(ctx context.Context, duration time.Duration, err error)
+
+		// RecordHandled is called for every item of the notification.
+		RecordHandled(ctx context.Context, event *MetricsEvent)
+	}
+)
+
+// SetMetricsRecorder sets the recorder receiving the measurements of the handlers.
+func (handler *Handlers) SetMetricsRecorder(recorder MetricsRecorder) {
+	handler.Metrics = recorder
+}
+
+// observe calls fn and records the time it took and its error when a recorder is set.
+func (handler *Handlers) observe(ctx context.Context, field, kind, typ string, fn func() error) error {
+	if handler.Metrics == nil {
+		return fn()
+	}
+
+	start := time.Now()
+	err := fn()
+	handler.Metrics.RecordHandled(ctx, &MetricsEvent{
+		Field:    field,
+		Kind:     kind,
+		Type:     typ,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+
+	return err
+}
@@ -0,0 +1,90 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	whatsapp "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type recordedEvent struct {
+	Field, Kind, Type string
+	Failed            bool
+}
+
+type fakeRecorder struct {
+	mu            sync.Mutex
+	notifications []error
+	events        []recordedEvent
+}
+
+func (r *fakeRecorder) RecordNotification(_ context.Context, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, err)
+}
+
+func (r *fakeRecorder) RecordHandled(_ context.Context, event *message.MetricsEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, recordedEvent{
+		Field:  event.Field,
+		Kind:   event.Kind,
+		Type:   event.Type,
+		Failed: event.Err != nil,
+	})
+}
+
+const metricsPayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"messages","value":{"messaging_product":"whatsapp",
+"statuses":[{"id":"wamid.1","status":"read","timestamp":"1","recipient_id":"255700000001"}],
+"messages":[{"from":"255700000001","id":"wamid.2","timestamp":"1","type":"text","text":{"body":"hi"}},
+{"from":"255700000001","id":"wamid.3","timestamp":"1","type":"location","location":{"latitude":1,"longitude":2}}]}}]}]}`
+
+func TestHandlers_Metrics(t *testing.T) {
+	t.Parallel()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(metricsPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &fakeRecorder{}
+	handlers := &message.Handlers{}
+	handlers.SetMetricsRecorder(recorder)
+	handlers.SetTextMessageHandler(message.OnTextMessageHook(
+		func(context.Context, *message.NotificationContext, *message.Info, *message.Text) error {
+			return nil
+		}))
+	handlers.SetLocationMessageHandler(message.OnLocationMessageHook(func(context.Context,
+		*message.NotificationContext, *message.Info, *whatsapp.Location,
+	) error {
+		return errors.New("geocoding failed")
+	}))
+
+	response := handlers.HandleNotification(context.Background(), &notification)
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	want := []recordedEvent{
+		{Field: "messages", Kind: message.MetricKindStatus, Type: "read"},
+		{Field: "messages", Kind: message.MetricKindMessage, Type: "text"},
+		{Field: "messages", Kind: message.MetricKindMessage, Type: "location", Failed: true},
+	}
+	if diff := gcmp.Diff(want, recorder.events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(recorder.notifications) != 1 || !errors.Is(recorder.notifications[0], message.ErrLocationMessage) {
+		t.Errorf("notifications = %v, want one ErrLocationMessage", recorder.notifications)
+	}
+}
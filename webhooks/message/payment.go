@@ -0,0 +1,97 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import "github.com/piusalfred/whatsapp/message"
+
+// StatusTypePayment is the type of the statuses reporting a payment of an order sent
+// with an order_details message.
+const StatusTypePayment = "payment"
+
+// Statuses of payments, in Status.StatusValue.
+const (
+	PaymentStatusCaptured = "captured"
+	PaymentStatusPending  = "pending"
+	PaymentStatusFailed   = "failed"
+)
+
+// Statuses of payment transactions and refunds.
+const (
+	TransactionStatusSuccess = "success"
+	TransactionStatusPending = "pending"
+	TransactionStatusFailed  = "failed"
+)
+
+type (
+	// Payment is the payment of the order with ReferenceID, the reference id of the
+	// order_details message.
+	Payment struct {
+		ReferenceID string              `json:"reference_id,omitempty"`
+		Amount      *message.Amount     `json:"amount,omitempty"`
+		Currency    string              `json:"currency,omitempty"`
+		Transaction *PaymentTransaction `json:"transaction,omitempty"`
+		Refunds     []*PaymentRefund    `json:"refunds,omitempty"`
+	}
+
+	// PaymentTransaction is the transaction of a payment. Type is the payment gateway and
+	// PaymentGatewayTransactionID the id of the transaction at the gateway.
+	PaymentTransaction struct {
+		ID                          string          `json:"id,omitempty"`
+		PaymentGatewayTransactionID string          `json:"pg_transaction_id,omitempty"`
+		Type                        string          `json:"type,omitempty"`
+		Status                      string          `json:"status,omitempty"`
+		CreatedTimestamp            int64           `json:"created_timestamp,omitempty"`
+		UpdatedTimestamp            int64           `json:"updated_timestamp,omitempty"`
+		Amount                      *message.Amount `json:"amount,omitempty"`
+		Currency                    string          `json:"currency,omitempty"`
+		Method                      *PaymentMethod  `json:"method,omitempty"`
+		Error                       *PaymentError   `json:"error,omitempty"`
+	}
+
+	PaymentMethod struct {
+		Type string `json:"type,omitempty"`
+	}
+
+	PaymentError struct {
+		Code   string `json:"code,omitempty"`
+		Reason string `json:"reason,omitempty"`
+	}
+
+	PaymentRefund struct {
+		ID               string          `json:"id,omitempty"`
+		Amount           *message.Amount `json:"amount,omitempty"`
+		Currency         string          `json:"currency,omitempty"`
+		Speed            string          `json:"speed,omitempty"`
+		Status           string          `json:"status,omitempty"`
+		CreatedTimestamp int64           `json:"created_timestamp,omitempty"`
+		UpdatedTimestamp int64           `json:"updated_timestamp,omitempty"`
+	}
+)
+
+// IsPayment reports whether the status reports a payment rather than a message status.
+func (s *Status) IsPayment() bool {
+	return s.Type == StatusTypePayment || s.Payment != nil
+}
+
+// SetPaymentStatusHandler sets the handler for payment statuses. Without it payment
+// statuses are passed to the message status change handler.
+func (handler *Handlers) SetPaymentStatusHandler(h PaymentStatusHandler) {
+	handler.PaymentStatus = h
+}
@@ -0,0 +1,76 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	whatsapp "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const paymentStatusPayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"messages","value":{"messaging_product":"whatsapp","statuses":[
+{"id":"wamid.1","status":"delivered","timestamp":"1","recipient_id":"919800000000"},
+{"id":"pay.1","status":"captured","timestamp":"2","recipient_id":"919800000000","type":"payment",
+"payment":{"reference_id":"order-1","amount":{"value":21000,"offset":100},"currency":"INR",
+"transaction":{"id":"tx-1","pg_transaction_id":"pay_1","type":"razorpay","status":"success",
+"created_timestamp":2,"updated_timestamp":3,"method":{"type":"upi"}}}}]}}]}]}`
+
+func TestHandlers_PaymentStatus(t *testing.T) {
+	t.Parallel()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(paymentStatusPayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		payments []*message.Payment
+		statuses []string
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetPaymentStatusHandler(message.OnPaymentStatusHook(
+		func(_ context.Context, _ *message.NotificationContext, s *message.Status) error {
+			payments = append(payments, s.Payment)
+
+			return nil
+		}))
+	handlers.SetMessageStatusChangeHandler(message.OnMessageStatusChangeHook(
+		func(_ context.Context, _ *message.NotificationContext, s *message.Status) error {
+			statuses = append(statuses, s.StatusValue)
+
+			return nil
+		}))
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", response.StatusCode)
+	}
+
+	want := []*message.Payment{{
+		ReferenceID: "order-1",
+		Amount:      &whatsapp.Amount{Value: 21000, Offset: 100},
+		Currency:    "INR",
+		Transaction: &message.PaymentTransaction{
+			ID:                          "tx-1",
+			PaymentGatewayTransactionID: "pay_1",
+			Type:                        "razorpay",
+			Status:                      message.TransactionStatusSuccess,
+			CreatedTimestamp:            2,
+			UpdatedTimestamp:            3,
+			Method:                      &message.PaymentMethod{Type: "upi"},
+		},
+	}}
+
+	if diff := gcmp.Diff(want, payments); diff != "" {
+		t.Errorf("payments mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := gcmp.Diff([]string{"delivered"}, statuses); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+	}
+}
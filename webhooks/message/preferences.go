@@ -0,0 +1,55 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+// ChangeFieldUserPreferences is the change field of the notifications sent when a user
+// stops or resumes marketing messages from the business.
+const ChangeFieldUserPreferences = "user_preferences"
+
+const (
+	UserPreferenceCategoryMarketing = "marketing_messages"
+	UserPreferenceStop              = "stop"
+	UserPreferenceResume            = "resume"
+)
+
+// UserPreference is a change of the messages a user accepts from the business. Value is
+// UserPreferenceStop or UserPreferenceResume.
+type UserPreference struct {
+	WaID      string `json:"wa_id,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Value     string `json:"value,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+}
+
+// IsMarketingStop reports whether the user stopped marketing messages.
+func (p *UserPreference) IsMarketingStop() bool {
+	return p.Category == UserPreferenceCategoryMarketing && p.Value == UserPreferenceStop
+}
+
+// IsMarketingResume reports whether the user resumed marketing messages.
+func (p *UserPreference) IsMarketingResume() bool {
+	return p.Category == UserPreferenceCategoryMarketing && p.Value == UserPreferenceResume
+}
+
+// SetUserPreferenceHandler sets the handler for user preference changes.
+func (handler *Handlers) SetUserPreferenceHandler(h UserPreferenceHandler) {
+	handler.UserPreference = h
+}
@@ -0,0 +1,140 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+const (
+	UnknownPayloadKindField           UnknownPayloadKind = "field"
+	UnknownPayloadKindMessageType     UnknownPayloadKind = "message_type"
+	UnknownPayloadKindInteractiveType UnknownPayloadKind = "interactive_type"
+)
+
+type (
+	// UnknownPayloadKind tells which part of a notification was not recognized.
+	UnknownPayloadKind string
+
+	// UnknownPayload describes a change field, message type or interactive type that the
+	// Handlers do not know about. Raw is the JSON of the change value for unknown fields and
+	// of the message otherwise. It is taken from the payload received by the webhooks.Listener
+	// when available, so it includes the fields the Go types do not model.
+	UnknownPayload struct {
+		Kind  UnknownPayloadKind
+		Field string
+		Type  string
+		Raw   json.RawMessage
+	}
+
+	UnknownPayloadHandler = ChangeValueHandler[UnknownPayload]
+	OnUnknownPayloadHook  = ChangeValueHandlerFunc[UnknownPayload]
+)
+
+// SetStrictMode turns on strict mode. Change fields other than messages and
+// smb_message_echoes, message types ParseType does not recognize and unknown interactive
+// types are passed to handler instead of the fallback handlers. With a nil handler they are
+// returned as ErrUnknownPayload errors and the notification fails.
+func (handler *Handlers) SetStrictMode(h UnknownPayloadHandler) {
+	handler.Strict = true
+	handler.UnknownPayload = h
+}
+
+// payloadPath locates a change value or a message within the raw notification payload.
+type payloadPath struct {
+	entry   int
+	change  int
+	message int
+}
+
+func (handler *Handlers) handleUnknownPayload(ctx context.Context, nctx *NotificationContext,
+	payload *UnknownPayload,
+) error {
+	if handler.UnknownPayload == nil {
+		return fmt.Errorf("%w: %s %q", ErrUnknownPayload, payload.Kind, payload.Type)
+	}
+
+	if err := handler.UnknownPayload.Handle(ctx, nctx, payload); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknownPayloadHandler, err)
+	}
+
+	return nil
+}
+
+// rawValue returns the raw change value at path, falling back to encoding value.
+func rawValue(ctx context.Context, path payloadPath, value any) json.RawMessage {
+	if raw := lookupRaw(ctx, path, false); raw != nil {
+		return raw
+	}
+
+	encoded, _ := json.Marshal(value)
+
+	return encoded
+}
+
+// rawMessage returns the raw message at path, falling back to encoding message.
+func rawMessage(ctx context.Context, path payloadPath, message *Message) json.RawMessage {
+	if raw := lookupRaw(ctx, path, true); raw != nil {
+		return raw
+	}
+
+	encoded, _ := json.Marshal(message)
+
+	return encoded
+}
+
+func lookupRaw(ctx context.Context, path payloadPath, message bool) json.RawMessage {
+	payload, ok := webhooks.RawPayloadFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var notification struct {
+		Entry []struct {
+			Changes []struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+
+	if err := json.Unmarshal(payload, &notification); err != nil ||
+		path.entry >= len(notification.Entry) || path.change >= len(notification.Entry[path.entry].Changes) {
+		return nil
+	}
+
+	value := notification.Entry[path.entry].Changes[path.change].Value
+	if !message {
+		return value
+	}
+
+	var messages struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+
+	if err := json.Unmarshal(value, &messages); err != nil || path.message >= len(messages.Messages) {
+		return nil
+	}
+
+	return messages.Messages[path.message]
+}
@@ -0,0 +1,87 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const unknownTypePayload = `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+{"field":"messages","value":{"messaging_product":"whatsapp","messages":[
+{"from":"255700000000","id":"wamid.1","timestamp":"1","type":"text","text":{"body":"hi"}},
+{"from":"255700000000","id":"wamid.2","timestamp":"1","type":"hologram","hologram":{"depth":3}}]}},
+{"field":"brand_new_field","value":{"something":"new"}}]}]}`
+
+func TestHandlers_StrictMode(t *testing.T) {
+	t.Parallel()
+
+	var (
+		texts   int
+		unknown []*message.UnknownPayload
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetTextMessageHandler(message.OnTextMessageHook(
+		func(context.Context, *message.NotificationContext, *message.Info, *message.Text) error {
+			texts++
+
+			return nil
+		}))
+	handlers.SetStrictMode(message.OnUnknownPayloadHook(
+		func(_ context.Context, _ *message.NotificationContext, payload *message.UnknownPayload) error {
+			unknown = append(unknown, payload)
+
+			return nil
+		}))
+
+	listener := webhooks.NewListener(handlers.HandleNotification, nil, &webhooks.ValidateOptions{})
+	recorder := httptest.NewRecorder()
+	listener.HandleNotification(recorder,
+		httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(unknownTypePayload)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d", recorder.Code)
+	}
+
+	if texts != 1 || len(unknown) != 2 {
+		t.Fatalf("texts = %d, unknown = %d", texts, len(unknown))
+	}
+
+	if unknown[0].Kind != message.UnknownPayloadKindMessageType || unknown[0].Type != "hologram" ||
+		!strings.Contains(string(unknown[0].Raw), `"hologram":{"depth":3}`) {
+		t.Errorf("unexpected unknown message: %+v (%s)", unknown[0], unknown[0].Raw)
+	}
+
+	var value map[string]string
+	if err := json.Unmarshal(unknown[1].Raw, &value); err != nil || value["something"] != "new" ||
+		unknown[1].Kind != message.UnknownPayloadKindField {
+		t.Errorf("unexpected unknown field: %+v (%s)", unknown[1], unknown[1].Raw)
+	}
+}
+
+func TestHandlers_StrictModeWithoutHandler(t *testing.T) {
+	t.Parallel()
+
+	var notification message.Notification
+	if err := json.Unmarshal([]byte(unknownTypePayload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := &message.Handlers{
+		Strict: true,
+		TextMessage: message.OnTextMessageHook(
+			func(context.Context, *message.NotificationContext, *message.Info, *message.Text) error {
+				return nil
+			}),
+	}
+
+	if response := handlers.HandleNotification(context.Background(), &notification); response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/pkg/crypto"
+	"github.com/piusalfred/whatsapp/webhooks/edge"
+)
+
+type (
+	// MirrorTarget is a downstream endpoint that receives a copy of every validated
+	// notification. When AppSecret is set the payload is re-signed with it, so the
+	// downstream system can keep validating X-Hub-Signature-256 with its own secret.
+	// Signer additionally signs the payload with a rotating key in MirrorSignatureHeader,
+	// which the downstream system checks with crypto.Signer.VerifyDetached.
+	MirrorTarget struct {
+		URL       string
+		AppSecret string
+		Signer    *crypto.Signer
+		Headers   map[string]string
+	}
+
+	// Mirror forwards notification payloads to additional downstream URLs. It is meant for
+	// migrations between processing systems: the Listener keeps handling notifications
+	// while the new system receives the same events.
+	Mirror struct {
+		targets []*MirrorTarget
+		client  *http.Client
+		timeout time.Duration
+		onError func(ctx context.Context, target *MirrorTarget, err error)
+		wg      sync.WaitGroup
+	}
+
+	MirrorOption func(*Mirror)
+)
+
+// WithMirrorHTTPClient sets the http client used to forward payloads.
+func WithMirrorHTTPClient(client *http.Client) MirrorOption {
+	return func(m *Mirror) {
+		m.client = client
+	}
+}
+
+// WithMirrorTimeout bounds every forward request. The default is 10 seconds.
+func WithMirrorTimeout(timeout time.Duration) MirrorOption {
+	return func(m *Mirror) {
+		m.timeout = timeout
+	}
+}
+
+// WithMirrorErrorHandler sets the function called when forwarding to a target fails
+// during Dispatch.
+func WithMirrorErrorHandler(fn func(ctx context.Context, target *MirrorTarget, err error)) MirrorOption {
+	return func(m *Mirror) {
+		m.onError = fn
+	}
+}
+
+func NewMirror(targets []*MirrorTarget, options ...MirrorOption) *Mirror {
+	m := &Mirror{
+		targets: targets,
+		client:  http.DefaultClient,
+		timeout: 10 * time.Second, //nolint:mnd // default timeout
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(m)
+		}
+	}
+
+	return m
+}
+
+// Forward sends payload to all targets and waits for them. Failed targets do not stop
+// the others, their errors are joined.
+func (m *Mirror) Forward(ctx context.Context, payload []byte) error {
+	errs := make([]error, len(m.targets))
+
+	var wg sync.WaitGroup
+	for i, target := range m.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.forward(ctx, target, payload)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Dispatch forwards payload in the background so that the response to Meta is not
+// delayed by downstream systems. Errors are reported to the error handler. The request
+// context is not used for cancellation since it ends when the response is written.
+func (m *Mirror) Dispatch(ctx context.Context, payload []byte) {
+	ctx = context.WithoutCancel(ctx)
+	for _, target := range m.targets {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			if err := m.forward(ctx, target, payload); err != nil && m.onError != nil {
+				m.onError(ctx, target, err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until all payloads passed to Dispatch have been forwarded.
+func (m *Mirror) Wait() {
+	m.wg.Wait()
+}
+
+func (m *Mirror) forward(ctx context.Context, target *MirrorTarget, payload []byte) error {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMirrorForward, target.URL, err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range target.Headers {
+		request.Header.Set(key, value)
+	}
+
+	if target.AppSecret != "" {
+		request.Header.Set(SignatureHeaderKey, SignPayload(payload, target.AppSecret))
+	}
+
+	if target.Signer != nil {
+		signature, err := target.Signer.SignDetached(ctx, payload)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrMirrorForward, target.URL, err)
+		}
+		request.Header.Set(MirrorSignatureHeader, signature)
+	}
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMirrorForward, target.URL, err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s: status %d", ErrMirrorForward, target.URL, response.StatusCode)
+	}
+
+	return nil
+}
+
+// SignPayload returns the X-Hub-Signature-256 header value for payload, in the same
+// "sha256=<hex>" form Meta uses.
+func SignPayload(payload []byte, appSecret string) string {
+	return edge.Sign(payload, appSecret)
+}
+
+// MirrorSignatureHeader carries the signature made by MirrorTarget.Signer.
+const MirrorSignatureHeader = "X-Mirror-Signature"
@@ -0,0 +1,109 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+func TestListenerMirror(t *testing.T) {
+	t.Parallel()
+
+	const (
+		payload          = `{"object":"whatsapp_business_account","entry":[]}`
+		appSecret        = "meta-secret"
+		downstreamSecret = "downstream-secret"
+	)
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer downstream.Close()
+
+	var forwardErr error
+	mirror := webhooks.NewMirror([]*webhooks.MirrorTarget{
+		{URL: downstream.URL, AppSecret: downstreamSecret},
+	}, webhooks.WithMirrorErrorHandler(func(_ context.Context, _ *webhooks.MirrorTarget, err error) {
+		forwardErr = err
+	}))
+
+	handler := func(_ context.Context, _ *map[string]any) *webhooks.Response {
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	}
+
+	listener := webhooks.NewListener[map[string]any](handler, nil, &webhooks.ValidateOptions{
+		Validate:  true,
+		AppSecret: appSecret,
+	})
+	listener.Mirror = mirror
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(payload))
+	req.Header.Set(webhooks.SignatureHeaderKey, webhooks.SignPayload([]byte(payload), appSecret))
+	rec := httptest.NewRecorder()
+	listener.HandleNotification(rec, req)
+	mirror.Wait()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if forwardErr != nil {
+		t.Fatalf("forward: %v", forwardErr)
+	}
+
+	forwarded := <-received
+	body := <-bodies
+	if string(body) != payload {
+		t.Errorf("forwarded body = %q, want %q", body, payload)
+	}
+
+	if err := webhooks.ValidatePayloadSignature(forwarded.Header, body, downstreamSecret); err != nil {
+		t.Errorf("forwarded payload not re-signed: %v", err)
+	}
+}
+
+func TestListenerMirrorSkipsInvalidPayloads(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer downstream.Close()
+
+	mirror := webhooks.NewMirror([]*webhooks.MirrorTarget{{URL: downstream.URL}})
+	handler := func(_ context.Context, _ *map[string]any) *webhooks.Response {
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	}
+
+	listener := webhooks.NewListener[map[string]any](handler, nil, &webhooks.ValidateOptions{
+		Validate:  true,
+		AppSecret: "secret",
+	})
+	listener.Mirror = mirror
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(`{}`))
+	req.Header.Set(webhooks.SignatureHeaderKey, webhooks.SignPayload([]byte(`{}`), "wrong"))
+	rec := httptest.NewRecorder()
+	listener.HandleNotification(rec, req)
+	mirror.Wait()
+
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected invalid signature to be rejected")
+	}
+
+	if calls != 0 {
+		t.Errorf("mirror called %d times for an invalid payload", calls)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/piusalfred/whatsapp/pkg/pretty"
+)
+
+// PrettyPrintMiddleware writes every decoded notification to w as a tree rendered by
+// printer before handing it to the next handler. It is meant for development listeners,
+// a nil printer renders without colors.
+func PrettyPrintMiddleware[T any](w io.Writer, printer *pretty.Printer) HandleMiddleware[T] {
+	if printer == nil {
+		printer = &pretty.Printer{}
+	}
+
+	var mu sync.Mutex
+
+	return func(next NotificationHandlerFunc[T]) NotificationHandlerFunc[T] {
+		return func(ctx context.Context, notification *T) *Response {
+			mu.Lock()
+			_, _ = fmt.Fprintf(w, "--- %T\n", notification)
+			_ = printer.Fprint(w, notification)
+			mu.Unlock()
+
+			return next(ctx, notification)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package publish forwards normalized webhook events to a message broker, turning the
+// webhook listener into the ingestion edge of an event driven system.
+//
+// The core only defines the Publisher contract and how events are turned into broker
+// messages, so it does not depend on a broker client. Every message is keyed by the
+// customer wa_id, so brokers that partition by key keep the events of a customer in order.
+// Reference implementations for segmentio/kafka-go and NATS live in the optional
+// extras/publish module.
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/events"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+// DefaultTopic is the topic events are published to unless configured otherwise.
+const DefaultTopic = "whatsapp.events"
+
+const (
+	HeaderEventID     = "whatsapp-event-id"
+	HeaderEventKind   = "whatsapp-event-kind"
+	HeaderContentType = "content-type"
+	ContentTypeJSON   = "application/json"
+)
+
+type (
+	// Message is an event serialized for a broker. Key is the partition key, see
+	// events.Event.Key.
+	Message struct {
+		Topic   string
+		Key     string
+		Value   []byte
+		Headers map[string]string
+	}
+
+	// Publisher writes messages to a broker. Publish returns once the broker accepted all
+	// the messages, or an error. Webhooks can be delivered more than once so consumers
+	// should deduplicate on HeaderEventID.
+	Publisher interface {
+		Publish(ctx context.Context, messages ...*Message) error
+	}
+
+	PublisherFunc func(ctx context.Context, messages ...*Message) error
+
+	// Encoder serializes an event, EncodeJSON by default.
+	Encoder func(event *events.Event) (value []byte, contentType string, err error)
+
+	// TopicFunc picks the topic of an event.
+	TopicFunc func(event *events.Event) string
+
+	ForwarderOption func(*Forwarder)
+
+	// Forwarder normalizes notifications and publishes their events.
+	Forwarder struct {
+		publisher Publisher
+		encode    Encoder
+		topic     TopicFunc
+	}
+)
+
+func (fn PublisherFunc) Publish(ctx context.Context, messages ...*Message) error {
+	return fn(ctx, messages...)
+}
+
+// EncodeJSON encodes the event as JSON.
+func EncodeJSON(event *events.Event) ([]byte, string, error) {
+	value, err := json.Marshal(event)
+
+	return value, ContentTypeJSON, err
+}
+
+// WithEncoder sets how events are serialized.
+func WithEncoder(encode Encoder) ForwarderOption {
+	return func(f *Forwarder) {
+		f.encode = encode
+	}
+}
+
+// WithTopic publishes every event to topic.
+func WithTopic(topic string) ForwarderOption {
+	return func(f *Forwarder) {
+		f.topic = func(*events.Event) string { return topic }
+	}
+}
+
+// WithTopicFunc picks the topic of every event with fn, for example one topic per kind:
+//
+//	publish.WithTopicFunc(func(e *events.Event) string { return "whatsapp." + string(e.Kind) })
+func WithTopicFunc(fn TopicFunc) ForwarderOption {
+	return func(f *Forwarder) {
+		f.topic = fn
+	}
+}
+
+// NewForwarder creates a Forwarder publishing to publisher.
+func NewForwarder(publisher Publisher, options ...ForwarderOption) *Forwarder {
+	f := &Forwarder{
+		publisher: publisher,
+		encode:    EncodeJSON,
+		topic:     func(*events.Event) string { return DefaultTopic },
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(f)
+		}
+	}
+
+	return f
+}
+
+// Forward serializes and publishes events in a single Publish call.
+func (f *Forwarder) Forward(ctx context.Context, evts ...*events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	messages := make([]*Message, 0, len(evts))
+	for _, event := range evts {
+		value, contentType, err := f.encode(event)
+		if err != nil {
+			return fmt.Errorf("%w: event %q: %w", ErrEncodeEvent, event.ID, err)
+		}
+
+		messages = append(messages, &Message{
+			Topic: f.topic(event),
+			Key:   event.Key(),
+			Value: value,
+			Headers: map[string]string{
+				HeaderEventID:     event.ID,
+				HeaderEventKind:   string(event.Kind),
+				HeaderContentType: contentType,
+			},
+		})
+	}
+
+	if err := f.publisher.Publish(ctx, messages...); err != nil {
+		return fmt.Errorf("%w: %w", ErrPublish, err)
+	}
+
+	return nil
+}
+
+// HandleNotification publishes the events of notification. It answers 500 when publishing
+// fails so that WhatsApp delivers the notification again.
+func (f *Forwarder) HandleNotification(ctx context.Context, notification *message.Notification) *webhooks.Response {
+	if err := f.Forward(ctx, events.Normalize(notification)...); err != nil {
+		return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+	}
+
+	return &webhooks.Response{StatusCode: http.StatusOK}
+}
+
+// Middleware publishes the events of every notification before handing it to the next
+// handler. When publishing fails the next handler is skipped and the webhook answers 500.
+func (f *Forwarder) Middleware() webhooks.HandleMiddleware[message.Notification] {
+	return func(next webhooks.NotificationHandlerFunc[message.Notification],
+	) webhooks.NotificationHandlerFunc[message.Notification] {
+		return func(ctx context.Context, notification *message.Notification) *webhooks.Response {
+			if err := f.Forward(ctx, events.Normalize(notification)...); err != nil {
+				return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+			}
+
+			return next(ctx, notification)
+		}
+	}
+}
+
+// publishError is a custom error type for publish errors.
+type publishError string
+
+func (e publishError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrEncodeEvent = publishError("encode event")
+	ErrPublish     = publishError("publish events")
+)
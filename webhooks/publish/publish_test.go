@@ -0,0 +1,102 @@
+package publish_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/events"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+	"github.com/piusalfred/whatsapp/webhooks/publish"
+)
+
+func testNotification() *message.Notification {
+	return &message.Notification{Entry: []*message.Entry{{
+		ID: "waba",
+		Changes: []*message.Change{{
+			Field: message.ChangeFieldMessages,
+			Value: &message.Value{
+				Metadata: &message.Metadata{PhoneNumberID: "phone"},
+				Messages: []*message.Message{{
+					From: "255700000001", ID: "wamid.in", Type: "text", Text: &message.Text{Body: "hi"},
+				}},
+				Statuses: []*message.Status{{ID: "wamid.out", StatusValue: "sent", RecipientID: "255700000002"}},
+			},
+		}},
+	}}}
+}
+
+func TestForwarder(t *testing.T) {
+	t.Parallel()
+
+	var published []*publish.Message
+	publisher := publish.PublisherFunc(func(_ context.Context, messages ...*publish.Message) error {
+		published = append(published, messages...)
+
+		return nil
+	})
+
+	forwarder := publish.NewForwarder(publisher, publish.WithTopicFunc(func(e *events.Event) string {
+		return "whatsapp." + string(e.Kind)
+	}))
+
+	var handled bool
+	handler := forwarder.Middleware()(func(context.Context, *message.Notification) *webhooks.Response {
+		handled = true
+
+		return &webhooks.Response{StatusCode: http.StatusOK}
+	})
+
+	response := handler(context.Background(), testNotification())
+	if response.StatusCode != http.StatusOK || !handled {
+		t.Fatalf("response = %+v, handled = %t", response, handled)
+	}
+
+	type summary struct {
+		Topic, Key, ID, Kind string
+	}
+
+	got := make([]summary, 0, len(published))
+	for _, msg := range published {
+		headers := msg.Headers
+		got = append(got, summary{msg.Topic, msg.Key, headers[publish.HeaderEventID], headers[publish.HeaderEventKind]})
+	}
+
+	want := []summary{
+		{"whatsapp.message", "255700000001", "wamid.in", "message"},
+		{"whatsapp.status", "255700000002", "wamid.out:sent", "status"},
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("published mismatch (-want +got):\n%s", diff)
+	}
+
+	var event events.Event
+	if err := json.Unmarshal(published[0].Value, &event); err != nil || event.Message.Text.Body != "hi" {
+		t.Errorf("decode published event: %+v, %v", event, err)
+	}
+}
+
+func TestForwarder_PublishError(t *testing.T) {
+	t.Parallel()
+
+	brokerDown := errors.New("broker down")
+	forwarder := publish.NewForwarder(publish.PublisherFunc(func(context.Context, ...*publish.Message) error {
+		return brokerDown
+	}), publish.WithTopic("events"))
+
+	err := forwarder.Forward(context.Background(), events.Normalize(testNotification())...)
+	if !errors.Is(err, publish.ErrPublish) || !errors.Is(err, brokerDown) {
+		t.Errorf("Forward() error = %v", err)
+	}
+
+	response := forwarder.HandleNotification(context.Background(), testNotification())
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusInternalServerError)
+	}
+}
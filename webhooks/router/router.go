@@ -0,0 +1,123 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package router mounts webhook listeners onto HTTP routers.
+//
+// A webhook endpoint answers the subscription verification on GET and receives the
+// notifications on POST, both on the same path. Endpoints describes the two routes of a
+// listener, and the Mount functions register them on a router:
+//
+//	mux := http.NewServeMux()
+//	router.Mount(mux, "/webhooks/messages", messageListener)
+//	router.Mount(mux, "/webhooks/business", businessListener)
+//
+// chi routers are used directly, see MountChi. Other frameworks take a net/http handler
+// through the adapter they ship, which is passed to MountFunc:
+//
+//	// echo
+//	router.MountFunc("/webhooks", listener, func(method, path string, handler http.Handler) {
+//		e.Add(method, path, echo.WrapHandler(handler))
+//	})
+//
+//	// gin
+//	router.MountFunc("/webhooks", listener, func(method, path string, handler http.Handler) {
+//		r.Handle(method, path, gin.WrapH(handler))
+//	})
+//
+//	// fiber, with github.com/gofiber/fiber/v2/middleware/adaptor
+//	router.MountFunc("/webhooks", listener, func(method, path string, handler http.Handler) {
+//		app.Add(method, path, adaptor.HTTPHandler(handler))
+//	})
+package router
+
+import (
+	"net/http"
+)
+
+type (
+	// Listener handles the requests of a webhook endpoint. It is implemented by
+	// webhooks.Listener.
+	Listener interface {
+		HandleSubscriptionVerification(writer http.ResponseWriter, request *http.Request)
+		HandleNotification(writer http.ResponseWriter, request *http.Request)
+	}
+
+	// Endpoint is a route of a webhook endpoint.
+	Endpoint struct {
+		Method  string
+		Path    string
+		Handler http.Handler
+	}
+
+	// HandleFunc registers handler for the requests with method on path. It adapts
+	// MountFunc to a router.
+	HandleFunc func(method, path string, handler http.Handler)
+
+	// ChiRouter is the part of chi.Router used by MountChi.
+	ChiRouter interface {
+		Method(method, pattern string, handler http.Handler)
+	}
+)
+
+// Endpoints returns the routes of listener on path: the subscription verification on GET
+// and the notifications on POST.
+func Endpoints(path string, listener Listener) []Endpoint {
+	return []Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    path,
+			Handler: http.HandlerFunc(listener.HandleSubscriptionVerification),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    path,
+			Handler: http.HandlerFunc(listener.HandleNotification),
+		},
+	}
+}
+
+// MountFunc registers the endpoints of listener on path with handle.
+func MountFunc(path string, listener Listener, handle HandleFunc) {
+	for _, endpoint := range Endpoints(path, listener) {
+		handle(endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// Mount registers the endpoints of listener on mux with method qualified patterns, like
+// "POST /webhooks", so that the mux answers other methods with 405 Method Not Allowed.
+func Mount(mux *http.ServeMux, path string, listener Listener) {
+	MountFunc(path, listener, func(method, path string, handler http.Handler) {
+		mux.Handle(method+" "+path, handler)
+	})
+}
+
+// NewServeMux returns a http.ServeMux serving the listeners, keyed by their path.
+func NewServeMux(listeners map[string]Listener) *http.ServeMux {
+	mux := http.NewServeMux()
+	for path, listener := range listeners {
+		Mount(mux, path, listener)
+	}
+
+	return mux
+}
+
+// MountChi registers the endpoints of listener on path with a chi router.
+func MountChi(router ChiRouter, path string, listener Listener) {
+	MountFunc(path, listener, router.Method)
+}
@@ -0,0 +1,71 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/router"
+)
+
+type listener struct{}
+
+func (listener) HandleSubscriptionVerification(writer http.ResponseWriter, _ *http.Request) {
+	_, _ = writer.Write([]byte("verify"))
+}
+
+func (listener) HandleNotification(writer http.ResponseWriter, _ *http.Request) {
+	_, _ = writer.Write([]byte("notify"))
+}
+
+func TestMount(t *testing.T) {
+	t.Parallel()
+
+	mux := router.NewServeMux(map[string]router.Listener{"/webhooks/messages": listener{}})
+
+	tests := []struct {
+		method     string
+		wantStatus int
+		wantBody   string
+	}{
+		{method: http.MethodGet, wantStatus: http.StatusOK, wantBody: "verify"},
+		{method: http.MethodPost, wantStatus: http.StatusOK, wantBody: "notify"},
+		{method: http.MethodPut, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/webhooks/messages", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+type chiRouter []string
+
+func (r *chiRouter) Method(method, pattern string, _ http.Handler) {
+	*r = append(*r, method+" "+pattern)
+}
+
+func TestMountChi(t *testing.T) {
+	t.Parallel()
+
+	var r chiRouter
+	router.MountChi(&r, "/webhooks", listener{})
+
+	if diff := gcmp.Diff(chiRouter{"GET /webhooks", "POST /webhooks"}, r); diff != "" {
+		t.Errorf("routes mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+)
+
+// Attribute keys set on handler spans.
+const (
+	AttributeHandler     = "whatsapp.webhook.handler"
+	AttributeField       = "whatsapp.webhook.field"
+	AttributeMessageType = "whatsapp.webhook.message_type"
+	AttributeOverBudget  = "whatsapp.webhook.over_budget"
+)
+
+type (
+	// Budget is the time a handler is expected to complete within. A handler over budget
+	// is reported to the HandlerObserver and, when Cancel is set, its context is cancelled
+	// once the budget is spent. A zero Limit means no budget.
+	Budget struct {
+		Limit  time.Duration
+		Cancel bool
+	}
+
+	RegisterOption func(budget *Budget)
+
+	// Tracer starts a span around every handler run. Its method matches the one of
+	// whttp.Telemetry, so the adapter used to trace the API requests fits here too.
+	Tracer interface {
+		StartSpan(ctx context.Context, name string, attributes []whttp.Attribute) (context.Context, whttp.Span)
+	}
+
+	// HandlerRun describes a finished handler run.
+	HandlerRun struct {
+		Handler     string
+		Field       string
+		MessageType string
+		Duration    time.Duration
+		Budget      time.Duration
+		OverBudget  bool
+		Err         error
+	}
+
+	// HandlerObserver is called after every handler run, to log the ones over budget or
+	// record their durations.
+	HandlerObserver func(ctx context.Context, run *HandlerRun)
+
+	// HandlerStats aggregates the runs of a handler.
+	HandlerStats struct {
+		Handler    string
+		Runs       int
+		Errors     int
+		OverBudget int
+		Total      time.Duration
+		Max        time.Duration
+	}
+
+	RouterOption func(router *Router)
+
+	namedHandler struct {
+		name    string
+		handler Handler
+		budget  Budget
+	}
+
+	handlerStats struct {
+		mu    sync.Mutex
+		stats map[string]*HandlerStats
+	}
+)
+
+// WithBudget sets the latency budget of the handler being registered. With cancel the
+// context passed to the handler is cancelled when the budget is spent.
+func WithBudget(limit time.Duration, cancel bool) RegisterOption {
+	return func(budget *Budget) {
+		budget.Limit = limit
+		budget.Cancel = cancel
+	}
+}
+
+// WithTracer traces every handler run with a span named after the handler.
+func WithTracer(tracer Tracer) RouterOption {
+	return func(router *Router) {
+		router.tracer = tracer
+	}
+}
+
+// WithHandlerObserver sets the function called after every handler run.
+func WithHandlerObserver(observer HandlerObserver) RouterOption {
+	return func(router *Router) {
+		router.observer = observer
+	}
+}
+
+// Mean returns the average duration of the runs.
+func (s HandlerStats) Mean() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+
+	return s.Total / time.Duration(s.Runs)
+}
+
+// HandlerSpanName returns the name of the spans of the handler registered as name, for
+// example "webhook handler orders".
+func HandlerSpanName(name string) string {
+	return "webhook handler " + name
+}
+
+// bind looks up the handler registered as name along with its budget.
+func (r *Registry) bind(name string) (*namedHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h, ok := r.handlers[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &namedHandler{name: name, handler: h, budget: r.budgets[name]}, true
+}
+
+// SlowestHandlers returns the stats of up to n handlers, the slowest on average first. A
+// n of zero or less returns all of them.
+func (router *Router) SlowestHandlers(n int) []HandlerStats {
+	router.stats.mu.Lock()
+	stats := make([]HandlerStats, 0, len(router.stats.stats))
+	for _, s := range router.stats.stats {
+		stats = append(stats, *s)
+	}
+	router.stats.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Mean() != stats[j].Mean() {
+			return stats[i].Mean() > stats[j].Mean()
+		}
+
+		return stats[i].Handler < stats[j].Handler
+	})
+
+	if n > 0 && n < len(stats) {
+		stats = stats[:n]
+	}
+
+	return stats
+}
+
+// run calls the handler within its budget, tracing and measuring the run.
+func (router *Router) run(ctx context.Context, h *namedHandler, event *Event) error {
+	var span whttp.Span
+	if router.tracer != nil {
+		ctx, span = router.tracer.StartSpan(ctx, HandlerSpanName(h.name), []whttp.Attribute{
+			{Key: AttributeHandler, Value: h.name},
+			{Key: AttributeField, Value: event.Field},
+			{Key: AttributeMessageType, Value: event.MessageType},
+		})
+	}
+
+	if h.budget.Cancel && h.budget.Limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.budget.Limit)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := h.handler.Handle(ctx, event)
+	run := &HandlerRun{
+		Handler:     h.name,
+		Field:       event.Field,
+		MessageType: event.MessageType,
+		Duration:    time.Since(start),
+		Budget:      h.budget.Limit,
+		Err:         err,
+	}
+	run.OverBudget = run.Budget > 0 && (run.Duration > run.Budget || errors.Is(err, context.DeadlineExceeded))
+
+	if span != nil {
+		span.SetAttributes(whttp.Attribute{Key: AttributeOverBudget, Value: run.OverBudget})
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+
+	router.stats.record(run)
+	if router.observer != nil {
+		router.observer(ctx, run)
+	}
+
+	return err
+}
+
+func newHandlerStats() *handlerStats {
+	return &handlerStats{stats: make(map[string]*HandlerStats)}
+}
+
+func (s *handlerStats) record(run *HandlerRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.stats[run.Handler]
+	if !ok {
+		stats = &HandlerStats{Handler: run.Handler}
+		s.stats[run.Handler] = stats
+	}
+
+	stats.Runs++
+	stats.Total += run.Duration
+	stats.Max = max(stats.Max, run.Duration)
+	if run.Err != nil {
+		stats.Errors++
+	}
+	if run.OverBudget {
+		stats.OverBudget++
+	}
+}
@@ -0,0 +1,68 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks/routing"
+)
+
+func TestRouter_HandlerBudgets(t *testing.T) {
+	t.Parallel()
+
+	registry := routing.NewRegistry()
+	if err := registry.Register("fast", routing.HandlerFunc(func(context.Context, *routing.Event) error {
+		return nil
+	}), routing.WithBudget(time.Second, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.Register("slow", routing.HandlerFunc(func(ctx context.Context, _ *routing.Event) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}), routing.WithBudget(10*time.Millisecond, true)); err != nil {
+		t.Fatal(err)
+	}
+
+	table := &routing.Table{Routes: []routing.Route{
+		{Field: "messages", MessageType: "text", Handler: "fast"},
+		{Field: "messages", MessageType: "image", Handler: "slow"},
+	}}
+
+	var runs []*routing.HandlerRun
+	router, err := routing.NewRouter(table, registry, routing.WithHandlerObserver(
+		func(_ context.Context, run *routing.HandlerRun) {
+			runs = append(runs, run)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := &routing.Notification{Entry: []*routing.Entry{{Changes: []*routing.Change{
+		{Field: "messages", Value: []byte(`{"messages":[{"type":"text"},{"type":"text"}]}`)},
+	}}}}
+	if err := router.Dispatch(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	notification.Entry[0].Changes[0].Value = []byte(`{"messages":[{"type":"image"}]}`)
+	if err := router.Dispatch(context.Background(), notification); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Dispatch() error = %v, want the slow handler cancelled", err)
+	}
+
+	if len(runs) != 3 || runs[0].OverBudget || !runs[2].OverBudget || runs[2].Handler != "slow" {
+		t.Errorf("runs = %+v", runs)
+	}
+
+	slowest := router.SlowestHandlers(1)
+	if len(slowest) != 1 || slowest[0].Handler != "slow" || slowest[0].OverBudget != 1 || slowest[0].Errors != 1 {
+		t.Errorf("SlowestHandlers(1) = %+v", slowest)
+	}
+
+	if all := router.SlowestHandlers(0); len(all) != 2 || all[1].Runs != 2 {
+		t.Errorf("SlowestHandlers(0) = %+v", all)
+	}
+}
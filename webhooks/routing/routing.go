@@ -0,0 +1,459 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package routing provides a declarative dispatch layer for webhook notifications.
+//
+// Instead of wiring handlers imperatively, routes are described in a Table that maps a
+// change field (and optionally a message type) to the name of a handler. The names are
+// resolved against a Registry when the Router is created, so a misconfigured table is
+// reported at startup rather than when the first notification arrives.
+//
+// A Table can be decoded from JSON with LoadTable, the extras/webhookd module decodes it
+// from YAML with the same keys.
+//
+// Handlers can be registered with a latency budget. The Router traces and measures every
+// handler run, reports the ones over budget and keeps a report of the slowest handlers.
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+const (
+	// FieldMessages is the change field used by the messages webhooks. Routes for this field
+	// may specify a MessageType to dispatch individual messages.
+	FieldMessages = "messages"
+)
+
+type (
+	// Route maps a change field, and optionally a message type, to a named handler.
+	// MessageType is only meaningful for the "messages" field.
+	Route struct {
+		Field       string `json:"field"                  yaml:"field"`
+		MessageType string `json:"message_type,omitempty" yaml:"message_type,omitempty"`
+		Handler     string `json:"handler"                yaml:"handler"`
+	}
+
+	// Table is the declarative routing configuration. Default names the handler used when
+	// no route matches, if empty unmatched changes are ignored.
+	Table struct {
+		Routes  []Route `json:"routes"            yaml:"routes"`
+		Default string  `json:"default,omitempty" yaml:"default,omitempty"`
+	}
+
+	// Notification is the common envelope shared by all webhook notifications. Change values
+	// are kept raw so that each handler can decode the part it is interested in.
+	Notification struct {
+		Object string   `json:"object"`
+		Entry  []*Entry `json:"entry"`
+	}
+
+	Entry struct {
+		ID      string    `json:"id"`
+		Time    int64     `json:"time"`
+		Changes []*Change `json:"changes"`
+	}
+
+	Change struct {
+		Field string          `json:"field"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	// Event is what a routed handler receives. Value is the raw change value and Message is
+	// the raw message when the event was dispatched per message, see Router.Dispatch.
+	Event struct {
+		Object      string
+		EntryID     string
+		EntryTime   int64
+		Field       string
+		MessageType string
+		Value       json.RawMessage
+		Message     json.RawMessage
+	}
+
+	Handler interface {
+		Handle(ctx context.Context, event *Event) error
+	}
+
+	HandlerFunc func(ctx context.Context, event *Event) error
+)
+
+func (fn HandlerFunc) Handle(ctx context.Context, event *Event) error {
+	return fn(ctx, event)
+}
+
+// LoadTable decodes a JSON routing table from r and validates its structure. Handler names
+// are checked later by NewRouter against a Registry.
+func LoadTable(r io.Reader) (*Table, error) {
+	var table Table
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&table); err != nil {
+		return nil, fmt.Errorf("%w: decode: %w", ErrInvalidTable, err)
+	}
+
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+// Validate checks that every route has a field and a handler, that message types are only
+// used with the messages field and that no two routes share the same key.
+func (t *Table) Validate() error {
+	var errs []error
+	seen := make(map[routeKey]int, len(t.Routes))
+	for i, route := range t.Routes {
+		if strings.TrimSpace(route.Field) == "" {
+			errs = append(errs, fmt.Errorf("route %d: %w", i, ErrMissingField))
+		}
+
+		if strings.TrimSpace(route.Handler) == "" {
+			errs = append(errs, fmt.Errorf("route %d: %w", i, ErrMissingHandlerName))
+		}
+
+		if route.MessageType != "" && route.Field != FieldMessages {
+			errs = append(errs, fmt.Errorf("route %d: %w: field %q", i, ErrMessageTypeNotAllowed, route.Field))
+		}
+
+		key := routeKey{field: route.Field, messageType: route.MessageType}
+		if j, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("route %d: %w: same as route %d", i, ErrDuplicateRoute, j))
+
+			continue
+		}
+		seen[key] = i
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidTable, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// Registry holds named handler implementations that a Table refers to.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	budgets  map[string]Budget
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler), budgets: make(map[string]Budget)}
+}
+
+// Register adds a handler under the given name. Registering the same name twice is an error.
+func (r *Registry) Register(name string, handler Handler, options ...RegisterOption) error {
+	if name == "" || handler == nil {
+		return fmt.Errorf("%w: name and handler are required", ErrInvalidRegistration)
+	}
+
+	var budget Budget
+	for _, option := range options {
+		if option != nil {
+			option(&budget)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[name]; ok {
+		return fmt.Errorf("%w: %q", ErrHandlerAlreadyRegistered, name)
+	}
+	r.handlers[name] = handler
+	r.budgets[name] = budget
+
+	return nil
+}
+
+// Lookup returns the handler registered under name.
+func (r *Registry) Lookup(name string) (Handler, bool) { //nolint:ireturn
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[name]
+
+	return h, ok
+}
+
+// Names returns the sorted names of all registered handlers.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type routeKey struct {
+	field       string
+	messageType string
+}
+
+var _ webhooks.NotificationHandler[Notification] = (*Router)(nil)
+
+// Router dispatches notifications according to a validated Table.
+type Router struct {
+	routes   map[routeKey]*namedHandler
+	fallback *namedHandler
+	tracer   Tracer
+	observer HandlerObserver
+	stats    *handlerStats
+}
+
+// NewRouter validates the table and binds every route to a handler from the registry.
+func NewRouter(table *Table, registry *Registry, options ...RouterOption) (*Router, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	routes := make(map[routeKey]*namedHandler, len(table.Routes))
+	for _, route := range table.Routes {
+		h, ok := registry.bind(route.Handler)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrHandlerNotFound, route.Handler))
+
+			continue
+		}
+		routes[routeKey{field: route.Field, messageType: route.MessageType}] = h
+	}
+
+	var fallback *namedHandler
+	if table.Default != "" {
+		h, ok := registry.bind(table.Default)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: default %q", ErrHandlerNotFound, table.Default))
+		}
+		fallback = h
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTable, errors.Join(errs...))
+	}
+
+	router := &Router{routes: routes, fallback: fallback, stats: newHandlerStats()}
+	for _, option := range options {
+		if option != nil {
+			option(router)
+		}
+	}
+
+	return router, nil
+}
+
+func (router *Router) HandleNotification(ctx context.Context, notification *Notification) *webhooks.Response {
+	if err := router.Dispatch(ctx, notification); err != nil {
+		return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+	}
+
+	return &webhooks.Response{StatusCode: http.StatusOK}
+}
+
+// Dispatch routes every change in the notification. Changes of the messages field that
+// carry messages are dispatched once per message, and the Value of each message event holds
+// only that message with the metadata and contacts of the change. Statuses, errors and any
+// other parts of the same value are then dispatched together as one event without a
+// MessageType, so a field route sees them as it would in a change without messages.
+func (router *Router) Dispatch(ctx context.Context, notification *Notification) error {
+	if notification == nil {
+		return nil
+	}
+
+	for _, entry := range notification.Entry {
+		for _, change := range entry.Changes {
+			event := &Event{
+				Object:    notification.Object,
+				EntryID:   entry.ID,
+				EntryTime: entry.Time,
+				Field:     change.Field,
+				Value:     change.Value,
+			}
+
+			if err := router.dispatchChange(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (router *Router) dispatchChange(ctx context.Context, event *Event) error {
+	if event.Field != FieldMessages {
+		return router.dispatch(ctx, event)
+	}
+
+	var value map[string]json.RawMessage
+	if err := json.Unmarshal(event.Value, &value); err != nil {
+		return router.dispatch(ctx, event)
+	}
+
+	var messages []json.RawMessage
+	if err := json.Unmarshal(value["messages"], &messages); err != nil || len(messages) == 0 {
+		// statuses, errors and other values without messages are routed by field.
+		return router.dispatch(ctx, event)
+	}
+
+	for _, raw := range messages {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return fmt.Errorf("%w: decode message type: %w", ErrDispatch, err)
+		}
+
+		parts := pickValue(value, "contacts")
+		parts["messages"] = json.RawMessage("[" + string(raw) + "]")
+		messageValue, err := encodeValue(parts)
+		if err != nil {
+			return err
+		}
+
+		messageEvent := *event
+		messageEvent.MessageType = header.Type
+		messageEvent.Message = raw
+		messageEvent.Value = messageValue
+		if err := router.dispatch(ctx, &messageEvent); err != nil {
+			return err
+		}
+	}
+
+	rest := make([]string, 0, len(value))
+	for key := range value {
+		if !isMessageValueKey(key) {
+			rest = append(rest, key)
+		}
+	}
+
+	if len(rest) == 0 {
+		return nil
+	}
+
+	restValue, err := encodeValue(pickValue(value, rest...))
+	if err != nil {
+		return err
+	}
+
+	restEvent := *event
+	restEvent.Value = restValue
+
+	return router.dispatch(ctx, &restEvent)
+}
+
+// isMessageValueKey reports whether key belongs to the message events of a change value
+// rather than to the event carrying the rest of it.
+func isMessageValueKey(key string) bool {
+	switch key {
+	case "messaging_product", "metadata", "messages", "contacts":
+		return true
+	default:
+		return false
+	}
+}
+
+// pickValue returns the messaging product and metadata of a change value together with
+// the given keys.
+func pickValue(value map[string]json.RawMessage, keys ...string) map[string]json.RawMessage {
+	parts := make(map[string]json.RawMessage, len(keys)+2) //nolint:mnd // messaging product and metadata
+	for _, key := range append([]string{"messaging_product", "metadata"}, keys...) {
+		if v, ok := value[key]; ok {
+			parts[key] = v
+		}
+	}
+
+	return parts
+}
+
+func encodeValue(parts map[string]json.RawMessage) (json.RawMessage, error) {
+	encoded, err := json.Marshal(parts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: encode value: %w", ErrDispatch, err)
+	}
+
+	return encoded, nil
+}
+
+// dispatch looks up the handler for the event, trying the (field, message type) route
+// first, then the field route and finally the default handler.
+func (router *Router) dispatch(ctx context.Context, event *Event) error {
+	h, ok := router.routes[routeKey{field: event.Field, messageType: event.MessageType}]
+	if !ok && event.MessageType != "" {
+		h, ok = router.routes[routeKey{field: event.Field}]
+	}
+
+	if !ok {
+		h = router.fallback
+	}
+
+	if h == nil {
+		return nil
+	}
+
+	if err := router.run(ctx, h, event); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDispatch, event.Field, err)
+	}
+
+	return nil
+}
+
+// Decode unmarshals a raw value or message carried by an Event into T.
+func Decode[T any](raw json.RawMessage) (*T, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodeEvent, err)
+	}
+
+	return &v, nil
+}
+
+// routingError is a custom error type for routing errors.
+type routingError string
+
+func (e routingError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrInvalidTable             = routingError("invalid routing table")
+	ErrMissingField             = routingError("route field is required")
+	ErrMissingHandlerName       = routingError("route handler name is required")
+	ErrMessageTypeNotAllowed    = routingError("message type is only allowed for the messages field")
+	ErrDuplicateRoute           = routingError("duplicate route")
+	ErrHandlerNotFound          = routingError("handler not registered")
+	ErrHandlerAlreadyRegistered = routingError("handler already registered")
+	ErrInvalidRegistration      = routingError("invalid handler registration")
+	ErrDispatch                 = routingError("dispatch failed")
+	ErrDecodeEvent              = routingError("decode event")
+)
@@ -0,0 +1,271 @@
+package routing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/routing"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) handler(name string) routing.HandlerFunc {
+	return func(_ context.Context, event *routing.Event) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, name+":"+event.Field+"/"+event.MessageType)
+
+		return nil
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{
+			name:  "valid table",
+			input: `{"routes":[{"field":"messages","message_type":"text","handler":"text"}],"default":"log"}`,
+		},
+		{
+			name:    "missing handler name",
+			input:   `{"routes":[{"field":"messages"}]}`,
+			wantErr: routing.ErrMissingHandlerName,
+		},
+		{
+			name:    "message type on non messages field",
+			input:   `{"routes":[{"field":"flows","message_type":"text","handler":"x"}]}`,
+			wantErr: routing.ErrMessageTypeNotAllowed,
+		},
+		{
+			name: "duplicate route",
+			input: `{"routes":[{"field":"flows","handler":"a"},
+				{"field":"flows","handler":"b"}]}`,
+			wantErr: routing.ErrDuplicateRoute,
+		},
+		{
+			name:    "unknown key",
+			input:   `{"routez":[]}`,
+			wantErr: routing.ErrInvalidTable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := routing.LoadTable(strings.NewReader(tt.input))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewRouterUnknownHandler(t *testing.T) {
+	t.Parallel()
+	table := &routing.Table{
+		Routes:  []routing.Route{{Field: "messages", Handler: "missing"}},
+		Default: "also-missing",
+	}
+
+	_, err := routing.NewRouter(table, routing.NewRegistry())
+	if !errors.Is(err, routing.ErrHandlerNotFound) {
+		t.Fatalf("expected ErrHandlerNotFound, got %v", err)
+	}
+}
+
+func TestRouterDispatch(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	registry := routing.NewRegistry()
+	for _, name := range []string{"text", "messages", "flows", "fallback"} {
+		if err := registry.Register(name, rec.handler(name)); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	if err := registry.Register("text", rec.handler("text")); !errors.Is(err, routing.ErrHandlerAlreadyRegistered) {
+		t.Fatalf("expected ErrHandlerAlreadyRegistered, got %v", err)
+	}
+
+	table := &routing.Table{
+		Routes: []routing.Route{
+			{Field: "messages", MessageType: "text", Handler: "text"},
+			{Field: "messages", Handler: "messages"},
+			{Field: "flows", Handler: "flows"},
+		},
+		Default: "fallback",
+	}
+
+	router, err := routing.NewRouter(table, registry)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+
+	payload := `{
+		"object": "whatsapp_business_account",
+		"entry": [{
+			"id": "1",
+			"changes": [
+				{"field": "messages", "value": {"messages": [{"type": "text"}, {"type": "image"}]}},
+				{"field": "messages", "value": {"messages": [{"type": "image"}]}},
+				{"field": "messages", "value": {"statuses": [{"id": "wamid"}]}},
+				{"field": "flows", "value": {}},
+				{"field": "account_update", "value": {}}
+			]
+		}]
+	}`
+
+	var notification routing.Notification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	response := router.HandleNotification(context.TODO(), &notification)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	want := []string{
+		"text:messages/text",
+		"messages:messages/image",
+		"messages:messages/image",
+		"messages:messages/",
+		"flows:flows/",
+		"fallback:account_update/",
+	}
+
+	if diff := gcmp.Diff(want, rec.calls); diff != "" {
+		t.Errorf("dispatch mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterDispatchMixedValue(t *testing.T) {
+	t.Parallel()
+
+	var events []*routing.Event
+	capture := routing.HandlerFunc(func(_ context.Context, event *routing.Event) error {
+		events = append(events, event)
+
+		return nil
+	})
+
+	registry := routing.NewRegistry()
+	if err := registry.Register("messages", capture); err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := routing.NewRouter(&routing.Table{Routes: []routing.Route{
+		{Field: "messages", Handler: "messages"},
+	}}, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := `{
+		"object": "whatsapp_business_account",
+		"entry": [{
+			"id": "1",
+			"changes": [{"field": "messages", "value": {
+				"messaging_product": "whatsapp",
+				"metadata": {"phone_number_id": "phone"},
+				"contacts": [{"wa_id": "255700000001"}],
+				"messages": [{"id": "m1", "type": "text"}, {"id": "m2", "type": "image"}],
+				"statuses": [{"id": "s1", "status": "read"}],
+				"errors": [{"code": 131000}]
+			}}]
+		}]
+	}`
+
+	var notification routing.Notification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := router.Dispatch(context.TODO(), &notification); err != nil {
+		t.Fatal(err)
+	}
+
+	type got struct {
+		MessageType string
+		Message     string
+		Value       string
+	}
+
+	const envelope = `"messaging_product":"whatsapp","metadata":{"phone_number_id":"phone"}`
+	want := []got{
+		{
+			MessageType: "text",
+			Message:     `{"id": "m1", "type": "text"}`,
+			Value:       `{"contacts":[{"wa_id":"255700000001"}],"messages":[{"id":"m1","type":"text"}],` + envelope + `}`,
+		},
+		{
+			MessageType: "image",
+			Message:     `{"id": "m2", "type": "image"}`,
+			Value:       `{"contacts":[{"wa_id":"255700000001"}],"messages":[{"id":"m2","type":"image"}],` + envelope + `}`,
+		},
+		{
+			Value: `{"errors":[{"code":131000}],` + envelope + `,"statuses":[{"id":"s1","status":"read"}]}`,
+		},
+	}
+
+	gotEvents := make([]got, 0, len(events))
+	for _, event := range events {
+		gotEvents = append(gotEvents, got{
+			MessageType: event.MessageType,
+			Message:     string(event.Message),
+			Value:       string(event.Value),
+		})
+	}
+
+	if diff := gcmp.Diff(want, gotEvents); diff != "" {
+		t.Errorf("dispatched events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterHandlerError(t *testing.T) {
+	t.Parallel()
+	registry := routing.NewRegistry()
+	failing := routing.HandlerFunc(func(context.Context, *routing.Event) error {
+		return errors.New("boom")
+	})
+	if err := registry.Register("failing", failing); err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := routing.NewRouter(&routing.Table{Default: "failing"}, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := &routing.Notification{
+		Entry: []*routing.Entry{{Changes: []*routing.Change{{Field: "flows"}}}},
+	}
+
+	if err := router.Dispatch(context.TODO(), notification); !errors.Is(err, routing.ErrDispatch) {
+		t.Fatalf("expected ErrDispatch, got %v", err)
+	}
+
+	response := router.HandleNotification(context.TODO(), notification)
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package search
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var (
+	_ Indexer  = (*MemoryIndex)(nil)
+	_ Searcher = (*MemoryIndex)(nil)
+)
+
+// MemoryIndex is an in-memory inverted index. It keeps every document and is meant for
+// tests, development and small deployments.
+type MemoryIndex struct {
+	mu        sync.RWMutex
+	documents map[string]*Document
+	terms     map[string]map[string]struct{}
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		documents: make(map[string]*Document),
+		terms:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Index adds the documents, replacing the ones with the same message ID.
+func (index *MemoryIndex) Index(_ context.Context, documents ...*Document) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for _, doc := range documents {
+		if doc == nil || doc.MessageID == "" {
+			continue
+		}
+
+		if previous, ok := index.documents[doc.MessageID]; ok {
+			index.remove(previous)
+		}
+
+		stored := *doc
+		index.documents[doc.MessageID] = &stored
+		for _, term := range Tokenize(doc.Text) {
+			ids, ok := index.terms[term]
+			if !ok {
+				ids = make(map[string]struct{})
+				index.terms[term] = ids
+			}
+			ids[doc.MessageID] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func (index *MemoryIndex) remove(doc *Document) {
+	for _, term := range Tokenize(doc.Text) {
+		delete(index.terms[term], doc.MessageID)
+		if len(index.terms[term]) == 0 {
+			delete(index.terms, term)
+		}
+	}
+	delete(index.documents, doc.MessageID)
+}
+
+// Search returns the documents matching query, most recent first.
+func (index *MemoryIndex) Search(_ context.Context, query *Query) ([]*Document, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	var candidates map[string]struct{}
+	for _, term := range Tokenize(query.Text) {
+		ids := index.terms[term]
+		if candidates == nil {
+			candidates = make(map[string]struct{}, len(ids))
+			for id := range ids {
+				candidates[id] = struct{}{}
+			}
+
+			continue
+		}
+
+		for id := range candidates {
+			if _, ok := ids[id]; !ok {
+				delete(candidates, id)
+			}
+		}
+	}
+
+	results := make([]*Document, 0)
+	for id, doc := range index.documents {
+		if candidates != nil {
+			if _, ok := candidates[id]; !ok {
+				continue
+			}
+		}
+
+		if !query.matches(doc) {
+			continue
+		}
+
+		result := *doc
+		results = append(results, &result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Timestamp.Equal(results[j].Timestamp) {
+			return results[i].MessageID > results[j].MessageID
+		}
+
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+func (query *Query) matches(doc *Document) bool {
+	if query.WaID != "" && doc.WaID != query.WaID {
+		return false
+	}
+
+	if !query.Since.IsZero() && doc.Timestamp.Before(query.Since) {
+		return false
+	}
+
+	return query.Until.IsZero() || doc.Timestamp.Before(query.Until)
+}
@@ -0,0 +1,186 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package search feeds inbound messages into a full text index so that support tools can
+// search conversation transcripts.
+//
+// The core only defines the Indexer contract and how a Document is extracted from a webhook
+// message, so it does not depend on a search engine. Any engine can be plugged in by
+// implementing Indexer, for example on top of Elasticsearch or a database with full text
+// search. A Bleve implementation lives in the extras/search/bleve module, and MemoryIndex is
+// a small reference implementation for tests and development.
+package search
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+type (
+	// Document is the searchable content of a message. Text is the text the customer typed,
+	// the caption or file name of media and the visible titles of replies.
+	Document struct {
+		MessageID     string    `json:"message_id"`
+		WaID          string    `json:"wa_id"`
+		PhoneNumberID string    `json:"phone_number_id,omitempty"`
+		Type          string    `json:"type"`
+		Text          string    `json:"text,omitempty"`
+		Timestamp     time.Time `json:"timestamp"`
+	}
+
+	// Indexer adds documents to a search index. Index is called with every inbound message
+	// and must be idempotent since webhooks can be delivered more than once.
+	Indexer interface {
+		Index(ctx context.Context, documents ...*Document) error
+	}
+
+	IndexerFunc func(ctx context.Context, documents ...*Document) error
+
+	// Query searches documents containing all the terms of Text. WaID restricts the search
+	// to one customer and zero Since and Until are ignored.
+	Query struct {
+		Text  string
+		WaID  string
+		Since time.Time
+		Until time.Time
+		Limit int
+	}
+
+	// Searcher is implemented by indexes that can be queried.
+	Searcher interface {
+		Search(ctx context.Context, query *Query) ([]*Document, error)
+	}
+)
+
+func (fn IndexerFunc) Index(ctx context.Context, documents ...*Document) error {
+	return fn(ctx, documents...)
+}
+
+// NewDocument extracts the searchable content of an inbound message.
+func NewDocument(nctx *message.NotificationContext, msg *message.Message) *Document {
+	doc := &Document{
+		MessageID: msg.ID,
+		WaID:      msg.From,
+		Type:      msg.Type,
+		Text:      strings.Join(messageText(msg), "\n"),
+	}
+
+	if nctx != nil && nctx.Metadata != nil {
+		doc.PhoneNumberID = nctx.Metadata.PhoneNumberID
+	}
+
+	if seconds, err := strconv.ParseInt(msg.Timestamp, 10, 64); err == nil {
+		doc.Timestamp = time.Unix(seconds, 0).UTC()
+	}
+
+	return doc
+}
+
+func messageText(msg *message.Message) []string {
+	var parts []string
+	add := func(values ...string) {
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				parts = append(parts, value)
+			}
+		}
+	}
+
+	if msg.Text != nil {
+		add(msg.Text.Body)
+	}
+
+	for _, media := range []*wmessage.MediaInfo{msg.Image, msg.Video, msg.Document, msg.Audio} {
+		if media != nil {
+			add(media.Caption, media.Filename)
+		}
+	}
+
+	if msg.Button != nil {
+		add(msg.Button.Text)
+	}
+
+	if msg.Interactive != nil {
+		if msg.Interactive.ButtonReply != nil {
+			add(msg.Interactive.ButtonReply.Title)
+		}
+		if msg.Interactive.ListReply != nil {
+			add(msg.Interactive.ListReply.Title, msg.Interactive.ListReply.Description)
+		}
+	}
+
+	if msg.Location != nil {
+		add(msg.Location.Name, msg.Location.Address)
+	}
+
+	if msg.Order != nil {
+		add(msg.Order.Text)
+	}
+
+	if msg.Contacts != nil {
+		for _, contact := range *msg.Contacts {
+			if contact != nil && contact.Name != nil {
+				add(contact.Name.FormattedName)
+			}
+		}
+	}
+
+	if msg.Referral != nil {
+		add(msg.Referral.Headline, msg.Referral.Body)
+	}
+
+	return parts
+}
+
+// MessageReceivedHandler returns a message.ReceivedHandler that indexes every inbound
+// message, set it as message.Handlers.MessageReceived.
+func MessageReceivedHandler(indexer Indexer) message.ReceivedHandler {
+	return message.OnMessageReceivedHook(func(ctx context.Context, nctx *message.NotificationContext,
+		msg *message.Message,
+	) error {
+		if err := indexer.Index(ctx, NewDocument(nctx, msg)); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrIndex, msg.ID, err)
+		}
+
+		return nil
+	})
+}
+
+// Tokenize splits text into lower case terms, it is the analyzer used by MemoryIndex.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchError is a custom error type for search errors.
+type searchError string
+
+func (e searchError) Error() string {
+	return string(e)
+}
+
+const ErrIndex = searchError("index message")
@@ -0,0 +1,64 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+	"github.com/piusalfred/whatsapp/webhooks/search"
+)
+
+func TestMessageReceivedHandler(t *testing.T) {
+	t.Parallel()
+
+	index := search.NewMemoryIndex()
+	handler := search.MessageReceivedHandler(index)
+	nctx := &message.NotificationContext{Metadata: &message.Metadata{PhoneNumberID: "phone"}}
+
+	messages := []*message.Message{
+		{ID: "m1", From: "alice", Type: "text", Timestamp: "1700000000", Text: &message.Text{Body: "Where is my ORDER?"}},
+		{ID: "m2", From: "bob", Type: "image", Timestamp: "1700000100", Image: &wmessage.MediaInfo{Caption: "damaged order box"}},
+		{ID: "m3", From: "alice", Type: "interactive", Timestamp: "1700000200", Interactive: &message.Interactive{
+			Type: "button_reply", ButtonReply: &message.ButtonReply{ID: "b1", Title: "Track order"},
+		}},
+		{ID: "m1", From: "alice", Type: "text", Timestamp: "1700000000", Text: &message.Text{Body: "Where is my ORDER?"}},
+	}
+
+	for _, msg := range messages {
+		if err := handler.Handle(context.Background(), nctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query *search.Query
+		want  []string
+	}{
+		{name: "single term", query: &search.Query{Text: "order"}, want: []string{"m3", "m2", "m1"}},
+		{name: "all terms", query: &search.Query{Text: "order damaged"}, want: []string{"m2"}},
+		{name: "customer", query: &search.Query{Text: "order", WaID: "alice"}, want: []string{"m3", "m1"}},
+		{name: "since", query: &search.Query{Text: "order", Since: time.Unix(1700000050, 0)}, want: []string{"m3", "m2"}},
+		{name: "no match", query: &search.Query{Text: "refund"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		docs, err := index.Search(context.Background(), tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			got = append(got, doc.MessageID)
+		}
+
+		if diff := gcmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: results mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
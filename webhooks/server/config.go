@@ -0,0 +1,268 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables read by Config.ApplyEnv.
+const (
+	EnvAddr            = "WHATSAPP_WEBHOOK_ADDR"
+	EnvTLSCertFile     = "WHATSAPP_WEBHOOK_TLS_CERT_FILE"
+	EnvTLSKeyFile      = "WHATSAPP_WEBHOOK_TLS_KEY_FILE"
+	EnvShutdownTimeout = "WHATSAPP_WEBHOOK_SHUTDOWN_TIMEOUT"
+)
+
+const (
+	DefaultAddr            = ":8080"
+	DefaultHealthPath      = "/healthz"
+	DefaultReadyPath       = "/readyz"
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+type (
+	// Config configures a Server. It is decoded from JSON by LoadConfig, the yaml keys
+	// are the same and used by the YAML loader of the extras/webhookd module. The
+	// environment overrides the file, see ApplyEnv.
+	//
+	//	{
+	//		"addr": ":8443",
+	//		"tls": {"cert_file": "/etc/webhookd/cert.pem", "key_file": "/etc/webhookd/key.pem"},
+	//		"shutdown_timeout": "15s",
+	//		"endpoints": [{
+	//			"path": "/webhooks/messages",
+	//			"handler": "messages",
+	//			"verify_token_env": "MESSAGES_VERIFY_TOKEN",
+	//			"app_secret_env": "APP_SECRET"
+	//		}]
+	//	}
+	Config struct {
+		Addr            string            `json:"addr,omitempty"             yaml:"addr,omitempty"`
+		TLS             *TLSConfig        `json:"tls,omitempty"              yaml:"tls,omitempty"`
+		ShutdownTimeout Duration          `json:"shutdown_timeout,omitempty" yaml:"shutdown_timeout,omitempty"`
+		HealthPath      string            `json:"health_path,omitempty"      yaml:"health_path,omitempty"`
+		ReadyPath       string            `json:"ready_path,omitempty"       yaml:"ready_path,omitempty"`
+		Endpoints       []*EndpointConfig `json:"endpoints"                  yaml:"endpoints"`
+	}
+
+	// TLSConfig makes the Server serve HTTPS with the certificate and key in the files.
+	TLSConfig struct {
+		CertFile string `json:"cert_file" yaml:"cert_file"`
+		KeyFile  string `json:"key_file"  yaml:"key_file"`
+	}
+
+	// EndpointConfig mounts the listener built by the factory registered as Handler on
+	// Path. Secrets are better passed in the environment variables named by the *Env
+	// fields, which ApplyEnv reads into VerifyToken and AppSecret. Payload signatures are
+	// always validated, an endpoint without an app secret is only accepted when
+	// InsecureSkipSignature turns the validation off.
+	EndpointConfig struct {
+		Path                  string `json:"path"                              yaml:"path"`
+		Handler               string `json:"handler"                           yaml:"handler"`
+		VerifyToken           string `json:"verify_token,omitempty"            yaml:"verify_token,omitempty"`
+		VerifyTokenEnv        string `json:"verify_token_env,omitempty"        yaml:"verify_token_env,omitempty"`
+		AppSecret             string `json:"app_secret,omitempty"              yaml:"app_secret,omitempty"`
+		AppSecretEnv          string `json:"app_secret_env,omitempty"          yaml:"app_secret_env,omitempty"`
+		InsecureSkipSignature bool   `json:"insecure_skip_signature,omitempty" yaml:"insecure_skip_signature,omitempty"`
+	}
+
+	// Duration is a time.Duration written like "10s" in the configuration.
+	Duration time.Duration
+
+	// LookupEnvFunc looks up environment variables, like os.LookupEnv.
+	LookupEnvFunc func(key string) (string, bool)
+)
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// LoadConfig decodes a JSON configuration from r and validates it.
+func LoadConfig(r io.Reader) (*Config, error) {
+	var config Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%w: decode: %w", ErrInvalidConfig, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadConfigFile loads the JSON configuration in the file at path and applies the
+// environment to it.
+func LoadConfigFile(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	defer file.Close()
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ApplyEnv overrides the address, TLS files and shutdown timeout with the environment
+// variables that are set, and reads the secrets of the endpoints from the variables they
+// name. A variable named by an endpoint that is not set, or set but empty, is an error.
+func (c *Config) ApplyEnv(lookup LookupEnvFunc) error {
+	if value, ok := lookup(EnvAddr); ok {
+		c.Addr = value
+	}
+
+	cert, okCert := lookup(EnvTLSCertFile)
+	key, okKey := lookup(EnvTLSKeyFile)
+	if okCert || okKey {
+		if c.TLS == nil {
+			c.TLS = &TLSConfig{}
+		}
+		if okCert {
+			c.TLS.CertFile = cert
+		}
+		if okKey {
+			c.TLS.KeyFile = key
+		}
+	}
+
+	if value, ok := lookup(EnvShutdownTimeout); ok {
+		if err := c.ShutdownTimeout.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%s: %w", EnvShutdownTimeout, err)
+		}
+	}
+
+	for _, endpoint := range c.Endpoints {
+		for _, secret := range []struct {
+			env   string
+			value *string
+		}{
+			{env: endpoint.VerifyTokenEnv, value: &endpoint.VerifyToken},
+			{env: endpoint.AppSecretEnv, value: &endpoint.AppSecret},
+		} {
+			if secret.env == "" {
+				continue
+			}
+
+			value, ok := lookup(secret.env)
+			switch {
+			case !ok:
+				return fmt.Errorf("%w: endpoint %s: %s is not set", ErrInvalidConfig, endpoint.Path, secret.env)
+			case value == "":
+				return fmt.Errorf("%w: endpoint %s: %s is empty", ErrInvalidConfig, endpoint.Path, secret.env)
+			}
+			*secret.value = value
+		}
+	}
+
+	return c.Validate()
+}
+
+// Validate checks that every endpoint has a path, a handler and an app secret, or
+// InsecureSkipSignature set, that no two endpoints share a path or use the path of a
+// probe and that the TLS configuration is complete.
+func (c *Config) Validate() error {
+	var errs []string
+	if c.TLS != nil && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, "tls needs both cert_file and key_file")
+	}
+
+	healthPath, readyPath := orDefault(c.HealthPath, DefaultHealthPath), orDefault(c.ReadyPath, DefaultReadyPath)
+	if healthPath == readyPath {
+		errs = append(errs, fmt.Sprintf("health_path and ready_path share the path %q", healthPath))
+	}
+
+	seen := make(map[string]bool, len(c.Endpoints))
+	for i, endpoint := range c.Endpoints {
+		switch {
+		case endpoint == nil:
+			errs = append(errs, fmt.Sprintf("endpoint %d: empty", i))
+		case !strings.HasPrefix(endpoint.Path, "/"):
+			errs = append(errs, fmt.Sprintf("endpoint %d: path %q must start with /", i, endpoint.Path))
+		case endpoint.Handler == "":
+			errs = append(errs, fmt.Sprintf("endpoint %d: missing handler", i))
+		case endpoint.AppSecret == "" && endpoint.AppSecretEnv == "" && !endpoint.InsecureSkipSignature:
+			errs = append(errs, fmt.Sprintf("endpoint %d: missing app_secret, set insecure_skip_signature "+
+				"to accept unsigned notifications", i))
+		case endpoint.Path == healthPath || endpoint.Path == readyPath:
+			errs = append(errs, fmt.Sprintf("endpoint %d: path %q is used by a probe", i, endpoint.Path))
+		case seen[endpoint.Path]:
+			errs = append(errs, fmt.Sprintf("endpoint %d: duplicate path %q", i, endpoint.Path))
+		default:
+			seen[endpoint.Path] = true
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (c *Config) addr() string {
+	if c.Addr == "" {
+		return DefaultAddr
+	}
+
+	return c.Addr
+}
+
+func (c *Config) shutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+
+	return time.Duration(c.ShutdownTimeout)
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,350 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package server runs a standalone webhook server from a configuration.
+//
+// The server mounts a webhook listener on every configured endpoint, serves health and
+// readiness probes, optionally over TLS, and shuts down gracefully when its context is
+// canceled. Endpoints refer by name to listener factories registered beforehand, the way
+// database/sql drivers are registered:
+//
+//	func main() {
+//		handlers := &message.Handlers{}
+//		handlers.SetTextMessageHandler(onText)
+//		server.Register("messages", server.ListenerFactory[message.Notification](handlers))
+//
+//		config, err := server.LoadConfigFile("webhookd.json")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//		defer stop()
+//
+//		if err := server.Run(ctx, config); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The health endpoint answers 200 as long as the process serves requests. The readiness
+// endpoint answers 503 once shutdown started, or while a readiness check fails, so that
+// load balancers stop sending notifications before the server stops.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/router"
+)
+
+type (
+	// Factory builds the listener of an endpoint from its configuration.
+	Factory func(endpoint *EndpointConfig) (router.Listener, error)
+
+	// Registry holds the factories the endpoints of a configuration refer to.
+	Registry struct {
+		mu        sync.RWMutex
+		factories map[string]Factory
+	}
+
+	// ReadinessCheck reports whether a dependency of the handlers is available.
+	ReadinessCheck func(ctx context.Context) error
+
+	Option func(*Server)
+
+	// Server serves the webhook endpoints of a Config.
+	Server struct {
+		config   *Config
+		handler  http.Handler
+		checks   map[string]ReadinessCheck
+		logger   *slog.Logger
+		draining atomic.Bool
+	}
+)
+
+// DefaultRegistry is the Registry used by Register and Run.
+var DefaultRegistry = NewRegistry() //nolint:gochecknoglobals // registry of the package level functions
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a factory under name. Registering the same name twice is an error.
+func (r *Registry) Register(name string, factory Factory) error {
+	if name == "" || factory == nil {
+		return fmt.Errorf("%w: name and factory are required", ErrInvalidRegistration)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("%w: %q is already registered", ErrInvalidRegistration, name)
+	}
+	r.factories[name] = factory
+
+	return nil
+}
+
+// Lookup returns the factory registered under name.
+func (r *Registry) Lookup(name string) (Factory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.factories[name]
+
+	return factory, ok
+}
+
+// Names returns the sorted names of all registered factories.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Register adds a factory to the DefaultRegistry. It panics when the name is taken, it is
+// meant to be called from init functions or at the start of main.
+func Register(name string, factory Factory) {
+	if err := DefaultRegistry.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// ListenerFactory returns a Factory building a webhooks.Listener passing the notifications
+// to handler. The listener answers the subscription verification with the VerifyToken of
+// the endpoint and validates the payload signatures with its AppSecret, unless the
+// endpoint sets InsecureSkipSignature.
+func ListenerFactory[T any](handler webhooks.NotificationHandler[T],
+	middlewares ...webhooks.HandleMiddleware[T],
+) Factory {
+	return func(endpoint *EndpointConfig) (router.Listener, error) {
+		verifyToken := endpoint.VerifyToken
+		reader := webhooks.VerifyTokenReader(func(context.Context) (string, error) {
+			return verifyToken, nil
+		})
+
+		validate := &webhooks.ValidateOptions{
+			Validate:  !endpoint.InsecureSkipSignature,
+			AppSecret: endpoint.AppSecret,
+		}
+
+		return webhooks.NewListener(handler.HandleNotification, reader, validate, middlewares...), nil
+	}
+}
+
+// WithReadinessCheck adds a check to the readiness endpoint, which answers 503 with the
+// names of the failing checks while any fails.
+func WithReadinessCheck(name string, check ReadinessCheck) Option {
+	return func(s *Server) {
+		s.checks[name] = check
+	}
+}
+
+// WithLogger sets the logger of the server lifecycle, slog.Default by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// New validates config and builds the listeners of its endpoints with the factories in
+// registry, DefaultRegistry when nil.
+func New(config *Config, registry *Registry, options ...Option) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	s := &Server{config: config, checks: make(map[string]ReadinessCheck), logger: slog.Default()}
+	for _, option := range options {
+		if option != nil {
+			option(s)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+orDefault(config.HealthPath, DefaultHealthPath), s.handleHealth)
+	mux.HandleFunc("GET "+orDefault(config.ReadyPath, DefaultReadyPath), s.handleReady)
+
+	var errs []error
+	for _, endpoint := range config.Endpoints {
+		if endpoint.InsecureSkipSignature {
+			s.logger.Warn("webhook signatures are not validated, forged notifications are accepted",
+				slog.String("path", endpoint.Path))
+		} else if endpoint.AppSecret == "" {
+			errs = append(errs, fmt.Errorf("endpoint %s: %s is not applied, see Config.ApplyEnv",
+				endpoint.Path, endpoint.AppSecretEnv))
+
+			continue
+		}
+
+		factory, ok := registry.Lookup(endpoint.Handler)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %q, registered: %v", ErrHandlerNotFound, endpoint.Handler,
+				registry.Names()))
+
+			continue
+		}
+
+		listener, err := factory(endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %s: %w", endpoint.Path, err))
+
+			continue
+		}
+
+		router.Mount(mux, endpoint.Path, listener)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	s.handler = mux
+
+	return s, nil
+}
+
+// Handler returns the handler serving the endpoints and the probes, to embed the server
+// in another one.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Run serves until ctx is canceled, then marks the server as not ready and shuts it down,
+// waiting up to the shutdown timeout for the notifications being handled.
+func Run(ctx context.Context, config *Config, options ...Option) error {
+	s, err := New(config, DefaultRegistry, options...)
+	if err != nil {
+		return err
+	}
+
+	return s.Run(ctx)
+}
+
+// Run listens on the configured address and serves until ctx is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.addr())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrServe, err)
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is canceled.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second, //nolint:mnd // guards against slow clients
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithoutCancel(ctx)
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.InfoContext(ctx, "webhook server started", slog.String("addr", listener.Addr().String()))
+		if tls := s.config.TLS; tls != nil {
+			serveErr <- server.ServeTLS(listener, tls.CertFile, tls.KeyFile)
+		} else {
+			serveErr <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("%w: %w", ErrServe, err)
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	s.logger.InfoContext(ctx, "webhook server shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.shutdownTimeout())
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("%w: shutdown: %w", ErrServe, err)
+	}
+
+	return nil
+}
+
+func (s *Server) handleHealth(writer http.ResponseWriter, _ *http.Request) {
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write([]byte("ok"))
+}
+
+func (s *Server) handleReady(writer http.ResponseWriter, request *http.Request) {
+	if s.draining.Load() {
+		http.Error(writer, "shutting down", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	var failed []string
+	for name, check := range s.checks {
+		if err := check(request.Context()); err != nil {
+			failed = append(failed, name+": "+err.Error())
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		http.Error(writer, fmt.Sprint(failed), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write([]byte("ok"))
+}
+
+// serverError is a custom error type for webhook server errors.
+type serverError string
+
+func (e serverError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrInvalidConfig       = serverError("invalid webhook server config")
+	ErrInvalidRegistration = serverError("invalid handler registration")
+	ErrHandlerNotFound     = serverError("handler not registered")
+	ErrServe               = serverError("serve webhooks")
+)
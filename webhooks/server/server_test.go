@@ -0,0 +1,314 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/server"
+)
+
+const appSecret = "app-secret"
+
+type notification struct {
+	Object string `json:"object"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := server.LoadConfig(strings.NewReader(`{
+		"shutdown_timeout": "3s",
+		"endpoints": [{"path": "/webhooks", "handler": "test", "verify_token_env": "VERIFY_TOKEN",
+			"app_secret": "app-secret"}]
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	env := map[string]string{server.EnvAddr: ":9000"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+
+		return value, ok
+	}
+
+	if err := config.ApplyEnv(lookup); !errors.Is(err, server.ErrInvalidConfig) {
+		t.Fatalf("ApplyEnv() error = %v, want ErrInvalidConfig for the missing VERIFY_TOKEN", err)
+	}
+
+	env["VERIFY_TOKEN"] = "secret"
+	if err := config.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	if config.Addr != ":9000" || config.Endpoints[0].VerifyToken != "secret" ||
+		time.Duration(config.ShutdownTimeout) != 3*time.Second {
+		t.Errorf("config = %+v, endpoint = %+v", config, config.Endpoints[0])
+	}
+
+	_, err = server.LoadConfig(strings.NewReader(`{"endpoints": [{"path": "/a", "handler": "x", "app_secret": "s"},
+		{"path": "/a", "handler": "y", "app_secret": "s"}]}`))
+	if !errors.Is(err, server.ErrInvalidConfig) {
+		t.Errorf("LoadConfig() error = %v, want ErrInvalidConfig for duplicate paths", err)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "webhookd.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"endpoints": [{"path": "/webhooks", "handler": "test",
+		"app_secret": "app-secret"}]}`),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := server.LoadConfigFile(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if config.Endpoints[0].Path != "/webhooks" {
+		t.Errorf("config = %+v", config)
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan string, 1)
+	registry := server.NewRegistry()
+	err := registry.Register("test", server.ListenerFactory[notification](
+		webhooks.NotificationHandlerFunc[notification](func(_ context.Context,
+			n *notification,
+		) *webhooks.Response {
+			received <- n.Object
+
+			return &webhooks.Response{StatusCode: http.StatusOK}
+		})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &server.Config{Endpoints: []*server.EndpointConfig{
+		{Path: "/webhooks", Handler: "test", VerifyToken: "token", AppSecret: appSecret},
+	}}
+
+	srv, err := server.New(config, registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	base := "http://" + listener.Addr().String()
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(base + path) //nolint:noctx
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(body)
+	}
+
+	if status, body := get("/webhooks?hub.mode=subscribe&hub.verify_token=token&hub.challenge=42"); status != 200 ||
+		body != "42" {
+		t.Errorf("verification = %d %q", status, body)
+	}
+
+	payload := `{"object":"whatsapp_business_account"}`
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/webhooks", strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set(webhooks.SignatureHeaderKey, webhooks.SignPayload([]byte(payload), appSecret))
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := <-received; got != "whatsapp_business_account" || resp.StatusCode != http.StatusOK {
+		t.Errorf("notification = %q, status = %d", got, resp.StatusCode)
+	}
+
+	if status, _ := get(server.DefaultReadyPath); status != http.StatusOK {
+		t.Errorf("readiness = %d", status)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
+
+func TestServer_Readiness(t *testing.T) {
+	t.Parallel()
+
+	srv, err := server.New(&server.Config{}, server.NewRegistry(),
+		server.WithReadinessCheck("store", func(context.Context) error { return errors.New("unreachable") }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]int{
+		server.DefaultHealthPath: http.StatusOK,
+		server.DefaultReadyPath:  http.StatusServiceUnavailable,
+	} {
+		recorder := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, recorder.Code, want)
+		}
+	}
+
+	_, err = server.New(&server.Config{Endpoints: []*server.EndpointConfig{{Path: "/x", Handler: "missing", InsecureSkipSignature: true}}},
+		server.NewRegistry())
+	if !errors.Is(err, server.ErrHandlerNotFound) {
+		t.Errorf("New() error = %v, want ErrHandlerNotFound", err)
+	}
+}
+
+func TestConfig_AppSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint *server.EndpointConfig
+		env      map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "app secret",
+			endpoint: &server.EndpointConfig{Path: "/webhooks", Handler: "test", AppSecret: "secret"},
+		},
+		{
+			name:     "app secret from the environment",
+			endpoint: &server.EndpointConfig{Path: "/webhooks", Handler: "test", AppSecretEnv: "APP_SECRET"},
+			env:      map[string]string{"APP_SECRET": "secret"},
+		},
+		{
+			name:     "missing app secret",
+			endpoint: &server.EndpointConfig{Path: "/webhooks", Handler: "test"},
+			wantErr:  true,
+		},
+		{
+			name:     "empty app secret in the environment",
+			endpoint: &server.EndpointConfig{Path: "/webhooks", Handler: "test", AppSecretEnv: "APP_SECRET"},
+			env:      map[string]string{"APP_SECRET": ""},
+			wantErr:  true,
+		},
+		{
+			name:     "insecure skip signature",
+			endpoint: &server.EndpointConfig{Path: "/webhooks", Handler: "test", InsecureSkipSignature: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &server.Config{Endpoints: []*server.EndpointConfig{tt.endpoint}}
+			err := config.ApplyEnv(func(key string) (string, bool) {
+				value, ok := tt.env[key]
+
+				return value, ok
+			})
+			if gotErr := errors.Is(err, server.ErrInvalidConfig); gotErr != tt.wantErr {
+				t.Errorf("ApplyEnv() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_UnappliedAppSecret(t *testing.T) {
+	t.Parallel()
+
+	registry := server.NewRegistry()
+	if err := registry.Register("test", server.ListenerFactory[notification](
+		webhooks.NotificationHandlerFunc[notification](func(context.Context, *notification) *webhooks.Response {
+			return &webhooks.Response{StatusCode: http.StatusOK}
+		}))); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &server.Config{Endpoints: []*server.EndpointConfig{
+		{Path: "/webhooks", Handler: "test", AppSecretEnv: "APP_SECRET"},
+	}}
+	if _, err := server.New(config, registry); !errors.Is(err, server.ErrInvalidConfig) {
+		t.Errorf("New() error = %v, want ErrInvalidConfig before the environment is applied", err)
+	}
+}
+
+func TestConfig_ProbePaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  *server.Config
+		wantErr bool
+	}{
+		{
+			name: "distinct paths",
+			config: &server.Config{Endpoints: []*server.EndpointConfig{
+				{Path: "/webhooks", Handler: "test", AppSecret: appSecret},
+			}},
+		},
+		{
+			name: "endpoint on the default health path",
+			config: &server.Config{Endpoints: []*server.EndpointConfig{
+				{Path: server.DefaultHealthPath, Handler: "test", AppSecret: appSecret},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "endpoint on the configured ready path",
+			config: &server.Config{ReadyPath: "/ready", Endpoints: []*server.EndpointConfig{
+				{Path: "/ready", Handler: "test", AppSecret: appSecret},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "probes sharing a path",
+			config:  &server.Config{HealthPath: "/probe", ReadyPath: "/probe"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.config.Validate()
+			if gotErr := errors.Is(err, server.ErrInvalidConfig); gotErr != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if _, err := server.New(tt.config, server.NewRegistry()); tt.wantErr && err == nil {
+				t.Error("New() error = nil, want the invalid configuration rejected")
+			}
+		})
+	}
+}
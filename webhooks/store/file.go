@@ -0,0 +1,163 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const fileExtension = ".json"
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore is a Store that keeps every record as a JSON file named after its ID in a
+// directory. Files are replaced atomically, so a crash never leaves a partial record.
+// List reads the whole directory and suits the volumes of a single deployment.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates dir if needed and returns a FileStore keeping records in it.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil { //nolint:mnd // directory permissions
+		return nil, fmt.Errorf("create store directory: %w", err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Save(_ context.Context, record *Record) error {
+	if record == nil || record.ID == "" || strings.ContainsAny(record.ID, `/\.`) {
+		return ErrInvalidRecord
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create record file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write record file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write record file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(record.ID)); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write record file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Get(_ context.Context, id string) (*Record, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, ErrNotFound
+	}
+
+	return s.read(s.path(id))
+}
+
+func (s *FileStore) List(_ context.Context, query *Query) ([]*Record, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read store directory: %w", err)
+	}
+
+	records := make([]*Record, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != fileExtension {
+			continue
+		}
+
+		record, err := s.read(filepath.Join(s.dir, name))
+		if errors.Is(err, ErrNotFound) {
+			continue // deleted since the directory was read
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return filterRecords(records, query), nil
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil
+	}
+
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete record file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+fileExtension)
+}
+
+func (s *FileStore) read(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read record file: %w", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("decode record file %s: %w", filepath.Base(path), err)
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,388 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder returns the bind parameter for the nth argument of a query, starting at 1.
+type Placeholder func(n int) string
+
+// Upsert returns the statement inserting a record into table or updating the record with
+// the same ID, with the arguments of recordColumns.
+type Upsert func(table string, placeholder Placeholder) string
+
+// PlaceholderQuestion is used by MySQL and SQLite, PlaceholderDollar by PostgreSQL.
+func PlaceholderQuestion(int) string { return "?" }
+
+func PlaceholderDollar(n int) string { return "$" + strconv.Itoa(n) }
+
+// UpsertOnConflict is the Upsert of PostgreSQL and SQLite.
+func UpsertOnConflict(table string, placeholder Placeholder) string {
+	return insertStatement(table, placeholder) + " ON CONFLICT (id) DO UPDATE SET " +
+		updatedColumns(func(column string) string { return "excluded." + column })
+}
+
+// UpsertOnDuplicateKey is the Upsert of MySQL.
+func UpsertOnDuplicateKey(table string, placeholder Placeholder) string {
+	return insertStatement(table, placeholder) + " ON DUPLICATE KEY UPDATE " +
+		updatedColumns(func(column string) string { return "VALUES(" + column + ")" })
+}
+
+var _ Store = (*SQLStore)(nil)
+
+type (
+	// SQLStore is a Store backed by a database/sql table. It only uses portable SQL unless
+	// an Upsert is set, the driver is up to the application. Times are stored as UNIX
+	// nanoseconds.
+	SQLStore struct {
+		db          *sql.DB
+		table       string
+		placeholder Placeholder
+		upsert      Upsert
+	}
+
+	SQLStoreOption func(*SQLStore)
+
+	// sqlConn is implemented by *sql.DB and *sql.Tx.
+	sqlConn interface {
+		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+)
+
+func WithSQLTable(table string) SQLStoreOption {
+	return func(s *SQLStore) {
+		s.table = table
+	}
+}
+
+func WithSQLPlaceholder(placeholder Placeholder) SQLStoreOption {
+	return func(s *SQLStore) {
+		s.placeholder = placeholder
+	}
+}
+
+// WithSQLUpsert makes Save use a single upsert statement, like UpsertOnConflict or
+// UpsertOnDuplicateKey, instead of a transaction.
+func WithSQLUpsert(upsert Upsert) SQLStoreOption {
+	return func(s *SQLStore) {
+		s.upsert = upsert
+	}
+}
+
+// NewSQLStore returns a SQLStore using the table "webhook_notifications" and "?"
+// placeholders unless configured otherwise. The table name must be a plain identifier.
+func NewSQLStore(db *sql.DB, options ...SQLStoreOption) (*SQLStore, error) {
+	store := &SQLStore{db: db, table: "webhook_notifications", placeholder: PlaceholderQuestion}
+	for _, option := range options {
+		if option != nil {
+			option(store)
+		}
+	}
+
+	if !validIdentifier(store.table) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTable, store.table)
+	}
+
+	return store, nil
+}
+
+// CreateTable creates the table and its index when they do not exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.table + ` (
+	id VARCHAR(64) PRIMARY KEY,
+	received_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	signature TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempts INTEGER NOT NULL,
+	last_error TEXT NOT NULL
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + s.table + `_status_received_at ON ` + s.table + ` (status, received_at)`,
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create table %s: %w", s.table, err)
+		}
+	}
+
+	return nil
+}
+
+// Save inserts the record, or updates the stored record with the same ID. With an Upsert
+// this is a single statement, otherwise the record is looked up and then updated or
+// inserted in a transaction. When a concurrent Save inserts the same ID first, the insert
+// fails and the record is updated instead.
+func (s *SQLStore) Save(ctx context.Context, record *Record) error {
+	if record == nil || record.ID == "" {
+		return ErrInvalidRecord
+	}
+
+	if s.upsert != nil {
+		if _, err := s.db.ExecContext(ctx, s.upsert(s.table, s.placeholder), recordArgs(record)...); err != nil {
+			return fmt.Errorf("upsert record %s: %w", record.ID, err)
+		}
+
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("save record %s: %w", record.ID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	exists, err := s.exists(ctx, tx, record.ID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		err = s.update(ctx, tx, record)
+	} else if err = s.insert(ctx, tx, record); err != nil {
+		_ = tx.Rollback()
+
+		return s.updateInserted(ctx, record, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("save record %s: %w", record.ID, err)
+	}
+
+	return nil
+}
+
+// updateInserted updates the record when the failed insert lost the race against another
+// Save of the same ID, and returns insertErr otherwise.
+func (s *SQLStore) updateInserted(ctx context.Context, record *Record, insertErr error) error {
+	if exists, err := s.exists(ctx, s.db, record.ID); err != nil || !exists {
+		return insertErr
+	}
+
+	return s.update(ctx, s.db, record)
+}
+
+func (s *SQLStore) exists(ctx context.Context, conn sqlConn, id string) (bool, error) {
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE id = %s`, s.table, s.placeholder(1))
+
+	var found int
+	err := conn.QueryRowContext(ctx, query, id).Scan(&found)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("look up record %s: %w", id, err)
+	default:
+		return true, nil
+	}
+}
+
+func (s *SQLStore) update(ctx context.Context, conn sqlConn, record *Record) error {
+	update := fmt.Sprintf(`UPDATE %s SET received_at = %s, updated_at = %s, signature = %s, payload = %s,
+	status = %s, attempts = %s, last_error = %s WHERE id = %s`, s.table,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), //nolint:mnd // parameter positions
+		s.placeholder(5), s.placeholder(6), s.placeholder(7), s.placeholder(8)) //nolint:mnd // parameter positions
+
+	args := recordArgs(record)
+	if _, err := conn.ExecContext(ctx, update, append(args[1:], args[0])...); err != nil {
+		return fmt.Errorf("update record %s: %w", record.ID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) insert(ctx context.Context, conn sqlConn, record *Record) error {
+	if _, err := conn.ExecContext(ctx, insertStatement(s.table, s.placeholder), recordArgs(record)...); err != nil {
+		return fmt.Errorf("insert record %s: %w", record.ID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) Get(ctx context.Context, id string) (*Record, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = %s`, recordColumns, s.table, s.placeholder(1))
+
+	record, err := scanRecord(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get record %s: %w", id, err)
+	}
+
+	return record, nil
+}
+
+func (s *SQLStore) List(ctx context.Context, query *Query) ([]*Record, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if query.Status != "" {
+		args = append(args, string(query.Status))
+		conditions = append(conditions, "status = "+s.placeholder(len(args)))
+	}
+
+	if !query.Since.IsZero() {
+		args = append(args, query.Since.UnixNano())
+		conditions = append(conditions, "received_at >= "+s.placeholder(len(args)))
+	}
+
+	if !query.Until.IsZero() {
+		args = append(args, query.Until.UnixNano())
+		conditions = append(conditions, "received_at < "+s.placeholder(len(args)))
+	}
+
+	var statement strings.Builder
+	fmt.Fprintf(&statement, "SELECT %s FROM %s", recordColumns, s.table)
+
+	if len(conditions) > 0 {
+		statement.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+
+	statement.WriteString(" ORDER BY received_at, id")
+
+	if query.Limit > 0 {
+		statement.WriteString(" LIMIT " + strconv.Itoa(query.Limit))
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list records: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+
+	return records, nil
+}
+
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = %s`, s.table, s.placeholder(1))
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("delete record %s: %w", id, err)
+	}
+
+	return nil
+}
+
+const recordColumns = "id, received_at, updated_at, signature, payload, status, attempts, last_error"
+
+// recordArgs returns the arguments of recordColumns.
+func recordArgs(record *Record) []any {
+	return []any{
+		record.ID, record.ReceivedAt.UnixNano(), record.UpdatedAt.UnixNano(), record.Signature,
+		string(record.Payload), string(record.Status), record.Attempts, record.LastError,
+	}
+}
+
+func insertStatement(table string, placeholder Placeholder) string {
+	columns := strings.Split(recordColumns, ", ")
+	values := make([]string, len(columns))
+	for i := range columns {
+		values[i] = placeholder(i + 1)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, recordColumns, strings.Join(values, ", "))
+}
+
+// updatedColumns returns the assignments of the columns but id to the values returned by
+// value.
+func updatedColumns(value func(column string) string) string {
+	columns := strings.Split(recordColumns, ", ")[1:]
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = column + " = " + value(column)
+	}
+
+	return strings.Join(assignments, ", ")
+}
+
+func scanRecord(row interface{ Scan(dest ...any) error }) (*Record, error) {
+	var (
+		record     Record
+		receivedAt int64
+		updatedAt  int64
+		payload    string
+		status     string
+	)
+
+	if err := row.Scan(&record.ID, &receivedAt, &updatedAt, &record.Signature, &payload, &status,
+		&record.Attempts, &record.LastError); err != nil {
+		return nil, err
+	}
+
+	record.ReceivedAt = time.Unix(0, receivedAt).UTC()
+	record.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	record.Payload = []byte(payload)
+	record.Status = Status(status)
+
+	return &record, nil
+}
+
+func validIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,348 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks/store"
+)
+
+func TestSQLStore_Save(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []store.SQLStoreOption
+	}{
+		{name: "transaction"},
+		{name: "upsert", options: []store.SQLStoreOption{store.WithSQLUpsert(store.UpsertOnDuplicateKey)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &fakeDB{rows: make(map[string][]driver.Value)}
+			backend := newSQLStore(t, db, tt.options...)
+
+			ctx := context.Background()
+			record := &store.Record{
+				ID:         "id",
+				ReceivedAt: time.Unix(1700000000, 0).UTC(),
+				UpdatedAt:  time.Unix(1700000000, 0).UTC(),
+				Payload:    []byte(`{"object":"whatsapp_business_account"}`),
+				Status:     store.StatusFailed,
+				Attempts:   1,
+				LastError:  "status 500",
+			}
+
+			// Saving an unchanged record updates no row, like MySQL reports it.
+			for range 2 {
+				if err := backend.Save(ctx, record); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			record.Status, record.Attempts, record.LastError = store.StatusHandled, 2, ""
+			if err := backend.Save(ctx, record); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			got, err := backend.Get(ctx, record.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := gcmp.Diff(record, got); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSQLStore_SaveConcurrentInsert(t *testing.T) {
+	t.Parallel()
+
+	db := &fakeDB{rows: make(map[string][]driver.Value)}
+	backend := newSQLStore(t, db)
+
+	ctx := context.Background()
+	first := &store.Record{ID: "id", Status: store.StatusPending, Attempts: 1, Payload: []byte(`{}`)}
+	second := &store.Record{ID: "id", Status: store.StatusHandled, Attempts: 1, Payload: []byte(`{}`)}
+
+	// The first Save inserts the record after the second one looked it up.
+	db.beforeInsert = func() {
+		if err := backend.Save(ctx, first); err != nil {
+			t.Errorf("Save(first) error = %v", err)
+		}
+	}
+
+	if err := backend.Save(ctx, second); err != nil {
+		t.Fatalf("Save(second) error = %v", err)
+	}
+
+	got, err := backend.Get(ctx, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Status != store.StatusHandled {
+		t.Errorf("status = %s, want %s", got.Status, store.StatusHandled)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		upsert store.Upsert
+		want   string
+	}{
+		{
+			name:   "on conflict",
+			upsert: store.UpsertOnConflict,
+			want: "INSERT INTO notifications (id, received_at, updated_at, signature, payload, status, attempts, " +
+				"last_error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET " +
+				"received_at = excluded.received_at, updated_at = excluded.updated_at, " +
+				"signature = excluded.signature, payload = excluded.payload, status = excluded.status, " +
+				"attempts = excluded.attempts, last_error = excluded.last_error",
+		},
+		{
+			name:   "on duplicate key",
+			upsert: store.UpsertOnDuplicateKey,
+			want: "INSERT INTO notifications (id, received_at, updated_at, signature, payload, status, attempts, " +
+				"last_error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON DUPLICATE KEY UPDATE " +
+				"received_at = VALUES(received_at), updated_at = VALUES(updated_at), " +
+				"signature = VALUES(signature), payload = VALUES(payload), status = VALUES(status), " +
+				"attempts = VALUES(attempts), last_error = VALUES(last_error)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.upsert("notifications", store.PlaceholderDollar); got != tt.want {
+				t.Errorf("upsert =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func newSQLStore(t *testing.T, db *fakeDB, options ...store.SQLStoreOption) *store.SQLStore {
+	t.Helper()
+
+	conn := sql.OpenDB(db)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	backend, err := store.NewSQLStore(conn, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.CreateTable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return backend
+}
+
+// fakeDB is an in-memory database/sql driver understanding the statements of SQLStore.
+// Like MySQL, an UPDATE leaving a row unchanged reports no affected row.
+type fakeDB struct {
+	mu   sync.Mutex
+	rows map[string][]driver.Value
+
+	// beforeInsert runs once before the next plain INSERT.
+	beforeInsert func()
+}
+
+var errDuplicateKey = errors.New("duplicate entry for key PRIMARY")
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+
+func (db *fakeDB) Driver() driver.Driver { return fakeDriver{} }
+
+func (db *fakeDB) exec(query string, args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(query, "INSERT") && !strings.Contains(query, " ON ") && db.beforeInsert != nil {
+		before := db.beforeInsert
+		db.beforeInsert = nil
+		before()
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "CREATE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(query, "DELETE"):
+		delete(db.rows, args[0].(string))
+
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "UPDATE"):
+		id := args[len(args)-1].(string)
+
+		return db.set(append([]driver.Value{id}, args[:len(args)-1]...)), nil
+	case strings.HasPrefix(query, "INSERT"):
+		if _, ok := db.rows[args[0].(string)]; ok && !strings.Contains(query, " ON ") {
+			return nil, errDuplicateKey
+		}
+
+		db.set(args)
+
+		return driver.RowsAffected(1), nil
+	}
+
+	return nil, errors.New("unsupported statement: " + query)
+}
+
+// set stores the row and returns the number of rows it changed.
+func (db *fakeDB) set(row []driver.Value) driver.RowsAffected {
+	id := row[0].(string)
+	if current, ok := db.rows[id]; ok && gcmp.Equal(current, row) {
+		return 0
+	}
+
+	db.rows[id] = append([]driver.Value(nil), row...)
+
+	return 1
+}
+
+func (db *fakeDB) query(query string, args []driver.Value) (driver.Rows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if strings.HasPrefix(query, "SELECT 1 ") {
+		if _, ok := db.rows[args[0].(string)]; ok {
+			return &fakeRows{columns: []string{"1"}, values: [][]driver.Value{{int64(1)}}}, nil
+		}
+
+		return &fakeRows{columns: []string{"1"}}, nil
+	}
+
+	var rows [][]driver.Value
+	for _, row := range db.rows {
+		if db.matches(query, args, row) {
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][1] != rows[j][1] {
+			return rows[i][1].(int64) < rows[j][1].(int64)
+		}
+
+		return rows[i][0].(string) < rows[j][0].(string)
+	})
+
+	if _, limit, ok := strings.Cut(query, " LIMIT "); ok {
+		if n, _ := strconv.Atoi(limit); n < len(rows) {
+			rows = rows[:n]
+		}
+	}
+
+	return &fakeRows{columns: strings.Split(
+		"id, received_at, updated_at, signature, payload, status, attempts, last_error", ", "), values: rows}, nil
+}
+
+// matches evaluates the conditions of the WHERE clause of query, in the order of args.
+func (db *fakeDB) matches(query string, args []driver.Value, row []driver.Value) bool {
+	_, where, ok := strings.Cut(query, " WHERE ")
+	if !ok {
+		return true
+	}
+
+	where, _, _ = strings.Cut(where, " ORDER BY ")
+	for i, condition := range strings.Split(where, " AND ") {
+		switch {
+		case strings.HasPrefix(condition, "id ="):
+			if row[0] != args[i] {
+				return false
+			}
+		case strings.HasPrefix(condition, "status ="):
+			if row[5] != args[i] {
+				return false
+			}
+		case strings.HasPrefix(condition, "received_at >="):
+			if row[1].(int64) < args[i].(int64) {
+				return false
+			}
+		case strings.HasPrefix(condition, "received_at <"):
+			if row[1].(int64) >= args[i].(int64) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+type (
+	fakeDriver struct{}
+
+	fakeConn struct {
+		db *fakeDB
+	}
+
+	fakeTx struct{}
+
+	fakeStmt struct {
+		db    *fakeDB
+		query string
+	}
+
+	fakeRows struct {
+		columns []string
+		values  [][]driver.Value
+	}
+)
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use sql.OpenDB") }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (fakeTx) Commit() error { return nil }
+
+func (fakeTx) Rollback() error { return nil }
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return s.db.exec(s.query, args) }
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) { return s.db.query(s.query, args) }
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+
+	return nil
+}
@@ -0,0 +1,394 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package store persists raw webhook notifications so that failed handler executions can be
+// driven again later.
+//
+// Meta retries undelivered notifications for a limited time only, and a notification that
+// was delivered but failed inside the handler is never retried. Handler saves every raw
+// payload before it is handled and records whether handling succeeded. Replay passes the
+// failed notifications through a handler again, for example after a bug fix or an outage of
+// a downstream service.
+//
+// Store is the storage contract. MemoryStore keeps records in process, FileStore keeps one
+// JSON file per record in a directory and SQLStore keeps them in a database/sql table.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusHandled Status = "handled"
+	StatusFailed  Status = "failed"
+)
+
+type (
+	// Record is a persisted notification. ID is derived from the payload, so redelivered
+	// notifications update the same record.
+	Record struct {
+		ID         string          `json:"id"`
+		ReceivedAt time.Time       `json:"received_at"`
+		UpdatedAt  time.Time       `json:"updated_at"`
+		Signature  string          `json:"signature,omitempty"`
+		Payload    json.RawMessage `json:"payload"`
+		Status     Status          `json:"status"`
+		Attempts   int             `json:"attempts"`
+		LastError  string          `json:"last_error,omitempty"`
+	}
+
+	// Query filters records. Zero values are ignored. Results are ordered by ReceivedAt,
+	// oldest first, and Limit keeps the oldest ones.
+	Query struct {
+		Status Status
+		Since  time.Time
+		Until  time.Time
+		Limit  int
+	}
+
+	Store interface {
+		// Save inserts the record or replaces the one with the same ID.
+		Save(ctx context.Context, record *Record) error
+		Get(ctx context.Context, id string) (*Record, error)
+		List(ctx context.Context, query *Query) ([]*Record, error)
+		Delete(ctx context.Context, id string) error
+	}
+)
+
+// RecordID returns the ID of the record of payload.
+func RecordID(payload []byte) string {
+	sum := sha256.Sum256(payload)
+
+	return hex.EncodeToString(sum[:])
+}
+
+type (
+	// Recorder saves notifications to a Store as they are handled.
+	Recorder struct {
+		store   Store
+		now     func() time.Time
+		onError func(ctx context.Context, err error)
+	}
+
+	RecorderOption func(*Recorder)
+)
+
+func WithRecorderClock(now func() time.Time) RecorderOption {
+	return func(r *Recorder) {
+		r.now = now
+	}
+}
+
+// WithRecorderErrorHandler sets the function called when a record cannot be saved. The
+// notification is handled regardless, so a store outage does not stop the webhooks.
+func WithRecorderErrorHandler(fn func(ctx context.Context, err error)) RecorderOption {
+	return func(r *Recorder) {
+		r.onError = fn
+	}
+}
+
+func NewRecorder(store Store, options ...RecorderOption) *Recorder {
+	recorder := &Recorder{
+		store:   store,
+		now:     time.Now,
+		onError: func(context.Context, error) {},
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(recorder)
+		}
+	}
+
+	return recorder
+}
+
+// Handler saves the raw payload of every notification as pending, passes the notification
+// to next and then marks the record handled, or failed when next responds with a 4xx or 5xx
+// status. It needs the raw payload in the context, which webhooks.Listener and
+// webhooks.OnEventNotification put there.
+func Handler[T any](recorder *Recorder, next webhooks.NotificationHandler[T]) webhooks.NotificationHandler[T] {
+	return webhooks.NotificationHandlerFunc[T](func(ctx context.Context, notification *T) *webhooks.Response {
+		payload, ok := webhooks.RawPayloadFromContext(ctx)
+		if !ok {
+			return next.HandleNotification(ctx, notification)
+		}
+
+		now := recorder.now()
+		record, err := recorder.store.Get(ctx, RecordID(payload))
+		if err != nil {
+			// a redelivered notification keeps its first ReceivedAt and its attempts.
+			record = &Record{ID: RecordID(payload), ReceivedAt: now, Payload: payload}
+		}
+
+		record.UpdatedAt = now
+		record.Status = StatusPending
+		if signature := SignatureFromContext(ctx); signature != "" {
+			record.Signature = signature
+		}
+
+		if err := recorder.store.Save(ctx, record); err != nil {
+			recorder.onError(ctx, fmt.Errorf("%w: %s: %w", ErrSaveRecord, record.ID, err))
+
+			return next.HandleNotification(ctx, notification)
+		}
+
+		response := next.HandleNotification(ctx, notification)
+		recorder.finish(record, response)
+
+		if err := recorder.store.Save(ctx, record); err != nil {
+			recorder.onError(ctx, fmt.Errorf("%w: %s: %w", ErrSaveRecord, record.ID, err))
+		}
+
+		return response
+	})
+}
+
+// finish records the outcome of an attempt on record and reports whether it failed.
+func (r *Recorder) finish(record *Record, response *webhooks.Response) bool {
+	record.Attempts++
+	record.UpdatedAt = r.now()
+	record.Status = StatusHandled
+	record.LastError = ""
+
+	if response != nil && response.StatusCode >= http.StatusBadRequest {
+		record.Status = StatusFailed
+		record.LastError = fmt.Sprintf("handler responded with status %d", response.StatusCode)
+
+		return true
+	}
+
+	return false
+}
+
+type signatureContextKey struct{}
+
+// ContextWithSignature stores the signature header of the request, Middleware does this
+// for every request so that it is saved along with the payload.
+func ContextWithSignature(ctx context.Context, signature string) context.Context {
+	return context.WithValue(ctx, signatureContextKey{}, signature)
+}
+
+func SignatureFromContext(ctx context.Context) string {
+	signature, _ := ctx.Value(signatureContextKey{}).(string)
+
+	return signature
+}
+
+// Middleware puts the signature header of the request in its context.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		signature := request.Header.Get(webhooks.SignatureHeaderKey)
+		if signature != "" {
+			request = request.WithContext(ContextWithSignature(request.Context(), signature))
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// ReplayResult counts the records passed through the handler by Replay.
+type ReplayResult struct {
+	Replayed int
+	Handled  int
+	Failed   int
+}
+
+// Replay passes the records matched by query to handle and updates their status. A nil
+// query replays the failed records. Failed records are counted, Replay only stops early
+// when ctx is done or the store fails.
+func (r *Recorder) Replay(ctx context.Context, query *Query,
+	handle func(ctx context.Context, record *Record) *webhooks.Response,
+) (*ReplayResult, error) {
+	if query == nil {
+		query = &Query{Status: StatusFailed}
+	}
+
+	records, err := r.store.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list records: %w", ErrReplay, err)
+	}
+
+	result := &ReplayResult{}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("%w: %w", ErrReplay, err)
+		}
+
+		response := handle(webhooks.ContextWithRawPayload(ctx, record.Payload), record)
+		result.Replayed++
+
+		if r.finish(record, response) {
+			result.Failed++
+		} else {
+			result.Handled++
+		}
+
+		if err := r.store.Save(ctx, record); err != nil {
+			return result, fmt.Errorf("%w: %w: %s: %w", ErrReplay, ErrSaveRecord, record.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Replay decodes the records matched by query into T and passes them to handler, see
+// Recorder.Replay. Records that cannot be decoded are marked failed.
+func Replay[T any](ctx context.Context, recorder *Recorder, query *Query,
+	handler webhooks.NotificationHandler[T],
+) (*ReplayResult, error) {
+	return recorder.Replay(ctx, query, func(ctx context.Context, record *Record) *webhooks.Response {
+		var notification T
+		if err := json.Unmarshal(record.Payload, &notification); err != nil {
+			return &webhooks.Response{StatusCode: http.StatusUnprocessableEntity}
+		}
+
+		return handler.HandleNotification(ctx, &notification)
+	})
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store that keeps records in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]*Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, record *Record) error {
+	if record == nil || record.ID == "" {
+		return ErrInvalidRecord
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *record
+	s.records[record.ID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r := *record
+
+	return &r, nil
+}
+
+func (s *MemoryStore) List(_ context.Context, query *Query) ([]*Record, error) {
+	s.mu.RLock()
+	records := make([]*Record, 0, len(s.records))
+	for _, record := range s.records {
+		r := *record
+		records = append(records, &r)
+	}
+	s.mu.RUnlock()
+
+	return filterRecords(records, query), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+
+	return nil
+}
+
+// filterRecords applies query to records and sorts the result.
+func filterRecords(records []*Record, query *Query) []*Record {
+	if query == nil {
+		query = &Query{}
+	}
+
+	result := records[:0]
+	for _, record := range records {
+		if query.matches(record) {
+			result = append(result, record)
+		}
+	}
+
+	SortRecords(result)
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+
+	return result
+}
+
+func (q *Query) matches(record *Record) bool {
+	if q.Status != "" && record.Status != q.Status {
+		return false
+	}
+
+	if !q.Since.IsZero() && record.ReceivedAt.Before(q.Since) {
+		return false
+	}
+
+	return q.Until.IsZero() || record.ReceivedAt.Before(q.Until)
+}
+
+// SortRecords orders records by the time they were received, oldest first, using the ID
+// to break ties.
+func SortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].ReceivedAt.Equal(records[j].ReceivedAt) {
+			return records[i].ID < records[j].ID
+		}
+
+		return records[i].ReceivedAt.Before(records[j].ReceivedAt)
+	})
+}
+
+// storeError is a custom error type for webhook store errors.
+type storeError string
+
+func (e storeError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNotFound      = storeError("notification record not found")
+	ErrInvalidRecord = storeError("notification record must have an id")
+	ErrSaveRecord    = storeError("could not save notification record")
+	ErrReplay        = storeError("replay failed")
+	ErrInvalidTable  = storeError("invalid table name")
+)
@@ -0,0 +1,94 @@
+package store_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/store"
+)
+
+type notification struct {
+	Object string `json:"object"`
+}
+
+func TestHandlerAndReplay(t *testing.T) {
+	t.Parallel()
+
+	fileStore, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]store.Store{
+		"memory": store.NewMemoryStore(),
+		"file":   fileStore,
+		"sql":    newSQLStore(t, &fakeDB{rows: make(map[string][]driver.Value)}),
+	}
+
+	for name, backend := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			now := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+			recorder := store.NewRecorder(backend, store.WithRecorderClock(func() time.Time {
+				now = now.Add(time.Second)
+
+				return now
+			}))
+
+			failing := true
+			inner := webhooks.NotificationHandlerFunc[notification](
+				func(_ context.Context, n *notification) *webhooks.Response {
+					if n.Object == "broken" && failing {
+						return &webhooks.Response{StatusCode: http.StatusInternalServerError}
+					}
+
+					return &webhooks.Response{StatusCode: http.StatusOK}
+				})
+			handler := store.Handler(recorder, inner)
+
+			for _, payload := range []string{`{"object":"ok"}`, `{"object":"broken"}`} {
+				var n notification
+				if err := json.Unmarshal([]byte(payload), &n); err != nil {
+					t.Fatal(err)
+				}
+				handler.HandleNotification(webhooks.ContextWithRawPayload(ctx, []byte(payload)), &n)
+			}
+
+			failed, err := backend.List(ctx, &store.Query{Status: store.StatusFailed})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(failed) != 1 || string(failed[0].Payload) != `{"object":"broken"}` || failed[0].Attempts != 1 {
+				t.Fatalf("unexpected failed records: %+v", failed)
+			}
+
+			failing = false
+
+			result, err := store.Replay(ctx, recorder, nil, inner)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Replayed != 1 || result.Handled != 1 {
+				t.Errorf("unexpected replay result: %+v", result)
+			}
+
+			record, err := backend.Get(ctx, store.RecordID([]byte(`{"object":"broken"}`)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if record.Status != store.StatusHandled || record.Attempts != 2 {
+				t.Errorf("unexpected record after replay: status %s, attempts %d", record.Status, record.Attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package webhooks
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/webhooks/edge"
+)
+
+type (
+	// WatchKey identifies a source of notifications. PhoneNumberID is empty for fields that
+	// are not tied to a phone number, such as account and template updates.
+	WatchKey struct {
+		BusinessAccountID string
+		PhoneNumberID     string
+	}
+
+	// SilenceAlert reports that no valid notification was received from Key for Silence.
+	// LastSeen is zero when nothing was ever received since the key was watched.
+	SilenceAlert struct {
+		Key      WatchKey
+		LastSeen time.Time
+		Silence  time.Duration
+	}
+
+	SilenceAlertFunc func(ctx context.Context, alert *SilenceAlert)
+
+	// BusinessHours limits alerting to the hours where traffic is expected, so a quiet night
+	// does not page anyone. Start and End are offsets from midnight in Location, an empty
+	// Days means every day.
+	BusinessHours struct {
+		Location *time.Location
+		Days     []time.Weekday
+		Start    time.Duration
+		End      time.Duration
+	}
+
+	WatchdogOption func(*Watchdog)
+
+	// Watchdog tracks when each business account and phone number last delivered a valid
+	// notification and calls the alert function once a source stays silent longer than the
+	// threshold. A silent subscription, for example after an app was unsubscribed from the
+	// WABA, produces no errors anywhere, only the absence of traffic.
+	Watchdog struct {
+		threshold time.Duration
+		interval  time.Duration
+		hours     *BusinessHours
+		alert     SilenceAlertFunc
+		now       func() time.Time
+
+		mu       sync.Mutex
+		sources  map[WatchKey]*watchState
+		lastSeen time.Time
+	}
+
+	watchState struct {
+		since    time.Time
+		lastSeen time.Time
+		alerted  bool
+	}
+)
+
+// Contains reports whether t falls within the business hours, a nil BusinessHours contains
+// every instant.
+func (h *BusinessHours) Contains(t time.Time) bool {
+	if h == nil {
+		return true
+	}
+
+	if h.Location != nil {
+		t = t.In(h.Location)
+	}
+
+	if len(h.Days) > 0 && !slices.Contains(h.Days, t.Weekday()) {
+		return false
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	return offset >= h.Start && offset < h.End
+}
+
+func WithWatchdogBusinessHours(hours *BusinessHours) WatchdogOption {
+	return func(w *Watchdog) {
+		w.hours = hours
+	}
+}
+
+// WithWatchdogInterval sets how often Run checks the sources, a quarter of the threshold
+// by default.
+func WithWatchdogInterval(interval time.Duration) WatchdogOption {
+	return func(w *Watchdog) {
+		w.interval = interval
+	}
+}
+
+func WithWatchdogClock(now func() time.Time) WatchdogOption {
+	return func(w *Watchdog) {
+		w.now = now
+	}
+}
+
+// WithWatchdogSources watches sources before any notification arrives from them, so a
+// subscription that never delivers anything is reported as well.
+func WithWatchdogSources(keys ...WatchKey) WatchdogOption {
+	return func(w *Watchdog) {
+		for _, key := range keys {
+			w.sources[key] = &watchState{}
+		}
+	}
+}
+
+func NewWatchdog(threshold time.Duration, alert SilenceAlertFunc, options ...WatchdogOption) *Watchdog {
+	watchdog := &Watchdog{
+		threshold: threshold,
+		interval:  threshold / 4, //nolint:mnd // check four times per threshold
+		alert:     alert,
+		now:       time.Now,
+		sources:   make(map[WatchKey]*watchState),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(watchdog)
+		}
+	}
+
+	now := watchdog.now()
+	for _, state := range watchdog.sources {
+		state.since = now
+	}
+
+	return watchdog
+}
+
+// Observe records a valid notification from key received at t.
+func (w *Watchdog) Observe(key WatchKey, t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state, ok := w.sources[key]
+	if !ok {
+		state = &watchState{since: t}
+		w.sources[key] = state
+	}
+
+	if t.After(state.lastSeen) {
+		state.lastSeen = t
+		state.alerted = false
+	}
+
+	if t.After(w.lastSeen) {
+		w.lastSeen = t
+	}
+}
+
+// ObservePayload records every business account and phone number found in a raw
+// notification payload.
+func (w *Watchdog) ObservePayload(payload []byte, t time.Time) {
+	_, events, err := edge.Decode(payload)
+	if err != nil {
+		return
+	}
+
+	for _, event := range events {
+		w.Observe(WatchKey{BusinessAccountID: event.EntryID, PhoneNumberID: event.PhoneNumberID}, t)
+	}
+}
+
+// LastSeen returns when the last notification from any source was received.
+func (w *Watchdog) LastSeen() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lastSeen
+}
+
+// LastSeenFor returns when the last notification from key was received.
+func (w *Watchdog) LastSeenFor(key WatchKey) (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state, ok := w.sources[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return state.lastSeen, true
+}
+
+// Check alerts for every source silent for longer than the threshold, once per silence
+// period and only within business hours, and returns the raised alerts.
+func (w *Watchdog) Check(ctx context.Context) []*SilenceAlert {
+	now := w.now()
+	if !w.hours.Contains(now) {
+		return nil
+	}
+
+	w.mu.Lock()
+	var alerts []*SilenceAlert
+	for key, state := range w.sources {
+		from := state.lastSeen
+		if from.IsZero() {
+			from = state.since
+		}
+
+		silence := now.Sub(from)
+		if state.alerted || silence <= w.threshold {
+			continue
+		}
+
+		state.alerted = true
+		alerts = append(alerts, &SilenceAlert{Key: key, LastSeen: state.lastSeen, Silence: silence})
+	}
+	w.mu.Unlock()
+
+	slices.SortFunc(alerts, func(a, b *SilenceAlert) int {
+		return cmp.Or(
+			cmp.Compare(a.Key.BusinessAccountID, b.Key.BusinessAccountID),
+			cmp.Compare(a.Key.PhoneNumberID, b.Key.PhoneNumberID),
+		)
+	})
+
+	if w.alert != nil {
+		for _, alert := range alerts {
+			w.alert(ctx, alert)
+		}
+	}
+
+	return alerts
+}
+
+// Run checks the sources every interval until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) error {
+	ticker := time.NewTicker(max(w.interval, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			w.Check(ctx)
+		}
+	}
+}
+
+// WatchdogMiddleware feeds watchdog with every notification reaching the handler, that is
+// every notification that passed signature validation and decoding.
+func WatchdogMiddleware[T any](watchdog *Watchdog) HandleMiddleware[T] {
+	return func(next NotificationHandlerFunc[T]) NotificationHandlerFunc[T] {
+		return func(ctx context.Context, notification *T) *Response {
+			if payload, ok := RawPayloadFromContext(ctx); ok {
+				watchdog.ObservePayload(payload, watchdog.now())
+			}
+
+			return next(ctx, notification)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package webhooks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/webhooks"
+)
+
+func TestWatchdog_Check(t *testing.T) {
+	t.Parallel()
+
+	// Monday 2024-03-04 09:00 UTC.
+	now := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	silent := webhooks.WatchKey{BusinessAccountID: "waba", PhoneNumberID: "never"}
+	active := webhooks.WatchKey{BusinessAccountID: "waba", PhoneNumberID: "111"}
+
+	var alerted []webhooks.WatchKey
+	watchdog := webhooks.NewWatchdog(time.Hour,
+		func(_ context.Context, alert *webhooks.SilenceAlert) {
+			alerted = append(alerted, alert.Key)
+		},
+		webhooks.WithWatchdogClock(clock),
+		webhooks.WithWatchdogSources(silent),
+		webhooks.WithWatchdogBusinessHours(&webhooks.BusinessHours{
+			Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Start: 8 * time.Hour,
+			End:   18 * time.Hour,
+		}),
+	)
+
+	watchdog.ObservePayload([]byte(`{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[
+		{"field":"messages","value":{"metadata":{"phone_number_id":"111"}}}]}]}`), now)
+
+	if got, ok := watchdog.LastSeenFor(active); !ok || !got.Equal(now) {
+		t.Fatalf("LastSeenFor() = %v, %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	watchdog.Check(context.Background())
+	watchdog.Check(context.Background())
+
+	if diff := gcmp.Diff([]webhooks.WatchKey{active, silent}, alerted); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+
+	// A new notification re-arms the alert, which stays quiet outside business hours.
+	watchdog.Observe(active, now)
+	now = now.Add(10 * time.Hour)
+	if alerts := watchdog.Check(context.Background()); len(alerts) != 0 {
+		t.Errorf("expected no alerts outside business hours, got %d", len(alerts))
+	}
+
+	now = now.Add(12 * time.Hour)
+	if alerts := watchdog.Check(context.Background()); len(alerts) != 1 || alerts[0].Key != active {
+		t.Errorf("expected the active source to alert again, got %v", alerts)
+	}
+}
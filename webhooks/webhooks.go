@@ -22,15 +22,14 @@ package webhooks
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/piusalfred/whatsapp/webhooks/edge"
 )
 
 type Listener[T any] struct {
@@ -39,6 +38,25 @@ type Listener[T any] struct {
 	Handler           NotificationHandlerFunc[T]
 	VerifyTokenReader VerifyTokenReader
 	ValidateOptions   *ValidateOptions
+
+	// Mirror, when set, receives a copy of every notification that passed validation.
+	Mirror *Mirror
+
+	// Lenient makes the Listener pass partially decoded notifications to the handler
+	// instead of failing when some values do not match the models, see DecodeLenient.
+	Lenient bool
+
+	// RawHandler, when set, receives the raw body of notifications that failed to decode.
+	RawHandler RawNotificationHandler
+
+	// Backpressure, when set, makes the Listener answer 503 with a Retry-After header
+	// while the processing queues are too deep, so that WhatsApp retries later.
+	Backpressure *Backpressure
+
+	// Queue, when set, makes the Listener acknowledge notifications as soon as their
+	// signature is validated and leave decoding and handling to the workers started by
+	// ProcessQueue.
+	Queue Queue
 }
 
 func NewListener[T any](handler NotificationHandlerFunc[T],
@@ -68,20 +86,91 @@ func (listener *Listener[T]) HandleNotification(writer http.ResponseWriter, requ
 		notification *T
 		ctx          = request.Context()
 		err          error
+		payload      []byte
 	)
 
-	notification, err = ExtractAndValidatePayload[T](request, listener.ValidateOptions)
+	if listener.Backpressure != nil && listener.Backpressure.reject(writer) {
+		return
+	}
+
+	payload, err = io.ReadAll(request.Body)
 	if err != nil {
-		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		http.Error(writer, fmt.Errorf("%w: %w", ErrBadRequest, err).Error(), http.StatusInternalServerError)
 
 		return
 	}
+	request.Body = io.NopCloser(bytes.NewReader(payload))
+
+	if listener.ValidateOptions != nil && listener.ValidateOptions.Validate {
+		err = ValidatePayloadSignature(request.Header, payload, listener.ValidateOptions.AppSecret)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	if listener.Queue != nil {
+		listener.enqueue(ctx, writer, payload)
+
+		return
+	}
+
+	notification, response := listener.decode(ctx, payload)
+	if response != nil {
+		writer.WriteHeader(response.StatusCode)
+
+		return
+	}
+
+	if notification == nil {
+		http.Error(writer, ErrMessageDecode.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if listener.Mirror != nil {
+		listener.Mirror.Dispatch(ctx, payload)
+	}
 
-	response := listener.Handler.HandleNotification(ctx, notification)
+	response = listener.Handler.HandleNotification(ContextWithRawPayload(ctx, payload), notification)
 
 	writer.WriteHeader(response.StatusCode)
 }
 
+// decode returns the notification to handle, or the response to end the request with when
+// the RawHandler took over. Both are nil when decoding failed and nobody recovered.
+func (listener *Listener[T]) decode(ctx context.Context, payload []byte) (*T, *Response) {
+	var (
+		notification T
+		errs         []*DecodeError
+	)
+
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return &notification, nil
+	}
+
+	err := json.Unmarshal(payload, &notification)
+	if err == nil {
+		return &notification, nil
+	}
+
+	partial := (*T)(nil)
+	if listener.Lenient {
+		partial, errs = DecodeLenient[T](payload)
+	} else {
+		errs = []*DecodeError{{Err: err}}
+	}
+
+	if listener.RawHandler != nil {
+		if response := listener.RawHandler.HandleRawNotification(ContextWithRawPayload(ctx, payload), payload, errs); response != nil {
+			return nil, response
+		}
+	}
+
+	return partial, nil
+}
+
 type (
 	HandleMiddleware[T any] func(handlerFunc NotificationHandlerFunc[T]) NotificationHandlerFunc[T]
 
@@ -122,7 +211,7 @@ func OnEventNotification[T any](handler NotificationHandler[T]) http.HandlerFunc
 			return
 		}
 
-		response := handler.HandleNotification(request.Context(), &payload)
+		response := handler.HandleNotification(ContextWithRawPayload(request.Context(), body), &payload)
 
 		writer.WriteHeader(response.StatusCode)
 	})
@@ -130,6 +219,21 @@ func OnEventNotification[T any](handler NotificationHandler[T]) http.HandlerFunc
 	return fn
 }
 
+type rawPayloadContextKey struct{}
+
+// ContextWithRawPayload returns a copy of ctx carrying the raw notification payload. The
+// Listener and OnEventNotification add it before calling the notification handler.
+func ContextWithRawPayload(ctx context.Context, payload []byte) context.Context {
+	return context.WithValue(ctx, rawPayloadContextKey{}, payload)
+}
+
+// RawPayloadFromContext returns the raw notification payload the handler is processing.
+func RawPayloadFromContext(ctx context.Context) ([]byte, bool) {
+	payload, ok := ctx.Value(rawPayloadContextKey{}).([]byte)
+
+	return payload, ok
+}
+
 type ValidateOptions struct {
 	Validate  bool
 	AppSecret string
@@ -159,7 +263,7 @@ func ExtractAndValidatePayload[T any](request *http.Request, options *ValidateOp
 }
 
 // SignatureHeaderKey is the key for the X-Hub-Signature-256 header.
-const SignatureHeaderKey = "X-Hub-Signature-256"
+const SignatureHeaderKey = edge.SignatureHeader
 
 // ValidateSignatureOptions holds the parameters required for signature validation.
 // It combines the payload (which is the raw request body), the signature string extracted from the header,
@@ -184,20 +288,13 @@ type ValidateSignatureOptions struct {
 //
 // Errors are returned if the signature is invalid or the decoding process fails.
 func ValidateSignature(payload []byte, params ValidateSignatureOptions) error {
-	// Decode the provided signature from hexadecimal to raw bytes.
-	decodedSig, err := hex.DecodeString(params.Signature)
-	if err != nil {
-		return fmt.Errorf("error decoding signature: %w", err)
-	}
-
-	mac := hmac.New(sha256.New, []byte(params.AppSecret))
-	if _, err := mac.Write(payload); err != nil {
-		return fmt.Errorf("error hashing payload: %w", err)
+	err := edge.VerifySignature(payload, params.Signature, params.AppSecret)
+	if errors.Is(err, edge.ErrInvalidSignature) {
+		return ErrInvalidSignature
 	}
-	expectedSignature := mac.Sum(nil)
 
-	if !hmac.Equal(decodedSig, expectedSignature) {
-		return ErrInvalidSignature
+	if err != nil {
+		return fmt.Errorf("error decoding signature: %w", err)
 	}
 
 	return nil
@@ -353,4 +450,11 @@ const (
 	ErrReadNotification      = webhookError("error reading request body")
 	ErrMessageDecode         = webhookError("error decoding message")
 	ErrBadRequest            = webhookError("could not retrieve the notification content")
+	ErrMirrorForward         = webhookError("could not forward notification to mirror target")
+	ErrBackpressure          = webhookError("notification processing is overloaded, retry later")
+	ErrEnqueue               = webhookError("could not enqueue notification")
+	ErrQueueFull             = webhookError("notification queue is full")
+	ErrQueueClosed           = webhookError("notification queue is closed")
+	ErrNoQueue               = webhookError("listener has no queue")
+	ErrProcessNotification   = webhookError("failed to process notification")
 )
@@ -0,0 +1,326 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package webhooktest fabricates webhook notifications for tests of notification
+// handlers. A NotificationBuilder assembles the payload from typed values instead of
+// copied JSON, Sign and NewRequest sign it like Meta does, and Deliver hands it to a
+// Listener:
+//
+//	payload := webhooktest.NewNotificationBuilder().
+//		Text("255700000000", "hello").
+//		Status("wamid.1", "255700000000", "read").
+//		MustPayload()
+//
+//	recorder := webhooktest.Deliver(listener, payload, "app-secret")
+package webhooktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/flow"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+)
+
+const (
+	ObjectWhatsAppBusinessAccount = "whatsapp_business_account"
+	MessagingProduct              = "whatsapp"
+
+	ChangeFieldCalls = "calls"
+	ChangeFieldFlows = "flows"
+
+	DefaultBusinessAccountID  = "102290129340398"
+	DefaultPhoneNumberID      = "106540352242922"
+	DefaultDisplayPhoneNumber = "15550783881"
+)
+
+type (
+	// NotificationBuilder builds the payload of a notification with one entry and a
+	// change per call. It is not safe for concurrent use.
+	NotificationBuilder struct {
+		businessAccountID string
+		metadata          *message.Metadata
+		now               func() time.Time
+		changes           []*change
+	}
+
+	BuilderOption func(*NotificationBuilder)
+
+	// Call is a call event delivered on the calls field.
+	Call struct {
+		ID        string `json:"id"`
+		From      string `json:"from,omitempty"`
+		To        string `json:"to,omitempty"`
+		Event     string `json:"event"`
+		Direction string `json:"direction,omitempty"`
+		Status    string `json:"status,omitempty"`
+		Timestamp string `json:"timestamp"`
+	}
+
+	change struct {
+		Field string `json:"field"`
+		Value any    `json:"value"`
+	}
+
+	callValue struct {
+		MessagingProduct string            `json:"messaging_product"`
+		Metadata         *message.Metadata `json:"metadata"`
+		Calls            []*Call           `json:"calls"`
+	}
+
+	entry struct {
+		ID      string    `json:"id"`
+		Time    int64     `json:"time"`
+		Changes []*change `json:"changes"`
+	}
+
+	notification struct {
+		Object string   `json:"object"`
+		Entry  []*entry `json:"entry"`
+	}
+)
+
+// WithBusinessAccountID sets the id of the entry, DefaultBusinessAccountID by default.
+func WithBusinessAccountID(id string) BuilderOption {
+	return func(b *NotificationBuilder) {
+		b.businessAccountID = id
+	}
+}
+
+// WithPhoneNumber sets the business phone number the notifications are about.
+func WithPhoneNumber(phoneNumberID, displayPhoneNumber string) BuilderOption {
+	return func(b *NotificationBuilder) {
+		b.metadata = &message.Metadata{DisplayPhoneNumber: displayPhoneNumber, PhoneNumberID: phoneNumberID}
+	}
+}
+
+// WithClock sets the source of the timestamps, time.Now by default.
+func WithClock(now func() time.Time) BuilderOption {
+	return func(b *NotificationBuilder) {
+		b.now = now
+	}
+}
+
+func NewNotificationBuilder(options ...BuilderOption) *NotificationBuilder {
+	b := &NotificationBuilder{
+		businessAccountID: DefaultBusinessAccountID,
+		metadata: &message.Metadata{
+			DisplayPhoneNumber: DefaultDisplayPhoneNumber,
+			PhoneNumberID:      DefaultPhoneNumberID,
+		},
+		now: time.Now,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(b)
+		}
+	}
+
+	return b
+}
+
+var messageSequence atomic.Int64
+
+// MessageID returns a new message id in the wamid format, unique within the process.
+func MessageID() string {
+	return "wamid.test" + strconv.FormatInt(messageSequence.Add(1), 10)
+}
+
+func (b *NotificationBuilder) timestamp() string {
+	return strconv.FormatInt(b.now().Unix(), 10)
+}
+
+// Change adds a change with an arbitrary value, for fields the builder has no method for.
+func (b *NotificationBuilder) Change(field string, value any) *NotificationBuilder {
+	b.changes = append(b.changes, &change{Field: field, Value: value})
+
+	return b
+}
+
+// Message adds an inbound message from msg.From. The id and the timestamp are set when
+// they are empty.
+func (b *NotificationBuilder) Message(msg *message.Message) *NotificationBuilder {
+	if msg.ID == "" {
+		msg.ID = MessageID()
+	}
+
+	if msg.Timestamp == "" {
+		msg.Timestamp = b.timestamp()
+	}
+
+	return b.Change(message.ChangeFieldMessages, &message.Value{
+		MessagingProduct: MessagingProduct,
+		Metadata:         b.metadata,
+		Contacts:         []*message.Contact{{Profile: &message.Profile{Name: "Test User"}, WaID: msg.From}},
+		Messages:         []*message.Message{msg},
+	})
+}
+
+func (b *NotificationBuilder) Text(from, body string) *NotificationBuilder {
+	return b.Message(&message.Message{From: from, Type: string(message.TypeText), Text: &message.Text{Body: body}})
+}
+
+// Media adds a media message, mediaType is one of audio, document, image, sticker and video.
+func (b *NotificationBuilder) Media(from, mediaType string, media *wmessage.MediaInfo) *NotificationBuilder {
+	msg := &message.Message{From: from, Type: mediaType}
+
+	switch mediaType {
+	case "audio":
+		msg.Audio = media
+	case "document":
+		msg.Document = media
+	case "sticker":
+		msg.Sticker = media
+	case "video":
+		msg.Video = media
+	default:
+		msg.Image = media
+	}
+
+	return b.Message(msg)
+}
+
+// FlowReply adds the reply sent when the user completes a flow, response is encoded as
+// the response_json string the way the Cloud API delivers it.
+func (b *NotificationBuilder) FlowReply(from string, response map[string]any) *NotificationBuilder {
+	data, _ := json.Marshal(response)
+	encoded, _ := json.Marshal(string(data))
+
+	return b.Message(&message.Message{
+		From: from,
+		Type: string(message.TypeInteractive),
+		Interactive: &message.Interactive{
+			Type: message.InteractiveTypeNFMReply,
+			NFMReply: &message.NFMReply{
+				Name:         "flow",
+				Body:         "Sent",
+				ResponseJSON: encoded,
+			},
+		},
+	})
+}
+
+// Status adds a status update of the message sent to recipient.
+func (b *NotificationBuilder) Status(messageID, recipient, status string) *NotificationBuilder {
+	return b.Change(message.ChangeFieldMessages, &message.Value{
+		MessagingProduct: MessagingProduct,
+		Metadata:         b.metadata,
+		Statuses: []*message.Status{{
+			ID:          messageID,
+			RecipientID: recipient,
+			StatusValue: status,
+			Timestamp:   b.now().Unix(),
+		}},
+	})
+}
+
+// FlowEvent adds a flows notification, such as a status change or an error rate alert.
+func (b *NotificationBuilder) FlowEvent(value *flow.Value) *NotificationBuilder {
+	return b.Change(ChangeFieldFlows, value)
+}
+
+// Call adds a call event. The timestamp is set when it is empty.
+func (b *NotificationBuilder) Call(call *Call) *NotificationBuilder {
+	if call.Timestamp == "" {
+		call.Timestamp = b.timestamp()
+	}
+
+	return b.Change(ChangeFieldCalls, &callValue{
+		MessagingProduct: MessagingProduct,
+		Metadata:         b.metadata,
+		Calls:            []*Call{call},
+	})
+}
+
+// Payload returns the JSON body of the notification.
+func (b *NotificationBuilder) Payload() ([]byte, error) {
+	payload, err := json.Marshal(&notification{
+		Object: ObjectWhatsAppBusinessAccount,
+		Entry: []*entry{{
+			ID:      b.businessAccountID,
+			Time:    b.now().Unix(),
+			Changes: b.changes,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webhooktest: encode notification: %w", err)
+	}
+
+	return payload, nil
+}
+
+// MustPayload is like Payload but panics on error.
+func (b *NotificationBuilder) MustPayload() []byte {
+	payload, err := b.Payload()
+	if err != nil {
+		panic(err)
+	}
+
+	return payload
+}
+
+// Sign returns the X-Hub-Signature-256 header value of payload.
+func Sign(payload []byte, appSecret string) string {
+	return webhooks.SignPayload(payload, appSecret)
+}
+
+// NewRequest returns a signed POST request carrying payload, ready to be served by a
+// handler. An empty appSecret leaves the request unsigned.
+func NewRequest(ctx context.Context, url string, payload []byte, appSecret string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("webhooktest: new request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if appSecret != "" {
+		request.Header.Set(webhooks.SignatureHeaderKey, Sign(payload, appSecret))
+	}
+
+	return request, nil
+}
+
+// Post serves the signed payload with handler and returns the recorded response.
+func Post(handler http.Handler, payload []byte, appSecret string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	if appSecret != "" {
+		request.Header.Set(webhooks.SignatureHeaderKey, Sign(payload, appSecret))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// Deliver posts the signed payload to the notification handler of listener.
+func Deliver[T any](listener *webhooks.Listener[T], payload []byte, appSecret string) *httptest.ResponseRecorder {
+	return Post(http.HandlerFunc(listener.HandleNotification), payload, appSecret)
+}
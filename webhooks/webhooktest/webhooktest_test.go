@@ -0,0 +1,85 @@
+package webhooktest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	wmessage "github.com/piusalfred/whatsapp/message"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+	"github.com/piusalfred/whatsapp/webhooks/webhooktest"
+)
+
+func TestDeliver(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+
+	handlers := &message.Handlers{}
+	handlers.SetTextMessageHandler(message.OnTextMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, info *message.Info, text *message.Text) error {
+			got = append(got, "text:"+info.From+":"+text.Body)
+
+			return nil
+		}))
+	handlers.SetImageMessageHandler(message.OnMediaMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, _ *message.Info, media *wmessage.MediaInfo) error {
+			got = append(got, "image:"+media.ID)
+
+			return nil
+		}))
+	handlers.SetFlowCompletionMessageHandler(message.OnFlowCompletionMessageHook(
+		func(_ context.Context, _ *message.NotificationContext, _ *message.Info, reply *message.NFMReply) error {
+			var encoded string
+			if err := json.Unmarshal(reply.ResponseJSON, &encoded); err != nil {
+				return err
+			}
+
+			got = append(got, "flow:"+encoded)
+
+			return nil
+		}))
+	handlers.SetMessageStatusChangeHandler(message.OnMessageStatusChangeHook(
+		func(_ context.Context, _ *message.NotificationContext, status *message.Status) error {
+			got = append(got, "status:"+status.ID+":"+status.StatusValue)
+
+			return nil
+		}))
+
+	listener := webhooks.NewListener(handlers.HandleNotification, nil,
+		&webhooks.ValidateOptions{Validate: true, AppSecret: "secret"})
+
+	payload := webhooktest.NewNotificationBuilder(
+		webhooktest.WithClock(func() time.Time { return time.Unix(1700000000, 0) }),
+	).
+		Text("255700000000", "hello").
+		Media("255700000000", "image", &wmessage.MediaInfo{ID: "media-1", MimeType: "image/jpeg"}).
+		FlowReply("255700000000", map[string]any{"flow_token": "t"}).
+		Status("wamid.1", "255700000000", "read").
+		Call(&webhooktest.Call{ID: "wacid.1", From: "255700000000", Event: "connect"}).
+		MustPayload()
+
+	if recorder := webhooktest.Deliver(listener, payload, "wrong"); recorder.Code == http.StatusOK {
+		t.Fatalf("delivery signed with the wrong secret was accepted")
+	}
+
+	if recorder := webhooktest.Deliver(listener, payload, "secret"); recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	want := []string{
+		"text:255700000000:hello",
+		"image:media-1",
+		`flow:{"flow_token":"t"}`,
+		"status:wamid.1:read",
+	}
+
+	if diff := gcmp.Diff(want, got); diff != "" {
+		t.Errorf("handled mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,606 @@
+/*
+ *  Copyright 2023 Pius Alfred <me.pius1102@gmail.com>
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy of this software
+ *  and associated documentation files (the “Software”), to deal in the Software without restriction,
+ *  including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ *  and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so,
+ *  subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all copies or substantial
+ *  portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+ *  LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ *  IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+ *  WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ *  SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Package whatsapptest provides a fake Cloud API server for end-to-end tests. The Server
+// answers the message, media and QR code endpoints like the Graph API does, keeps what it
+// received for assertions, and can report the delivery of the messages it accepted to a
+// webhook handler:
+//
+//	server := whatsapptest.NewServer(whatsapptest.WithWebhook(listener, "app-secret"))
+//	defer server.Close()
+//
+//	client, err := message.NewBaseClient(whttp.NewSender[message.Message](), server.ConfigReader())
+//
+// Errors, including rate limits, are injected with FailNext and WithRateLimit.
+package whatsapptest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piusalfred/whatsapp/config"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	"github.com/piusalfred/whatsapp/webhooks/webhooktest"
+)
+
+const (
+	DefaultAPIVersion         = "v20.0"
+	DefaultAccessToken        = "test-access-token"
+	DefaultPhoneNumberID      = webhooktest.DefaultPhoneNumberID
+	DefaultDisplayPhoneNumber = webhooktest.DefaultDisplayPhoneNumber
+	DefaultBusinessAccountID  = webhooktest.DefaultBusinessAccountID
+
+	// MaxUploadSize is the largest media upload the server accepts.
+	MaxUploadSize = 100 << 20
+)
+
+type (
+	// Server is a fake Cloud API. It is safe for concurrent use.
+	Server struct {
+		server     *httptest.Server
+		token      string
+		webhook    http.Handler
+		secret     string
+		statuses   []string
+		delay      time.Duration
+		limit      int
+		window     time.Duration
+		now        func() time.Time
+		ctx        context.Context //nolint:containedctx // cancels the pending status webhooks
+		cancel     context.CancelFunc
+		deliveries sync.WaitGroup
+
+		mu       sync.Mutex
+		sequence int
+		messages []*SentMessage
+		media    map[string]*Media
+		qrCodes  map[string]*QRCode
+		failures []*failure
+		sends    map[string][]time.Time
+	}
+
+	ServerOption func(*Server)
+
+	// SentMessage is a message accepted by the server.
+	SentMessage struct {
+		ID            string
+		PhoneNumberID string
+		Message       *wmessage.Message
+		ReceivedAt    time.Time
+	}
+
+	// Media is an uploaded media file.
+	Media struct {
+		ID       string
+		MimeType string
+		Data     []byte
+	}
+
+	QRCode struct {
+		Code             string `json:"code"`
+		PrefilledMessage string `json:"prefilled_message"`
+		DeepLinkURL      string `json:"deep_link_url"`
+	}
+
+	failure struct {
+		status int
+		err    *werrors.Error
+	}
+)
+
+// WithAccessToken makes the server reject requests without this access token, as a bearer
+// token or an access_token parameter. The default is DefaultAccessToken, an empty token
+// accepts every request.
+func WithAccessToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithWebhook delivers the status updates of accepted messages to handler, signed with
+// appSecret. Use http.HandlerFunc(listener.HandleNotification) for a webhooks.Listener and
+// WebhookURL for a listener served over HTTP.
+func WithWebhook(handler http.Handler, appSecret string) ServerOption {
+	return func(s *Server) {
+		s.webhook = handler
+		s.secret = appSecret
+	}
+}
+
+// WithStatuses sets the statuses reported for every accepted message, "sent" and
+// "delivered" by default, and the delay before each of them.
+func WithStatuses(delay time.Duration, statuses ...string) ServerOption {
+	return func(s *Server) {
+		s.delay = delay
+		s.statuses = statuses
+	}
+}
+
+// WithRateLimit fails the messages sent from a phone number beyond limit per window with
+// the throughput error 130429.
+func WithRateLimit(limit int, window time.Duration) ServerOption {
+	return func(s *Server) {
+		s.limit = limit
+		s.window = window
+	}
+}
+
+// WithClock sets the source of the timestamps, time.Now by default.
+func WithClock(now func() time.Time) ServerOption {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WebhookURL returns a handler that forwards the notifications to the listener at url.
+func WebhookURL(url string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := http.NewRequestWithContext(r.Context(), r.Method, url, r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		request.Header = r.Header.Clone()
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+
+			return
+		}
+		defer response.Body.Close()
+
+		w.WriteHeader(response.StatusCode)
+		_, _ = io.Copy(w, response.Body)
+	})
+}
+
+// NewServer starts a Server, it is stopped with Close.
+func NewServer(options ...ServerOption) *Server {
+	s := &Server{
+		token:    DefaultAccessToken,
+		statuses: []string{"sent", "delivered"},
+		now:      time.Now,
+		media:    make(map[string]*Media),
+		qrCodes:  make(map[string]*QRCode),
+		sends:    make(map[string][]time.Time),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(s)
+		}
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{version}/{phone}/messages", s.handleMessage)
+	mux.HandleFunc("POST /{version}/{phone}/media", s.handleUpload)
+	mux.HandleFunc("GET /{version}/{id}", s.handleGetMedia)
+	mux.HandleFunc("DELETE /{version}/{id}", s.handleDeleteMedia)
+	mux.HandleFunc("GET /files/{id}", s.handleDownload)
+	mux.HandleFunc("GET /{version}/{phone}/message_qrdls", s.handleListQRCodes)
+	mux.HandleFunc("POST /{version}/{phone}/message_qrdls", s.handleSaveQRCode)
+	mux.HandleFunc("GET /{version}/{phone}/message_qrdls/{code}", s.handleGetQRCode)
+	mux.HandleFunc("POST /{version}/{phone}/message_qrdls/{code}", s.handleSaveQRCode)
+	mux.HandleFunc("DELETE /{version}/{phone}/message_qrdls/{code}", s.handleDeleteQRCode)
+
+	s.server = httptest.NewServer(s.authenticate(mux))
+
+	return s
+}
+
+// URL is the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Config returns a Config that points the clients at the server.
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		BaseURL:           s.server.URL,
+		APIVersion:        DefaultAPIVersion,
+		AccessToken:       s.token,
+		PhoneNumberID:     DefaultPhoneNumberID,
+		BusinessAccountID: DefaultBusinessAccountID,
+	}
+}
+
+// ConfigReader returns a config.Reader of Config.
+func (s *Server) ConfigReader() config.ReaderFunc {
+	return func(context.Context) (*config.Config, error) {
+		return s.Config(), nil
+	}
+}
+
+// Close stops the server, pending status webhooks are dropped.
+func (s *Server) Close() {
+	s.cancel()
+	s.deliveries.Wait()
+	s.server.Close()
+}
+
+// FailNext makes the next request fail with err and the HTTP status.
+func (s *Server) FailNext(status int, err *werrors.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &failure{status: status, err: err})
+}
+
+// Messages returns the messages accepted so far, in the order they were received.
+func (s *Server) Messages() []*SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]*SentMessage, len(s.messages))
+	copy(messages, s.messages)
+
+	return messages
+}
+
+// Media returns the uploaded media with the given id.
+func (s *Server) Media(id string) (*Media, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	media, ok := s.media[id]
+
+	return media, ok
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.sequence++
+
+	return prefix + strconv.Itoa(s.sequence)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = r.URL.Query().Get("access_token")
+			}
+
+			if token != s.token {
+				writeError(w, http.StatusUnauthorized, &werrors.Error{
+					Message: "Invalid OAuth access token - Cannot parse access token",
+					Type:    "OAuthException",
+					Code:    190, //nolint:mnd // invalid access token
+				})
+
+				return
+			}
+		}
+
+		s.mu.Lock()
+		var injected *failure
+		if len(s.failures) > 0 {
+			injected, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if injected != nil {
+			writeError(w, injected.status, injected.err)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var msg wmessage.Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		writeError(w, http.StatusBadRequest, &werrors.Error{
+			Message: "Invalid parameter", Type: "OAuthException", Code: werrors.CodeInvalidParameter,
+		})
+
+		return
+	}
+
+	if msg.Status != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"success": true})
+
+		return
+	}
+
+	phone := r.PathValue("phone")
+	now := s.now()
+
+	s.mu.Lock()
+	if s.limited(phone, now) {
+		s.mu.Unlock()
+		writeError(w, http.StatusTooManyRequests, &werrors.Error{
+			Message: "(#130429) Rate limit hit", Type: "OAuthException", Code: werrors.CodeCloudAPIThroughput,
+		})
+
+		return
+	}
+
+	sent := &SentMessage{ID: s.nextID("wamid.test."), PhoneNumberID: phone, Message: &msg, ReceivedAt: now}
+	s.messages = append(s.messages, sent)
+	s.mu.Unlock()
+
+	s.reportStatuses(sent)
+
+	writeJSON(w, http.StatusOK, &wmessage.Response{
+		Product:  wmessage.MessagingProduct,
+		Contacts: []*wmessage.ResponseContact{{Input: msg.To, WhatsappID: msg.To}},
+		Messages: []*wmessage.ID{{ID: sent.ID}},
+	})
+}
+
+// limited records a send from phone and reports whether it exceeds the rate limit.
+func (s *Server) limited(phone string, now time.Time) bool {
+	if s.limit <= 0 {
+		return false
+	}
+
+	recent := s.sends[phone][:0]
+	for _, at := range s.sends[phone] {
+		if now.Sub(at) < s.window {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) >= s.limit {
+		s.sends[phone] = recent
+
+		return true
+	}
+
+	s.sends[phone] = append(recent, now)
+
+	return false
+}
+
+func (s *Server) reportStatuses(sent *SentMessage) {
+	if s.webhook == nil || len(s.statuses) == 0 {
+		return
+	}
+
+	s.deliveries.Add(1)
+
+	go func() {
+		defer s.deliveries.Done()
+
+		for _, status := range s.statuses {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(s.delay):
+			}
+
+			payload, err := webhooktest.NewNotificationBuilder(
+				webhooktest.WithPhoneNumber(sent.PhoneNumberID, DefaultDisplayPhoneNumber),
+				webhooktest.WithClock(s.now),
+			).Status(sent.ID, sent.Message.To, status).Payload()
+			if err != nil {
+				return
+			}
+
+			webhooktest.Post(s.webhook, payload, s.secret)
+		}
+	}()
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, &werrors.Error{
+			Message: "Invalid parameter", Type: "OAuthException", Code: werrors.CodeInvalidParameter,
+		})
+
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, &werrors.Error{
+			Message: "file is required", Type: "OAuthException", Code: werrors.CodeInvalidParameter,
+		})
+
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	mimeType := r.FormValue("type")
+	if mimeType == "" {
+		mimeType = header.Header.Get("Content-Type")
+	}
+
+	s.mu.Lock()
+	media := &Media{ID: s.nextID("media-"), MimeType: mimeType, Data: data}
+	s.media[media.ID] = media
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": media.ID})
+}
+
+func (s *Server) handleGetMedia(w http.ResponseWriter, r *http.Request) {
+	media, ok := s.Media(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	sum := sha256.Sum256(media.Data)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"messaging_product": wmessage.MessagingProduct,
+		"url":               s.server.URL + "/files/" + media.ID,
+		"mime_type":         media.MimeType,
+		"sha256":            hex.EncodeToString(sum[:]),
+		"file_size":         len(media.Data),
+		"id":                media.ID,
+	})
+}
+
+func (s *Server) handleDeleteMedia(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.media[r.PathValue("id")]
+	delete(s.media, r.PathValue("id"))
+	s.mu.Unlock()
+
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"success": true})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	media, ok := s.Media(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", media.MimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(media.Data)))
+	_, _ = w.Write(media.Data)
+}
+
+func (s *Server) handleListQRCodes(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	codes := make([]*QRCode, 0, len(s.qrCodes))
+	for _, code := range s.qrCodes {
+		codes = append(codes, code)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": codes})
+}
+
+func (s *Server) handleGetQRCode(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	code, ok := s.qrCodes[r.PathValue("code")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": []*QRCode{code}})
+}
+
+func (s *Server) handleSaveQRCode(w http.ResponseWriter, r *http.Request) {
+	message := r.URL.Query().Get("prefilled_message")
+	id := r.PathValue("code")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id != "" {
+		code, ok := s.qrCodes[id]
+		if !ok {
+			writeNotFound(w)
+
+			return
+		}
+
+		code.PrefilledMessage = message
+		writeJSON(w, http.StatusOK, map[string]any{"success": true})
+
+		return
+	}
+
+	code := &QRCode{Code: s.nextID("QR"), PrefilledMessage: message}
+	code.DeepLinkURL = "https://wa.me/message/" + code.Code
+	s.qrCodes[code.Code] = code
+
+	response := map[string]string{
+		"code":              code.Code,
+		"prefilled_message": code.PrefilledMessage,
+		"deep_link_url":     code.DeepLinkURL,
+	}
+
+	if format := r.URL.Query().Get("generate_qr_image"); format != "" {
+		response["qr_image_url"] = s.server.URL + "/files/" + code.Code + "." + strings.ToLower(format)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleDeleteQRCode(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.qrCodes[r.PathValue("code")]
+	delete(s.qrCodes, r.PathValue("code"))
+	s.mu.Unlock()
+
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"success": true})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, &werrors.Error{
+		Message: "Unsupported get request. Object does not exist",
+		Type:    "GraphMethodException",
+		Code:    werrors.CodeInvalidParameter,
+		Subcode: 33, //nolint:mnd // object does not exist
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, err *werrors.Error) {
+	body := *err
+	if body.FBTraceID == "" {
+		body.FBTraceID = "whatsapptest"
+	}
+
+	writeJSON(w, status, map[string]any{"error": &body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
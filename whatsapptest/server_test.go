@@ -0,0 +1,159 @@
+package whatsapptest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	gcmp "github.com/google/go-cmp/cmp"
+
+	"github.com/piusalfred/whatsapp/media"
+	wmessage "github.com/piusalfred/whatsapp/message"
+	werrors "github.com/piusalfred/whatsapp/pkg/errors"
+	whttp "github.com/piusalfred/whatsapp/pkg/http"
+	"github.com/piusalfred/whatsapp/qrcode"
+	"github.com/piusalfred/whatsapp/webhooks"
+	"github.com/piusalfred/whatsapp/webhooks/message"
+	"github.com/piusalfred/whatsapp/whatsapptest"
+)
+
+func TestServer_SendReceiveLoop(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		statuses []string
+		done     = make(chan struct{})
+	)
+
+	handlers := &message.Handlers{}
+	handlers.SetMessageStatusChangeHandler(message.OnMessageStatusChangeHook(
+		func(_ context.Context, _ *message.NotificationContext, status *message.Status) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			statuses = append(statuses, status.StatusValue)
+			if len(statuses) == 2 {
+				close(done)
+			}
+
+			return nil
+		}))
+
+	listener := webhooks.NewListener(handlers.HandleNotification, nil,
+		&webhooks.ValidateOptions{Validate: true, AppSecret: "secret"})
+
+	server := whatsapptest.NewServer(
+		whatsapptest.WithWebhook(http.HandlerFunc(listener.HandleNotification), "secret"),
+		whatsapptest.WithStatuses(time.Millisecond, "delivered", "read"),
+	)
+	t.Cleanup(server.Close)
+
+	client, err := wmessage.NewBaseClient(whttp.NewSender[wmessage.Message](), server.ConfigReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.SendText(context.Background(),
+		wmessage.NewRequest("255700000000", &wmessage.Text{Body: "hello"}, ""))
+	if err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	sent := server.Messages()
+	if len(sent) != 1 || sent[0].ID != response.Messages[0].ID || sent[0].Message.Text.Body != "hello" {
+		t.Fatalf("unexpected sent messages: %+v", sent)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("status webhooks were not delivered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if diff := gcmp.Diff([]string{"delivered", "read"}, statuses); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := whatsapptest.NewServer(whatsapptest.WithRateLimit(1, time.Hour))
+	t.Cleanup(server.Close)
+
+	client, err := wmessage.NewBaseClient(whttp.NewSender[wmessage.Message](), server.ConfigReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func() error {
+		_, err := client.SendText(context.Background(),
+			wmessage.NewRequest("255700000000", &wmessage.Text{Body: "hello"}, ""))
+
+		return err
+	}
+
+	server.FailNext(http.StatusBadRequest, &werrors.Error{Message: "Re-engagement message", Code: 131047})
+
+	var apiErr *werrors.Error
+	if err := send(); !errors.As(err, &apiErr) || apiErr.Code != 131047 {
+		t.Fatalf("injected failure error = %v", err)
+	}
+
+	if err := send(); err != nil {
+		t.Fatalf("first send error = %v", err)
+	}
+
+	if err := send(); !errors.As(err, &apiErr) || apiErr.Code != werrors.CodeCloudAPIThroughput {
+		t.Errorf("rate limited send error = %v", err)
+	}
+}
+
+func TestServer_MediaAndQRCodes(t *testing.T) {
+	t.Parallel()
+
+	// media.BaseClient.GetInfo does not attach the access token, so authentication is off here.
+	server := whatsapptest.NewServer(whatsapptest.WithAccessToken(""))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	mediaClient := &media.BaseClient{ConfReader: server.ConfigReader(), Sender: whttp.NewAnySender()}
+
+	uploaded, err := mediaClient.UploadReader(ctx, bytes.NewReader([]byte("png")), 3, media.TypeImagePNG, nil)
+	if err != nil {
+		t.Fatalf("UploadReader() error = %v", err)
+	}
+
+	info, err := mediaClient.GetInfo(ctx, &media.BaseRequest{MediaID: uploaded.ID})
+	if err != nil || info.FileSize != 3 || info.MimeType != string(media.TypeImagePNG) {
+		t.Fatalf("GetInfo() = %+v, %v", info, err)
+	}
+
+	qrClient := qrcode.NewBaseClient(whttp.NewAnySender(), server.ConfigReader())
+
+	created, err := qrClient.Create(ctx, &qrcode.CreateRequest{PrefilledMessage: "hi", ImageFormat: qrcode.ImageFormatPNG})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := qrClient.Get(ctx, created.Code)
+	if err != nil || got.PrefilledMessage != "hi" {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+
+	if _, err := qrClient.Delete(ctx, created.Code); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := qrClient.Get(ctx, created.Code); err == nil {
+		t.Error("Get() of a deleted code succeeded")
+	}
+}